@@ -0,0 +1,145 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package addressbook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+)
+
+var (
+	ErrEmptyLabel   = errors.New("address label is empty")
+	ErrEmptyAddress = errors.New("address is empty")
+)
+
+// AddressBook maps human labels to addresses (eg "treasury", "validator-7 rewards"),
+// so summaries and reports can annotate the addresses they show.
+// Addresses are kept as strings, so both P/X bech32 addresses and EVM
+// hex addresses can be labeled. EVM addresses are matched case insensitively
+type AddressBook struct {
+	lock    sync.RWMutex
+	entries map[string]string // address -> label
+}
+
+func New() *AddressBook {
+	return &AddressBook{
+		entries: map[string]string{},
+	}
+}
+
+func normalize(addr string) string {
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		return strings.ToLower(addr)
+	}
+	return addr
+}
+
+// Add labels [addr] with [label], replacing any previous label for it
+func (ab *AddressBook) Add(label string, addr string) error {
+	if label == "" {
+		return ErrEmptyLabel
+	}
+	if addr == "" {
+		return ErrEmptyAddress
+	}
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+	ab.entries[normalize(addr)] = label
+	return nil
+}
+
+// AddShortID labels the [chainAlias] address for [addr] on [network]
+func (ab *AddressBook) AddShortID(label string, network avalanche.Network, chainAlias string, addr ids.ShortID) error {
+	addrStr, err := address.Format(chainAlias, network.HRP(), addr[:])
+	if err != nil {
+		return err
+	}
+	return ab.Add(label, addrStr)
+}
+
+// Remove deletes the label associated to [addr], if any
+func (ab *AddressBook) Remove(addr string) {
+	ab.lock.Lock()
+	defer ab.lock.Unlock()
+	delete(ab.entries, normalize(addr))
+}
+
+// Label returns the label associated to [addr]. A nil address book has no labels
+func (ab *AddressBook) Label(addr string) (string, bool) {
+	if ab == nil {
+		return "", false
+	}
+	ab.lock.RLock()
+	defer ab.lock.RUnlock()
+	label, ok := ab.entries[normalize(addr)]
+	return label, ok
+}
+
+// Addresses returns the addresses labeled with [label], sorted
+func (ab *AddressBook) Addresses(label string) []string {
+	ab.lock.RLock()
+	defer ab.lock.RUnlock()
+	addrs := []string{}
+	for addr, addrLabel := range ab.entries {
+		if addrLabel == label {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// Annotate returns [addr] followed by its label, if it has one, eg "P-fuji1... (treasury)"
+func (ab *AddressBook) Annotate(addr string) string {
+	if label, ok := ab.Label(addr); ok {
+		return fmt.Sprintf("%s (%s)", addr, label)
+	}
+	return addr
+}
+
+// AnnotateShortIDs formats [addrs] as [chainAlias] addresses on [network], and annotates them
+func (ab *AddressBook) AnnotateShortIDs(network avalanche.Network, chainAlias string, addrs []ids.ShortID) ([]string, error) {
+	annotated := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		addrStr, err := address.Format(chainAlias, network.HRP(), addr[:])
+		if err != nil {
+			return nil, err
+		}
+		annotated = append(annotated, ab.Annotate(addrStr))
+	}
+	return annotated, nil
+}
+
+// ToFile saves the address book as JSON into [path]
+func (ab *AddressBook) ToFile(path string) error {
+	ab.lock.RLock()
+	bs, err := json.MarshalIndent(ab.entries, "", "  ")
+	ab.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, constants.WriteReadUserOnlyPerms)
+}
+
+// FromFile loads an address book saved with ToFile
+func FromFile(path string) (*AddressBook, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ab := New()
+	if err := json.Unmarshal(bs, &ab.entries); err != nil {
+		return nil, fmt.Errorf("invalid address book file %s: %w", path, err)
+	}
+	return ab, nil
+}
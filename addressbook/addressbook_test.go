@@ -0,0 +1,50 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package addressbook
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressBook(t *testing.T) {
+	require := require.New(t)
+	ab := New()
+	require.ErrorIs(ab.Add("", "0x01"), ErrEmptyLabel)
+	require.ErrorIs(ab.Add("treasury", ""), ErrEmptyAddress)
+
+	evmAddr := "0xAbCdEf0000000000000000000000000000000001"
+	require.NoError(ab.Add("treasury", evmAddr))
+	label, ok := ab.Label("0xabcdef0000000000000000000000000000000001")
+	require.True(ok)
+	require.Equal("treasury", label)
+	require.Equal(evmAddr+" (treasury)", ab.Annotate(evmAddr))
+	require.Equal("0x02", ab.Annotate("0x02"))
+
+	network := avalanche.FujiNetwork()
+	rewards := ids.GenerateTestShortID()
+	require.NoError(ab.AddShortID("validator-7 rewards", network, "P", rewards))
+	annotated, err := ab.AnnotateShortIDs(network, "P", []ids.ShortID{rewards})
+	require.NoError(err)
+	require.Len(annotated, 1)
+	require.Contains(annotated[0], "P-fuji1")
+	require.Contains(annotated[0], "(validator-7 rewards)")
+
+	path := filepath.Join(t.TempDir(), "addressbook.json")
+	require.NoError(ab.ToFile(path))
+	loaded, err := FromFile(path)
+	require.NoError(err)
+	require.Equal(ab.entries, loaded.entries)
+	require.Len(loaded.Addresses("treasury"), 1)
+
+	loaded.Remove(evmAddr)
+	_, ok = loaded.Label(evmAddr)
+	require.False(ok)
+
+	var nilBook *AddressBook
+	require.Equal(evmAddr, nilBook.Annotate(evmAddr))
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/avm"
+)
+
+// AssetDescription is the metadata of an X-Chain asset
+type AssetDescription struct {
+	AssetID ids.ID
+	Name    string
+	Symbol  string
+	// Denomination is the number of decimal digits of the asset, so an amount of
+	// 10^Denomination base units is one unit of the asset
+	Denomination uint8
+}
+
+// FormatAmount renders [amount], given in base units of the asset, as a decimal
+// number followed by the asset symbol, eg. 1500000000 nAVAX as "1.5 AVAX"
+func (a AssetDescription) FormatAmount(amount uint64) string {
+	symbol := a.Symbol
+	if symbol == "" {
+		symbol = a.AssetID.String()
+	}
+	return FormatDenominatedAmount(amount, a.Denomination) + " " + symbol
+}
+
+// FormatDenominatedAmount renders [amount] base units as a decimal number with
+// [denomination] decimal digits, dropping trailing zeros
+func FormatDenominatedAmount(amount uint64, denomination uint8) string {
+	digits := strconv.FormatUint(amount, 10)
+	if denomination == 0 {
+		return digits
+	}
+	if len(digits) <= int(denomination) {
+		digits = strings.Repeat("0", int(denomination)-len(digits)+1) + digits
+	}
+	integer := digits[:len(digits)-int(denomination)]
+	fraction := strings.TrimRight(digits[len(digits)-int(denomination):], "0")
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
+
+type assetDescriptionClient interface {
+	GetAssetDescription(ctx context.Context, assetID string, options ...rpc.Option) (*avm.GetAssetDescriptionReply, error)
+}
+
+// assetCache keeps the descriptions already obtained, by endpoint. Asset metadata
+// is immutable, so entries never expire
+var assetCache = struct {
+	lock         sync.RWMutex
+	descriptions map[string]map[ids.ID]AssetDescription
+}{
+	descriptions: map[string]map[ids.ID]AssetDescription{},
+}
+
+// GetAssetDescription returns the name, symbol and denomination of the X-Chain asset
+// [assetID]. Descriptions are cached, so only the first lookup of an asset on a
+// network queries the X-Chain API
+func (n Network) GetAssetDescription(assetID ids.ID) (AssetDescription, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	return getAssetDescription(ctx, avm.NewClient(n.Endpoint, "X"), n.Endpoint, assetID)
+}
+
+// FormatAmount renders [amount] base units of [assetID] in the asset denomination,
+// as given by GetAssetDescription
+func (n Network) FormatAmount(assetID ids.ID, amount uint64) (string, error) {
+	description, err := n.GetAssetDescription(assetID)
+	if err != nil {
+		return "", err
+	}
+	return description.FormatAmount(amount), nil
+}
+
+func getAssetDescription(
+	ctx context.Context,
+	client assetDescriptionClient,
+	endpoint string,
+	assetID ids.ID,
+) (AssetDescription, error) {
+	assetCache.lock.RLock()
+	description, ok := assetCache.descriptions[endpoint][assetID]
+	assetCache.lock.RUnlock()
+	if ok {
+		return description, nil
+	}
+	reply, err := client.GetAssetDescription(ctx, assetID.String())
+	if err != nil {
+		return AssetDescription{}, fmt.Errorf("failure getting description of asset %s: %w", assetID, err)
+	}
+	description = AssetDescription{
+		AssetID:      assetID,
+		Name:         reply.Name,
+		Symbol:       reply.Symbol,
+		Denomination: uint8(reply.Denomination),
+	}
+	assetCache.lock.Lock()
+	defer assetCache.lock.Unlock()
+	if _, ok := assetCache.descriptions[endpoint]; !ok {
+		assetCache.descriptions[endpoint] = map[ids.ID]AssetDescription{}
+	}
+	assetCache.descriptions[endpoint][assetID] = description
+	return description, nil
+}
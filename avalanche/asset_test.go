@@ -0,0 +1,53 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAssetClient struct {
+	calls int
+}
+
+func (c *fakeAssetClient) GetAssetDescription(_ context.Context, _ string, _ ...rpc.Option) (*avm.GetAssetDescriptionReply, error) {
+	c.calls++
+	return &avm.GetAssetDescriptionReply{
+		Name:         "Test Token",
+		Symbol:       "TST",
+		Denomination: 6,
+	}, nil
+}
+
+func TestFormatDenominatedAmount(t *testing.T) {
+	require := require.New(t)
+	require.Equal("1.5", FormatDenominatedAmount(1_500_000_000, 9))
+	require.Equal("2", FormatDenominatedAmount(2_000_000_000, 9))
+	require.Equal("0.000000001", FormatDenominatedAmount(1, 9))
+	require.Equal("0", FormatDenominatedAmount(0, 9))
+	require.Equal("42", FormatDenominatedAmount(42, 0))
+}
+
+func TestGetAssetDescriptionCache(t *testing.T) {
+	require := require.New(t)
+	client := &fakeAssetClient{}
+	assetID := ids.GenerateTestID()
+	description, err := getAssetDescription(context.Background(), client, "http://cache-test", assetID)
+	require.NoError(err)
+	require.Equal(AssetDescription{AssetID: assetID, Name: "Test Token", Symbol: "TST", Denomination: 6}, description)
+	require.Equal("12.34 TST", description.FormatAmount(12_340_000))
+	_, err = getAssetDescription(context.Background(), client, "http://cache-test", assetID)
+	require.NoError(err)
+	require.Equal(1, client.calls)
+	// cache is per network endpoint
+	_, err = getAssetDescription(context.Background(), client, "http://other", assetID)
+	require.NoError(err)
+	require.Equal(2, client.calls)
+}
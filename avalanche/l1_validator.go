@@ -0,0 +1,88 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/json"
+)
+
+var ErrL1ValidatorNotFound = errors.New("L1 validator not found")
+
+// L1Validator is the P-Chain state of an L1 validator
+type L1Validator struct {
+	ValidationID ids.ID      `json:"validationID"`
+	SubnetID     ids.ID      `json:"subnetID"`
+	NodeID       ids.NodeID  `json:"nodeID"`
+	StartTime    json.Uint64 `json:"startTime"`
+	Weight       json.Uint64 `json:"weight"`
+	// MinNonce is the lowest nonce a weight update message for the validator must have
+	MinNonce json.Uint64 `json:"minNonce"`
+	// Balance is the nAVAX left to pay the continuous fee. The validator is inactive at 0
+	Balance json.Uint64 `json:"balance"`
+	// PublicKey is the registered BLS public key, hex encoded in compressed form
+	PublicKey string `json:"publicKey"`
+}
+
+// Active tells if the validator still has balance to pay for the continuous fee
+func (v L1Validator) Active() bool {
+	return v.Balance > 0
+}
+
+// GetL1Validator returns the P-Chain state of the L1 validator [validationID]. It
+// returns ErrL1ValidatorNotFound if the P-Chain doesn't have it, either because it was
+// never registered or because it was removed
+func (n Network) GetL1Validator(ctx context.Context, validationID ids.ID) (L1Validator, error) {
+	reply := L1Validator{}
+	err := n.pChainRequester().SendRequest(
+		ctx,
+		"platform.getL1Validator",
+		struct {
+			ValidationID ids.ID `json:"validationID"`
+		}{validationID},
+		&reply,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return L1Validator{}, ErrL1ValidatorNotFound
+		}
+		return L1Validator{}, err
+	}
+	reply.ValidationID = validationID
+	return reply, nil
+}
+
+// GetL1ValidationIDs returns the validation ID of each current L1 validator of [subnetID],
+// by node ID
+func (n Network) GetL1ValidationIDs(ctx context.Context, subnetID ids.ID) (map[ids.NodeID]ids.ID, error) {
+	reply := struct {
+		Validators []struct {
+			NodeID       ids.NodeID `json:"nodeID"`
+			ValidationID *ids.ID    `json:"validationID"`
+		} `json:"validators"`
+	}{}
+	err := n.pChainRequester().SendRequest(
+		ctx,
+		"platform.getCurrentValidators",
+		struct {
+			SubnetID ids.ID `json:"subnetID"`
+		}{subnetID},
+		&reply,
+	)
+	if err != nil {
+		return nil, err
+	}
+	validationIDs := map[ids.NodeID]ids.ID{}
+	for _, validator := range reply.Validators {
+		// permissioned subnet validators have no validation ID
+		if validator.ValidationID != nil {
+			validationIDs[validator.NodeID] = *validator.ValidationID
+		}
+	}
+	return validationIDs, nil
+}
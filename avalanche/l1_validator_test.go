@@ -0,0 +1,52 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetL1Validator(t *testing.T) {
+	require := require.New(t)
+	validationID := ids.GenerateTestID()
+	nodeID := ids.GenerateTestNodeID()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params struct {
+				ValidationID ids.ID `json:"validationID"`
+			} `json:"params"`
+		}
+		require.NoError(json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case req.Method == "platform.getCurrentValidators":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"validators":[{"nodeID":"%s","validationID":"%s"},{"nodeID":"%s"}]}}`, nodeID, validationID, ids.GenerateTestNodeID())
+		case req.Params.ValidationID == validationID:
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"nodeID":"%s","weight":"20","minNonce":"1","balance":"0"}}`, nodeID)
+		default:
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"fetching L1 validator: not found"}}`))
+		}
+	}))
+	defer server.Close()
+	network := NewNetwork(Devnet, 1338, server.URL)
+	validationIDs, err := network.GetL1ValidationIDs(context.Background(), ids.GenerateTestID())
+	require.NoError(err)
+	require.Equal(map[ids.NodeID]ids.ID{nodeID: validationID}, validationIDs)
+	validator, err := network.GetL1Validator(context.Background(), validationID)
+	require.NoError(err)
+	require.Equal(validationID, validator.ValidationID)
+	require.Equal(nodeID, validator.NodeID)
+	require.Equal(uint64(20), uint64(validator.Weight))
+	require.False(validator.Active())
+	_, err = network.GetL1Validator(context.Background(), ids.GenerateTestID())
+	require.ErrorIs(err, ErrL1ValidatorNotFound)
+}
@@ -49,22 +49,15 @@ type Network struct {
 var UndefinedNetwork = Network{}
 
 func (n Network) HRP() string {
-	switch n.ID {
-	case constants.FujiID:
-		return constants.FujiHRP
-	case constants.MainnetID:
-		return constants.MainnetHRP
-	default:
-		return constants.FallbackHRP
+	if info, ok := GetNetworkInfo(n.ID); ok {
+		return info.HRP
 	}
+	return constants.FallbackHRP
 }
 
 func NetworkFromNetworkID(networkID uint32) Network {
-	switch networkID {
-	case constants.MainnetID:
-		return MainnetNetwork()
-	case constants.FujiID:
-		return FujiNetwork()
+	if info, ok := GetNetworkInfo(networkID); ok {
+		return NewNetwork(info.Kind, networkID, info.Endpoint)
 	}
 	return UndefinedNetwork
 }
@@ -0,0 +1,105 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/txdetect"
+)
+
+const (
+	// Multicall3 is deployed at the same address on all EVM chains that support it
+	Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	MainnetCChainEVMChainID = 43114
+	FujiCChainEVMChainID    = 43113
+	// C-Chain EVM chain ID of local networks
+	LocalCChainEVMChainID = 43112
+)
+
+var (
+	MainnetCChainBlockchainID = ids.FromStringOrPanic("2q9e4r6Mu3U68nU1fYjgbR6JvwrRx36CohpAX5UQxse55x1Q5")
+	FujiCChainBlockchainID    = ids.FromStringOrPanic("yH8D7ThNJkxmtkuv2jgBa4P1Rn3Qpr4pPr7QYNfcdoS6k6HWp")
+)
+
+// NetworkInfo holds the well known settings of a network
+type NetworkInfo struct {
+	Kind     NetworkKind
+	HRP      string
+	Endpoint string
+	// C-Chain settings
+	CChainBlockchainID        ids.ID
+	CChainEVMChainID          uint64
+	TeleporterRegistryAddress string
+	Multicall3Address         string
+}
+
+var networkRegistry = struct {
+	lock     sync.RWMutex
+	networks map[uint32]NetworkInfo
+}{
+	networks: map[uint32]NetworkInfo{
+		constants.MainnetID: {
+			Kind:                      Mainnet,
+			HRP:                       constants.MainnetHRP,
+			Endpoint:                  MainnetAPIEndpoint,
+			CChainBlockchainID:        MainnetCChainBlockchainID,
+			CChainEVMChainID:          MainnetCChainEVMChainID,
+			TeleporterRegistryAddress: "0x7C43605E14F391720e1b37E49C78C4b03A488d98",
+			Multicall3Address:         Multicall3Address,
+		},
+		constants.FujiID: {
+			Kind:                      Fuji,
+			HRP:                       constants.FujiHRP,
+			Endpoint:                  FujiAPIEndpoint,
+			CChainBlockchainID:        FujiCChainBlockchainID,
+			CChainEVMChainID:          FujiCChainEVMChainID,
+			TeleporterRegistryAddress: "0xF86Cb19Ad8405AEFa7d09C778215D2Cb6eBfB228",
+			Multicall3Address:         Multicall3Address,
+		},
+	},
+}
+
+func init() {
+	txdetect.RegisterCChainEVMChainID(LocalCChainEVMChainID)
+	for _, info := range networkRegistry.networks {
+		txdetect.RegisterCChainEVMChainID(info.CChainEVMChainID)
+	}
+}
+
+// GetNetworkInfo returns the registered settings for [networkID]
+func GetNetworkInfo(networkID uint32) (NetworkInfo, bool) {
+	networkRegistry.lock.RLock()
+	defer networkRegistry.lock.RUnlock()
+	info, ok := networkRegistry.networks[networkID]
+	return info, ok
+}
+
+// RegisterNetworkInfo sets the settings for [networkID], overriding the
+// existing ones if any. Custom networks default to Devnet kind and
+// fallback HRP. EVM txs with its C-Chain EVM chain ID, if set, are detected
+// as C-Chain txs by package txdetect
+func RegisterNetworkInfo(networkID uint32, info NetworkInfo) {
+	if info.Kind == Undefined {
+		info.Kind = Devnet
+	}
+	if info.HRP == "" {
+		info.HRP = constants.FallbackHRP
+	}
+	networkRegistry.lock.Lock()
+	defer networkRegistry.lock.Unlock()
+	networkRegistry.networks[networkID] = info
+	if info.CChainEVMChainID != 0 {
+		txdetect.RegisterCChainEVMChainID(info.CChainEVMChainID)
+	}
+}
+
+// Info returns the registered settings for the network
+func (n Network) Info() (NetworkInfo, bool) {
+	return GetNetworkInfo(n.ID)
+}
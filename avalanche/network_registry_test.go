@@ -0,0 +1,75 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/txdetect"
+)
+
+func TestNetworkRegistry(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(MainnetNetwork(), NetworkFromNetworkID(constants.MainnetID))
+	require.Equal(FujiNetwork(), NetworkFromNetworkID(constants.FujiID))
+	require.Equal(constants.FujiHRP, FujiNetwork().HRP())
+	info, ok := FujiNetwork().Info()
+	require.True(ok)
+	require.Equal(FujiCChainBlockchainID, info.CChainBlockchainID)
+
+	customID := uint32(1337)
+	require.Equal(UndefinedNetwork, NetworkFromNetworkID(customID))
+	require.Equal(constants.FallbackHRP, NewNetwork(Devnet, customID, "").HRP())
+
+	RegisterNetworkInfo(customID, NetworkInfo{
+		HRP:      "custom",
+		Endpoint: "http://127.0.0.1:9650",
+	})
+	defer func() {
+		networkRegistry.lock.Lock()
+		delete(networkRegistry.networks, customID)
+		networkRegistry.lock.Unlock()
+	}()
+	network := NetworkFromNetworkID(customID)
+	require.Equal(NewNetwork(Devnet, customID, "http://127.0.0.1:9650"), network)
+	require.Equal("custom", network.HRP())
+}
+
+func TestNetworkRegistryCChainDetection(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	detect := func(chainID uint64) string {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(new(big.Int).SetUint64(chainID)), &types.LegacyTx{
+			Gas:      21_000,
+			GasPrice: big.NewInt(1),
+		})
+		require.NoError(err)
+		txBytes, err := tx.MarshalBinary()
+		require.NoError(err)
+		detection, err := txdetect.Detect(txBytes)
+		require.NoError(err)
+		return detection.Chain
+	}
+	require.Equal(txdetect.CChain, detect(MainnetCChainEVMChainID))
+	require.Equal(txdetect.CChain, detect(FujiCChainEVMChainID))
+	require.Equal(txdetect.CChain, detect(LocalCChainEVMChainID))
+
+	customID := uint32(54321)
+	require.Equal(txdetect.EVMChain, detect(54321))
+	RegisterNetworkInfo(customID, NetworkInfo{CChainEVMChainID: 54321})
+	defer func() {
+		networkRegistry.lock.Lock()
+		delete(networkRegistry.networks, customID)
+		networkRegistry.lock.Unlock()
+	}()
+	require.Equal(txdetect.CChain, detect(54321))
+}
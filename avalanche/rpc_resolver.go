@@ -0,0 +1,193 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// rpcMaxBlockLag is how many blocks an endpoint can be behind the most synced
+// candidate and still be selected for being faster
+const rpcMaxBlockLag = 2
+
+var ErrNoLiveRPCEndpoint = errors.New("no live RPC endpoint")
+
+var apiNodesRegistry = struct {
+	lock  sync.RWMutex
+	nodes map[uint32][]string
+}{
+	nodes: map[uint32][]string{},
+}
+
+// RegisterAPINodes adds the API nodes at [endpoints] (eg. "http://1.2.3.4:9650") to
+// the candidates of [networkID] considered by Network.ResolveRPCEndpoint
+func RegisterAPINodes(networkID uint32, endpoints ...string) {
+	apiNodesRegistry.lock.Lock()
+	defer apiNodesRegistry.lock.Unlock()
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSuffix(endpoint, "/")
+		if !utils.Belongs(apiNodesRegistry.nodes[networkID], endpoint) {
+			apiNodesRegistry.nodes[networkID] = append(apiNodesRegistry.nodes[networkID], endpoint)
+		}
+	}
+}
+
+// GetAPINodes returns the API node endpoints registered for [networkID]
+func GetAPINodes(networkID uint32) []string {
+	apiNodesRegistry.lock.RLock()
+	defer apiNodesRegistry.lock.RUnlock()
+	return append([]string{}, apiNodesRegistry.nodes[networkID]...)
+}
+
+// RPCProbe is the result of checking a candidate RPC URL
+type RPCProbe struct {
+	URL         string        `json:"url"`
+	Live        bool          `json:"live"`
+	BlockNumber uint64        `json:"blockNumber,omitempty"`
+	Latency     time.Duration `json:"latency,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// RPCCandidates returns the candidate RPC URLs of EVM chain [blockchainID]: the ones of
+// the API nodes registered for the network first, then the one of the network endpoint,
+// and the one of the registered public endpoint of the network
+func (n Network) RPCCandidates(blockchainID ids.ID) []string {
+	endpoints := GetAPINodes(n.ID)
+	endpoints = append(endpoints, n.Endpoint)
+	if info, ok := n.Info(); ok {
+		endpoints = append(endpoints, info.Endpoint)
+	}
+	candidates := []string{}
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSuffix(endpoint, "/")
+		if endpoint == "" {
+			continue
+		}
+		candidate := Network{Endpoint: endpoint}.BlockchainEndpoint(blockchainID.String())
+		if !utils.Belongs(candidates, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// ResolveRPCEndpoint probes in parallel the RPC candidates of EVM chain [blockchainID],
+// and returns the best live one, together with the result of all probes. The best
+// endpoint is the fastest among the ones synced up to rpcMaxBlockLag blocks of the
+// most synced candidate. Returns ErrNoLiveRPCEndpoint if none answers
+func (n Network) ResolveRPCEndpoint(ctx context.Context, blockchainID ids.ID) (string, []RPCProbe, error) {
+	candidates := n.RPCCandidates(blockchainID)
+	probes := make([]RPCProbe, len(candidates))
+	wg := sync.WaitGroup{}
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func(i int, candidate string) {
+			defer wg.Done()
+			probes[i] = ProbeRPC(ctx, candidate)
+		}(i, candidate)
+	}
+	wg.Wait()
+	best, ok := bestRPCProbe(probes)
+	if !ok {
+		return "", probes, fmt.Errorf("%w for blockchain %s among %d candidates", ErrNoLiveRPCEndpoint, blockchainID, len(candidates))
+	}
+	return best.URL, probes, nil
+}
+
+// ProbeRPC checks that [rpcURL] answers eth_blockNumber, measuring its latency
+func ProbeRPC(ctx context.Context, rpcURL string) RPCProbe {
+	probe := RPCProbe{URL: rpcURL}
+	ctx, cancel := context.WithTimeout(ctx, constants.APIRequestTimeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_blockNumber",
+		"params":  []interface{}{},
+	})
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcURL, bytes.NewReader(body))
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	req.Header.Set("Content-Type", "application/json")
+	startTime := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	defer resp.Body.Close()
+	probe.Latency = time.Since(startTime)
+	if resp.StatusCode != http.StatusOK {
+		probe.Error = "unexpected status " + resp.Status
+		return probe
+	}
+	var reply struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		probe.Error = fmt.Sprintf("invalid response: %s", err)
+		return probe
+	}
+	if reply.Error != nil {
+		probe.Error = reply.Error.Message
+		return probe
+	}
+	blockNumber, err := strconv.ParseUint(strings.TrimPrefix(reply.Result, "0x"), 16, 64)
+	if err != nil {
+		probe.Error = fmt.Sprintf("invalid block number %q", reply.Result)
+		return probe
+	}
+	probe.Live = true
+	probe.BlockNumber = blockNumber
+	return probe
+}
+
+// bestRPCProbe returns the fastest live probe among the ones at most rpcMaxBlockLag
+// blocks behind the highest block number seen. Ties keep the candidate order
+func bestRPCProbe(probes []RPCProbe) (RPCProbe, bool) {
+	live := []RPCProbe{}
+	tip := uint64(0)
+	for _, probe := range probes {
+		if probe.Live {
+			live = append(live, probe)
+			tip = max(tip, probe.BlockNumber)
+		}
+	}
+	synced := []RPCProbe{}
+	for _, probe := range live {
+		if probe.BlockNumber+rpcMaxBlockLag >= tip {
+			synced = append(synced, probe)
+		}
+	}
+	if len(synced) == 0 {
+		return RPCProbe{}, false
+	}
+	sort.SliceStable(synced, func(i, j int) bool {
+		return synced[i].Latency < synced[j].Latency
+	})
+	return synced[0], true
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func newRPCTestServer(blockchainID ids.ID, blockNumber uint64, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/ext/bc/%s/rpc", blockchainID) {
+			http.NotFound(w, r)
+			return
+		}
+		time.Sleep(delay)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%x"}`, blockNumber)
+	}))
+}
+
+func TestResolveRPCEndpoint(t *testing.T) {
+	require := require.New(t)
+	blockchainID := ids.GenerateTestID()
+	networkID := uint32(4321)
+	defer func() {
+		apiNodesRegistry.lock.Lock()
+		delete(apiNodesRegistry.nodes, networkID)
+		apiNodesRegistry.lock.Unlock()
+	}()
+
+	lagging := newRPCTestServer(blockchainID, 90, 0)
+	defer lagging.Close()
+	slow := newRPCTestServer(blockchainID, 100, 100*time.Millisecond)
+	defer slow.Close()
+	fast := newRPCTestServer(blockchainID, 99, 0)
+	defer fast.Close()
+	down := newRPCTestServer(ids.GenerateTestID(), 100, 0)
+	defer down.Close()
+
+	RegisterAPINodes(networkID, lagging.URL+"/", fast.URL, down.URL, fast.URL)
+	require.Equal([]string{lagging.URL, fast.URL, down.URL}, GetAPINodes(networkID))
+	network := NewNetwork(Devnet, networkID, slow.URL)
+	rpcURL := func(endpoint string) string {
+		return fmt.Sprintf("%s/ext/bc/%s/rpc", endpoint, blockchainID)
+	}
+	require.Equal(
+		[]string{rpcURL(lagging.URL), rpcURL(fast.URL), rpcURL(down.URL), rpcURL(slow.URL)},
+		network.RPCCandidates(blockchainID),
+	)
+
+	best, probes, err := network.ResolveRPCEndpoint(context.Background(), blockchainID)
+	require.NoError(err)
+	require.Equal(rpcURL(fast.URL), best)
+	require.Len(probes, 4)
+	require.True(probes[0].Live)
+	require.Equal(uint64(90), probes[0].BlockNumber)
+	require.False(probes[2].Live)
+	require.NotEmpty(probes[2].Error)
+
+	_, _, err = NewNetwork(Devnet, 4322, down.URL).ResolveRPCEndpoint(context.Background(), blockchainID)
+	require.ErrorIs(err, ErrNoLiveRPCEndpoint)
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/json"
+)
+
+// SubnetInfo is the P-Chain state of a subnet, including the conversion data of L1s
+type SubnetInfo struct {
+	IsPermissioned bool `json:"isPermissioned"`
+	// ControlKeys are the P-Chain addresses of the subnet owners, empty after the
+	// conversion to an L1
+	ControlKeys []string    `json:"controlKeys"`
+	Threshold   json.Uint32 `json:"threshold"`
+	Locktime    json.Uint64 `json:"locktime"`
+	// SubnetTransformationTxID is set for elastic subnets
+	SubnetTransformationTxID ids.ID `json:"subnetTransformationTxID"`
+	// ConversionID, ManagerChainID and ManagerAddress are set for L1s. ManagerAddress is
+	// hex encoded
+	ConversionID   ids.ID `json:"conversionID"`
+	ManagerChainID ids.ID `json:"managerChainID"`
+	ManagerAddress string `json:"managerAddress"`
+}
+
+// IsL1 tells if the subnet was converted to an L1
+func (s SubnetInfo) IsL1() bool {
+	return s.ConversionID != ids.Empty
+}
+
+// GetSubnetInfo returns the P-Chain state of [subnetID]. Unlike the avalanchego client
+// GetSubnet, it includes the conversion data of L1s
+func (n Network) GetSubnetInfo(ctx context.Context, subnetID ids.ID) (SubnetInfo, error) {
+	reply := SubnetInfo{}
+	err := n.pChainRequester().SendRequest(
+		ctx,
+		"platform.getSubnet",
+		struct {
+			SubnetID ids.ID `json:"subnetID"`
+		}{subnetID},
+		&reply,
+	)
+	return reply, err
+}
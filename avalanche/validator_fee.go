@@ -0,0 +1,162 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"math/bits"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+)
+
+// MonthDuration is the period used to compute monthly L1 validator costs
+const MonthDuration = 30 * 24 * time.Hour
+
+var ErrInvalidValidatorFeeConfig = errors.New("invalid L1 validator fee config")
+
+// ValidatorFeeConfig is the P-Chain configuration of the continuous fee paid by
+// L1 validators
+type ValidatorFeeConfig struct {
+	// Capacity is the maximum number of active L1 validators
+	Capacity json.Uint64 `json:"capacity"`
+	// Target is the number of active L1 validators at which the price stays constant
+	Target json.Uint64 `json:"target"`
+	// MinPrice is the minimum price per second, in nAVAX, of an L1 validator
+	MinPrice json.Uint64 `json:"minPrice"`
+	// ExcessConversionConstant controls how fast the price changes
+	ExcessConversionConstant json.Uint64 `json:"excessConversionConstant"`
+}
+
+// ValidatorFeeState is the P-Chain state of the L1 validator fee
+type ValidatorFeeState struct {
+	// Excess accumulates the validator-seconds above target
+	Excess json.Uint64 `json:"excess"`
+	// Price is the current price per second, in nAVAX, of an L1 validator
+	Price json.Uint64 `json:"price"`
+	// Timestamp the state refers to
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (n Network) pChainRequester() rpc.EndpointRequester {
+	return rpc.NewEndpointRequester(n.Endpoint + "/ext/P")
+}
+
+// GetValidatorFeeConfig returns the L1 validator fee configuration of the network
+func (n Network) GetValidatorFeeConfig(ctx context.Context) (ValidatorFeeConfig, error) {
+	reply := ValidatorFeeConfig{}
+	err := n.pChainRequester().SendRequest(ctx, "platform.getValidatorFeeConfig", struct{}{}, &reply)
+	return reply, err
+}
+
+// GetValidatorFeeState returns the current L1 validator fee state of the network
+func (n Network) GetValidatorFeeState(ctx context.Context) (ValidatorFeeState, error) {
+	reply := ValidatorFeeState{}
+	err := n.pChainRequester().SendRequest(ctx, "platform.getValidatorFeeState", struct{}{}, &reply)
+	return reply, err
+}
+
+// ValidatorFeePrice returns the price per second, in nAVAX, of an L1 validator
+// for the given [excess], as computed by the P-Chain:
+// MinPrice * e^(excess / ExcessConversionConstant)
+func ValidatorFeePrice(config ValidatorFeeConfig, excess uint64) uint64 {
+	denominator := uint64(config.ExcessConversionConstant)
+	if denominator == 0 {
+		return math.MaxUint64
+	}
+	// taylor expansion of the exponential, the same approximation the P-Chain uses
+	hi, accum := bits.Mul64(uint64(config.MinPrice), denominator)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	var output uint64
+	for i := uint64(1); accum > 0; i++ {
+		var carry uint64
+		output, carry = bits.Add64(output, accum, 0)
+		if carry != 0 {
+			return math.MaxUint64
+		}
+		hi, lo := bits.Mul64(accum, excess)
+		divHi, divisor := bits.Mul64(denominator, i)
+		if divHi != 0 {
+			// the divisor is too big for any term to be relevant
+			break
+		}
+		if hi >= divisor {
+			return math.MaxUint64
+		}
+		accum, _ = bits.Div64(hi, lo, divisor)
+	}
+	return output / denominator
+}
+
+// advanceExcess returns the fee excess after one second with [active] L1 validators
+func advanceExcess(config ValidatorFeeConfig, excess uint64, active uint64) uint64 {
+	target := uint64(config.Target)
+	if active > target {
+		diff := active - target
+		if excess > math.MaxUint64-diff {
+			return math.MaxUint64
+		}
+		return excess + diff
+	}
+	diff := target - active
+	if diff > excess {
+		return 0
+	}
+	return excess - diff
+}
+
+// ProjectValidatorCost returns the amount of nAVAX an L1 validator pays during [duration],
+// starting from [state] and assuming that the number of active L1 validators on the network
+// stays at [active]. Passing the fee Target as [active] keeps the price constant
+func ProjectValidatorCost(
+	config ValidatorFeeConfig,
+	state ValidatorFeeState,
+	active uint64,
+	duration time.Duration,
+) (uint64, error) {
+	if config.ExcessConversionConstant == 0 || config.Target > config.Capacity {
+		return 0, ErrInvalidValidatorFeeConfig
+	}
+	if active > uint64(config.Capacity) {
+		active = uint64(config.Capacity)
+	}
+	seconds := uint64(duration / time.Second)
+	excess := uint64(state.Excess)
+	cost := new(big.Int)
+	for s := uint64(0); s < seconds; s++ {
+		excess = advanceExcess(config, excess, active)
+		price := ValidatorFeePrice(config, excess)
+		// once the excess no longer changes the price is constant for the rest of the period
+		if next := advanceExcess(config, excess, active); next == excess {
+			remaining := new(big.Int).SetUint64(seconds - s)
+			cost.Add(cost, remaining.Mul(remaining, new(big.Int).SetUint64(price)))
+			break
+		}
+		cost.Add(cost, new(big.Int).SetUint64(price))
+	}
+	if !cost.IsUint64() {
+		return math.MaxUint64, nil
+	}
+	return cost.Uint64(), nil
+}
+
+// MonthlyValidatorCost returns the projected cost, in nAVAX, of keeping an L1 validator active
+// for MonthDuration, given the current fee state of the network. See ProjectValidatorCost
+func (n Network) MonthlyValidatorCost(ctx context.Context, active uint64) (uint64, error) {
+	config, err := n.GetValidatorFeeConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+	state, err := n.GetValidatorFeeState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return ProjectValidatorCost(config, state, active, MonthDuration)
+}
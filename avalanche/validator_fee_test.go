@@ -0,0 +1,89 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avalanche
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testValidatorFeeConfig = ValidatorFeeConfig{
+	Capacity:                 20_000,
+	Target:                   10_000,
+	MinPrice:                 512,
+	ExcessConversionConstant: 1_246_488_515,
+}
+
+func TestValidatorFeePrice(t *testing.T) {
+	require := require.New(t)
+	require.Equal(uint64(512), ValidatorFeePrice(testValidatorFeeConfig, 0))
+	// e^(ln 2) doubles the price
+	require.InDelta(1024, ValidatorFeePrice(testValidatorFeeConfig, 864_000_000), 1)
+	require.Greater(ValidatorFeePrice(testValidatorFeeConfig, 2_000_000_000), uint64(2048))
+}
+
+func TestProjectValidatorCost(t *testing.T) {
+	require := require.New(t)
+	state := ValidatorFeeState{Excess: 0, Price: 512}
+
+	// at target the price doesn't move
+	cost, err := ProjectValidatorCost(testValidatorFeeConfig, state, 10_000, MonthDuration)
+	require.NoError(err)
+	require.Equal(uint64(512*30*24*3600), cost)
+
+	// below target the excess can't go under zero
+	cost, err = ProjectValidatorCost(testValidatorFeeConfig, state, 10, time.Hour)
+	require.NoError(err)
+	require.Equal(uint64(512*3600), cost)
+
+	// above target the price grows
+	cost, err = ProjectValidatorCost(testValidatorFeeConfig, state, 20_000, time.Hour)
+	require.NoError(err)
+	require.Greater(cost, uint64(512*3600))
+
+	// excess above zero decreases when below target
+	state.Excess = 864_000_000
+	cost, err = ProjectValidatorCost(testValidatorFeeConfig, state, 0, time.Hour)
+	require.NoError(err)
+	require.Less(cost, uint64(1024*3600))
+	require.Greater(cost, uint64(1000*3600))
+
+	_, err = ProjectValidatorCost(ValidatorFeeConfig{}, state, 0, time.Hour)
+	require.ErrorIs(err, ErrInvalidValidatorFeeConfig)
+}
+
+func TestGetValidatorFeeState(t *testing.T) {
+	require := require.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal("/ext/P", r.URL.Path)
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(json.NewDecoder(r.Body).Decode(&req))
+		switch req.Method {
+		case "platform.getValidatorFeeConfig":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"capacity":20000,"target":10000,"minPrice":512,"excessConversionConstant":1246488515}}`))
+		case "platform.getValidatorFeeState":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"excess":"0","price":"512","timestamp":"2024-12-16T17:00:00Z"}}`))
+		}
+	}))
+	defer server.Close()
+	network := NewNetwork(Devnet, 1338, server.URL)
+	config, err := network.GetValidatorFeeConfig(context.Background())
+	require.NoError(err)
+	require.Equal(testValidatorFeeConfig, config)
+	state, err := network.GetValidatorFeeState(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(512), uint64(state.Price))
+	require.Equal(time.Date(2024, 12, 16, 17, 0, 0, 0, time.UTC), state.Timestamp)
+	cost, err := network.MonthlyValidatorCost(context.Background(), 10_000)
+	require.NoError(err)
+	require.Equal(uint64(512*30*24*3600), cost)
+}
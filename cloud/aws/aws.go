@@ -5,63 +5,85 @@ package aws
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
-	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/proxy"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 )
 
 var (
-	ErrNoInstanceState         = errors.New("unable to get instance state")
-	ErrNoAddressFound          = errors.New("unable to get public IP address info on AWS")
-	ErrNodeNotFoundToBeRunning = errors.New("node not found to be running")
+	ErrNoInstanceState          = errors.New("unable to get instance state")
+	ErrNoAddressFound           = errors.New("unable to get public IP address info on AWS")
+	ErrNodeNotFoundToBeRunning  = errors.New("node not found to be running")
+	ErrSecurityGroupNotFound    = errors.New("security group not found")
+	ErrKeyPairNotFound          = errors.New("key pair not found")
+	ErrSecurityGroupPortsClosed = errors.New("required ports are not open in security group")
+	ErrHostKeysNotFound         = errors.New("SSH host keys not found in console output")
 )
 
 type AwsCloud struct {
 	ec2Client *ec2.Client
+	elbClient *elasticloadbalancingv2.Client
 	ctx       context.Context
 }
 
-// NewAwsCloud creates an AWS cloud
-func NewAwsCloud(ctx context.Context, awsProfile, region string) (*AwsCloud, error) {
-	var (
-		cfg aws.Config
-		err error
-	)
+// LoadConfig loads the AWS SDK config for [region], from env variables if AWS_ACCESS_KEY_ID
+// is set, or else from [awsProfile] in the AWS config files. API calls go through the proxy
+// set with proxy.SetDefault, if any, and are retried following SetRetryConfig
+func LoadConfig(ctx context.Context, awsProfile, region string) (aws.Config, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	if os.Getenv("AWS_ACCESS_KEY_ID") != "" {
-		// Load session from env variables
-		cfg, err = config.LoadDefaultConfig(
-			ctx,
-			config.WithRegion(region),
-		)
-	} else {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(region),
+		config.WithRetryer(GetRetryConfig().Retryer),
+	}
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
 		// Load session from profile in config file
-		cfg, err = config.LoadDefaultConfig(
-			ctx,
-			config.WithRegion(region),
-			config.WithSharedConfigProfile(awsProfile),
-		)
+		opts = append(opts, config.WithSharedConfigProfile(awsProfile))
+	}
+	if proxyConfig := proxy.Default(); proxyConfig.Enabled() {
+		if _, err := proxyConfig.Transport(); err != nil {
+			return aws.Config{}, err
+		}
+		opts = append(opts, config.WithHTTPClient(
+			awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+				tr.Proxy = proxyConfig.ProxyFunc()
+			}),
+		))
+	}
+	return config.LoadDefaultConfig(ctx, opts...)
+}
+
+// NewAwsCloud creates an AWS cloud
+func NewAwsCloud(ctx context.Context, awsProfile, region string) (*AwsCloud, error) {
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	cfg, err := LoadConfig(ctx, awsProfile, region)
 	if err != nil {
 		return nil, err
 	}
 	return &AwsCloud{
 		ec2Client: ec2.NewFromConfig(cfg),
+		elbClient: elasticloadbalancingv2.NewFromConfig(cfg),
 		ctx:       ctx,
 	}, nil
 }
@@ -192,8 +214,77 @@ func (c *AwsCloud) DeleteSecurityGroupRule(groupID, direction, protocol, ip stri
 	return nil
 }
 
+// EC2InstanceOptions are the hardening settings of the EC2 instances created by
+// CreateEC2InstancesWithOptions. The zero value keeps the AWS defaults
+type EC2InstanceOptions struct {
+	// RequireIMDSv2 makes the instance metadata service only accept session token
+	// (IMDSv2) requests
+	RequireIMDSv2 bool
+	// MetadataHopLimit is the PUT response hop limit of the metadata service. 0 keeps
+	// the AWS default
+	MetadataHopLimit int32
+	// DisablePublicIP prevents the assignment of an ephemeral public IP. Only makes
+	// sense when elastic IPs are associated afterwards
+	DisablePublicIP bool
+	// EncryptVolume enables encryption of the root EBS volume, with the account default
+	// KMS key unless KMSKeyID is set
+	EncryptVolume bool
+	// KMSKeyID is the KMS key used to encrypt the root EBS volume. Setting it implies
+	// EncryptVolume
+	KMSKeyID string
+	// TerminationProtection prevents the instances from being terminated until it is
+	// disabled with SetTerminationProtection
+	TerminationProtection bool
+}
+
 // CreateEC2Instances creates EC2 instances
 func (c *AwsCloud) CreateEC2Instances(count int, amiID, instanceType, keyName, securityGroupID string, iops, throughput int, volumeTypeString string, volumeSize int) ([]string, error) {
+	return c.CreateEC2InstancesWithOptions(count, amiID, instanceType, keyName, securityGroupID, iops, throughput, volumeTypeString, volumeSize, EC2InstanceOptions{})
+}
+
+// CreateEC2InstancesWithOptions creates EC2 instances with the hardening settings of [opts]
+func (c *AwsCloud) CreateEC2InstancesWithOptions(
+	count int,
+	amiID,
+	instanceType,
+	keyName,
+	securityGroupID string,
+	iops,
+	throughput int,
+	volumeTypeString string,
+	volumeSize int,
+	opts EC2InstanceOptions,
+) ([]string, error) {
+	input := runInstancesInput(count, amiID, instanceType, keyName, securityGroupID, iops, throughput, volumeTypeString, volumeSize, opts)
+	runResult, err := c.ec2Client.RunInstances(c.ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	switch len(runResult.Instances) {
+	case 0:
+		return nil, fmt.Errorf("no instances created")
+	case count:
+		instanceIDs := utils.Map(runResult.Instances, func(instance types.Instance) string {
+			return *instance.InstanceId
+		})
+		return instanceIDs, nil
+	default:
+		return nil, fmt.Errorf("expected %d instances, got %d", count, len(runResult.Instances))
+	}
+}
+
+func runInstancesInput(
+	count int,
+	amiID,
+	instanceType,
+	keyName,
+	securityGroupID string,
+	iops,
+	throughput int,
+	volumeTypeString string,
+	volumeSize int,
+	opts EC2InstanceOptions,
+) *ec2.RunInstancesInput {
 	volumeType := types.VolumeType(volumeTypeString)
 	ebsValue := &types.EbsBlockDevice{
 		VolumeSize:          aws.Int32(int32(volumeSize)),
@@ -206,8 +297,13 @@ func (c *AwsCloud) CreateEC2Instances(count int, amiID, instanceType, keyName, s
 	if iops > 0 {
 		ebsValue.Iops = aws.Int32(int32(iops))
 	}
-
-	runResult, err := c.ec2Client.RunInstances(c.ctx, &ec2.RunInstancesInput{
+	if opts.EncryptVolume || opts.KMSKeyID != "" {
+		ebsValue.Encrypted = aws.Bool(true)
+	}
+	if opts.KMSKeyID != "" {
+		ebsValue.KmsKeyId = aws.String(opts.KMSKeyID)
+	}
+	input := &ec2.RunInstancesInput{
 		ImageId:          aws.String(amiID),
 		InstanceType:     types.InstanceType(instanceType),
 		KeyName:          aws.String(keyName),
@@ -235,20 +331,75 @@ func (c *AwsCloud) CreateEC2Instances(count int, amiID, instanceType, keyName, s
 				},
 			},
 		},
-	})
-	if err != nil {
-		return nil, err
 	}
-	switch len(runResult.Instances) {
-	case 0:
-		return nil, fmt.Errorf("no instances created")
-	case count:
-		instanceIDs := utils.Map(runResult.Instances, func(instance types.Instance) string {
-			return *instance.InstanceId
+	if opts.RequireIMDSv2 || opts.MetadataHopLimit > 0 {
+		input.MetadataOptions = &types.InstanceMetadataOptionsRequest{
+			HttpEndpoint: types.InstanceMetadataEndpointStateEnabled,
+		}
+		if opts.RequireIMDSv2 {
+			input.MetadataOptions.HttpTokens = types.HttpTokensStateRequired
+		}
+		if opts.MetadataHopLimit > 0 {
+			input.MetadataOptions.HttpPutResponseHopLimit = aws.Int32(opts.MetadataHopLimit)
+		}
+	}
+	if opts.DisablePublicIP {
+		// security groups have to be set on the interface when it is specified
+		input.SecurityGroupIds = nil
+		input.NetworkInterfaces = []types.InstanceNetworkInterfaceSpecification{
+			{
+				DeviceIndex:              aws.Int32(0),
+				AssociatePublicIpAddress: aws.Bool(false),
+				Groups:                   []string{securityGroupID},
+				DeleteOnTermination:      aws.Bool(true),
+			},
+		}
+	}
+	if opts.TerminationProtection {
+		input.DisableApiTermination = aws.Bool(true)
+	}
+	return input
+}
+
+// SetTerminationProtection enables or disables termination protection of an EC2 instance
+func (c *AwsCloud) SetTerminationProtection(instanceID string, enabled bool) error {
+	_, err := c.ec2Client.ModifyInstanceAttribute(c.ctx, &ec2.ModifyInstanceAttributeInput{
+		InstanceId:            aws.String(instanceID),
+		DisableApiTermination: &types.AttributeBooleanValue{Value: aws.Bool(enabled)},
+	})
+	return err
+}
+
+// GetInstanceHostKeys returns the SSH host public keys printed by cloud-init on the console
+// of [instanceID] at first boot, in authorized_keys format. As the console output is only
+// published some minutes after boot, it waits up to constants.CloudConsoleOutputTimeout
+// for the keys to show up
+func (c *AwsCloud) GetInstanceHostKeys(instanceID string) ([]string, error) {
+	deadline := time.Now().Add(constants.CloudConsoleOutputTimeout)
+	for {
+		output, err := c.ec2Client.GetConsoleOutput(c.ctx, &ec2.GetConsoleOutputInput{
+			InstanceId: aws.String(instanceID),
 		})
-		return instanceIDs, nil
-	default:
-		return nil, fmt.Errorf("expected %d instances, got %d", count, len(runResult.Instances))
+		if err != nil {
+			return nil, err
+		}
+		if output.Output != nil {
+			consoleOutput, err := base64.StdEncoding.DecodeString(*output.Output)
+			if err != nil {
+				return nil, err
+			}
+			if hostKeys := utils.ParseConsoleHostKeys(string(consoleOutput)); len(hostKeys) > 0 {
+				return hostKeys, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w of instance %s", ErrHostKeysNotFound, instanceID)
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
 	}
 }
 
@@ -496,13 +647,14 @@ func CheckIPInSg(sg *types.SecurityGroup, currentIP string, port int32) bool {
 		currentIP = fmt.Sprintf("%s/32", currentIP) // add netmask /32 if missing
 	}
 	for _, ipPermission := range sg.IpPermissions {
+		if !permissionIncludesPort(ipPermission, port) {
+			continue
+		}
 		for _, ipRange := range ipPermission.IpRanges {
 			cidr := *ipRange.CidrIp
 			switch {
 			case cidr == "0.0.0.0/0" || cidr == currentIP:
-				if ipPermission.FromPort != nil && *ipPermission.FromPort == port {
-					return true
-				}
+				return true
 			default:
 				_, ipNet, err := net.ParseCIDR(cidr)
 				if err != nil {
@@ -512,7 +664,7 @@ func CheckIPInSg(sg *types.SecurityGroup, currentIP string, port int32) bool {
 				if ip == nil {
 					continue
 				}
-				if ipNet.Contains(ip) && ipPermission.FromPort != nil && *ipPermission.FromPort == port {
+				if ipNet.Contains(ip) {
 					return true
 				}
 			}
@@ -521,6 +673,82 @@ func CheckIPInSg(sg *types.SecurityGroup, currentIP string, port int32) bool {
 	return false
 }
 
+// permissionIncludesPort checks if the TCP [port] is covered by the security group rule,
+// either as its port, inside its port range, or because the rule allows all traffic.
+// Rules for other protocols, eg. UDP, never cover it
+func permissionIncludesPort(ipPermission types.IpPermission, port int32) bool {
+	switch aws.ToString(ipPermission.IpProtocol) {
+	case "-1":
+		return true
+	case "tcp", "6":
+	default:
+		return false
+	}
+	if ipPermission.FromPort == nil {
+		return false
+	}
+	if ipPermission.ToPort != nil {
+		return *ipPermission.FromPort <= port && port <= *ipPermission.ToPort
+	}
+	return *ipPermission.FromPort == port
+}
+
+// GetSecurityGroup returns the security group with ID [sgID]
+func (c *AwsCloud) GetSecurityGroup(sgID string) (types.SecurityGroup, error) {
+	sg, err := c.ec2Client.DescribeSecurityGroups(c.ctx, &ec2.DescribeSecurityGroupsInput{
+		GroupIds: []string{sgID},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidGroup.NotFound") {
+			return types.SecurityGroup{}, fmt.Errorf("%w: %s", ErrSecurityGroupNotFound, sgID)
+		}
+		return types.SecurityGroup{}, err
+	}
+	if len(sg.SecurityGroups) == 0 {
+		return types.SecurityGroup{}, fmt.Errorf("%w: %s", ErrSecurityGroupNotFound, sgID)
+	}
+	return sg.SecurityGroups[0], nil
+}
+
+// CheckSecurityGroupPorts checks that the security group allows the inbound traffic an
+// avalanchego node requires: SSH from [sshSourceIP], and P2P from anywhere.
+// The SSH check is skipped if [sshSourceIP] is empty, eg. when connecting through a jump host
+func CheckSecurityGroupPorts(sg *types.SecurityGroup, sshSourceIP string) error {
+	closed := []string{}
+	if sshSourceIP != "" && !CheckIPInSg(sg, sshSourceIP, constants.SSHTCPPort) {
+		closed = append(closed, fmt.Sprintf("tcp %d from %s", constants.SSHTCPPort, sshSourceIP))
+	}
+	if !CheckIPInSg(sg, "0.0.0.0/0", constants.AvalanchegoP2PPort) {
+		closed = append(closed, fmt.Sprintf("tcp %d from 0.0.0.0/0", constants.AvalanchegoP2PPort))
+	}
+	if len(closed) > 0 {
+		return fmt.Errorf("%w %s: %s", ErrSecurityGroupPortsClosed, aws.ToString(sg.GroupId), strings.Join(closed, ", "))
+	}
+	return nil
+}
+
+// CheckExistingResources checks that the pre existing security group [sgID] and key pair
+// [keyPairName] can be used to create nodes: both must exist, and the security group must
+// allow the ports checked by CheckSecurityGroupPorts. Nothing is created or modified.
+// Returns the security group
+func (c *AwsCloud) CheckExistingResources(sgID, keyPairName, sshSourceIP string) (types.SecurityGroup, error) {
+	sg, err := c.GetSecurityGroup(sgID)
+	if err != nil {
+		return types.SecurityGroup{}, err
+	}
+	if err := CheckSecurityGroupPorts(&sg, sshSourceIP); err != nil {
+		return types.SecurityGroup{}, err
+	}
+	keyPairExists, err := c.CheckKeyPairExists(keyPairName)
+	if err != nil {
+		return types.SecurityGroup{}, err
+	}
+	if !keyPairExists {
+		return types.SecurityGroup{}, fmt.Errorf("%w: %s", ErrKeyPairNotFound, keyPairName)
+	}
+	return sg, nil
+}
+
 // CheckKeyPairExists checks if the specified key pair exists in the AWS Cloud.
 func (c *AwsCloud) CheckKeyPairExists(kpName string) (bool, error) {
 	keyPairInput := &ec2.DescribeKeyPairsInput{
@@ -592,8 +820,30 @@ func isEIPQuotaExceededError(err error) bool {
 	return err != nil && (utils.ContainsIgnoreCase(err.Error(), "limit exceeded") || utils.ContainsIgnoreCase(err.Error(), "elastic ip address limit exceeded"))
 }
 
+// instanceTypeInfo caches instance type queries, whose results don't change during the
+// lifetime of the process, by region and instance type
+var instanceTypeInfo = struct {
+	sync.Mutex
+	arch      map[string]string
+	supported map[string]bool
+}{
+	arch:      map[string]string{},
+	supported: map[string]bool{},
+}
+
+func (c *AwsCloud) instanceTypeKey(instanceType string) string {
+	return c.ec2Client.Options().Region + "/" + instanceType
+}
+
 // GetInstanceTypeArch returns the architecture of the given instance type.
 func (c *AwsCloud) GetInstanceTypeArch(instanceType string) (string, error) {
+	key := c.instanceTypeKey(instanceType)
+	instanceTypeInfo.Lock()
+	arch, ok := instanceTypeInfo.arch[key]
+	instanceTypeInfo.Unlock()
+	if ok {
+		return arch, nil
+	}
 	archOutput, err := c.ec2Client.DescribeInstanceTypes(c.ctx, &ec2.DescribeInstanceTypesInput{
 		InstanceTypes: []types.InstanceType{types.InstanceType(instanceType)},
 	})
@@ -603,25 +853,66 @@ func (c *AwsCloud) GetInstanceTypeArch(instanceType string) (string, error) {
 	if len(archOutput.InstanceTypes) == 0 {
 		return "", fmt.Errorf("no instance type found for %s", instanceType)
 	}
-	return string(archOutput.InstanceTypes[0].ProcessorInfo.SupportedArchitectures[0]), nil
+	arch = string(archOutput.InstanceTypes[0].ProcessorInfo.SupportedArchitectures[0])
+	instanceTypeInfo.Lock()
+	instanceTypeInfo.arch[key] = arch
+	instanceTypeInfo.Unlock()
+	return arch, nil
 }
 
-// IsInstanceTypeSupported checks if the given instance type is supported by the AWS cloud.
+// IsInstanceTypeSupported checks if the given instance type is offered in the region of the AWS cloud.
 func (c *AwsCloud) IsInstanceTypeSupported(instanceType string) (bool, error) {
-	var supportedInstanceTypes []string
-	paginator := ec2.NewDescribeInstanceTypesPaginator(c.ec2Client, &ec2.DescribeInstanceTypesInput{})
+	key := c.instanceTypeKey(instanceType)
+	instanceTypeInfo.Lock()
+	supported, ok := instanceTypeInfo.supported[key]
+	instanceTypeInfo.Unlock()
+	if ok {
+		return supported, nil
+	}
+	output, err := c.ec2Client.DescribeInstanceTypeOfferings(c.ctx, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: types.LocationTypeRegion,
+		Filters: []types.Filter{
+			{Name: aws.String("instance-type"), Values: []string{instanceType}},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	supported = len(output.InstanceTypeOfferings) > 0
+	instanceTypeInfo.Lock()
+	instanceTypeInfo.supported[key] = supported
+	instanceTypeInfo.Unlock()
+	return supported, nil
+}
 
+// ListInstanceTypes returns the instance types offered in the region of the AWS cloud
+// for architecture [arch]
+func (c *AwsCloud) ListInstanceTypes(arch string) ([]string, error) {
+	if !utils.ArchSupported(arch) {
+		return nil, fmt.Errorf("unsupported architecture: %s", arch)
+	}
+	instanceTypes := []string{}
+	paginator := ec2.NewDescribeInstanceTypesPaginator(c.ec2Client, &ec2.DescribeInstanceTypesInput{
+		Filters: []types.Filter{
+			{Name: aws.String("processor-info.supported-architecture"), Values: []string{arch}},
+		},
+	})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(c.ctx)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-
 		for _, it := range output.InstanceTypes {
-			supportedInstanceTypes = append(supportedInstanceTypes, string(it.InstanceType))
+			instanceTypes = append(instanceTypes, string(it.InstanceType))
 		}
 	}
-	return slices.Contains(supportedInstanceTypes, instanceType), nil
+	instanceTypeInfo.Lock()
+	for _, instanceType := range instanceTypes {
+		instanceTypeInfo.supported[c.instanceTypeKey(instanceType)] = true
+	}
+	instanceTypeInfo.Unlock()
+	sort.Strings(instanceTypes)
+	return instanceTypes, nil
 }
 
 // GetRootVolume returns a volume IDs attached to the given which is used as a root volume
@@ -824,6 +1115,11 @@ func (c *AwsCloud) AddMonitoringSecurityGroupRule(monitoringHostPublicIP, securi
 			return err
 		}
 	}
+	if !CheckIPInSg(&sg, monitoringHostPublicIP, constants.MetricsExporterPort) {
+		if err = c.AddSecurityGroupRule(*sg.GroupId, "ingress", "tcp", monitoringHostPublicIP+constants.IPAddressSuffix, constants.MetricsExporterPort); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
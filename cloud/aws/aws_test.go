@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCheckIPInSg tests the CheckIPInSg function
@@ -17,7 +19,8 @@ func TestCheckIPInSg(t *testing.T) {
 	sg := &types.SecurityGroup{
 		IpPermissions: []types.IpPermission{
 			{
-				FromPort: &port80,
+				IpProtocol: aws.String("tcp"),
+				FromPort:   &port80,
 				IpRanges: []types.IpRange{
 					{CidrIp: aws.String("192.168.1.0/24")},
 					{CidrIp: aws.String("10.0.0.0/16")},
@@ -25,13 +28,15 @@ func TestCheckIPInSg(t *testing.T) {
 				},
 			},
 			{
-				FromPort: &port443,
+				IpProtocol: aws.String("tcp"),
+				FromPort:   &port443,
 				IpRanges: []types.IpRange{
 					{CidrIp: aws.String("172.16.0.0/16")},
 				},
 			},
 			{
-				FromPort: &port22,
+				IpProtocol: aws.String("tcp"),
+				FromPort:   &port22,
 				IpRanges: []types.IpRange{
 					{CidrIp: aws.String("0.0.0.0/0")},
 				},
@@ -97,3 +102,105 @@ func TestCheckIPInSg(t *testing.T) {
 		t.Errorf("Expected both 1.1.1.1/32 IP addresses to match")
 	}
 }
+
+func TestCheckIPInSgPortRanges(t *testing.T) {
+	require := require.New(t)
+	sg := &types.SecurityGroup{
+		GroupId: aws.String("sg-1"),
+		IpPermissions: []types.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int32(9000),
+				ToPort:     aws.Int32(9700),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+			},
+			{
+				IpProtocol: aws.String("-1"),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String("10.0.0.0/8")}},
+			},
+		},
+	}
+	require.True(CheckIPInSg(sg, "1.2.3.4", 9651))
+	require.False(CheckIPInSg(sg, "1.2.3.4", 22))
+	// all traffic rule
+	require.True(CheckIPInSg(sg, "10.1.2.3", 22))
+
+	require.NoError(CheckSecurityGroupPorts(sg, "10.1.2.3"))
+	err := CheckSecurityGroupPorts(sg, "1.2.3.4")
+	require.ErrorIs(err, ErrSecurityGroupPortsClosed)
+	require.ErrorContains(err, "tcp 22 from 1.2.3.4")
+	require.NotContains(err.Error(), "9651")
+	// SSH source unknown, eg. behind a jump host
+	require.NoError(CheckSecurityGroupPorts(sg, ""))
+}
+
+func TestCheckIPInSgProtocol(t *testing.T) {
+	require := require.New(t)
+	sg := &types.SecurityGroup{
+		GroupId: aws.String("sg-1"),
+		IpPermissions: []types.IpPermission{
+			{
+				IpProtocol: aws.String("udp"),
+				FromPort:   aws.Int32(0),
+				ToPort:     aws.Int32(65535),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+			},
+			{
+				IpProtocol: aws.String("6"),
+				FromPort:   aws.Int32(22),
+				ToPort:     aws.Int32(22),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String("10.0.0.0/8")}},
+			},
+		},
+	}
+	require.False(CheckIPInSg(sg, "1.2.3.4", 9651))
+	require.False(CheckIPInSg(sg, "1.2.3.4", 22))
+	require.True(CheckIPInSg(sg, "10.1.2.3", 22))
+	require.ErrorIs(CheckSecurityGroupPorts(sg, "10.1.2.3"), ErrSecurityGroupPortsClosed)
+}
+
+func TestRunInstancesInputOptions(t *testing.T) {
+	require := require.New(t)
+	input := runInstancesInput(2, "ami-1", "c5.2xlarge", "kp", "sg-1", 1000, 500, "gp3", 1000, EC2InstanceOptions{})
+	require.Equal([]string{"sg-1"}, input.SecurityGroupIds)
+	require.Nil(input.MetadataOptions)
+	require.Nil(input.NetworkInterfaces)
+	require.Nil(input.DisableApiTermination)
+	require.Nil(input.BlockDeviceMappings[0].Ebs.Encrypted)
+	require.Equal(int32(500), *input.BlockDeviceMappings[0].Ebs.Throughput)
+
+	input = runInstancesInput(2, "ami-1", "c5.2xlarge", "kp", "sg-1", 1000, 500, "gp3", 1000, EC2InstanceOptions{
+		RequireIMDSv2:         true,
+		MetadataHopLimit:      2,
+		DisablePublicIP:       true,
+		KMSKeyID:              "arn:aws:kms:us-east-1:123:key/abc",
+		TerminationProtection: true,
+	})
+	require.Equal(types.HttpTokensStateRequired, input.MetadataOptions.HttpTokens)
+	require.Equal(int32(2), *input.MetadataOptions.HttpPutResponseHopLimit)
+	require.Empty(input.SecurityGroupIds)
+	require.Len(input.NetworkInterfaces, 1)
+	require.False(*input.NetworkInterfaces[0].AssociatePublicIpAddress)
+	require.Equal([]string{"sg-1"}, input.NetworkInterfaces[0].Groups)
+	require.True(*input.BlockDeviceMappings[0].Ebs.Encrypted)
+	require.Equal("arn:aws:kms:us-east-1:123:key/abc", *input.BlockDeviceMappings[0].Ebs.KmsKeyId)
+	require.True(*input.DisableApiTermination)
+}
+
+func TestRetryConfig(t *testing.T) {
+	require := require.New(t)
+	defer SetRetryConfig(RetryConfig{})
+
+	config := GetRetryConfig()
+	require.Equal(defaultRetryMaxAttempts, config.MaxAttempts)
+	require.Equal(defaultRetryMaxBackoff, config.MaxBackoff)
+	require.Equal(defaultRetryMaxAttempts, config.Retryer().MaxAttempts())
+
+	SetRetryConfig(RetryConfig{MaxAttempts: 3, Adaptive: true})
+	config = GetRetryConfig()
+	require.Equal(3, config.MaxAttempts)
+	require.Equal(defaultRetryMaxBackoff, config.MaxBackoff)
+	retryer := config.Retryer()
+	require.IsType(&retry.AdaptiveMode{}, retryer)
+	require.Equal(3, retryer.MaxAttempts())
+}
@@ -0,0 +1,344 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+// LoadBalancerType is the kind of AWS Elastic Load Balancer
+type LoadBalancerType string
+
+const (
+	NetworkLoadBalancer     LoadBalancerType = "network"
+	ApplicationLoadBalancer LoadBalancerType = "application"
+
+	// max length of load balancer and target group names on AWS
+	maxLoadBalancerNameLen  = 32
+	targetGroupNameSuffix   = "-tg"
+	defaultHealthCheckPath  = "/ext/health"
+	loadBalancerWaitTimeout = 10 * time.Minute
+)
+
+var (
+	ErrNoLoadBalancerTargets       = errors.New("at least one instance must be provided as load balancer target")
+	ErrInvalidLoadBalancerName     = errors.New("invalid load balancer name")
+	ErrALBSecurityGroupsNeeded     = errors.New("application load balancers require security groups")
+	ErrInstancesInDifferentVPCs    = errors.New("load balancer targets must belong to the same VPC")
+	ErrUnsupportedLoadBalancerType = errors.New("unsupported load balancer type")
+)
+
+// LoadBalancerParams describes a public RPC load balancer in front of API nodes
+type LoadBalancerParams struct {
+	// Name of the load balancer. The target group is named Name-tg
+	Name string
+	// Type of the load balancer. Defaults to NetworkLoadBalancer
+	Type LoadBalancerType
+	// InstanceIDs are the EC2 instances to balance
+	InstanceIDs []string
+	// TargetPort is the instance port traffic is forwarded to. Defaults to the avalanchego API port
+	TargetPort int32
+	// HealthCheckPath is the HTTP path used to health check targets. Defaults to /ext/health
+	HealthCheckPath string
+	// CertificateARN, if set, makes the listener terminate TLS on port 443 (HTTPS for ALB,
+	// TLS for NLB) using this ACM certificate. Otherwise the listener is plain on port 80
+	CertificateARN string
+	// SubnetIDs the load balancer is attached to. Defaults to the subnets of the instances.
+	// ALBs require subnets in at least two availability zones
+	SubnetIDs []string
+	// SecurityGroupIDs attached to the load balancer. Required for ALB, which must allow
+	// inbound traffic on the listener port
+	SecurityGroupIDs []string
+}
+
+// LoadBalancer is a provisioned AWS load balancer
+type LoadBalancer struct {
+	ARN            string
+	DNSName        string
+	TargetGroupARN string
+	ListenerARN    string
+}
+
+func (p *LoadBalancerParams) setDefaults() {
+	if p.Type == "" {
+		p.Type = NetworkLoadBalancer
+	}
+	if p.TargetPort == 0 {
+		p.TargetPort = constants.AvalanchegoAPIPort
+	}
+	if p.HealthCheckPath == "" {
+		p.HealthCheckPath = defaultHealthCheckPath
+	}
+}
+
+func (p LoadBalancerParams) validate() error {
+	if p.Name == "" || len(p.Name)+len(targetGroupNameSuffix) > maxLoadBalancerNameLen {
+		return fmt.Errorf("%w %q: must be non empty and at most %d characters", ErrInvalidLoadBalancerName, p.Name, maxLoadBalancerNameLen-len(targetGroupNameSuffix))
+	}
+	if len(p.InstanceIDs) == 0 {
+		return ErrNoLoadBalancerTargets
+	}
+	switch p.Type {
+	case NetworkLoadBalancer:
+	case ApplicationLoadBalancer:
+		if len(p.SecurityGroupIDs) == 0 {
+			return ErrALBSecurityGroupsNeeded
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedLoadBalancerType, p.Type)
+	}
+	return nil
+}
+
+// targetProtocol returns the protocol used between the load balancer and the targets
+func (p LoadBalancerParams) targetProtocol() elbtypes.ProtocolEnum {
+	if p.Type == ApplicationLoadBalancer {
+		return elbtypes.ProtocolEnumHttp
+	}
+	return elbtypes.ProtocolEnumTcp
+}
+
+// listenerConfig returns the protocol and port of the load balancer listener
+func (p LoadBalancerParams) listenerConfig() (elbtypes.ProtocolEnum, int32) {
+	switch {
+	case p.Type == ApplicationLoadBalancer && p.CertificateARN != "":
+		return elbtypes.ProtocolEnumHttps, constants.HTTPSPort
+	case p.Type == ApplicationLoadBalancer:
+		return elbtypes.ProtocolEnumHttp, constants.HTTPPort
+	case p.CertificateARN != "":
+		return elbtypes.ProtocolEnumTls, constants.HTTPSPort
+	default:
+		return elbtypes.ProtocolEnumTcp, constants.HTTPPort
+	}
+}
+
+// CreateLoadBalancer creates a load balancer with a target group health checked on
+// [params.HealthCheckPath], registers the instances on it and sets up the listener.
+// The instances security groups are updated to accept traffic on the target port from
+// inside the VPC. Waits for the load balancer to be available
+func (c *AwsCloud) CreateLoadBalancer(params LoadBalancerParams) (LoadBalancer, error) {
+	params.setDefaults()
+	if err := params.validate(); err != nil {
+		return LoadBalancer{}, err
+	}
+	vpcID, subnetIDs, securityGroupIDs, err := c.getInstancesNetworking(params.InstanceIDs)
+	if err != nil {
+		return LoadBalancer{}, err
+	}
+	if len(params.SubnetIDs) == 0 {
+		params.SubnetIDs = subnetIDs
+	}
+	if err := c.allowVPCTraffic(vpcID, securityGroupIDs, params.TargetPort); err != nil {
+		return LoadBalancer{}, err
+	}
+	targetGroupOutput, err := c.elbClient.CreateTargetGroup(c.ctx, &elb.CreateTargetGroupInput{
+		Name:                aws.String(params.Name + targetGroupNameSuffix),
+		Protocol:            params.targetProtocol(),
+		Port:                aws.Int32(params.TargetPort),
+		VpcId:               aws.String(vpcID),
+		TargetType:          elbtypes.TargetTypeEnumInstance,
+		HealthCheckEnabled:  aws.Bool(true),
+		HealthCheckProtocol: elbtypes.ProtocolEnumHttp,
+		HealthCheckPort:     aws.String("traffic-port"),
+		HealthCheckPath:     aws.String(params.HealthCheckPath),
+		Matcher:             &elbtypes.Matcher{HttpCode: aws.String("200")},
+	})
+	if err != nil {
+		return LoadBalancer{}, err
+	}
+	lb := LoadBalancer{
+		TargetGroupARN: aws.ToString(targetGroupOutput.TargetGroups[0].TargetGroupArn),
+	}
+	if params.Type == NetworkLoadBalancer {
+		// targets then see the NLB as source, so the VPC rule is enough for them to be reachable
+		if _, err := c.elbClient.ModifyTargetGroupAttributes(c.ctx, &elb.ModifyTargetGroupAttributesInput{
+			TargetGroupArn: aws.String(lb.TargetGroupARN),
+			Attributes: []elbtypes.TargetGroupAttribute{
+				{Key: aws.String("preserve_client_ip.enabled"), Value: aws.String("false")},
+			},
+		}); err != nil {
+			return lb, err
+		}
+	}
+	if err := c.RegisterLoadBalancerTargets(lb.TargetGroupARN, params.InstanceIDs, params.TargetPort); err != nil {
+		return lb, err
+	}
+	createInput := &elb.CreateLoadBalancerInput{
+		Name:    aws.String(params.Name),
+		Type:    elbtypes.LoadBalancerTypeEnum(params.Type),
+		Scheme:  elbtypes.LoadBalancerSchemeEnumInternetFacing,
+		Subnets: params.SubnetIDs,
+	}
+	if len(params.SecurityGroupIDs) > 0 {
+		createInput.SecurityGroups = params.SecurityGroupIDs
+	}
+	lbOutput, err := c.elbClient.CreateLoadBalancer(c.ctx, createInput)
+	if err != nil {
+		return lb, err
+	}
+	lb.ARN = aws.ToString(lbOutput.LoadBalancers[0].LoadBalancerArn)
+	lb.DNSName = aws.ToString(lbOutput.LoadBalancers[0].DNSName)
+	listenerProtocol, listenerPort := params.listenerConfig()
+	listenerInput := &elb.CreateListenerInput{
+		LoadBalancerArn: aws.String(lb.ARN),
+		Protocol:        listenerProtocol,
+		Port:            aws.Int32(listenerPort),
+		DefaultActions: []elbtypes.Action{
+			{
+				Type:           elbtypes.ActionTypeEnumForward,
+				TargetGroupArn: aws.String(lb.TargetGroupARN),
+			},
+		},
+	}
+	if params.CertificateARN != "" {
+		listenerInput.Certificates = []elbtypes.Certificate{{CertificateArn: aws.String(params.CertificateARN)}}
+	}
+	listenerOutput, err := c.elbClient.CreateListener(c.ctx, listenerInput)
+	if err != nil {
+		return lb, err
+	}
+	lb.ListenerARN = aws.ToString(listenerOutput.Listeners[0].ListenerArn)
+	waiter := elb.NewLoadBalancerAvailableWaiter(c.elbClient)
+	if err := waiter.Wait(c.ctx, &elb.DescribeLoadBalancersInput{LoadBalancerArns: []string{lb.ARN}}, loadBalancerWaitTimeout); err != nil {
+		return lb, err
+	}
+	return lb, nil
+}
+
+// DeleteLoadBalancer deletes the load balancer [lb] together with its listener and target group
+func (c *AwsCloud) DeleteLoadBalancer(lb LoadBalancer) error {
+	if lb.ARN != "" {
+		if _, err := c.elbClient.DeleteLoadBalancer(c.ctx, &elb.DeleteLoadBalancerInput{
+			LoadBalancerArn: aws.String(lb.ARN),
+		}); err != nil {
+			return err
+		}
+		waiter := elb.NewLoadBalancersDeletedWaiter(c.elbClient)
+		if err := waiter.Wait(c.ctx, &elb.DescribeLoadBalancersInput{LoadBalancerArns: []string{lb.ARN}}, loadBalancerWaitTimeout); err != nil {
+			return err
+		}
+	}
+	if lb.TargetGroupARN != "" {
+		if _, err := c.elbClient.DeleteTargetGroup(c.ctx, &elb.DeleteTargetGroupInput{
+			TargetGroupArn: aws.String(lb.TargetGroupARN),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterLoadBalancerTargets adds [instanceIDs] to the target group [targetGroupARN]
+func (c *AwsCloud) RegisterLoadBalancerTargets(targetGroupARN string, instanceIDs []string, port int32) error {
+	_, err := c.elbClient.RegisterTargets(c.ctx, &elb.RegisterTargetsInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+		Targets:        targetDescriptions(instanceIDs, port),
+	})
+	return err
+}
+
+// DeregisterLoadBalancerTargets removes [instanceIDs] from the target group [targetGroupARN]
+func (c *AwsCloud) DeregisterLoadBalancerTargets(targetGroupARN string, instanceIDs []string, port int32) error {
+	_, err := c.elbClient.DeregisterTargets(c.ctx, &elb.DeregisterTargetsInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+		Targets:        targetDescriptions(instanceIDs, port),
+	})
+	return err
+}
+
+// GetLoadBalancerTargetsHealth returns the health state of each target of [targetGroupARN]
+func (c *AwsCloud) GetLoadBalancerTargetsHealth(targetGroupARN string) (map[string]string, error) {
+	output, err := c.elbClient.DescribeTargetHealth(c.ctx, &elb.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+	})
+	if err != nil {
+		return nil, err
+	}
+	health := map[string]string{}
+	for _, description := range output.TargetHealthDescriptions {
+		if description.Target == nil || description.TargetHealth == nil {
+			continue
+		}
+		health[aws.ToString(description.Target.Id)] = string(description.TargetHealth.State)
+	}
+	return health, nil
+}
+
+func targetDescriptions(instanceIDs []string, port int32) []elbtypes.TargetDescription {
+	targets := make([]elbtypes.TargetDescription, 0, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		targets = append(targets, elbtypes.TargetDescription{
+			Id:   aws.String(instanceID),
+			Port: aws.Int32(port),
+		})
+	}
+	return targets
+}
+
+// getInstancesNetworking returns the VPC, the subnets and the security groups of [instanceIDs]
+func (c *AwsCloud) getInstancesNetworking(instanceIDs []string) (string, []string, []string, error) {
+	output, err := c.ec2Client.DescribeInstances(c.ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	vpcID := ""
+	subnetIDs := []string{}
+	securityGroupIDs := []string{}
+	for _, reservation := range output.Reservations {
+		for _, instance := range reservation.Instances {
+			instanceVpcID := aws.ToString(instance.VpcId)
+			if vpcID != "" && instanceVpcID != vpcID {
+				return "", nil, nil, ErrInstancesInDifferentVPCs
+			}
+			vpcID = instanceVpcID
+			subnetIDs = append(subnetIDs, aws.ToString(instance.SubnetId))
+			for _, sg := range instance.SecurityGroups {
+				securityGroupIDs = append(securityGroupIDs, aws.ToString(sg.GroupId))
+			}
+		}
+	}
+	return vpcID, utils.Unique(subnetIDs), utils.Unique(securityGroupIDs), nil
+}
+
+// allowVPCTraffic adds ingress rules on [port] from the CIDR of [vpcID] to [securityGroupIDs]
+func (c *AwsCloud) allowVPCTraffic(vpcID string, securityGroupIDs []string, port int32) error {
+	vpcOutput, err := c.ec2Client.DescribeVpcs(c.ctx, &ec2.DescribeVpcsInput{
+		VpcIds: []string{vpcID},
+	})
+	if err != nil {
+		return err
+	}
+	if len(vpcOutput.Vpcs) == 0 {
+		return fmt.Errorf("vpc %s not found", vpcID)
+	}
+	vpcCIDR := aws.ToString(vpcOutput.Vpcs[0].CidrBlock)
+	sgOutput, err := c.ec2Client.DescribeSecurityGroups(c.ctx, &ec2.DescribeSecurityGroupsInput{
+		GroupIds: securityGroupIDs,
+	})
+	if err != nil {
+		return err
+	}
+	for _, sg := range sgOutput.SecurityGroups {
+		sg := sg
+		if CheckIPInSg(&sg, vpcCIDR, port) {
+			continue
+		}
+		if err := c.AddSecurityGroupRule(aws.ToString(sg.GroupId), "ingress", "tcp", vpcCIDR, port); err != nil {
+			return fmt.Errorf("failure allowing port %d from %s on %s: %w", port, vpcCIDR, aws.ToString(sg.GroupId), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package aws
+
+import (
+	"testing"
+
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+)
+
+func TestLoadBalancerParams(t *testing.T) {
+	require := require.New(t)
+
+	params := LoadBalancerParams{Name: "rpc", InstanceIDs: []string{"i-1", "i-2"}}
+	params.setDefaults()
+	require.NoError(params.validate())
+	require.Equal(NetworkLoadBalancer, params.Type)
+	require.Equal(int32(constants.AvalanchegoAPIPort), params.TargetPort)
+	require.Equal(defaultHealthCheckPath, params.HealthCheckPath)
+	require.Equal(elbtypes.ProtocolEnumTcp, params.targetProtocol())
+	protocol, port := params.listenerConfig()
+	require.Equal(elbtypes.ProtocolEnumTcp, protocol)
+	require.Equal(int32(constants.HTTPPort), port)
+
+	params.CertificateARN = "arn:aws:acm:cert"
+	protocol, port = params.listenerConfig()
+	require.Equal(elbtypes.ProtocolEnumTls, protocol)
+	require.Equal(int32(constants.HTTPSPort), port)
+
+	params.Type = ApplicationLoadBalancer
+	require.ErrorIs(params.validate(), ErrALBSecurityGroupsNeeded)
+	params.SecurityGroupIDs = []string{"sg-1"}
+	require.NoError(params.validate())
+	require.Equal(elbtypes.ProtocolEnumHttp, params.targetProtocol())
+	protocol, _ = params.listenerConfig()
+	require.Equal(elbtypes.ProtocolEnumHttps, protocol)
+
+	params.Name = "a-very-long-load-balancer-name-xx"
+	require.ErrorIs(params.validate(), ErrInvalidLoadBalancerName)
+	params.Name = "rpc"
+	params.InstanceIDs = nil
+	require.ErrorIs(params.validate(), ErrNoLoadBalancerTargets)
+	params.InstanceIDs = []string{"i-1"}
+	params.Type = "classic"
+	require.ErrorIs(params.validate(), ErrUnsupportedLoadBalancerType)
+}
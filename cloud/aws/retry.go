@@ -0,0 +1,70 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+const (
+	defaultRetryMaxAttempts = 10
+	defaultRetryMaxBackoff  = 30 * time.Second
+)
+
+// RetryConfig configures how AWS API calls are retried on throttling and transient errors
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of each API call. Defaults to 10
+	MaxAttempts int
+
+	// MaxBackoff is the maximum delay between attempts. Defaults to 30 seconds
+	MaxBackoff time.Duration
+
+	// Adaptive enables client side rate limiting, so that calls are delayed while
+	// AWS is throttling requests instead of failing after MaxAttempts
+	Adaptive bool
+}
+
+var (
+	retryConfigLock sync.RWMutex
+	retryConfig     RetryConfig
+)
+
+// SetRetryConfig sets the retry configuration used by the AWS clients created after the call
+func SetRetryConfig(config RetryConfig) {
+	retryConfigLock.Lock()
+	defer retryConfigLock.Unlock()
+	retryConfig = config
+}
+
+// GetRetryConfig returns the retry configuration set with SetRetryConfig, with defaults applied
+func GetRetryConfig() RetryConfig {
+	retryConfigLock.RLock()
+	config := retryConfig
+	retryConfigLock.RUnlock()
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = defaultRetryMaxBackoff
+	}
+	return config
+}
+
+// Retryer returns a new retryer for the AWS SDK that follows the configuration
+func (c RetryConfig) Retryer() aws.Retryer {
+	standardOptions := func(o *retry.StandardOptions) {
+		o.MaxAttempts = c.MaxAttempts
+		o.MaxBackoff = c.MaxBackoff
+	}
+	if c.Adaptive {
+		return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+			o.StandardOptions = append(o.StandardOptions, standardOptions)
+		})
+	}
+	return retry.NewStandard(standardOptions)
+}
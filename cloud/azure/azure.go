@@ -0,0 +1,1075 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/proxy"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+const (
+	vnetAddressPrefix   = "10.0.0.0/16"
+	subnetAddressPrefix = "10.0.0.0/24"
+	defaultSubnetName   = "default"
+	// priorities of the rules added by AddSecurityGroupRule. Azure accepts 100 to 4096,
+	// lower values being evaluated first
+	minRulePriority  = 100
+	maxRulePriority  = 4096
+	rulePriorityStep = 10
+
+	powerStateRunning = "PowerState/running"
+	archX8664         = "x86_64"
+	archArm64         = "arm64"
+)
+
+var (
+	ErrNodeNotFoundToBeRunning  = errors.New("node not found to be running")
+	ErrSecurityGroupNotFound    = errors.New("network security group not found")
+	ErrKeyPairNotFound          = errors.New("SSH public key not found")
+	ErrSecurityGroupPortsClosed = errors.New("required ports are not open in network security group")
+	ErrHostKeysNotFound         = errors.New("SSH host keys not found in serial console output")
+	ErrInvalidImage             = errors.New("invalid Azure image")
+	ErrNoRulePriorityAvailable  = errors.New("no security rule priority available")
+)
+
+type AzureCloud struct {
+	ctx           context.Context
+	resourceGroup string
+	location      string
+
+	vmClient       *armcompute.VirtualMachinesClient
+	diskClient     *armcompute.DisksClient
+	skuClient      *armcompute.ResourceSKUsClient
+	sshKeyClient   *armcompute.SSHPublicKeysClient
+	nsgClient      *armnetwork.SecurityGroupsClient
+	ruleClient     *armnetwork.SecurityRulesClient
+	publicIPClient *armnetwork.PublicIPAddressesClient
+	nicClient      *armnetwork.InterfacesClient
+	vnetClient     *armnetwork.VirtualNetworksClient
+	httpClient     *http.Client
+}
+
+// VMOptions are the settings of the VMs created by CreateVMs
+type VMOptions struct {
+	// StaticPublicIP keeps the public IP of a VM when it is deleted, until it is released
+	// with ReleasePublicIP. Otherwise the IP is deleted together with the VM
+	StaticPublicIP bool
+	// DiskType is the managed disk storage account type, eg Premium_LRS. Defaults to
+	// Premium_LRS
+	DiskType string
+}
+
+// NewAzureCloud creates an Azure cloud managing the resources of [resourceGroup] in
+// [subscriptionID], creating them at [location]. The resource group must exist.
+// Credentials are obtained with azidentity.NewDefaultAzureCredential: env variables,
+// workload or managed identity, or the Azure CLI login. API calls go through the proxy
+// set with proxy.SetDefault, if any
+func NewAzureCloud(ctx context.Context, subscriptionID, resourceGroup, location string) (*AzureCloud, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	clientOptions := azcore.ClientOptions{}
+	httpClient := http.DefaultClient
+	if proxyConfig := proxy.Default(); proxyConfig.Enabled() {
+		var err error
+		httpClient, err = proxyConfig.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.Transport = httpClient
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: clientOptions})
+	if err != nil {
+		return nil, err
+	}
+	armOptions := &arm.ClientOptions{ClientOptions: clientOptions}
+	computeFactory, err := armcompute.NewClientFactory(subscriptionID, cred, armOptions)
+	if err != nil {
+		return nil, err
+	}
+	networkFactory, err := armnetwork.NewClientFactory(subscriptionID, cred, armOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureCloud{
+		ctx:            ctx,
+		resourceGroup:  resourceGroup,
+		location:       location,
+		vmClient:       computeFactory.NewVirtualMachinesClient(),
+		diskClient:     computeFactory.NewDisksClient(),
+		skuClient:      computeFactory.NewResourceSKUsClient(),
+		sshKeyClient:   computeFactory.NewSSHPublicKeysClient(),
+		nsgClient:      networkFactory.NewSecurityGroupsClient(),
+		ruleClient:     networkFactory.NewSecurityRulesClient(),
+		publicIPClient: networkFactory.NewPublicIPAddressesClient(),
+		nicClient:      networkFactory.NewInterfacesClient(),
+		vnetClient:     networkFactory.NewVirtualNetworksClient(),
+		httpClient:     httpClient,
+	}, nil
+}
+
+// isNotFoundError checks if [err] is an Azure resource not found error
+func isNotFoundError(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// SetupNetwork creates the virtual network [vnetName], with a single subnet, if it doesn't
+// exist. Returns the ID of the subnet
+func (c *AzureCloud) SetupNetwork(vnetName string) (string, error) {
+	vnet, err := c.vnetClient.Get(c.ctx, c.resourceGroup, vnetName, nil)
+	if err == nil {
+		for _, subnet := range vnet.Properties.Subnets {
+			if subnet.Name != nil && *subnet.Name == defaultSubnetName {
+				return *subnet.ID, nil
+			}
+		}
+		return "", fmt.Errorf("virtual network %s has no %s subnet", vnetName, defaultSubnetName)
+	}
+	if !isNotFoundError(err) {
+		return "", err
+	}
+	poller, err := c.vnetClient.BeginCreateOrUpdate(c.ctx, c.resourceGroup, vnetName, armnetwork.VirtualNetwork{
+		Location: to.Ptr(c.location),
+		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+			AddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr(vnetAddressPrefix)}},
+			Subnets: []*armnetwork.Subnet{{
+				Name:       to.Ptr(defaultSubnetName),
+				Properties: &armnetwork.SubnetPropertiesFormat{AddressPrefix: to.Ptr(subnetAddressPrefix)},
+			}},
+		},
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating virtual network %s: %w", vnetName, err)
+	}
+	created, err := poller.PollUntilDone(c.ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating virtual network %s: %w", vnetName, err)
+	}
+	if len(created.Properties.Subnets) == 0 {
+		return "", fmt.Errorf("virtual network %s was created without subnet", vnetName)
+	}
+	return *created.Properties.Subnets[0].ID, nil
+}
+
+// CreateSecurityGroup creates the network security group [nsgName]. Returns its ID
+func (c *AzureCloud) CreateSecurityGroup(nsgName string) (string, error) {
+	poller, err := c.nsgClient.BeginCreateOrUpdate(c.ctx, c.resourceGroup, nsgName, armnetwork.SecurityGroup{
+		Location: to.Ptr(c.location),
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	nsg, err := poller.PollUntilDone(c.ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return *nsg.ID, nil
+}
+
+// CheckSecurityGroupExists checks if the network security group [nsgName] exists, and returns it
+func (c *AzureCloud) CheckSecurityGroupExists(nsgName string) (bool, armnetwork.SecurityGroup, error) {
+	nsg, err := c.GetSecurityGroup(nsgName)
+	if errors.Is(err, ErrSecurityGroupNotFound) {
+		return false, armnetwork.SecurityGroup{}, nil
+	}
+	if err != nil {
+		return false, armnetwork.SecurityGroup{}, err
+	}
+	return true, nsg, nil
+}
+
+// GetSecurityGroup returns the network security group [nsgName]
+func (c *AzureCloud) GetSecurityGroup(nsgName string) (armnetwork.SecurityGroup, error) {
+	nsg, err := c.nsgClient.Get(c.ctx, c.resourceGroup, nsgName, nil)
+	if err != nil {
+		if isNotFoundError(err) {
+			return armnetwork.SecurityGroup{}, fmt.Errorf("%w: %s", ErrSecurityGroupNotFound, nsgName)
+		}
+		return armnetwork.SecurityGroup{}, err
+	}
+	return nsg.SecurityGroup, nil
+}
+
+// securityRuleName returns the name of the rule allowing [protocol] traffic on [port]
+// from/to [ip]. Azure rule names can't contain '/'
+func securityRuleName(direction, protocol, ip string, port int32) string {
+	return strings.NewReplacer("/", "_", ".", "-", ":", "-").
+		Replace(fmt.Sprintf("%s-%s-%d-%s", strings.ToLower(direction), strings.ToLower(protocol), port, ip))
+}
+
+// nextRulePriority returns the lowest priority multiple of rulePriorityStep not used by
+// the rules of [nsg] in [direction]
+func nextRulePriority(nsg *armnetwork.SecurityGroup, direction armnetwork.SecurityRuleDirection) (int32, error) {
+	used := map[int32]bool{}
+	if nsg.Properties != nil {
+		for _, rule := range nsg.Properties.SecurityRules {
+			if rule.Properties != nil && rule.Properties.Priority != nil && rule.Properties.Direction != nil &&
+				*rule.Properties.Direction == direction {
+				used[*rule.Properties.Priority] = true
+			}
+		}
+	}
+	for priority := int32(minRulePriority); priority <= maxRulePriority; priority += rulePriorityStep {
+		if !used[priority] {
+			return priority, nil
+		}
+	}
+	return 0, ErrNoRulePriorityAvailable
+}
+
+// ruleDirection returns the Azure rule direction for [direction], ingress or egress
+func ruleDirection(direction string) (armnetwork.SecurityRuleDirection, error) {
+	switch direction {
+	case "ingress":
+		return armnetwork.SecurityRuleDirectionInbound, nil
+	case "egress":
+		return armnetwork.SecurityRuleDirectionOutbound, nil
+	default:
+		return "", fmt.Errorf("invalid direction %s", direction)
+	}
+}
+
+// ruleProtocol returns the Azure rule protocol for [protocol], eg tcp
+func ruleProtocol(protocol string) armnetwork.SecurityRuleProtocol {
+	for _, p := range armnetwork.PossibleSecurityRuleProtocolValues() {
+		if strings.EqualFold(string(p), protocol) {
+			return p
+		}
+	}
+	return armnetwork.SecurityRuleProtocolAsterisk
+}
+
+// AddSecurityGroupRule adds a rule to the network security group [nsgName] allowing
+// [protocol] traffic on [port] from [ip] for ingress, or to [ip] for egress.
+// [direction] is either ingress or egress
+func (c *AzureCloud) AddSecurityGroupRule(nsgName, direction, protocol, ip string, port int32) error {
+	ruleDir, err := ruleDirection(direction)
+	if err != nil {
+		return err
+	}
+	nsg, err := c.GetSecurityGroup(nsgName)
+	if err != nil {
+		return err
+	}
+	if CheckIPInSg(&nsg, ip, port) && ruleDir == armnetwork.SecurityRuleDirectionInbound {
+		return nil
+	}
+	priority, err := nextRulePriority(&nsg, ruleDir)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(ip, "/") {
+		ip = fmt.Sprintf("%s/32", ip) // add netmask /32 if missing
+	}
+	properties := &armnetwork.SecurityRulePropertiesFormat{
+		Access:                   to.Ptr(armnetwork.SecurityRuleAccessAllow),
+		Direction:                to.Ptr(ruleDir),
+		Protocol:                 to.Ptr(ruleProtocol(protocol)),
+		Priority:                 to.Ptr(priority),
+		SourcePortRange:          to.Ptr("*"),
+		SourceAddressPrefix:      to.Ptr(ip),
+		DestinationAddressPrefix: to.Ptr("*"),
+		DestinationPortRange:     to.Ptr(fmt.Sprint(port)),
+	}
+	if ruleDir == armnetwork.SecurityRuleDirectionOutbound {
+		properties.SourceAddressPrefix = to.Ptr("*")
+		properties.DestinationAddressPrefix = to.Ptr(ip)
+	}
+	ruleName := securityRuleName(direction, protocol, ip, port)
+	poller, err := c.ruleClient.BeginCreateOrUpdate(c.ctx, c.resourceGroup, nsgName, ruleName, armnetwork.SecurityRule{
+		Properties: properties,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(c.ctx, nil)
+	return err
+}
+
+// DeleteSecurityGroupRule deletes the rule added by AddSecurityGroupRule with the same arguments
+func (c *AzureCloud) DeleteSecurityGroupRule(nsgName, direction, protocol, ip string, port int32) error {
+	if _, err := ruleDirection(direction); err != nil {
+		return err
+	}
+	if !strings.Contains(ip, "/") {
+		ip = fmt.Sprintf("%s/32", ip)
+	}
+	poller, err := c.ruleClient.BeginDelete(c.ctx, c.resourceGroup, nsgName, securityRuleName(direction, protocol, ip, port), nil)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = poller.PollUntilDone(c.ctx, nil)
+	return err
+}
+
+// SetupSecurityGroup sets up a network security group allowing SSH, avalanchego API
+// and monitoring from [ipAddress], and avalanchego P2P and Loki from anywhere
+func (c *AzureCloud) SetupSecurityGroup(ipAddress, nsgName string) (string, error) {
+	nsgID, err := c.CreateSecurityGroup(nsgName)
+	if err != nil {
+		return "", err
+	}
+	for _, port := range []int32{
+		constants.SSHTCPPort,
+		constants.AvalanchegoAPIPort,
+		constants.AvalanchegoMonitoringPort,
+		constants.AvalanchegoGrafanaPort,
+	} {
+		if err := c.AddSecurityGroupRule(nsgName, "ingress", "tcp", ipAddress, port); err != nil {
+			return "", err
+		}
+	}
+	for _, port := range []int32{constants.AvalanchegoLokiPort, constants.AvalanchegoP2PPort} {
+		if err := c.AddSecurityGroupRule(nsgName, "ingress", "tcp", "0.0.0.0/0", port); err != nil {
+			return "", err
+		}
+	}
+	return nsgID, nil
+}
+
+// CheckIPInSg checks if the inbound rules of [nsg] allow [currentIP] on TCP [port]
+func CheckIPInSg(nsg *armnetwork.SecurityGroup, currentIP string, port int32) bool {
+	if !strings.Contains(currentIP, "/") {
+		currentIP = fmt.Sprintf("%s/32", currentIP) // add netmask /32 if missing
+	}
+	if nsg.Properties == nil {
+		return false
+	}
+	ip := net.ParseIP(strings.Split(currentIP, "/")[0])
+	for _, rule := range nsg.Properties.SecurityRules {
+		properties := rule.Properties
+		if properties == nil || properties.Direction == nil || *properties.Direction != armnetwork.SecurityRuleDirectionInbound ||
+			properties.Access == nil || *properties.Access != armnetwork.SecurityRuleAccessAllow {
+			continue
+		}
+		if properties.Protocol != nil && *properties.Protocol != armnetwork.SecurityRuleProtocolTCP &&
+			*properties.Protocol != armnetwork.SecurityRuleProtocolAsterisk {
+			continue
+		}
+		if !ruleIncludesPort(properties, port) {
+			continue
+		}
+		prefixes := utils.Map(properties.SourceAddressPrefixes, func(p *string) string { return *p })
+		if properties.SourceAddressPrefix != nil {
+			prefixes = append(prefixes, *properties.SourceAddressPrefix)
+		}
+		for _, prefix := range prefixes {
+			switch {
+			case prefix == "*" || prefix == "Internet" || prefix == "0.0.0.0/0" || prefix == currentIP:
+				return true
+			default:
+				_, ipNet, err := net.ParseCIDR(prefix)
+				if err != nil || ip == nil {
+					continue
+				}
+				if ipNet.Contains(ip) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ruleIncludesPort checks if [port] is in the destination port ranges of the rule
+func ruleIncludesPort(properties *armnetwork.SecurityRulePropertiesFormat, port int32) bool {
+	ranges := utils.Map(properties.DestinationPortRanges, func(r *string) string { return *r })
+	if properties.DestinationPortRange != nil {
+		ranges = append(ranges, *properties.DestinationPortRange)
+	}
+	for _, portRange := range ranges {
+		if portRange == "*" {
+			return true
+		}
+		var from, until int32
+		if _, err := fmt.Sscanf(portRange, "%d-%d", &from, &until); err == nil {
+			if from <= port && port <= until {
+				return true
+			}
+			continue
+		}
+		if _, err := fmt.Sscanf(portRange, "%d", &from); err == nil && from == port {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSecurityGroupPorts checks that [nsg] allows the inbound traffic an avalanchego
+// node requires: SSH from [sshSourceIP], and P2P from anywhere. The SSH check is
+// skipped if [sshSourceIP] is empty, eg. when connecting through a jump host
+func CheckSecurityGroupPorts(nsg *armnetwork.SecurityGroup, sshSourceIP string) error {
+	closed := []string{}
+	if sshSourceIP != "" && !CheckIPInSg(nsg, sshSourceIP, constants.SSHTCPPort) {
+		closed = append(closed, fmt.Sprintf("tcp %d from %s", constants.SSHTCPPort, sshSourceIP))
+	}
+	if !CheckIPInSg(nsg, "0.0.0.0/0", constants.AvalanchegoP2PPort) {
+		closed = append(closed, fmt.Sprintf("tcp %d from 0.0.0.0/0", constants.AvalanchegoP2PPort))
+	}
+	if len(closed) > 0 {
+		nsgName := ""
+		if nsg.Name != nil {
+			nsgName = *nsg.Name
+		}
+		return fmt.Errorf("%w %s: %s", ErrSecurityGroupPortsClosed, nsgName, strings.Join(closed, ", "))
+	}
+	return nil
+}
+
+// CheckExistingResources checks that the pre existing network security group [nsgName]
+// and SSH public key [keyName] can be used to create nodes: both must exist, and the
+// security group must allow the ports checked by CheckSecurityGroupPorts. Nothing is
+// created or modified. Returns the network security group
+func (c *AzureCloud) CheckExistingResources(nsgName, keyName, sshSourceIP string) (armnetwork.SecurityGroup, error) {
+	nsg, err := c.GetSecurityGroup(nsgName)
+	if err != nil {
+		return armnetwork.SecurityGroup{}, err
+	}
+	if err := CheckSecurityGroupPorts(&nsg, sshSourceIP); err != nil {
+		return armnetwork.SecurityGroup{}, err
+	}
+	keyExists, err := c.CheckKeyPairExists(keyName)
+	if err != nil {
+		return armnetwork.SecurityGroup{}, err
+	}
+	if !keyExists {
+		return armnetwork.SecurityGroup{}, fmt.Errorf("%w: %s", ErrKeyPairNotFound, keyName)
+	}
+	return nsg, nil
+}
+
+// CheckKeyPairExists checks if the SSH public key resource [keyName] exists
+func (c *AzureCloud) CheckKeyPairExists(keyName string) (bool, error) {
+	if _, err := c.sshKeyClient.Get(c.ctx, c.resourceGroup, keyName, nil); err != nil {
+		if isNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getSSHPublicKey returns the public key stored in the SSH public key resource [keyName]
+func (c *AzureCloud) getSSHPublicKey(keyName string) (string, error) {
+	key, err := c.sshKeyClient.Get(c.ctx, c.resourceGroup, keyName, nil)
+	if err != nil {
+		if isNotFoundError(err) {
+			return "", fmt.Errorf("%w: %s", ErrKeyPairNotFound, keyName)
+		}
+		return "", err
+	}
+	if key.Properties == nil || key.Properties.PublicKey == nil {
+		return "", fmt.Errorf("%w: %s has no public key", ErrKeyPairNotFound, keyName)
+	}
+	return *key.Properties.PublicKey, nil
+}
+
+// UploadSSHPublicKey stores [publicKey] as the SSH public key resource [keyName]
+func (c *AzureCloud) UploadSSHPublicKey(keyName, publicKey string) error {
+	_, err := c.sshKeyClient.Create(c.ctx, c.resourceGroup, keyName, armcompute.SSHPublicKeyResource{
+		Location:   to.Ptr(c.location),
+		Properties: &armcompute.SSHPublicKeyResourceProperties{PublicKey: to.Ptr(strings.TrimSpace(publicKey))},
+	}, nil)
+	return err
+}
+
+// UploadSSHIdentityKeyPair stores the public key of the ssh-agent [identity] as the SSH
+// public key resource [keyName]
+func (c *AzureCloud) UploadSSHIdentityKeyPair(keyName string, identity string) error {
+	publicKey, err := utils.ReadSSHAgentIdentityPublicKey(identity)
+	if err != nil {
+		return err
+	}
+	return c.UploadSSHPublicKey(keyName, publicKey)
+}
+
+// CreateAndDownloadKeyPair creates the SSH public key resource [keyName] with a new key
+// pair generated by Azure, and writes the private key to [privateKeyFilePath]
+func (c *AzureCloud) CreateAndDownloadKeyPair(keyName string, privateKeyFilePath string) error {
+	if _, err := c.sshKeyClient.Create(c.ctx, c.resourceGroup, keyName, armcompute.SSHPublicKeyResource{
+		Location: to.Ptr(c.location),
+	}, nil); err != nil {
+		return err
+	}
+	keyPair, err := c.sshKeyClient.GenerateKeyPair(c.ctx, c.resourceGroup, keyName, nil)
+	if err != nil {
+		return err
+	}
+	if keyPair.PrivateKey == nil {
+		return fmt.Errorf("no private key generated for %s", keyName)
+	}
+	return os.WriteFile(privateKeyFilePath, []byte(*keyPair.PrivateKey), 0o600)
+}
+
+// GetUbuntuImageID returns the URN of the Canonical Ubuntu 22.04 LTS image for [arch].
+// Avalanche Tooling doesn't publish images on Azure, so avalanchego dependencies are
+// installed when the node is provisioned
+func GetUbuntuImageID(arch string) (string, error) {
+	switch arch {
+	case archX8664:
+		return "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest", nil
+	case archArm64:
+		return "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-arm64:latest", nil
+	default:
+		return "", fmt.Errorf("unsupported architecture: %s", arch)
+	}
+}
+
+// imageReference returns the image reference for [image], either an image resource ID,
+// or a publisher:offer:sku:version URN
+func imageReference(image string) (*armcompute.ImageReference, error) {
+	if strings.HasPrefix(image, "/subscriptions/") {
+		return &armcompute.ImageReference{ID: to.Ptr(image)}, nil
+	}
+	parts := strings.Split(image, ":")
+	if len(parts) != 4 || slices.Contains(parts, "") {
+		return nil, fmt.Errorf("%w %q: expected an image resource ID or a publisher:offer:sku:version URN", ErrInvalidImage, image)
+	}
+	return &armcompute.ImageReference{
+		Publisher: to.Ptr(parts[0]),
+		Offer:     to.Ptr(parts[1]),
+		SKU:       to.Ptr(parts[2]),
+		Version:   to.Ptr(parts[3]),
+	}, nil
+}
+
+// CreatePublicIP creates the static public IP [name]. If [keepOnVMDelete] is false, the IP
+// is deleted together with the VM it gets attached to. Returns its ID and address
+func (c *AzureCloud) CreatePublicIP(name string, keepOnVMDelete bool) (string, string, error) {
+	deleteOption := armnetwork.DeleteOptionsDelete
+	if keepOnVMDelete {
+		deleteOption = armnetwork.DeleteOptionsDetach
+	}
+	poller, err := c.publicIPClient.BeginCreateOrUpdate(c.ctx, c.resourceGroup, name, armnetwork.PublicIPAddress{
+		Location: to.Ptr(c.location),
+		SKU:      &armnetwork.PublicIPAddressSKU{Name: to.Ptr(armnetwork.PublicIPAddressSKUNameStandard)},
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+			DeleteOption:             to.Ptr(deleteOption),
+		},
+	}, nil)
+	if err != nil {
+		if isIPQuotaExceededError(err) {
+			return "", "", fmt.Errorf("public IP quota exceeded when creating %s: %w", name, err)
+		}
+		return "", "", err
+	}
+	publicIP, err := poller.PollUntilDone(c.ctx, nil)
+	if err != nil {
+		return "", "", err
+	}
+	return *publicIP.ID, *publicIP.Properties.IPAddress, nil
+}
+
+// isIPQuotaExceededError checks if error is a public IP quota error
+func isIPQuotaExceededError(err error) bool {
+	return strings.Contains(err.Error(), "PublicIPCountLimitReached") || strings.Contains(err.Error(), "QuotaExceeded")
+}
+
+// ReleasePublicIP deletes the public IP [name]. It must not be attached
+func (c *AzureCloud) ReleasePublicIP(name string) error {
+	poller, err := c.publicIPClient.BeginDelete(c.ctx, c.resourceGroup, name, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(c.ctx, nil)
+	return err
+}
+
+// createNIC creates the network interface of VM [vmName] in [subnetID], protected by
+// [nsgID] and with the public IP [publicIPID]
+func (c *AzureCloud) createNIC(vmName, subnetID, nsgID, publicIPID string) (string, error) {
+	poller, err := c.nicClient.BeginCreateOrUpdate(c.ctx, c.resourceGroup, vmName+"-nic", armnetwork.Interface{
+		Location: to.Ptr(c.location),
+		Properties: &armnetwork.InterfacePropertiesFormat{
+			NetworkSecurityGroup: &armnetwork.SecurityGroup{ID: to.Ptr(nsgID)},
+			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{{
+				Name: to.Ptr("ipconfig"),
+				Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+					PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodDynamic),
+					Subnet:                    &armnetwork.Subnet{ID: to.Ptr(subnetID)},
+					PublicIPAddress:           &armnetwork.PublicIPAddress{ID: to.Ptr(publicIPID)},
+				},
+			}},
+		},
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	nic, err := poller.PollUntilDone(c.ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return *nic.ID, nil
+}
+
+// CreateVMs creates [count] VMs of size [vmSize] from [image] (see imageReference), with a
+// [diskSizeGB] OS disk, in the subnet [subnetID] and protected by the network security
+// group [nsgID]. The SSH public key resource [keyName] is authorized for
+// constants.RemoteHostUser. Each VM gets a static public IP, kept after the VM deletion
+// if [opts.StaticPublicIP] is set. Returns the VM names. If any VM fails to be created,
+// the public IPs, network interfaces and VMs already created are deleted
+func (c *AzureCloud) CreateVMs(
+	count int,
+	image,
+	vmSize,
+	keyName,
+	nsgID,
+	subnetID string,
+	diskSizeGB int,
+	opts VMOptions,
+) ([]string, error) {
+	imageRef, err := imageReference(image)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := c.getSSHPublicKey(keyName)
+	if err != nil {
+		return nil, err
+	}
+	diskType := armcompute.StorageAccountTypesPremiumLRS
+	if opts.DiskType != "" {
+		diskType = armcompute.StorageAccountTypes(opts.DiskType)
+	}
+	prefix := utils.RandomString(5)
+	vmNames := make([]string, count)
+	created := make([]createdVMResources, count)
+	eg := &errgroup.Group{}
+	eg.SetLimit(8)
+	for i := 0; i < count; i++ {
+		i := i
+		vmName := fmt.Sprintf("%s-%d", prefix, i)
+		vmNames[i] = vmName
+		eg.Go(func() error {
+			publicIPID, _, err := c.CreatePublicIP(vmName+"-ip", opts.StaticPublicIP)
+			if err != nil {
+				return err
+			}
+			created[i].publicIP = true
+			nicID, err := c.createNIC(vmName, subnetID, nsgID, publicIPID)
+			if err != nil {
+				return fmt.Errorf("error creating network interface of %s: %w", vmName, err)
+			}
+			created[i].nic = true
+			poller, err := c.vmClient.BeginCreateOrUpdate(c.ctx, c.resourceGroup, vmName, armcompute.VirtualMachine{
+				Location: to.Ptr(c.location),
+				Tags:     map[string]*string{"managed-by": to.Ptr("avalanche-tooling-sdk-go")},
+				Properties: &armcompute.VirtualMachineProperties{
+					HardwareProfile: &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(vmSize))},
+					StorageProfile: &armcompute.StorageProfile{
+						ImageReference: imageRef,
+						OSDisk: &armcompute.OSDisk{
+							CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesFromImage),
+							DiskSizeGB:   to.Ptr(int32(diskSizeGB)),
+							ManagedDisk:  &armcompute.ManagedDiskParameters{StorageAccountType: to.Ptr(diskType)},
+							DeleteOption: to.Ptr(armcompute.DiskDeleteOptionTypesDelete),
+						},
+					},
+					OSProfile: &armcompute.OSProfile{
+						ComputerName:  to.Ptr(vmName),
+						AdminUsername: to.Ptr(constants.RemoteHostUser),
+						LinuxConfiguration: &armcompute.LinuxConfiguration{
+							DisablePasswordAuthentication: to.Ptr(true),
+							SSH: &armcompute.SSHConfiguration{PublicKeys: []*armcompute.SSHPublicKey{{
+								Path:    to.Ptr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", constants.RemoteHostUser)),
+								KeyData: to.Ptr(publicKey),
+							}}},
+						},
+					},
+					NetworkProfile: &armcompute.NetworkProfile{NetworkInterfaces: []*armcompute.NetworkInterfaceReference{{
+						ID: to.Ptr(nicID),
+						Properties: &armcompute.NetworkInterfaceReferenceProperties{
+							Primary:      to.Ptr(true),
+							DeleteOption: to.Ptr(armcompute.DeleteOptionsDelete),
+						},
+					}}},
+					// managed boot diagnostics keep the serial console log, read by GetInstanceHostKeys
+					DiagnosticsProfile: &armcompute.DiagnosticsProfile{
+						BootDiagnostics: &armcompute.BootDiagnostics{Enabled: to.Ptr(true)},
+					},
+				},
+			}, nil)
+			if err != nil {
+				return fmt.Errorf("error creating VM %s: %w", vmName, err)
+			}
+			created[i].vm = true
+			if _, err := poller.PollUntilDone(c.ctx, nil); err != nil {
+				return fmt.Errorf("error creating VM %s: %w", vmName, err)
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		for i, vmName := range vmNames {
+			err = errors.Join(err, c.deleteVMResources(vmName, created[i]))
+		}
+		return nil, err
+	}
+	return vmNames, nil
+}
+
+// createdVMResources are the resources of a VM created by CreateVMs
+type createdVMResources struct {
+	publicIP bool
+	nic      bool
+	vm       bool
+}
+
+// deleteVMResources deletes the [created] resources of VM [vmName], skipping the ones
+// already deleted together with the VM
+func (c *AzureCloud) deleteVMResources(vmName string, created createdVMResources) error {
+	if created.vm {
+		poller, err := c.vmClient.BeginDelete(c.ctx, c.resourceGroup, vmName, nil)
+		if err == nil {
+			_, err = poller.PollUntilDone(c.ctx, nil)
+		}
+		if err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("failure deleting VM %s: %w", vmName, err)
+		}
+	}
+	if created.nic {
+		poller, err := c.nicClient.BeginDelete(c.ctx, c.resourceGroup, vmName+"-nic", nil)
+		if err == nil {
+			_, err = poller.PollUntilDone(c.ctx, nil)
+		}
+		if err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("failure deleting network interface of %s: %w", vmName, err)
+		}
+	}
+	if created.publicIP {
+		if err := c.ReleasePublicIP(vmName + "-ip"); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("failure deleting public IP of %s: %w", vmName, err)
+		}
+	}
+	return nil
+}
+
+// getPowerState returns the power state of VM [vmName], eg PowerState/running
+func (c *AzureCloud) getPowerState(vmName string) (string, error) {
+	view, err := c.vmClient.InstanceView(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, status := range view.Statuses {
+		if status.Code != nil && strings.HasPrefix(*status.Code, "PowerState/") {
+			return *status.Code, nil
+		}
+	}
+	return "", nil
+}
+
+// WaitForVMs waits for the VMs [vmNames] to be running
+func (c *AzureCloud) WaitForVMs(vmNames []string) error {
+	deadline := time.Now().Add(constants.CloudOperationTimeout)
+	for {
+		running := 0
+		for _, vmName := range vmNames {
+			if state, err := c.getPowerState(vmName); err == nil && state == powerStateRunning {
+				running++
+			}
+		}
+		if running == len(vmNames) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for VMs to be running")
+		}
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// GetInstancePublicIPs returns a map from VM name to public IP
+func (c *AzureCloud) GetInstancePublicIPs(vmNames []string) (map[string]string, error) {
+	vmIPMap := map[string]string{}
+	for _, vmName := range vmNames {
+		nic, err := c.nicClient.Get(c.ctx, c.resourceGroup, vmName+"-nic", nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, ipConfig := range nic.Properties.IPConfigurations {
+			if ipConfig.Properties == nil || ipConfig.Properties.PublicIPAddress == nil || ipConfig.Properties.PublicIPAddress.ID == nil {
+				continue
+			}
+			publicIPName := getNameFromID(*ipConfig.Properties.PublicIPAddress.ID)
+			publicIP, err := c.publicIPClient.Get(c.ctx, c.resourceGroup, publicIPName, nil)
+			if err != nil {
+				return nil, err
+			}
+			if publicIP.Properties != nil && publicIP.Properties.IPAddress != nil {
+				vmIPMap[vmName] = *publicIP.Properties.IPAddress
+				break
+			}
+		}
+	}
+	return vmIPMap, nil
+}
+
+// getNameFromID returns the resource name at the end of an Azure resource [id]
+func getNameFromID(id string) string {
+	parts := strings.Split(id, "/")
+	return parts[len(parts)-1]
+}
+
+// GetInstanceHostKeys returns the SSH host public keys printed by cloud-init on the
+// serial console of [vmName] at first boot, in authorized_keys format. It waits up to
+// constants.CloudConsoleOutputTimeout for the keys to show up
+func (c *AzureCloud) GetInstanceHostKeys(vmName string) ([]string, error) {
+	deadline := time.Now().Add(constants.CloudConsoleOutputTimeout)
+	for {
+		hostKeys, err := c.readSerialConsoleHostKeys(vmName)
+		if err != nil {
+			return nil, err
+		}
+		if len(hostKeys) > 0 {
+			return hostKeys, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w of VM %s", ErrHostKeysNotFound, vmName)
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// readSerialConsoleHostKeys reads the host keys from the current serial console log of [vmName]
+func (c *AzureCloud) readSerialConsoleHostKeys(vmName string) ([]string, error) {
+	data, err := c.vmClient.RetrieveBootDiagnosticsData(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if data.SerialConsoleLogBlobURI == nil {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, *data.SerialConsoleLogBlobURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// the log blob is created some time after boot
+		return nil, nil
+	}
+	consoleOutput, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseConsoleHostKeys(string(consoleOutput)), nil
+}
+
+// checkInstanceIsRunning checks that VM [vmName] is running
+func (c *AzureCloud) checkInstanceIsRunning(vmName string) (bool, error) {
+	state, err := c.getPowerState(vmName)
+	if err != nil {
+		return false, err
+	}
+	return state == powerStateRunning, nil
+}
+
+// DestroyAzureNode deletes VM [vmName], with its OS disk and network interface. Its public
+// IP is also deleted unless it was created as a static IP, see VMOptions
+func (c *AzureCloud) DestroyAzureNode(vmName string) error {
+	isRunning, err := c.checkInstanceIsRunning(vmName)
+	if err != nil {
+		return err
+	}
+	if !isRunning {
+		return fmt.Errorf("%w: VM %s", ErrNodeNotFoundToBeRunning, vmName)
+	}
+	poller, err := c.vmClient.BeginDelete(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(c.ctx, nil)
+	return err
+}
+
+// GetRootVolumeID returns the name of the OS disk of VM [vmName]
+func (c *AzureCloud) GetRootVolumeID(vmName string) (string, error) {
+	vm, err := c.vmClient.Get(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return "", err
+	}
+	if vm.Properties == nil || vm.Properties.StorageProfile == nil || vm.Properties.StorageProfile.OSDisk == nil ||
+		vm.Properties.StorageProfile.OSDisk.Name == nil {
+		return "", fmt.Errorf("no root volume found for VM %s", vmName)
+	}
+	return *vm.Properties.StorageProfile.OSDisk.Name, nil
+}
+
+// ResizeVolume grows the managed disk [diskName] to [newSizeInGB]. Azure only resizes
+// attached OS disks without downtime on supported VM sizes, otherwise the VM must be
+// deallocated first
+func (c *AzureCloud) ResizeVolume(diskName string, newSizeInGB int32) error {
+	disk, err := c.diskClient.Get(c.ctx, c.resourceGroup, diskName, nil)
+	if err != nil {
+		return err
+	}
+	if disk.Properties != nil && disk.Properties.DiskSizeGB != nil && *disk.Properties.DiskSizeGB >= newSizeInGB {
+		return fmt.Errorf("new size %dGb must be greater than the current size %dGb", newSizeInGB, *disk.Properties.DiskSizeGB)
+	}
+	poller, err := c.diskClient.BeginUpdate(c.ctx, c.resourceGroup, diskName, armcompute.DiskUpdate{
+		Properties: &armcompute.DiskUpdateProperties{DiskSizeGB: to.Ptr(newSizeInGB)},
+	}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(c.ctx, nil)
+	return err
+}
+
+// listVMSKUs returns the VM sizes available at the cloud location
+func (c *AzureCloud) listVMSKUs() ([]*armcompute.ResourceSKU, error) {
+	skus := []*armcompute.ResourceSKU{}
+	pager := c.skuClient.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: to.Ptr(fmt.Sprintf("location eq '%s'", c.location)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType != nil && *sku.ResourceType == "virtualMachines" && !skuRestricted(sku) {
+				skus = append(skus, sku)
+			}
+		}
+	}
+	return skus, nil
+}
+
+// skuRestricted checks if [sku] can't be used by the subscription
+func skuRestricted(sku *armcompute.ResourceSKU) bool {
+	for _, restriction := range sku.Restrictions {
+		if restriction.Type != nil && *restriction.Type == armcompute.ResourceSKURestrictionsTypeLocation {
+			return true
+		}
+	}
+	return false
+}
+
+// skuArch returns the architecture of [sku], as named by utils.SupportedAvagoArch
+func skuArch(sku *armcompute.ResourceSKU) string {
+	for _, capability := range sku.Capabilities {
+		if capability.Name != nil && *capability.Name == "CpuArchitectureType" && capability.Value != nil &&
+			strings.EqualFold(*capability.Value, "Arm64") {
+			return archArm64
+		}
+	}
+	return archX8664
+}
+
+// GetInstanceTypeArch returns the architecture of VM size [vmSize]
+func (c *AzureCloud) GetInstanceTypeArch(vmSize string) (string, error) {
+	skus, err := c.listVMSKUs()
+	if err != nil {
+		return "", err
+	}
+	for _, sku := range skus {
+		if sku.Name != nil && strings.EqualFold(*sku.Name, vmSize) {
+			return skuArch(sku), nil
+		}
+	}
+	return "", fmt.Errorf("no VM size found for %s", vmSize)
+}
+
+// IsInstanceTypeSupported checks if VM size [vmSize] is available at the cloud location
+func (c *AzureCloud) IsInstanceTypeSupported(vmSize string) (bool, error) {
+	skus, err := c.listVMSKUs()
+	if err != nil {
+		return false, err
+	}
+	for _, sku := range skus {
+		if sku.Name != nil && strings.EqualFold(*sku.Name, vmSize) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListInstanceTypes returns the VM sizes of architecture [arch] available at the cloud location
+func (c *AzureCloud) ListInstanceTypes(arch string) ([]string, error) {
+	skus, err := c.listVMSKUs()
+	if err != nil {
+		return nil, err
+	}
+	vmSizes := []string{}
+	for _, sku := range skus {
+		if sku.Name != nil && skuArch(sku) == arch {
+			vmSizes = append(vmSizes, *sku.Name)
+		}
+	}
+	slices.Sort(vmSizes)
+	return vmSizes, nil
+}
+
+// ChangeInstanceType changes the size of VM [vmName], deallocating and starting it
+func (c *AzureCloud) ChangeInstanceType(vmName, vmSize string) error {
+	vm, err := c.vmClient.Get(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return err
+	}
+	if vm.Properties != nil && vm.Properties.HardwareProfile != nil && vm.Properties.HardwareProfile.VMSize != nil &&
+		strings.EqualFold(string(*vm.Properties.HardwareProfile.VMSize), vmSize) {
+		return fmt.Errorf("VM %s is already of size %s", vmName, vmSize)
+	}
+	deallocatePoller, err := c.vmClient.BeginDeallocate(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := deallocatePoller.PollUntilDone(c.ctx, nil); err != nil {
+		return err
+	}
+	updatePoller, err := c.vmClient.BeginUpdate(c.ctx, c.resourceGroup, vmName, armcompute.VirtualMachineUpdate{
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(vmSize))},
+		},
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := updatePoller.PollUntilDone(c.ctx, nil); err != nil {
+		return err
+	}
+	startPoller, err := c.vmClient.BeginStart(c.ctx, c.resourceGroup, vmName, nil)
+	if err != nil {
+		return err
+	}
+	_, err = startPoller.PollUntilDone(c.ctx, nil)
+	return err
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func inboundRule(priority int32, source string, ports ...string) *armnetwork.SecurityRule {
+	properties := &armnetwork.SecurityRulePropertiesFormat{
+		Access:              to.Ptr(armnetwork.SecurityRuleAccessAllow),
+		Direction:           to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+		Protocol:            to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+		Priority:            to.Ptr(priority),
+		SourceAddressPrefix: to.Ptr(source),
+	}
+	if len(ports) == 1 {
+		properties.DestinationPortRange = to.Ptr(ports[0])
+	} else {
+		properties.DestinationPortRanges = []*string{}
+		for _, port := range ports {
+			properties.DestinationPortRanges = append(properties.DestinationPortRanges, to.Ptr(port))
+		}
+	}
+	return &armnetwork.SecurityRule{Properties: properties}
+}
+
+func TestCheckIPInSg(t *testing.T) {
+	require := require.New(t)
+	nsg := &armnetwork.SecurityGroup{
+		Name: to.Ptr("nsg"),
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{
+			SecurityRules: []*armnetwork.SecurityRule{
+				inboundRule(100, "192.168.1.0/24", "22"),
+				inboundRule(110, "1.1.1.1/32", "9650", "3000-3100"),
+				inboundRule(120, "*", "9651"),
+			},
+		},
+	}
+	require.True(CheckIPInSg(nsg, "192.168.1.10", 22))
+	require.False(CheckIPInSg(nsg, "10.0.0.1", 22))
+	require.True(CheckIPInSg(nsg, "1.1.1.1", 9650))
+	require.True(CheckIPInSg(nsg, "1.1.1.1", 3050))
+	require.False(CheckIPInSg(nsg, "1.1.1.1", 22))
+	require.True(CheckIPInSg(nsg, "0.0.0.0/0", 9651))
+
+	require.NoError(CheckSecurityGroupPorts(nsg, "192.168.1.10"))
+	require.ErrorIs(CheckSecurityGroupPorts(nsg, "10.0.0.1"), ErrSecurityGroupPortsClosed)
+
+	priority, err := nextRulePriority(nsg, armnetwork.SecurityRuleDirectionInbound)
+	require.NoError(err)
+	require.Equal(int32(130), priority)
+	priority, err = nextRulePriority(nsg, armnetwork.SecurityRuleDirectionOutbound)
+	require.NoError(err)
+	require.Equal(int32(minRulePriority), priority)
+
+	require.Equal("ingress-tcp-22-1-2-3-4_32", securityRuleName("ingress", "tcp", "1.2.3.4/32", 22))
+}
+
+func TestImageReference(t *testing.T) {
+	require := require.New(t)
+	for _, arch := range []string{"x86_64", "arm64"} {
+		image, err := GetUbuntuImageID(arch)
+		require.NoError(err)
+		ref, err := imageReference(image)
+		require.NoError(err)
+		require.Equal("Canonical", *ref.Publisher)
+		require.Equal("latest", *ref.Version)
+	}
+	_, err := GetUbuntuImageID("riscv")
+	require.Error(err)
+
+	id := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/images/avalanche"
+	ref, err := imageReference(id)
+	require.NoError(err)
+	require.Equal(id, *ref.ID)
+	_, err = imageReference("Canonical:ubuntu")
+	require.ErrorIs(err, ErrInvalidImage)
+
+	require.Equal("arm64", skuArch(&armcompute.ResourceSKU{Capabilities: []*armcompute.ResourceSKUCapabilities{
+		{Name: to.Ptr("CpuArchitectureType"), Value: to.Ptr("Arm64")},
+	}}))
+	require.Equal("x86_64", skuArch(&armcompute.ResourceSKU{}))
+	require.Equal("vm-ip", getNameFromID("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/vm-ip"))
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	// DefaultTTL is the TTL in seconds used for node records
+	DefaultTTL = 300
+	// DefaultHealthCheckPath is the avalanchego health endpoint
+	DefaultHealthCheckPath = "/ext/health"
+)
+
+var (
+	ErrEmptyRecordName = errors.New("record name cannot be empty")
+	ErrNoTargets       = errors.New("at least one target must be provided")
+	ErrZoneNotFound    = errors.New("hosted zone not found")
+)
+
+// Target is one member of a weighted multi-node record (eg a node in an RPC pool)
+type Target struct {
+	// ID uniquely identifies the target inside the record set (eg the node cloud ID)
+	ID string
+	// IP is the public IP of the target
+	IP string
+	// Weight is the relative amount of traffic sent to the target
+	Weight int64
+	// HealthCheckPort, if not zero, enables an HTTP health check on the target
+	HealthCheckPort int32
+	// HealthCheckPath is the HTTP path checked. Defaults to DefaultHealthCheckPath
+	HealthCheckPath string
+}
+
+// Provider manages DNS records for provisioned nodes
+type Provider interface {
+	// UpsertARecord creates or updates A record [name] in [zone] to resolve to [ips]
+	UpsertARecord(zone string, name string, ips []string, ttl int64) error
+	// UpsertWeightedARecords creates or updates a weighted A record [name] in [zone],
+	// with one record per target
+	UpsertWeightedARecords(zone string, name string, targets []Target, ttl int64) error
+	// DeleteARecords deletes all A records [name] in [zone]
+	DeleteARecords(zone string, name string) error
+	// UpsertTXTRecord creates or updates TXT record [name] in [zone] with [values]
+	// (eg for ACME DNS-01 challenges)
+	UpsertTXTRecord(zone string, name string, values []string, ttl int64) error
+	// DeleteTXTRecords deletes all TXT records [name] in [zone]
+	DeleteTXTRecords(zone string, name string) error
+}
+
+// FQDN returns [name] as a fully qualified domain name, with trailing dot
+func FQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(name) + "."
+}
+
+// RecordName returns the record name for [host] under [domain] (eg rpc, mychain.example.com
+// -> rpc.mychain.example.com.)
+func RecordName(host string, domain string) string {
+	return FQDN(strings.TrimSuffix(host, ".") + "." + strings.TrimPrefix(domain, "."))
+}
+
+func validateRecord(name string, ips []string) error {
+	if name == "" {
+		return ErrEmptyRecordName
+	}
+	if len(ips) == 0 {
+		return ErrNoTargets
+	}
+	for _, ip := range ips {
+		if net.ParseIP(ip).To4() == nil {
+			return fmt.Errorf("invalid IPv4 address %q for record %s", ip, name)
+		}
+	}
+	return nil
+}
+
+func validateTargets(name string, targets []Target) error {
+	if len(targets) == 0 {
+		return ErrNoTargets
+	}
+	ips := []string{}
+	ids := map[string]struct{}{}
+	for _, target := range targets {
+		if target.ID == "" {
+			return fmt.Errorf("target with IP %s for record %s has empty ID", target.IP, name)
+		}
+		if _, ok := ids[target.ID]; ok {
+			return fmt.Errorf("duplicated target ID %s for record %s", target.ID, name)
+		}
+		ids[target.ID] = struct{}{}
+		if target.Weight < 0 || target.Weight > 255 {
+			return fmt.Errorf("target %s weight %d for record %s is out of range [0, 255]", target.ID, target.Weight, name)
+		}
+		ips = append(ips, target.IP)
+	}
+	return validateRecord(name, ips)
+}
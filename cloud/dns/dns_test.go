@@ -0,0 +1,159 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+)
+
+func TestRecordName(t *testing.T) {
+	require := require.New(t)
+	require.Equal("rpc.mychain.example.com.", RecordName("rpc", "mychain.example.com"))
+	require.Equal("rpc.mychain.example.com.", RecordName("RPC", ".mychain.example.com."))
+	require.Equal("example.com.", FQDN("example.com."))
+}
+
+func TestValidateTargets(t *testing.T) {
+	require := require.New(t)
+	require.ErrorIs(validateRecord("", []string{"1.2.3.4"}), ErrEmptyRecordName)
+	require.ErrorIs(validateTargets("rpc", nil), ErrNoTargets)
+	require.Error(validateRecord("rpc", []string{"not-an-ip"}))
+	require.Error(validateTargets("rpc", []Target{{ID: "a", IP: "1.2.3.4"}, {ID: "a", IP: "1.2.3.5"}}))
+	require.Error(validateTargets("rpc", []Target{{ID: "a", IP: "1.2.3.4", Weight: 256}}))
+	require.NoError(validateTargets("rpc", []Target{{ID: "a", IP: "1.2.3.4", Weight: 1}, {ID: "b", IP: "1.2.3.5", Weight: 1}}))
+}
+
+func TestWeightedARecordSet(t *testing.T) {
+	require := require.New(t)
+	recordSet := weightedARecordSet("rpc.example.com", Target{ID: "node1", IP: "1.2.3.4", Weight: 10}, "hc-1", 0)
+	require.Equal("rpc.example.com.", aws.ToString(recordSet.Name))
+	require.Equal(int64(DefaultTTL), aws.ToInt64(recordSet.TTL))
+	require.Equal("node1", aws.ToString(recordSet.SetIdentifier))
+	require.Equal(int64(10), aws.ToInt64(recordSet.Weight))
+	require.Equal("hc-1", aws.ToString(recordSet.HealthCheckId))
+	require.Len(recordSet.ResourceRecords, 1)
+	require.Equal("1.2.3.4", aws.ToString(recordSet.ResourceRecords[0].Value))
+}
+
+func TestTXTRecordSet(t *testing.T) {
+	require := require.New(t)
+	recordSet := txtRecordSet("_acme-challenge.rpc.example.com", []string{"token"}, 60)
+	require.Equal("_acme-challenge.rpc.example.com.", aws.ToString(recordSet.Name))
+	require.Equal(int64(60), aws.ToInt64(recordSet.TTL))
+	require.Equal(`"token"`, aws.ToString(recordSet.ResourceRecords[0].Value))
+}
+
+// fakeRoute53 keeps record sets and health checks in memory
+type fakeRoute53 struct {
+	recordSets   map[string]types.ResourceRecordSet
+	healthChecks map[string]types.HealthCheckConfig
+	nextID       int
+	changeErr    error
+}
+
+func newFakeRoute53() *fakeRoute53 {
+	return &fakeRoute53{
+		recordSets:   map[string]types.ResourceRecordSet{},
+		healthChecks: map[string]types.HealthCheckConfig{},
+	}
+}
+
+func (*fakeRoute53) GetChange(context.Context, *route53.GetChangeInput, ...func(*route53.Options)) (*route53.GetChangeOutput, error) {
+	return &route53.GetChangeOutput{ChangeInfo: &types.ChangeInfo{Status: types.ChangeStatusInsync}}, nil
+}
+
+func (*fakeRoute53) ListHostedZonesByName(context.Context, *route53.ListHostedZonesByNameInput, ...func(*route53.Options)) (*route53.ListHostedZonesByNameOutput, error) {
+	return &route53.ListHostedZonesByNameOutput{}, nil
+}
+
+func (f *fakeRoute53) ListResourceRecordSets(context.Context, *route53.ListResourceRecordSetsInput, ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
+	return &route53.ListResourceRecordSetsOutput{ResourceRecordSets: maps.Values(f.recordSets)}, nil
+}
+
+func (f *fakeRoute53) ChangeResourceRecordSets(_ context.Context, input *route53.ChangeResourceRecordSetsInput, _ ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error) {
+	if f.changeErr != nil {
+		return nil, f.changeErr
+	}
+	for _, change := range input.ChangeBatch.Changes {
+		key := aws.ToString(change.ResourceRecordSet.Name) + aws.ToString(change.ResourceRecordSet.SetIdentifier)
+		if change.Action == types.ChangeActionDelete {
+			delete(f.recordSets, key)
+		} else {
+			f.recordSets[key] = *change.ResourceRecordSet
+		}
+	}
+	return &route53.ChangeResourceRecordSetsOutput{ChangeInfo: &types.ChangeInfo{Id: aws.String("change")}}, nil
+}
+
+func (f *fakeRoute53) CreateHealthCheck(_ context.Context, input *route53.CreateHealthCheckInput, _ ...func(*route53.Options)) (*route53.CreateHealthCheckOutput, error) {
+	f.nextID++
+	id := fmt.Sprintf("hc-%d", f.nextID)
+	f.healthChecks[id] = *input.HealthCheckConfig
+	return &route53.CreateHealthCheckOutput{HealthCheck: &types.HealthCheck{Id: aws.String(id)}}, nil
+}
+
+func (f *fakeRoute53) GetHealthCheck(_ context.Context, input *route53.GetHealthCheckInput, _ ...func(*route53.Options)) (*route53.GetHealthCheckOutput, error) {
+	config, ok := f.healthChecks[aws.ToString(input.HealthCheckId)]
+	if !ok {
+		return nil, &types.NoSuchHealthCheck{}
+	}
+	return &route53.GetHealthCheckOutput{HealthCheck: &types.HealthCheck{Id: input.HealthCheckId, HealthCheckConfig: &config}}, nil
+}
+
+func (f *fakeRoute53) DeleteHealthCheck(_ context.Context, input *route53.DeleteHealthCheckInput, _ ...func(*route53.Options)) (*route53.DeleteHealthCheckOutput, error) {
+	delete(f.healthChecks, aws.ToString(input.HealthCheckId))
+	return &route53.DeleteHealthCheckOutput{}, nil
+}
+
+func TestUpsertWeightedARecordsHealthChecks(t *testing.T) {
+	require := require.New(t)
+	client := newFakeRoute53()
+	r := &Route53{client: client, ctx: context.Background()}
+	targets := []Target{
+		{ID: "node1", IP: "1.2.3.4", Weight: 1, HealthCheckPort: 9650},
+		{ID: "node2", IP: "1.2.3.5", Weight: 1, HealthCheckPort: 9650},
+	}
+	require.NoError(r.UpsertWeightedARecords("zone", "rpc.example.com", targets, 0))
+	require.Len(client.healthChecks, 2)
+
+	// same endpoints: the health checks are reused
+	require.NoError(r.UpsertWeightedARecords("zone", "rpc.example.com", targets, 0))
+	require.Len(client.healthChecks, 2)
+	require.Equal(2, client.nextID)
+
+	// a changed endpoint replaces its health check, and a removed one is deleted
+	targets[0].IP = "1.2.3.6"
+	targets[1].HealthCheckPort = 0
+	require.NoError(r.UpsertWeightedARecords("zone", "rpc.example.com", targets, 0))
+	require.Len(client.healthChecks, 1)
+	require.Contains(client.healthChecks, "hc-3")
+	require.Equal("hc-3", aws.ToString(client.recordSets["rpc.example.com.node1"].HealthCheckId))
+
+	// health checks created for a failed change are deleted
+	client.changeErr = errors.New("throttled")
+	targets[1].HealthCheckPort = 9650
+	require.ErrorIs(r.UpsertWeightedARecords("zone", "rpc.example.com", targets, 0), client.changeErr)
+	require.Len(client.healthChecks, 1)
+	require.Contains(client.healthChecks, "hc-3")
+
+	// records of targets no longer given are deleted with their health checks
+	client.changeErr = nil
+	require.NoError(r.UpsertWeightedARecords("zone", "rpc.example.com", targets, 0))
+	require.Len(client.recordSets, 2)
+	require.Len(client.healthChecks, 2)
+	require.NoError(r.UpsertWeightedARecords("zone", "rpc.example.com", targets[1:], 0))
+	require.Len(client.recordSets, 1)
+	require.Contains(client.recordSets, "rpc.example.com.node2")
+	require.Len(client.healthChecks, 1)
+	require.NotContains(client.healthChecks, "hc-3")
+}
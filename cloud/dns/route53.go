@@ -0,0 +1,384 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+
+	awsAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/aws"
+)
+
+const (
+	// route53 is a global service, with its API on us-east-1
+	route53Region        = "us-east-1"
+	route53ChangeTimeout = 5 * time.Minute
+	healthCheckInterval  = 30
+	healthCheckThreshold = 3
+	healthCheckRefPrefix = "avalanche-tooling-sdk"
+	changeBatchComment   = "managed by avalanche-tooling-sdk"
+)
+
+// route53API is the subset of the Route53 client used by Route53
+type route53API interface {
+	route53.GetChangeAPIClient
+	ListHostedZonesByName(context.Context, *route53.ListHostedZonesByNameInput, ...func(*route53.Options)) (*route53.ListHostedZonesByNameOutput, error)
+	ListResourceRecordSets(context.Context, *route53.ListResourceRecordSetsInput, ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error)
+	ChangeResourceRecordSets(context.Context, *route53.ChangeResourceRecordSetsInput, ...func(*route53.Options)) (*route53.ChangeResourceRecordSetsOutput, error)
+	CreateHealthCheck(context.Context, *route53.CreateHealthCheckInput, ...func(*route53.Options)) (*route53.CreateHealthCheckOutput, error)
+	GetHealthCheck(context.Context, *route53.GetHealthCheckInput, ...func(*route53.Options)) (*route53.GetHealthCheckOutput, error)
+	DeleteHealthCheck(context.Context, *route53.DeleteHealthCheckInput, ...func(*route53.Options)) (*route53.DeleteHealthCheckOutput, error)
+}
+
+// Route53 is a Provider backed by AWS Route53
+type Route53 struct {
+	client route53API
+	ctx    context.Context
+}
+
+var _ Provider = (*Route53)(nil)
+
+// NewRoute53 creates a Route53 DNS provider
+func NewRoute53(ctx context.Context, awsProfile string) (*Route53, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cfg, err := awsAPI.LoadConfig(ctx, awsProfile, route53Region)
+	if err != nil {
+		return nil, err
+	}
+	return &Route53{
+		client: route53.NewFromConfig(cfg),
+		ctx:    ctx,
+	}, nil
+}
+
+// GetHostedZoneID returns the ID of the public hosted zone for [domain]
+func (r *Route53) GetHostedZoneID(domain string) (string, error) {
+	domain = FQDN(domain)
+	output, err := r.client.ListHostedZonesByName(r.ctx, &route53.ListHostedZonesByNameInput{
+		DNSName: aws.String(domain),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, zone := range output.HostedZones {
+		if aws.ToString(zone.Name) != domain {
+			continue
+		}
+		if zone.Config != nil && zone.Config.PrivateZone {
+			continue
+		}
+		return strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/"), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrZoneNotFound, domain)
+}
+
+// UpsertARecord creates or updates A record [name] in hosted zone [zone] to resolve to [ips]
+func (r *Route53) UpsertARecord(zone string, name string, ips []string, ttl int64) error {
+	if err := validateRecord(name, ips); err != nil {
+		return err
+	}
+	return r.changeRecords(zone, []types.Change{
+		{
+			Action:            types.ChangeActionUpsert,
+			ResourceRecordSet: aRecordSet(name, ips, ttl),
+		},
+	})
+}
+
+// UpsertWeightedARecords creates or updates a weighted A record [name] in hosted zone [zone],
+// with one record per target. Targets with a HealthCheckPort get an HTTP health check
+// so that Route53 stops resolving to them when they are unhealthy. The health check
+// of a target already in the record is kept if its IP, port and path did not change.
+// Otherwise it is replaced, and the previous one deleted. Weighted records of targets
+// not in [targets] are deleted, together with their health checks
+func (r *Route53) UpsertWeightedARecords(zone string, name string, targets []Target, ttl int64) error {
+	if err := validateTargets(name, targets); err != nil {
+		return err
+	}
+	recordSets, err := r.listRecords(zone, name, types.RRTypeA)
+	if err != nil {
+		return err
+	}
+	previousHealthCheckIDs := map[string]string{}
+	for _, recordSet := range recordSets {
+		if recordSet.SetIdentifier != nil && recordSet.HealthCheckId != nil {
+			previousHealthCheckIDs[*recordSet.SetIdentifier] = *recordSet.HealthCheckId
+		}
+	}
+	created := []string{}
+	replaced := []string{}
+	changes := []types.Change{}
+	targetIDs := map[string]struct{}{}
+	for _, target := range targets {
+		targetIDs[target.ID] = struct{}{}
+	}
+	for _, recordSet := range recordSets {
+		if recordSet.SetIdentifier == nil {
+			continue
+		}
+		if _, ok := targetIDs[*recordSet.SetIdentifier]; ok {
+			continue
+		}
+		recordSet := recordSet
+		changes = append(changes, types.Change{
+			Action:            types.ChangeActionDelete,
+			ResourceRecordSet: &recordSet,
+		})
+		if recordSet.HealthCheckId != nil {
+			replaced = append(replaced, *recordSet.HealthCheckId)
+		}
+	}
+	for _, target := range targets {
+		previousHealthCheckID := previousHealthCheckIDs[target.ID]
+		healthCheckID, isNew, err := r.targetHealthCheck(target, previousHealthCheckID)
+		if err != nil {
+			return errors.Join(err, r.deleteHealthChecks(created))
+		}
+		if isNew {
+			created = append(created, healthCheckID)
+		}
+		if previousHealthCheckID != "" && previousHealthCheckID != healthCheckID {
+			replaced = append(replaced, previousHealthCheckID)
+		}
+		changes = append(changes, types.Change{
+			Action:            types.ChangeActionUpsert,
+			ResourceRecordSet: weightedARecordSet(name, target, healthCheckID, ttl),
+		})
+	}
+	if err := r.changeRecords(zone, changes); err != nil {
+		return errors.Join(err, r.deleteHealthChecks(created))
+	}
+	return r.deleteHealthChecks(replaced)
+}
+
+// targetHealthCheck returns the ID of the health check to associate to [target], if any,
+// reusing [previousHealthCheckID] if it checks the same endpoint. Returns true if the
+// health check was created
+func (r *Route53) targetHealthCheck(target Target, previousHealthCheckID string) (string, bool, error) {
+	if target.HealthCheckPort == 0 {
+		return "", false, nil
+	}
+	if previousHealthCheckID != "" {
+		matches, err := r.healthCheckMatches(previousHealthCheckID, target)
+		if err != nil {
+			return "", false, err
+		}
+		if matches {
+			return previousHealthCheckID, false, nil
+		}
+	}
+	healthCheckID, err := r.CreateHealthCheck(target.IP, target.HealthCheckPort, target.HealthCheckPath)
+	if err != nil {
+		return "", false, err
+	}
+	return healthCheckID, true, nil
+}
+
+// healthCheckMatches tells if health check [healthCheckID] exists and checks the
+// endpoint of [target]
+func (r *Route53) healthCheckMatches(healthCheckID string, target Target) (bool, error) {
+	output, err := r.client.GetHealthCheck(r.ctx, &route53.GetHealthCheckInput{
+		HealthCheckId: aws.String(healthCheckID),
+	})
+	if err != nil {
+		var notFound *types.NoSuchHealthCheck
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if output.HealthCheck == nil || output.HealthCheck.HealthCheckConfig == nil {
+		return false, nil
+	}
+	path := target.HealthCheckPath
+	if path == "" {
+		path = DefaultHealthCheckPath
+	}
+	config := output.HealthCheck.HealthCheckConfig
+	return config.Type == types.HealthCheckTypeHttp &&
+		aws.ToString(config.IPAddress) == target.IP &&
+		aws.ToInt32(config.Port) == target.HealthCheckPort &&
+		aws.ToString(config.ResourcePath) == path, nil
+}
+
+// DeleteARecords deletes all A records [name] in hosted zone [zone], including weighted ones,
+// together with their health checks
+func (r *Route53) DeleteARecords(zone string, name string) error {
+	healthCheckIDs, err := r.deleteRecords(zone, name, types.RRTypeA)
+	if err != nil {
+		return err
+	}
+	return r.deleteHealthChecks(healthCheckIDs)
+}
+
+// deleteHealthChecks deletes the health checks [healthCheckIDs]
+func (r *Route53) deleteHealthChecks(healthCheckIDs []string) error {
+	for _, healthCheckID := range healthCheckIDs {
+		if _, err := r.client.DeleteHealthCheck(r.ctx, &route53.DeleteHealthCheckInput{
+			HealthCheckId: aws.String(healthCheckID),
+		}); err != nil {
+			return fmt.Errorf("failure deleting health check %s: %w", healthCheckID, err)
+		}
+	}
+	return nil
+}
+
+// UpsertTXTRecord creates or updates TXT record [name] in hosted zone [zone] with [values]
+func (r *Route53) UpsertTXTRecord(zone string, name string, values []string, ttl int64) error {
+	if name == "" {
+		return ErrEmptyRecordName
+	}
+	if len(values) == 0 {
+		return ErrNoTargets
+	}
+	return r.changeRecords(zone, []types.Change{
+		{
+			Action:            types.ChangeActionUpsert,
+			ResourceRecordSet: txtRecordSet(name, values, ttl),
+		},
+	})
+}
+
+// DeleteTXTRecords deletes all TXT records [name] in hosted zone [zone]
+func (r *Route53) DeleteTXTRecords(zone string, name string) error {
+	_, err := r.deleteRecords(zone, name, types.RRTypeTxt)
+	return err
+}
+
+// deleteRecords deletes all records of type [recordType] named [name] in hosted zone [zone],
+// returning the IDs of the health checks that were associated to them
+func (r *Route53) deleteRecords(zone string, name string, recordType types.RRType) ([]string, error) {
+	recordSets, err := r.listRecords(zone, name, recordType)
+	if err != nil {
+		return nil, err
+	}
+	changes := []types.Change{}
+	healthCheckIDs := []string{}
+	for _, recordSet := range recordSets {
+		recordSet := recordSet
+		changes = append(changes, types.Change{
+			Action:            types.ChangeActionDelete,
+			ResourceRecordSet: &recordSet,
+		})
+		if recordSet.HealthCheckId != nil {
+			healthCheckIDs = append(healthCheckIDs, *recordSet.HealthCheckId)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return healthCheckIDs, r.changeRecords(zone, changes)
+}
+
+// listRecords returns the records of type [recordType] named [name] in hosted zone [zone]
+func (r *Route53) listRecords(zone string, name string, recordType types.RRType) ([]types.ResourceRecordSet, error) {
+	name = FQDN(name)
+	output, err := r.client.ListResourceRecordSets(r.ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zone),
+		StartRecordName: aws.String(name),
+		StartRecordType: recordType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordSets := []types.ResourceRecordSet{}
+	for _, recordSet := range output.ResourceRecordSets {
+		if FQDN(aws.ToString(recordSet.Name)) == name && recordSet.Type == recordType {
+			recordSets = append(recordSets, recordSet)
+		}
+	}
+	return recordSets, nil
+}
+
+// CreateHealthCheck creates an HTTP health check against [ip]:[port][path], returning its ID.
+// If [path] is empty, DefaultHealthCheckPath is used
+func (r *Route53) CreateHealthCheck(ip string, port int32, path string) (string, error) {
+	if path == "" {
+		path = DefaultHealthCheckPath
+	}
+	output, err := r.client.CreateHealthCheck(r.ctx, &route53.CreateHealthCheckInput{
+		CallerReference: aws.String(fmt.Sprintf("%s-%s-%d-%d", healthCheckRefPrefix, ip, port, time.Now().UnixNano())),
+		HealthCheckConfig: &types.HealthCheckConfig{
+			Type:             types.HealthCheckTypeHttp,
+			IPAddress:        aws.String(ip),
+			Port:             aws.Int32(port),
+			ResourcePath:     aws.String(path),
+			RequestInterval:  aws.Int32(healthCheckInterval),
+			FailureThreshold: aws.Int32(healthCheckThreshold),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.HealthCheck.Id), nil
+}
+
+// changeRecords applies [changes] to hosted zone [zone] and waits for them to be propagated
+func (r *Route53) changeRecords(zone string, changes []types.Change) error {
+	output, err := r.client.ChangeResourceRecordSets(r.ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zone),
+		ChangeBatch: &types.ChangeBatch{
+			Changes: changes,
+			Comment: aws.String(changeBatchComment),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	waiter := route53.NewResourceRecordSetsChangedWaiter(r.client)
+	return waiter.Wait(r.ctx, &route53.GetChangeInput{Id: output.ChangeInfo.Id}, route53ChangeTimeout)
+}
+
+func aRecordSet(name string, ips []string, ttl int64) *types.ResourceRecordSet {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	resourceRecords := make([]types.ResourceRecord, 0, len(ips))
+	for _, ip := range ips {
+		resourceRecords = append(resourceRecords, types.ResourceRecord{Value: aws.String(ip)})
+	}
+	return &types.ResourceRecordSet{
+		Name:            aws.String(FQDN(name)),
+		Type:            types.RRTypeA,
+		TTL:             aws.Int64(ttl),
+		ResourceRecords: resourceRecords,
+	}
+}
+
+func weightedARecordSet(name string, target Target, healthCheckID string, ttl int64) *types.ResourceRecordSet {
+	recordSet := aRecordSet(name, []string{target.IP}, ttl)
+	recordSet.SetIdentifier = aws.String(target.ID)
+	recordSet.Weight = aws.Int64(target.Weight)
+	if healthCheckID != "" {
+		recordSet.HealthCheckId = aws.String(healthCheckID)
+	}
+	return recordSet
+}
+
+func txtRecordSet(name string, values []string, ttl int64) *types.ResourceRecordSet {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	resourceRecords := make([]types.ResourceRecord, 0, len(values))
+	for _, value := range values {
+		// route53 expects TXT values to be quoted
+		resourceRecords = append(resourceRecords, types.ResourceRecord{Value: aws.String(strconv.Quote(value))})
+	}
+	return &types.ResourceRecordSet{
+		Name:            aws.String(FQDN(name)),
+		Type:            types.RRTypeTxt,
+		TTL:             aws.Int64(ttl),
+		ResourceRecords: resourceRecords,
+	}
+}
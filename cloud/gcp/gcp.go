@@ -28,7 +28,18 @@ const (
 	gcpRegionAPI  = "https://www.googleapis.com/compute/v1/projects/%s/regions/%s"
 )
 
-var ErrNodeNotFoundToBeRunning = errors.New("node not found to be running")
+var (
+	ErrNodeNotFoundToBeRunning = errors.New("node not found to be running")
+	ErrHostKeysNotFound        = errors.New("SSH host keys not found in serial port output")
+)
+
+const (
+	archX8664 = "x86_64"
+	archArm64 = "arm64"
+)
+
+// armMachineSeries are the GCP machine series with Arm processors
+var armMachineSeries = []string{"t2a", "c4a"}
 
 type GcpCloud struct {
 	gcpClient *compute.Service
@@ -148,19 +159,51 @@ func (c *GcpCloud) SetupNetwork(ipAddress, networkName string) (*compute.Network
 		[]string{strconv.Itoa(constants.AvalanchegoP2PPort), strconv.Itoa(constants.AvalanchegoLokiPort)}); err != nil {
 		return nil, err
 	}
-	if _, err := c.SetFirewallRule(ipAddress,
-		fmt.Sprintf("%s-%s", networkName, strings.ReplaceAll(ipAddress, ".", "")),
-		networkName,
-		[]string{
-			strconv.Itoa(constants.SSHTCPPort), strconv.Itoa(constants.AvalanchegoAPIPort),
-			strconv.Itoa(constants.AvalanchegoMonitoringPort), strconv.Itoa(constants.AvalanchegoGrafanaPort),
-		}); err != nil {
+	if _, err := c.SetFirewallRule(ipAddress, userFirewallName(networkName, ipAddress), networkName, userPorts()); err != nil {
 		return nil, err
 	}
 
 	return createdNetwork, nil
 }
 
+// userFirewallName is the name of the firewall rule of [networkName] allowing access from [ipAddress]
+func userFirewallName(networkName, ipAddress string) string {
+	return fmt.Sprintf("%s-%s", networkName, strings.ReplaceAll(ipAddress, ".", ""))
+}
+
+// userPorts are the ports only open to the user IP: SSH, avalanchego API and monitoring
+func userPorts() []string {
+	return []string{
+		strconv.Itoa(constants.SSHTCPPort), strconv.Itoa(constants.AvalanchegoAPIPort),
+		strconv.Itoa(constants.AvalanchegoMonitoringPort), strconv.Itoa(constants.AvalanchegoGrafanaPort),
+	}
+}
+
+// EnsureNetworkAccess makes [networkName] usable to create nodes from [ipAddress]: the
+// network is created with SetupNetwork if it doesn't exist, otherwise the firewall rule
+// allowing SSH, API and monitoring access from [ipAddress] is added if missing
+func (c *GcpCloud) EnsureNetworkAccess(ipAddress, networkName string) error {
+	networkExists, err := c.CheckNetworkExists(networkName)
+	if err != nil {
+		return err
+	}
+	if !networkExists {
+		_, err := c.SetupNetwork(ipAddress, networkName)
+		return err
+	}
+	firewallName := userFirewallName(networkName, ipAddress)
+	firewallExists, err := c.CheckFirewallExists(firewallName, false)
+	if err != nil {
+		return err
+	}
+	if !firewallExists {
+		if _, err := c.SetFirewallRule(ipAddress, firewallName, networkName, userPorts()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SetFirewallRule creates a new firewall rule in GCP
 func (c *GcpCloud) SetFirewallRule(ipAddress, firewallName, networkName string, ports []string) (*compute.Firewall, error) {
 	if !strings.Contains(ipAddress, "/") {
@@ -283,7 +326,7 @@ func (c *GcpCloud) SetupInstances(
 					"managed-by": "avalanche-cli",
 				},
 			}
-			if staticIP != nil {
+			if len(staticIP) > 0 {
 				instance.NetworkInterfaces[0].AccessConfigs[0].NatIP = staticIP[currentIndex]
 			}
 			insertOp, err := c.gcpClient.Instances.Insert(c.projectID, zone, instance).Do()
@@ -328,19 +371,69 @@ func (c *GcpCloud) SetupInstances(
 // that an Avalanche Node requires (AvalancheGo, gcc, go, etc), thereby decreasing in massive
 // reduction in the time required to provision a node.
 func (c *GcpCloud) GetAvalancheUbuntuAMIID() (string, error) {
-	imageListCall := c.gcpClient.Images.List(constants.GCPDefaultImageProvider).Filter(constants.GCPImageFilter)
+	return c.GetAvalancheUbuntuImageID(archX8664)
+}
+
+// GetAvalancheUbuntuImageID returns the name of the latest non deprecated Machine Image
+// published by Avalanche Tooling on GCP for [arch]. MachineTypeArch gives the
+// architecture of a machine type
+func (c *GcpCloud) GetAvalancheUbuntuImageID(arch string) (string, error) {
+	if !utils.ArchSupported(arch) {
+		return "", fmt.Errorf("unsupported architecture: %s", arch)
+	}
+	imageListCall := c.gcpClient.Images.List(constants.GCPDefaultImageProvider).Filter(imageFilter(arch))
 	imageList, err := imageListCall.Do()
 	if err != nil {
 		return "", err
 	}
-	imageID := ""
 	for _, image := range imageList.Items {
 		if image.Deprecated == nil {
-			imageID = image.Name
-			break
+			return image.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no %s image found in %s", arch, constants.GCPDefaultImageProvider)
+}
+
+// imageFilter returns the images list filter for the Avalanche Tooling image family of [arch]
+func imageFilter(arch string) string {
+	return fmt.Sprintf("family=%s AND architecture=%s", constants.GCPImageFamily, arch)
+}
+
+// MachineTypeArch returns the architecture of GCP [machineType], as named by
+// utils.SupportedAvagoArch
+func MachineTypeArch(machineType string) string {
+	series := strings.Split(machineType, "-")[0]
+	if slices.Contains(armMachineSeries, series) {
+		return archArm64
+	}
+	return archX8664
+}
+
+// WaitForInstances waits for the instances [instanceNames] in [zone] to have [status], eg. RUNNING
+func (c *GcpCloud) WaitForInstances(zone string, instanceNames []string, status string) error {
+	deadline := time.Now().Add(constants.CloudOperationTimeout)
+	for {
+		instancesList, err := c.gcpClient.Instances.List(c.projectID, zone).Do()
+		if err == nil {
+			inStatus := 0
+			for _, instance := range instancesList.Items {
+				if slices.Contains(instanceNames, instance.Name) && instance.Status == status {
+					inStatus++
+				}
+			}
+			if inStatus == len(instanceNames) {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for instances to be in %s status", status)
+		}
+		select {
+		case <-c.ctx.Done():
+			return fmt.Errorf("operation canceled")
+		case <-time.After(1 * time.Second):
 		}
 	}
-	return imageID, nil
 }
 
 // CheckFirewallExists checks that firewall firewallName exists in GCP project projectName
@@ -400,6 +493,30 @@ func (c *GcpCloud) GetInstancePublicIPs(zone string, nodeIDs []string) (map[stri
 	return instanceIDToIP, nil
 }
 
+// GetInstanceHostKeys returns the SSH host public keys printed by cloud-init on the serial
+// port of [instanceName] at first boot, in authorized_keys format. It waits up to
+// constants.CloudConsoleOutputTimeout for the keys to show up
+func (c *GcpCloud) GetInstanceHostKeys(zone, instanceName string) ([]string, error) {
+	deadline := time.Now().Add(constants.CloudConsoleOutputTimeout)
+	for {
+		output, err := c.gcpClient.Instances.GetSerialPortOutput(c.projectID, zone, instanceName).Do()
+		if err != nil {
+			return nil, err
+		}
+		if hostKeys := utils.ParseConsoleHostKeys(output.Contents); len(hostKeys) > 0 {
+			return hostKeys, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w of instance %s", ErrHostKeysNotFound, instanceName)
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil, fmt.Errorf("operation canceled")
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
 // checkInstanceIsRunning checks that GCP instance nodeID is running in GCP
 func (c *GcpCloud) checkInstanceIsRunning(zone, nodeID string) (bool, error) {
 	if zone == "" || nodeID == "" {
@@ -416,16 +533,16 @@ func (c *GcpCloud) checkInstanceIsRunning(zone, nodeID string) (bool, error) {
 	return true, nil
 }
 
-// DestroyGCPNode terminates GCP node in GCP
-func (c *GcpCloud) DestroyGCPNode(nodeRegion string, nodeID string) error {
-	isRunning, err := c.checkInstanceIsRunning(nodeRegion, nodeID)
+// DestroyGCPNode terminates GCP node [nodeID] in [zone]
+func (c *GcpCloud) DestroyGCPNode(zone string, nodeID string) error {
+	isRunning, err := c.checkInstanceIsRunning(zone, nodeID)
 	if err != nil {
 		return err
 	}
 	if !isRunning {
 		return fmt.Errorf("%w: instance %s", ErrNodeNotFoundToBeRunning, nodeID)
 	}
-	instancesStopCall := c.gcpClient.Instances.Delete(c.projectID, nodeRegion, nodeID)
+	instancesStopCall := c.gcpClient.Instances.Delete(c.projectID, zone, nodeID)
 	if _, err = instancesStopCall.Do(); err != nil {
 		return err
 	}
@@ -0,0 +1,33 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package gcp
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMachineTypeArch(t *testing.T) {
+	require := require.New(t)
+	for machineType, arch := range map[string]string{
+		"e2-standard-8":  "x86_64",
+		"n2-highmem-4":   "x86_64",
+		"t2a-standard-8": "arm64",
+		"c4a-highcpu-16": "arm64",
+	} {
+		require.Equal(arch, MachineTypeArch(machineType), machineType)
+		require.True(utils.ArchSupported(MachineTypeArch(machineType)))
+	}
+	require.Equal(constants.GCPImageFilter, imageFilter("x86_64"))
+}
+
+func TestZoneToRegion(t *testing.T) {
+	require := require.New(t)
+	require.Equal("us-east1", zoneToRegion("us-east1-b"))
+	require.Equal("europe-west4", zoneToRegion("europe-west4-a"))
+	require.Equal("", zoneToRegion("invalid"))
+	require.Equal("avalanche-1234", userFirewallName("avalanche", "1.2.3.4"))
+}
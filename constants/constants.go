@@ -12,14 +12,21 @@ const (
 	// clouds
 	CloudOperationTimeout  = 2 * time.Minute
 	CloudServerStorageSize = 1000
+	// CloudConsoleOutputTimeout is how long the console output of a new instance can take to
+	// show the cloud-init SSH host keys
+	CloudConsoleOutputTimeout = 10 * time.Minute
 
 	AWSCloudServerRunningState = "running"
 	AWSDefaultInstanceType     = "c5.2xlarge"
 	AWSNodeIDPrefix            = "aws_node"
 
+	AzureDefaultInstanceType = "Standard_F8s_v2"
+	AzureSubscriptionEnvVar  = "AZURE_SUBSCRIPTION_ID"
+
 	GCPDefaultImageProvider = "avalabs-experimental"
 	GCPDefaultInstanceType  = "e2-standard-8"
 	GCPImageFilter          = "family=avalanchecli-ubuntu-2204 AND architecture=x86_64"
+	GCPImageFamily          = "avalanchecli-ubuntu-2204"
 	GCPEnvVar               = "GOOGLE_APPLICATION_CREDENTIALS"
 	GCPDefaultAuthKeyPath   = "~/.config/gcloud/application_default_credentials.json"
 	GCPStaticIPPrefix       = "static-ip"
@@ -35,6 +42,9 @@ const (
 	AvalanchegoMonitoringPort     = 9090
 	AvalanchegoMachineMetricsPort = 9100
 	AvalanchegoLoadTestPort       = 8082
+	MetricsExporterPort           = 9200
+	HTTPPort                      = 80
+	HTTPSPort                     = 443
 
 	// http
 	APIRequestTimeout      = 30 * time.Second
@@ -52,6 +62,8 @@ const (
 	CloudNodeCLIConfigBasePath = "/home/ubuntu/.avalanche-cli/"
 	CloudNodeStakingPath       = "/home/ubuntu/.avalanchego/staking/"
 	CloudNodeConfigPath        = "/home/ubuntu/.avalanchego/configs/"
+	CloudNodePluginsPath       = "/home/ubuntu/.avalanchego/plugins/"
+	CloudNodeLogsPath          = "/home/ubuntu/.avalanchego/logs/"
 	ServicesDir                = "services"
 	DashboardsDir              = "dashboards"
 	// services
@@ -61,6 +73,9 @@ const (
 	ServicePrometheus  = "prometheus"
 	ServiceLoki        = "loki"
 	ServiceAWMRelayer  = "awm-relayer"
+	ServiceNginx       = "nginx"
+	// ServiceMetricsExporter serves the business metrics computed by the SDK
+	ServiceMetricsExporter = "metrics-exporter"
 
 	// misc
 	DefaultPerms755        = 0o755
@@ -0,0 +1,226 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package devtools contains helpers to set up devnets and test L1s
+package devtools
+
+import (
+	_ "embed"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/key"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// faucetBytecode is the creation code of the faucet contract, hex encoded.
+//
+// The contract stores the owner (the deployer), the drip amount and the cooldown,
+// and keeps the timestamp of the last drip of each recipient. Its interface is:
+//
+//	constructor(uint256 dripAmount, uint256 cooldown)
+//	fund() payable, also used for plain transfers
+//	drip(address recipient): sends dripAmount to recipient, reverts if the
+//	    recipient got a drip less than cooldown seconds ago
+//	dripAmount() view returns (uint256)
+//	cooldown() view returns (uint256)
+//	lastDrip(address recipient) view returns (uint256)
+//	owner() view returns (address)
+//	setParams(uint256 dripAmount, uint256 cooldown): owner only
+//	withdraw(uint256 amount): owner only
+//	event Drip(address indexed recipient, uint256 amount)
+//
+// The bytecode is hand written, so it has no solc dependency. TestFaucetContract
+// exercises it on an in memory EVM.
+//
+//go:embed faucet.bin
+var faucetBytecode []byte
+
+// FaucetParams are the rate limiting parameters of a faucet contract
+type FaucetParams struct {
+	// DripAmount is the amount, in wei, sent on each drip
+	DripAmount *big.Int
+	// Cooldown is the time a recipient has to wait between drips
+	Cooldown time.Duration
+}
+
+func (p FaucetParams) validate() error {
+	if p.DripAmount == nil || p.DripAmount.Sign() <= 0 {
+		return fmt.Errorf("faucet drip amount must be positive")
+	}
+	if p.Cooldown < 0 {
+		return fmt.Errorf("faucet cooldown can't be negative")
+	}
+	return nil
+}
+
+// DeployFaucet deploys a faucet contract with [params] on the EVM chain at [rpcURL],
+// paid and owned by [privateKey], and funds it with [fundAmount] wei taken from
+// the same key. A nil or zero [fundAmount] skips funding
+func DeployFaucet(
+	rpcURL string,
+	privateKey string,
+	params FaucetParams,
+	fundAmount *big.Int,
+) (common.Address, error) {
+	if err := params.validate(); err != nil {
+		return common.Address{}, err
+	}
+	faucetAddress, err := evm.DeployContract(
+		rpcURL,
+		privateKey,
+		faucetBytecode,
+		"(uint256, uint256)",
+		params.DripAmount,
+		big.NewInt(int64(params.Cooldown/time.Second)),
+	)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failure deploying faucet: %w", err)
+	}
+	if fundAmount != nil && fundAmount.Sign() > 0 {
+		if err := FundFaucet(rpcURL, privateKey, faucetAddress, fundAmount); err != nil {
+			return faucetAddress, err
+		}
+	}
+	return faucetAddress, nil
+}
+
+// DeployAirdropFaucet deploys a faucet on a fresh L1 whose genesis airdrop went to
+// the ewoq key, and funds it with [fundAmount] wei from the airdrop account
+func DeployAirdropFaucet(
+	rpcURL string,
+	params FaucetParams,
+	fundAmount *big.Int,
+) (common.Address, error) {
+	ewoq, err := key.LoadEwoq()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return DeployFaucet(rpcURL, ewoq.PrivKeyHex(), params, fundAmount)
+}
+
+// FundFaucet sends [amount] wei from [privateKey] to the faucet at [faucetAddress]
+func FundFaucet(
+	rpcURL string,
+	privateKey string,
+	faucetAddress common.Address,
+	amount *big.Int,
+) error {
+	if _, _, err := evm.TxToMethod(
+		rpcURL,
+		privateKey,
+		faucetAddress,
+		amount,
+		"fund()",
+	); err != nil {
+		return fmt.Errorf("failure funding faucet %s: %w", faucetAddress.Hex(), err)
+	}
+	return nil
+}
+
+// Drip asks the faucet at [faucetAddress] to send its drip amount to [recipient].
+// The tx is paid by [privateKey], that can be any funded key, including the
+// recipient one. It fails if [recipient] is still on cooldown, or if the faucet
+// doesn't have enough balance
+func Drip(
+	rpcURL string,
+	privateKey string,
+	faucetAddress common.Address,
+	recipient common.Address,
+) error {
+	if _, _, err := evm.TxToMethod(
+		rpcURL,
+		privateKey,
+		faucetAddress,
+		nil,
+		"drip(address)",
+		recipient,
+	); err != nil {
+		return fmt.Errorf("failure dripping from faucet %s to %s: %w", faucetAddress.Hex(), recipient.Hex(), err)
+	}
+	return nil
+}
+
+// SetFaucetParams changes the rate limiting parameters of the faucet at [faucetAddress].
+// [privateKey] must be the faucet owner
+func SetFaucetParams(
+	rpcURL string,
+	privateKey string,
+	faucetAddress common.Address,
+	params FaucetParams,
+) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+	if _, _, err := evm.TxToMethod(
+		rpcURL,
+		privateKey,
+		faucetAddress,
+		nil,
+		"setParams(uint256, uint256)",
+		params.DripAmount,
+		big.NewInt(int64(params.Cooldown/time.Second)),
+	); err != nil {
+		return fmt.Errorf("failure setting params of faucet %s: %w", faucetAddress.Hex(), err)
+	}
+	return nil
+}
+
+// GetFaucetParams returns the rate limiting parameters of the faucet at [faucetAddress]
+func GetFaucetParams(
+	rpcURL string,
+	faucetAddress common.Address,
+) (FaucetParams, error) {
+	dripAmount, err := callUint256(rpcURL, faucetAddress, "dripAmount()->(uint256)")
+	if err != nil {
+		return FaucetParams{}, err
+	}
+	cooldown, err := callUint256(rpcURL, faucetAddress, "cooldown()->(uint256)")
+	if err != nil {
+		return FaucetParams{}, err
+	}
+	return FaucetParams{
+		DripAmount: dripAmount,
+		Cooldown:   time.Duration(cooldown.Int64()) * time.Second,
+	}, nil
+}
+
+// GetNextDripTime returns the earliest time [recipient] can get a drip from the
+// faucet at [faucetAddress]. It is the zero time if the recipient never got one
+func GetNextDripTime(
+	rpcURL string,
+	faucetAddress common.Address,
+	recipient common.Address,
+) (time.Time, error) {
+	lastDrip, err := callUint256(rpcURL, faucetAddress, "lastDrip(address)->(uint256)", recipient)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if lastDrip.Sign() == 0 {
+		return time.Time{}, nil
+	}
+	params, err := GetFaucetParams(rpcURL, faucetAddress)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(lastDrip.Int64(), 0).Add(params.Cooldown), nil
+}
+
+func callUint256(
+	rpcURL string,
+	contractAddress common.Address,
+	methodSignature string,
+	params ...interface{},
+) (*big.Int, error) {
+	out, err := evm.CallToMethod(rpcURL, contractAddress, methodSignature, params...)
+	if err != nil {
+		return nil, err
+	}
+	value, b := out[0].(*big.Int)
+	if !b {
+		return nil, fmt.Errorf("error at %s call, expected *big.Int, got %T", methodSignature, out[0])
+	}
+	return value, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package devtools
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+const faucetABI = `[
+	{"type":"constructor","inputs":[{"name":"dripAmount","type":"uint256"},{"name":"cooldown","type":"uint256"}]},
+	{"type":"function","name":"fund","inputs":[],"outputs":[],"stateMutability":"payable"},
+	{"type":"function","name":"drip","inputs":[{"name":"recipient","type":"address"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"function","name":"dripAmount","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"function","name":"cooldown","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"function","name":"lastDrip","inputs":[{"name":"recipient","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"function","name":"owner","inputs":[],"outputs":[{"name":"","type":"address"}],"stateMutability":"view"},
+	{"type":"function","name":"setParams","inputs":[{"name":"dripAmount","type":"uint256"},{"name":"cooldown","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"function","name":"withdraw","inputs":[{"name":"amount","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"event","name":"Drip","inputs":[{"name":"recipient","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false}]}
+]`
+
+func TestFaucetContract(t *testing.T) {
+	require := require.New(t)
+	faucet, err := abi.JSON(strings.NewReader(faucetABI))
+	require.NoError(err)
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	owner := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	user := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	recipient := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	cfg := &runtime.Config{
+		Origin:    owner,
+		Time:      1000,
+		State:     statedb,
+		EVMConfig: vm.Config{},
+	}
+	call := func(from common.Address, address common.Address, method string, args ...interface{}) ([]interface{}, error) {
+		input, err := faucet.Pack(method, args...)
+		require.NoError(err)
+		cfg.Origin = from
+		out, _, err := runtime.Call(address, input, cfg)
+		if err != nil {
+			if reason, unpackErr := abi.UnpackRevert(out); unpackErr == nil {
+				return nil, &revertError{reason}
+			}
+			return nil, err
+		}
+		return faucet.Unpack(method, out)
+	}
+
+	constructorArgs, err := faucet.Pack("", big.NewInt(100), big.NewInt(60))
+	require.NoError(err)
+	initCode := append(common.FromHex(string(faucetBytecode)), constructorArgs...)
+	_, faucetAddress, _, err := runtime.Create(initCode, cfg)
+	require.NoError(err)
+
+	out, err := call(user, faucetAddress, "owner")
+	require.NoError(err)
+	require.Equal(owner, out[0])
+	out, err = call(user, faucetAddress, "dripAmount")
+	require.NoError(err)
+	require.Equal(big.NewInt(100), out[0])
+	out, err = call(user, faucetAddress, "cooldown")
+	require.NoError(err)
+	require.Equal(big.NewInt(60), out[0])
+
+	// empty faucet
+	_, err = call(user, faucetAddress, "drip", recipient)
+	require.ErrorContains(err, "faucet: insufficient balance")
+
+	statedb.AddBalance(user, big.NewInt(1000))
+	cfg.Value = big.NewInt(250)
+	_, err = call(user, faucetAddress, "fund")
+	require.NoError(err)
+	// drip is not payable
+	_, err = call(user, faucetAddress, "drip", recipient)
+	require.ErrorContains(err, "faucet: not payable")
+	cfg.Value = nil
+	require.Equal(big.NewInt(250), statedb.GetBalance(faucetAddress))
+
+	_, err = call(user, faucetAddress, "drip", recipient)
+	require.NoError(err)
+	require.Equal(big.NewInt(100), statedb.GetBalance(recipient))
+	logs := statedb.Logs()
+	require.Len(logs, 1)
+	require.Equal(faucet.Events["Drip"].ID, logs[0].Topics[0])
+	require.Equal(common.BytesToHash(recipient.Bytes()), logs[0].Topics[1])
+	require.Equal(common.BigToHash(big.NewInt(100)).Bytes(), logs[0].Data)
+	out, err = call(user, faucetAddress, "lastDrip", recipient)
+	require.NoError(err)
+	require.Equal(big.NewInt(1000), out[0])
+
+	// rate limited per recipient
+	cfg.Time = 1059
+	_, err = call(user, faucetAddress, "drip", recipient)
+	require.ErrorContains(err, "faucet: cooldown not elapsed")
+	_, err = call(user, faucetAddress, "drip", user)
+	require.NoError(err)
+	cfg.Time = 1060
+	_, err = call(user, faucetAddress, "drip", recipient)
+	require.ErrorContains(err, "faucet: insufficient balance")
+
+	// owner only
+	_, err = call(user, faucetAddress, "setParams", big.NewInt(10), big.NewInt(0))
+	require.ErrorContains(err, "faucet: caller is not the owner")
+	_, err = call(user, faucetAddress, "withdraw", big.NewInt(10))
+	require.ErrorContains(err, "faucet: caller is not the owner")
+	_, err = call(owner, faucetAddress, "setParams", big.NewInt(10), big.NewInt(0))
+	require.NoError(err)
+	_, err = call(user, faucetAddress, "drip", recipient)
+	require.NoError(err)
+	require.Equal(big.NewInt(110), statedb.GetBalance(recipient))
+	_, err = call(owner, faucetAddress, "withdraw", big.NewInt(40))
+	require.NoError(err)
+	require.Equal(big.NewInt(40), statedb.GetBalance(owner))
+	require.Zero(statedb.GetBalance(faucetAddress).Sign())
+}
+
+type revertError struct {
+	reason string
+}
+
+func (e *revertError) Error() string {
+	return e.reason
+}
+
+func TestFaucetParamsValidate(t *testing.T) {
+	require.Error(t, FaucetParams{}.validate())
+	require.Error(t, FaucetParams{DripAmount: big.NewInt(1), Cooldown: -1}.validate())
+	require.NoError(t, FaucetParams{DripAmount: big.NewInt(1)}.validate())
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package devtools
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/key"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// DevKey is a deterministic key of a KeySet
+type DevKey struct {
+	// Index of the key in the set
+	Index int
+	Key   *key.SoftKey
+	// ShortID is the address of the key on the P-Chain and X-Chain, before bech32 encoding
+	ShortID    ids.ShortID
+	EVMAddress common.Address
+}
+
+// DevKeyAddresses are the encodings of the addresses of a DevKey on a given network
+type DevKeyAddresses struct {
+	// P is the P-Chain address, eg. P-fuji1...
+	P string
+	// X is the X-Chain address, eg. X-fuji1...
+	X string
+	// C is the bech32 C-Chain address used on atomic txs, eg. C-fuji1...
+	C string
+	// EVM is the hex address used on EVM chains
+	EVM string
+}
+
+// Addresses returns the addresses of the key on the network with HRP [networkHRP]
+func (k DevKey) Addresses(networkHRP string) (DevKeyAddresses, error) {
+	addrs := DevKeyAddresses{EVM: k.EVMAddress.Hex()}
+	for _, addr := range []struct {
+		chain string
+		value *string
+	}{
+		{"P", &addrs.P},
+		{"X", &addrs.X},
+		{"C", &addrs.C},
+	} {
+		formatted, err := address.Format(addr.chain, networkHRP, k.ShortID.Bytes())
+		if err != nil {
+			return DevKeyAddresses{}, err
+		}
+		*addr.value = formatted
+	}
+	return addrs, nil
+}
+
+// KeySet is a set of deterministic keys generated by GenerateKeySet
+type KeySet []DevKey
+
+// EVMAddresses returns the EVM addresses of the keys, in order
+func (s KeySet) EVMAddresses() []common.Address {
+	addrs := make([]common.Address, len(s))
+	for i, k := range s {
+		addrs[i] = k.EVMAddress
+	}
+	return addrs
+}
+
+// GenesisAlloc returns a genesis allocation giving [balance] wei to each key of the
+// set, to be used as SubnetEVMParams.Allocation or merged with other allocations
+func (s KeySet) GenesisAlloc(balance *big.Int) core.GenesisAlloc {
+	alloc := make(core.GenesisAlloc, len(s))
+	for _, k := range s {
+		alloc[k.EVMAddress] = core.GenesisAccount{Balance: new(big.Int).Set(balance)}
+	}
+	return alloc
+}
+
+// GenerateKeySet derives [n] secp256k1 keys from [seed]. The same seed always gives
+// the same keys, so examples and integration tests get reproducible addresses.
+// The keys are derived with sha256, so they are not meant to hold real funds
+func GenerateKeySet(n int, seed string) (KeySet, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid number of keys %d", n)
+	}
+	keySet := make(KeySet, n)
+	for i := 0; i < n; i++ {
+		privKey, err := derivePrivateKey(seed, uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		softKey, err := key.NewSoft(key.WithPrivateKey(privKey))
+		if err != nil {
+			return nil, err
+		}
+		keySet[i] = DevKey{
+			Index:      i,
+			Key:        softKey,
+			ShortID:    privKey.Address(),
+			EVMAddress: common.HexToAddress(softKey.C()),
+		}
+	}
+	return keySet, nil
+}
+
+// derivePrivateKey returns the key number [index] of [seed]. Candidates outside the
+// curve order are skipped by hashing again with an increasing counter
+func derivePrivateKey(seed string, index uint32) (*secp256k1.PrivateKey, error) {
+	for counter := uint32(0); ; counter++ {
+		preimage := make([]byte, 0, len(seed)+8)
+		preimage = append(preimage, seed...)
+		preimage = binary.BigEndian.AppendUint32(preimage, index)
+		preimage = binary.BigEndian.AppendUint32(preimage, counter)
+		candidate := sha256.Sum256(preimage)
+		if _, err := ethcrypto.ToECDSA(candidate[:]); err != nil {
+			continue
+		}
+		return secp256k1.ToPrivateKey(candidate[:])
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package devtools
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKeySet(t *testing.T) {
+	require := require.New(t)
+	keySet, err := GenerateKeySet(3, "test")
+	require.NoError(err)
+	require.Len(keySet, 3)
+
+	// deterministic
+	again, err := GenerateKeySet(5, "test")
+	require.NoError(err)
+	for i, k := range keySet {
+		require.Equal(i, k.Index)
+		require.Equal(k.Key.PrivKeyHex(), again[i].Key.PrivKeyHex())
+		require.Equal(k.EVMAddress, again[i].EVMAddress)
+	}
+	other, err := GenerateKeySet(1, "other")
+	require.NoError(err)
+	require.NotEqual(keySet[0].Key.PrivKeyHex(), other[0].Key.PrivKeyHex())
+	// distinct keys
+	seen := map[string]bool{}
+	for _, k := range again {
+		require.False(seen[k.Key.PrivKeyHex()])
+		seen[k.Key.PrivKeyHex()] = true
+	}
+
+	addrs, err := keySet[0].Addresses("fuji")
+	require.NoError(err)
+	pAddr, err := keySet[0].Key.P("fuji")
+	require.NoError(err)
+	require.Equal(pAddr, addrs.P)
+	require.True(strings.HasPrefix(addrs.X, "X-fuji1"))
+	require.True(strings.HasPrefix(addrs.C, "C-fuji1"))
+	require.Equal(keySet[0].Key.C(), addrs.EVM)
+
+	balance := big.NewInt(1000)
+	alloc := keySet.GenesisAlloc(balance)
+	require.Len(alloc, 3)
+	for _, addr := range keySet.EVMAddresses() {
+		require.Equal(balance, alloc[addr].Balance)
+	}
+
+	empty, err := GenerateKeySet(0, "test")
+	require.NoError(err)
+	require.Empty(empty)
+	_, err = GenerateKeySet(-1, "test")
+	require.Error(err)
+}
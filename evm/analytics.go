@@ -0,0 +1,210 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+)
+
+// BlockSample holds the gas and fee data of a block
+type BlockSample struct {
+	Number    uint64    `json:"number"`
+	Timestamp time.Time `json:"timestamp"`
+	GasUsed   uint64    `json:"gasUsed"`
+	GasLimit  uint64    `json:"gasLimit"`
+	BaseFee   *big.Int  `json:"baseFee"`
+	TxCount   uint      `json:"txCount"`
+}
+
+// GasUtilization returns the fraction of the block gas limit used by the block
+func (s BlockSample) GasUtilization() float64 {
+	if s.GasLimit == 0 {
+		return 0
+	}
+	return float64(s.GasUsed) / float64(s.GasLimit)
+}
+
+// FeeStats summarizes a set of block samples
+type FeeStats struct {
+	Blocks            int     `json:"blocks"`
+	TxCount           uint64  `json:"txCount"`
+	AvgGasUtilization float64 `json:"avgGasUtilization"`
+	// base fee percentile (eg 50, 90, 99) -> base fee
+	BaseFee map[int]*big.Int `json:"baseFee"`
+}
+
+var DefaultFeePercentiles = []int{10, 50, 90, 99}
+
+func GetHeader(
+	client ethclient.Client,
+	blockNumber *big.Int,
+) (*types.Header, error) {
+	return utils.Retry(
+		func(ctx context.Context) (*types.Header, error) { return client.HeaderByNumber(ctx, blockNumber) },
+		constants.APIRequestLargeTimeout,
+		repeatsOnFailure,
+		fmt.Sprintf("failure obtaining header %s on %#v", blockNumber, client),
+	)
+}
+
+// GetBlockSample gets the gas and fee data of block [blockNumber]
+func GetBlockSample(
+	client ethclient.Client,
+	blockNumber uint64,
+) (BlockSample, error) {
+	header, err := GetHeader(client, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return BlockSample{}, err
+	}
+	txCount, err := utils.Retry(
+		func(ctx context.Context) (uint, error) { return client.TransactionCount(ctx, header.Hash()) },
+		constants.APIRequestLargeTimeout,
+		repeatsOnFailure,
+		fmt.Sprintf("failure obtaining tx count for block %d on %#v", blockNumber, client),
+	)
+	if err != nil {
+		return BlockSample{}, err
+	}
+	baseFee := big.NewInt(0)
+	if header.BaseFee != nil {
+		baseFee = header.BaseFee
+	}
+	return BlockSample{
+		Number:    blockNumber,
+		Timestamp: time.Unix(int64(header.Time), 0),
+		GasUsed:   header.GasUsed,
+		GasLimit:  header.GasLimit,
+		BaseFee:   baseFee,
+		TxCount:   txCount,
+	}, nil
+}
+
+// FindBlockByTime returns the number of the first block with timestamp >= [t],
+// or the latest block number if there is none
+func FindBlockByTime(
+	client ethclient.Client,
+	t time.Time,
+) (uint64, error) {
+	latest, err := GetHeader(client, nil)
+	if err != nil {
+		return 0, err
+	}
+	low, high := uint64(0), latest.Number.Uint64()
+	for low < high {
+		mid := low + (high-low)/2
+		header, err := GetHeader(client, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if int64(header.Time) < t.Unix() {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low, nil
+}
+
+// SampleBlocks gets the gas and fee data of the blocks produced between [start]
+// and [end], sampling one block each [step] blocks
+func SampleBlocks(
+	client ethclient.Client,
+	start time.Time,
+	end time.Time,
+	step uint64,
+) ([]BlockSample, error) {
+	if step == 0 {
+		step = 1
+	}
+	fromBlock, err := FindBlockByTime(client, start)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := FindBlockByTime(client, end)
+	if err != nil {
+		return nil, err
+	}
+	samples := []BlockSample{}
+	for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber += step {
+		sample, err := GetBlockSample(client, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if sample.Timestamp.After(end) {
+			break
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// ComputeFeeStats summarizes [samples], computing the given base fee [percentiles]
+// (DefaultFeePercentiles if empty)
+func ComputeFeeStats(samples []BlockSample, percentiles []int) FeeStats {
+	if len(percentiles) == 0 {
+		percentiles = DefaultFeePercentiles
+	}
+	stats := FeeStats{
+		Blocks:  len(samples),
+		BaseFee: map[int]*big.Int{},
+	}
+	if len(samples) == 0 {
+		return stats
+	}
+	baseFees := make([]*big.Int, 0, len(samples))
+	utilization := 0.0
+	for _, sample := range samples {
+		stats.TxCount += uint64(sample.TxCount)
+		utilization += sample.GasUtilization()
+		baseFees = append(baseFees, sample.BaseFee)
+	}
+	stats.AvgGasUtilization = utilization / float64(len(samples))
+	sort.Slice(baseFees, func(i, j int) bool { return baseFees[i].Cmp(baseFees[j]) < 0 })
+	for _, p := range percentiles {
+		// nearest rank method
+		rank := (p*len(baseFees) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		if rank > len(baseFees) {
+			rank = len(baseFees)
+		}
+		stats.BaseFee[p] = baseFees[rank-1]
+	}
+	return stats
+}
+
+// WriteBlockSamplesCSV writes [samples] as CSV into [w], with a header row
+func WriteBlockSamplesCSV(w io.Writer, samples []BlockSample) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"number", "timestamp", "gasUsed", "gasLimit", "gasUtilization", "baseFee", "txCount"}); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		if err := csvWriter.Write([]string{
+			strconv.FormatUint(sample.Number, 10),
+			sample.Timestamp.UTC().Format(time.RFC3339),
+			strconv.FormatUint(sample.GasUsed, 10),
+			strconv.FormatUint(sample.GasLimit, 10),
+			strconv.FormatFloat(sample.GasUtilization(), 'f', 4, 64),
+			sample.BaseFee.String(),
+			strconv.FormatUint(uint64(sample.TxCount), 10),
+		}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
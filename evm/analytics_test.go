@@ -0,0 +1,55 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFeeStats(t *testing.T) {
+	samples := []BlockSample{}
+	for i := 1; i <= 10; i++ {
+		samples = append(samples, BlockSample{
+			Number:   uint64(i),
+			GasUsed:  uint64(i * 100),
+			GasLimit: 1000,
+			BaseFee:  big.NewInt(int64(11-i) * 25),
+			TxCount:  2,
+		})
+	}
+	stats := ComputeFeeStats(samples, []int{10, 50, 90, 100})
+	require.Equal(t, 10, stats.Blocks)
+	require.Equal(t, uint64(20), stats.TxCount)
+	require.InDelta(t, 0.55, stats.AvgGasUtilization, 1e-9)
+	require.Equal(t, big.NewInt(25), stats.BaseFee[10])
+	require.Equal(t, big.NewInt(125), stats.BaseFee[50])
+	require.Equal(t, big.NewInt(225), stats.BaseFee[90])
+	require.Equal(t, big.NewInt(250), stats.BaseFee[100])
+
+	empty := ComputeFeeStats(nil, nil)
+	require.Equal(t, 0, empty.Blocks)
+	require.Empty(t, empty.BaseFee)
+}
+
+func TestWriteBlockSamplesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteBlockSamplesCSV(&buf, []BlockSample{
+		{
+			Number:    7,
+			Timestamp: time.Unix(0, 0),
+			GasUsed:   500,
+			GasLimit:  1000,
+			BaseFee:   big.NewInt(25),
+			TxCount:   3,
+		},
+	}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "7,1970-01-01T00:00:00Z,500,1000,0.5000,25,3", lines[1])
+}
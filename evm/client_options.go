@@ -0,0 +1,126 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/proxy"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// HeaderProvider sets headers on each request sent by the client. It must be safe
+// for concurrent use
+type HeaderProvider func(h http.Header) error
+
+type clientOptions struct {
+	headers   http.Header
+	providers []HeaderProvider
+	proxy     proxy.Config
+}
+
+// ClientOption configures the connection created by GetClient and GetRPCClient.
+// Options apply to both http and ws endpoints
+type ClientOption func(*clientOptions)
+
+func newClientOptions(opts []ClientOption) *clientOptions {
+	o := &clientOptions{
+		headers: http.Header{},
+		proxy:   proxy.Default(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHeader sets header [key] to [value] on all requests
+func WithHeader(key, value string) ClientOption {
+	return func(o *clientOptions) {
+		o.headers.Set(key, value)
+	}
+}
+
+// WithHeaders sets the given headers on all requests
+func WithHeaders(headers http.Header) ClientOption {
+	return func(o *clientOptions) {
+		for key, values := range headers {
+			o.headers.Del(key)
+			for _, value := range values {
+				o.headers.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithBasicAuth authenticates all requests with the given user and password
+func WithBasicAuth(user, password string) ClientOption {
+	auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+	return WithHeader("Authorization", "Basic "+auth)
+}
+
+// WithBearerToken authenticates all requests with the given token
+func WithBearerToken(token string) ClientOption {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHeaderProvider calls [provider] before each http request, and before dialing a ws
+// connection, so headers can be computed at request time (eg short lived tokens).
+// Providers are called in the order they are given
+func WithHeaderProvider(provider HeaderProvider) ClientOption {
+	return func(o *clientOptions) {
+		o.providers = append(o.providers, provider)
+	}
+}
+
+// WithProxy connects through [config] instead of the proxy set with proxy.SetDefault.
+// Both http requests and ws dials go through the proxy
+func WithProxy(config proxy.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.proxy = config
+	}
+}
+
+func (o *clientOptions) rpcOptions() ([]rpc.ClientOption, error) {
+	rpcOpts := []rpc.ClientOption{}
+	if o.proxy.Enabled() {
+		httpClient, err := o.proxy.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		rpcOpts = append(rpcOpts,
+			rpc.WithHTTPClient(httpClient),
+			rpc.WithWebsocketDialer(websocket.Dialer{
+				NetDialContext:   o.proxy.DialContext,
+				ReadBufferSize:   1024,
+				WriteBufferSize:  1024,
+				HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+			}),
+		)
+	}
+	if len(o.headers) > 0 {
+		rpcOpts = append(rpcOpts, rpc.WithHeaders(o.headers))
+	}
+	if len(o.providers) > 0 {
+		// the rpc client accepts only one auth function, so all providers are chained
+		providers := o.providers
+		rpcOpts = append(rpcOpts, rpc.WithHTTPAuth(func(h http.Header) error {
+			for _, provider := range providers {
+				if err := provider(h); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	return rpcOpts, nil
+}
+
+// ContextWithHeaders returns a context that adds [headers] to the http requests made with it,
+// for headers that only apply to specific calls
+func ContextWithHeaders(ctx context.Context, headers http.Header) context.Context {
+	return rpc.NewContextWithHeaders(ctx, headers)
+}
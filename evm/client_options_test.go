@@ -0,0 +1,83 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/proxy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientOptionsHeaders(t *testing.T) {
+	require := require.New(t)
+	var (
+		lock     sync.Mutex
+		received []http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		received = append(received, r.Header.Clone())
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xa86a"}`))
+	}))
+	defer server.Close()
+
+	calls := 0
+	client, err := GetClient(
+		server.URL,
+		WithBasicAuth("user", "pass"),
+		WithHeader("X-Api-Key", "key"),
+		WithHeaderProvider(func(h http.Header) error {
+			calls++
+			h.Set("X-Request-Count", strconv.Itoa(calls))
+			return nil
+		}),
+	)
+	require.NoError(err)
+	chainID, err := client.ChainID(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(43114), chainID.Uint64())
+	ctx := ContextWithHeaders(context.Background(), http.Header{"X-Trace": []string{"abc"}})
+	_, err = client.ChainID(ctx)
+	require.NoError(err)
+
+	require.Len(received, 2)
+	user, pass, ok := (&http.Request{Header: received[0]}).BasicAuth()
+	require.True(ok)
+	require.Equal("user", user)
+	require.Equal("pass", pass)
+	require.Equal("key", received[0].Get("X-Api-Key"))
+	require.Equal("1", received[0].Get("X-Request-Count"))
+	require.Equal("2", received[1].Get("X-Request-Count"))
+	require.Empty(received[0].Get("X-Trace"))
+	require.Equal("abc", received[1].Get("X-Trace"))
+}
+
+func TestClientOptionsBearer(t *testing.T) {
+	o := newClientOptions([]ClientOption{
+		WithHeaders(http.Header{"Authorization": []string{"old"}}),
+		WithBearerToken("token"),
+	})
+	require.Equal(t, "Bearer token", o.headers.Get("Authorization"))
+	rpcOpts, err := o.rpcOptions()
+	require.NoError(t, err)
+	require.Len(t, rpcOpts, 1)
+}
+
+func TestClientOptionsProxy(t *testing.T) {
+	o := newClientOptions([]ClientOption{WithProxy(proxy.Config{URL: "ftp://proxy:21"})})
+	_, err := o.rpcOptions()
+	require.ErrorIs(t, err, proxy.ErrUnsupportedScheme)
+
+	o = newClientOptions([]ClientOption{WithProxy(proxy.Config{URL: "socks5://proxy:1080"})})
+	rpcOpts, err := o.rpcOptions()
+	require.NoError(t, err)
+	require.Len(t, rpcOpts, 2)
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The CREATE2 deployer is the deterministic deployment proxy
+// (https://github.com/Arachnid/deterministic-deployment-proxy). It is deployed by a
+// keyless tx, valid on any chain, so it lives at the same address on every chain it is
+// deployed to, and so do the contracts it deploys with a given salt and init code.
+// The keyless tx is not replay protected (EIP-155), so the chain must accept
+// unprotected txs (allow-unprotected-txs). Otherwise, it can be added to the
+// genesis with Create2DeployerGenesisAccount
+const (
+	create2DeployerSigner      = "0x3fab184622dc19b6109349b94811493bf2a45362"
+	create2DeployerRuntimeCode = "0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf3"
+	create2DeployerTx          = "0xf8a58085174876e800830186a08080b853604580600e600039806000f350fe7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf31ba02222222222222222222222222222222222222222222222222222222222222222a02222222222222222222222222222222222222222222222222222222222222222"
+)
+
+var (
+	Create2DeployerAddress = common.HexToAddress("0x4e59b44847b379578588920ca78fbf26c0b4956c")
+	// gas price (100 gwei) * gas limit (100k) of the keyless tx
+	create2DeployerRequiredBalance = big.NewInt(0).Mul(big.NewInt(100_000_000_000), big.NewInt(100_000))
+
+	ErrContractNotDeployed   = errors.New("contract not deployed")
+	ErrContractCodeMismatch  = errors.New("deployed contract code does not match the expected one")
+	ErrCreate2DeployerFailed = errors.New("CREATE2 deployer failed to deploy the contract")
+)
+
+// Create2DeployerGenesisAccount returns the genesis account that predeploys the CREATE2
+// deployer, to be added to a genesis allocation at Create2DeployerAddress
+func Create2DeployerGenesisAccount() core.GenesisAccount {
+	return core.GenesisAccount{
+		Code:    common.FromHex(create2DeployerRuntimeCode),
+		Balance: big.NewInt(0),
+	}
+}
+
+// Create2Salt returns the salt for CREATE2 deployments identified by [label], eg.
+// "PoAValidatorManager v1.0.0"
+func Create2Salt(label string) [32]byte {
+	return crypto.Keccak256Hash([]byte(label))
+}
+
+// Create2InitCode returns the init code that deploys the contract with [binBytes] hex
+// bytecode, with the constructor params [params] of type [methodEsp]
+func Create2InitCode(
+	binBytes []byte,
+	methodEsp string,
+	params ...interface{},
+) ([]byte, error) {
+	_, methodABI, err := ParseMethodSignature(methodEsp, Constructor, nil, NonPayable, params...)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &bind.MetaData{
+		ABI: methodABI,
+	}
+	abi, err := metadata.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	constructorInput, err := abi.Pack("", params...)
+	if err != nil {
+		return nil, err
+	}
+	return append(common.FromHex(string(bytes.TrimSpace(binBytes))), constructorInput...), nil
+}
+
+// Create2Address returns the address the CREATE2 deployer deploys [initCode] to with [salt]
+func Create2Address(salt [32]byte, initCode []byte) common.Address {
+	return crypto.CreateAddress2(Create2DeployerAddress, salt, crypto.Keccak256(initCode))
+}
+
+// DeployCreate2Deployer deploys the CREATE2 deployer by issuing its keyless tx, after
+// funding its signer with [privateKey]. Returns true if it was already deployed
+func DeployCreate2Deployer(
+	rpcURL string,
+	privateKey string,
+) (bool, error) {
+	client, err := GetClient(rpcURL)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	if deployed, err := ContractAlreadyDeployed(client, Create2DeployerAddress.Hex()); err != nil {
+		return false, fmt.Errorf("failure making a request to %s: %w", rpcURL, err)
+	} else if deployed {
+		return true, nil
+	}
+	if err := SetMinBalance(
+		client,
+		privateKey,
+		create2DeployerSigner,
+		create2DeployerRequiredBalance,
+	); err != nil {
+		return false, err
+	}
+	if err := IssueTx(client, create2DeployerTx); err != nil {
+		return false, fmt.Errorf("failure issuing CREATE2 deployer tx (does the chain allow unprotected txs?): %w", err)
+	}
+	return false, nil
+}
+
+// DeployContractCreate2 deploys the contract with [binBytes] hex bytecode and constructor
+// params [params] of type [methodEsp] through the CREATE2 deployer, with [salt], so it gets
+// the address given by Create2Address on every chain. The CREATE2 deployer is deployed
+// first if needed. If the contract is already deployed at that address, nothing is
+// issued, and true is returned.
+//
+// The contract constructor sees the CREATE2 deployer as msg.sender, so contracts that set
+// their owner to the deployer must be initialized with an explicit owner instead
+func DeployContractCreate2(
+	rpcURL string,
+	privateKey string,
+	salt [32]byte,
+	binBytes []byte,
+	methodEsp string,
+	params ...interface{},
+) (common.Address, bool, error) {
+	initCode, err := Create2InitCode(binBytes, methodEsp, params...)
+	if err != nil {
+		return common.Address{}, false, err
+	}
+	address := Create2Address(salt, initCode)
+	client, err := GetClient(rpcURL)
+	if err != nil {
+		return common.Address{}, false, err
+	}
+	defer client.Close()
+	if deployed, err := ContractAlreadyDeployed(client, address.Hex()); err != nil {
+		return common.Address{}, false, fmt.Errorf("failure making a request to %s: %w", rpcURL, err)
+	} else if deployed {
+		return address, true, nil
+	}
+	if _, err := DeployCreate2Deployer(rpcURL, privateKey); err != nil {
+		return common.Address{}, false, err
+	}
+	txOpts, err := GetTxOptsWithSigner(client, privateKey)
+	if err != nil {
+		return common.Address{}, false, err
+	}
+	deployer := bind.NewBoundContract(Create2DeployerAddress, abi.ABI{}, client, client, client)
+	tx, err := deployer.RawTransact(txOpts, append(salt[:], initCode...))
+	if err != nil {
+		return common.Address{}, false, err
+	}
+	if _, success, err := WaitForTransaction(client, tx); err != nil {
+		return common.Address{}, false, err
+	} else if !success {
+		return common.Address{}, false, ErrFailedReceiptStatus
+	}
+	// the deployer proxy reverts when CREATE2 returns the zero address, so a failed
+	// deployment gets a failed receipt. The code at the address is still checked, as a
+	// guard against it being already occupied
+	if deployed, err := ContractAlreadyDeployed(client, address.Hex()); err != nil {
+		return common.Address{}, false, fmt.Errorf("failure making a request to %s: %w", rpcURL, err)
+	} else if !deployed {
+		return common.Address{}, false, fmt.Errorf("%w at %s", ErrCreate2DeployerFailed, address.Hex())
+	}
+	return address, false, nil
+}
+
+// VerifyContractCode checks that the contract deployed at [address] has [runtimeCode] hex
+// bytecode (the deployed bytecode of the contract artifacts)
+func VerifyContractCode(
+	rpcURL string,
+	address common.Address,
+	runtimeCode []byte,
+) error {
+	client, err := GetClient(rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	code, err := GetContractBytecode(client, address.Hex())
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("%w at %s", ErrContractNotDeployed, address.Hex())
+	}
+	if !bytes.Equal(code, common.FromHex(string(bytes.TrimSpace(runtimeCode)))) {
+		return fmt.Errorf("%w at %s", ErrContractCodeMismatch, address.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate2Deployer(t *testing.T) {
+	require := require.New(t)
+	tx := new(types.Transaction)
+	require.NoError(tx.UnmarshalBinary(common.FromHex(create2DeployerTx)))
+	require.False(tx.Protected())
+	signer, err := types.Sender(types.HomesteadSigner{}, tx)
+	require.NoError(err)
+	require.Equal(common.HexToAddress(create2DeployerSigner), signer)
+	require.Equal(Create2DeployerAddress, crypto.CreateAddress(signer, tx.Nonce()))
+	require.Equal(0, create2DeployerRequiredBalance.Cmp(tx.Cost()))
+	// the init code copies the runtime code after its 14 bytes
+	require.Equal(common.FromHex(create2DeployerRuntimeCode), tx.Data()[14:])
+	require.Equal(common.FromHex(create2DeployerRuntimeCode), Create2DeployerGenesisAccount().Code)
+}
+
+func TestCreate2Address(t *testing.T) {
+	require := require.New(t)
+	binBytes := []byte("0x6080604052\n")
+	initCode, err := Create2InitCode(binBytes, "(address, uint256)", common.HexToAddress("0x01"), common.Big2)
+	require.NoError(err)
+	require.Equal(common.FromHex("0x6080604052"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000002"), initCode)
+
+	salt := Create2Salt("PoAValidatorManager")
+	address := Create2Address(salt, initCode)
+	require.Equal(crypto.CreateAddress2(Create2DeployerAddress, salt, crypto.Keccak256(initCode)), address)
+	require.NotEqual(address, Create2Address(Create2Salt("TeleporterRegistry"), initCode))
+	otherInitCode, err := Create2InitCode(binBytes, "(address, uint256)", common.HexToAddress("0x01"), common.Big3)
+	require.NoError(err)
+	require.NotEqual(address, Create2Address(salt, otherInitCode))
+
+	// no constructor params
+	initCode, err = Create2InitCode(binBytes, "()")
+	require.NoError(err)
+	require.Equal(common.FromHex("0x6080604052"), initCode)
+}
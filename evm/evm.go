@@ -4,6 +4,7 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -220,9 +221,21 @@ func SendTransaction(
 	return err
 }
 
-func GetClient(rpcURL string) (ethclient.Client, error) {
+// GetClient connects to the EVM chain at [rpcURL]. See ClientOption for the available
+// connection settings
+func GetClient(rpcURL string, opts ...ClientOption) (ethclient.Client, error) {
+	rpcOpts, err := newClientOptions(opts).rpcOptions()
+	if err != nil {
+		return nil, err
+	}
 	return utils.Retry(
-		func(ctx context.Context) (ethclient.Client, error) { return ethclient.DialContext(ctx, rpcURL) },
+		func(ctx context.Context) (ethclient.Client, error) {
+			rpcClient, err := rpc.DialOptions(ctx, rpcURL, rpcOpts...)
+			if err != nil {
+				return nil, err
+			}
+			return ethclient.NewClient(rpcClient), nil
+		},
 		constants.APIRequestLargeTimeout,
 		repeatsOnFailure,
 		fmt.Sprintf("failure connecting to %s", rpcURL),
@@ -253,16 +266,31 @@ func GetTxOptsWithSigner(
 	return bind.NewKeyedTransactorWithChainID(prefundedPrivateKey, chainID)
 }
 
+// WaitForTransaction waits for [tx] to be accepted and returns its receipt, and whether
+// it was successfully executed. If another tx from the same sender and nonce is accepted
+// instead, a *TxReplacedError (matching ErrTxReplaced) is returned
 func WaitForTransaction(
 	client ethclient.Client,
 	tx *types.Transaction,
 ) (*types.Receipt, bool, error) {
+	var replacedErr error
 	receipt, err := utils.Retry(
-		func(ctx context.Context) (*types.Receipt, error) { return bind.WaitMined(ctx, client, tx) },
+		func(ctx context.Context) (*types.Receipt, error) {
+			receipt, err := waitMined(ctx, client, tx)
+			if errors.Is(err, ErrTxReplaced) {
+				// no point in retrying
+				replacedErr = err
+				return nil, nil
+			}
+			return receipt, err
+		},
 		constants.APIRequestLargeTimeout,
 		repeatsOnFailure,
 		fmt.Sprintf("failure waiting for tx %#v on client %#v", tx, client),
 	)
+	if replacedErr != nil {
+		return nil, false, replacedErr
+	}
 	var success bool
 	if receipt != nil {
 		success = receipt.Status == types.ReceiptStatusSuccessful
@@ -286,9 +314,15 @@ func GetEventFromLogs[T any](logs []*types.Log, parser func(log types.Log) (T, e
 	return *new(T), fmt.Errorf("failed to find %T event in receipt logs: [%s]", *new(T), cumErrMsg)
 }
 
-func GetRPCClient(rpcURL string) (*rpc.Client, error) {
+// GetRPCClient creates a raw RPC client for [rpcURL]. See ClientOption for the
+// available connection settings
+func GetRPCClient(rpcURL string, opts ...ClientOption) (*rpc.Client, error) {
+	rpcOpts, err := newClientOptions(opts).rpcOptions()
+	if err != nil {
+		return nil, err
+	}
 	return utils.Retry(
-		func(ctx context.Context) (*rpc.Client, error) { return rpc.DialContext(ctx, rpcURL) },
+		func(ctx context.Context) (*rpc.Client, error) { return rpc.DialOptions(ctx, rpcURL, rpcOpts...) },
 		constants.APIRequestLargeTimeout,
 		repeatsOnFailure,
 		fmt.Sprintf("failure connecting to %s", rpcURL),
@@ -324,3 +358,27 @@ func GetTrace(rpcURL string, txID string) (map[string]interface{}, error) {
 	}
 	return DebugTraceTransaction(client, txID)
 }
+
+// GetActivePrecompilesAt returns the configs of the precompiles active at [timestamp],
+// keyed by precompile config key (eg "warpConfig"). If [timestamp] is nil, the
+// current time is used
+func GetActivePrecompilesAt(
+	client *rpc.Client,
+	timestamp *uint64,
+) (map[string]map[string]interface{}, error) {
+	var precompiles map[string]map[string]interface{}
+	_, err := utils.Retry(
+		func(ctx context.Context) (interface{}, error) {
+			return nil, client.CallContext(
+				ctx,
+				&precompiles,
+				"eth_getActivePrecompilesAt",
+				timestamp,
+			)
+		},
+		constants.APIRequestLargeTimeout,
+		repeatsOnFailure,
+		fmt.Sprintf("failure getting active precompiles for client %#v", client),
+	)
+	return precompiles, err
+}
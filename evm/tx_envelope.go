@@ -0,0 +1,192 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrTxNotSigned   = errors.New("tx is not signed")
+	ErrTxSigned      = errors.New("tx is already signed")
+	ErrUnexpectedKey = errors.New("key does not match the tx sender")
+)
+
+// TxEnvelope is the file format of an EVM tx going through an offline signing workflow,
+// like the one used for P-Chain multisig txs: the tx is built by one party, signed by
+// the holder of the [From] key, and issued by anyone.
+//
+// The tx is encoded with the EIP-2718 typed tx JSON format
+type TxEnvelope struct {
+	// From is the account expected to sign the tx
+	From common.Address `json:"from"`
+	// Description is a human readable note on the tx purpose, for the signer to review
+	Description string             `json:"description,omitempty"`
+	Tx          *types.Transaction `json:"tx"`
+}
+
+// NewTxEnvelope creates an envelope for [tx], to be signed by [from]
+func NewTxEnvelope(tx *types.Transaction, from common.Address, description string) *TxEnvelope {
+	return &TxEnvelope{
+		From:        from,
+		Description: description,
+		Tx:          tx,
+	}
+}
+
+// Signed indicates if the tx has a signature
+func (e *TxEnvelope) Signed() bool {
+	v, r, s := e.Tx.RawSignatureValues()
+	return v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0
+}
+
+// Sender recovers the account that signed the tx
+func (e *TxEnvelope) Sender() (common.Address, error) {
+	if !e.Signed() {
+		return common.Address{}, ErrTxNotSigned
+	}
+	return types.Sender(types.LatestSignerForChainID(e.Tx.ChainId()), e.Tx)
+}
+
+// Sign signs the tx with [privateKey], that must be the key of [From]
+func (e *TxEnvelope) Sign(privateKey string) error {
+	if e.Signed() {
+		return ErrTxSigned
+	}
+	key, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return err
+	}
+	if address := crypto.PubkeyToAddress(key.PublicKey); address != e.From {
+		return fmt.Errorf("%w: key is for %s, tx is from %s", ErrUnexpectedKey, address.Hex(), e.From.Hex())
+	}
+	signedTx, err := types.SignTx(e.Tx, types.LatestSignerForChainID(e.Tx.ChainId()), key)
+	if err != nil {
+		return err
+	}
+	e.Tx = signedTx
+	return nil
+}
+
+// Issue sends the signed tx to [client] and waits for it to be accepted
+func (e *TxEnvelope) Issue(client ethclient.Client) (*types.Receipt, error) {
+	sender, err := e.Sender()
+	if err != nil {
+		return nil, err
+	}
+	if sender != e.From {
+		return nil, fmt.Errorf("%w: tx signed by %s, expected %s", ErrUnexpectedKey, sender.Hex(), e.From.Hex())
+	}
+	if err := SendTransaction(client, e.Tx); err != nil {
+		return nil, err
+	}
+	receipt, success, err := WaitForTransaction(client, e.Tx)
+	if err != nil {
+		return receipt, err
+	} else if !success {
+		return receipt, ErrFailedReceiptStatus
+	}
+	return receipt, nil
+}
+
+// ToFile saves the envelope to [path]
+func (e *TxEnvelope) ToFile(path string) error {
+	envelopeBytes, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode tx envelope: %w", err)
+	}
+	if err := os.WriteFile(path, envelopeBytes, 0o600); err != nil {
+		return fmt.Errorf("couldn't write tx envelope into file: %w", err)
+	}
+	return nil
+}
+
+// TxEnvelopeFromFile loads an envelope saved with ToFile
+func TxEnvelopeFromFile(path string) (*TxEnvelope, error) {
+	envelopeBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	envelope := TxEnvelope{}
+	if err := json.Unmarshal(envelopeBytes, &envelope); err != nil {
+		return nil, fmt.Errorf("couldn't decode tx envelope: %w", err)
+	}
+	if envelope.Tx == nil {
+		return nil, fmt.Errorf("tx envelope at %s has no tx", path)
+	}
+	return &envelope, nil
+}
+
+// BuildTxToMethod builds, without signing it, a tx from [from] that calls [methodSignature]
+// on [contractAddress], as TxToMethod would send it. Nonce, fees and gas limit are
+// filled from the chain state, so the tx should be signed and issued before [from] sends
+// any other tx
+func BuildTxToMethod(
+	rpcURL string,
+	from common.Address,
+	contractAddress common.Address,
+	payment *big.Int,
+	methodSignature string,
+	params ...interface{},
+) (*types.Transaction, error) {
+	paymentKind := NonPayable
+	if payment != nil {
+		paymentKind = Payable
+	}
+	methodName, methodABI, err := ParseMethodSignature(methodSignature, Method, nil, paymentKind, params...)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &bind.MetaData{
+		ABI: methodABI,
+	}
+	abi, err := metadata.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	client, err := GetClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	chainID, err := GetChainID(client)
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(contractAddress, *abi, client, client, client)
+	txOpts := &bind.TransactOpts{
+		From: from,
+		// keeps the tx unsigned
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+		Value:  payment,
+		NoSend: true,
+	}
+	tx, err := contract.Transact(txOpts, methodName, params...)
+	if err != nil {
+		return nil, err
+	}
+	// Transact doesn't set the chain ID on unsigned txs
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      tx.Nonce(),
+		GasTipCap:  tx.GasTipCap(),
+		GasFeeCap:  tx.GasFeeCap(),
+		Gas:        tx.Gas(),
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}), nil
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxEnvelope(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	privateKey := common.Bytes2Hex(crypto.FromECDSA(key))
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x0Feedc0de0000000000000000000000000000000")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(43113),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(25_000_000_000),
+		Gas:       100_000,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      []byte{1, 2, 3, 4},
+	})
+	envelope := NewTxEnvelope(tx, from, "transfer ownership")
+	require.False(envelope.Signed())
+	_, err = envelope.Sender()
+	require.ErrorIs(err, ErrTxNotSigned)
+
+	path := filepath.Join(t.TempDir(), "tx.json")
+	require.NoError(envelope.ToFile(path))
+	fileBytes, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Contains(string(fileBytes), `"type": "0x2"`)
+	loaded, err := TxEnvelopeFromFile(path)
+	require.NoError(err)
+	require.Equal(from, loaded.From)
+	require.Equal("transfer ownership", loaded.Description)
+	require.Equal(tx.Hash(), loaded.Tx.Hash())
+	require.False(loaded.Signed())
+
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(err)
+	require.ErrorIs(loaded.Sign(common.Bytes2Hex(crypto.FromECDSA(otherKey))), ErrUnexpectedKey)
+	require.NoError(loaded.Sign(privateKey))
+	require.True(loaded.Signed())
+	require.ErrorIs(loaded.Sign(privateKey), ErrTxSigned)
+
+	require.NoError(loaded.ToFile(path))
+	signed, err := TxEnvelopeFromFile(path)
+	require.NoError(err)
+	require.True(signed.Signed())
+	sender, err := signed.Sender()
+	require.NoError(err)
+	require.Equal(from, sender)
+	require.Equal(loaded.Tx.Hash(), signed.Tx.Hash())
+	require.Equal(big.NewInt(43113), signed.Tx.ChainId())
+}
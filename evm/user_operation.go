@@ -0,0 +1,488 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EntryPointV06Address is the canonical address of the ERC-4337 v0.6 EntryPoint contract
+var EntryPointV06Address = common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+// dummySignature is a well formed ECDSA signature used while estimating gas, so the
+// account signature validation runs its full path without reverting
+var dummySignature = common.FromHex("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+var (
+	ErrAccountNotDeployed   = errors.New("smart account is not deployed and no init code was given")
+	ErrUserOperationFailed  = errors.New("user operation execution failed")
+	ErrUserOperationTimeout = errors.New("timeout waiting for user operation")
+	ErrPaymasterGasMismatch = errors.New("paymaster sponsored different gas fields than the estimated ones")
+)
+
+// UserOperation is an ERC-4337 v0.6 user operation
+type UserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+type userOperationJSON struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// MarshalJSON encodes the operation in the format expected by bundlers
+func (op *UserOperation) MarshalJSON() ([]byte, error) {
+	bigOrZero := func(n *big.Int) *hexutil.Big {
+		if n == nil {
+			return (*hexutil.Big)(new(big.Int))
+		}
+		return (*hexutil.Big)(n)
+	}
+	return json.Marshal(userOperationJSON{
+		Sender:               op.Sender,
+		Nonce:                bigOrZero(op.Nonce),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         bigOrZero(op.CallGasLimit),
+		VerificationGasLimit: bigOrZero(op.VerificationGasLimit),
+		PreVerificationGas:   bigOrZero(op.PreVerificationGas),
+		MaxFeePerGas:         bigOrZero(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: bigOrZero(op.MaxPriorityFeePerGas),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	})
+}
+
+// Hash computes the hash of the operation as the v0.6 EntryPoint at [entryPoint] does on
+// chain [chainID]. The signature is not part of the hash
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	addressType, _ := abi.NewType("address", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytes32Type, _ := abi.NewType("bytes32", "", nil)
+	zeroIfNil := func(n *big.Int) *big.Int {
+		if n == nil {
+			return new(big.Int)
+		}
+		return n
+	}
+	packed, err := abi.Arguments{
+		{Type: addressType},
+		{Type: uint256Type},
+		{Type: bytes32Type},
+		{Type: bytes32Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: bytes32Type},
+	}.Pack(
+		op.Sender,
+		zeroIfNil(op.Nonce),
+		crypto.Keccak256Hash(op.InitCode),
+		crypto.Keccak256Hash(op.CallData),
+		zeroIfNil(op.CallGasLimit),
+		zeroIfNil(op.VerificationGasLimit),
+		zeroIfNil(op.PreVerificationGas),
+		zeroIfNil(op.MaxFeePerGas),
+		zeroIfNil(op.MaxPriorityFeePerGas),
+		crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failure packing user operation: %w", err)
+	}
+	encoded, err := abi.Arguments{
+		{Type: bytes32Type},
+		{Type: addressType},
+		{Type: uint256Type},
+	}.Pack(crypto.Keccak256Hash(packed), entryPoint, chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failure packing user operation hash: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Sign sets the operation signature with [privateKey], as expected by the reference
+// SimpleAccount: an EIP-191 signature of the operation hash
+func (op *UserOperation) Sign(privateKey string, entryPoint common.Address, chainID *big.Int) error {
+	key, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return err
+	}
+	hash, err := op.Hash(entryPoint, chainID)
+	if err != nil {
+		return err
+	}
+	signature, err := crypto.Sign(accounts.TextHash(hash[:]), key)
+	if err != nil {
+		return err
+	}
+	// ecrecover expects v to be 27 or 28
+	signature[crypto.RecoveryIDOffset] += 27
+	op.Signature = signature
+	return nil
+}
+
+// SmartAccount is the ERC-4337 account that sends sponsored txs
+type SmartAccount struct {
+	Address common.Address
+	// InitCode is the factory address followed by the factory call data that deploys
+	// the account. It is only used if the account is not yet deployed
+	InitCode []byte
+}
+
+// Sponsor configures the submission of txs as ERC-4337 user operations through a
+// bundler, with gas paid by a paymaster, for chains that deploy account abstraction
+// infra. The bundler and paymaster are reached with the ClientOptions given to the
+// sponsored calls
+type Sponsor struct {
+	BundlerURL string
+	// PaymasterURL is the endpoint serving pm_sponsorUserOperation. If empty, no
+	// paymaster is used and the smart account pays for the gas
+	PaymasterURL string
+	// PaymasterContext is passed as is to the paymaster, eg. a sponsorship policy ID
+	PaymasterContext map[string]interface{}
+	// Paymaster is the address of the paymaster contract. It is used to build the dummy
+	// paymasterAndData the gas is estimated with, so paymaster verification is accounted
+	// for. If zero, the gas is estimated without paymaster data
+	Paymaster common.Address
+	// EntryPoint defaults to EntryPointV06Address
+	EntryPoint common.Address
+	// Timeout to wait for the operation to be included. Defaults to
+	// constants.APIRequestLargeTimeout
+	Timeout time.Duration
+}
+
+func (s Sponsor) entryPoint() common.Address {
+	if s.EntryPoint == (common.Address{}) {
+		return EntryPointV06Address
+	}
+	return s.EntryPoint
+}
+
+func (s Sponsor) timeout() time.Duration {
+	if s.Timeout == 0 {
+		return constants.APIRequestLargeTimeout
+	}
+	return s.Timeout
+}
+
+// UserOperationReceipt is the outcome of an included user operation
+type UserOperationReceipt struct {
+	UserOpHash    common.Hash
+	Success       bool
+	Reason        string
+	ActualGasCost *big.Int
+	ActualGasUsed *big.Int
+	// TxHash is the hash of the bundle tx that included the operation
+	TxHash common.Hash
+}
+
+type userOperationReceiptJSON struct {
+	UserOpHash    common.Hash  `json:"userOpHash"`
+	Success       bool         `json:"success"`
+	Reason        string       `json:"reason"`
+	ActualGasCost *hexutil.Big `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big `json:"actualGasUsed"`
+	Receipt       struct {
+		TransactionHash common.Hash `json:"transactionHash"`
+	} `json:"receipt"`
+}
+
+func (r *UserOperationReceipt) UnmarshalJSON(b []byte) error {
+	var receipt userOperationReceiptJSON
+	if err := json.Unmarshal(b, &receipt); err != nil {
+		return err
+	}
+	*r = UserOperationReceipt{
+		UserOpHash:    receipt.UserOpHash,
+		Success:       receipt.Success,
+		Reason:        receipt.Reason,
+		ActualGasCost: (*big.Int)(receipt.ActualGasCost),
+		ActualGasUsed: (*big.Int)(receipt.ActualGasUsed),
+		TxHash:        receipt.Receipt.TransactionHash,
+	}
+	return nil
+}
+
+// userOperationGas are the gas fields returned by gas estimation and sponsorship
+type userOperationGas struct {
+	PaymasterAndData     hexutil.Bytes `json:"paymasterAndData"`
+	CallGasLimit         *hexutil.Big  `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big  `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big  `json:"preVerificationGas"`
+}
+
+// matches tells if the gas fields set in [g] are equal to the ones of [op]
+func (g userOperationGas) matches(op *UserOperation) bool {
+	equal := func(a *hexutil.Big, b *big.Int) bool {
+		return a == nil || (b != nil && a.ToInt().Cmp(b) == 0)
+	}
+	return equal(g.CallGasLimit, op.CallGasLimit) &&
+		equal(g.VerificationGasLimit, op.VerificationGasLimit) &&
+		equal(g.PreVerificationGas, op.PreVerificationGas)
+}
+
+// apply sets the gas fields of [op] that are not set yet
+func (g userOperationGas) apply(op *UserOperation) {
+	if op.CallGasLimit == nil && g.CallGasLimit != nil {
+		op.CallGasLimit = g.CallGasLimit.ToInt()
+	}
+	if op.VerificationGasLimit == nil && g.VerificationGasLimit != nil {
+		op.VerificationGasLimit = g.VerificationGasLimit.ToInt()
+	}
+	if op.PreVerificationGas == nil && g.PreVerificationGas != nil {
+		op.PreVerificationGas = g.PreVerificationGas.ToInt()
+	}
+}
+
+// BuildExecuteCallData encodes a call to execute(address,uint256,bytes) on a SimpleAccount,
+// that makes the account call [to] with [value] and [data]
+func BuildExecuteCallData(to common.Address, value *big.Int, data []byte) ([]byte, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return packMethod("execute(address,uint256,bytes)", to, value, data)
+}
+
+func packMethod(methodSignature string, params ...interface{}) ([]byte, error) {
+	methodName, methodABI, err := ParseMethodSignature(methodSignature, Method, nil, NonPayable, params...)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &bind.MetaData{
+		ABI: methodABI,
+	}
+	abi, err := metadata.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return abi.Pack(methodName, params...)
+}
+
+// SponsoredTxToMethod makes [account] call [methodSignature] on [contractAddress] with a
+// user operation signed by [privateKey], the account owner, and submitted through
+// [sponsor]. See SendSponsoredTx
+func SponsoredTxToMethod(
+	rpcURL string,
+	sponsor Sponsor,
+	privateKey string,
+	account SmartAccount,
+	contractAddress common.Address,
+	payment *big.Int,
+	methodSignature string,
+	params ...interface{},
+) (*UserOperationReceipt, error) {
+	data, err := packMethod(methodSignature, params...)
+	if err != nil {
+		return nil, err
+	}
+	return SendSponsoredTx(rpcURL, sponsor, privateKey, account, contractAddress, payment, data)
+}
+
+// SendSponsoredTx makes [account] call [to] with [value] and [data], by means of a user
+// operation signed by [privateKey], the account owner, and submitted to the bundler
+// of [sponsor], so the gas is paid by its paymaster and the owner doesn't need to hold
+// native tokens. [rpcURL] is the chain RPC endpoint, used to get the account nonce and
+// the fees. Waits for the operation to be included, and returns ErrUserOperationFailed
+// if its execution reverted
+func SendSponsoredTx(
+	rpcURL string,
+	sponsor Sponsor,
+	privateKey string,
+	account SmartAccount,
+	to common.Address,
+	value *big.Int,
+	data []byte,
+	opts ...ClientOption,
+) (*UserOperationReceipt, error) {
+	client, err := GetClient(rpcURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	bundler, err := GetRPCClient(sponsor.BundlerURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer bundler.Close()
+	paymaster := bundler
+	if sponsor.PaymasterURL != "" && sponsor.PaymasterURL != sponsor.BundlerURL {
+		if paymaster, err = GetRPCClient(sponsor.PaymasterURL, opts...); err != nil {
+			return nil, err
+		}
+		defer paymaster.Close()
+	}
+	chainID, err := GetChainID(client)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := BuildExecuteCallData(to, value, data)
+	if err != nil {
+		return nil, err
+	}
+	op, err := buildUserOperation(client, sponsor.entryPoint(), account, callData)
+	if err != nil {
+		return nil, err
+	}
+	// gas is estimated with a dummy signature and paymaster data, as the real ones
+	// depend on the gas fields
+	op.Signature = dummySignature
+	if sponsor.PaymasterURL != "" && sponsor.Paymaster != (common.Address{}) {
+		op.PaymasterAndData = dummyPaymasterAndData(sponsor.Paymaster)
+	}
+	var estimatedGas userOperationGas
+	if err := callRPC(bundler, &estimatedGas, "eth_estimateUserOperationGas", op, sponsor.entryPoint()); err != nil {
+		return nil, fmt.Errorf("failure estimating user operation gas: %w", err)
+	}
+	estimatedGas.apply(op)
+	if sponsor.PaymasterURL != "" {
+		// the paymaster signs over the gas and fee fields, so the operation must not
+		// change after being sponsored
+		var sponsoredGas userOperationGas
+		if err := callRPC(paymaster, &sponsoredGas, "pm_sponsorUserOperation", op, sponsor.entryPoint(), sponsor.PaymasterContext); err != nil {
+			return nil, fmt.Errorf("failure sponsoring user operation: %w", err)
+		}
+		if !sponsoredGas.matches(op) {
+			return nil, ErrPaymasterGasMismatch
+		}
+		op.PaymasterAndData = sponsoredGas.PaymasterAndData
+	}
+	if err := op.Sign(privateKey, sponsor.entryPoint(), chainID); err != nil {
+		return nil, err
+	}
+	var userOpHash common.Hash
+	if err := callRPC(bundler, &userOpHash, "eth_sendUserOperation", op, sponsor.entryPoint()); err != nil {
+		return nil, fmt.Errorf("failure sending user operation: %w", err)
+	}
+	receipt, err := waitForUserOperation(bundler, userOpHash, sponsor.timeout())
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Success {
+		return receipt, fmt.Errorf("%w: %s %s", ErrUserOperationFailed, userOpHash, receipt.Reason)
+	}
+	return receipt, nil
+}
+
+// dummyPaymasterAndData is a v0.6 VerifyingPaymaster paymasterAndData for [paymaster],
+// with zero validity bounds and a dummy signature, used while estimating gas
+func dummyPaymasterAndData(paymaster common.Address) []byte {
+	data := append(paymaster.Bytes(), make([]byte, 64)...)
+	return append(data, dummySignature...)
+}
+
+// buildUserOperation creates an unsigned operation for [account], with the nonce and fees
+// taken from the chain, and the init code set if the account is not deployed
+func buildUserOperation(
+	client ethclient.Client,
+	entryPoint common.Address,
+	account SmartAccount,
+	callData []byte,
+) (*UserOperation, error) {
+	ctx, cancel := utils.GetAPIContext()
+	code, err := client.CodeAt(ctx, account.Address, nil)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failure getting code of %s: %w", account.Address.Hex(), err)
+	}
+	var initCode []byte
+	if len(code) == 0 {
+		if len(account.InitCode) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotDeployed, account.Address.Hex())
+		}
+		initCode = account.InitCode
+	}
+	getNonceData, err := packMethod("getNonce(address,uint192)", account.Address, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel = utils.GetAPIContext()
+	out, err := client.CallContract(ctx, interfaces.CallMsg{To: &entryPoint, Data: getNonceData}, nil)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failure getting entry point nonce of %s: %w", account.Address.Hex(), err)
+	}
+	baseFee, err := EstimateBaseFee(client)
+	if err != nil {
+		return nil, err
+	}
+	gasTipCap, err := SuggestGasTipCap(client)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap := baseFee.Mul(baseFee, big.NewInt(BaseFeeFactor))
+	gasFeeCap.Add(gasFeeCap, big.NewInt(MaxPriorityFeePerGas))
+	return &UserOperation{
+		Sender:               account.Address,
+		Nonce:                new(big.Int).SetBytes(out),
+		InitCode:             initCode,
+		CallData:             callData,
+		MaxFeePerGas:         gasFeeCap,
+		MaxPriorityFeePerGas: gasTipCap,
+	}, nil
+}
+
+func callRPC(client *rpc.Client, result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := utils.GetAPILargeContext()
+	defer cancel()
+	return client.CallContext(ctx, result, method, args...)
+}
+
+// waitForUserOperation polls the bundler for the receipt of [userOpHash]
+func waitForUserOperation(bundler *rpc.Client, userOpHash common.Hash, timeout time.Duration) (*UserOperationReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ticker := time.NewTicker(waitMinedPollInterval)
+	defer ticker.Stop()
+	for {
+		var receipt *UserOperationReceipt
+		if err := bundler.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", userOpHash); err != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("failure getting user operation receipt: %w", err)
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w %s", ErrUserOperationTimeout, userOpHash)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBundler struct {
+	lock      sync.Mutex
+	code      string
+	methods   []string
+	sent      userOperationJSON
+	sponsor   []json.RawMessage
+	receipts  int
+	sponsored userOperationJSON
+	estimated userOperationJSON
+	// sponsorGas are gas fields returned by the paymaster along paymasterAndData
+	sponsorGas map[string]string
+}
+
+func (b *fakeBundler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b.methods = append(b.methods, req.Method)
+	var result interface{}
+	switch req.Method {
+	case "eth_chainId":
+		result = "0xa868"
+	case "eth_getCode":
+		result = b.code
+	case "eth_call":
+		result = hexutil.Encode(common.LeftPadBytes([]byte{7}, 32))
+	case "eth_baseFee":
+		result = "0x5d21dba00"
+	case "eth_maxPriorityFeePerGas":
+		result = "0x1"
+	case "pm_sponsorUserOperation":
+		b.sponsor = req.Params
+		_ = json.Unmarshal(req.Params[0], &b.sponsored)
+		sponsored := map[string]string{"paymasterAndData": "0xaabbcc"}
+		for k, v := range b.sponsorGas {
+			sponsored[k] = v
+		}
+		result = sponsored
+	case "eth_estimateUserOperationGas":
+		_ = json.Unmarshal(req.Params[0], &b.estimated)
+		result = map[string]string{
+			"callGasLimit":         "0x400",
+			"verificationGasLimit": "0x500",
+			"preVerificationGas":   "0x600",
+		}
+	case "eth_sendUserOperation":
+		_ = json.Unmarshal(req.Params[0], &b.sent)
+		result = common.Hash{1}.Hex()
+	case "eth_getUserOperationReceipt":
+		b.receipts++
+		if b.receipts > 1 {
+			result = map[string]interface{}{
+				"userOpHash":    common.Hash{1}.Hex(),
+				"success":       true,
+				"actualGasCost": "0x10",
+				"actualGasUsed": "0x20",
+				"receipt":       map[string]string{"transactionHash": common.Hash{2}.Hex()},
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func TestSendSponsoredTx(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	privateKey := hexutil.Encode(crypto.FromECDSA(key))[2:]
+	account := SmartAccount{Address: common.Address{9}, InitCode: []byte{0xfa, 0xc7}}
+	to := common.Address{5}
+
+	bundler := &fakeBundler{code: "0x"}
+	server := httptest.NewServer(bundler)
+	defer server.Close()
+	sponsor := Sponsor{
+		BundlerURL:       server.URL,
+		PaymasterURL:     server.URL,
+		PaymasterContext: map[string]interface{}{"policyId": "onboarding"},
+	}
+
+	receipt, err := SendSponsoredTx(server.URL, sponsor, privateKey, account, to, big.NewInt(3), []byte{1, 2})
+	require.NoError(err)
+	require.True(receipt.Success)
+	require.Equal(common.Hash{2}, receipt.TxHash)
+	require.Equal(big.NewInt(0x10), receipt.ActualGasCost)
+	require.Equal(2, bundler.receipts)
+
+	require.Len(bundler.sponsor, 3)
+	require.JSONEq(`{"policyId":"onboarding"}`, string(bundler.sponsor[2]))
+	sent := bundler.sent
+	require.Equal(account.Address, sent.Sender)
+	require.Equal(int64(7), sent.Nonce.ToInt().Int64())
+	require.Equal(hexutil.Bytes(account.InitCode), sent.InitCode)
+	require.Equal(hexutil.Bytes{0xaa, 0xbb, 0xcc}, sent.PaymasterAndData)
+	require.Equal(int64(0x600), sent.PreVerificationGas.ToInt().Int64())
+	callData, err := BuildExecuteCallData(to, big.NewInt(3), []byte{1, 2})
+	require.NoError(err)
+	require.Equal(hexutil.Bytes(callData), sent.CallData)
+
+	// the signature is an EIP-191 signature of the operation hash by the owner
+	op := UserOperation{
+		Sender:               sent.Sender,
+		Nonce:                sent.Nonce.ToInt(),
+		InitCode:             sent.InitCode,
+		CallData:             sent.CallData,
+		CallGasLimit:         sent.CallGasLimit.ToInt(),
+		VerificationGasLimit: sent.VerificationGasLimit.ToInt(),
+		PreVerificationGas:   sent.PreVerificationGas.ToInt(),
+		MaxFeePerGas:         sent.MaxFeePerGas.ToInt(),
+		MaxPriorityFeePerGas: sent.MaxPriorityFeePerGas.ToInt(),
+		PaymasterAndData:     sent.PaymasterAndData,
+	}
+	hash, err := op.Hash(EntryPointV06Address, big.NewInt(0xa868))
+	require.NoError(err)
+	signature := common.CopyBytes(sent.Signature)
+	require.Len(signature, crypto.SignatureLength)
+	signature[crypto.RecoveryIDOffset] -= 27
+	pubKey, err := crypto.SigToPub(accounts.TextHash(hash[:]), signature)
+	require.NoError(err)
+	require.Equal(crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pubKey))
+
+	// the hash commits to the chain
+	otherHash, err := op.Hash(EntryPointV06Address, big.NewInt(43114))
+	require.NoError(err)
+	require.NotEqual(hash, otherHash)
+
+	// undeployed account without init code
+	_, err = SendSponsoredTx(server.URL, sponsor, privateKey, SmartAccount{Address: account.Address}, to, nil, nil)
+	require.ErrorIs(err, ErrAccountNotDeployed)
+}
+
+func TestSendSponsoredTxEstimatesGas(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	privateKey := hexutil.Encode(crypto.FromECDSA(key))[2:]
+	account := SmartAccount{Address: common.Address{9}, InitCode: []byte{0xfa, 0xc7}}
+
+	bundler := &fakeBundler{code: "0x"}
+	server := httptest.NewServer(bundler)
+	defer server.Close()
+	sponsor := Sponsor{
+		BundlerURL:   server.URL,
+		PaymasterURL: server.URL,
+		Paymaster:    common.Address{8},
+	}
+	_, err = SendSponsoredTx(server.URL, sponsor, privateKey, account, common.Address{5}, nil, nil)
+	require.NoError(err)
+	// gas is estimated before sponsoring, with a dummy paymasterAndData
+	require.Less(indexOf(bundler.methods, "eth_estimateUserOperationGas"), indexOf(bundler.methods, "pm_sponsorUserOperation"))
+	require.Equal(hexutil.Bytes(dummyPaymasterAndData(common.Address{8})), bundler.estimated.PaymasterAndData)
+	require.Len(bundler.estimated.PaymasterAndData, common.AddressLength+64+crypto.SignatureLength)
+	// the paymaster is given the final gas and fee fields
+	sponsored := bundler.sponsored
+	require.Equal(int64(0x400), sponsored.CallGasLimit.ToInt().Int64())
+	require.Equal(int64(0x500), sponsored.VerificationGasLimit.ToInt().Int64())
+	require.Equal(int64(0x600), sponsored.PreVerificationGas.ToInt().Int64())
+	// and only paymasterAndData changes afterwards
+	sent := bundler.sent
+	require.Equal(hexutil.Bytes{0xaa, 0xbb, 0xcc}, sent.PaymasterAndData)
+	require.Equal(sponsored.CallGasLimit, sent.CallGasLimit)
+	require.Equal(sponsored.VerificationGasLimit, sent.VerificationGasLimit)
+	require.Equal(sponsored.PreVerificationGas, sent.PreVerificationGas)
+	require.Equal(sponsored.MaxFeePerGas, sent.MaxFeePerGas)
+	require.Equal(sponsored.MaxPriorityFeePerGas, sent.MaxPriorityFeePerGas)
+
+	// a paymaster returning the same gas fields is accepted
+	bundler.sponsorGas = map[string]string{"callGasLimit": "0x400", "preVerificationGas": "0x600"}
+	_, err = SendSponsoredTx(server.URL, sponsor, privateKey, account, common.Address{5}, nil, nil)
+	require.NoError(err)
+
+	// a paymaster changing the gas fields would invalidate its own signature
+	bundler.sponsorGas = map[string]string{"callGasLimit": "0x100"}
+	bundler.sent = userOperationJSON{}
+	_, err = SendSponsoredTx(server.URL, sponsor, privateKey, account, common.Address{5}, nil, nil)
+	require.ErrorIs(err, ErrPaymasterGasMismatch)
+	require.Nil(bundler.sent.CallGasLimit)
+
+	// without paymaster, the bundler estimation is used
+	bundler.sponsorGas = nil
+	bundler.methods = nil
+	_, err = SendSponsoredTx(server.URL, Sponsor{BundlerURL: server.URL}, privateKey, account, common.Address{5}, nil, nil)
+	require.NoError(err)
+	require.Contains(bundler.methods, "eth_estimateUserOperationGas")
+	require.NotContains(bundler.methods, "pm_sponsorUserOperation")
+	require.Empty(bundler.estimated.PaymasterAndData)
+	require.Empty(bundler.sent.PaymasterAndData)
+	require.Equal(int64(0x400), bundler.sent.CallGasLimit.ToInt().Int64())
+}
+
+func indexOf(methods []string, method string) int {
+	for i, m := range methods {
+		if m == method {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	waitMinedPollInterval = time.Second
+	// replacementLookbackBlocks is the number of blocks before the start of the wait
+	// that are searched for a replacement tx, as it may have been accepted before
+	replacementLookbackBlocks = 128
+)
+
+var ErrTxReplaced = errors.New("tx was replaced by another tx with the same nonce")
+
+// TxReplacedError is returned when waiting for a tx whose nonce was consumed by a
+// different tx from the same sender (eg a speed up or a cancellation)
+type TxReplacedError struct {
+	TxHash common.Hash
+	// ReplacementHash is the hash of the accepted tx. It is empty if the tx
+	// could not be found
+	ReplacementHash common.Hash
+}
+
+func (e *TxReplacedError) Error() string {
+	if e.ReplacementHash == (common.Hash{}) {
+		return fmt.Sprintf("tx %s was replaced by an unknown tx", e.TxHash)
+	}
+	return fmt.Sprintf("tx %s was replaced by tx %s", e.TxHash, e.ReplacementHash)
+}
+
+func (*TxReplacedError) Unwrap() error {
+	return ErrTxReplaced
+}
+
+// waitClient contains the client methods used to wait for a tx
+type waitClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// waitMined waits for [tx] to be accepted, returning its receipt. If the sender nonce
+// gets past the tx nonce without the tx being accepted, a *TxReplacedError is returned
+func waitMined(ctx context.Context, client waitClient, tx *types.Transaction) (*types.Receipt, error) {
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failure obtaining sender of tx %s: %w", tx.Hash(), err)
+	}
+	startBlock, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(waitMinedPollInterval)
+	defer ticker.Stop()
+	for {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, interfaces.NotFound) {
+			return nil, err
+		}
+		nonce, err := client.NonceAt(ctx, sender, nil)
+		if err != nil {
+			return nil, err
+		}
+		if nonce > tx.Nonce() {
+			// the tx may have been accepted after the receipt was requested
+			if receipt, err := client.TransactionReceipt(ctx, tx.Hash()); err == nil {
+				return receipt, nil
+			}
+			replacementHash, err := findReplacement(ctx, client, sender, tx, startBlock)
+			if err != nil {
+				return nil, err
+			}
+			return nil, &TxReplacedError{TxHash: tx.Hash(), ReplacementHash: replacementHash}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// findReplacement searches the accepted blocks, starting a bit before [startBlock], for a
+// tx from [sender] with the same nonce as [tx]
+func findReplacement(
+	ctx context.Context,
+	client waitClient,
+	sender common.Address,
+	tx *types.Transaction,
+	startBlock uint64,
+) (common.Hash, error) {
+	lastBlock, err := client.BlockNumber(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	firstBlock := uint64(0)
+	if startBlock > replacementLookbackBlocks {
+		firstBlock = startBlock - replacementLookbackBlocks
+	}
+	// search backwards, as the replacement is most likely recent
+	for n := lastBlock; n+1 > firstBlock; n-- {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return common.Hash{}, err
+		}
+		for _, blockTx := range block.Transactions() {
+			if blockTx.Nonce() != tx.Nonce() {
+				continue
+			}
+			blockTxSender, err := types.Sender(types.LatestSignerForChainID(blockTx.ChainId()), blockTx)
+			if err == nil && blockTxSender == sender {
+				return blockTx.Hash(), nil
+			}
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return common.Hash{}, nil
+}
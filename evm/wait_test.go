@@ -0,0 +1,111 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWaitClient struct {
+	receipts map[common.Hash]*types.Receipt
+	nonces   map[common.Address]uint64
+	blocks   []*types.Block
+}
+
+func (c *fakeWaitClient) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, interfaces.NotFound
+}
+
+func (c *fakeWaitClient) NonceAt(_ context.Context, account common.Address, _ *big.Int) (uint64, error) {
+	return c.nonces[account], nil
+}
+
+func (c *fakeWaitClient) BlockNumber(context.Context) (uint64, error) {
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *fakeWaitClient) BlockByNumber(_ context.Context, number *big.Int) (*types.Block, error) {
+	return c.blocks[number.Uint64()], nil
+}
+
+func newTestBlock(number int64, txs ...*types.Transaction) *types.Block {
+	return types.NewBlock(&types.Header{Number: big.NewInt(number)}, txs, nil, nil, trie.NewStackTrie(nil))
+}
+
+func TestWaitMinedReplaced(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(43114)
+	to := common.Address{1}
+	signTx := func(nonce uint64, tip int64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       NativeTransferGas,
+			GasFeeCap: big.NewInt(100),
+			GasTipCap: big.NewInt(tip),
+		}), types.LatestSignerForChainID(chainID), key)
+		require.NoError(err)
+		return tx
+	}
+	original := signTx(5, 1)
+	replacement := signTx(5, 2)
+	other := signTx(4, 1)
+
+	client := &fakeWaitClient{
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{sender: 6},
+		blocks:   []*types.Block{newTestBlock(0), newTestBlock(1, other), newTestBlock(2, replacement), newTestBlock(3)},
+	}
+	_, err = waitMined(context.Background(), client, original)
+	require.ErrorIs(err, ErrTxReplaced)
+	var replacedErr *TxReplacedError
+	require.ErrorAs(err, &replacedErr)
+	require.Equal(original.Hash(), replacedErr.TxHash)
+	require.Equal(replacement.Hash(), replacedErr.ReplacementHash)
+
+	// replacement not found in the searched blocks
+	client.blocks = []*types.Block{newTestBlock(0)}
+	_, err = waitMined(context.Background(), client, original)
+	require.ErrorAs(err, &replacedErr)
+	require.Equal(common.Hash{}, replacedErr.ReplacementHash)
+
+	// accepted tx returns its receipt even if the nonce moved on
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: original.Hash()}
+	client.receipts[original.Hash()] = receipt
+	got, err := waitMined(context.Background(), client, original)
+	require.NoError(err)
+	require.Equal(receipt, got)
+}
+
+func TestWaitMinedCanceled(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	chainID := big.NewInt(43114)
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3}), types.LatestSignerForChainID(chainID), key)
+	require.NoError(err)
+	client := &fakeWaitClient{
+		nonces: map[common.Address]uint64{},
+		blocks: []*types.Block{newTestBlock(0)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = waitMined(ctx, client, tx)
+	require.ErrorIs(err, context.Canceled)
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ava-labs/subnet-evm/predicate"
+	"github.com/ava-labs/subnet-evm/rpc"
+	subnetEvmUtils "github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrNoWarpPredicates = errors.New("tx has no warp predicates")
+
+// WarpPredicateCheck is the result of verifying one of the warp predicates of a tx
+type WarpPredicateCheck struct {
+	// Index is the position of the predicate among the warp predicates of the tx, as
+	// passed to getVerifiedWarpMessage
+	Index     int
+	MessageID ids.ID
+	// SourceChainID is the chain that sent the message
+	SourceChainID ids.ID
+	// SignerSubnetID is the subnet whose validators must sign the message. For messages
+	// sent from the primary network, it is the subnet of the receiving chain
+	SignerSubnetID  ids.ID
+	PChainHeight    uint64
+	NumSigners      int
+	SignedWeight    uint64
+	TotalWeight     uint64
+	QuorumNumerator uint64
+	// MarkedValidByBlock tells whether the predicate results of the block that
+	// included the tx flagged the predicate as valid. It is nil for pending txs
+	MarkedValidByBlock *bool
+	// Err is the reason the predicate doesn't verify, or nil if it does
+	Err error
+}
+
+// Valid returns true if the predicate verified against the validator set
+func (c WarpPredicateCheck) Valid() bool {
+	return c.Err == nil
+}
+
+// VerifyWarpPredicates checks the warp predicates of tx [txHash], issued to chain
+// [blockchainID] at [rpcURL], against the validator set of the P-Chain of [network] at
+// [pChainHeight], the same way the VM does on block verification. Each predicate is
+// reported separately, together with the signed and total weights used in the quorum
+// check and the result recorded in the block, if the tx was accepted.
+//
+// The P-Chain height a block is verified at is set by the ProposerVM block that
+// wraps it, and is not part of the EVM header. If [pChainHeight] is 0, the current
+// P-Chain height is used, which is what a tx about to be issued gets checked against
+func VerifyWarpPredicates(
+	rpcURL string,
+	network avalanche.Network,
+	blockchainID ids.ID,
+	txHash common.Hash,
+	pChainHeight uint64,
+) ([]WarpPredicateCheck, error) {
+	rpcClient, err := GetRPCClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	client := ethclient.NewClient(rpcClient)
+	ctx, cancel := utils.GetAPILargeContext()
+	defer cancel()
+	tx, isPending, err := client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting tx %s: %w", txHash, err)
+	}
+	predicates := GetWarpPredicates(tx)
+	if len(predicates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoWarpPredicates, txHash)
+	}
+	var (
+		blockResults []byte
+		timestamp    *uint64
+	)
+	if !isPending {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			return nil, fmt.Errorf("failure getting receipt of tx %s: %w", txHash, err)
+		}
+		header, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failure getting header %s: %w", receipt.BlockNumber, err)
+		}
+		timestamp = &header.Time
+		if resultsBytes, ok := predicate.GetPredicateResultBytes(header.Extra); ok {
+			results, err := predicate.ParseResults(resultsBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failure parsing predicate results of block %s: %w", receipt.BlockNumber, err)
+			}
+			blockResults = results.GetResults(txHash, warp.ContractAddress)
+		} else {
+			blockResults = []byte{}
+		}
+	}
+	quorumNumerator, err := getWarpQuorumNumerator(rpcClient, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	pClient := platformvm.NewClient(network.Endpoint)
+	if pChainHeight == 0 {
+		pChainHeight, err = pClient.GetHeight(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failure getting P-Chain height: %w", err)
+		}
+	}
+	subnetID, err := pClient.ValidatedBy(ctx, blockchainID)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting subnet of blockchain %s: %w", blockchainID, err)
+	}
+	state := &pChainValidatorState{
+		client:   pClient,
+		subnetID: subnetID,
+	}
+	checks := make([]WarpPredicateCheck, len(predicates))
+	for i, predicateBytes := range predicates {
+		checks[i] = verifyWarpPredicate(ctx, state, network.ID, subnetID, pChainHeight, quorumNumerator, predicateBytes)
+		checks[i].Index = i
+		if blockResults != nil {
+			valid := !set.BitsFromBytes(blockResults).Contains(i)
+			checks[i].MarkedValidByBlock = &valid
+		}
+	}
+	return checks, nil
+}
+
+// GetWarpPredicates returns the predicate bytes of each warp message attached to [tx]
+// access list, in the order they are indexed by the warp precompile
+func GetWarpPredicates(tx *types.Transaction) [][]byte {
+	predicates := [][]byte{}
+	for _, tuple := range tx.AccessList() {
+		if tuple.Address == warp.ContractAddress {
+			predicates = append(predicates, subnetEvmUtils.HashSliceToBytes(tuple.StorageKeys))
+		}
+	}
+	return predicates
+}
+
+// getWarpQuorumNumerator returns the quorum numerator set at the warp precompile config
+// active at [timestamp], or the default one
+func getWarpQuorumNumerator(client *rpc.Client, timestamp *uint64) (uint64, error) {
+	precompiles, err := GetActivePrecompilesAt(client, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	warpConfig, ok := precompiles["warpConfig"]
+	if !ok {
+		return 0, fmt.Errorf("warp precompile is not active")
+	}
+	if quorumNumerator, ok := warpConfig["quorumNumerator"].(float64); ok && quorumNumerator != 0 {
+		return uint64(quorumNumerator), nil
+	}
+	return warp.WarpDefaultQuorumNumerator, nil
+}
+
+// verifyWarpPredicate checks [predicateBytes] as warp.Config.VerifyPredicate does, but
+// gathering the intermediate values of the check, to help find out why it fails
+func verifyWarpPredicate(
+	ctx context.Context,
+	state validators.State,
+	networkID uint32,
+	receivingSubnetID ids.ID,
+	pChainHeight uint64,
+	quorumNumerator uint64,
+	predicateBytes []byte,
+) WarpPredicateCheck {
+	check := WarpPredicateCheck{
+		PChainHeight:    pChainHeight,
+		QuorumNumerator: quorumNumerator,
+	}
+	unpackedBytes, err := predicate.UnpackPredicate(predicateBytes)
+	if err != nil {
+		check.Err = fmt.Errorf("invalid predicate bytes: %w", err)
+		return check
+	}
+	msg, err := avalancheWarp.ParseMessage(unpackedBytes)
+	if err != nil {
+		check.Err = fmt.Errorf("failure parsing warp message: %w", err)
+		return check
+	}
+	check.MessageID = msg.ID()
+	check.SourceChainID = msg.SourceChainID
+	signature, ok := msg.Signature.(*avalancheWarp.BitSetSignature)
+	if !ok {
+		check.Err = fmt.Errorf("unexpected warp signature type %T", msg.Signature)
+		return check
+	}
+	if msg.NetworkID != networkID {
+		check.Err = fmt.Errorf("%w: message is for network %d, expected %d", avalancheWarp.ErrWrongNetworkID, msg.NetworkID, networkID)
+		return check
+	}
+	subnetID, err := state.GetSubnetID(ctx, msg.SourceChainID)
+	if err != nil {
+		check.Err = fmt.Errorf("failure getting subnet of source chain %s: %w", msg.SourceChainID, err)
+		return check
+	}
+	// [state] special cases the primary network as the VM does
+	check.SignerSubnetID = subnetID
+	if subnetID == constants.PrimaryNetworkID {
+		check.SignerSubnetID = receivingSubnetID
+	}
+	vdrs, totalWeight, err := avalancheWarp.GetCanonicalValidatorSet(ctx, state, pChainHeight, subnetID)
+	if err != nil {
+		check.Err = fmt.Errorf("failure getting validators of subnet %s at P-Chain height %d: %w", check.SignerSubnetID, pChainHeight, err)
+		return check
+	}
+	check.TotalWeight = totalWeight
+	signers, err := avalancheWarp.FilterValidators(set.BitsFromBytes(signature.Signers), vdrs)
+	if err != nil {
+		check.Err = fmt.Errorf("signers don't match the validator set: %w", err)
+		return check
+	}
+	check.NumSigners = len(signers)
+	check.SignedWeight, _ = avalancheWarp.SumWeight(signers)
+	check.Err = signature.Verify(
+		ctx,
+		&msg.UnsignedMessage,
+		networkID,
+		state,
+		pChainHeight,
+		quorumNumerator,
+		warp.WarpQuorumDenominator,
+	)
+	return check
+}
+
+// pChainValidatorState implements validators.State on top of the P-Chain API. As in
+// the VM, validator sets asked for the primary network are the ones of [subnetID], the
+// subnet of the receiving chain
+type pChainValidatorState struct {
+	client   platformvm.Client
+	subnetID ids.ID
+}
+
+func (s *pChainValidatorState) GetMinimumHeight(ctx context.Context) (uint64, error) {
+	return s.client.GetHeight(ctx)
+}
+
+func (s *pChainValidatorState) GetCurrentHeight(ctx context.Context) (uint64, error) {
+	return s.client.GetHeight(ctx)
+}
+
+func (s *pChainValidatorState) GetSubnetID(ctx context.Context, chainID ids.ID) (ids.ID, error) {
+	return s.client.ValidatedBy(ctx, chainID)
+}
+
+func (s *pChainValidatorState) GetValidatorSet(
+	ctx context.Context,
+	height uint64,
+	subnetID ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	if subnetID == constants.PrimaryNetworkID {
+		subnetID = s.subnetID
+	}
+	return s.client.GetValidatorsAt(ctx, subnetID, height)
+}
@@ -0,0 +1,407 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+var (
+	ErrNoSignerEndpoint       = errors.New("no RPC endpoint for validator")
+	ErrInvalidWarpSignature   = errors.New("invalid warp signature")
+	ErrInsufficientSignatures = errors.New("not enough signed weight")
+)
+
+// Results of a signature request, as used on the metrics labels
+const (
+	SignatureResultSuccess   = "success"
+	SignatureResultTimeout   = "timeout"
+	SignatureResultInvalid   = "invalid"
+	SignatureResultError     = "error"
+	SignatureResultNoRequest = "no_endpoint"
+	// only used for aggregations
+	SignatureResultInsufficientWeight = "insufficient_weight"
+)
+
+// SignMessageParams configures the signature aggregation done by SignMessage
+type SignMessageParams struct {
+	// Network whose P-Chain gives the validator set of [SubnetID]
+	Network avalanche.Network
+	// SubnetID is the subnet whose validators sign the message
+	SubnetID ids.ID
+	// PChainHeight the validator set is taken at. If 0, the current height is used
+	PChainHeight uint64
+	// QuorumNumerator of the signed weight needed over warp.WarpQuorumDenominator. If 0,
+	// warp.WarpDefaultQuorumNumerator is used
+	QuorumNumerator uint64
+	// Endpoints maps each validator node to the RPC URL of the source chain on it,
+	// that serves warp_getMessageSignature
+	Endpoints map[ids.NodeID]string
+	// ValidatorTimeout bounds each signature request. Defaults to constants.APIRequestTimeout
+	ValidatorTimeout time.Duration
+	// Deadline bounds the whole aggregation. Defaults to constants.APIRequestLargeTimeout
+	Deadline time.Duration
+	// Metrics, if set, gets the counters of the aggregation
+	Metrics *SignatureAggregationMetrics
+}
+
+// ValidatorSignatureResult is the outcome of asking one validator node for its signature
+type ValidatorSignatureResult struct {
+	NodeID ids.NodeID
+	// Weight of the canonical validator the node belongs to
+	Weight   uint64
+	Duration time.Duration
+	// Result is one of the SignatureResult* values
+	Result string
+	// Err is the reason the node didn't provide a valid signature, or nil if it did
+	Err error
+}
+
+// SignatureAggregationReport tells how the signatures of a warp message were gathered
+type SignatureAggregationReport struct {
+	MessageID       ids.ID
+	PChainHeight    uint64
+	QuorumNumerator uint64
+	SignedWeight    uint64
+	TotalWeight     uint64
+	Duration        time.Duration
+	Results         []ValidatorSignatureResult
+}
+
+// Failed returns the results of the nodes that didn't provide a valid signature
+func (r SignatureAggregationReport) Failed() []ValidatorSignatureResult {
+	failed := []ValidatorSignatureResult{}
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// warpSignatureFetcher asks the node at [endpoint] for its signature of [messageID]
+type warpSignatureFetcher func(ctx context.Context, endpoint string, messageID ids.ID) ([]byte, error)
+
+// SignMessage gathers the signatures of [unsignedMessage] from the validators of
+// [params.SubnetID], by calling warp_getMessageSignature on each of them, and
+// aggregates them into a signed warp message. Each validator node is asked once, with
+// its own timeout, and the report gives the duration and error of every request, so
+// validators that fail to sign can be told apart. An error wrapping
+// ErrInsufficientSignatures is returned, together with the report, if the signed
+// weight doesn't reach the quorum
+func SignMessage(
+	unsignedMessage *avalancheWarp.UnsignedMessage,
+	params SignMessageParams,
+) (*avalancheWarp.Message, SignatureAggregationReport, error) {
+	params.setDefaults()
+	ctx, cancel := context.WithTimeout(context.Background(), params.Deadline)
+	defer cancel()
+	pClient := platformvm.NewClient(params.Network.Endpoint)
+	if params.PChainHeight == 0 {
+		height, err := pClient.GetHeight(ctx)
+		if err != nil {
+			return nil, SignatureAggregationReport{}, fmt.Errorf("failure getting P-Chain height: %w", err)
+		}
+		params.PChainHeight = height
+	}
+	state := &pChainValidatorState{
+		client:   pClient,
+		subnetID: params.SubnetID,
+	}
+	vdrs, totalWeight, err := avalancheWarp.GetCanonicalValidatorSet(ctx, state, params.PChainHeight, params.SubnetID)
+	if err != nil {
+		return nil, SignatureAggregationReport{}, fmt.Errorf(
+			"failure getting validators of subnet %s at P-Chain height %d: %w",
+			params.SubnetID,
+			params.PChainHeight,
+			err,
+		)
+	}
+	return aggregateSignatures(ctx, unsignedMessage, vdrs, totalWeight, fetchWarpSignature, params)
+}
+
+func (p *SignMessageParams) setDefaults() {
+	if p.QuorumNumerator == 0 {
+		p.QuorumNumerator = warp.WarpDefaultQuorumNumerator
+	}
+	if p.ValidatorTimeout == 0 {
+		p.ValidatorTimeout = constants.APIRequestTimeout
+	}
+	if p.Deadline == 0 {
+		p.Deadline = constants.APIRequestLargeTimeout
+	}
+}
+
+func fetchWarpSignature(ctx context.Context, endpoint string, messageID ids.ID) ([]byte, error) {
+	client, err := GetRPCClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	var signature hexutil.Bytes
+	if err := client.CallContext(ctx, &signature, "warp_getMessageSignature", messageID); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// aggregateSignatures asks the nodes of the canonical validators [vdrs] for their
+// signatures concurrently, and aggregates the valid ones. A validator with several
+// nodes counts once, with the first valid signature of its nodes
+func aggregateSignatures(
+	ctx context.Context,
+	unsignedMessage *avalancheWarp.UnsignedMessage,
+	vdrs []*avalancheWarp.Validator,
+	totalWeight uint64,
+	fetch warpSignatureFetcher,
+	params SignMessageParams,
+) (*avalancheWarp.Message, SignatureAggregationReport, error) {
+	start := time.Now()
+	report := SignatureAggregationReport{
+		MessageID:       unsignedMessage.ID(),
+		PChainHeight:    params.PChainHeight,
+		QuorumNumerator: params.QuorumNumerator,
+		TotalWeight:     totalWeight,
+	}
+	type nodeRequest struct {
+		index  int
+		result ValidatorSignatureResult
+		sig    *bls.Signature
+	}
+	requests := []*nodeRequest{}
+	for i, vdr := range vdrs {
+		for _, nodeID := range vdr.NodeIDs {
+			requests = append(requests, &nodeRequest{
+				index:  i,
+				result: ValidatorSignatureResult{NodeID: nodeID, Weight: vdr.Weight},
+			})
+		}
+	}
+	var wg sync.WaitGroup
+	for _, req := range requests {
+		endpoint, ok := params.Endpoints[req.result.NodeID]
+		if !ok {
+			req.result.Result = SignatureResultNoRequest
+			req.result.Err = fmt.Errorf("%w %s", ErrNoSignerEndpoint, req.result.NodeID)
+			continue
+		}
+		wg.Add(1)
+		go func(req *nodeRequest, endpoint string) {
+			defer wg.Done()
+			requestCtx, cancel := context.WithTimeout(ctx, params.ValidatorTimeout)
+			defer cancel()
+			requestStart := time.Now()
+			sig, err := requestWarpSignature(requestCtx, fetch, endpoint, unsignedMessage, vdrs[req.index].PublicKey)
+			req.result.Duration = time.Since(requestStart)
+			req.sig = sig
+			req.result.Err = err
+			req.result.Result = signatureResult(err)
+		}(req, endpoint)
+	}
+	wg.Wait()
+
+	signers := set.NewBits()
+	sigs := []*bls.Signature{}
+	for _, req := range requests {
+		report.Results = append(report.Results, req.result)
+		params.Metrics.observeRequest(req.result)
+		if req.sig == nil || signers.Contains(req.index) {
+			continue
+		}
+		signers.Add(req.index)
+		sigs = append(sigs, req.sig)
+		report.SignedWeight += vdrs[req.index].Weight
+	}
+	report.Duration = time.Since(start)
+	if err := avalancheWarp.VerifyWeight(
+		report.SignedWeight,
+		totalWeight,
+		params.QuorumNumerator,
+		warp.WarpQuorumDenominator,
+	); err != nil {
+		params.Metrics.observeAggregation(SignatureResultInsufficientWeight, report.Duration)
+		return nil, report, fmt.Errorf(
+			"%w: %d of %d signed, %d/%d needed: %w",
+			ErrInsufficientSignatures,
+			report.SignedWeight,
+			totalWeight,
+			params.QuorumNumerator,
+			warp.WarpQuorumDenominator,
+			err,
+		)
+	}
+	aggregatedSig, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		params.Metrics.observeAggregation(SignatureResultError, report.Duration)
+		return nil, report, fmt.Errorf("failure aggregating signatures: %w", err)
+	}
+	signature := &avalancheWarp.BitSetSignature{Signers: signers.Bytes()}
+	copy(signature.Signature[:], bls.SignatureToBytes(aggregatedSig))
+	msg, err := avalancheWarp.NewMessage(unsignedMessage, signature)
+	if err != nil {
+		params.Metrics.observeAggregation(SignatureResultError, report.Duration)
+		return nil, report, err
+	}
+	params.Metrics.observeAggregation(SignatureResultSuccess, report.Duration)
+	return msg, report, nil
+}
+
+// requestWarpSignature fetches the signature of [unsignedMessage] from [endpoint] and
+// checks it against the validator key [publicKey]
+func requestWarpSignature(
+	ctx context.Context,
+	fetch warpSignatureFetcher,
+	endpoint string,
+	unsignedMessage *avalancheWarp.UnsignedMessage,
+	publicKey *bls.PublicKey,
+) (*bls.Signature, error) {
+	type response struct {
+		sigBytes []byte
+		err      error
+	}
+	// the fetcher may not honor [ctx], so the timeout is enforced here too
+	responseCh := make(chan response, 1)
+	go func() {
+		sigBytes, err := fetch(ctx, endpoint, unsignedMessage.ID())
+		responseCh <- response{sigBytes: sigBytes, err: err}
+	}()
+	var resp response
+	select {
+	case resp = <-responseCh:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	sig, err := bls.SignatureFromBytes(resp.sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWarpSignature, err)
+	}
+	if !bls.Verify(publicKey, sig, unsignedMessage.Bytes()) {
+		return nil, fmt.Errorf("%w: doesn't match the validator key", ErrInvalidWarpSignature)
+	}
+	return sig, nil
+}
+
+func signatureResult(err error) string {
+	switch {
+	case err == nil:
+		return SignatureResultSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return SignatureResultTimeout
+	case errors.Is(err, ErrInvalidWarpSignature):
+		return SignatureResultInvalid
+	default:
+		return SignatureResultError
+	}
+}
+
+// SignatureAggregationMetrics are the counters of the signature aggregations done by
+// SignMessage, to monitor the reliability of the signers from a relayer. They can be
+// rendered with Render, or served from Registry
+type SignatureAggregationMetrics struct {
+	registry         *prometheus.Registry
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	aggregations     *prometheus.CounterVec
+	aggregationTimes prometheus.Histogram
+}
+
+// NewSignatureAggregationMetrics creates the signature aggregation metrics under
+// [namespace], eg. "relayer"
+func NewSignatureAggregationMetrics(namespace string) (*SignatureAggregationMetrics, error) {
+	m := &SignatureAggregationMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "warp_signature_requests_total",
+			Help:      "Warp signature requests to validator nodes, by result",
+		}, []string{"node_id", "result"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "warp_signature_request_duration_seconds",
+			Help:      "Duration of the warp signature requests to validator nodes",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"node_id"}),
+		aggregations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "warp_signature_aggregations_total",
+			Help:      "Warp signature aggregations, by result",
+		}, []string{"result"}),
+		aggregationTimes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "warp_signature_aggregation_duration_seconds",
+			Help:      "Duration of the warp signature aggregations",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+	for _, collector := range []prometheus.Collector{
+		m.requests,
+		m.requestDuration,
+		m.aggregations,
+		m.aggregationTimes,
+	} {
+		if err := m.registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Registry returns the registry holding the metrics, eg. to serve them with promhttp
+func (m *SignatureAggregationMetrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Render returns the metrics in the Prometheus text exposition format
+func (m *SignatureAggregationMetrics) Render() ([]byte, error) {
+	families, err := m.registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *SignatureAggregationMetrics) observeRequest(result ValidatorSignatureResult) {
+	if m == nil {
+		return
+	}
+	nodeID := result.NodeID.String()
+	m.requests.WithLabelValues(nodeID, result.Result).Inc()
+	if result.Result != SignatureResultNoRequest {
+		m.requestDuration.WithLabelValues(nodeID).Observe(result.Duration.Seconds())
+	}
+}
+
+func (m *SignatureAggregationMetrics) observeAggregation(result string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.aggregations.WithLabelValues(result).Inc()
+	m.aggregationTimes.Observe(duration.Seconds())
+}
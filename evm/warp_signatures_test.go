@@ -0,0 +1,121 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateSignatures(t *testing.T) {
+	require := require.New(t)
+	const networkID = 5
+	subnetID := ids.GenerateTestID()
+	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(networkID, ids.GenerateTestID(), []byte("payload"))
+	require.NoError(err)
+
+	// signs, times out, gives a wrong signature, fails, has no endpoint
+	nodeIDs := []ids.NodeID{}
+	secretKeys := map[ids.NodeID]*bls.SecretKey{}
+	state := &fakeValidatorState{subnetID: subnetID, validators: map[ids.NodeID]*validators.GetValidatorOutput{}}
+	endpoints := map[ids.NodeID]string{}
+	for i := 0; i < 5; i++ {
+		sk, err := bls.NewSecretKey()
+		require.NoError(err)
+		nodeID := ids.GenerateTestNodeID()
+		nodeIDs = append(nodeIDs, nodeID)
+		secretKeys[nodeID] = sk
+		state.validators[nodeID] = &validators.GetValidatorOutput{
+			NodeID:    nodeID,
+			PublicKey: bls.PublicFromSecretKey(sk),
+			Weight:    100,
+		}
+		if i < 4 {
+			endpoints[nodeID] = nodeID.String()
+		}
+	}
+	otherKey, err := bls.NewSecretKey()
+	require.NoError(err)
+	fetch := func(ctx context.Context, endpoint string, messageID ids.ID) ([]byte, error) {
+		require.Equal(unsignedMessage.ID(), messageID)
+		switch endpoint {
+		case nodeIDs[0].String():
+			return bls.SignatureToBytes(bls.Sign(secretKeys[nodeIDs[0]], unsignedMessage.Bytes())), nil
+		case nodeIDs[1].String():
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case nodeIDs[2].String():
+			return bls.SignatureToBytes(bls.Sign(otherKey, unsignedMessage.Bytes())), nil
+		default:
+			return nil, errors.New("method not found")
+		}
+	}
+	vdrs, totalWeight, err := avalancheWarp.GetCanonicalValidatorSet(context.Background(), state, 1, subnetID)
+	require.NoError(err)
+	metrics, err := NewSignatureAggregationMetrics("relayer")
+	require.NoError(err)
+	params := SignMessageParams{
+		SubnetID:         subnetID,
+		PChainHeight:     1,
+		Endpoints:        endpoints,
+		ValidatorTimeout: 50 * time.Millisecond,
+		Metrics:          metrics,
+	}
+	params.setDefaults()
+
+	_, report, err := aggregateSignatures(context.Background(), unsignedMessage, vdrs, totalWeight, fetch, params)
+	require.ErrorIs(err, ErrInsufficientSignatures)
+	require.Equal(uint64(100), report.SignedWeight)
+	require.Equal(uint64(500), report.TotalWeight)
+	require.Len(report.Results, 5)
+	require.Len(report.Failed(), 4)
+	results := map[ids.NodeID]string{}
+	for _, result := range report.Results {
+		results[result.NodeID] = result.Result
+	}
+	require.Equal(map[ids.NodeID]string{
+		nodeIDs[0]: SignatureResultSuccess,
+		nodeIDs[1]: SignatureResultTimeout,
+		nodeIDs[2]: SignatureResultInvalid,
+		nodeIDs[3]: SignatureResultError,
+		nodeIDs[4]: SignatureResultNoRequest,
+	}, results)
+
+	// quorum reached when every node signs
+	for _, nodeID := range nodeIDs {
+		endpoints[nodeID] = nodeID.String()
+	}
+	fetch = func(_ context.Context, endpoint string, _ ids.ID) ([]byte, error) {
+		nodeID, err := ids.NodeIDFromString(endpoint)
+		require.NoError(err)
+		return bls.SignatureToBytes(bls.Sign(secretKeys[nodeID], unsignedMessage.Bytes())), nil
+	}
+	msg, report, err := aggregateSignatures(context.Background(), unsignedMessage, vdrs, totalWeight, fetch, params)
+	require.NoError(err)
+	require.Equal(uint64(500), report.SignedWeight)
+	require.Empty(report.Failed())
+	require.NoError(msg.Signature.Verify(
+		context.Background(),
+		&msg.UnsignedMessage,
+		networkID,
+		state,
+		1,
+		warp.WarpDefaultQuorumNumerator,
+		warp.WarpQuorumDenominator,
+	))
+
+	rendered, err := metrics.Render()
+	require.NoError(err)
+	require.Contains(string(rendered), `relayer_warp_signature_requests_total{node_id="`+nodeIDs[1].String()+`",result="timeout"} 1`)
+	require.Contains(string(rendered), `relayer_warp_signature_aggregations_total{result="insufficient_weight"} 1`)
+	require.Contains(string(rendered), `relayer_warp_signature_aggregations_total{result="success"} 1`)
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ava-labs/subnet-evm/predicate"
+	subnetEvmUtils "github.com/ava-labs/subnet-evm/utils"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeValidatorState struct {
+	validators.State
+	subnetID   ids.ID
+	validators map[ids.NodeID]*validators.GetValidatorOutput
+}
+
+func (s *fakeValidatorState) GetSubnetID(context.Context, ids.ID) (ids.ID, error) {
+	return s.subnetID, nil
+}
+
+func (s *fakeValidatorState) GetValidatorSet(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	return s.validators, nil
+}
+
+func TestVerifyWarpPredicate(t *testing.T) {
+	require := require.New(t)
+	const networkID = 5
+	subnetID := ids.GenerateTestID()
+	sourceChainID := ids.GenerateTestID()
+	state := &fakeValidatorState{
+		subnetID:   subnetID,
+		validators: map[ids.NodeID]*validators.GetValidatorOutput{},
+	}
+	secretKeys := map[string]*bls.SecretKey{}
+	for i := 0; i < 3; i++ {
+		sk, err := bls.NewSecretKey()
+		require.NoError(err)
+		pk := bls.PublicFromSecretKey(sk)
+		nodeID := ids.GenerateTestNodeID()
+		state.validators[nodeID] = &validators.GetValidatorOutput{NodeID: nodeID, PublicKey: pk, Weight: 100}
+		secretKeys[string(bls.PublicKeyToUncompressedBytes(pk))] = sk
+	}
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(networkID, sourceChainID, []byte("payload"))
+	require.NoError(err)
+	vdrs, _, err := avalancheWarp.GetCanonicalValidatorSet(context.Background(), state, 1, subnetID)
+	require.NoError(err)
+	signWith := func(indices ...int) []byte {
+		signers := set.NewBits()
+		sigs := []*bls.Signature{}
+		for _, i := range indices {
+			signers.Add(i)
+			sigs = append(sigs, bls.Sign(secretKeys[string(vdrs[i].PublicKeyBytes)], unsignedMsg.Bytes()))
+		}
+		aggSig, err := bls.AggregateSignatures(sigs)
+		require.NoError(err)
+		signature := &avalancheWarp.BitSetSignature{Signers: signers.Bytes()}
+		copy(signature.Signature[:], bls.SignatureToBytes(aggSig))
+		msg, err := avalancheWarp.NewMessage(unsignedMsg, signature)
+		require.NoError(err)
+		return predicate.PackPredicate(msg.Bytes())
+	}
+
+	check := verifyWarpPredicate(context.Background(), state, networkID, subnetID, 1, 67, signWith(0, 1, 2))
+	require.NoError(check.Err)
+	require.True(check.Valid())
+	require.Equal(unsignedMsg.ID(), check.MessageID)
+	require.Equal(sourceChainID, check.SourceChainID)
+	require.Equal(subnetID, check.SignerSubnetID)
+	require.Equal(3, check.NumSigners)
+	require.Equal(uint64(300), check.SignedWeight)
+	require.Equal(uint64(300), check.TotalWeight)
+
+	// 2/3 of the weight is below a 67% quorum
+	check = verifyWarpPredicate(context.Background(), state, networkID, subnetID, 1, 67, signWith(0, 1))
+	require.ErrorIs(check.Err, avalancheWarp.ErrInsufficientWeight)
+	require.Equal(2, check.NumSigners)
+	require.Equal(uint64(200), check.SignedWeight)
+	check = verifyWarpPredicate(context.Background(), state, networkID, subnetID, 1, 60, signWith(0, 1))
+	require.NoError(check.Err)
+
+	check = verifyWarpPredicate(context.Background(), state, networkID+1, subnetID, 1, 67, signWith(0, 1, 2))
+	require.ErrorIs(check.Err, avalancheWarp.ErrWrongNetworkID)
+
+	check = verifyWarpPredicate(context.Background(), state, networkID, subnetID, 1, 67, []byte{1, 2, 3})
+	require.ErrorIs(check.Err, predicate.ErrInvalidPadding)
+
+	// messages from the primary network are signed by the receiving subnet
+	state.subnetID = constants.PrimaryNetworkID
+	check = verifyWarpPredicate(context.Background(), state, networkID, subnetID, 1, 67, signWith(0, 1, 2))
+	require.NoError(check.Err)
+	require.Equal(subnetID, check.SignerSubnetID)
+}
+
+func TestGetWarpPredicates(t *testing.T) {
+	require := require.New(t)
+	tx := predicate.NewPredicateTx(nil, 0, nil, 0, nil, nil, nil, nil, types.AccessList{}, warp.ContractAddress, []byte("message"))
+	predicates := GetWarpPredicates(tx)
+	require.Len(predicates, 1)
+	require.Equal(subnetEvmUtils.HashSliceToBytes(tx.AccessList()[0].StorageKeys), predicates[0])
+	message, err := predicate.UnpackPredicate(predicates[0])
+	require.NoError(err)
+	require.Equal([]byte("message"), message)
+}
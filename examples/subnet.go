@@ -71,14 +71,10 @@ func DeploySubnet() {
 	threshold := 1
 	newSubnet.SetSubnetControlParams(controlKeys, uint32(threshold))
 
-	wallet, _ := wallet.New(
+	wallet, _ := wallet.NewWithOptions(
 		context.Background(),
-		&primary.WalletConfig{
-			URI:              network.Endpoint,
-			AVAXKeychain:     keychain.Keychain,
-			EthKeychain:      secp256k1fx.NewKeychain(),
-			PChainTxsToFetch: nil,
-		},
+		network,
+		*keychain,
 	)
 
 	// Build and Sign CreateSubnetTx with our fee paying key
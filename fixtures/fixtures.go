@@ -0,0 +1,321 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package fixtures generates valid, signed P-Chain txs of every kind supported by the
+// SDK, with deterministic keys and IDs, so tools can test their parsers against
+// stable vectors. Fixtures are not meant to be issued: they spend UTXOs that don't exist
+package fixtures
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// seed prefixes everything derived by the package, so fixtures are stable across versions
+const seed = "avalanche-tooling-sdk-go/fixtures/"
+
+const (
+	// FundingKeyIndex is the index of the key that owns the UTXOs spent by the fixtures
+	FundingKeyIndex = 0
+	// ControlKeyIndex is the index of the key that controls the fixtures subnet
+	ControlKeyIndex = 1
+	// OwnerKeyIndex is the index of the key set as owner by the fixtures
+	OwnerKeyIndex = 2
+
+	// StartTime and EndTime bound the validations and delegations of the fixtures
+	StartTime = 1_700_000_000
+	EndTime   = StartTime + 30*24*60*60
+
+	inputAmount  = 1_000_000_000_000
+	stakeAmount  = 2_000_000_000_000
+	outputAmount = inputAmount - 1_000_000
+)
+
+var (
+	// AVAXAssetID is the asset spent by the fixtures
+	AVAXAssetID = ID("AVAX")
+	// SubnetID is the subnet the fixtures operate on
+	SubnetID = ID("subnet")
+	// ChainID is the chain imported from and exported to by the fixtures
+	ChainID = ID("chain")
+)
+
+// Fixture is a signed P-Chain tx of a given kind
+type Fixture struct {
+	Kind multisig.TxKind
+	Tx   *txs.Tx
+}
+
+// Hex returns the tx bytes hex encoded, as done by avalanche-cli tx files
+func (f Fixture) Hex() (string, error) {
+	return formatting.Encode(formatting.Hex, f.Tx.Bytes())
+}
+
+// Kinds are the tx kinds fixtures are generated for
+var Kinds = []multisig.TxKind{
+	multisig.PChainBaseTx,
+	multisig.PChainCreateSubnetTx,
+	multisig.PChainCreateChainTx,
+	multisig.PChainAddSubnetValidatorTx,
+	multisig.PChainRemoveSubnetValidatorTx,
+	multisig.PChainTransformSubnetTx,
+	multisig.PChainTransferSubnetOwnershipTx,
+	multisig.PChainAddValidatorTx,
+	multisig.PChainAddDelegatorTx,
+	multisig.PChainAddPermissionlessValidatorTx,
+	multisig.PChainAddPermissionlessDelegatorTx,
+	multisig.PChainImportTx,
+	multisig.PChainExportTx,
+}
+
+// ID derives a deterministic ID from [label]
+func ID(label string) ids.ID {
+	return sha256.Sum256([]byte(seed + "id/" + label))
+}
+
+// NodeID returns the deterministic node ID [i]
+func NodeID(i int) ids.NodeID {
+	id := ID(fmt.Sprintf("node/%d", i))
+	return ids.NodeID(id[:ids.NodeIDLen])
+}
+
+// Key returns the deterministic secp256k1 private key [i]
+func Key(i int) *secp256k1.PrivateKey {
+	keyBytes := sha256.Sum256([]byte(fmt.Sprintf("%skey/%d", seed, i)))
+	key, err := secp256k1.ToPrivateKey(keyBytes[:])
+	if err != nil {
+		// a sha256 hash is a valid key with overwhelming probability, and this one is fixed
+		panic(err)
+	}
+	return key
+}
+
+// Address returns the address of Key(i)
+func Address(i int) ids.ShortID {
+	return Key(i).Address()
+}
+
+// BLSKey returns the deterministic BLS secret key [i]
+func BLSKey(i int) *bls.SecretKey {
+	keyBytes := sha256.Sum256([]byte(fmt.Sprintf("%sbls/%d", seed, i)))
+	// keeps the scalar below the curve order
+	keyBytes[0] &= 0x3f
+	key, err := bls.SecretKeyFromBytes(keyBytes[:])
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// All generates a fixture of each of Kinds for [networkID]
+func All(networkID uint32) ([]Fixture, error) {
+	fixtures := make([]Fixture, 0, len(Kinds))
+	for _, kind := range Kinds {
+		tx, err := Tx(kind, networkID)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, Fixture{Kind: kind, Tx: tx})
+	}
+	return fixtures, nil
+}
+
+// Tx generates the signed fixture tx of [kind] for [networkID]. Inputs are signed by
+// Key(FundingKeyIndex) and subnet auth by Key(ControlKeyIndex)
+func Tx(kind multisig.TxKind, networkID uint32) (*txs.Tx, error) {
+	baseTx := newBaseTx(kind, networkID)
+	subnetAuth := &secp256k1fx.Input{SigIndices: []uint32{0}}
+	validator := txs.Validator{
+		NodeID: NodeID(0),
+		Start:  StartTime,
+		End:    EndTime,
+		Wght:   stakeAmount,
+	}
+	var unsignedTx txs.UnsignedTx
+	switch kind {
+	case multisig.PChainBaseTx:
+		unsignedTx = &baseTx
+	case multisig.PChainCreateSubnetTx:
+		unsignedTx = &txs.CreateSubnetTx{
+			BaseTx: baseTx,
+			Owner:  owners(ControlKeyIndex),
+		}
+	case multisig.PChainCreateChainTx:
+		unsignedTx = &txs.CreateChainTx{
+			BaseTx:      baseTx,
+			SubnetID:    SubnetID,
+			ChainName:   "fixture",
+			VMID:        ID("vm"),
+			FxIDs:       []ids.ID{},
+			GenesisData: []byte(`{"config":{}}`),
+			SubnetAuth:  subnetAuth,
+		}
+	case multisig.PChainAddSubnetValidatorTx:
+		unsignedTx = &txs.AddSubnetValidatorTx{
+			BaseTx: baseTx,
+			SubnetValidator: txs.SubnetValidator{
+				Validator: txs.Validator{NodeID: NodeID(0), Start: StartTime, End: EndTime, Wght: 20},
+				Subnet:    SubnetID,
+			},
+			SubnetAuth: subnetAuth,
+		}
+	case multisig.PChainRemoveSubnetValidatorTx:
+		unsignedTx = &txs.RemoveSubnetValidatorTx{
+			BaseTx:     baseTx,
+			NodeID:     NodeID(0),
+			Subnet:     SubnetID,
+			SubnetAuth: subnetAuth,
+		}
+	case multisig.PChainTransformSubnetTx:
+		unsignedTx = &txs.TransformSubnetTx{
+			BaseTx:                   baseTx,
+			Subnet:                   SubnetID,
+			AssetID:                  ID("staking asset"),
+			InitialSupply:            1_000_000,
+			MaximumSupply:            2_000_000,
+			MinConsumptionRate:       100_000,
+			MaxConsumptionRate:       120_000,
+			MinValidatorStake:        1,
+			MaxValidatorStake:        1_000_000,
+			MinStakeDuration:         24 * 60 * 60,
+			MaxStakeDuration:         365 * 24 * 60 * 60,
+			MinDelegationFee:         20_000,
+			MinDelegatorStake:        1,
+			MaxValidatorWeightFactor: 5,
+			UptimeRequirement:        800_000,
+			SubnetAuth:               subnetAuth,
+		}
+	case multisig.PChainTransferSubnetOwnershipTx:
+		unsignedTx = &txs.TransferSubnetOwnershipTx{
+			BaseTx:     baseTx,
+			Subnet:     SubnetID,
+			SubnetAuth: subnetAuth,
+			Owner:      owners(OwnerKeyIndex),
+		}
+	case multisig.PChainAddValidatorTx:
+		unsignedTx = &txs.AddValidatorTx{
+			BaseTx:           baseTx,
+			Validator:        validator,
+			StakeOuts:        []*avax.TransferableOutput{output(stakeAmount, FundingKeyIndex)},
+			RewardsOwner:     owners(OwnerKeyIndex),
+			DelegationShares: 20_000,
+		}
+	case multisig.PChainAddDelegatorTx:
+		unsignedTx = &txs.AddDelegatorTx{
+			BaseTx:                 baseTx,
+			Validator:              validator,
+			StakeOuts:              []*avax.TransferableOutput{output(stakeAmount, FundingKeyIndex)},
+			DelegationRewardsOwner: owners(OwnerKeyIndex),
+		}
+	case multisig.PChainAddPermissionlessValidatorTx:
+		unsignedTx = &txs.AddPermissionlessValidatorTx{
+			BaseTx:                baseTx,
+			Validator:             validator,
+			Subnet:                constants.PrimaryNetworkID,
+			Signer:                signer.NewProofOfPossession(BLSKey(0)),
+			StakeOuts:             []*avax.TransferableOutput{output(stakeAmount, FundingKeyIndex)},
+			ValidatorRewardsOwner: owners(OwnerKeyIndex),
+			DelegatorRewardsOwner: owners(OwnerKeyIndex),
+			DelegationShares:      20_000,
+		}
+	case multisig.PChainAddPermissionlessDelegatorTx:
+		unsignedTx = &txs.AddPermissionlessDelegatorTx{
+			BaseTx:                 baseTx,
+			Validator:              validator,
+			Subnet:                 constants.PrimaryNetworkID,
+			StakeOuts:              []*avax.TransferableOutput{output(stakeAmount, FundingKeyIndex)},
+			DelegationRewardsOwner: owners(OwnerKeyIndex),
+		}
+	case multisig.PChainImportTx:
+		unsignedTx = &txs.ImportTx{
+			BaseTx:         baseTx,
+			SourceChain:    ChainID,
+			ImportedInputs: []*avax.TransferableInput{input(kind, 1)},
+		}
+	case multisig.PChainExportTx:
+		unsignedTx = &txs.ExportTx{
+			BaseTx:           baseTx,
+			DestinationChain: ChainID,
+			ExportedOutputs:  []*avax.TransferableOutput{output(stakeAmount, OwnerKeyIndex)},
+		}
+	default:
+		return nil, fmt.Errorf("no fixture for tx kind %s", kind)
+	}
+	// one signer set per input, then one for the subnet auth
+	signers := [][]*secp256k1.PrivateKey{{Key(FundingKeyIndex)}}
+	if kind == multisig.PChainImportTx {
+		signers = append(signers, []*secp256k1.PrivateKey{Key(FundingKeyIndex)})
+	}
+	if kind.RequiresSubnetAuth() {
+		signers = append(signers, []*secp256k1.PrivateKey{Key(ControlKeyIndex)})
+	}
+	tx := &txs.Tx{Unsigned: unsignedTx}
+	if err := tx.Sign(txs.Codec, signers); err != nil {
+		return nil, fmt.Errorf("failure signing %s fixture: %w", kind, err)
+	}
+	return tx, nil
+}
+
+func newBaseTx(kind multisig.TxKind, networkID uint32) txs.BaseTx {
+	return txs.BaseTx{
+		BaseTx: avax.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: constants.PlatformChainID,
+			Ins:          []*avax.TransferableInput{input(kind, 0)},
+			Outs:         []*avax.TransferableOutput{output(outputAmount, FundingKeyIndex)},
+			Memo:         []byte("fixture " + kind.String()),
+		},
+	}
+}
+
+// input spends a UTXO derived from the tx kind, owned by Key(FundingKeyIndex)
+func input(kind multisig.TxKind, outputIndex uint32) *avax.TransferableInput {
+	amount := uint64(inputAmount)
+	// staking and export txs also fund their stake or exported output from the input
+	switch kind {
+	case multisig.PChainAddValidatorTx,
+		multisig.PChainAddDelegatorTx,
+		multisig.PChainAddPermissionlessValidatorTx,
+		multisig.PChainAddPermissionlessDelegatorTx,
+		multisig.PChainExportTx:
+		if outputIndex == 0 {
+			amount += stakeAmount
+		}
+	}
+	return &avax.TransferableInput{
+		UTXOID: avax.UTXOID{TxID: ID("utxo/" + kind.String()), OutputIndex: outputIndex},
+		Asset:  avax.Asset{ID: AVAXAssetID},
+		In: &secp256k1fx.TransferInput{
+			Amt:   amount,
+			Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+		},
+	}
+}
+
+func output(amount uint64, keyIndex int) *avax.TransferableOutput {
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: AVAXAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: *owners(keyIndex),
+		},
+	}
+}
+
+func owners(keyIndex int) *secp256k1fx.OutputOwners {
+	return &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{Address(keyIndex)},
+	}
+}
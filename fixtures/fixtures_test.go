@@ -0,0 +1,76 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fixtures
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixturesAreDeterministic(t *testing.T) {
+	require := require.New(t)
+	fixtures, err := All(constants.FujiID)
+	require.NoError(err)
+	require.Len(fixtures, len(Kinds))
+	again, err := All(constants.FujiID)
+	require.NoError(err)
+	txIDs := map[ids.ID]struct{}{}
+	for i, fixture := range fixtures {
+		require.Equal(fixture.Tx.ID(), again[i].Tx.ID(), fixture.Kind.String())
+		require.Equal(fixture.Tx.Bytes(), again[i].Tx.Bytes(), fixture.Kind.String())
+		txIDs[fixture.Tx.ID()] = struct{}{}
+	}
+	require.Len(txIDs, len(Kinds))
+
+	mainnetTx, err := Tx(multisig.PChainBaseTx, constants.MainnetID)
+	require.NoError(err)
+	require.NotEqual(fixtures[0].Tx.ID(), mainnetTx.ID())
+}
+
+func TestFixturesParseAndVerify(t *testing.T) {
+	require := require.New(t)
+	fixtures, err := All(constants.FujiID)
+	require.NoError(err)
+	for _, fixture := range fixtures {
+		txHex, err := fixture.Hex()
+		require.NoError(err)
+		txBytes, err := formatting.Decode(formatting.Hex, txHex)
+		require.NoError(err)
+		tx, err := txs.Parse(txs.Codec, txBytes)
+		require.NoError(err, fixture.Kind.String())
+		require.Equal(fixture.Tx.ID(), tx.ID())
+
+		ms := multisig.New(tx)
+		kind, err := ms.GetTxKind()
+		require.NoError(err)
+		require.Equal(fixture.Kind, kind)
+
+		// signatures recover the fixture keys
+		unsignedBytes, err := txs.Codec.Marshal(txs.CodecVersion, &tx.Unsigned)
+		require.NoError(err)
+		for i, cred := range tx.Creds {
+			expected := Address(FundingKeyIndex)
+			if fixture.Kind.RequiresSubnetAuth() && i == len(tx.Creds)-1 {
+				expected = Address(ControlKeyIndex)
+			}
+			sig := cred.(*secp256k1fx.Credential).Sigs[0]
+			publicKey, err := secp256k1.RecoverPublicKey(unsignedBytes, sig[:])
+			require.NoError(err)
+			require.Equal(expected, publicKey.Address(), fixture.Kind.String())
+		}
+	}
+}
+
+func TestTxUnsupportedKind(t *testing.T) {
+	_, err := Tx(multisig.Undefined, constants.FujiID)
+	require.ErrorContains(t, err, "no fixture for tx kind")
+}
@@ -193,26 +193,95 @@ func (t *Deployer) DeployRegistry(
 	if err := t.CheckAssets(); err != nil {
 		return "", err
 	}
-	messengerContractAddress := common.HexToAddress(string(t.messengerContractAddress))
-	type ProtocolRegistryEntry struct {
-		Version         *big.Int
-		ProtocolAddress common.Address
+	registryAddress, err := evm.DeployContract(
+		rpcURL,
+		privateKey,
+		t.registryBytecode,
+		registryConstructorEsp,
+		t.registryConstructorInput(),
+	)
+	if err != nil {
+		return "", err
 	}
-	constructorInput := []ProtocolRegistryEntry{
+	return registryAddress.Hex(), nil
+}
+
+// ProtocolRegistryEntry is a messenger version registered in the registry
+type ProtocolRegistryEntry struct {
+	Version         *big.Int
+	ProtocolAddress common.Address
+}
+
+const registryConstructorEsp = "([(uint256, address)])"
+
+// registryConstructorInput registers the messenger as the version 1 of the protocol
+func (t *Deployer) registryConstructorInput() []ProtocolRegistryEntry {
+	return []ProtocolRegistryEntry{
 		{
 			Version:         big.NewInt(1),
-			ProtocolAddress: messengerContractAddress,
+			ProtocolAddress: common.HexToAddress(string(t.messengerContractAddress)),
 		},
 	}
-	registryAddress, err := evm.DeployContract(
+}
+
+// RegistryCreate2Address returns the address DeployRegistryCreate2 deploys the registry
+// to with [salt]. As the messenger address is the same on every chain, so is the
+// registry one
+func (t *Deployer) RegistryCreate2Address(salt [32]byte) (common.Address, error) {
+	if err := t.CheckAssets(); err != nil {
+		return common.Address{}, err
+	}
+	initCode, err := evm.Create2InitCode(t.registryBytecode, registryConstructorEsp, t.registryConstructorInput())
+	if err != nil {
+		return common.Address{}, err
+	}
+	return evm.Create2Address(salt, initCode), nil
+}
+
+// DeployRegistryCreate2 deploys the registry through the CREATE2 deployer, with [salt],
+// at the address given by RegistryCreate2Address. Returns true if it was already
+// deployed there
+func (t *Deployer) DeployRegistryCreate2(
+	rpcURL string,
+	privateKey string,
+	salt [32]byte,
+) (common.Address, bool, error) {
+	if err := t.CheckAssets(); err != nil {
+		return common.Address{}, false, err
+	}
+	return evm.DeployContractCreate2(
 		rpcURL,
 		privateKey,
+		salt,
 		t.registryBytecode,
-		"([(uint256, address)])",
-		constructorInput,
+		registryConstructorEsp,
+		t.registryConstructorInput(),
 	)
+}
+
+// VerifyCreate2Deployment checks that the messenger is deployed, and that the registry is
+// deployed at the address given by RegistryCreate2Address for [salt]
+func (t *Deployer) VerifyCreate2Deployment(
+	rpcURL string,
+	salt [32]byte,
+) (common.Address, error) {
+	registryAddress, err := t.RegistryCreate2Address(salt)
 	if err != nil {
-		return "", err
+		return common.Address{}, err
 	}
-	return registryAddress.Hex(), nil
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer client.Close()
+	for _, address := range []string{string(t.messengerContractAddress), registryAddress.Hex()} {
+		deployed, err := evm.ContractAlreadyDeployed(client, address)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("failure making a request to %s: %w", rpcURL, err)
+		}
+		if !deployed {
+			return common.Address{}, fmt.Errorf("%w at %s", evm.ErrContractNotDeployed, address)
+		}
+	}
+	return registryAddress, nil
 }
@@ -0,0 +1,198 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package interchainmessenger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const warpConfigKey = "warpConfig"
+
+var (
+	ErrWarpNotEnabled         = errors.New("warp precompile is not enabled")
+	ErrWarpQuorumMismatch     = errors.New("warp quorum numerator mismatch")
+	ErrMessengerVersionTooOld = errors.New("registered messenger version is too old")
+)
+
+// MessengerVersion is an ICM messenger registered at a TeleporterRegistry
+type MessengerVersion struct {
+	Version *big.Int
+	Address common.Address
+}
+
+// GetLatestRegisteredMessenger returns the latest messenger version, and its address,
+// registered at the TeleporterRegistry [registryAddress]
+func GetLatestRegisteredMessenger(
+	rpcURL string,
+	registryAddress common.Address,
+) (MessengerVersion, error) {
+	out, err := evm.CallToMethod(
+		rpcURL,
+		registryAddress,
+		"latestVersion()->(uint256)",
+	)
+	if err != nil {
+		return MessengerVersion{}, err
+	}
+	version, b := out[0].(*big.Int)
+	if !b {
+		return MessengerVersion{}, fmt.Errorf("error at latestVersion call, expected *big.Int, got %T", out[0])
+	}
+	address, err := GetRegisteredMessengerAddress(rpcURL, registryAddress, version)
+	if err != nil {
+		return MessengerVersion{}, err
+	}
+	return MessengerVersion{
+		Version: version,
+		Address: address,
+	}, nil
+}
+
+// GetRegisteredMessengerAddress returns the address of the messenger registered
+// with [version] at the TeleporterRegistry [registryAddress]
+func GetRegisteredMessengerAddress(
+	rpcURL string,
+	registryAddress common.Address,
+	version *big.Int,
+) (common.Address, error) {
+	out, err := evm.CallToMethod(
+		rpcURL,
+		registryAddress,
+		"getAddressFromVersion(uint256)->(address)",
+		version,
+	)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, b := out[0].(common.Address)
+	if !b {
+		return common.Address{}, fmt.Errorf("error at getAddressFromVersion call, expected common.Address, got %T", out[0])
+	}
+	return address, nil
+}
+
+// GetBytecodeHash returns the keccak256 hash of the code deployed at [address]
+func GetBytecodeHash(
+	rpcURL string,
+	address common.Address,
+) (common.Hash, error) {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer client.Close()
+	bytecode, err := evm.GetContractBytecode(client, address.Hex())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(bytecode) == 0 {
+		return common.Hash{}, fmt.Errorf("no contract deployed at %s", address.Hex())
+	}
+	return crypto.Keccak256Hash(bytecode), nil
+}
+
+// VerifyBytecode checks that the code deployed at [address] matches one of [knownReleases],
+// a map from bytecode hash to release name, and returns the matching release
+func VerifyBytecode(
+	rpcURL string,
+	address common.Address,
+	knownReleases map[common.Hash]string,
+) (string, error) {
+	hash, err := GetBytecodeHash(rpcURL, address)
+	if err != nil {
+		return "", err
+	}
+	release, ok := knownReleases[hash]
+	if !ok {
+		return "", fmt.Errorf("bytecode hash %s of contract %s does not match any known release", hash.Hex(), address.Hex())
+	}
+	return release, nil
+}
+
+// WarpConfig is the warp precompile configuration of a chain
+type WarpConfig struct {
+	Enabled bool
+	// QuorumNumerator of the signed weight, over warp.WarpQuorumDenominator, the chain
+	// requires to accept warp messages. Set to warp.WarpDefaultQuorumNumerator if the
+	// config doesn't set it
+	QuorumNumerator uint64
+	// Raw precompile config, as informed by the chain
+	Config map[string]interface{}
+}
+
+// WarpRequirements is what a cross-chain app expects from the chains it is deployed on
+type WarpRequirements struct {
+	// QuorumNumerator the app aggregates warp signatures with. If 0,
+	// warp.WarpDefaultQuorumNumerator is used
+	QuorumNumerator uint64
+	// MinMessengerVersion is the minimum messenger version that must be registered at
+	// the TeleporterRegistry. If nil, any registered version is accepted
+	MinMessengerVersion *big.Int
+}
+
+// GetWarpConfig checks if the warp precompile, required by ICM, is currently
+// active on the chain at [rpcURL]
+func GetWarpConfig(rpcURL string) (WarpConfig, error) {
+	client, err := evm.GetRPCClient(rpcURL)
+	if err != nil {
+		return WarpConfig{}, err
+	}
+	defer client.Close()
+	precompiles, err := evm.GetActivePrecompilesAt(client, nil)
+	if err != nil {
+		return WarpConfig{}, err
+	}
+	config, ok := precompiles[warpConfigKey]
+	warpConfig := WarpConfig{
+		Enabled: ok,
+		Config:  config,
+	}
+	if ok {
+		warpConfig.QuorumNumerator = warp.WarpDefaultQuorumNumerator
+		if quorumNumerator, ok := config["quorumNumerator"].(float64); ok && quorumNumerator != 0 {
+			warpConfig.QuorumNumerator = uint64(quorumNumerator)
+		}
+	}
+	return warpConfig, nil
+}
+
+// CheckWarpCompatibility checks that the chain at [rpcURL] can run a cross-chain app
+// with the given [requirements]: the warp precompile must be active with the same
+// quorum numerator the app uses, and the TeleporterRegistry [registryAddress] must
+// have a messenger of at least the required version. Returns the latest registered
+// messenger
+func CheckWarpCompatibility(
+	rpcURL string,
+	registryAddress common.Address,
+	requirements WarpRequirements,
+) (MessengerVersion, error) {
+	warpConfig, err := GetWarpConfig(rpcURL)
+	if err != nil {
+		return MessengerVersion{}, err
+	}
+	if !warpConfig.Enabled {
+		return MessengerVersion{}, ErrWarpNotEnabled
+	}
+	quorumNumerator := requirements.QuorumNumerator
+	if quorumNumerator == 0 {
+		quorumNumerator = warp.WarpDefaultQuorumNumerator
+	}
+	if warpConfig.QuorumNumerator != quorumNumerator {
+		return MessengerVersion{}, fmt.Errorf("%w: chain requires %d, app uses %d", ErrWarpQuorumMismatch, warpConfig.QuorumNumerator, quorumNumerator)
+	}
+	messenger, err := GetLatestRegisteredMessenger(rpcURL, registryAddress)
+	if err != nil {
+		return MessengerVersion{}, err
+	}
+	if requirements.MinMessengerVersion != nil && messenger.Version.Cmp(requirements.MinMessengerVersion) < 0 {
+		return MessengerVersion{}, fmt.Errorf("%w: latest registered is %s, required %s", ErrMessengerVersionTooOld, messenger.Version, requirements.MinMessengerVersion)
+	}
+	return messenger, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package interchainmessenger
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistryChain serves the JSON-RPC methods used by the registry helpers, for a
+// chain with a TeleporterRegistry at [registry]
+type fakeRegistryChain struct {
+	registry      common.Address
+	latestVersion int64
+	messengers    map[int64]common.Address
+	code          map[common.Address][]byte
+	warpConfig    map[string]interface{}
+}
+
+func (c *fakeRegistryChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var result interface{}
+	switch req.Method {
+	case "eth_call":
+		var call struct {
+			To    common.Address `json:"to"`
+			Data  hexutil.Bytes  `json:"data"`
+			Input hexutil.Bytes  `json:"input"`
+		}
+		_ = json.Unmarshal(req.Params[0], &call)
+		data := call.Input
+		if len(data) == 0 {
+			data = call.Data
+		}
+		if call.To != c.registry || len(data) < 4 {
+			result = "0x"
+			break
+		}
+		switch {
+		case hexutil.Encode(data[:4]) == hexutil.Encode(crypto.Keccak256([]byte("latestVersion()"))[:4]):
+			result = hexutil.Encode(common.LeftPadBytes(big.NewInt(c.latestVersion).Bytes(), 32))
+		case hexutil.Encode(data[:4]) == hexutil.Encode(crypto.Keccak256([]byte("getAddressFromVersion(uint256)"))[:4]):
+			version := new(big.Int).SetBytes(data[4:36]).Int64()
+			result = hexutil.Encode(common.LeftPadBytes(c.messengers[version].Bytes(), 32))
+		default:
+			result = "0x"
+		}
+	case "eth_getCode":
+		var address common.Address
+		_ = json.Unmarshal(req.Params[0], &address)
+		result = hexutil.Encode(c.code[address])
+	case "eth_getActivePrecompilesAt":
+		precompiles := map[string]interface{}{}
+		if c.warpConfig != nil {
+			precompiles[warpConfigKey] = c.warpConfig
+		}
+		result = precompiles
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func newFakeRegistryChain(t *testing.T) (*fakeRegistryChain, string) {
+	chain := &fakeRegistryChain{
+		registry:      common.HexToAddress("0x0100000000000000000000000000000000000000"),
+		latestVersion: 2,
+		messengers: map[int64]common.Address{
+			1: common.HexToAddress("0x0200000000000000000000000000000000000001"),
+			2: common.HexToAddress("0x0200000000000000000000000000000000000002"),
+		},
+		code: map[common.Address][]byte{
+			common.HexToAddress("0x0200000000000000000000000000000000000002"): {0x60, 0x80, 0x60, 0x40},
+		},
+		warpConfig: map[string]interface{}{"blockTimestamp": 0, "quorumNumerator": 0},
+	}
+	server := httptest.NewServer(chain)
+	t.Cleanup(server.Close)
+	return chain, server.URL
+}
+
+func TestRegistryVersions(t *testing.T) {
+	require := require.New(t)
+	chain, rpcURL := newFakeRegistryChain(t)
+
+	messenger, err := GetLatestRegisteredMessenger(rpcURL, chain.registry)
+	require.NoError(err)
+	require.Equal(big.NewInt(2), messenger.Version)
+	require.Equal(chain.messengers[2], messenger.Address)
+
+	address, err := GetRegisteredMessengerAddress(rpcURL, chain.registry, big.NewInt(1))
+	require.NoError(err)
+	require.Equal(chain.messengers[1], address)
+}
+
+func TestVerifyBytecode(t *testing.T) {
+	require := require.New(t)
+	chain, rpcURL := newFakeRegistryChain(t)
+	messenger := chain.messengers[2]
+	hash := crypto.Keccak256Hash(chain.code[messenger])
+
+	bytecodeHash, err := GetBytecodeHash(rpcURL, messenger)
+	require.NoError(err)
+	require.Equal(hash, bytecodeHash)
+
+	release, err := VerifyBytecode(rpcURL, messenger, map[common.Hash]string{hash: "v1.0.0"})
+	require.NoError(err)
+	require.Equal("v1.0.0", release)
+
+	_, err = VerifyBytecode(rpcURL, messenger, map[common.Hash]string{{1}: "v1.0.0"})
+	require.ErrorContains(err, "does not match any known release")
+
+	// no code at the older messenger
+	_, err = VerifyBytecode(rpcURL, chain.messengers[1], map[common.Hash]string{hash: "v1.0.0"})
+	require.ErrorContains(err, "no contract deployed")
+}
+
+func TestCheckWarpCompatibility(t *testing.T) {
+	require := require.New(t)
+	chain, rpcURL := newFakeRegistryChain(t)
+
+	warpConfig, err := GetWarpConfig(rpcURL)
+	require.NoError(err)
+	require.True(warpConfig.Enabled)
+	require.Equal(uint64(67), warpConfig.QuorumNumerator)
+
+	messenger, err := CheckWarpCompatibility(rpcURL, chain.registry, WarpRequirements{MinMessengerVersion: big.NewInt(2)})
+	require.NoError(err)
+	require.Equal(chain.messengers[2], messenger.Address)
+
+	_, err = CheckWarpCompatibility(rpcURL, chain.registry, WarpRequirements{MinMessengerVersion: big.NewInt(3)})
+	require.ErrorIs(err, ErrMessengerVersionTooOld)
+
+	_, err = CheckWarpCompatibility(rpcURL, chain.registry, WarpRequirements{QuorumNumerator: 80})
+	require.ErrorIs(err, ErrWarpQuorumMismatch)
+
+	chain.warpConfig = map[string]interface{}{"blockTimestamp": 0, "quorumNumerator": 80}
+	_, err = CheckWarpCompatibility(rpcURL, chain.registry, WarpRequirements{QuorumNumerator: 80})
+	require.NoError(err)
+	_, err = CheckWarpCompatibility(rpcURL, chain.registry, WarpRequirements{})
+	require.ErrorIs(err, ErrWarpQuorumMismatch)
+
+	chain.warpConfig = nil
+	_, err = CheckWarpCompatibility(rpcURL, chain.registry, WarpRequirements{})
+	require.ErrorIs(err, ErrWarpNotEnabled)
+}
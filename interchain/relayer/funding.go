@@ -0,0 +1,100 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayer
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/awm-relayer/config"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// FundingRecord registers a top up of a relayer account made by FundRelayerAccounts
+type FundingRecord struct {
+	BlockchainID   string
+	RelayerAddress string
+	// balance of the relayer account before the top up
+	PrevBalance *big.Int
+	Amount      *big.Int
+	Time        time.Time
+}
+
+// FundingHistory is the list of top ups made to relayer accounts
+type FundingHistory []FundingRecord
+
+// Total returns the amount spent in all top ups of [h]
+func (h FundingHistory) Total() *big.Int {
+	total := big.NewInt(0)
+	for _, record := range h {
+		total.Add(total, record.Amount)
+	}
+	return total
+}
+
+// TotalByBlockchain returns the amount spent in top ups of [h], by blockchain ID
+func (h FundingHistory) TotalByBlockchain() map[string]*big.Int {
+	totals := map[string]*big.Int{}
+	for _, record := range h {
+		if _, ok := totals[record.BlockchainID]; !ok {
+			totals[record.BlockchainID] = big.NewInt(0)
+		}
+		totals[record.BlockchainID].Add(totals[record.BlockchainID], record.Amount)
+	}
+	return totals
+}
+
+// FundRelayerAccounts checks the balance of the relayer account on every destination
+// blockchain of [relayerConfig]. If the balance is below [minBalance], transfers from
+// [treasuryPrivateKey] the amount needed so as balance == [targetBalance].
+// Returns the top ups made, also on error, so the caller can keep track of the spend
+func FundRelayerAccounts(
+	relayerConfig *config.Config,
+	treasuryPrivateKey string,
+	minBalance *big.Int,
+	targetBalance *big.Int,
+) (FundingHistory, error) {
+	if minBalance == nil || targetBalance == nil {
+		return nil, fmt.Errorf("failure funding relayer accounts: you must provide a min balance and a target balance")
+	}
+	if targetBalance.Cmp(minBalance) < 0 {
+		return nil, fmt.Errorf("failure funding relayer accounts: target balance %s is lower than min balance %s", targetBalance, minBalance)
+	}
+	history := FundingHistory{}
+	for _, destination := range relayerConfig.DestinationBlockchains {
+		relayerPK, err := crypto.HexToECDSA(destination.AccountPrivateKey)
+		if err != nil {
+			return history, fmt.Errorf("invalid relayer private key for blockchain %s: %w", destination.BlockchainID, err)
+		}
+		relayerAddress := crypto.PubkeyToAddress(relayerPK.PublicKey).Hex()
+		client, err := evm.GetClient(destination.RPCEndpoint.BaseURL)
+		if err != nil {
+			return history, err
+		}
+		balance, err := evm.GetAddressBalance(client, relayerAddress)
+		if err != nil {
+			client.Close()
+			return history, err
+		}
+		if balance.Cmp(minBalance) >= 0 {
+			client.Close()
+			continue
+		}
+		amount := big.NewInt(0).Sub(targetBalance, balance)
+		err = evm.Transfer(client, treasuryPrivateKey, relayerAddress, amount)
+		client.Close()
+		if err != nil {
+			return history, fmt.Errorf("failure funding relayer %s on blockchain %s: %w", relayerAddress, destination.BlockchainID, err)
+		}
+		history = append(history, FundingRecord{
+			BlockchainID:   destination.BlockchainID,
+			RelayerAddress: relayerAddress,
+			PrevBalance:    balance,
+			Amount:         amount,
+			Time:           time.Now(),
+		})
+	}
+	return history, nil
+}
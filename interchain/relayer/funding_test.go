@@ -0,0 +1,36 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/awm-relayer/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFundingHistoryTotals(t *testing.T) {
+	history := FundingHistory{
+		{BlockchainID: "a", Amount: big.NewInt(10)},
+		{BlockchainID: "b", Amount: big.NewInt(5)},
+		{BlockchainID: "a", Amount: big.NewInt(7)},
+	}
+	require.Equal(t, big.NewInt(22), history.Total())
+	require.Equal(t, map[string]*big.Int{
+		"a": big.NewInt(17),
+		"b": big.NewInt(5),
+	}, history.TotalByBlockchain())
+	require.Equal(t, big.NewInt(0), FundingHistory{}.Total())
+}
+
+func TestFundRelayerAccountsInvalidBalances(t *testing.T) {
+	relayerConfig := &config.Config{}
+	_, err := FundRelayerAccounts(relayerConfig, "", nil, big.NewInt(1))
+	require.Error(t, err)
+	_, err = FundRelayerAccounts(relayerConfig, "", big.NewInt(2), big.NewInt(1))
+	require.Error(t, err)
+	history, err := FundRelayerAccounts(relayerConfig, "", big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
@@ -17,6 +17,13 @@ type Keychain struct {
 	keychain.Keychain
 	network avalanche.Network
 	Ledger  *Ledger
+
+	// KeyID identifies the key material in the SignerMetadata of the keychain signatures.
+	// NewKeychain sets it to the key path for stored keys
+	KeyID string
+
+	// members are the keychains merged by NewUnionKeychain
+	members []*Keychain
 }
 
 // LedgerParams is an input to NewKeyChain if a new keychain is to be created using Ledger
@@ -38,6 +45,15 @@ type LedgerParams struct {
 	// For example if Ledger's index 0 and index 1 each contains 0.1 AVAX and RequiredFunds is
 	// 0.2 AVAX, LedgerIndices will have value of [0,1]
 	RequiredFunds uint64
+
+	// DiscoverAddresses makes NewKeychain add all the used addresses in Ledger, found by
+	// scanning its indices until DiscoveryGapLimit consecutive unused addresses, as BIP44
+	// wallets do. This finds funds on any index without having to know it
+	DiscoverAddresses bool
+
+	// DiscoveryGapLimit is the number of consecutive unused addresses after which the
+	// discovery stops. ledger.DefaultGapLimit is used if 0
+	DiscoveryGapLimit uint32
 }
 
 // Ledger is part of the output of NewKeyChain if a new keychain is to be created using Ledger
@@ -47,6 +63,10 @@ type Ledger struct {
 
 	// LedgerIndices contain indexes of the addresses selected from Ledger
 	LedgerIndices []uint32
+
+	// DeviceSerial optionally identifies the device in the SignerMetadata of the
+	// keychain signatures, as the serial can't be read from the ledger app
+	DeviceSerial string
 }
 
 // NewKeychain generates a new key pair from either a stored key path or Ledger.
@@ -81,6 +101,11 @@ func NewKeychain(
 				return nil, err
 			}
 		}
+		if ledgerInfo.DiscoverAddresses {
+			if _, err := kc.AddDiscoveredLedgerAddresses(ledgerInfo.DiscoveryGapLimit); err != nil {
+				return nil, err
+			}
+		}
 		if len(kc.Ledger.LedgerIndices) == 0 {
 			return nil, fmt.Errorf("keychain currently does not contain any addresses from ledger")
 		}
@@ -93,6 +118,7 @@ func NewKeychain(
 	kc := Keychain{
 		Keychain: sf.KeyChain(),
 		network:  network,
+		KeyID:    keyPath,
 	}
 	return &kc, nil
 }
@@ -103,7 +129,7 @@ func (kc *Keychain) P() ([]string, error) {
 }
 
 func (kc *Keychain) LedgerEnabled() bool {
-	return kc.Ledger.LedgerDevice != nil
+	return kc.Ledger != nil && kc.Ledger.LedgerDevice != nil
 }
 
 func (kc *Keychain) AddLedgerIndices(indices []uint32) error {
@@ -141,3 +167,23 @@ func (kc *Keychain) AddLedgerFunds(amount uint64) error {
 	}
 	return fmt.Errorf("keychain is not ledger enabled")
 }
+
+// AddDiscoveredLedgerAddresses adds the used addresses in Ledger, found by scanning its
+// indices until [gapLimit] consecutive unused addresses, and returns them
+func (kc *Keychain) AddDiscoveredLedgerAddresses(gapLimit uint32) ([]ledger.DiscoveredAddress, error) {
+	if kc.LedgerEnabled() {
+		discovered, err := kc.Ledger.LedgerDevice.DiscoverAddresses(kc.network, gapLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(discovered) == 0 {
+			return discovered, nil
+		}
+		indices := make([]uint32, 0, len(discovered))
+		for _, addr := range discovered {
+			indices = append(indices, addr.Index)
+		}
+		return discovered, kc.AddLedgerIndices(indices)
+	}
+	return nil, fmt.Errorf("keychain is not ledger enabled")
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package keychain
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// ledgerDerivationPathFormat is the BIP44 path of the Avalanche ledger app addresses
+const ledgerDerivationPathFormat = "m/44'/9000'/0'/0/%d"
+
+// SignerMetadata describes the key that produced a signature, so signed txs can be
+// audited to know which key material was used
+type SignerMetadata struct {
+	// Address of the signing key
+	Address ids.ShortID `json:"address"`
+	// DerivationPath of the key, for hardware wallets
+	DerivationPath string `json:"derivationPath,omitempty"`
+	// DeviceSerial identifies the hardware wallet holding the key
+	DeviceSerial string `json:"deviceSerial,omitempty"`
+	// KeyID identifies the key material, eg the key file or the KMS key
+	KeyID string `json:"keyID,omitempty"`
+}
+
+// SignerMetadata returns the metadata of the keychain key for [addr]. It returns
+// false if the keychain doesn't hold [addr]
+func (kc *Keychain) SignerMetadata(addr ids.ShortID) (SignerMetadata, bool, error) {
+	if kc.Keychain == nil {
+		return SignerMetadata{}, false, nil
+	}
+	if kc.members != nil {
+		member, ok := kc.member(addr)
+		if !ok {
+			return SignerMetadata{}, false, nil
+		}
+		return member.SignerMetadata(addr)
+	}
+	if addrs := kc.Addresses(); !addrs.Contains(addr) {
+		return SignerMetadata{}, false, nil
+	}
+	metadata := SignerMetadata{
+		Address: addr,
+		KeyID:   kc.KeyID,
+	}
+	if kc.LedgerEnabled() {
+		metadata.DeviceSerial = kc.Ledger.DeviceSerial
+		addrs, err := kc.Ledger.LedgerDevice.Addresses(kc.Ledger.LedgerIndices)
+		if err != nil {
+			return SignerMetadata{}, false, fmt.Errorf("failure getting ledger addresses: %w", err)
+		}
+		for i, ledgerAddr := range addrs {
+			if ledgerAddr == addr {
+				metadata.DerivationPath = fmt.Sprintf(ledgerDerivationPathFormat, kc.Ledger.LedgerIndices[i])
+				break
+			}
+		}
+	}
+	return metadata, true, nil
+}
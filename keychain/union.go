@@ -0,0 +1,76 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package keychain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var ErrNoKeychains = errors.New("at least one keychain is needed")
+
+// unionKeychain resolves each address on the first of its keychains that holds it
+type unionKeychain struct {
+	keychains []keychain.Keychain
+}
+
+func (u *unionKeychain) Get(addr ids.ShortID) (keychain.Signer, bool) {
+	for _, kc := range u.keychains {
+		if signer, ok := kc.Get(addr); ok {
+			return signer, true
+		}
+	}
+	return nil, false
+}
+
+func (u *unionKeychain) Addresses() set.Set[ids.ShortID] {
+	addrs := set.Set[ids.ShortID]{}
+	for _, kc := range u.keychains {
+		addrs.Union(kc.Addresses())
+	}
+	return addrs
+}
+
+// NewUnionKeychain creates a keychain for [network] that merges [keychains], eg. a
+// stored key that funds the txs and a ledger that holds the subnet auth keys. Each
+// address is signed for by the first of [keychains] that holds it.
+//
+// Any other avalanchego keychain, eg. one backed by a KMS, can be merged by wrapping it
+// as &Keychain{Keychain: kc, KeyID: id}
+func NewUnionKeychain(network avalanche.Network, keychains ...*Keychain) (*Keychain, error) {
+	if len(keychains) == 0 {
+		return nil, ErrNoKeychains
+	}
+	union := &unionKeychain{}
+	for i, kc := range keychains {
+		if kc == nil || kc.Keychain == nil {
+			return nil, fmt.Errorf("keychain %d has no keys", i)
+		}
+		union.keychains = append(union.keychains, kc)
+	}
+	return &Keychain{
+		Keychain: union,
+		network:  network,
+		members:  keychains,
+	}, nil
+}
+
+// Members returns the keychains merged by NewUnionKeychain, or nil for other keychains
+func (kc *Keychain) Members() []*Keychain {
+	return kc.members
+}
+
+// member returns the keychain of a union that signs for [addr]
+func (kc *Keychain) member(addr ids.ShortID) (*Keychain, bool) {
+	for _, member := range kc.members {
+		if addrs := member.Addresses(); addrs.Contains(addr) {
+			return member, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package keychain
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/key"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func newSoftKeychain(t *testing.T, keyID string) (*Keychain, ids.ShortID) {
+	sk, err := key.NewSoft()
+	require.NoError(t, err)
+	kc := sk.KeyChain()
+	return &Keychain{Keychain: kc, KeyID: keyID}, kc.Addresses().List()[0]
+}
+
+func TestUnionKeychain(t *testing.T) {
+	require := require.New(t)
+	funding, fundingAddr := newSoftKeychain(t, "funding.pk")
+	auth, authAddr := newSoftKeychain(t, "auth.pk")
+
+	_, err := NewUnionKeychain(avalanche.FujiNetwork())
+	require.ErrorIs(err, ErrNoKeychains)
+	_, err = NewUnionKeychain(avalanche.FujiNetwork(), funding, &Keychain{})
+	require.ErrorContains(err, "keychain 1 has no keys")
+
+	kc, err := NewUnionKeychain(avalanche.FujiNetwork(), funding, auth)
+	require.NoError(err)
+	require.Len(kc.Members(), 2)
+	require.False(kc.LedgerEnabled())
+
+	addrs := kc.Addresses()
+	require.Equal(2, addrs.Len())
+	signer, ok := kc.Get(authAddr)
+	require.True(ok)
+	require.Equal(authAddr, signer.Address())
+	_, ok = kc.Get(ids.GenerateTestShortID())
+	require.False(ok)
+
+	pAddrs, err := kc.P()
+	require.NoError(err)
+	require.Len(pAddrs, 2)
+
+	metadata, ok, err := kc.SignerMetadata(fundingAddr)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal("funding.pk", metadata.KeyID)
+	metadata, ok, err = kc.SignerMetadata(authAddr)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal("auth.pk", metadata.KeyID)
+	_, ok, err = kc.SignerMetadata(ids.GenerateTestShortID())
+	require.NoError(err)
+	require.False(ok)
+}
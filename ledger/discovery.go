@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+// DefaultGapLimit is the number of consecutive unused addresses after which
+// DiscoverAddresses stops, as BIP44 wallets do
+const DefaultGapLimit = 20
+
+// DiscoveredAddress is a used ledger address found by DiscoverAddresses
+type DiscoveredAddress struct {
+	Index   uint32
+	Address ids.ShortID
+	// Balance is the AVAX balance of the address on the P-Chain, in nAVAX. It is 0 for
+	// addresses holding only other assets
+	Balance uint64
+}
+
+type balanceClient interface {
+	GetBalance(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*platformvm.GetBalanceResponse, error)
+}
+
+// DiscoverAddresses scans the ledger addresses in index order, querying their P-Chain
+// UTXOs at [network], and returns the used ones, so funds on any index can be found
+// without knowing it. The scan stops after [gapLimit] consecutive unused addresses,
+// DefaultGapLimit if 0, or at the max index searched by FindAddresses.
+//
+// Addresses are considered used if they own UTXOs, so the ones whose funds were fully
+// spent, or are staked, are not found
+func (dev *LedgerDevice) DiscoverAddresses(
+	network avalanche.Network,
+	gapLimit uint32,
+) ([]DiscoveredAddress, error) {
+	return discoverAddresses(dev.Ledger, platformvm.NewClient(network.Endpoint), gapLimit)
+}
+
+func discoverAddresses(
+	dev keychain.Ledger,
+	pClient balanceClient,
+	gapLimit uint32,
+) ([]DiscoveredAddress, error) {
+	if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+	discovered := []DiscoveredAddress{}
+	unused := uint32(0)
+	for index := uint32(0); index < maxIndexToSearch && unused < gapLimit; index++ {
+		ledgerAddress, err := dev.Addresses([]uint32{index})
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := utils.GetAPIContext()
+		resp, err := pClient.GetBalance(ctx, ledgerAddress)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failure getting balance of ledger index %d: %w", index, err)
+		}
+		if len(resp.UTXOIDs) == 0 {
+			unused++
+			continue
+		}
+		unused = 0
+		discovered = append(discovered, DiscoveredAddress{
+			Index:   index,
+			Address: ledgerAddress[0],
+			Balance: uint64(resp.Balance),
+		})
+	}
+	return discovered, nil
+}
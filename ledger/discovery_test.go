@@ -0,0 +1,87 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package ledger
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/keychain"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLedger derives the address of an index from the index itself
+type fakeLedger struct {
+	keychain.Ledger
+	derived int
+}
+
+func fakeLedgerAddress(index uint32) ids.ShortID {
+	addr := ids.ShortID{1}
+	binary.BigEndian.PutUint32(addr[16:], index)
+	return addr
+}
+
+func (l *fakeLedger) Addresses(indices []uint32) ([]ids.ShortID, error) {
+	addrs := []ids.ShortID{}
+	for _, index := range indices {
+		addrs = append(addrs, fakeLedgerAddress(index))
+		l.derived++
+	}
+	return addrs, nil
+}
+
+// fakeBalanceClient has UTXOs for the addresses in [balances]
+type fakeBalanceClient struct {
+	balances map[ids.ShortID]uint64
+}
+
+func (c *fakeBalanceClient) GetBalance(_ context.Context, addrs []ids.ShortID, _ ...rpc.Option) (*platformvm.GetBalanceResponse, error) {
+	resp := &platformvm.GetBalanceResponse{}
+	for _, addr := range addrs {
+		if balance, ok := c.balances[addr]; ok {
+			resp.Balance += json.Uint64(balance)
+			resp.UTXOIDs = append(resp.UTXOIDs, &avax.UTXOID{TxID: ids.GenerateTestID()})
+		}
+	}
+	return resp, nil
+}
+
+func TestDiscoverAddresses(t *testing.T) {
+	require := require.New(t)
+	client := &fakeBalanceClient{balances: map[ids.ShortID]uint64{
+		fakeLedgerAddress(0):  1_000,
+		fakeLedgerAddress(3):  0, // holds other assets only
+		fakeLedgerAddress(12): 5_000,
+		fakeLedgerAddress(40): 7_000,
+	}}
+
+	dev := &fakeLedger{}
+	discovered, err := discoverAddresses(dev, client, 10)
+	require.NoError(err)
+	require.Equal([]DiscoveredAddress{
+		{Index: 0, Address: fakeLedgerAddress(0), Balance: 1_000},
+		{Index: 3, Address: fakeLedgerAddress(3)},
+		{Index: 12, Address: fakeLedgerAddress(12), Balance: 5_000},
+	}, discovered)
+	// stops after 10 unused addresses following index 12
+	require.Equal(23, dev.derived)
+
+	// a larger gap reaches index 40
+	discovered, err = discoverAddresses(&fakeLedger{}, client, 30)
+	require.NoError(err)
+	require.Len(discovered, 4)
+	require.Equal(uint32(40), discovered[3].Index)
+
+	dev = &fakeLedger{}
+	discovered, err = discoverAddresses(dev, &fakeBalanceClient{}, 0)
+	require.NoError(err)
+	require.Empty(discovered)
+	require.Equal(DefaultGapLimit, dev.derived)
+}
@@ -0,0 +1,321 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package logparse parses avalanchego logs, as fetched with node.Node.GetLogs, into
+// structured records that can be filtered and summarized
+package logparse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// chainLoggerSuffix is appended by avalanchego to the alias of a chain to name its logger
+const chainLoggerSuffix = " Chain"
+
+var (
+	ErrInvalidLogLine = errors.New("invalid log line")
+
+	// termLineRegex matches the avalanchego plain and colors formats, eg.
+	// [05-02|20:50:07.652] INFO <P Chain> snowman/transitive.go:583 consensus starting {"height": 1}
+	termLineRegex = regexp.MustCompile(`^\[(\d\d-\d\d\|\d\d:\d\d:\d\d\.\d{3})\] ([A-Z]+) (?:<([^>]*)> )?(?:(\S+\.go:\d+) )?(.*)$`)
+	ansiRegex     = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+const termTimeLayout = "01-02|15:04:05.000"
+
+// standard keys of the avalanchego JSON format
+const (
+	timeKey    = "timestamp"
+	levelKey   = "level"
+	loggerKey  = "logger"
+	callerKey  = "caller"
+	messageKey = "msg"
+)
+
+// Record is a parsed log entry
+type Record struct {
+	Time time.Time `json:"time"`
+	// Level is logging.Off if the entry level is unknown
+	Level  logging.Level `json:"level"`
+	Logger string        `json:"logger,omitempty"`
+	Caller string        `json:"caller,omitempty"`
+	Msg    string        `json:"msg"`
+	// Fields are the structured fields of the entry
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// Continuation holds the lines that follow the entry without being log entries
+	// themselves, eg. stack traces
+	Continuation []string `json:"continuation,omitempty"`
+}
+
+// Chain returns the alias or ID of the chain that logged the entry, or an empty
+// string for entries not logged by a chain
+func (r Record) Chain() string {
+	if chain, ok := strings.CutSuffix(r.Logger, chainLoggerSuffix); ok {
+		return chain
+	}
+	return ""
+}
+
+// Subsystem returns the package that logged the entry, taken from its caller, eg.
+// "snowman" for snowman/transitive.go:583
+func (r Record) Subsystem() string {
+	subsystem, _, ok := strings.Cut(r.Caller, "/")
+	if !ok {
+		return ""
+	}
+	return subsystem
+}
+
+// String renders the entry in the avalanchego plain format
+func (r Record) String() string {
+	parts := []string{r.Time.Format("[" + termTimeLayout + "]"), r.Level.String()}
+	if r.Logger != "" {
+		parts = append(parts, "<"+r.Logger+">")
+	}
+	if r.Caller != "" {
+		parts = append(parts, r.Caller)
+	}
+	parts = append(parts, r.Msg)
+	if len(r.Fields) > 0 {
+		if fieldsBytes, err := json.Marshal(r.Fields); err == nil {
+			parts = append(parts, string(fieldsBytes))
+		}
+	}
+	line := strings.Join(parts, " ")
+	for _, continuation := range r.Continuation {
+		line += "\n" + continuation
+	}
+	return line
+}
+
+// ParseLine parses a single log line in any of the avalanchego formats. As the plain
+// format has no year, the current one is assumed
+func ParseLine(line string) (Record, error) {
+	line = strings.TrimSpace(ansiRegex.ReplaceAllString(line, ""))
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	return parseTermLine(line)
+}
+
+func parseJSONLine(line string) (Record, error) {
+	fields := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return Record{}, fmt.Errorf("%w: %w", ErrInvalidLogLine, err)
+	}
+	record := Record{Level: logging.Off}
+	timestamp, ok := fields[timeKey].(string)
+	if !ok {
+		return Record{}, fmt.Errorf("%w: no %s", ErrInvalidLogLine, timeKey)
+	}
+	var err error
+	record.Time, err = time.Parse("2006-01-02T15:04:05.000Z0700", timestamp)
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: %w", ErrInvalidLogLine, err)
+	}
+	if level, ok := fields[levelKey].(string); ok {
+		record.Level, _ = logging.ToLevel(level)
+	}
+	record.Logger, _ = fields[loggerKey].(string)
+	record.Caller, _ = fields[callerKey].(string)
+	record.Msg, _ = fields[messageKey].(string)
+	for _, key := range []string{timeKey, levelKey, loggerKey, callerKey, messageKey} {
+		delete(fields, key)
+	}
+	if len(fields) > 0 {
+		record.Fields = fields
+	}
+	return record, nil
+}
+
+func parseTermLine(line string) (Record, error) {
+	matches := termLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return Record{}, ErrInvalidLogLine
+	}
+	timestamp, err := time.Parse(termTimeLayout, matches[1])
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: %w", ErrInvalidLogLine, err)
+	}
+	level, _ := logging.ToLevel(matches[2])
+	record := Record{
+		Time:   timestamp.AddDate(time.Now().UTC().Year(), 0, 0),
+		Level:  level,
+		Logger: matches[3],
+		Caller: matches[4],
+		Msg:    matches[5],
+	}
+	// the fields are appended as a JSON object, and the message may contain braces
+	for i := strings.Index(record.Msg, " {"); i != -1; {
+		fields := map[string]interface{}{}
+		decoder := json.NewDecoder(strings.NewReader(record.Msg[i+1:]))
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err == nil && decoder.InputOffset() == int64(len(record.Msg)-i-1) {
+			record.Msg, record.Fields = record.Msg[:i], fields
+			break
+		}
+		next := strings.Index(record.Msg[i+1:], " {")
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return record, nil
+}
+
+// Parse parses all the entries of [r]. Lines that are not log entries are added as
+// continuation of the previous entry, or skipped if there is none
+func Parse(r io.Reader) ([]Record, error) {
+	records := []Record{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		record, err := ParseLine(line)
+		if err != nil {
+			if len(records) > 0 {
+				last := &records[len(records)-1]
+				last.Continuation = append(last.Continuation, line)
+			}
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// ParseBytes is a shorthand for Parse on the output of node.Node.GetLogs
+func ParseBytes(logs []byte) ([]Record, error) {
+	return Parse(bytes.NewReader(logs))
+}
+
+// Filter selects the records to keep
+type Filter func(Record) bool
+
+// ByChain keeps the records logged by [chain], given by alias or ID
+func ByChain(chain string) Filter {
+	return func(r Record) bool {
+		return r.Chain() == chain
+	}
+}
+
+// BySubsystem keeps the records logged by the package [subsystem], eg. "snowman"
+func BySubsystem(subsystem string) Filter {
+	return func(r Record) bool {
+		return r.Subsystem() == subsystem
+	}
+}
+
+// MinLevel keeps the records of at least [level]
+func MinLevel(level logging.Level) Filter {
+	return func(r Record) bool {
+		return r.Level >= level && r.Level != logging.Off
+	}
+}
+
+// Since keeps the records logged at or after [t]
+func Since(t time.Time) Filter {
+	return func(r Record) bool {
+		return !r.Time.Before(t)
+	}
+}
+
+// Select returns the records accepted by all [filters]
+func Select(records []Record, filters ...Filter) []Record {
+	selected := []Record{}
+	for _, record := range records {
+		accepted := true
+		for _, filter := range filters {
+			if !filter(record) {
+				accepted = false
+				break
+			}
+		}
+		if accepted {
+			selected = append(selected, record)
+		}
+	}
+	return selected
+}
+
+// ErrorSummary counts the occurrences of an error message of a logger
+type ErrorSummary struct {
+	Level  logging.Level
+	Logger string
+	Msg    string
+	Count  int
+	First  time.Time
+	Last   time.Time
+}
+
+func (s ErrorSummary) String() string {
+	logger := ""
+	if s.Logger != "" {
+		logger = "<" + s.Logger + "> "
+	}
+	return fmt.Sprintf("%dx %s %s%s (last at %s)", s.Count, s.Level, logger, s.Msg, s.Last.Format(time.RFC3339))
+}
+
+// SummarizeErrors groups the entries of level Warn and above by level, logger and
+// message, sorted by decreasing count
+func SummarizeErrors(records []Record) []ErrorSummary {
+	type key struct {
+		level  logging.Level
+		logger string
+		msg    string
+	}
+	summaries := map[key]*ErrorSummary{}
+	for _, record := range Select(records, MinLevel(logging.Warn)) {
+		k := key{record.Level, record.Logger, record.Msg}
+		summary, ok := summaries[k]
+		if !ok {
+			summary = &ErrorSummary{
+				Level:  record.Level,
+				Logger: record.Logger,
+				Msg:    record.Msg,
+				First:  record.Time,
+			}
+			summaries[k] = summary
+		}
+		summary.Count++
+		if record.Time.Before(summary.First) {
+			summary.First = record.Time
+		}
+		if record.Time.After(summary.Last) {
+			summary.Last = record.Time
+		}
+	}
+	sorted := make([]ErrorSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		sorted = append(sorted, *summary)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		if sorted[i].Level != sorted[j].Level {
+			return sorted[i].Level > sorted[j].Level
+		}
+		if sorted[i].Logger != sorted[j].Logger {
+			return sorted[i].Logger < sorted[j].Logger
+		}
+		return sorted[i].Msg < sorted[j].Msg
+	})
+	return sorted
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logparse
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+const testLogs = `[05-02|20:50:03.432] INFO node/node.go:1525 initializing API server
+[05-02|20:50:07.652] INFO <P Chain> snowman/transitive.go:583 consensus starting {"lastAcceptedBlock": "2p3h", "height": 12}
+[05-02|20:50:08.001] WARN <C Chain> handler/handler.go:400 message {not json} dropped {"op": "get"}
+[05-02|20:50:09.100] ERROR <C Chain> snowman/bootstrap.go:210 failed to fetch {"error": "timeout"}
+goroutine 1 [running]:
+main.main()
+[05-02|20:50:10.100] ERROR <C Chain> snowman/bootstrap.go:210 failed to fetch {"error": "timeout"}
+` + "\x1b[31m[05-02|20:50:11.000]\x1b[0m \x1b[1;31mERROR\x1b[0m network/network.go:10 peer dropped\n" +
+	`{"level":"fatal","timestamp":"2024-05-02T20:50:12.000Z","logger":"P Chain","caller":"platformvm/vm.go:99","msg":"shutting down","reason":"db closed"}
+`
+
+func TestParse(t *testing.T) {
+	require := require.New(t)
+	records, err := Parse(strings.NewReader(testLogs))
+	require.NoError(err)
+	require.Len(records, 7)
+
+	main := records[0]
+	require.Equal(logging.Info, main.Level)
+	require.Equal("node/node.go:1525", main.Caller)
+	require.Equal("initializing API server", main.Msg)
+	require.Equal("", main.Chain())
+	require.Equal("node", main.Subsystem())
+	require.Equal(time.Month(5), main.Time.Month())
+	require.Equal(time.Now().UTC().Year(), main.Time.Year())
+
+	require.Equal("P", records[1].Chain())
+	require.Equal("consensus starting", records[1].Msg)
+	require.Equal(map[string]interface{}{"lastAcceptedBlock": "2p3h", "height": json.Number("12")}, records[1].Fields)
+	require.Equal(`[05-02|20:50:07.652] INFO <P Chain> snowman/transitive.go:583 consensus starting {"height":12,"lastAcceptedBlock":"2p3h"}`, records[1].String())
+
+	require.Equal("message {not json} dropped", records[2].Msg)
+	require.Equal([]string{"goroutine 1 [running]:", "main.main()"}, records[3].Continuation)
+	require.Equal(logging.Error, records[5].Level)
+	require.Equal("peer dropped", records[5].Msg)
+
+	fatal := records[6]
+	require.Equal(logging.Fatal, fatal.Level)
+	require.Equal("P", fatal.Chain())
+	require.Equal("platformvm", fatal.Subsystem())
+	require.Equal(time.Date(2024, 5, 2, 20, 50, 12, 0, time.UTC), fatal.Time.UTC())
+	require.Equal(map[string]interface{}{"reason": "db closed"}, fatal.Fields)
+
+	_, err = ParseLine("not a log line")
+	require.ErrorIs(err, ErrInvalidLogLine)
+}
+
+func TestSelectAndSummarize(t *testing.T) {
+	require := require.New(t)
+	records, err := ParseBytes([]byte(testLogs))
+	require.NoError(err)
+
+	require.Len(Select(records, ByChain("C")), 3)
+	require.Len(Select(records, ByChain("C"), BySubsystem("snowman")), 2)
+	require.Len(Select(records, MinLevel(logging.Error)), 4)
+	// the year of the plain format entries is assumed, so only those are compared
+	require.Len(Select(records[:6], Since(records[4].Time)), 2)
+
+	summaries := SummarizeErrors(records)
+	require.Len(summaries, 4)
+	require.Equal(2, summaries[0].Count)
+	require.Equal("failed to fetch", summaries[0].Msg)
+	require.Equal(records[3].Time, summaries[0].First)
+	require.Equal(records[4].Time, summaries[0].Last)
+	require.Equal(logging.Fatal, summaries[1].Level)
+	require.Equal(logging.Warn, summaries[3].Level)
+}
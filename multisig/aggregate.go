@@ -0,0 +1,128 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	ErrTxMismatch           = errors.New("partially signed txs are not the same tx")
+	ErrConflictingSignature = errors.New("conflicting signatures")
+)
+
+// Aggregate returns a tx with all the signatures of [partiallySigned], copies of the same
+// tx signed by different wallets, eg. the co-signers of a multisig address each signing
+// with Wallet.SignMultisig. The given txs are not modified
+func Aggregate(partiallySigned ...*Multisig) (*Multisig, error) {
+	if len(partiallySigned) == 0 || partiallySigned[0].Undefined() {
+		return nil, ErrUndefinedTx
+	}
+	txBytes, err := partiallySigned[0].ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	aggregated := &Multisig{}
+	if err := aggregated.FromBytes(txBytes); err != nil {
+		return nil, err
+	}
+	aggregated.AddSignerMetadata(partiallySigned[0].signers...)
+	if err := aggregated.Merge(partiallySigned[1:]...); err != nil {
+		return nil, err
+	}
+	return aggregated, nil
+}
+
+// Merge adds to the tx the signatures of [others], copies of the same tx signed by
+// different wallets, along with their signer metadata. It fails with ErrTxMismatch if
+// any of them is a different tx, and with ErrConflictingSignature if they have different
+// signatures for the same position, in which case the tx is not modified
+func (ms *Multisig) Merge(others ...*Multisig) error {
+	if ms.Undefined() {
+		return ErrUndefinedTx
+	}
+	unsignedBytes := ms.PChainTx.Unsigned.Bytes()
+	if len(unsignedBytes) == 0 {
+		return fmt.Errorf("tx is not initialized")
+	}
+	creds, err := copyCreds(ms.PChainTx.Creds)
+	if err != nil {
+		return err
+	}
+	for i, other := range others {
+		if other.Undefined() {
+			return ErrUndefinedTx
+		}
+		if !bytes.Equal(other.PChainTx.Unsigned.Bytes(), unsignedBytes) {
+			return fmt.Errorf("%w: tx %d has different unsigned bytes", ErrTxMismatch, i)
+		}
+		if creds, err = mergeCreds(creds, other.PChainTx.Creds); err != nil {
+			return fmt.Errorf("couldn't merge tx %d: %w", i, err)
+		}
+	}
+	ms.PChainTx.Creds = creds
+	if err := ms.PChainTx.Initialize(txs.Codec); err != nil {
+		return fmt.Errorf("error initializing merged tx: %w", err)
+	}
+	for _, other := range others {
+		ms.AddSignerMetadata(other.signers...)
+	}
+	return nil
+}
+
+// mergeCreds fills the empty signatures of [creds] with the ones of [otherCreds].
+// A tx not signed by a wallet has no creds, so any of them may be empty
+func mergeCreds(creds []verify.Verifiable, otherCreds []verify.Verifiable) ([]verify.Verifiable, error) {
+	if len(otherCreds) == 0 {
+		return creds, nil
+	}
+	if len(creds) == 0 {
+		return copyCreds(otherCreds)
+	}
+	if len(creds) != len(otherCreds) {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrWrongNumberOfCreds, len(creds), len(otherCreds))
+	}
+	emptySig := [secp256k1.SignatureLen]byte{}
+	for credIndex := range creds {
+		cred := creds[credIndex].(*secp256k1fx.Credential)
+		otherCred, ok := otherCreds[credIndex].(*secp256k1fx.Credential)
+		if !ok {
+			return nil, fmt.Errorf("%w: cred %d is %T", ErrUnexpectedCredential, credIndex, otherCreds[credIndex])
+		}
+		if len(cred.Sigs) != len(otherCred.Sigs) {
+			return nil, fmt.Errorf("%w: cred %d has %d signatures, expected %d", ErrWrongNumberOfSigs, credIndex, len(otherCred.Sigs), len(cred.Sigs))
+		}
+		for sigIndex, sig := range otherCred.Sigs {
+			switch {
+			case sig == emptySig || sig == cred.Sigs[sigIndex]:
+			case cred.Sigs[sigIndex] == emptySig:
+				cred.Sigs[sigIndex] = sig
+			default:
+				return nil, fmt.Errorf("%w: signature %d of cred %d", ErrConflictingSignature, sigIndex, credIndex)
+			}
+		}
+	}
+	return creds, nil
+}
+
+// copyCreds returns a deep copy of [creds], that must be secp256k1fx credentials
+func copyCreds(creds []verify.Verifiable) ([]verify.Verifiable, error) {
+	copied := make([]verify.Verifiable, 0, len(creds))
+	for credIndex, credential := range creds {
+		cred, ok := credential.(*secp256k1fx.Credential)
+		if !ok {
+			return nil, fmt.Errorf("%w: cred %d is %T", ErrUnexpectedCredential, credIndex, credential)
+		}
+		copied = append(copied, &secp256k1fx.Credential{
+			Sigs: append([][secp256k1.SignatureLen]byte{}, cred.Sigs...),
+		})
+	}
+	return copied, nil
+}
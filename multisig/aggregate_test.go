@@ -0,0 +1,84 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	require := require.New(t)
+	keys := make([]*secp256k1.PrivateKey, 3)
+	for i := range keys {
+		key, err := secp256k1.NewPrivateKey()
+		require.NoError(err)
+		keys[i] = key
+	}
+	utxoID := avax.UTXOID{TxID: ids.GenerateTestID()}
+	newUnsignedTx := func() txs.UnsignedTx {
+		return &txs.ExportTx{
+			BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+				NetworkID:    constants.FujiID,
+				BlockchainID: constants.PlatformChainID,
+				Ins:          []*avax.TransferableInput{newSpendTestIn(utxoID, 0, 1)},
+			}},
+			DestinationChain: ids.GenerateTestID(),
+		}
+	}
+	unsignedTx := newUnsignedTx()
+	// signs with all of [signers], and keeps only the signature at [sigIndex]
+	partiallySign := func(signers []*secp256k1.PrivateKey, sigIndex int) *Multisig {
+		tx := &txs.Tx{Unsigned: unsignedTx}
+		require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{signers}))
+		cred := tx.Creds[0].(*secp256k1fx.Credential)
+		for i := range cred.Sigs {
+			if i != sigIndex {
+				cred.Sigs[i] = [secp256k1.SignatureLen]byte{}
+			}
+		}
+		require.NoError(tx.Initialize(txs.Codec))
+		return New(tx)
+	}
+	first := partiallySign(keys[:2], 0)
+	first.AddSignerMetadata(keychain.SignerMetadata{Address: keys[0].Address(), KeyID: "alice"})
+	second := partiallySign(keys[:2], 1)
+	second.AddSignerMetadata(keychain.SignerMetadata{Address: keys[1].Address(), KeyID: "bob"})
+	unsigned := New(&txs.Tx{Unsigned: unsignedTx})
+
+	ms, err := Aggregate(unsigned, first, second)
+	require.NoError(err)
+	ready, err := ms.IsReadyToCommit()
+	require.NoError(err)
+	require.True(ready)
+	require.Len(ms.SignerMetadata(), 2)
+	utxoOwners := map[ids.ID]*secp256k1fx.OutputOwners{
+		utxoID.InputID(): {Threshold: 2, Addrs: []ids.ShortID{keys[0].Address(), keys[1].Address()}},
+	}
+	require.NoError(VerifySignatures(ms.PChainTx, ExpectedOwners{UTXOOwners: utxoOwners}))
+	// the partially signed txs are not modified
+	ready, err = first.IsReadyToCommit()
+	require.NoError(err)
+	require.False(ready)
+
+	// a different key signed the same position
+	conflicting := partiallySign([]*secp256k1.PrivateKey{keys[2], keys[1]}, 0)
+	ms = New(first.PChainTx)
+	require.ErrorIs(ms.Merge(conflicting), ErrConflictingSignature)
+	require.Equal(first.PChainTx.Creds, ms.PChainTx.Creds)
+
+	other := New(&txs.Tx{Unsigned: newUnsignedTx()})
+	require.NoError(other.PChainTx.Initialize(txs.Codec))
+	_, err = Aggregate(first, other)
+	require.ErrorIs(err, ErrTxMismatch)
+	_, err = Aggregate()
+	require.ErrorIs(err, ErrUndefinedTx)
+}
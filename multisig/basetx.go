@@ -0,0 +1,64 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+var ErrNoBaseTx = errors.New("tx has no base tx")
+
+var avaxBaseTxType = reflect.TypeOf(avax.BaseTx{})
+
+// GetBaseTx returns the avax.BaseTx embedded in [unsignedTx], that holds its network
+// ID, blockchain ID, P-Chain inputs and outputs, and memo. As the BaseTx is found by
+// walking the embedded fields of the tx, it works for every P-Chain tx type,
+// including the ones added by later avalanchego versions. It returns ErrNoBaseTx for
+// the txs that don't have one, eg AdvanceTimeTx
+func GetBaseTx(unsignedTx txs.UnsignedTx) (*avax.BaseTx, error) {
+	v := reflect.ValueOf(unsignedTx)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("%w: %T", ErrNoBaseTx, unsignedTx)
+	}
+	baseTx := findBaseTx(v.Elem())
+	if baseTx == nil {
+		return nil, fmt.Errorf("%w: %T", ErrNoBaseTx, unsignedTx)
+	}
+	return baseTx, nil
+}
+
+// findBaseTx returns the avax.BaseTx that is, or is embedded in, the addressable
+// struct [v], or nil if there is none
+func findBaseTx(v reflect.Value) *avax.BaseTx {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if v.Type() == avaxBaseTxType {
+		if !v.CanAddr() || !v.CanInterface() {
+			return nil
+		}
+		baseTx, _ := v.Addr().Interface().(*avax.BaseTx)
+		return baseTx
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).Anonymous {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if baseTx := findBaseTx(field); baseTx != nil {
+			return baseTx
+		}
+	}
+	return nil
+}
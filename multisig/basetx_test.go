@@ -0,0 +1,69 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBaseTx(t *testing.T) {
+	require := require.New(t)
+	ins := []*avax.TransferableInput{{UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()}}}
+	baseTx := txs.BaseTx{
+		BaseTx: avax.BaseTx{
+			NetworkID:    constants.FujiID,
+			BlockchainID: constants.PlatformChainID,
+			Ins:          ins,
+		},
+	}
+	for _, unsignedTx := range []txs.UnsignedTx{
+		&baseTx,
+		&txs.CreateSubnetTx{BaseTx: baseTx},
+		&txs.CreateChainTx{BaseTx: baseTx},
+		&txs.AddValidatorTx{BaseTx: baseTx},
+		&txs.AddDelegatorTx{BaseTx: baseTx},
+		&txs.AddSubnetValidatorTx{BaseTx: baseTx},
+		&txs.RemoveSubnetValidatorTx{BaseTx: baseTx},
+		&txs.TransformSubnetTx{BaseTx: baseTx},
+		&txs.AddPermissionlessValidatorTx{BaseTx: baseTx},
+		&txs.AddPermissionlessDelegatorTx{BaseTx: baseTx},
+		&txs.TransferSubnetOwnershipTx{BaseTx: baseTx},
+		&txs.ImportTx{BaseTx: baseTx},
+		&txs.ExportTx{BaseTx: baseTx},
+	} {
+		got, err := GetBaseTx(unsignedTx)
+		require.NoError(err, "%T", unsignedTx)
+		require.Equal(constants.FujiID, got.NetworkID, "%T", unsignedTx)
+		require.Equal(constants.PlatformChainID, got.BlockchainID, "%T", unsignedTx)
+		gotIns, err := GetInputs(unsignedTx)
+		require.NoError(err)
+		require.Equal(ins, gotIns, "%T", unsignedTx)
+		networkID, err := New(&txs.Tx{Unsigned: unsignedTx}).GetNetworkID()
+		require.NoError(err)
+		require.Equal(constants.FujiID, networkID, "%T", unsignedTx)
+	}
+
+	// the returned base tx is the one embedded in the tx
+	createSubnetTx := &txs.CreateSubnetTx{BaseTx: baseTx}
+	got, err := GetBaseTx(createSubnetTx)
+	require.NoError(err)
+	got.Memo = []byte("memo")
+	require.Equal("memo", string(createSubnetTx.Memo))
+
+	for _, unsignedTx := range []txs.UnsignedTx{
+		&txs.AdvanceTimeTx{},
+		&txs.RewardValidatorTx{},
+		nil,
+	} {
+		_, err := GetBaseTx(unsignedTx)
+		require.ErrorIs(err, ErrNoBaseTx)
+	}
+	_, err = New(&txs.Tx{Unsigned: &txs.AdvanceTimeTx{}}).GetBlockchainID()
+	require.ErrorIs(err, ErrNoBaseTx)
+}
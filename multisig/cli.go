@@ -0,0 +1,53 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+)
+
+// avalanche-cli saves partially signed P-Chain txs to disk as the hex encoding (with
+// checksum) of the codec serialization of the signed tx, which is what
+// ToHex/FromHex produce and consume. The CLI functions below can be used to
+// exchange tx files with avalanche-cli users (eg `avalanche transaction sign`)
+
+// ToHex returns the signed tx encoded in avalanche-cli tx file format
+func (ms *Multisig) ToHex() (string, error) {
+	txBytes, err := ms.ToBytes()
+	if err != nil {
+		return "", err
+	}
+	txStr, err := formatting.Encode(formatting.Hex, txBytes)
+	if err != nil {
+		return "", fmt.Errorf("couldn't encode signed tx: %w", err)
+	}
+	return txStr, nil
+}
+
+// FromHex loads a signed tx encoded in avalanche-cli tx file format.
+// Surrounding whitespace is ignored
+func (ms *Multisig) FromHex(txStr string) error {
+	txBytes, err := formatting.Decode(formatting.Hex, strings.TrimSpace(txStr))
+	if err != nil {
+		return fmt.Errorf("couldn't decode signed tx: %w", err)
+	}
+	return ms.FromBytes(txBytes)
+}
+
+// ToCLIFile saves the signed tx to [txPath] so it can be loaded by avalanche-cli.
+// As avalanche-cli does, it fails if the file exists, unless [forceOverwrite] is set
+func (ms *Multisig) ToCLIFile(txPath string, forceOverwrite bool) error {
+	if utils.FileExists(txPath) && !forceOverwrite {
+		return fmt.Errorf("couldn't save tx to %s: file exists", txPath)
+	}
+	return ms.ToFile(txPath)
+}
+
+// FromCLIFile loads a signed tx saved by avalanche-cli at [txPath]
+func (ms *Multisig) FromCLIFile(txPath string) error {
+	return ms.FromFile(txPath)
+}
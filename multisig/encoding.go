@@ -0,0 +1,172 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// PChainAlias is the alias of the chain multisig txs belong to, used to tag the tagged
+// and protobuf encodings
+const PChainAlias = "P"
+
+// ProtoSchema is the protobuf definition of the ToProto encoding
+const ProtoSchema = `syntax = "proto3";
+
+message SignedTx {
+  // chain_alias is the alias of the chain the tx belongs to, eg. "P"
+  string chain_alias = 1;
+  // tx is the codec serialization of the signed tx
+  bytes tx = 2;
+}`
+
+const (
+	protoChainAliasField protowire.Number = 1
+	protoTxField         protowire.Number = 2
+)
+
+var (
+	ErrUnknownEncoding = errors.New("unknown tx encoding")
+	ErrWrongChainAlias = errors.New("tx belongs to a different chain")
+)
+
+// Encoding is a text encoding of a signed tx
+type Encoding string
+
+const (
+	// EncodingHex is the avalanche-cli tx file format. See ToHex
+	EncodingHex Encoding = "hex"
+	// EncodingBase64 is the standard base64 encoding of the codec serialization
+	EncodingBase64 Encoding = "base64"
+	// EncodingProto is the standard base64 encoding of the protobuf SignedTx message.
+	// See ToProto
+	EncodingProto Encoding = "proto"
+)
+
+// ToBase64 returns the standard base64 encoding of the codec serialization of the
+// signed tx
+func (ms *Multisig) ToBase64() (string, error) {
+	txBytes, err := ms.ToBytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(txBytes), nil
+}
+
+// FromBase64 loads a signed tx encoded with ToBase64. Surrounding whitespace is ignored
+func (ms *Multisig) FromBase64(txStr string) error {
+	txBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(txStr))
+	if err != nil {
+		return fmt.Errorf("couldn't decode signed tx: %w", err)
+	}
+	return ms.FromBytes(txBytes)
+}
+
+// ToProto returns the signed tx as a protobuf SignedTx message, tagged with the
+// P-Chain alias. See ProtoSchema
+func (ms *Multisig) ToProto() ([]byte, error) {
+	txBytes, err := ms.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	msg := protowire.AppendTag(nil, protoChainAliasField, protowire.BytesType)
+	msg = protowire.AppendString(msg, PChainAlias)
+	msg = protowire.AppendTag(msg, protoTxField, protowire.BytesType)
+	return protowire.AppendBytes(msg, txBytes), nil
+}
+
+// FromProto loads a signed tx encoded with ToProto. It fails with ErrWrongChainAlias if
+// the message is tagged with a chain other than the P-Chain
+func (ms *Multisig) FromProto(msg []byte) error {
+	var (
+		chainAlias string
+		txBytes    []byte
+	)
+	for len(msg) > 0 {
+		number, wireType, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return fmt.Errorf("couldn't decode signed tx message: %w", protowire.ParseError(n))
+		}
+		msg = msg[n:]
+		switch {
+		case number == protoChainAliasField && wireType == protowire.BytesType:
+			chainAlias, n = protowire.ConsumeString(msg)
+		case number == protoTxField && wireType == protowire.BytesType:
+			txBytes, n = protowire.ConsumeBytes(msg)
+		default:
+			// unknown fields are skipped, as protobuf decoders do
+			n = protowire.ConsumeFieldValue(number, wireType, msg)
+		}
+		if n < 0 {
+			return fmt.Errorf("couldn't decode signed tx message: %w", protowire.ParseError(n))
+		}
+		msg = msg[n:]
+	}
+	if chainAlias != PChainAlias {
+		return fmt.Errorf("%w: expected %q, got %q", ErrWrongChainAlias, PChainAlias, chainAlias)
+	}
+	return ms.FromBytes(txBytes)
+}
+
+// Encode returns the signed tx in [encoding]
+func (ms *Multisig) Encode(encoding Encoding) (string, error) {
+	switch encoding {
+	case EncodingHex:
+		return ms.ToHex()
+	case EncodingBase64:
+		return ms.ToBase64()
+	case EncodingProto:
+		msg, err := ms.ToProto()
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(msg), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownEncoding, encoding)
+}
+
+// Decode loads a signed tx in [encoding]
+func (ms *Multisig) Decode(encoding Encoding, txStr string) error {
+	switch encoding {
+	case EncodingHex:
+		return ms.FromHex(txStr)
+	case EncodingBase64:
+		return ms.FromBase64(txStr)
+	case EncodingProto:
+		msg, err := base64.StdEncoding.DecodeString(strings.TrimSpace(txStr))
+		if err != nil {
+			return fmt.Errorf("couldn't decode signed tx message: %w", err)
+		}
+		return ms.FromProto(msg)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownEncoding, encoding)
+}
+
+// ToTagged returns the signed tx in [encoding], prefixed with the P-Chain alias and the
+// encoding, eg. "P:base64:AAAAAAAO...", so it is self describing when moved through
+// message queues or APIs
+func (ms *Multisig) ToTagged(encoding Encoding) (string, error) {
+	txStr, err := ms.Encode(encoding)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{PChainAlias, string(encoding), txStr}, ":"), nil
+}
+
+// FromTagged loads a signed tx encoded with ToTagged. It fails with ErrWrongChainAlias
+// if the payload is tagged with a chain other than the P-Chain
+func (ms *Multisig) FromTagged(tagged string) error {
+	parts := strings.SplitN(strings.TrimSpace(tagged), ":", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: expected <chain alias>:<encoding>:<tx>", ErrUnknownEncoding)
+	}
+	if parts[0] != PChainAlias {
+		return fmt.Errorf("%w: expected %q, got %q", ErrWrongChainAlias, PChainAlias, parts[0])
+	}
+	return ms.Decode(Encoding(parts[1]), parts[2])
+}
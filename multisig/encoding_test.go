@@ -0,0 +1,54 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestEncodings(t *testing.T) {
+	require := require.New(t)
+	tx := newTestTx(t)
+	ms := New(tx)
+
+	for _, encoding := range []Encoding{EncodingHex, EncodingBase64, EncodingProto} {
+		txStr, err := ms.Encode(encoding)
+		require.NoError(err)
+		decoded := &Multisig{}
+		require.NoError(decoded.Decode(encoding, txStr+"\n"))
+		require.Equal(tx.ID(), decoded.PChainTx.ID())
+		require.Equal(tx.Creds, decoded.PChainTx.Creds)
+
+		tagged, err := ms.ToTagged(encoding)
+		require.NoError(err)
+		require.True(strings.HasPrefix(tagged, "P:"+string(encoding)+":"))
+		decoded = &Multisig{}
+		require.NoError(decoded.FromTagged(tagged))
+		require.Equal(tx.ID(), decoded.PChainTx.ID())
+		require.ErrorIs(decoded.FromTagged("X"+tagged[1:]), ErrWrongChainAlias)
+	}
+	_, err := ms.Encode("base58")
+	require.ErrorIs(err, ErrUnknownEncoding)
+	require.ErrorIs(ms.FromTagged("P:base64"), ErrUnknownEncoding)
+	_, err = New(nil).ToBase64()
+	require.ErrorIs(err, ErrUndefinedTx)
+
+	// protobuf decoders skip unknown fields, and the chain alias is checked
+	txBytes, err := ms.ToBytes()
+	require.NoError(err)
+	msg := protowire.AppendTag(nil, 7, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, 42)
+	msg = protowire.AppendTag(msg, protoTxField, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, txBytes)
+	require.ErrorIs(New(nil).FromProto(msg), ErrWrongChainAlias)
+	msg = protowire.AppendTag(msg, protoChainAliasField, protowire.BytesType)
+	msg = protowire.AppendString(msg, PChainAlias)
+	decoded := &Multisig{}
+	require.NoError(decoded.FromProto(msg))
+	require.Equal(tx.ID(), decoded.PChainTx.ID())
+	require.Error(decoded.FromProto([]byte{0x0a, 0x05}))
+}
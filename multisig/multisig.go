@@ -3,16 +3,18 @@
 package multisig
 
 import (
-	"context"
 	"fmt"
 	"os"
 
 	"github.com/ava-labs/avalanchego/vms/platformvm"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
-	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -31,12 +33,67 @@ const (
 	PChainTransformSubnetTx
 	PChainAddPermissionlessValidatorTx
 	PChainTransferSubnetOwnershipTx
+	PChainCreateSubnetTx
+	PChainAddPermissionlessDelegatorTx
+	PChainAddValidatorTx
+	PChainAddDelegatorTx
+	PChainBaseTx
+	PChainImportTx
+	PChainExportTx
 )
 
+func (kind TxKind) String() string {
+	switch kind {
+	case PChainRemoveSubnetValidatorTx:
+		return "RemoveSubnetValidatorTx"
+	case PChainAddSubnetValidatorTx:
+		return "AddSubnetValidatorTx"
+	case PChainCreateChainTx:
+		return "CreateChainTx"
+	case PChainTransformSubnetTx:
+		return "TransformSubnetTx"
+	case PChainAddPermissionlessValidatorTx:
+		return "AddPermissionlessValidatorTx"
+	case PChainTransferSubnetOwnershipTx:
+		return "TransferSubnetOwnershipTx"
+	case PChainCreateSubnetTx:
+		return "CreateSubnetTx"
+	case PChainAddPermissionlessDelegatorTx:
+		return "AddPermissionlessDelegatorTx"
+	case PChainAddValidatorTx:
+		return "AddValidatorTx"
+	case PChainAddDelegatorTx:
+		return "AddDelegatorTx"
+	case PChainBaseTx:
+		return "BaseTx"
+	case PChainImportTx:
+		return "ImportTx"
+	case PChainExportTx:
+		return "ExportTx"
+	}
+	return "Undefined"
+}
+
+// RequiresSubnetAuth indicates if txs of the given kind must be signed by
+// the subnet control keys, on top of the signatures for the inputs
+func (kind TxKind) RequiresSubnetAuth() bool {
+	switch kind {
+	case PChainRemoveSubnetValidatorTx,
+		PChainAddSubnetValidatorTx,
+		PChainCreateChainTx,
+		PChainTransformSubnetTx,
+		PChainTransferSubnetOwnershipTx:
+		return true
+	default:
+		return false
+	}
+}
+
 type Multisig struct {
 	PChainTx    *txs.Tx
 	controlKeys []ids.ShortID
 	threshold   uint32
+	signers     []keychain.SignerMetadata
 }
 
 func New(pChainTx *txs.Tx) *Multisig {
@@ -72,14 +129,10 @@ func (ms *Multisig) ToFile(txPath string) error {
 	if ms.Undefined() {
 		return ErrUndefinedTx
 	}
-	txBytes, err := ms.ToBytes()
+	txStr, err := ms.ToHex()
 	if err != nil {
 		return err
 	}
-	txStr, err := formatting.Encode(formatting.Hex, txBytes)
-	if err != nil {
-		return fmt.Errorf("couldn't encode signed tx: %w", err)
-	}
 	f, err := os.Create(txPath)
 	if err != nil {
 		return fmt.Errorf("couldn't create file to write tx to: %w", err)
@@ -109,22 +162,27 @@ func (ms *Multisig) FromFile(txPath string) error {
 	if err != nil {
 		return err
 	}
-	txBytes, err := formatting.Decode(formatting.Hex, string(txEncodedBytes))
-	if err != nil {
-		return fmt.Errorf("couldn't decode signed tx: %w", err)
-	}
-	return ms.FromBytes(txBytes)
+	return ms.FromHex(string(txEncodedBytes))
 }
 
 func (ms *Multisig) IsReadyToCommit() (bool, error) {
 	if ms.Undefined() {
 		return false, ErrUndefinedTx
 	}
-	unsignedTx := ms.PChainTx.Unsigned
-	switch unsignedTx.(type) {
-	case *txs.CreateSubnetTx:
-		return true, nil
-	default:
+	kind, err := ms.GetTxKind()
+	if err != nil {
+		return false, err
+	}
+	if !kind.RequiresSubnetAuth() {
+		ins, err := getSignedInputs(ms.PChainTx.Unsigned)
+		if err != nil {
+			return false, err
+		}
+		// a tx not yet signed by any wallet has no creds
+		if len(ms.PChainTx.Creds) < len(ins) {
+			return false, nil
+		}
+		return ms.credsFullySigned()
 	}
 	_, remainingSigners, err := ms.GetRemainingAuthSigners()
 	if err != nil {
@@ -133,6 +191,23 @@ func (ms *Multisig) IsReadyToCommit() (bool, error) {
 	return len(remainingSigners) == 0, nil
 }
 
+// credsFullySigned indicates if all the signatures of all the creds in the tx are filled
+func (ms *Multisig) credsFullySigned() (bool, error) {
+	emptySig := [secp256k1.SignatureLen]byte{}
+	for credIndex := range ms.PChainTx.Creds {
+		cred, ok := ms.PChainTx.Creds[credIndex].(*secp256k1fx.Credential)
+		if !ok {
+			return false, fmt.Errorf("expected cred to be of type *secp256k1fx.Credential, got %T", ms.PChainTx.Creds[credIndex])
+		}
+		for _, sig := range cred.Sigs {
+			if sig == emptySig {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
 // GetRemainingAuthSigners gets subnet auth addresses that have not signed a given tx
 //   - get the string slice of auth signers for the tx (GetAuthSigners)
 //   - verifies that all creds in tx.Creds, except the last one, are fully signed
@@ -231,8 +306,117 @@ func (ms *Multisig) GetAuthSigners() ([]ids.ShortID, error) {
 	return authSigners, nil
 }
 
-func (*Multisig) GetSpendSigners() ([]ids.ShortID, error) {
-	return nil, fmt.Errorf("not implemented yet")
+// GetSpendSigners gets all addresses that are required to sign the inputs of a given tx
+//   - for each input in the tx, gets the output owners of the consumed UTXO, by querying
+//     the P-Chain API for the tx that produced it (GetUTXOOwners)
+//   - creates the slice of required spend addresses by applying the input sig indices
+//     to the owner addresses
+//
+// addresses are returned in input order, and may be repeated if several inputs
+// are owned by the same address. For X-Chain txs, see GetXChainSpendSigners
+func (ms *Multisig) GetSpendSigners() ([]ids.ShortID, error) {
+	spendSigners, err := ms.getSpendSignersByInput(nil)
+	if err != nil {
+		return nil, err
+	}
+	return utils.AppendSlices(spendSigners...), nil
+}
+
+// GetRemainingSpendSigners gets spend addresses that have not signed a given tx
+//   - get the spend signers for each of the tx inputs (GetSpendSigners)
+//   - for each input, iterates the associated cred in tx.Creds
+//   - for each sig in cred.Sig: if sig is empty, then add the associated spend signer address
+//     to the remaining signers list
+//
+// if the tx inputs are fully signed, returns empty slice. Imported inputs are not
+// checked, as their owners are not on the P-Chain. See RemainingSpendSigners
+func (ms *Multisig) GetRemainingSpendSigners() ([]ids.ShortID, []ids.ShortID, error) {
+	spendSigners, err := ms.getSpendSignersByInput(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	remainingSigners, err := remainingSpendSigners(ms.PChainTx.Creds, spendSigners)
+	if err != nil {
+		return nil, nil, err
+	}
+	return utils.AppendSlices(spendSigners...), remainingSigners, nil
+}
+
+// getSpendSignersByInput returns, for each input of the tx, the addresses required to sign it.
+// The consumed UTXOs are queried with [pClient], or with a client for the tx network if nil
+func (ms *Multisig) getSpendSignersByInput(pClient txGetter) ([][]ids.ShortID, error) {
+	if ms.Undefined() {
+		return nil, ErrUndefinedTx
+	}
+	ins, err := GetInputs(ms.PChainTx.Unsigned)
+	if err != nil {
+		return nil, err
+	}
+	if len(ins) == 0 {
+		return nil, nil
+	}
+	if pClient == nil {
+		network, err := ms.GetNetwork()
+		if err != nil {
+			return nil, err
+		}
+		pClient = platformvm.NewClient(network.Endpoint)
+	}
+	utxoOwners, err := fetchUTXOOwners(ins, func(utxoID avax.UTXOID) (*secp256k1fx.OutputOwners, error) {
+		return pChainUTXOOwners(pClient, utxoID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spendSignersByInput(ins, utxoOwners)
+}
+
+// GetUTXOOwners gets the output owners of the P-Chain UTXO [utxoID], by querying the
+// tx that produced it
+func GetUTXOOwners(network avalanche.Network, utxoID avax.UTXOID) (*secp256k1fx.OutputOwners, error) {
+	return pChainUTXOOwners(platformvm.NewClient(network.Endpoint), utxoID)
+}
+
+func pChainUTXOOwners(pClient txGetter, utxoID avax.UTXOID) (*secp256k1fx.OutputOwners, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	txBytes, err := pClient.GetTx(ctx, utxoID.TxID)
+	if err != nil {
+		return nil, fmt.Errorf("tx %s query error: %w", utxoID.TxID, err)
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx %s: %w", utxoID.TxID, err)
+	}
+	outs := tx.Unsigned.Outputs()
+	// staked outputs are indexed after the regular ones
+	if stakerTx, ok := tx.Unsigned.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		outs = append(outs, stakerTx.Stake()...)
+	}
+	if utxoID.OutputIndex >= uint32(len(outs)) {
+		return nil, fmt.Errorf("output index %d not found on tx %s", utxoID.OutputIndex, utxoID.TxID)
+	}
+	out := outs[utxoID.OutputIndex].Out
+	if lockOut, ok := out.(*stakeable.LockOut); ok {
+		out = lockOut.TransferableOut
+	}
+	transferOutput, ok := out.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return nil, fmt.Errorf("expected output of type *secp256k1fx.TransferOutput, got %T", out)
+	}
+	return &transferOutput.OutputOwners, nil
+}
+
+// GetInputs returns the inputs consumed by [unsignedTx] from the P-Chain UTXO set
+// (imported inputs are not included, as they come from shared memory)
+func GetInputs(unsignedTx txs.UnsignedTx) ([]*avax.TransferableInput, error) {
+	baseTx, err := GetBaseTx(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	return baseTx.Ins, nil
 }
 
 func (ms *Multisig) GetTxKind() (TxKind, error) {
@@ -253,6 +437,20 @@ func (ms *Multisig) GetTxKind() (TxKind, error) {
 		return PChainAddPermissionlessValidatorTx, nil
 	case *txs.TransferSubnetOwnershipTx:
 		return PChainTransferSubnetOwnershipTx, nil
+	case *txs.CreateSubnetTx:
+		return PChainCreateSubnetTx, nil
+	case *txs.AddPermissionlessDelegatorTx:
+		return PChainAddPermissionlessDelegatorTx, nil
+	case *txs.AddValidatorTx:
+		return PChainAddValidatorTx, nil
+	case *txs.AddDelegatorTx:
+		return PChainAddDelegatorTx, nil
+	case *txs.BaseTx:
+		return PChainBaseTx, nil
+	case *txs.ImportTx:
+		return PChainImportTx, nil
+	case *txs.ExportTx:
+		return PChainExportTx, nil
 	default:
 		return Undefined, fmt.Errorf("unexpected unsigned tx type %T", unsignedTx)
 	}
@@ -263,25 +461,11 @@ func (ms *Multisig) GetNetworkID() (uint32, error) {
 	if ms.Undefined() {
 		return 0, ErrUndefinedTx
 	}
-	unsignedTx := ms.PChainTx.Unsigned
-	var networkID uint32
-	switch unsignedTx := unsignedTx.(type) {
-	case *txs.RemoveSubnetValidatorTx:
-		networkID = unsignedTx.NetworkID
-	case *txs.AddSubnetValidatorTx:
-		networkID = unsignedTx.NetworkID
-	case *txs.CreateChainTx:
-		networkID = unsignedTx.NetworkID
-	case *txs.TransformSubnetTx:
-		networkID = unsignedTx.NetworkID
-	case *txs.AddPermissionlessValidatorTx:
-		networkID = unsignedTx.NetworkID
-	case *txs.TransferSubnetOwnershipTx:
-		networkID = unsignedTx.NetworkID
-	default:
-		return 0, fmt.Errorf("unexpected unsigned tx type %T", unsignedTx)
+	baseTx, err := GetBaseTx(ms.PChainTx.Unsigned)
+	if err != nil {
+		return 0, err
 	}
-	return networkID, nil
+	return baseTx.NetworkID, nil
 }
 
 // get network model associated to tx
@@ -304,25 +488,27 @@ func (ms *Multisig) GetBlockchainID() (ids.ID, error) {
 	if ms.Undefined() {
 		return ids.Empty, ErrUndefinedTx
 	}
-	unsignedTx := ms.PChainTx.Unsigned
-	var blockchainID ids.ID
-	switch unsignedTx := unsignedTx.(type) {
-	case *txs.RemoveSubnetValidatorTx:
-		blockchainID = unsignedTx.BlockchainID
-	case *txs.AddSubnetValidatorTx:
-		blockchainID = unsignedTx.BlockchainID
-	case *txs.CreateChainTx:
-		blockchainID = unsignedTx.BlockchainID
-	case *txs.TransformSubnetTx:
-		blockchainID = unsignedTx.BlockchainID
-	case *txs.AddPermissionlessValidatorTx:
-		blockchainID = unsignedTx.BlockchainID
-	case *txs.TransferSubnetOwnershipTx:
-		blockchainID = unsignedTx.BlockchainID
+	baseTx, err := GetBaseTx(ms.PChainTx.Unsigned)
+	if err != nil {
+		return ids.Empty, err
+	}
+	return baseTx.BlockchainID, nil
+}
+
+// GetCrossChainID gets the other chain of a cross-chain transfer tx: the source
+// chain of an ImportTx, or the destination chain of an ExportTx
+func (ms *Multisig) GetCrossChainID() (ids.ID, error) {
+	if ms.Undefined() {
+		return ids.Empty, ErrUndefinedTx
+	}
+	switch unsignedTx := ms.PChainTx.Unsigned.(type) {
+	case *txs.ImportTx:
+		return unsignedTx.SourceChain, nil
+	case *txs.ExportTx:
+		return unsignedTx.DestinationChain, nil
 	default:
 		return ids.Empty, fmt.Errorf("unexpected unsigned tx type %T", unsignedTx)
 	}
-	return blockchainID, nil
 }
 
 // GetSubnetID gets subnet id associated to tx
@@ -343,6 +529,8 @@ func (ms *Multisig) GetSubnetID() (ids.ID, error) {
 		subnetID = unsignedTx.Subnet
 	case *txs.AddPermissionlessValidatorTx:
 		subnetID = unsignedTx.Subnet
+	case *txs.AddPermissionlessDelegatorTx:
+		subnetID = unsignedTx.Subnet
 	case *txs.TransferSubnetOwnershipTx:
 		subnetID = unsignedTx.Subnet
 	default:
@@ -375,18 +563,6 @@ func (ms *Multisig) GetSubnetOwners() ([]ids.ShortID, uint32, error) {
 	return ms.controlKeys, ms.threshold, nil
 }
 
-func GetOwners(network avalanche.Network, subnetID ids.ID) ([]ids.ShortID, uint32, error) {
-	pClient := platformvm.NewClient(network.Endpoint)
-	ctx := context.Background()
-	subnetResponse, err := pClient.GetSubnet(ctx, subnetID)
-	if err != nil {
-		return nil, 0, fmt.Errorf("subnet tx %s query error: %w", subnetID, err)
-	}
-	controlKeys := subnetResponse.ControlKeys
-	threshold := subnetResponse.Threshold
-	return controlKeys, threshold, nil
-}
-
 func (ms *Multisig) GetWrappedPChainTx() (*txs.Tx, error) {
 	if ms.Undefined() {
 		return nil, ErrUndefinedTx
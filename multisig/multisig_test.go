@@ -0,0 +1,139 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxKindRequiresSubnetAuth(t *testing.T) {
+	for kind, expected := range map[TxKind]bool{
+		PChainRemoveSubnetValidatorTx:      true,
+		PChainAddSubnetValidatorTx:         true,
+		PChainCreateChainTx:                true,
+		PChainTransformSubnetTx:            true,
+		PChainTransferSubnetOwnershipTx:    true,
+		PChainAddPermissionlessValidatorTx: false,
+		PChainCreateSubnetTx:               false,
+		PChainBaseTx:                       false,
+		PChainImportTx:                     false,
+		PChainExportTx:                     false,
+	} {
+		require.Equal(t, expected, kind.RequiresSubnetAuth(), "kind %d", kind)
+	}
+}
+
+func TestIsReadyToCommitWithoutSubnetAuth(t *testing.T) {
+	filledSig := [secp256k1.SignatureLen]byte{1}
+	emptySig := [secp256k1.SignatureLen]byte{}
+	for _, unsignedTx := range []txs.UnsignedTx{
+		&txs.BaseTx{},
+		&txs.CreateSubnetTx{},
+		&txs.AddPermissionlessValidatorTx{},
+	} {
+		ms := New(&txs.Tx{
+			Unsigned: unsignedTx,
+			Creds: []verify.Verifiable{
+				&secp256k1fx.Credential{Sigs: [][secp256k1.SignatureLen]byte{filledSig}},
+			},
+		})
+		ready, err := ms.IsReadyToCommit()
+		require.NoError(t, err)
+		require.True(t, ready, "%T", unsignedTx)
+		ms.PChainTx.Creds = append(ms.PChainTx.Creds, &secp256k1fx.Credential{
+			Sigs: [][secp256k1.SignatureLen]byte{filledSig, emptySig},
+		})
+		ready, err = ms.IsReadyToCommit()
+		require.NoError(t, err)
+		require.False(t, ready, "%T", unsignedTx)
+	}
+	_, err := New(nil).IsReadyToCommit()
+	require.ErrorIs(t, err, ErrUndefinedTx)
+}
+
+func newTestTx(t *testing.T) *txs.Tx {
+	tx := &txs.Tx{
+		Unsigned: &txs.BaseTx{
+			BaseTx: avax.BaseTx{
+				NetworkID:    constants.FujiID,
+				BlockchainID: constants.PlatformChainID,
+			},
+		},
+		Creds: []verify.Verifiable{
+			&secp256k1fx.Credential{Sigs: [][secp256k1.SignatureLen]byte{{1}, {}}},
+		},
+	}
+	require.NoError(t, tx.Initialize(txs.Codec))
+	return tx
+}
+
+func TestCLIFileRoundTrip(t *testing.T) {
+	require := require.New(t)
+	tx := newTestTx(t)
+	txPath := filepath.Join(t.TempDir(), "tx.txt")
+
+	require.NoError(New(tx).ToCLIFile(txPath, false))
+	require.ErrorContains(New(tx).ToCLIFile(txPath, false), "file exists")
+	require.NoError(New(tx).ToCLIFile(txPath, true))
+
+	// avalanche-cli reads tx files by hex decoding and unmarshaling them with the P-Chain codec
+	txEncodedBytes, err := os.ReadFile(txPath)
+	require.NoError(err)
+	txBytes, err := formatting.Decode(formatting.Hex, string(txEncodedBytes))
+	require.NoError(err)
+	var cliTx txs.Tx
+	_, err = txs.Codec.Unmarshal(txBytes, &cliTx)
+	require.NoError(err)
+	require.NoError(cliTx.Initialize(txs.Codec))
+	require.Equal(tx.ID(), cliTx.ID())
+
+	// files edited by hand may have a trailing newline
+	require.NoError(os.WriteFile(txPath, append(txEncodedBytes, '\n'), 0o600))
+	ms := &Multisig{}
+	require.NoError(ms.FromCLIFile(txPath))
+	require.Equal(tx.ID(), ms.PChainTx.ID())
+	require.Equal(tx.Creds, ms.PChainTx.Creds)
+}
+
+func TestCrossChainTxIntrospection(t *testing.T) {
+	require := require.New(t)
+	xChainID := ids.GenerateTestID()
+	baseTx := txs.BaseTx{
+		BaseTx: avax.BaseTx{
+			NetworkID:    constants.FujiID,
+			BlockchainID: constants.PlatformChainID,
+		},
+	}
+	for _, unsignedTx := range []txs.UnsignedTx{
+		&txs.ImportTx{BaseTx: baseTx, SourceChain: xChainID},
+		&txs.ExportTx{BaseTx: baseTx, DestinationChain: xChainID},
+	} {
+		ms := New(&txs.Tx{Unsigned: unsignedTx})
+		networkID, err := ms.GetNetworkID()
+		require.NoError(err)
+		require.Equal(constants.FujiID, networkID)
+		network, err := ms.GetNetwork()
+		require.NoError(err)
+		require.Equal(constants.FujiID, network.ID)
+		blockchainID, err := ms.GetBlockchainID()
+		require.NoError(err)
+		require.Equal(constants.PlatformChainID, blockchainID)
+		crossChainID, err := ms.GetCrossChainID()
+		require.NoError(err)
+		require.Equal(xChainID, crossChainID)
+	}
+	_, err := New(&txs.Tx{Unsigned: &baseTx}).GetCrossChainID()
+	require.ErrorContains(err, "unexpected unsigned tx type")
+}
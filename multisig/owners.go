@@ -0,0 +1,190 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+// SubnetOwners are the control keys of a subnet, [Threshold] of which must sign its
+// subnet auth
+type SubnetOwners struct {
+	ControlKeys []ids.ShortID
+	Threshold   uint32
+}
+
+// OwnersCache stores subnet owners by network endpoint and subnet ID, to avoid querying
+// the P-Chain each time they are needed. See SetOwnersCache
+type OwnersCache interface {
+	Get(endpoint string, subnetID ids.ID) (SubnetOwners, bool)
+	Set(endpoint string, subnetID ids.ID, owners SubnetOwners)
+}
+
+// ownersCache is the cache used by GetOwners and GetOwnersBatch. nil disables caching
+var ownersCache struct {
+	lock  sync.RWMutex
+	cache OwnersCache
+}
+
+// SetOwnersCache sets the cache used by GetOwners and GetOwnersBatch, eg. one created
+// with NewOwnersCache. nil, the default, disables caching
+func SetOwnersCache(cache OwnersCache) {
+	ownersCache.lock.Lock()
+	defer ownersCache.lock.Unlock()
+	ownersCache.cache = cache
+}
+
+func getOwnersCache() OwnersCache {
+	ownersCache.lock.RLock()
+	defer ownersCache.lock.RUnlock()
+	return ownersCache.cache
+}
+
+type ttlOwnersCacheEntry struct {
+	owners  SubnetOwners
+	expires time.Time
+}
+
+// ttlOwnersCache is an in memory OwnersCache whose entries expire after a given time,
+// so ownership transfers are eventually seen
+type ttlOwnersCache struct {
+	lock    sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[ids.ID]ttlOwnersCacheEntry
+}
+
+// NewOwnersCache creates an in memory OwnersCache whose entries expire after [ttl].
+// A [ttl] of 0 keeps the entries forever
+func NewOwnersCache(ttl time.Duration) OwnersCache {
+	return &ttlOwnersCache{
+		ttl:     ttl,
+		entries: map[string]map[ids.ID]ttlOwnersCacheEntry{},
+	}
+}
+
+func (c *ttlOwnersCache) Get(endpoint string, subnetID ids.ID) (SubnetOwners, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries[endpoint][subnetID]
+	if !ok {
+		return SubnetOwners{}, false
+	}
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		delete(c.entries[endpoint], subnetID)
+		return SubnetOwners{}, false
+	}
+	return entry.owners, true
+}
+
+func (c *ttlOwnersCache) Set(endpoint string, subnetID ids.ID, owners SubnetOwners) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[endpoint]; !ok {
+		c.entries[endpoint] = map[ids.ID]ttlOwnersCacheEntry{}
+	}
+	c.entries[endpoint][subnetID] = ttlOwnersCacheEntry{
+		owners:  owners,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+type subnetOwnersClient interface {
+	GetSubnet(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (platformvm.GetSubnetClientResponse, error)
+	GetSubnets(ctx context.Context, subnetIDs []ids.ID, options ...rpc.Option) ([]platformvm.ClientSubnet, error)
+}
+
+// GetOwners gets the control keys and threshold of [subnetID], from the owners cache
+// if set
+func GetOwners(network avalanche.Network, subnetID ids.ID) ([]ids.ShortID, uint32, error) {
+	owners, err := GetOwnersBatch(network, []ids.ID{subnetID})
+	if err != nil {
+		return nil, 0, err
+	}
+	return owners[subnetID].ControlKeys, owners[subnetID].Threshold, nil
+}
+
+// GetOwnersBatch gets the owners of all [subnetIDs], querying the P-Chain only for the
+// ones not in the owners cache, with a single request if possible
+func GetOwnersBatch(network avalanche.Network, subnetIDs []ids.ID) (map[ids.ID]SubnetOwners, error) {
+	return getOwnersBatch(platformvm.NewClient(network.Endpoint), getOwnersCache(), network.Endpoint, subnetIDs)
+}
+
+func getOwnersBatch(
+	client subnetOwnersClient,
+	cache OwnersCache,
+	endpoint string,
+	subnetIDs []ids.ID,
+) (map[ids.ID]SubnetOwners, error) {
+	owners := map[ids.ID]SubnetOwners{}
+	missing := []ids.ID{}
+	for _, subnetID := range subnetIDs {
+		if _, ok := owners[subnetID]; ok {
+			continue
+		}
+		if cache != nil {
+			if cached, ok := cache.Get(endpoint, subnetID); ok {
+				owners[subnetID] = cached
+				continue
+			}
+		}
+		missing = append(missing, subnetID)
+	}
+	if len(missing) == 0 {
+		return owners, nil
+	}
+	fetched, err := fetchOwners(client, missing)
+	if err != nil {
+		return nil, err
+	}
+	for subnetID, subnetOwners := range fetched {
+		owners[subnetID] = subnetOwners
+		if cache != nil {
+			cache.Set(endpoint, subnetID, subnetOwners)
+		}
+	}
+	return owners, nil
+}
+
+// fetchOwners uses platform.getSubnets to get all owners at once. As it is deprecated,
+// it falls back to a platform.getSubnet call per subnet if it fails or misses subnets
+func fetchOwners(client subnetOwnersClient, subnetIDs []ids.ID) (map[ids.ID]SubnetOwners, error) {
+	owners := map[ids.ID]SubnetOwners{}
+	if len(subnetIDs) > 1 {
+		ctx, cancel := utils.GetAPIContext()
+		subnets, err := client.GetSubnets(ctx, subnetIDs)
+		cancel()
+		if err == nil {
+			for _, subnet := range subnets {
+				owners[subnet.ID] = SubnetOwners{
+					ControlKeys: subnet.ControlKeys,
+					Threshold:   subnet.Threshold,
+				}
+			}
+		}
+	}
+	for _, subnetID := range subnetIDs {
+		if _, ok := owners[subnetID]; ok {
+			continue
+		}
+		ctx, cancel := utils.GetAPIContext()
+		subnetResponse, err := client.GetSubnet(ctx, subnetID)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("subnet tx %s query error: %w", subnetID, err)
+		}
+		owners[subnetID] = SubnetOwners{
+			ControlKeys: subnetResponse.ControlKeys,
+			Threshold:   subnetResponse.Threshold,
+		}
+	}
+	return owners, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOwnersClient struct {
+	owners          map[ids.ID]SubnetOwners
+	getSubnetsErr   error
+	getSubnetCalls  int
+	getSubnetsCalls int
+}
+
+func (c *fakeOwnersClient) GetSubnet(_ context.Context, subnetID ids.ID, _ ...rpc.Option) (platformvm.GetSubnetClientResponse, error) {
+	c.getSubnetCalls++
+	owners, ok := c.owners[subnetID]
+	if !ok {
+		return platformvm.GetSubnetClientResponse{}, errors.New("not found")
+	}
+	return platformvm.GetSubnetClientResponse{
+		IsPermissioned: true,
+		ControlKeys:    owners.ControlKeys,
+		Threshold:      owners.Threshold,
+	}, nil
+}
+
+func (c *fakeOwnersClient) GetSubnets(_ context.Context, subnetIDs []ids.ID, _ ...rpc.Option) ([]platformvm.ClientSubnet, error) {
+	c.getSubnetsCalls++
+	if c.getSubnetsErr != nil {
+		return nil, c.getSubnetsErr
+	}
+	subnets := []platformvm.ClientSubnet{}
+	for _, subnetID := range subnetIDs {
+		if owners, ok := c.owners[subnetID]; ok {
+			subnets = append(subnets, platformvm.ClientSubnet{
+				ID:          subnetID,
+				ControlKeys: owners.ControlKeys,
+				Threshold:   owners.Threshold,
+			})
+		}
+	}
+	return subnets, nil
+}
+
+func TestGetOwnersBatch(t *testing.T) {
+	require := require.New(t)
+	subnetA := ids.GenerateTestID()
+	subnetB := ids.GenerateTestID()
+	subnetC := ids.GenerateTestID()
+	client := &fakeOwnersClient{owners: map[ids.ID]SubnetOwners{
+		subnetA: {ControlKeys: []ids.ShortID{ids.GenerateTestShortID()}, Threshold: 1},
+		subnetB: {ControlKeys: []ids.ShortID{ids.GenerateTestShortID(), ids.GenerateTestShortID()}, Threshold: 2},
+		subnetC: {ControlKeys: []ids.ShortID{ids.GenerateTestShortID()}, Threshold: 1},
+	}}
+	cache := NewOwnersCache(0)
+
+	owners, err := getOwnersBatch(client, cache, "endpoint", []ids.ID{subnetA, subnetB, subnetA})
+	require.NoError(err)
+	require.Len(owners, 2)
+	require.Equal(client.owners[subnetB], owners[subnetB])
+	require.Equal(1, client.getSubnetsCalls)
+	require.Zero(client.getSubnetCalls)
+
+	// only the subnet not cached is queried
+	owners, err = getOwnersBatch(client, cache, "endpoint", []ids.ID{subnetA, subnetB, subnetC})
+	require.NoError(err)
+	require.Len(owners, 3)
+	require.Equal(1, client.getSubnetsCalls)
+	require.Equal(1, client.getSubnetCalls)
+
+	// caches are per endpoint
+	_, err = getOwnersBatch(client, cache, "other endpoint", []ids.ID{subnetA})
+	require.NoError(err)
+	require.Equal(2, client.getSubnetCalls)
+}
+
+func TestGetOwnersBatchFallback(t *testing.T) {
+	require := require.New(t)
+	subnetA := ids.GenerateTestID()
+	subnetB := ids.GenerateTestID()
+	client := &fakeOwnersClient{
+		owners: map[ids.ID]SubnetOwners{
+			subnetA: {ControlKeys: []ids.ShortID{ids.GenerateTestShortID()}, Threshold: 1},
+			subnetB: {ControlKeys: []ids.ShortID{ids.GenerateTestShortID()}, Threshold: 1},
+		},
+		getSubnetsErr: errors.New("method not found"),
+	}
+	owners, err := getOwnersBatch(client, nil, "endpoint", []ids.ID{subnetA, subnetB})
+	require.NoError(err)
+	require.Len(owners, 2)
+	require.Equal(2, client.getSubnetCalls)
+
+	_, err = getOwnersBatch(client, nil, "endpoint", []ids.ID{ids.GenerateTestID()})
+	require.ErrorContains(err, "query error")
+}
+
+func TestOwnersCacheTTL(t *testing.T) {
+	require := require.New(t)
+	subnetID := ids.GenerateTestID()
+	owners := SubnetOwners{Threshold: 1}
+
+	cache := NewOwnersCache(time.Hour)
+	cache.Set("endpoint", subnetID, owners)
+	cached, ok := cache.Get("endpoint", subnetID)
+	require.True(ok)
+	require.Equal(owners, cached)
+
+	cache = NewOwnersCache(time.Nanosecond)
+	cache.Set("endpoint", subnetID, owners)
+	time.Sleep(time.Millisecond)
+	_, ok = cache.Get("endpoint", subnetID)
+	require.False(ok)
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// SignatureRecord is a signature present in the tx credentials, along with the metadata
+// of the key that produced it, if it was recorded
+type SignatureRecord struct {
+	CredentialIndex int `json:"credentialIndex"`
+	SignatureIndex  int `json:"signatureIndex"`
+	// Address is recovered from the signature
+	Address ids.ShortID              `json:"address"`
+	Signer  *keychain.SignerMetadata `json:"signer,omitempty"`
+}
+
+// Envelope is the file format of a multisig tx that carries the metadata of its signers
+type Envelope struct {
+	// Tx is the tx encoded as in the avalanche-cli tx file format
+	Tx      string                    `json:"tx"`
+	Signers []keychain.SignerMetadata `json:"signers,omitempty"`
+}
+
+// AddSignerMetadata records the metadata of keys that signed the tx. Metadata
+// for an already recorded address replaces the previous one
+func (ms *Multisig) AddSignerMetadata(signers ...keychain.SignerMetadata) {
+	for _, signer := range signers {
+		replaced := false
+		for i := range ms.signers {
+			if ms.signers[i].Address == signer.Address {
+				ms.signers[i] = signer
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			ms.signers = append(ms.signers, signer)
+		}
+	}
+}
+
+// SignerMetadata returns the recorded metadata of the keys that signed the tx
+func (ms *Multisig) SignerMetadata() []keychain.SignerMetadata {
+	return append([]keychain.SignerMetadata{}, ms.signers...)
+}
+
+// Signatures returns the signatures present in the tx credentials, in credential order.
+// The signer address is recovered from each signature, so no network access is needed,
+// and is matched with the recorded signer metadata
+func (ms *Multisig) Signatures() ([]SignatureRecord, error) {
+	if ms.Undefined() {
+		return nil, ErrUndefinedTx
+	}
+	unsignedBytes := ms.PChainTx.Unsigned.Bytes()
+	if len(unsignedBytes) == 0 {
+		return nil, fmt.Errorf("tx is not initialized")
+	}
+	emptySig := [secp256k1.SignatureLen]byte{}
+	records := []SignatureRecord{}
+	for credIndex := range ms.PChainTx.Creds {
+		cred, ok := ms.PChainTx.Creds[credIndex].(*secp256k1fx.Credential)
+		if !ok {
+			return nil, fmt.Errorf("expected cred to be of type *secp256k1fx.Credential, got %T", ms.PChainTx.Creds[credIndex])
+		}
+		for sigIndex, sig := range cred.Sigs {
+			if sig == emptySig {
+				continue
+			}
+			pubKey, err := secp256k1.RecoverPublicKey(unsignedBytes, sig[:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid sig %d of cred %d: %w", sigIndex, credIndex, err)
+			}
+			record := SignatureRecord{
+				CredentialIndex: credIndex,
+				SignatureIndex:  sigIndex,
+				Address:         pubKey.Address(),
+			}
+			for i := range ms.signers {
+				if ms.signers[i].Address == record.Address {
+					signer := ms.signers[i]
+					record.Signer = &signer
+					break
+				}
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// ToEnvelope serializes the tx along with its signer metadata
+func (ms *Multisig) ToEnvelope() ([]byte, error) {
+	txStr, err := ms.ToHex()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(Envelope{
+		Tx:      txStr,
+		Signers: ms.signers,
+	}, "", "  ")
+}
+
+// FromEnvelope loads a tx and its signer metadata serialized with ToEnvelope
+func (ms *Multisig) FromEnvelope(envelopeBytes []byte) error {
+	var envelope Envelope
+	if err := json.Unmarshal(envelopeBytes, &envelope); err != nil {
+		return fmt.Errorf("couldn't decode tx envelope: %w", err)
+	}
+	if err := ms.FromHex(envelope.Tx); err != nil {
+		return err
+	}
+	ms.signers = envelope.Signers
+	return nil
+}
+
+// ToEnvelopeFile saves the tx and its signer metadata to [path]
+func (ms *Multisig) ToEnvelopeFile(path string) error {
+	envelopeBytes, err := ms.ToEnvelope()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, envelopeBytes, 0o600); err != nil {
+		return fmt.Errorf("couldn't write tx envelope into file: %w", err)
+	}
+	return nil
+}
+
+// FromEnvelopeFile loads a tx and its signer metadata saved with ToEnvelopeFile
+func (ms *Multisig) FromEnvelopeFile(path string) error {
+	envelopeBytes, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ms.FromEnvelope(envelopeBytes)
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignaturesAndEnvelope(t *testing.T) {
+	require := require.New(t)
+	keyA, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	keyB, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	tx := &txs.Tx{Unsigned: &txs.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    constants.FujiID,
+		BlockchainID: constants.PlatformChainID,
+		Memo:         []byte{1},
+	}}}
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keyA}, {keyB}}))
+	ms := New(tx)
+
+	metadataA := keychain.SignerMetadata{
+		Address:        keyA.Address(),
+		DerivationPath: "m/44'/9000'/0'/0/3",
+		DeviceSerial:   "0001",
+	}
+	ms.AddSignerMetadata(keychain.SignerMetadata{Address: keyA.Address()}, metadataA)
+	require.Len(ms.SignerMetadata(), 1)
+
+	signatures, err := ms.Signatures()
+	require.NoError(err)
+	require.Len(signatures, 2)
+	require.Equal(keyA.Address(), signatures[0].Address)
+	require.Equal(&metadataA, signatures[0].Signer)
+	require.Equal(1, signatures[1].CredentialIndex)
+	require.Equal(keyB.Address(), signatures[1].Address)
+	require.Nil(signatures[1].Signer)
+
+	path := filepath.Join(t.TempDir(), "tx.json")
+	require.NoError(ms.ToEnvelopeFile(path))
+	loaded := New(nil)
+	require.NoError(loaded.FromEnvelopeFile(path))
+	require.Equal(ms.PChainTx.ID(), loaded.PChainTx.ID())
+	require.Equal([]keychain.SignerMetadata{metadataA}, loaded.SignerMetadata())
+
+	_, err = New(nil).Signatures()
+	require.ErrorIs(err, ErrUndefinedTx)
+}
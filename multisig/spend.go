@@ -0,0 +1,133 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// RemainingSpendSigners gets the addresses required to sign the inputs of [tx], and the
+// ones among them that have not signed yet, given the owners of the UTXOs it consumes
+// by UTXO ID (avax.UTXOID.InputID()). Unlike GetRemainingSpendSigners, no network
+// access is needed, and the imported inputs of an ImportTx are included, so it covers
+// txs spending UTXOs whose owners have a threshold greater than 1, eg. a BaseTx or
+// ExportTx funded by a multisig address.
+//
+// Addresses are returned in credential order, and may be repeated if several inputs
+// are owned by the same address
+func RemainingSpendSigners(
+	tx *txs.Tx,
+	utxoOwners map[ids.ID]*secp256k1fx.OutputOwners,
+) ([]ids.ShortID, []ids.ShortID, error) {
+	if tx == nil || tx.Unsigned == nil {
+		return nil, nil, ErrUndefinedTx
+	}
+	ins, err := getSignedInputs(tx.Unsigned)
+	if err != nil {
+		return nil, nil, err
+	}
+	spendSigners, err := spendSignersByInput(ins, utxoOwners)
+	if err != nil {
+		return nil, nil, err
+	}
+	remainingSigners, err := remainingSpendSigners(tx.Creds, spendSigners)
+	if err != nil {
+		return nil, nil, err
+	}
+	return utils.AppendSlices(spendSigners...), remainingSigners, nil
+}
+
+// txGetter gets txs from a chain API. It is implemented by the P-Chain and X-Chain clients
+type txGetter interface {
+	GetTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error)
+}
+
+// fetchUTXOOwners returns the owners of the UTXOs consumed by [ins], by UTXO ID,
+// as given by [getOwners]
+func fetchUTXOOwners(
+	ins []*avax.TransferableInput,
+	getOwners func(avax.UTXOID) (*secp256k1fx.OutputOwners, error),
+) (map[ids.ID]*secp256k1fx.OutputOwners, error) {
+	utxoOwners := map[ids.ID]*secp256k1fx.OutputOwners{}
+	for _, in := range ins {
+		owners, err := getOwners(in.UTXOID)
+		if err != nil {
+			return nil, err
+		}
+		utxoOwners[in.InputID()] = owners
+	}
+	return utxoOwners, nil
+}
+
+// spendSignersByInput returns, for each of [ins], the addresses of its UTXO owners
+// selected by its sig indices
+func spendSignersByInput(
+	ins []*avax.TransferableInput,
+	utxoOwners map[ids.ID]*secp256k1fx.OutputOwners,
+) ([][]ids.ShortID, error) {
+	spendSigners := [][]ids.ShortID{}
+	for i, in := range ins {
+		owners, ok := utxoOwners[in.InputID()]
+		if !ok || owners == nil {
+			return nil, fmt.Errorf("%w: input %d consumes utxo %s", ErrMissingOwners, i, in.InputID())
+		}
+		input := in.In
+		if lockIn, ok := input.(*stakeable.LockIn); ok {
+			input = lockIn.TransferableIn
+		}
+		transferInput, ok := input.(*secp256k1fx.TransferInput)
+		if !ok {
+			return nil, fmt.Errorf("expected input of type *secp256k1fx.TransferInput, got %T", input)
+		}
+		inputSigners := []ids.ShortID{}
+		for _, sigIndex := range transferInput.SigIndices {
+			if sigIndex >= uint32(len(owners.Addrs)) {
+				return nil, fmt.Errorf("signer index %d exceeds number of owners of utxo %s", sigIndex, in.InputID())
+			}
+			inputSigners = append(inputSigners, owners.Addrs[sigIndex])
+		}
+		spendSigners = append(spendSigners, inputSigners)
+	}
+	return spendSigners, nil
+}
+
+// remainingSpendSigners returns the addresses of [spendSigners] whose signature is
+// empty on the associated cred of [creds]. Inputs without a cred yet are fully remaining
+func remainingSpendSigners(creds []verify.Verifiable, spendSigners [][]ids.ShortID) ([]ids.ShortID, error) {
+	emptySig := [secp256k1.SignatureLen]byte{}
+	remainingSigners := []ids.ShortID{}
+	for credIndex, inputSigners := range spendSigners {
+		if credIndex >= len(creds) {
+			remainingSigners = append(remainingSigners, inputSigners...)
+			continue
+		}
+		cred, ok := creds[credIndex].(*secp256k1fx.Credential)
+		if !ok {
+			return nil, fmt.Errorf("expected cred to be of type *secp256k1fx.Credential, got %T", creds[credIndex])
+		}
+		if len(cred.Sigs) != len(inputSigners) {
+			return nil, fmt.Errorf("expected number of signatures %d of cred %d to equal number of spend signers %d",
+				len(cred.Sigs),
+				credIndex,
+				len(inputSigners),
+			)
+		}
+		for i, sig := range cred.Sigs {
+			if sig == emptySig {
+				remainingSigners = append(remainingSigners, inputSigners[i])
+			}
+		}
+	}
+	return remainingSigners, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoutils "github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/vms/avm/fxs"
+	avmtxs "github.com/ava-labs/avalanchego/vms/avm/txs"
+	xbuilder "github.com/ava-labs/avalanchego/wallet/chain/x/builder"
+)
+
+// fakeTxGetter serves the bytes of fixture txs by ID
+type fakeTxGetter map[ids.ID][]byte
+
+func (g fakeTxGetter) GetTx(_ context.Context, txID ids.ID, _ ...rpc.Option) ([]byte, error) {
+	txBytes, ok := g[txID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return txBytes, nil
+}
+
+func newSpendTestAddrs(n int) []ids.ShortID {
+	addrs := make([]ids.ShortID, n)
+	for i := range addrs {
+		addrs[i] = ids.GenerateTestShortID()
+	}
+	// owners addresses are sorted
+	avagoutils.Sort(addrs)
+	return addrs
+}
+
+func newSpendTestOut(assetID ids.ID, owners secp256k1fx.OutputOwners) *avax.TransferableOutput {
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: assetID},
+		Out:   &secp256k1fx.TransferOutput{Amt: 1_000, OutputOwners: owners},
+	}
+}
+
+// partiallySigned returns a cred with a non empty signature for the signers set in [signed]
+func partiallySigned(signed ...bool) *secp256k1fx.Credential {
+	cred := &secp256k1fx.Credential{Sigs: make([][secp256k1.SignatureLen]byte, len(signed))}
+	for i := range signed {
+		if signed[i] {
+			cred.Sigs[i][0] = 1
+		}
+	}
+	return cred
+}
+
+func TestPChainSpendSigners(t *testing.T) {
+	require := require.New(t)
+	addrs := newSpendTestAddrs(4)
+	assetID := ids.GenerateTestID()
+	multisigOwners := secp256k1fx.OutputOwners{Threshold: 2, Addrs: addrs[:3]}
+	singleOwner := secp256k1fx.OutputOwners{Threshold: 1, Addrs: addrs[3:]}
+	producerTx := &txs.Tx{Unsigned: &txs.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    constants.FujiID,
+		BlockchainID: constants.PlatformChainID,
+		Outs: []*avax.TransferableOutput{
+			newSpendTestOut(assetID, singleOwner),
+			newSpendTestOut(assetID, multisigOwners),
+		},
+	}}}
+	require.NoError(producerTx.Initialize(txs.Codec))
+	pClient := fakeTxGetter{producerTx.ID(): producerTx.Bytes()}
+
+	tx := &txs.Tx{Unsigned: &txs.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    constants.FujiID,
+		BlockchainID: constants.PlatformChainID,
+		Ins: []*avax.TransferableInput{
+			newSpendTestIn(avax.UTXOID{TxID: producerTx.ID(), OutputIndex: 1}, 0, 2),
+			newSpendTestIn(avax.UTXOID{TxID: producerTx.ID(), OutputIndex: 0}, 0),
+		},
+	}}}
+	require.NoError(tx.Initialize(txs.Codec))
+	tx.Creds = []verify.Verifiable{partiallySigned(true, false), partiallySigned(false)}
+	ms := New(tx)
+
+	spendSignersByInput, err := ms.getSpendSignersByInput(pClient)
+	require.NoError(err)
+	require.Equal([][]ids.ShortID{{addrs[0], addrs[2]}, {addrs[3]}}, spendSignersByInput)
+	remaining, err := remainingSpendSigners(tx.Creds, spendSignersByInput)
+	require.NoError(err)
+	require.Equal([]ids.ShortID{addrs[2], addrs[3]}, remaining)
+
+	owners, err := pChainUTXOOwners(pClient, avax.UTXOID{TxID: producerTx.ID(), OutputIndex: 1})
+	require.NoError(err)
+	require.Equal(multisigOwners, *owners)
+	_, err = pChainUTXOOwners(pClient, avax.UTXOID{TxID: producerTx.ID(), OutputIndex: 2})
+	require.ErrorContains(err, "output index 2 not found")
+	_, err = pChainUTXOOwners(pClient, avax.UTXOID{TxID: ids.GenerateTestID()})
+	require.Error(err)
+}
+
+func TestXChainSpendSigners(t *testing.T) {
+	require := require.New(t)
+	codec := xbuilder.Parser.Codec()
+	addrs := newSpendTestAddrs(4)
+	avaxAssetID := ids.GenerateTestID()
+	xChainID := ids.GenerateTestID()
+	multisigOwners := secp256k1fx.OutputOwners{Threshold: 2, Addrs: addrs[:3]}
+	singleOwner := secp256k1fx.OutputOwners{Threshold: 1, Addrs: addrs[3:]}
+	baseTx := func(ins []*avax.TransferableInput, outs ...*avax.TransferableOutput) avmtxs.BaseTx {
+		return avmtxs.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    constants.FujiID,
+			BlockchainID: xChainID,
+			Ins:          ins,
+			Outs:         outs,
+		}}
+	}
+	// the minted UTXO of a new asset is indexed after the regular outputs
+	createAssetTx := &avmtxs.Tx{Unsigned: &avmtxs.CreateAssetTx{
+		BaseTx:       baseTx(nil, newSpendTestOut(avaxAssetID, singleOwner)),
+		Name:         "token",
+		Symbol:       "TKN",
+		Denomination: 0,
+		States: []*avmtxs.InitialState{{
+			FxIndex: 0,
+			Outs: []verify.State{
+				&secp256k1fx.TransferOutput{Amt: 1_000, OutputOwners: multisigOwners},
+			},
+		}},
+	}}
+	require.NoError(createAssetTx.Initialize(codec))
+	xClient := fakeTxGetter{createAssetTx.ID(): createAssetTx.Bytes()}
+
+	owners, err := xChainUTXOOwners(xClient, avax.UTXOID{TxID: createAssetTx.ID(), OutputIndex: 1})
+	require.NoError(err)
+	require.Equal(multisigOwners, *owners)
+	_, err = xChainUTXOOwners(xClient, avax.UTXOID{TxID: createAssetTx.ID(), OutputIndex: 2})
+	require.ErrorContains(err, "output index 2 not found")
+
+	importedUTXO := avax.UTXOID{TxID: ids.GenerateTestID()}
+	tx := &avmtxs.Tx{Unsigned: &avmtxs.ImportTx{
+		BaseTx: baseTx([]*avax.TransferableInput{
+			newSpendTestIn(avax.UTXOID{TxID: createAssetTx.ID(), OutputIndex: 1}, 1, 2),
+			newSpendTestIn(avax.UTXOID{TxID: createAssetTx.ID(), OutputIndex: 0}, 0),
+		}),
+		SourceChain: constants.PlatformChainID,
+		ImportedIns: []*avax.TransferableInput{newSpendTestIn(importedUTXO, 0)},
+	}}
+	require.NoError(tx.Initialize(codec))
+	tx.Creds = []*fxs.FxCredential{
+		{Credential: partiallySigned(false, true)},
+		{Credential: partiallySigned(true)},
+		{Credential: partiallySigned(false)},
+	}
+
+	// from the network, imported inputs are not included
+	spendSignersByInput, err := xChainSpendSignersByInput(xClient, tx)
+	require.NoError(err)
+	require.Equal([][]ids.ShortID{{addrs[1], addrs[2]}, {addrs[3]}}, spendSignersByInput)
+	remaining, err := remainingSpendSigners(xChainCreds(tx), spendSignersByInput)
+	require.NoError(err)
+	require.Equal([]ids.ShortID{addrs[1]}, remaining)
+
+	// offline, with the owners of all the consumed UTXOs
+	importedOwner := ids.GenerateTestShortID()
+	utxoOwners := map[ids.ID]*secp256k1fx.OutputOwners{
+		tx.Unsigned.(*avmtxs.ImportTx).Ins[0].InputID(): &multisigOwners,
+		tx.Unsigned.(*avmtxs.ImportTx).Ins[1].InputID(): &singleOwner,
+		importedUTXO.InputID():                          {Threshold: 1, Addrs: []ids.ShortID{importedOwner}},
+	}
+	spendSigners, remaining, err := XChainRemainingSpendSigners(tx, utxoOwners)
+	require.NoError(err)
+	require.Equal([]ids.ShortID{addrs[1], addrs[2], addrs[3], importedOwner}, spendSigners)
+	require.Equal([]ids.ShortID{addrs[1], importedOwner}, remaining)
+
+	_, _, err = XChainRemainingSpendSigners(&avmtxs.Tx{}, utxoOwners)
+	require.ErrorIs(err, ErrUndefinedTx)
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func newSpendTestIn(utxoID avax.UTXOID, sigIndices ...uint32) *avax.TransferableInput {
+	return &avax.TransferableInput{
+		UTXOID: utxoID,
+		Asset:  avax.Asset{ID: ids.GenerateTestID()},
+		In: &secp256k1fx.TransferInput{
+			Amt:   1,
+			Input: secp256k1fx.Input{SigIndices: sigIndices},
+		},
+	}
+}
+
+func TestRemainingSpendSigners(t *testing.T) {
+	require := require.New(t)
+	keys := make([]*secp256k1.PrivateKey, 3)
+	for i := range keys {
+		key, err := secp256k1.NewPrivateKey()
+		require.NoError(err)
+		keys[i] = key
+	}
+	multisigUTXO := avax.UTXOID{TxID: ids.GenerateTestID()}
+	importedUTXO := avax.UTXOID{TxID: ids.GenerateTestID()}
+	utxoOwners := map[ids.ID]*secp256k1fx.OutputOwners{
+		multisigUTXO.InputID(): {Threshold: 2, Addrs: []ids.ShortID{keys[0].Address(), keys[1].Address()}},
+		importedUTXO.InputID(): {Threshold: 1, Addrs: []ids.ShortID{keys[2].Address()}},
+	}
+	tx := &txs.Tx{Unsigned: &txs.ImportTx{
+		BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    constants.FujiID,
+			BlockchainID: constants.PlatformChainID,
+			Ins:          []*avax.TransferableInput{newSpendTestIn(multisigUTXO, 0, 1)},
+		}},
+		SourceChain:    ids.GenerateTestID(),
+		ImportedInputs: []*avax.TransferableInput{newSpendTestIn(importedUTXO, 0)},
+	}}
+	require.NoError(tx.Initialize(txs.Codec))
+
+	// not signed yet
+	spendSigners, remainingSigners, err := RemainingSpendSigners(tx, utxoOwners)
+	require.NoError(err)
+	expected := []ids.ShortID{keys[0].Address(), keys[1].Address(), keys[2].Address()}
+	require.Equal(expected, spendSigners)
+	require.Equal(expected, remainingSigners)
+
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keys[0], keys[1]}, {keys[2]}}))
+	tx.Creds[0].(*secp256k1fx.Credential).Sigs[1] = [secp256k1.SignatureLen]byte{}
+	_, remainingSigners, err = RemainingSpendSigners(tx, utxoOwners)
+	require.NoError(err)
+	require.Equal([]ids.ShortID{keys[1].Address()}, remainingSigners)
+
+	delete(utxoOwners, importedUTXO.InputID())
+	_, _, err = RemainingSpendSigners(tx, utxoOwners)
+	require.ErrorIs(err, ErrMissingOwners)
+	_, _, err = RemainingSpendSigners(nil, utxoOwners)
+	require.ErrorIs(err, ErrUndefinedTx)
+
+	// inputs without creds are not ready to commit
+	ready, err := New(&txs.Tx{Unsigned: tx.Unsigned}).IsReadyToCommit()
+	require.NoError(err)
+	require.False(ready)
+}
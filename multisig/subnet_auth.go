@@ -0,0 +1,89 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	ErrNoControlKeys          = errors.New("no control keys provided")
+	ErrInvalidThreshold       = errors.New("threshold must be between 1 and the number of control keys")
+	ErrDuplicatedControlKey   = errors.New("duplicated control key")
+	ErrSignerNotControlKey    = errors.New("signing key is not a control key")
+	ErrNotEnoughSigningKeys   = errors.New("not enough signing keys to reach the threshold")
+	ErrUnexpectedSubnetAuthTx = errors.New("tx does not have subnet auth")
+)
+
+// ComputeSubnetAuth returns the subnet auth input that authorizes a subnet changing tx to be
+// signed by [signingKeys], for a subnet owned by [controlKeys] with [threshold].
+//
+// Sig indices refer to the control keys in the order the P-Chain stores them, which is
+// sorted, so [controlKeys] can be given in any order. If more signing keys than
+// [threshold] are given, the ones with the lowest indices are used, as the P-Chain
+// requires exactly [threshold] signatures
+func ComputeSubnetAuth(
+	controlKeys []ids.ShortID,
+	threshold uint32,
+	signingKeys []ids.ShortID,
+) (*secp256k1fx.Input, error) {
+	if len(controlKeys) == 0 {
+		return nil, ErrNoControlKeys
+	}
+	if threshold == 0 || threshold > uint32(len(controlKeys)) {
+		return nil, fmt.Errorf("%w: threshold %d, %d control keys", ErrInvalidThreshold, threshold, len(controlKeys))
+	}
+	sortedControlKeys := append([]ids.ShortID{}, controlKeys...)
+	utils.Sort(sortedControlKeys)
+	for i := 1; i < len(sortedControlKeys); i++ {
+		if sortedControlKeys[i] == sortedControlKeys[i-1] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatedControlKey, sortedControlKeys[i])
+		}
+	}
+	signers := set.Of(signingKeys...)
+	controlKeysSet := set.Of(controlKeys...)
+	for signer := range signers {
+		if !controlKeysSet.Contains(signer) {
+			return nil, fmt.Errorf("%w: %s", ErrSignerNotControlKey, signer)
+		}
+	}
+	sigIndices := []uint32{}
+	for i, controlKey := range sortedControlKeys {
+		if uint32(len(sigIndices)) == threshold {
+			break
+		}
+		if signers.Contains(controlKey) {
+			sigIndices = append(sigIndices, uint32(i))
+		}
+	}
+	if uint32(len(sigIndices)) < threshold {
+		return nil, fmt.Errorf("%w: %d signing keys, threshold %d", ErrNotEnoughSigningKeys, len(sigIndices), threshold)
+	}
+	return &secp256k1fx.Input{SigIndices: sigIndices}, nil
+}
+
+// SetSubnetAuth replaces the subnet auth of the subnet changing [unsignedTx] with [subnetAuth]
+func SetSubnetAuth(unsignedTx txs.UnsignedTx, subnetAuth *secp256k1fx.Input) error {
+	switch unsignedTx := unsignedTx.(type) {
+	case *txs.RemoveSubnetValidatorTx:
+		unsignedTx.SubnetAuth = subnetAuth
+	case *txs.AddSubnetValidatorTx:
+		unsignedTx.SubnetAuth = subnetAuth
+	case *txs.CreateChainTx:
+		unsignedTx.SubnetAuth = subnetAuth
+	case *txs.TransformSubnetTx:
+		unsignedTx.SubnetAuth = subnetAuth
+	case *txs.TransferSubnetOwnershipTx:
+		unsignedTx.SubnetAuth = subnetAuth
+	default:
+		return fmt.Errorf("%w: %T", ErrUnexpectedSubnetAuthTx, unsignedTx)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSubnetAuth(t *testing.T) {
+	require := require.New(t)
+	a := ids.ShortID{1}
+	b := ids.ShortID{2}
+	c := ids.ShortID{3}
+	unknown := ids.ShortID{4}
+
+	// indices refer to the sorted control keys
+	input, err := ComputeSubnetAuth([]ids.ShortID{c, a, b}, 2, []ids.ShortID{c, a})
+	require.NoError(err)
+	require.Equal([]uint32{0, 2}, input.SigIndices)
+	require.NoError(input.Verify())
+
+	// extra signing keys are ignored
+	input, err = ComputeSubnetAuth([]ids.ShortID{a, b, c}, 1, []ids.ShortID{c, b})
+	require.NoError(err)
+	require.Equal([]uint32{1}, input.SigIndices)
+
+	_, err = ComputeSubnetAuth(nil, 1, []ids.ShortID{a})
+	require.ErrorIs(err, ErrNoControlKeys)
+	_, err = ComputeSubnetAuth([]ids.ShortID{a}, 2, []ids.ShortID{a})
+	require.ErrorIs(err, ErrInvalidThreshold)
+	_, err = ComputeSubnetAuth([]ids.ShortID{a}, 0, []ids.ShortID{a})
+	require.ErrorIs(err, ErrInvalidThreshold)
+	_, err = ComputeSubnetAuth([]ids.ShortID{a, a}, 1, []ids.ShortID{a})
+	require.ErrorIs(err, ErrDuplicatedControlKey)
+	_, err = ComputeSubnetAuth([]ids.ShortID{a, b}, 1, []ids.ShortID{unknown})
+	require.ErrorIs(err, ErrSignerNotControlKey)
+	_, err = ComputeSubnetAuth([]ids.ShortID{a, b, c}, 2, []ids.ShortID{b})
+	require.ErrorIs(err, ErrNotEnoughSigningKeys)
+}
+
+func TestSetSubnetAuth(t *testing.T) {
+	require := require.New(t)
+	subnetAuth := &secp256k1fx.Input{SigIndices: []uint32{1}}
+	unsignedTx := &txs.AddSubnetValidatorTx{}
+	require.NoError(SetSubnetAuth(unsignedTx, subnetAuth))
+	require.Equal(subnetAuth, unsignedTx.SubnetAuth)
+	require.ErrorIs(SetSubnetAuth(&txs.CreateSubnetTx{}, subnetAuth), ErrUnexpectedSubnetAuthTx)
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/addressbook"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// TxSummary describes a multisig tx for review, with its addresses annotated with
+// the labels of an address book, eg "P-fuji1... (treasury)"
+type TxSummary struct {
+	TxID      ids.ID
+	Kind      TxKind
+	NetworkID uint32
+	// Outputs are the P-Chain outputs of the tx
+	Outputs []OutputSummary
+	// Signers are the addresses that already signed the tx, in credential order
+	Signers []string
+}
+
+// OutputSummary is a P-Chain output of a tx. Amount is in nAVAX
+type OutputSummary struct {
+	Owners    []string
+	Threshold uint32
+	Locktime  uint64
+	Amount    uint64
+}
+
+// Summary summarizes the tx for review, annotating its addresses with their labels
+// in [ab]. [ab] can be nil. No network access is needed
+func (ms *Multisig) Summary(ab *addressbook.AddressBook) (TxSummary, error) {
+	kind, err := ms.GetTxKind()
+	if err != nil {
+		return TxSummary{}, err
+	}
+	baseTx, err := GetBaseTx(ms.PChainTx.Unsigned)
+	if err != nil {
+		return TxSummary{}, err
+	}
+	network := avalanche.NetworkFromNetworkID(baseTx.NetworkID)
+	summary := TxSummary{
+		TxID:      ms.PChainTx.ID(),
+		Kind:      kind,
+		NetworkID: baseTx.NetworkID,
+		Outputs:   []OutputSummary{},
+		Signers:   []string{},
+	}
+	for _, out := range baseTx.Outs {
+		output := out.Out
+		locktime := uint64(0)
+		if lockOut, ok := output.(*stakeable.LockOut); ok {
+			output = lockOut.TransferableOut
+			locktime = lockOut.Locktime
+		}
+		transferOutput, ok := output.(*secp256k1fx.TransferOutput)
+		if !ok {
+			return TxSummary{}, fmt.Errorf("unexpected output type %T", output)
+		}
+		owners, err := ab.AnnotateShortIDs(network, "P", transferOutput.Addrs)
+		if err != nil {
+			return TxSummary{}, err
+		}
+		if transferOutput.Locktime > locktime {
+			locktime = transferOutput.Locktime
+		}
+		summary.Outputs = append(summary.Outputs, OutputSummary{
+			Owners:    owners,
+			Threshold: transferOutput.Threshold,
+			Locktime:  locktime,
+			Amount:    transferOutput.Amt,
+		})
+	}
+	signatures, err := ms.Signatures()
+	if err != nil {
+		return TxSummary{}, err
+	}
+	for _, signature := range signatures {
+		signers, err := ab.AnnotateShortIDs(network, "P", []ids.ShortID{signature.Address})
+		if err != nil {
+			return TxSummary{}, err
+		}
+		summary.Signers = append(summary.Signers, signers...)
+	}
+	return summary, nil
+}
+
+// String renders the summary as human readable lines
+func (s TxSummary) String() string {
+	lines := []string{
+		fmt.Sprintf("%s %s (network %d)", s.Kind, s.TxID, s.NetworkID),
+	}
+	for _, output := range s.Outputs {
+		line := fmt.Sprintf("  output: %d nAVAX to %s", output.Amount, strings.Join(output.Owners, ", "))
+		if len(output.Owners) > 1 {
+			line += fmt.Sprintf(" (threshold %d)", output.Threshold)
+		}
+		if output.Locktime > 0 {
+			line += fmt.Sprintf(" locked until %d", output.Locktime)
+		}
+		lines = append(lines, line)
+	}
+	if len(s.Signers) > 0 {
+		lines = append(lines, "  signed by: "+strings.Join(s.Signers, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/addressbook"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	require := require.New(t)
+	signer, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	treasury := ids.GenerateTestShortID()
+	other := ids.GenerateTestShortID()
+	tx := &txs.Tx{Unsigned: &txs.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    constants.FujiID,
+		BlockchainID: constants.PlatformChainID,
+		Outs: []*avax.TransferableOutput{{
+			Asset: avax.Asset{ID: ids.GenerateTestID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1_000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{treasury, other},
+				},
+			},
+		}},
+	}}}
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{signer}}))
+	ms := New(tx)
+
+	ab := addressbook.New()
+	network := avalanche.FujiNetwork()
+	require.NoError(ab.AddShortID("treasury", network, "P", treasury))
+	require.NoError(ab.AddShortID("signer-1", network, "P", signer.Address()))
+	treasuryAddr, err := address.Format("P", constants.FujiHRP, treasury[:])
+	require.NoError(err)
+	otherAddr, err := address.Format("P", constants.FujiHRP, other[:])
+	require.NoError(err)
+	signerAddr, err := address.Format("P", constants.FujiHRP, signer.Address().Bytes())
+	require.NoError(err)
+
+	summary, err := ms.Summary(ab)
+	require.NoError(err)
+	require.Equal(tx.ID(), summary.TxID)
+	require.Equal(PChainBaseTx, summary.Kind)
+	require.Equal(constants.FujiID, summary.NetworkID)
+	require.Len(summary.Outputs, 1)
+	require.Equal([]string{treasuryAddr + " (treasury)", otherAddr}, summary.Outputs[0].Owners)
+	require.Equal(uint64(1_000), summary.Outputs[0].Amount)
+	require.Equal([]string{signerAddr + " (signer-1)"}, summary.Signers)
+	require.Contains(summary.String(), "1000 nAVAX to "+treasuryAddr+" (treasury), "+otherAddr+" (threshold 1)")
+
+	// without address book
+	summary, err = ms.Summary(nil)
+	require.NoError(err)
+	require.Equal([]string{treasuryAddr, otherAddr}, summary.Outputs[0].Owners)
+
+	_, err = New(nil).Summary(ab)
+	require.ErrorIs(err, ErrUndefinedTx)
+}
@@ -0,0 +1,237 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	ErrMissingOwners        = errors.New("owners not provided")
+	ErrWrongNumberOfCreds   = errors.New("wrong number of credentials")
+	ErrWrongNumberOfSigs    = errors.New("wrong number of signatures")
+	ErrThresholdNotMet      = errors.New("signature threshold not met")
+	ErrMissingSignature     = errors.New("missing signature")
+	ErrWrongSigner          = errors.New("signature from unexpected key")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrSigIndexOutOfBounds  = errors.New("signature index out of bounds")
+	ErrUnexpectedInputType  = errors.New("unexpected input type")
+	ErrUnexpectedCredential = errors.New("unexpected credential type")
+)
+
+// ExpectedOwners are the owners the credentials of a tx are checked against by VerifySignatures
+type ExpectedOwners struct {
+	// UTXOOwners has the owners of each UTXO consumed by the tx, including imported
+	// ones, by UTXO ID (avax.UTXOID.InputID())
+	UTXOOwners map[ids.ID]*secp256k1fx.OutputOwners
+	// SubnetOwners are the owners of the subnet, for txs that require subnet auth.
+	// Addresses must be in the order the P-Chain stores them
+	SubnetOwners *secp256k1fx.OutputOwners
+}
+
+// VerifySignatures checks locally that the credentials of [tx] are the ones the P-Chain
+// expects: one credential per input, plus one for the subnet auth if needed, with exactly
+// the signatures required by the input (or subnet auth) sig indices, each one produced by
+// the owner address it is indexed to. Addresses are recovered from the signatures, so no
+// network access is needed.
+//
+// All issues found are returned joined, so the caller can see every wrong or missing
+// signature at once. Missing signatures wrap ErrMissingSignature, and signatures made by
+// a key other than the expected one wrap ErrWrongSigner
+func VerifySignatures(tx *txs.Tx, expectedOwners ExpectedOwners) error {
+	if tx == nil || tx.Unsigned == nil {
+		return ErrUndefinedTx
+	}
+	unsignedBytes := tx.Unsigned.Bytes()
+	if len(unsignedBytes) == 0 {
+		return fmt.Errorf("tx is not initialized")
+	}
+	ins, err := getSignedInputs(tx.Unsigned)
+	if err != nil {
+		return err
+	}
+	subnetAuth, requiresSubnetAuth := getSubnetAuth(tx.Unsigned)
+	expectedCreds := len(ins)
+	if requiresSubnetAuth {
+		expectedCreds++
+	}
+	if len(tx.Creds) != expectedCreds {
+		return fmt.Errorf("%w: expected %d, got %d", ErrWrongNumberOfCreds, expectedCreds, len(tx.Creds))
+	}
+	issues := []error{}
+	for i, in := range ins {
+		owners, ok := expectedOwners.UTXOOwners[in.InputID()]
+		if !ok || owners == nil {
+			issues = append(issues, fmt.Errorf("%w: input %d consumes utxo %s", ErrMissingOwners, i, in.InputID()))
+			continue
+		}
+		input := in.In
+		if lockIn, ok := input.(*stakeable.LockIn); ok {
+			input = lockIn.TransferableIn
+		}
+		transferInput, ok := input.(*secp256k1fx.TransferInput)
+		if !ok {
+			issues = append(issues, fmt.Errorf("%w: input %d is %T", ErrUnexpectedInputType, i, input))
+			continue
+		}
+		issues = append(issues, verifyCredential(
+			unsignedBytes,
+			fmt.Sprintf("input %d", i),
+			tx.Creds[i],
+			transferInput.SigIndices,
+			owners,
+		)...)
+	}
+	if requiresSubnetAuth {
+		subnetInput, ok := subnetAuth.(*secp256k1fx.Input)
+		switch {
+		case !ok:
+			issues = append(issues, fmt.Errorf("%w: subnet auth is %T", ErrUnexpectedInputType, subnetAuth))
+		case expectedOwners.SubnetOwners == nil:
+			issues = append(issues, fmt.Errorf("%w: subnet owners", ErrMissingOwners))
+		default:
+			issues = append(issues, verifyCredential(
+				unsignedBytes,
+				"subnet auth",
+				tx.Creds[len(tx.Creds)-1],
+				subnetInput.SigIndices,
+				expectedOwners.SubnetOwners,
+			)...)
+		}
+	}
+	return errors.Join(issues...)
+}
+
+// verifyCredential checks that [credential] has a signature by each of the [owners]
+// addresses selected by [sigIndices]
+func verifyCredential(
+	unsignedBytes []byte,
+	name string,
+	credential verify.Verifiable,
+	sigIndices []uint32,
+	owners *secp256k1fx.OutputOwners,
+) []error {
+	cred, ok := credential.(*secp256k1fx.Credential)
+	if !ok {
+		return []error{fmt.Errorf("%w: %s has credential %T", ErrUnexpectedCredential, name, credential)}
+	}
+	if uint32(len(sigIndices)) < owners.Threshold {
+		return []error{fmt.Errorf("%w: %s has %d sig indices, threshold is %d", ErrThresholdNotMet, name, len(sigIndices), owners.Threshold)}
+	}
+	if len(cred.Sigs) != len(sigIndices) {
+		return []error{fmt.Errorf("%w: %s has %d signatures, expected %d", ErrWrongNumberOfSigs, name, len(cred.Sigs), len(sigIndices))}
+	}
+	emptySig := [secp256k1.SignatureLen]byte{}
+	issues := []error{}
+	for i, sigIndex := range sigIndices {
+		if sigIndex >= uint32(len(owners.Addrs)) {
+			issues = append(issues, fmt.Errorf("%w: %s sig index %d, %d owners", ErrSigIndexOutOfBounds, name, sigIndex, len(owners.Addrs)))
+			continue
+		}
+		expected := owners.Addrs[sigIndex]
+		if cred.Sigs[i] == emptySig {
+			issues = append(issues, fmt.Errorf("%w: %s signature %d, expected from %s", ErrMissingSignature, name, i, expected))
+			continue
+		}
+		pubKey, err := secp256k1.RecoverPublicKey(unsignedBytes, cred.Sigs[i][:])
+		if err != nil {
+			issues = append(issues, fmt.Errorf("%w: %s signature %d: %w", ErrInvalidSignature, name, i, err))
+			continue
+		}
+		if signer := pubKey.Address(); signer != expected {
+			issues = append(issues, fmt.Errorf("%w: %s signature %d is from %s, expected from %s", ErrWrongSigner, name, i, signer, expected))
+		}
+	}
+	return issues
+}
+
+// getSignedInputs returns the inputs of [unsignedTx] in credential order, including
+// the imported ones
+func getSignedInputs(unsignedTx txs.UnsignedTx) ([]*avax.TransferableInput, error) {
+	ins, err := GetInputs(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	if importTx, ok := unsignedTx.(*txs.ImportTx); ok {
+		ins = append(append([]*avax.TransferableInput{}, ins...), importTx.ImportedInputs...)
+	}
+	return ins, nil
+}
+
+// getSubnetAuth returns the subnet auth of [unsignedTx], and false if it doesn't
+// require subnet auth
+func getSubnetAuth(unsignedTx txs.UnsignedTx) (verify.Verifiable, bool) {
+	switch unsignedTx := unsignedTx.(type) {
+	case *txs.RemoveSubnetValidatorTx:
+		return unsignedTx.SubnetAuth, true
+	case *txs.AddSubnetValidatorTx:
+		return unsignedTx.SubnetAuth, true
+	case *txs.CreateChainTx:
+		return unsignedTx.SubnetAuth, true
+	case *txs.TransformSubnetTx:
+		return unsignedTx.SubnetAuth, true
+	case *txs.TransferSubnetOwnershipTx:
+		return unsignedTx.SubnetAuth, true
+	default:
+		return nil, false
+	}
+}
+
+// GetExpectedOwners gets the owners of the UTXOs consumed by the tx and, if it requires
+// subnet auth, of the subnet, by querying the P-Chain API. Imported UTXOs are not
+// included, as they are not on the P-Chain
+func (ms *Multisig) GetExpectedOwners() (ExpectedOwners, error) {
+	if ms.Undefined() {
+		return ExpectedOwners{}, ErrUndefinedTx
+	}
+	ins, err := GetInputs(ms.PChainTx.Unsigned)
+	if err != nil {
+		return ExpectedOwners{}, err
+	}
+	expectedOwners := ExpectedOwners{
+		UTXOOwners: map[ids.ID]*secp256k1fx.OutputOwners{},
+	}
+	if len(ins) > 0 {
+		network, err := ms.GetNetwork()
+		if err != nil {
+			return ExpectedOwners{}, err
+		}
+		for _, in := range ins {
+			owners, err := GetUTXOOwners(network, in.UTXOID)
+			if err != nil {
+				return ExpectedOwners{}, err
+			}
+			expectedOwners.UTXOOwners[in.InputID()] = owners
+		}
+	}
+	if _, requiresSubnetAuth := getSubnetAuth(ms.PChainTx.Unsigned); requiresSubnetAuth {
+		controlKeys, threshold, err := ms.GetSubnetOwners()
+		if err != nil {
+			return ExpectedOwners{}, err
+		}
+		expectedOwners.SubnetOwners = &secp256k1fx.OutputOwners{
+			Addrs:     controlKeys,
+			Threshold: threshold,
+		}
+	}
+	return expectedOwners, nil
+}
+
+// VerifySignatures checks the tx signatures with VerifySignatures, getting the
+// expected owners from the P-Chain API
+func (ms *Multisig) VerifySignatures() error {
+	expectedOwners, err := ms.GetExpectedOwners()
+	if err != nil {
+		return err
+	}
+	return VerifySignatures(ms.PChainTx, expectedOwners)
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignatures(t *testing.T) {
+	require := require.New(t)
+	keys := make([]*secp256k1.PrivateKey, 3)
+	for i := range keys {
+		key, err := secp256k1.NewPrivateKey()
+		require.NoError(err)
+		keys[i] = key
+	}
+	utxoID := avax.UTXOID{TxID: ids.GenerateTestID()}
+	newTx := func() *txs.Tx {
+		return &txs.Tx{Unsigned: &txs.AddSubnetValidatorTx{
+			BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+				NetworkID:    constants.FujiID,
+				BlockchainID: constants.PlatformChainID,
+				Ins: []*avax.TransferableInput{{
+					UTXOID: utxoID,
+					Asset:  avax.Asset{ID: ids.GenerateTestID()},
+					In: &secp256k1fx.TransferInput{
+						Amt:   1,
+						Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+					},
+				}},
+			}},
+			SubnetValidator: txs.SubnetValidator{Subnet: ids.GenerateTestID()},
+			SubnetAuth:      &secp256k1fx.Input{SigIndices: []uint32{0, 1}},
+		}}
+	}
+	expectedOwners := ExpectedOwners{
+		UTXOOwners: map[ids.ID]*secp256k1fx.OutputOwners{
+			utxoID.InputID(): {Threshold: 1, Addrs: []ids.ShortID{keys[0].Address()}},
+		},
+		SubnetOwners: &secp256k1fx.OutputOwners{
+			Threshold: 2,
+			Addrs:     []ids.ShortID{keys[1].Address(), keys[2].Address()},
+		},
+	}
+
+	tx := newTx()
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keys[0]}, {keys[1], keys[2]}}))
+	require.NoError(VerifySignatures(tx, expectedOwners))
+
+	// subnet auth signed with the fee paying key instead of the second control key
+	tx = newTx()
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keys[0]}, {keys[1], keys[0]}}))
+	err := VerifySignatures(tx, expectedOwners)
+	require.ErrorIs(err, ErrWrongSigner)
+	require.ErrorContains(err, "subnet auth signature 1 is from "+keys[0].Address().String())
+
+	// partially signed
+	tx = newTx()
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keys[0]}, {keys[1], keys[2]}}))
+	tx.Creds[1].(*secp256k1fx.Credential).Sigs[0] = [secp256k1.SignatureLen]byte{}
+	err = VerifySignatures(tx, expectedOwners)
+	require.ErrorIs(err, ErrMissingSignature)
+	require.NotErrorIs(err, ErrWrongSigner)
+
+	// subnet auth credential missing
+	tx = newTx()
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keys[0]}}))
+	require.ErrorIs(VerifySignatures(tx, expectedOwners), ErrWrongNumberOfCreds)
+
+	// unknown utxo owners
+	tx = newTx()
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{keys[0]}, {keys[1], keys[2]}}))
+	require.ErrorIs(VerifySignatures(tx, ExpectedOwners{SubnetOwners: expectedOwners.SubnetOwners}), ErrMissingOwners)
+
+	require.ErrorIs(VerifySignatures(nil, expectedOwners), ErrUndefinedTx)
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package multisig
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+
+	avmtxs "github.com/ava-labs/avalanchego/vms/avm/txs"
+	xbuilder "github.com/ava-labs/avalanchego/wallet/chain/x/builder"
+)
+
+// X-Chain txs are not wrapped by Multisig, which holds P-Chain txs, so their spend
+// signers are obtained with the functions below. Only the transfer inputs are
+// considered: the operations of an OperationTx (NFT and property fx) are signed
+// with their own credentials, placed after the ones of the inputs
+
+// GetXChainSpendSigners gets all addresses that are required to sign the inputs of X-Chain
+// [tx], querying the X-Chain API of [network] for the owners of the consumed UTXOs. See
+// GetSpendSigners
+func GetXChainSpendSigners(network avalanche.Network, tx *avmtxs.Tx) ([]ids.ShortID, error) {
+	spendSigners, err := xChainSpendSignersByInput(newXChainClient(network), tx)
+	if err != nil {
+		return nil, err
+	}
+	return utils.AppendSlices(spendSigners...), nil
+}
+
+// GetXChainRemainingSpendSigners gets the addresses required to sign the inputs of X-Chain
+// [tx], and the ones among them that have not signed yet, querying the X-Chain API of
+// [network] for the owners of the consumed UTXOs. Imported inputs are not checked, as their
+// owners are not on the X-Chain. See XChainRemainingSpendSigners
+func GetXChainRemainingSpendSigners(network avalanche.Network, tx *avmtxs.Tx) ([]ids.ShortID, []ids.ShortID, error) {
+	spendSigners, err := xChainSpendSignersByInput(newXChainClient(network), tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	remainingSigners, err := remainingSpendSigners(xChainCreds(tx), spendSigners)
+	if err != nil {
+		return nil, nil, err
+	}
+	return utils.AppendSlices(spendSigners...), remainingSigners, nil
+}
+
+// XChainRemainingSpendSigners is RemainingSpendSigners for X-Chain [tx]: no network access
+// is needed, and the imported inputs of an ImportTx are included
+func XChainRemainingSpendSigners(
+	tx *avmtxs.Tx,
+	utxoOwners map[ids.ID]*secp256k1fx.OutputOwners,
+) ([]ids.ShortID, []ids.ShortID, error) {
+	if tx == nil || tx.Unsigned == nil {
+		return nil, nil, ErrUndefinedTx
+	}
+	ins, err := xChainInputs(tx.Unsigned)
+	if err != nil {
+		return nil, nil, err
+	}
+	if importTx, ok := tx.Unsigned.(*avmtxs.ImportTx); ok {
+		ins = append(append([]*avax.TransferableInput{}, ins...), importTx.ImportedIns...)
+	}
+	spendSigners, err := spendSignersByInput(ins, utxoOwners)
+	if err != nil {
+		return nil, nil, err
+	}
+	remainingSigners, err := remainingSpendSigners(xChainCreds(tx), spendSigners)
+	if err != nil {
+		return nil, nil, err
+	}
+	return utils.AppendSlices(spendSigners...), remainingSigners, nil
+}
+
+// GetXChainUTXOOwners gets the output owners of the X-Chain UTXO [utxoID], by querying
+// the tx that produced it
+func GetXChainUTXOOwners(network avalanche.Network, utxoID avax.UTXOID) (*secp256k1fx.OutputOwners, error) {
+	return xChainUTXOOwners(newXChainClient(network), utxoID)
+}
+
+func newXChainClient(network avalanche.Network) avm.Client {
+	return avm.NewClient(network.Endpoint, "X")
+}
+
+func xChainSpendSignersByInput(xClient txGetter, tx *avmtxs.Tx) ([][]ids.ShortID, error) {
+	if tx == nil || tx.Unsigned == nil {
+		return nil, ErrUndefinedTx
+	}
+	ins, err := xChainInputs(tx.Unsigned)
+	if err != nil {
+		return nil, err
+	}
+	utxoOwners, err := fetchUTXOOwners(ins, func(utxoID avax.UTXOID) (*secp256k1fx.OutputOwners, error) {
+		return xChainUTXOOwners(xClient, utxoID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spendSignersByInput(ins, utxoOwners)
+}
+
+func xChainUTXOOwners(xClient txGetter, utxoID avax.UTXOID) (*secp256k1fx.OutputOwners, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	txBytes, err := xClient.GetTx(ctx, utxoID.TxID)
+	if err != nil {
+		return nil, fmt.Errorf("tx %s query error: %w", utxoID.TxID, err)
+	}
+	tx, err := xbuilder.Parser.ParseTx(txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx %s: %w", utxoID.TxID, err)
+	}
+	// UTXOs also covers the initial states of a CreateAssetTx and the outputs of operations
+	for _, utxo := range tx.UTXOs() {
+		if utxo.OutputIndex != utxoID.OutputIndex {
+			continue
+		}
+		transferOutput, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok {
+			return nil, fmt.Errorf("expected output of type *secp256k1fx.TransferOutput, got %T", utxo.Out)
+		}
+		return &transferOutput.OutputOwners, nil
+	}
+	return nil, fmt.Errorf("output index %d not found on tx %s", utxoID.OutputIndex, utxoID.TxID)
+}
+
+// xChainInputs returns the inputs consumed by [unsignedTx] from the X-Chain UTXO set
+// (imported inputs are not included, as they come from shared memory)
+func xChainInputs(unsignedTx avmtxs.UnsignedTx) ([]*avax.TransferableInput, error) {
+	switch unsignedTx := unsignedTx.(type) {
+	case *avmtxs.BaseTx:
+		return unsignedTx.Ins, nil
+	case *avmtxs.CreateAssetTx:
+		return unsignedTx.Ins, nil
+	case *avmtxs.OperationTx:
+		return unsignedTx.Ins, nil
+	case *avmtxs.ImportTx:
+		return unsignedTx.Ins, nil
+	case *avmtxs.ExportTx:
+		return unsignedTx.Ins, nil
+	default:
+		return nil, fmt.Errorf("unexpected unsigned tx type %T", unsignedTx)
+	}
+}
+
+func xChainCreds(tx *avmtxs.Tx) []verify.Verifiable {
+	creds := make([]verify.Verifiable, 0, len(tx.Creds))
+	for _, cred := range tx.Creds {
+		creds = append(creds, cred.Credential)
+	}
+	return creds
+}
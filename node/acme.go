@@ -0,0 +1,124 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	letsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	acmeChallengeTTL      = 60
+)
+
+// issueCertificateDNS01 obtains a certificate for [params.Domain] from Let's Encrypt, solving
+// the DNS-01 challenge with [params.DNSProvider]. Returns the PEM encoded certificate chain
+// and private key
+func issueCertificateDNS01(ctx context.Context, params TLSParams) ([]byte, []byte, error) {
+	accountKey := params.AccountKey
+	if accountKey == nil {
+		var err error
+		accountKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: acme.LetsEncryptURL,
+	}
+	if params.Staging {
+		client.DirectoryURL = letsEncryptStagingURL
+	}
+	account := &acme.Account{Contact: []string{"mailto:" + params.Email}}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, nil, fmt.Errorf("failure registering ACME account: %w", err)
+	}
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(params.Domain))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := solveDNS01(ctx, client, authzURL, params); err != nil {
+			return nil, nil, err
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, nil, err
+	}
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: params.Domain},
+		DNSNames: []string{params.Domain},
+	}, certKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := []byte{}
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// solveDNS01 publishes the DNS-01 TXT record for [authzURL] and waits for the authorization
+// to be valid, removing the record afterwards
+func solveDNS01(ctx context.Context, client *acme.Client, authzURL string, params TLSParams) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "dns-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("no dns-01 challenge offered for %s", authz.Identifier.Value)
+	}
+	value, err := client.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return err
+	}
+	recordName := "_acme-challenge." + authz.Identifier.Value
+	if err := params.DNSProvider.UpsertTXTRecord(params.DNSZone, recordName, []string{value}, acmeChallengeTTL); err != nil {
+		return err
+	}
+	defer func() {
+		_ = params.DNSProvider.DeleteTXTRecords(params.DNSZone, recordName)
+	}()
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return err
+	}
+	_, err = client.WaitAuthorization(ctx, authz.URI)
+	return err
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+)
+
+// chainConfigHost is the subset of Node used to reload chain configs
+type chainConfigHost interface {
+	FileExists(path string) (bool, error)
+	ReadFileBytes(remoteFile string, timeout time.Duration) ([]byte, error)
+	MkdirAll(remoteDir string, timeout time.Duration) error
+	UploadBytes(data []byte, remoteFile string, timeout time.Duration) error
+	RunSSHRestartAvalanchego(opts ...SSHOption) error
+	WaitForAvalancheGoHealth(timeout time.Duration) error
+}
+
+// ReloadChainConfig uploads [chainConfig] as the config of [blockchainID] and restarts
+// avalanchego so the new config is applied, waiting up to [timeout] for the node
+// to be healthy again.
+// AvalancheGo only reads chain configs on chain creation, and does not support
+// restarting a single chain, so a restart is required whenever the config changes.
+// To minimize validator downtime, nothing is uploaded nor restarted if the node
+// already has the same config, ignoring JSON formatting differences. Returns
+// whether the node was restarted
+func (h *Node) ReloadChainConfig(blockchainID string, chainConfig []byte, timeout time.Duration) (bool, error) {
+	return reloadChainConfig(h, blockchainID, chainConfig, timeout)
+}
+
+func reloadChainConfig(h chainConfigHost, blockchainID string, chainConfig []byte, timeout time.Duration) (bool, error) {
+	remoteChainConfig := remoteconfig.GetRemoteAvalancheChainConfig(blockchainID)
+	exists, err := h.FileExists(remoteChainConfig)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		currentChainConfig, err := h.ReadFileBytes(remoteChainConfig, constants.SSHFileOpsTimeout)
+		if err != nil {
+			return false, err
+		}
+		if sameChainConfig(currentChainConfig, chainConfig) {
+			return false, nil
+		}
+	}
+	if err := h.MkdirAll(filepath.Dir(remoteChainConfig), constants.SSHFileOpsTimeout); err != nil {
+		return false, err
+	}
+	if err := h.UploadBytes(chainConfig, remoteChainConfig, constants.SSHFileOpsTimeout); err != nil {
+		return false, err
+	}
+	if err := h.RunSSHRestartAvalanchego(); err != nil {
+		return true, err
+	}
+	return true, h.WaitForAvalancheGoHealth(timeout)
+}
+
+// sameChainConfig checks if chain configs [a] and [b] are equal, either byte by byte
+// or as JSON values
+func sameChainConfig(a []byte, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var aValue, bValue interface{}
+	if json.Unmarshal(a, &aValue) != nil || json.Unmarshal(b, &bValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
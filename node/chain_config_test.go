@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/stretchr/testify/require"
+)
+
+var errChainConfigTestRestart = errors.New("restart failed")
+
+type fakeChainConfigHost struct {
+	files      map[string][]byte
+	restarts   int
+	restartErr error
+}
+
+func (h *fakeChainConfigHost) FileExists(path string) (bool, error) {
+	_, ok := h.files[path]
+	return ok, nil
+}
+
+func (h *fakeChainConfigHost) ReadFileBytes(remoteFile string, _ time.Duration) ([]byte, error) {
+	return h.files[remoteFile], nil
+}
+
+func (*fakeChainConfigHost) MkdirAll(string, time.Duration) error {
+	return nil
+}
+
+func (h *fakeChainConfigHost) UploadBytes(data []byte, remoteFile string, _ time.Duration) error {
+	h.files[remoteFile] = data
+	return nil
+}
+
+func (h *fakeChainConfigHost) RunSSHRestartAvalanchego(...SSHOption) error {
+	h.restarts++
+	return h.restartErr
+}
+
+func (*fakeChainConfigHost) WaitForAvalancheGoHealth(time.Duration) error {
+	return nil
+}
+
+func TestReloadChainConfig(t *testing.T) {
+	require := require.New(t)
+	blockchainID := "2Q2cnMpHLx5UZ5MiCsCJPPhH7uR1CEi8hQ4Ue8LSmJgbXpAhHi"
+	remoteChainConfig := remoteconfig.GetRemoteAvalancheChainConfig(blockchainID)
+	host := &fakeChainConfigHost{files: map[string][]byte{}}
+
+	// no config on the node yet
+	restarted, err := reloadChainConfig(host, blockchainID, []byte(`{"log-level":"info"}`), time.Second)
+	require.NoError(err)
+	require.True(restarted)
+	require.Equal(1, host.restarts)
+	require.Equal([]byte(`{"log-level":"info"}`), host.files[remoteChainConfig])
+
+	// unchanged, only formatted differently
+	restarted, err = reloadChainConfig(host, blockchainID, []byte("{\n  \"log-level\": \"info\"\n}\n"), time.Second)
+	require.NoError(err)
+	require.False(restarted)
+	require.Equal(1, host.restarts)
+	require.Equal([]byte(`{"log-level":"info"}`), host.files[remoteChainConfig])
+
+	// changed
+	restarted, err = reloadChainConfig(host, blockchainID, []byte(`{"log-level":"debug"}`), time.Second)
+	require.NoError(err)
+	require.True(restarted)
+	require.Equal(2, host.restarts)
+	require.Equal([]byte(`{"log-level":"debug"}`), host.files[remoteChainConfig])
+
+	host.restartErr = errChainConfigTestRestart
+	restarted, err = reloadChainConfig(host, blockchainID, []byte(`{"log-level":"warn"}`), time.Second)
+	require.ErrorIs(err, errChainConfigTestRestart)
+	require.True(restarted)
+}
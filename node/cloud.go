@@ -6,15 +6,17 @@ package node
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	awsAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/aws"
+	azureAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/azure"
 	gcpAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/gcp"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 )
 
-// CloudParams contains the specs of the nodes to be created in AWS / GCP.
+// CloudParams contains the specs of the nodes to be created in AWS / GCP / Azure.
 // For the minimum recommended hardware specification for nodes connected to Mainnet, head to https://github.com/ava-labs/avalanchego?tab=readme-ov-file#installation
 type CloudParams struct {
 	// Region to use for the node
@@ -50,6 +52,9 @@ type CloudParams struct {
 
 	// GCP Specific configuration
 	GCPConfig *GCPConfig
+
+	// Azure specific configuration. Region is the Azure location, eg eastus
+	AzureConfig *AzureConfig
 }
 
 type AWSConfig struct {
@@ -57,7 +62,8 @@ type AWSConfig struct {
 	// For more information about AWS Profile, head to https://docs.aws.amazon.com/cli/v1/userguide/cli-configure-files.html#cli-configure-files-format-profile
 	AWSProfile string
 
-	// AWSKeyPair is the name of the KeyPair used to access the node
+	// AWSKeyPair is the name of the KeyPair used to access the node. It can be an
+	// existing key pair, or one created with awsAPI.CreateSSHKeyPair
 	AWSKeyPair string
 
 	// AWSVolumeSize is AWS EBS volume size in GB
@@ -76,11 +82,53 @@ type AWSConfig struct {
 	// For more information on the throughput of various EBS volume types, head to https://docs.aws.amazon.com/ebs/latest/userguide/ebs-volume-types.html
 	AWSVolumeThroughput int
 
-	// AWSSecurityGroupID is ID of the AWS security group to use for the node
+	// AWSSecurityGroupID is ID of the AWS security group to use for the node. It can be
+	// an existing security group, or one created with awsAPI.CreateSecurityGroup. Before
+	// creating nodes, the security group is checked to allow SSH from the user IP and
+	// avalanchego P2P from anywhere
 	AWSSecurityGroupID string
 
-	// AWSSecurityGroupName is name of the AWS security group to use for the node
+	// AWSSecurityGroupName is name of the AWS security group to use for the node.
+	// If empty, it is set from AWSSecurityGroupID when nodes are created
 	AWSSecurityGroupName string
+
+	// AWSRequireIMDSv2 makes the node only accept IMDSv2 (session token) requests
+	// to the instance metadata service
+	AWSRequireIMDSv2 bool
+
+	// AWSDisablePublicIP prevents the assignment of an ephemeral public IP to the node.
+	// It requires the node to be created with a static (elastic) IP
+	AWSDisablePublicIP bool
+
+	// AWSEncryptVolume enables encryption of the AWS EBS volume of the node, using
+	// AWSKMSKeyID, or the account default KMS key if it is empty
+	AWSEncryptVolume bool
+
+	// AWSKMSKeyID is the ID or ARN of the KMS key used to encrypt the AWS EBS volume.
+	// Setting it implies AWSEncryptVolume
+	AWSKMSKeyID string
+
+	// AWSTerminationProtection prevents the node instance from being terminated. Destroy
+	// fails on protected nodes until the protection is disabled with
+	// awsAPI.SetTerminationProtection
+	AWSTerminationProtection bool
+
+	// AWSVerifyNodeIdentity pins the SSH host keys printed on the console of the new
+	// instances into the node SSHConfig, and makes Connect verify the node instance ID.
+	// It protects against connecting to another instance that reused the node IP, at the
+	// cost of waiting for the console output when the nodes are created
+	AWSVerifyNodeIdentity bool
+}
+
+// instanceOptions returns the EC2 hardening settings of the config
+func (c *AWSConfig) instanceOptions() awsAPI.EC2InstanceOptions {
+	return awsAPI.EC2InstanceOptions{
+		RequireIMDSv2:         c.AWSRequireIMDSv2,
+		DisablePublicIP:       c.AWSDisablePublicIP,
+		EncryptVolume:         c.AWSEncryptVolume,
+		KMSKeyID:              c.AWSKMSKeyID,
+		TerminationProtection: c.AWSTerminationProtection,
+	}
 }
 
 type GCPConfig struct {
@@ -101,6 +149,42 @@ type GCPConfig struct {
 
 	// GCP SSH Public Key
 	GCPSSHKey string
+
+	// GCPVerifyNodeIdentity pins the SSH host keys printed on the serial port of the new
+	// instances into the node SSHConfig, and makes Connect verify the node instance name
+	GCPVerifyNodeIdentity bool
+}
+
+// AzureConfig contains the Azure specific configuration of the nodes
+type AzureConfig struct {
+	// AzureSubscriptionID is the Azure subscription the nodes are created in
+	AzureSubscriptionID string
+
+	// AzureResourceGroup is the existing resource group the node resources are created in
+	AzureResourceGroup string
+
+	// AzureNetwork is the name of the virtual network of the nodes. It is created if it
+	// doesn't exist
+	AzureNetwork string
+
+	// AzureSecurityGroup is the name of the existing network security group of the nodes,
+	// eg created with azureAPI.SetupSecurityGroup. Before creating nodes, it is checked to
+	// allow SSH from the user IP and avalanchego P2P from anywhere
+	AzureSecurityGroup string
+
+	// AzureKeyName is the name of the existing SSH public key resource authorized on the
+	// nodes, eg created with azureAPI.CreateAndDownloadKeyPair
+	AzureKeyName string
+
+	// AzureVolumeSize is the OS disk size in GB
+	AzureVolumeSize int
+
+	// AzureVolumeType is the managed disk type, eg Premium_LRS or StandardSSD_LRS
+	AzureVolumeType string
+
+	// AzureVerifyNodeIdentity pins the SSH host keys printed on the serial console of the
+	// new VMs into the node SSHConfig, and makes Connect verify the node VM name
+	AzureVerifyNodeIdentity bool
 }
 
 // GetDefaultCloudParams returns the following specs:
@@ -108,8 +192,10 @@ type GCPConfig struct {
 // - AWSVolumeSize:       1000,
 // - AWSVolumeThroughput: 500,
 // - AWSVolumeIOPS:       1000,
-// - InstanceType: 		  "c5.2xlarge" (AWS), "e2-standard-8" (GCP)
-// - AMI:				  Avalanche-CLI Ubuntu 20.04
+// - InstanceType: 		  "c5.2xlarge" (AWS), "e2-standard-8" (GCP), "Standard_F8s_v2" (Azure)
+// - AMI:				  Avalanche-CLI Ubuntu 20.04, Canonical Ubuntu 22.04 on Azure
+// - AWSRequireIMDSv2:    true
+// - AWSEncryptVolume:    true
 func GetDefaultCloudParams(ctx context.Context, cloud SupportedCloud) (*CloudParams, error) {
 	// make sure that CloudParams is initialized with default values
 	switch cloud {
@@ -121,6 +207,8 @@ func GetDefaultCloudParams(ctx context.Context, cloud SupportedCloud) (*CloudPar
 				AWSVolumeThroughput: 500,
 				AWSVolumeIOPS:       1000,
 				AWSVolumeType:       "gp3",
+				AWSRequireIMDSv2:    true,
+				AWSEncryptVolume:    true,
 			},
 			Region:       "us-east-1",
 			InstanceType: constants.AWSDefaultInstanceType,
@@ -164,12 +252,32 @@ func GetDefaultCloudParams(ctx context.Context, cloud SupportedCloud) (*CloudPar
 		if err != nil {
 			return nil, err
 		}
-		imageID, err := gcpSvc.GetAvalancheUbuntuAMIID()
+		imageID, err := gcpSvc.GetAvalancheUbuntuImageID(gcpAPI.MachineTypeArch(cp.InstanceType))
 		if err != nil {
 			return nil, err
 		}
 		cp.ImageID = imageID
 		return cp, nil
+	case AzureCloud:
+		subscriptionID := os.Getenv(constants.AzureSubscriptionEnvVar)
+		if subscriptionID == "" {
+			return nil, fmt.Errorf("%s is not set", constants.AzureSubscriptionEnvVar)
+		}
+		imageID, err := azureAPI.GetUbuntuImageID("x86_64")
+		if err != nil {
+			return nil, err
+		}
+		return &CloudParams{
+			AzureConfig: &AzureConfig{
+				AzureSubscriptionID: subscriptionID,
+				AzureNetwork:        "avalanche-tooling-sdk-go-eastus",
+				AzureVolumeSize:     constants.CloudServerStorageSize,
+				AzureVolumeType:     "Premium_LRS",
+			},
+			Region:       "eastus",
+			InstanceType: constants.AzureDefaultInstanceType,
+			ImageID:      imageID,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported cloud")
 	}
@@ -198,9 +306,6 @@ func (cp *CloudParams) Validate() error {
 		if cp.AWSConfig.AWSSecurityGroupID == "" {
 			return fmt.Errorf("AWS security group ID is required")
 		}
-		if cp.AWSConfig.AWSSecurityGroupName == "" {
-			return fmt.Errorf("AWS security group Name is required")
-		}
 		if cp.AWSConfig.AWSVolumeSize < 0 {
 			return fmt.Errorf("AWS volume size must be positive")
 		}
@@ -241,6 +346,22 @@ func (cp *CloudParams) Validate() error {
 		if cp.GCPConfig.GCPSSHKey == "" {
 			return fmt.Errorf("GCP SSH key is required")
 		}
+	case AzureCloud:
+		if cp.AzureConfig.AzureResourceGroup == "" {
+			return fmt.Errorf("Azure resource group is required")
+		}
+		if cp.AzureConfig.AzureNetwork == "" {
+			return fmt.Errorf("Azure network is required")
+		}
+		if cp.AzureConfig.AzureSecurityGroup == "" {
+			return fmt.Errorf("Azure network security group is required")
+		}
+		if cp.AzureConfig.AzureKeyName == "" {
+			return fmt.Errorf("Azure SSH key name is required")
+		}
+		if cp.AzureConfig.AzureVolumeSize < 0 {
+			return fmt.Errorf("Azure volume size must be positive")
+		}
 	default:
 		return fmt.Errorf("unsupported cloud")
 	}
@@ -254,6 +375,8 @@ func (cp *CloudParams) Cloud() SupportedCloud {
 		return AWSCloud
 	case cp.GCPConfig != nil && (cp.GCPConfig.GCPProject != "" || cp.GCPConfig.GCPCredentials != ""):
 		return GCPCloud
+	case cp.AzureConfig != nil && cp.AzureConfig.AzureSubscriptionID != "":
+		return AzureCloud
 	default:
 		return Unknown
 	}
@@ -0,0 +1,84 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+)
+
+// Cluster is a set of nodes that are operated together
+type Cluster struct {
+	// Name of the cluster
+	Name string
+
+	// Nodes in the cluster
+	Nodes []Node
+}
+
+// NewCluster creates a cluster with the given nodes
+func NewCluster(name string, nodes []Node) *Cluster {
+	return &Cluster{
+		Name:  name,
+		Nodes: nodes,
+	}
+}
+
+// ScriptParams holds the execution settings of Cluster.RunScript
+type ScriptParams struct {
+	// Description of the script, used for logging. Defaults to the script path
+	Description string
+
+	// Timeout for the execution of the script on each node.
+	// Defaults to constants.SSHScriptTimeout
+	Timeout time.Duration
+
+	// Env is the environment set for the script on all nodes, in KEY=VALUE format
+	Env []string
+
+	// NodeEnv is the environment set for the script on specific nodes, by node ID.
+	// It is appended to Env, so it overrides common values
+	NodeEnv map[string][]string
+}
+
+// RunScript renders the embedded script template at [scriptPath] with [templateVars]
+// and runs it over SSH on all the nodes of the cluster in parallel.
+// The result value for each node is the combined output of the script
+func (c *Cluster) RunScript(
+	scriptPath string,
+	templateVars ScriptInputs,
+	params ScriptParams,
+) (*NodeResults, error) {
+	if params.Description == "" {
+		params.Description = scriptPath
+	}
+	if params.Timeout == 0 {
+		params.Timeout = constants.SSHScriptTimeout
+	}
+	script, err := renderScript(params.Description, scriptPath, templateVars)
+	if err != nil {
+		return nil, err
+	}
+	results := &NodeResults{}
+	wg := sync.WaitGroup{}
+	for i := range c.Nodes {
+		wg.Add(1)
+		go func(h *Node) {
+			defer wg.Done()
+			var env []string
+			if len(params.Env) > 0 || len(params.NodeEnv[h.NodeID]) > 0 {
+				env = append(append([]string{}, params.Env...), params.NodeEnv[h.NodeID]...)
+			}
+			startTime := time.Now()
+			output, err := h.Command(env, params.Timeout, script)
+			duration := time.Since(startTime)
+			h.Logger.Infof("RunScript[%s]%s took %s with err: %v", h.NodeID, params.Description, duration, err)
+			results.AddTimedResult(h.NodeID, string(output), err, duration)
+		}(&c.Nodes[i])
+	}
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterRunScript(t *testing.T) {
+	require := require.New(t)
+	commands := map[string]int{}
+	lock := sync.Mutex{}
+	server := newTestSSHServer(t, func(command string, env []string) ([]byte, uint32) {
+		lock.Lock()
+		commands[command]++
+		lock.Unlock()
+		if strings.Contains(strings.Join(env, " "), "FAIL=1") {
+			return []byte("failed"), 1
+		}
+		return []byte(fmt.Sprintf("env: %s", strings.Join(env, " "))), 0
+	})
+	cluster := NewCluster("test", []Node{
+		server.node(t, "node1"),
+		server.node(t, "node2"),
+		server.node(t, "node3"),
+	})
+
+	results, err := cluster.RunScript(
+		"shell/getNewSubnetEVMRelease.sh",
+		ScriptInputs{SubnetEVMReleaseURL: "https://example.com/subnet-evm.tar.gz", SubnetEVMArchive: "subnet-evm.tar.gz"},
+		ScriptParams{
+			Env:     []string{"NETWORK=fuji"},
+			NodeEnv: map[string][]string{"node2": {"NODE=2"}, "node3": {"FAIL=1"}},
+		},
+	)
+	require.NoError(err)
+	// the script is rendered once with the template vars, and run on all nodes
+	require.Len(commands, 1)
+	for command, count := range commands {
+		require.Equal(3, count)
+		require.Contains(command, `busybox wget "https://example.com/subnet-evm.tar.gz"`)
+		require.Contains(command, `tar xvf "subnet-evm.tar.gz"`)
+	}
+	require.Equal(3, results.Len())
+	require.Equal([]string{"node3"}, results.GetErrorHosts())
+	outputs := results.GetResultMap()
+	require.Equal("env: NETWORK=fuji", outputs["node1"])
+	require.Equal("env: NETWORK=fuji NODE=2", outputs["node2"])
+
+	_, err = cluster.RunScript("shell/missing.sh", ScriptInputs{}, ScriptParams{})
+	require.Error(err)
+}
@@ -19,12 +19,15 @@ type AvalancheConfigInputs struct {
 	NetworkID        string
 	DBDir            string
 	LogDir           string
+	PluginDir        string
 	PublicIP         string
 	StateSyncEnabled bool
 	PruningEnabled   bool
 	TrackSubnets     string
 	BootstrapIDs     string
 	BootstrapIPs     string
+	StateSyncIDs     string
+	StateSyncIPs     string
 	GenesisPath      string
 }
 
@@ -34,6 +37,7 @@ func PrepareAvalancheConfig(publicIP string, networkID string, subnetsToTrack []
 		NetworkID:        networkID,
 		DBDir:            "/.avalanchego/db/",
 		LogDir:           "/.avalanchego/logs/",
+		PluginDir:        "/.avalanchego/plugins/",
 		PublicIP:         publicIP,
 		StateSyncEnabled: true,
 		PruningEnabled:   false,
@@ -42,7 +46,7 @@ func PrepareAvalancheConfig(publicIP string, networkID string, subnetsToTrack []
 }
 
 func RenderAvalancheTemplate(templateName string, config AvalancheConfigInputs) ([]byte, error) {
-	templateBytes, err := templates.ReadFile(templateName)
+	templateBytes, err := readTemplate(templateName)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +91,10 @@ func GetRemoteAvalancheCChainConfig() string {
 	return filepath.Join(constants.CloudNodeConfigPath, "chains", "C", "config.json")
 }
 
+func GetRemoteAvalancheChainConfig(blockchainID string) string {
+	return filepath.Join(constants.CloudNodeConfigPath, "chains", blockchainID, "config.json")
+}
+
 func GetRemoteAvalancheGenesis() string {
 	return filepath.Join(constants.CloudNodeConfigPath, "genesis.json")
 }
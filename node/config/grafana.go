@@ -9,19 +9,19 @@ import (
 )
 
 func RenderGrafanaLokiDataSourceConfig() ([]byte, error) {
-	return templates.ReadFile("templates/grafana-loki-datasource.yaml")
+	return readTemplate("templates/grafana-loki-datasource.yaml")
 }
 
 func RenderGrafanaPrometheusDataSourceConfigg() ([]byte, error) {
-	return templates.ReadFile("templates/grafana-prometheus-datasource.yaml")
+	return readTemplate("templates/grafana-prometheus-datasource.yaml")
 }
 
 func RenderGrafanaConfig() ([]byte, error) {
-	return templates.ReadFile("templates/grafana.ini")
+	return readTemplate("templates/grafana.ini")
 }
 
 func RenderGrafanaDashboardConfig() ([]byte, error) {
-	return templates.ReadFile("templates/grafana-dashboards.yaml")
+	return readTemplate("templates/grafana-dashboards.yaml")
 }
 
 func GrafanaFoldersToCreate() []string {
@@ -5,6 +5,8 @@ package services
 
 import (
 	"embed"
+	"io/fs"
+	"sync"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 )
@@ -12,6 +14,27 @@ import (
 //go:embed templates/*
 var templates embed.FS
 
+var (
+	overridesLock sync.RWMutex
+	overrides     fs.FS
+)
+
+// SetOverrides makes the config templates be read from [fsys] when present there,
+// with the same paths as the embedded ones, eg. templates/avalanche-node.tmpl. A nil
+// [fsys] restores the embedded templates
+func SetOverrides(fsys fs.FS) {
+	overridesLock.Lock()
+	defer overridesLock.Unlock()
+	overrides = fsys
+}
+
+// readTemplate reads the template at [name], from the overrides if found there
+func readTemplate(name string) ([]byte, error) {
+	overridesLock.RLock()
+	defer overridesLock.RUnlock()
+	return utils.ReadFileWithOverride(overrides, templates, name)
+}
+
 // RemoteFoldersToCreateMonitoring returns a list of folders that need to be created on the remote Monitoring server
 func RemoteFoldersToCreateMonitoring() []string {
 	return utils.AppendSlices[string](
@@ -0,0 +1,71 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package services
+
+import (
+	"bytes"
+	"path/filepath"
+	"text/template"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+const (
+	// paths as seen from inside the nginx container
+	nginxCertsDir       = "/etc/nginx/certs"
+	letsEncryptLivePath = "/etc/letsencrypt/live"
+)
+
+type NginxConfigInputs struct {
+	Domain       string
+	CertFile     string
+	KeyFile      string
+	UpstreamPort int
+}
+
+// PrepareNginxConfig returns the reverse proxy config inputs for [domain]. If [certbotManaged]
+// is true, the certificate is the one managed by certbot on the node, otherwise the one
+// uploaded to GetRemoteNginxCertsDir
+func PrepareNginxConfig(domain string, certbotManaged bool) NginxConfigInputs {
+	certDir := nginxCertsDir
+	if certbotManaged {
+		certDir = filepath.Join(letsEncryptLivePath, domain)
+	}
+	return NginxConfigInputs{
+		Domain:       domain,
+		CertFile:     filepath.Join(certDir, "fullchain.pem"),
+		KeyFile:      filepath.Join(certDir, "privkey.pem"),
+		UpstreamPort: constants.AvalanchegoAPIPort,
+	}
+}
+
+func RenderNginxConfig(config NginxConfigInputs) ([]byte, error) {
+	templateBytes, err := readTemplate("templates/nginx.conf")
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("nginx").Parse(string(templateBytes))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, config); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func GetRemoteNginxConfig() string {
+	return utils.GetRemoteComposeServicePath(constants.ServiceNginx, "nginx.conf")
+}
+
+// GetRemoteNginxCertsDir returns the remote dir where SDK issued certificates are uploaded
+func GetRemoteNginxCertsDir() string {
+	return utils.GetRemoteComposeServicePath(constants.ServiceNginx, "certs")
+}
+
+func NginxFoldersToCreate() []string {
+	return []string{GetRemoteNginxCertsDir()}
+}
@@ -10,8 +10,11 @@ import (
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
 	awsAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/aws"
+	azureAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/azure"
 	gcpAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/gcp"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/node/monitoring"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/proxy"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
@@ -40,13 +43,34 @@ type NodeParams struct {
 	// to gain access to the created nodes
 	SSHPrivateKeyPath string
 
+	// SSHJumpHost, if set, is the bastion the created nodes are reached through. The
+	// security group is then not required to allow SSH from the user IP
+	SSHJumpHost *SSHJumpHost
+
 	// AvalancheGoVersion is the version of Avalanche Go to install in the created node
 	AvalancheGoVersion string
 
+	// AvalancheGoImageDigest optionally pins the AvalancheGo docker image to the given
+	// sha256 digest (see ResolveDockerImageDigest). When set, the image is pulled by
+	// digest and verified on the node before avalanchego is started
+	AvalancheGoImageDigest string
+
 	// UseStaticIP is whether the created node should have static IP attached to it. Note that
 	// assigning Static IP to a node may incur additional charges on AWS / GCP. There could also be
 	// a limit to how many Static IPs you can have in a region in AWS & GCP.
 	UseStaticIP bool
+
+	// TLS, if set, provisions a Let's Encrypt certificate and a TLS reverse proxy for the
+	// RPC endpoint of API nodes
+	TLS *TLSParams
+
+	// StateSyncPeers, if set, are the only peers the created nodes state sync from,
+	// eg. other healthy nodes of the same cluster. See Cluster.AddNode
+	StateSyncPeers []StateSyncPeer
+
+	// MonitoringRetention sets how much data Monitor nodes keep. Defaults to
+	// monitoring.DefaultRetention. See monitoring.EstimateDisk to size their volume
+	MonitoringRetention *monitoring.Retention
 }
 
 // CreateNodes launches the specified number of nodes on the selected cloud platform.
@@ -84,7 +108,7 @@ func CreateNodes(
 	ctx context.Context,
 	nodeParams *NodeParams,
 ) ([]Node, error) {
-	nodes, err := createCloudInstances(ctx, *nodeParams.CloudParams, nodeParams.Count, nodeParams.UseStaticIP, nodeParams.SSHPrivateKeyPath)
+	nodes, err := createCloudInstances(ctx, *nodeParams.CloudParams, nodeParams.Count, nodeParams.UseStaticIP, nodeParams.SSHPrivateKeyPath, nodeParams.SSHJumpHost)
 	if err != nil {
 		return nil, err
 	}
@@ -111,13 +135,16 @@ func CreateNodes(
 }
 
 // preCreateCheck checks if the cloud parameters are valid.
-func preCreateCheck(cp CloudParams, count int, sshPrivateKeyPath string) error {
+func preCreateCheck(cp CloudParams, count int, useStaticIP bool, sshPrivateKeyPath string) error {
 	if count < 1 {
 		return fmt.Errorf("count must be at least 1")
 	}
 	if err := cp.Validate(); err != nil {
 		return err
 	}
+	if cp.Cloud() == AWSCloud && cp.AWSConfig.AWSDisablePublicIP && !useStaticIP {
+		return fmt.Errorf("AWS public IP can only be disabled for nodes with a static IP")
+	}
 	if sshPrivateKeyPath != "" && !utils.FileExists(sshPrivateKeyPath) {
 		return fmt.Errorf("ssh private key path %s does not exist", sshPrivateKeyPath)
 	}
@@ -125,8 +152,15 @@ func preCreateCheck(cp CloudParams, count int, sshPrivateKeyPath string) error {
 }
 
 // createCloudInstances launches the specified number of instances on the selected cloud platform.
-func createCloudInstances(ctx context.Context, cp CloudParams, count int, useStaticIP bool, sshPrivateKeyPath string) ([]Node, error) {
-	if err := preCreateCheck(cp, count, sshPrivateKeyPath); err != nil {
+func createCloudInstances(
+	ctx context.Context,
+	cp CloudParams,
+	count int,
+	useStaticIP bool,
+	sshPrivateKeyPath string,
+	jumpHost *SSHJumpHost,
+) ([]Node, error) {
+	if err := preCreateCheck(cp, count, useStaticIP, sshPrivateKeyPath); err != nil {
 		return nil, err
 	}
 	nodes := make([]Node, 0, count)
@@ -140,7 +174,10 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 		if err != nil {
 			return nil, err
 		}
-		instanceIds, err := ec2Svc.CreateEC2Instances(
+		if err := checkExistingAWSResources(ec2Svc, cp.AWSConfig, jumpHost); err != nil {
+			return nil, err
+		}
+		instanceIds, err := ec2Svc.CreateEC2InstancesWithOptions(
 			count,
 			cp.ImageID,
 			cp.InstanceType,
@@ -150,6 +187,7 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 			cp.AWSConfig.AWSVolumeThroughput,
 			cp.AWSConfig.AWSVolumeType,
 			cp.AWSConfig.AWSVolumeSize,
+			cp.AWSConfig.instanceOptions(),
 		)
 		if err != nil {
 			return nil, err
@@ -181,16 +219,25 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 			}
 		}
 		for _, instanceID := range instanceIds {
+			sshConfig := SSHConfig{
+				User:           constants.RemoteHostUser,
+				PrivateKeyPath: sshPrivateKeyPath,
+				JumpHost:       jumpHost,
+			}
+			if cp.AWSConfig.AWSVerifyNodeIdentity {
+				sshConfig.HostKeys, err = ec2Svc.GetInstanceHostKeys(instanceID)
+				if err != nil {
+					return nil, err
+				}
+				sshConfig.VerifyIdentity = true
+			}
 			nodes = append(nodes, Node{
 				NodeID:      instanceID,
 				IP:          instanceEIPMap[instanceID],
 				Cloud:       cp.Cloud(),
 				CloudConfig: cp,
-				SSHConfig: SSHConfig{
-					User:           constants.RemoteHostUser,
-					PrivateKeyPath: sshPrivateKeyPath,
-				},
-				Roles: nil,
+				SSHConfig:   sshConfig,
+				Roles:       nil,
 			})
 		}
 		return nodes, nil
@@ -203,9 +250,13 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 		if err != nil {
 			return nil, err
 		}
+		if err := ensureGCPNetworkAccess(gcpSvc, cp.GCPConfig); err != nil {
+			return nil, err
+		}
 		staticIPs := []string{}
 		if useStaticIP {
-			staticIPs, err = gcpSvc.SetPublicIP(cp.GCPConfig.GCPZone, "", count)
+			// static IP names must be unique in the project region
+			staticIPs, err = gcpSvc.SetPublicIP(cp.GCPConfig.GCPZone, utils.RandomString(5), count)
 			if err != nil {
 				return nil, err
 			}
@@ -217,7 +268,7 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 			cp.ImageID,
 			cp.InstanceType,
 			staticIPs,
-			1,
+			count,
 			cp.GCPConfig.GCPVolumeSize,
 		)
 		if err != nil {
@@ -226,17 +277,100 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 		if len(computeInstances) != count {
 			return nil, fmt.Errorf("failed to create all instances. Expected %d, got %d", count, len(computeInstances))
 		}
+		instanceNames := make([]string, 0, count)
 		for _, computeInstance := range computeInstances {
+			instanceNames = append(instanceNames, computeInstance.Name)
+		}
+		if err := gcpSvc.WaitForInstances(cp.GCPConfig.GCPZone, instanceNames, "RUNNING"); err != nil {
+			return nil, err
+		}
+		instanceIPMap, err := gcpSvc.GetInstancePublicIPs(cp.GCPConfig.GCPZone, instanceNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, instanceName := range instanceNames {
+			sshConfig := SSHConfig{
+				User:           constants.RemoteHostUser,
+				PrivateKeyPath: sshPrivateKeyPath,
+				JumpHost:       jumpHost,
+			}
+			if cp.GCPConfig.GCPVerifyNodeIdentity {
+				sshConfig.HostKeys, err = gcpSvc.GetInstanceHostKeys(cp.GCPConfig.GCPZone, instanceName)
+				if err != nil {
+					return nil, err
+				}
+				sshConfig.VerifyIdentity = true
+			}
+			nodes = append(nodes, Node{
+				NodeID:      instanceName,
+				IP:          instanceIPMap[instanceName],
+				Cloud:       cp.Cloud(),
+				CloudConfig: cp,
+				SSHConfig:   sshConfig,
+				Roles:       nil,
+			})
+		}
+	case AzureCloud:
+		azureSvc, err := azureAPI.NewAzureCloud(
+			ctx,
+			cp.AzureConfig.AzureSubscriptionID,
+			cp.AzureConfig.AzureResourceGroup,
+			cp.Region,
+		)
+		if err != nil {
+			return nil, err
+		}
+		nsgID, err := checkExistingAzureResources(azureSvc, cp.AzureConfig, jumpHost)
+		if err != nil {
+			return nil, err
+		}
+		subnetID, err := azureSvc.SetupNetwork(cp.AzureConfig.AzureNetwork)
+		if err != nil {
+			return nil, err
+		}
+		vmNames, err := azureSvc.CreateVMs(
+			count,
+			cp.ImageID,
+			cp.InstanceType,
+			cp.AzureConfig.AzureKeyName,
+			nsgID,
+			subnetID,
+			cp.AzureConfig.AzureVolumeSize,
+			azureAPI.VMOptions{
+				StaticPublicIP: useStaticIP,
+				DiskType:       cp.AzureConfig.AzureVolumeType,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := azureSvc.WaitForVMs(vmNames); err != nil {
+			return nil, err
+		}
+		vmIPMap, err := azureSvc.GetInstancePublicIPs(vmNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, vmName := range vmNames {
+			sshConfig := SSHConfig{
+				User:           constants.RemoteHostUser,
+				PrivateKeyPath: sshPrivateKeyPath,
+				JumpHost:       jumpHost,
+			}
+			if cp.AzureConfig.AzureVerifyNodeIdentity {
+				sshConfig.HostKeys, err = azureSvc.GetInstanceHostKeys(vmName)
+				if err != nil {
+					return nil, err
+				}
+				sshConfig.VerifyIdentity = true
+			}
 			nodes = append(nodes, Node{
-				NodeID:      computeInstance.Name,
-				IP:          computeInstance.NetworkInterfaces[0].NetworkIP,
+				NodeID:      vmName,
+				IP:          vmIPMap[vmName],
 				Cloud:       cp.Cloud(),
 				CloudConfig: cp,
-				SSHConfig: SSHConfig{
-					User:           constants.RemoteHostUser,
-					PrivateKeyPath: sshPrivateKeyPath,
-				},
-				Roles: nil,
+				SSHConfig:   sshConfig,
+				Roles:       nil,
 			})
 		}
 	default:
@@ -245,6 +379,56 @@ func createCloudInstances(ctx context.Context, cp CloudParams, count int, useSta
 	return nodes, nil
 }
 
+// sshSourceIP returns the IP the SSH connections to the created nodes come from, or
+// an empty string if they go through [jumpHost] or a proxy, as the source is then unknown
+func sshSourceIP(jumpHost *SSHJumpHost) (string, error) {
+	if jumpHost != nil || proxy.Default().Enabled() {
+		return "", nil
+	}
+	return utils.GetUserIPAddress()
+}
+
+// checkExistingAWSResources checks that the security group and key pair in [awsConfig]
+// exist and can be used by the nodes, filling in the security group name if not given
+func checkExistingAWSResources(ec2Svc *awsAPI.AwsCloud, awsConfig *AWSConfig, jumpHost *SSHJumpHost) error {
+	userIPAddress, err := sshSourceIP(jumpHost)
+	if err != nil {
+		return err
+	}
+	sg, err := ec2Svc.CheckExistingResources(awsConfig.AWSSecurityGroupID, awsConfig.AWSKeyPair, userIPAddress)
+	if err != nil {
+		return err
+	}
+	if awsConfig.AWSSecurityGroupName == "" && sg.GroupName != nil {
+		awsConfig.AWSSecurityGroupName = *sg.GroupName
+	}
+	return nil
+}
+
+// ensureGCPNetworkAccess makes sure the network in [gcpConfig] exists and allows SSH
+// access from the user IP, creating the network or the firewall rule if needed
+func ensureGCPNetworkAccess(gcpSvc *gcpAPI.GcpCloud, gcpConfig *GCPConfig) error {
+	userIPAddress, err := utils.GetUserIPAddress()
+	if err != nil {
+		return err
+	}
+	return gcpSvc.EnsureNetworkAccess(userIPAddress, gcpConfig.GCPNetwork)
+}
+
+// checkExistingAzureResources checks that the network security group and SSH public key
+// in [azureConfig] exist and can be used by the nodes. Returns the network security group ID
+func checkExistingAzureResources(azureSvc *azureAPI.AzureCloud, azureConfig *AzureConfig, jumpHost *SSHJumpHost) (string, error) {
+	userIPAddress, err := sshSourceIP(jumpHost)
+	if err != nil {
+		return "", err
+	}
+	nsg, err := azureSvc.CheckExistingResources(azureConfig.AzureSecurityGroup, azureConfig.AzureKeyName, userIPAddress)
+	if err != nil {
+		return "", err
+	}
+	return *nsg.ID, nil
+}
+
 // provisionHost provisions a host with the given roles.
 func provisionHost(node Node, nodeParams *NodeParams) error {
 	if err := CheckRoles(nodeParams.Roles); err != nil {
@@ -263,12 +447,17 @@ func provisionHost(node Node, nodeParams *NodeParams) error {
 			if err := provisionAvagoHost(node, nodeParams); err != nil {
 				return err
 			}
+			if nodeParams.TLS != nil {
+				if err := node.SetupTLS(*nodeParams.TLS); err != nil {
+					return err
+				}
+			}
 		case Loadtest:
 			if err := provisionLoadTestHost(node); err != nil {
 				return err
 			}
 		case Monitor:
-			if err := provisionMonitoringHost(node); err != nil {
+			if err := provisionMonitoringHost(node, nodeParams.MonitoringRetention); err != nil {
 				return err
 			}
 		case AWMRelayer:
@@ -294,7 +483,7 @@ func provisionAvagoHost(node Node, nodeParams *NodeParams) error {
 	if err := node.RunSSHSetupPromtailConfig("127.0.0.1", constants.AvalanchegoLokiPort, node.NodeID, "", ""); err != nil {
 		return err
 	}
-	if err := node.ComposeSSHSetupNode(nodeParams.Network.HRP(), nodeParams.SubnetIDs, nodeParams.AvalancheGoVersion, withMonitoring); err != nil {
+	if err := node.composeSSHSetupNode(nodeParams.Network.HRP(), nodeParams.SubnetIDs, nodeParams.AvalancheGoVersion, nodeParams.AvalancheGoImageDigest, nodeParams.StateSyncPeers, withMonitoring); err != nil {
 		return err
 	}
 	if err := node.StartDockerCompose(constants.SSHScriptTimeout); err != nil {
@@ -313,14 +502,18 @@ func provisionLoadTestHost(node Node) error { // stub
 	return nil
 }
 
-func provisionMonitoringHost(node Node) error {
+func provisionMonitoringHost(node Node, retention *monitoring.Retention) error {
+	if retention == nil {
+		defaultRetention := monitoring.DefaultRetention()
+		retention = &defaultRetention
+	}
 	if err := node.RunSSHSetupDockerService(); err != nil {
 		return err
 	}
 	if err := node.RunSSHSetupMonitoringFolders(); err != nil {
 		return err
 	}
-	if err := node.ComposeSSHSetupMonitoring(); err != nil {
+	if err := node.ComposeSSHSetupMonitoringWithRetention(*retention); err != nil {
 		return err
 	}
 	if err := node.RestartDockerCompose(constants.SSHScriptTimeout); err != nil {
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	awsAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/aws"
+	azureAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/azure"
 	gcpAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/gcp"
 )
 
@@ -33,7 +34,18 @@ func (h *Node) Destroy(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		return gcpSvc.DestroyGCPNode(h.CloudConfig.Region, h.NodeID)
+		return gcpSvc.DestroyGCPNode(h.CloudConfig.GCPConfig.GCPZone, h.NodeID)
+	case AzureCloud:
+		azureSvc, err := azureAPI.NewAzureCloud(
+			ctx,
+			h.CloudConfig.AzureConfig.AzureSubscriptionID,
+			h.CloudConfig.AzureConfig.AzureResourceGroup,
+			h.CloudConfig.Region,
+		)
+		if err != nil {
+			return err
+		}
+		return azureSvc.DestroyAzureNode(h.NodeID)
 	default:
 		return fmt.Errorf("unsupported cloud type: %s", h.Cloud.String())
 	}
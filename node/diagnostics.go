@@ -0,0 +1,310 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanchego/api/info"
+)
+
+const (
+	// minHealthyPeers is the peer count below which a node is considered to
+	// have connectivity problems
+	minHealthyPeers = 20
+	// p2pDialTimeout bounds the reachability check of the P2P port
+	p2pDialTimeout = 5 * time.Second
+	// publicIPService returns the IP the node egresses from
+	publicIPService = "https://checkip.amazonaws.com"
+
+	peersMetric           = "avalanche_network_peers"
+	inboundAcceptedMetric = "avalanche_network_inbound_conn_throttler_allowed"
+)
+
+// DiagnosticsSchemaVersion is the version of the Diagnostics JSON output. It is
+// increased on any incompatible change, so consumers can detect them
+const DiagnosticsSchemaVersion = 1
+
+// DiagnosticCheck is the outcome of one connectivity check
+type DiagnosticCheck struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+	// Detail describes what was observed
+	Detail string `json:"detail"`
+	// Suggestion describes how to fix a failed check
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Diagnostics contains the peer connectivity information gathered from a node
+type Diagnostics struct {
+	NodeID string
+	// P2PReachable is true if the P2P port could be dialed from outside the node
+	P2PReachable bool
+	// P2PListening is true if avalanchego is listening on the P2P port
+	P2PListening bool
+	// AdvertisedIP is the IP the node tells its peers to connect to
+	AdvertisedIP string
+	// PublicIP is the IP the node egresses from
+	PublicIP string
+	// Peers is the number of connected peers reported by the node metrics
+	Peers int
+	// InboundConnections and OutboundConnections are the currently established
+	// P2P connections started by peers and by the node respectively
+	InboundConnections  int
+	OutboundConnections int
+	// InboundAccepted is the number of inbound connections accepted since the
+	// node started
+	InboundAccepted uint64
+	Checks          []DiagnosticCheck
+}
+
+// Healthy returns true if all checks succeeded
+func (d *Diagnostics) Healthy() bool {
+	for _, check := range d.Checks {
+		if !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Suggestions returns the actions recommended to fix the failed checks
+func (d *Diagnostics) Suggestions() []string {
+	suggestions := []string{}
+	for _, check := range d.Checks {
+		if !check.OK && check.Suggestion != "" {
+			suggestions = append(suggestions, check.Suggestion)
+		}
+	}
+	return suggestions
+}
+
+type diagnosticsJSON struct {
+	SchemaVersion       int               `json:"schemaVersion"`
+	NodeID              string            `json:"nodeID"`
+	Healthy             bool              `json:"healthy"`
+	P2PReachable        bool              `json:"p2pReachable"`
+	P2PListening        bool              `json:"p2pListening"`
+	AdvertisedIP        string            `json:"advertisedIP"`
+	PublicIP            string            `json:"publicIP"`
+	Peers               int               `json:"peers"`
+	InboundConnections  int               `json:"inboundConnections"`
+	OutboundConnections int               `json:"outboundConnections"`
+	InboundAccepted     uint64            `json:"inboundAccepted"`
+	Checks              []DiagnosticCheck `json:"checks"`
+	Suggestions         []string          `json:"suggestions"`
+}
+
+// MarshalJSON serializes the diagnostics with a stable schema, versioned by
+// DiagnosticsSchemaVersion, that includes the overall health and suggestions
+func (d Diagnostics) MarshalJSON() ([]byte, error) {
+	checks := d.Checks
+	if checks == nil {
+		checks = []DiagnosticCheck{}
+	}
+	return json.Marshal(diagnosticsJSON{
+		SchemaVersion:       DiagnosticsSchemaVersion,
+		NodeID:              d.NodeID,
+		Healthy:             d.Healthy(),
+		P2PReachable:        d.P2PReachable,
+		P2PListening:        d.P2PListening,
+		AdvertisedIP:        d.AdvertisedIP,
+		PublicIP:            d.PublicIP,
+		Peers:               d.Peers,
+		InboundConnections:  d.InboundConnections,
+		OutboundConnections: d.OutboundConnections,
+		InboundAccepted:     d.InboundAccepted,
+		Checks:              checks,
+		Suggestions:         d.Suggestions(),
+	})
+}
+
+// Diagnostics checks the P2P connectivity of the avalanchego node: whether its P2P port
+// is reachable from outside, if it is advertising the right IP, and how many peers are
+// connected in each direction. Failing checks include suggestions on how to fix them
+func (h *Node) Diagnostics() (*Diagnostics, error) {
+	if h.IP == "" {
+		return nil, fmt.Errorf("node IP is empty")
+	}
+	d := &Diagnostics{NodeID: h.NodeID}
+	d.P2PReachable = dialP2P(h.IP)
+	listening, err := h.Commandf(nil, constants.SSHScriptTimeout, "ss -Hltn 'sport = :%d'", constants.AvalanchegoP2PPort)
+	if err != nil {
+		return nil, fmt.Errorf("failure checking listening ports on node %s: %w", h.NodeID, err)
+	}
+	d.P2PListening = strings.TrimSpace(string(listening)) != ""
+	connections, err := h.Command(nil, constants.SSHScriptTimeout, "ss -Htn state established")
+	if err != nil {
+		return nil, fmt.Errorf("failure listing connections on node %s: %w", h.NodeID, err)
+	}
+	d.InboundConnections, d.OutboundConnections = countP2PConnections(string(connections), constants.AvalanchegoP2PPort)
+	if resp, err := h.Post("", `{"jsonrpc":"2.0","id":1,"method":"info.getNodeIP"}`); err == nil {
+		d.AdvertisedIP, _ = parseNodeIPOutput(resp)
+	}
+	if output, err := h.Commandf(nil, constants.SSHScriptTimeout, "curl -s -m 5 %s", publicIPService); err == nil {
+		d.PublicIP = strings.TrimSpace(string(output))
+	}
+	if metrics, err := h.Commandf(nil, constants.SSHScriptTimeout, "curl -s -m 5 %s/ext/metrics", constants.LocalAPIEndpoint); err == nil {
+		if peers, found := metricValue(string(metrics), peersMetric); found {
+			d.Peers = int(peers)
+		}
+		if accepted, found := metricValue(string(metrics), inboundAcceptedMetric); found {
+			d.InboundAccepted = uint64(accepted)
+		}
+	}
+	d.Checks = d.evaluate(h.IP)
+	return d, nil
+}
+
+func dialP2P(ip string) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(constants.AvalanchegoP2PPort)), p2pDialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// evaluate turns the gathered data into checks for a node reached at [nodeIP]
+func (d *Diagnostics) evaluate(nodeIP string) []DiagnosticCheck {
+	checks := []DiagnosticCheck{}
+	switch {
+	case !d.P2PListening:
+		checks = append(checks, DiagnosticCheck{
+			Name:       "p2p-listening",
+			Detail:     fmt.Sprintf("avalanchego is not listening on port %d", constants.AvalanchegoP2PPort),
+			Suggestion: "check that avalanchego is running (docker ps) and review its logs",
+		})
+	case !d.P2PReachable:
+		checks = append(checks, DiagnosticCheck{
+			Name:       "p2p-reachable",
+			Detail:     fmt.Sprintf("port %d is open on the node but can't be reached at %s", constants.AvalanchegoP2PPort, nodeIP),
+			Suggestion: fmt.Sprintf("allow inbound TCP %d from anywhere in the cloud security group / firewall, and forward it if the node is behind NAT", constants.AvalanchegoP2PPort),
+		})
+	default:
+		checks = append(checks, DiagnosticCheck{
+			Name:   "p2p-reachable",
+			OK:     true,
+			Detail: fmt.Sprintf("port %d reachable at %s", constants.AvalanchegoP2PPort, nodeIP),
+		})
+	}
+
+	advertisedHost := d.AdvertisedIP
+	if host, _, err := net.SplitHostPort(d.AdvertisedIP); err == nil {
+		advertisedHost = host
+	}
+	switch {
+	case advertisedHost == "":
+		checks = append(checks, DiagnosticCheck{
+			Name:       "advertised-ip",
+			Detail:     "unable to obtain the IP advertised by the node",
+			Suggestion: "check that the avalanchego API is up (info.getNodeIP)",
+		})
+	case d.PublicIP != "" && advertisedHost != d.PublicIP:
+		checks = append(checks, DiagnosticCheck{
+			Name:       "advertised-ip",
+			Detail:     fmt.Sprintf("node advertises %s but its public IP is %s", advertisedHost, d.PublicIP),
+			Suggestion: fmt.Sprintf("set public-ip to %s in the node config (or use public-ip-resolution-service) and restart avalanchego", d.PublicIP),
+		})
+	case advertisedHost != nodeIP:
+		checks = append(checks, DiagnosticCheck{
+			Name:       "advertised-ip",
+			Detail:     fmt.Sprintf("node advertises %s but is being reached at %s", advertisedHost, nodeIP),
+			Suggestion: "make sure the advertised IP routes to the node, or update the node IP after a static IP change",
+		})
+	default:
+		checks = append(checks, DiagnosticCheck{
+			Name:   "advertised-ip",
+			OK:     true,
+			Detail: fmt.Sprintf("node advertises %s", advertisedHost),
+		})
+	}
+
+	if d.Peers < minHealthyPeers {
+		checks = append(checks, DiagnosticCheck{
+			Name:       "peers",
+			Detail:     fmt.Sprintf("only %d peers connected", d.Peers),
+			Suggestion: "check outbound connectivity to the network bootstrappers and that the node clock is synchronized",
+		})
+	} else {
+		checks = append(checks, DiagnosticCheck{
+			Name:   "peers",
+			OK:     true,
+			Detail: fmt.Sprintf("%d peers connected", d.Peers),
+		})
+	}
+
+	total := d.InboundConnections + d.OutboundConnections
+	if total > 0 && d.InboundConnections == 0 {
+		checks = append(checks, DiagnosticCheck{
+			Name:       "inbound-connections",
+			Detail:     fmt.Sprintf("all %d P2P connections are outbound, %d inbound connections accepted since start", total, d.InboundAccepted),
+			Suggestion: "peers are not able to dial the node: this usually means a NAT or firewall dropping inbound connections, or a wrong advertised IP",
+		})
+	} else {
+		checks = append(checks, DiagnosticCheck{
+			Name:   "inbound-connections",
+			OK:     true,
+			Detail: fmt.Sprintf("%d inbound / %d outbound P2P connections", d.InboundConnections, d.OutboundConnections),
+		})
+	}
+	return checks
+}
+
+// countP2PConnections counts the established connections in the output of
+// 'ss -Htn state established' that use [port] locally (inbound) or remotely (outbound)
+func countP2PConnections(ssOutput string, port int) (int, int) {
+	inbound, outbound := 0, 0
+	portSuffix := ":" + strconv.Itoa(port)
+	scanner := bufio.NewScanner(strings.NewReader(ssOutput))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Recv-Q Send-Q Local:Port Peer:Port [Process]
+		if len(fields) < 4 {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(fields[2], portSuffix):
+			inbound++
+		case strings.HasSuffix(fields[3], portSuffix):
+			outbound++
+		}
+	}
+	return inbound, outbound
+}
+
+// metricValue returns the value of the unlabeled prometheus metric [name]
+func metricValue(metrics string, name string) (float64, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(metrics))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != name {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		return value, true
+	}
+	return 0, false
+}
+
+func parseNodeIPOutput(byteValue []byte) (string, error) {
+	reply := struct {
+		Result info.GetNodeIPReply `json:"result"`
+	}{}
+	if err := json.Unmarshal(byteValue, &reply); err != nil {
+		return "", err
+	}
+	return reply.Result.IP, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountP2PConnections(t *testing.T) {
+	ssOutput := `0      0      10.0.0.5:9651      52.1.2.3:41234
+0      0      10.0.0.5:9651      52.1.2.4:41235
+0      0      10.0.0.5:53124     34.5.6.7:9651
+0      36     10.0.0.5:22        1.2.3.4:60000
+`
+	inbound, outbound := countP2PConnections(ssOutput, 9651)
+	require.Equal(t, 2, inbound)
+	require.Equal(t, 1, outbound)
+}
+
+func TestMetricValue(t *testing.T) {
+	require := require.New(t)
+	metrics := `# HELP avalanche_network_peers Number of network peers
+# TYPE avalanche_network_peers gauge
+avalanche_network_peers 42
+avalanche_network_peers_subnet{subnetID="abc"} 3
+avalanche_network_inbound_conn_throttler_allowed 1.5e+06
+`
+	value, found := metricValue(metrics, peersMetric)
+	require.True(found)
+	require.Equal(42.0, value)
+	value, found = metricValue(metrics, inboundAcceptedMetric)
+	require.True(found)
+	require.Equal(1.5e6, value)
+	_, found = metricValue(metrics, "avalanche_network_tracked")
+	require.False(found)
+}
+
+func TestParseNodeIPOutput(t *testing.T) {
+	ip, err := parseNodeIPOutput([]byte(`{"jsonrpc":"2.0","result":{"ip":"52.1.2.3:9651"},"id":1}`))
+	require.NoError(t, err)
+	require.Equal(t, "52.1.2.3:9651", ip)
+}
+
+func TestDiagnosticsEvaluate(t *testing.T) {
+	require := require.New(t)
+	d := &Diagnostics{
+		P2PReachable:        true,
+		P2PListening:        true,
+		AdvertisedIP:        "52.1.2.3:9651",
+		PublicIP:            "52.1.2.3",
+		Peers:               100,
+		InboundConnections:  40,
+		OutboundConnections: 60,
+	}
+	d.Checks = d.evaluate("52.1.2.3")
+	require.True(d.Healthy())
+	require.Empty(d.Suggestions())
+
+	// behind NAT: listening but not reachable, wrong advertised IP, no inbound peers
+	d = &Diagnostics{
+		P2PListening:        true,
+		AdvertisedIP:        "10.0.0.5:9651",
+		PublicIP:            "52.1.2.3",
+		Peers:               5,
+		OutboundConnections: 5,
+	}
+	d.Checks = d.evaluate("52.1.2.3")
+	require.False(d.Healthy())
+	failed := map[string]bool{}
+	for _, check := range d.Checks {
+		if !check.OK {
+			failed[check.Name] = true
+		}
+	}
+	require.Equal(map[string]bool{
+		"p2p-reachable":       true,
+		"advertised-ip":       true,
+		"peers":               true,
+		"inbound-connections": true,
+	}, failed)
+	require.Len(d.Suggestions(), 4)
+	require.Contains(d.Suggestions()[1], "public-ip to 52.1.2.3")
+
+	d = &Diagnostics{AdvertisedIP: "52.1.2.3:9651", Peers: 30}
+	d.Checks = d.evaluate("52.1.2.3")
+	require.Equal("p2p-listening", d.Checks[0].Name)
+	require.False(d.Checks[0].OK)
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	require := require.New(t)
+	d := Diagnostics{
+		NodeID:       "NodeID-1",
+		P2PListening: true,
+		Peers:        3,
+		Checks: []DiagnosticCheck{
+			{Name: "p2p-reachable", OK: true, Detail: "reachable"},
+			{Name: "peers", Detail: "only 3 peers connected", Suggestion: "check outbound connectivity"},
+		},
+	}
+	bytes, err := json.Marshal(&d)
+	require.NoError(err)
+	require.JSONEq(`{
+		"schemaVersion": 1,
+		"nodeID": "NodeID-1",
+		"healthy": false,
+		"p2pReachable": false,
+		"p2pListening": true,
+		"advertisedIP": "",
+		"publicIP": "",
+		"peers": 3,
+		"inboundConnections": 0,
+		"outboundConnections": 0,
+		"inboundAccepted": 0,
+		"checks": [
+			{"name": "p2p-reachable", "ok": true, "detail": "reachable"},
+			{"name": "peers", "ok": false, "detail": "only 3 peers connected", "suggestion": "check outbound connectivity"}
+		],
+		"suggestions": ["check outbound connectivity"]
+	}`, string(bytes))
+}
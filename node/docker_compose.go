@@ -5,6 +5,7 @@ package node
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -22,16 +23,25 @@ type dockerComposeInputs struct {
 	WithMonitoring     bool
 	WithAvalanchego    bool
 	AvalanchegoVersion string
+	AvalanchegoDigest  string
 	E2E                bool
 	E2EIP              string
 	E2ESuffix          string
+	// MetricsExporterPort is the port the business metrics exporter sidecar listens on
+	MetricsExporterPort int
+	// PrometheusFlags and LokiFlags set the retention of the monitoring services
+	PrometheusFlags []string
+	LokiFlags       []string
+	// HomeDir is the home directory of the node user, where the services config lives.
+	// It is set by ComposeOverSSH
+	HomeDir string
 }
 
 //go:embed templates/*.docker-compose.yml
 var composeTemplate embed.FS
 
 func renderComposeFile(composePath string, composeDesc string, templateVars dockerComposeInputs) ([]byte, error) {
-	compose, err := composeTemplate.ReadFile(composePath)
+	compose, err := readEmbedded(composeTemplate, composePath)
 	if err != nil {
 		return nil, err
 	}
@@ -154,13 +164,17 @@ func (h *Node) StopDockerCompose(timeout time.Duration) error {
 }
 
 func (h *Node) RestartDockerCompose(timeout time.Duration) error {
+	return h.restartDockerCompose(context.Background(), timeout)
+}
+
+func (h *Node) restartDockerCompose(ctx context.Context, timeout time.Duration) error {
 	if h.HasSystemDAvailable() {
-		if output, err := h.Command(nil, timeout, "sudo systemctl restart avalanche-cli-docker"); err != nil {
+		if output, err := h.CommandContext(ctx, nil, timeout, "sudo systemctl restart avalanche-cli-docker"); err != nil {
 			return fmt.Errorf("%w: %s", err, string(output))
 		}
 	} else {
 		composeFile := utils.GetRemoteComposeFile()
-		output, err := h.Commandf(nil, constants.SSHScriptTimeout, "docker compose -f %s restart", composeFile)
+		output, err := h.CommandContextf(ctx, nil, constants.SSHScriptTimeout, "docker compose -f %s restart", composeFile)
 		if err != nil {
 			return fmt.Errorf("%w: %s", err, string(output))
 		}
@@ -169,31 +183,47 @@ func (h *Node) RestartDockerCompose(timeout time.Duration) error {
 }
 
 func (h *Node) StartDockerComposeService(composeFile string, service string, timeout time.Duration) error {
-	if err := h.InitDockerComposeService(composeFile, service, timeout); err != nil {
+	return h.startDockerComposeService(context.Background(), composeFile, service, timeout)
+}
+
+func (h *Node) startDockerComposeService(ctx context.Context, composeFile string, service string, timeout time.Duration) error {
+	if err := h.initDockerComposeService(ctx, composeFile, service, timeout); err != nil {
 		return err
 	}
-	if output, err := h.Commandf(nil, timeout, "docker compose -f %s start %s", composeFile, service); err != nil {
+	if output, err := h.CommandContextf(ctx, nil, timeout, "docker compose -f %s start %s", composeFile, service); err != nil {
 		return fmt.Errorf("%w: %s", err, string(output))
 	}
 	return nil
 }
 
 func (h *Node) StopDockerComposeService(composeFile string, service string, timeout time.Duration) error {
-	if output, err := h.Commandf(nil, timeout, "docker compose -f %s stop %s", composeFile, service); err != nil {
+	return h.stopDockerComposeService(context.Background(), composeFile, service, timeout)
+}
+
+func (h *Node) stopDockerComposeService(ctx context.Context, composeFile string, service string, timeout time.Duration) error {
+	if output, err := h.CommandContextf(ctx, nil, timeout, "docker compose -f %s stop %s", composeFile, service); err != nil {
 		return fmt.Errorf("%w: %s", err, string(output))
 	}
 	return nil
 }
 
 func (h *Node) RestartDockerComposeService(composeFile string, service string, timeout time.Duration) error {
-	if output, err := h.Commandf(nil, timeout, "docker compose -f %s restart %s", composeFile, service); err != nil {
+	return h.restartDockerComposeService(context.Background(), composeFile, service, timeout)
+}
+
+func (h *Node) restartDockerComposeService(ctx context.Context, composeFile string, service string, timeout time.Duration) error {
+	if output, err := h.CommandContextf(ctx, nil, timeout, "docker compose -f %s restart %s", composeFile, service); err != nil {
 		return fmt.Errorf("%w: %s", err, string(output))
 	}
 	return nil
 }
 
 func (h *Node) InitDockerComposeService(composeFile string, service string, timeout time.Duration) error {
-	if output, err := h.Commandf(nil, timeout, "docker compose -f %s create %s", composeFile, service); err != nil {
+	return h.initDockerComposeService(context.Background(), composeFile, service, timeout)
+}
+
+func (h *Node) initDockerComposeService(ctx context.Context, composeFile string, service string, timeout time.Duration) error {
+	if output, err := h.CommandContextf(ctx, nil, timeout, "docker compose -f %s create %s", composeFile, service); err != nil {
 		return fmt.Errorf("%w: %s", err, string(output))
 	}
 	return nil
@@ -213,6 +243,7 @@ func (h *Node) ComposeOverSSH(
 		return err
 	}
 	defer os.Remove(tmpFile.Name())
+	composeVars.HomeDir = h.ExpandHome("")
 	composeData, err := renderComposeFile(composePath, composeDesc, composeVars)
 	if err != nil {
 		return err
@@ -15,7 +15,18 @@ import (
 // networkID is the ID of the network to be used
 // trackSubnets is the list of subnets to track
 func (h *Node) RunSSHRenderAvalancheNodeConfig(networkID string, trackSubnets []string) error {
+	return h.renderAvalancheNodeConfig(networkID, trackSubnets, nil)
+}
+
+// RunSSHRenderAvalancheNodeConfigWithStateSyncPeers is equivalent to
+// RunSSHRenderAvalancheNodeConfig, but makes the node state sync only from [peers]
+func (h *Node) RunSSHRenderAvalancheNodeConfigWithStateSyncPeers(networkID string, trackSubnets []string, peers []StateSyncPeer) error {
+	return h.renderAvalancheNodeConfig(networkID, trackSubnets, peers)
+}
+
+func (h *Node) renderAvalancheNodeConfig(networkID string, trackSubnets []string, stateSyncPeers []StateSyncPeer) error {
 	avagoConf := remoteconfig.PrepareAvalancheConfig(h.IP, networkID, trackSubnets)
+	avagoConf.StateSyncIDs, avagoConf.StateSyncIPs = stateSyncFlags(stateSyncPeers)
 
 	nodeConf, err := remoteconfig.RenderAvalancheNodeConfig(avagoConf)
 	if err != nil {
@@ -0,0 +1,272 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+)
+
+const (
+	dockerHubDomain   = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+	defaultDockerTag  = "latest"
+	digestPrefix      = "sha256:"
+)
+
+// media types accepted when resolving a digest, so the registry answers with
+// the multi platform index digest instead of converting it to a single manifest
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var (
+	ErrInvalidDockerDigest   = errors.New("invalid docker image digest")
+	ErrDockerDigestMismatch  = errors.New("docker image digest mismatch")
+	ErrDockerDigestNotFound  = errors.New("registry did not return a digest for the docker image")
+	ErrInvalidDockerImageRef = errors.New("invalid docker image reference")
+)
+
+// imageReference is a docker image name split into its components
+type imageReference struct {
+	Domain     string
+	Repository string
+	Tag        string
+}
+
+// parseImageReference splits [image] as docker does, defaulting the domain to
+// docker hub and the tag to latest
+func parseImageReference(image string) (imageReference, error) {
+	ref := imageReference{Domain: dockerHubDomain, Tag: defaultDockerTag}
+	if image == "" || strings.Contains(image, "@") {
+		return ref, fmt.Errorf("%w: %q", ErrInvalidDockerImageRef, image)
+	}
+	name := image
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, ref.Tag = image[:i], image[i+1:]
+	}
+	if parts := strings.SplitN(name, "/", 2); len(parts) == 2 &&
+		(strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Domain, name = parts[0], parts[1]
+	}
+	if ref.Domain == dockerHubDomain && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	if name == "" || ref.Tag == "" {
+		return ref, fmt.Errorf("%w: %q", ErrInvalidDockerImageRef, image)
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+func (r imageReference) registryURL() string {
+	if r.Domain == dockerHubDomain {
+		return "https://" + dockerHubRegistry
+	}
+	return "https://" + r.Domain
+}
+
+// PinnedDockerImage returns the reference to [image] pinned to [digest], as
+// accepted by docker pull and docker compose
+func PinnedDockerImage(image string, digest string) string {
+	if digest == "" {
+		return image
+	}
+	return image + "@" + digest
+}
+
+func validateDigest(digest string) error {
+	hex := strings.TrimPrefix(digest, digestPrefix)
+	if hex == digest || len(hex) != 64 || strings.Trim(hex, "0123456789abcdef") != "" {
+		return fmt.Errorf("%w: %q", ErrInvalidDockerDigest, digest)
+	}
+	return nil
+}
+
+// ResolveDockerImageDigest asks the registry of [image] for the digest its tag
+// currently points to. The result can be used to pin the image so that later
+// changes to the tag are not deployed
+func ResolveDockerImageDigest(ctx context.Context, image string) (string, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return "", err
+	}
+	return resolveDockerImageDigest(ctx, http.DefaultClient, ref.registryURL(), ref)
+}
+
+func resolveDockerImageDigest(ctx context.Context, client *http.Client, registryURL string, ref imageReference) (string, error) {
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", registryURL, ref.Repository, ref.Tag)
+	resp, err := manifestHead(ctx, client, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := registryToken(ctx, client, resp.Header.Get("Www-Authenticate"), ref.Repository)
+		if err != nil {
+			return "", err
+		}
+		if resp, err = manifestHead(ctx, client, manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failure resolving digest for %s:%s: registry returned %s", ref.Repository, ref.Tag, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("%w: %s:%s", ErrDockerDigestNotFound, ref.Repository, ref.Tag)
+	}
+	if err := validateDigest(digest); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+func manifestHead(ctx context.Context, client *http.Client, manifestURL string, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// parseBearerChallenge parses a Www-Authenticate header of the form
+// Bearer realm="...",service="...",scope="..."
+func parseBearerChallenge(header string) (map[string]string, error) {
+	params, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, fmt.Errorf("unsupported registry auth challenge %q", header)
+	}
+	challenge := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			continue
+		}
+		challenge[key] = strings.Trim(value, `"`)
+	}
+	if challenge["realm"] == "" {
+		return nil, fmt.Errorf("registry auth challenge without realm %q", header)
+	}
+	return challenge, nil
+}
+
+// registryToken obtains an anonymous pull token for [repository]
+func registryToken(ctx context.Context, client *http.Client, authHeader string, repository string) (string, error) {
+	challenge, err := parseBearerChallenge(authHeader)
+	if err != nil {
+		return "", err
+	}
+	tokenURL, err := url.Parse(challenge["realm"])
+	if err != nil {
+		return "", err
+	}
+	query := tokenURL.Query()
+	if service := challenge["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := challenge["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", repository)
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failure obtaining registry token: %s", resp.Status)
+	}
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+	return tokenResp.AccessToken, nil
+}
+
+// GetDockerImageDigests returns the registry digests of [image] as stored on
+// the remote node, in the form repository@sha256:...
+func (h *Node) GetDockerImageDigests(image string) ([]string, error) {
+	output, err := h.Commandf(nil, constants.SSHScriptTimeout, "docker image inspect --format '{{json .RepoDigests}}' %s", image)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, string(output))
+	}
+	var repoDigests []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &repoDigests); err != nil {
+		return nil, err
+	}
+	return repoDigests, nil
+}
+
+// checkRepoDigests verifies that [digest] is among the [repoDigests] reported
+// by docker for [image]
+func checkRepoDigests(image string, digest string, repoDigests []string) error {
+	for _, repoDigest := range repoDigests {
+		if _, d, found := strings.Cut(repoDigest, "@"); found && d == digest {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s expected %s, found %v", ErrDockerDigestMismatch, image, digest, repoDigests)
+}
+
+// VerifyDockerImageDigest checks that the content of [image] on the remote
+// node matches [digest]
+func (h *Node) VerifyDockerImageDigest(image string, digest string) error {
+	if err := validateDigest(digest); err != nil {
+		return err
+	}
+	repoDigests, err := h.GetDockerImageDigests(PinnedDockerImage(image, digest))
+	if err != nil {
+		return err
+	}
+	return checkRepoDigests(image, digest, repoDigests)
+}
+
+// PreparePinnedDockerImage pulls [image] on the remote node by [digest] and
+// verifies the pulled content. Unlike PrepareDockerImageWithRepo it never
+// falls back to building the image, as a local build can't match the digest
+func (h *Node) PreparePinnedDockerImage(image string, digest string) error {
+	if err := validateDigest(digest); err != nil {
+		return err
+	}
+	if err := h.PullDockerImage(PinnedDockerImage(image, digest)); err != nil {
+		return err
+	}
+	if err := h.VerifyDockerImageDigest(image, digest); err != nil {
+		return err
+	}
+	h.Logger.Infof("Docker image %s verified with digest %s on %s", image, digest, h.NodeID)
+	return nil
+}
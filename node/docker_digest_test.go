@@ -0,0 +1,102 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected imageReference
+	}{
+		{"nginx", imageReference{Domain: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"avaplatform/avalanchego:v1.11.5", imageReference{Domain: "docker.io", Repository: "avaplatform/avalanchego", Tag: "v1.11.5"}},
+		{"ghcr.io/ava-labs/awm-relayer:v1", imageReference{Domain: "ghcr.io", Repository: "ava-labs/awm-relayer", Tag: "v1"}},
+		{"localhost:5000/relayer", imageReference{Domain: "localhost:5000", Repository: "relayer", Tag: "latest"}},
+	}
+	for _, tt := range tests {
+		ref, err := parseImageReference(tt.image)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, ref)
+	}
+	_, err := parseImageReference("nginx@" + testDigest)
+	require.ErrorIs(t, err, ErrInvalidDockerImageRef)
+	_, err = parseImageReference("nginx:")
+	require.ErrorIs(t, err, ErrInvalidDockerImageRef)
+}
+
+func TestResolveDockerImageDigest(t *testing.T) {
+	require := require.New(t)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			require.Equal("repository:avaplatform/avalanchego:pull", r.URL.Query().Get("scope"))
+			require.Equal("registry.test", r.URL.Query().Get("service"))
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+		case "/v2/avaplatform/avalanchego/manifests/v1.11.5":
+			require.Equal(http.MethodHead, r.Method)
+			require.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.test"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ref, err := parseImageReference("avaplatform/avalanchego:v1.11.5")
+	require.NoError(err)
+	digest, err := resolveDockerImageDigest(context.Background(), server.Client(), server.URL, ref)
+	require.NoError(err)
+	require.Equal(testDigest, digest)
+
+	ref.Tag = "missing"
+	_, err = resolveDockerImageDigest(context.Background(), server.Client(), server.URL, ref)
+	require.ErrorContains(err, "404")
+}
+
+func TestCheckRepoDigests(t *testing.T) {
+	require := require.New(t)
+	image := "avaplatform/avalanchego:v1.11.5"
+	require.NoError(checkRepoDigests(image, testDigest, []string{"avaplatform/avalanchego@" + testDigest}))
+	otherDigest := "sha256:" + strings.Repeat("0", 64)
+	require.ErrorIs(checkRepoDigests(image, testDigest, []string{"avaplatform/avalanchego@" + otherDigest}), ErrDockerDigestMismatch)
+	require.ErrorIs(checkRepoDigests(image, testDigest, nil), ErrDockerDigestMismatch)
+	require.ErrorIs(validateDigest("sha256:1234"), ErrInvalidDockerDigest)
+	require.ErrorIs(validateDigest(strings.Repeat("a", 64)), ErrInvalidDockerDigest)
+	require.NoError(validateDigest(testDigest))
+}
+
+func TestRenderPinnedAvalanchegoCompose(t *testing.T) {
+	require := require.New(t)
+	compose, err := renderComposeFile("templates/avalanchego.docker-compose.yml", "Compose Node", dockerComposeInputs{
+		AvalanchegoVersion: "v1.11.5",
+		AvalanchegoDigest:  testDigest,
+		WithAvalanchego:    true,
+	})
+	require.NoError(err)
+	require.Contains(string(compose), "image: avaplatform/avalanchego:v1.11.5@"+testDigest)
+	compose, err = renderComposeFile("templates/avalanchego.docker-compose.yml", "Compose Node", dockerComposeInputs{
+		AvalanchegoVersion: "v1.11.5",
+		WithAvalanchego:    true,
+	})
+	require.NoError(err)
+	require.Contains(string(compose), "image: avaplatform/avalanchego:v1.11.5\n")
+}
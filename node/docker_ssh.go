@@ -11,6 +11,7 @@ import (
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
 	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/node/monitoring"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 )
 
@@ -24,6 +25,17 @@ func (h *Node) ValidateComposeFile(composeFile string, timeout time.Duration) er
 
 // ComposeSSHSetupNode sets up an AvalancheGo node and dependencies on a remote node over SSH.
 func (h *Node) ComposeSSHSetupNode(networkID string, subnetsToTrack []string, avalancheGoVersion string, withMonitoring bool) error {
+	return h.composeSSHSetupNode(networkID, subnetsToTrack, avalancheGoVersion, "", nil, withMonitoring)
+}
+
+// ComposeSSHSetupPinnedNode is equivalent to ComposeSSHSetupNode, but pins the AvalancheGo
+// docker image to [avalancheGoDigest], refusing to start the node if the image pulled
+// on the remote node does not match it
+func (h *Node) ComposeSSHSetupPinnedNode(networkID string, subnetsToTrack []string, avalancheGoVersion string, avalancheGoDigest string, withMonitoring bool) error {
+	return h.composeSSHSetupNode(networkID, subnetsToTrack, avalancheGoVersion, avalancheGoDigest, nil, withMonitoring)
+}
+
+func (h *Node) composeSSHSetupNode(networkID string, subnetsToTrack []string, avalancheGoVersion string, avalancheGoDigest string, stateSyncPeers []StateSyncPeer, withMonitoring bool) error {
 	startTime := time.Now()
 	folderStructure := remoteconfig.RemoteFoldersToCreateAvalanchego()
 	for _, dir := range folderStructure {
@@ -34,11 +46,11 @@ func (h *Node) ComposeSSHSetupNode(networkID string, subnetsToTrack []string, av
 	h.Logger.Infof("avalancheCLI folder structure created on remote node %s after %s", folderStructure, time.Since(startTime))
 	avagoDockerImage := fmt.Sprintf("%s:%s", constants.AvalancheGoDockerImage, avalancheGoVersion)
 	h.Logger.Infof("Preparing AvalancheGo Docker image %s on %s[%s]", avagoDockerImage, h.NodeID, h.IP)
-	if err := h.PrepareDockerImageWithRepo(avagoDockerImage, constants.AvalancheGoGitRepo, avalancheGoVersion); err != nil {
+	if err := h.prepareAvalancheGoImage(avagoDockerImage, avalancheGoVersion, avalancheGoDigest); err != nil {
 		return err
 	}
 	h.Logger.Infof("AvalancheGo Docker image %s ready on %s[%s] after %s", avagoDockerImage, h.NodeID, h.IP, time.Since(startTime))
-	if err := h.RunSSHRenderAvalancheNodeConfig(networkID, subnetsToTrack); err != nil {
+	if err := h.renderAvalancheNodeConfig(networkID, subnetsToTrack, stateSyncPeers); err != nil {
 		return err
 	}
 	h.Logger.Infof("AvalancheGo configs uploaded to %s[%s] after %s", h.NodeID, h.IP, time.Since(startTime))
@@ -47,6 +59,7 @@ func (h *Node) ComposeSSHSetupNode(networkID string, subnetsToTrack []string, av
 		"templates/avalanchego.docker-compose.yml",
 		dockerComposeInputs{
 			AvalanchegoVersion: avalancheGoVersion,
+			AvalanchegoDigest:  avalancheGoDigest,
 			WithMonitoring:     withMonitoring,
 			WithAvalanchego:    true,
 			E2E:                utils.IsE2E(),
@@ -55,6 +68,15 @@ func (h *Node) ComposeSSHSetupNode(networkID string, subnetsToTrack []string, av
 		})
 }
 
+// prepareAvalancheGoImage makes the AvalancheGo image available on the node, either
+// pulling it by digest if pinned, or pulling or building it from the given version
+func (h *Node) prepareAvalancheGoImage(image string, avalancheGoVersion string, avalancheGoDigest string) error {
+	if avalancheGoDigest != "" {
+		return h.PreparePinnedDockerImage(image, avalancheGoDigest)
+	}
+	return h.PrepareDockerImageWithRepo(image, constants.AvalancheGoGitRepo, avalancheGoVersion)
+}
+
 func (h *Node) ComposeSSHSetupLoadTest() error {
 	return h.ComposeOverSSH("Compose Node",
 		constants.SSHScriptTimeout,
@@ -70,8 +92,19 @@ func (h *Node) WasNodeSetupWithMonitoring() (bool, error) {
 	return h.HasRemoteComposeService(utils.GetRemoteComposeFile(), constants.ServicePromtail, constants.SSHScriptTimeout)
 }
 
-// ComposeSSHSetupMonitoring sets up monitoring using docker-compose.
+// ComposeSSHSetupMonitoring sets up monitoring using docker-compose, with the default
+// retention. See ComposeSSHSetupMonitoringWithRetention
 func (h *Node) ComposeSSHSetupMonitoring() error {
+	return h.ComposeSSHSetupMonitoringWithRetention(monitoring.DefaultRetention())
+}
+
+// ComposeSSHSetupMonitoringWithRetention sets up monitoring using docker-compose,
+// keeping metrics and logs as set by [retention]. See monitoring.EstimateDisk to size
+// the monitoring host volume
+func (h *Node) ComposeSSHSetupMonitoringWithRetention(retention monitoring.Retention) error {
+	if err := retention.Validate(); err != nil {
+		return err
+	}
 	grafanaConfigFile, grafanaDashboardsFile, grafanaLokiDatasourceFile, grafanaPromDatasourceFile, err := prepareGrafanaConfig()
 	if err != nil {
 		return err
@@ -111,7 +144,10 @@ func (h *Node) ComposeSSHSetupMonitoring() error {
 	return h.ComposeOverSSH("Setup Monitoring",
 		constants.SSHScriptTimeout,
 		"templates/monitoring.docker-compose.yml",
-		dockerComposeInputs{})
+		dockerComposeInputs{
+			PrometheusFlags: retention.PrometheusFlags(),
+			LokiFlags:       retention.LokiFlags(),
+		})
 }
 
 func (h *Node) ComposeSSHSetupAWMRelayer() error {
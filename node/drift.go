@@ -0,0 +1,242 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+)
+
+// DesiredState is the configuration all the nodes of a cluster are expected to have.
+// Empty fields are not checked
+type DesiredState struct {
+	// AvalancheGoVersion as reported by info.getNodeVersion, eg. avalanchego/1.11.5
+	AvalancheGoVersion string
+
+	// TrackedSubnets is the set of subnet IDs the nodes track, in any order
+	TrackedSubnets []string
+
+	// ChainConfigs is the content of the chain config files, by blockchain ID
+	ChainConfigs map[string][]byte
+
+	// DockerImages is the expected digest of the docker images, by image name
+	DockerImages map[string]string
+}
+
+// NodeState is the configuration of a node collected by ConfigDrift
+type NodeState struct {
+	NodeID             string
+	AvalancheGoVersion string
+	TrackedSubnets     []string
+	// ChainConfigs is the sha256 of the chain config files, by blockchain ID.
+	// Missing files have an empty hash
+	ChainConfigs map[string]string
+	// DockerImageDigests are the repository digests of the docker images, by image name
+	DockerImageDigests map[string][]string
+}
+
+// Drift is a difference between the desired state and the state of a node
+type Drift struct {
+	NodeID   string
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s %s: expected %s, found %s", d.NodeID, d.Field, d.Expected, d.Actual)
+}
+
+// DriftReport is the result of comparing the state of the nodes of a cluster with
+// a desired state
+type DriftReport struct {
+	// States collected from the nodes that could be inspected
+	States []NodeState
+	Drifts []Drift
+	// Errors of the nodes that could not be inspected, by node ID
+	Errors map[string]error
+}
+
+// InSync tells if all the nodes could be inspected and no drift was found
+func (r DriftReport) InSync() bool {
+	return len(r.Drifts) == 0 && len(r.Errors) == 0
+}
+
+func (r DriftReport) String() string {
+	if r.InSync() {
+		return fmt.Sprintf("%d nodes are in sync", len(r.States))
+	}
+	lines := []string{fmt.Sprintf("%d drifts found on %d nodes, %d nodes failed:", len(r.Drifts), len(r.States), len(r.Errors))}
+	for _, d := range r.Drifts {
+		lines = append(lines, "  "+d.String())
+	}
+	nodeIDs := make([]string, 0, len(r.Errors))
+	for nodeID := range r.Errors {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	for _, nodeID := range nodeIDs {
+		lines = append(lines, fmt.Sprintf("  %s: %s", nodeID, r.Errors[nodeID]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func configHash(config []byte) string {
+	hash := sha256.Sum256(config)
+	return hex.EncodeToString(hash[:])
+}
+
+// parseTrackedSubnets splits the comma separated track-subnets value of node.json
+func parseTrackedSubnets(trackSubnets string) []string {
+	subnets := []string{}
+	for _, subnet := range strings.Split(trackSubnets, ",") {
+		if subnet = strings.TrimSpace(subnet); subnet != "" {
+			subnets = append(subnets, subnet)
+		}
+	}
+	sort.Strings(subnets)
+	return utils.Unique(subnets)
+}
+
+// GetConfigState collects the parts of the node configuration checked by [desired]
+func (h *Node) GetConfigState(desired DesiredState) (NodeState, error) {
+	state := NodeState{NodeID: h.NodeID}
+	var err error
+	if desired.AvalancheGoVersion != "" {
+		if state.AvalancheGoVersion, err = h.GetAvalancheGoVersion(); err != nil {
+			return state, err
+		}
+	}
+	if desired.TrackedSubnets != nil {
+		avagoConfig, err := h.GetAvalancheGoConfigData()
+		if err != nil {
+			return state, err
+		}
+		trackSubnets := ""
+		if _, ok := avagoConfig["track-subnets"]; ok {
+			if trackSubnets, err = utils.StringValue(avagoConfig, "track-subnets"); err != nil {
+				return state, err
+			}
+		}
+		state.TrackedSubnets = parseTrackedSubnets(trackSubnets)
+	}
+	if len(desired.ChainConfigs) > 0 {
+		state.ChainConfigs = map[string]string{}
+		for blockchainID := range desired.ChainConfigs {
+			remoteChainConfig := remoteconfig.GetRemoteAvalancheChainConfig(blockchainID)
+			exists, err := h.FileExists(remoteChainConfig)
+			if err != nil {
+				return state, err
+			}
+			if !exists {
+				state.ChainConfigs[blockchainID] = ""
+				continue
+			}
+			chainConfig, err := h.ReadFileBytes(remoteChainConfig, constants.SSHFileOpsTimeout)
+			if err != nil {
+				return state, err
+			}
+			state.ChainConfigs[blockchainID] = configHash(chainConfig)
+		}
+	}
+	if len(desired.DockerImages) > 0 {
+		state.DockerImageDigests = map[string][]string{}
+		for image := range desired.DockerImages {
+			repoDigests, err := h.GetDockerImageDigests(image)
+			if err != nil {
+				return state, err
+			}
+			state.DockerImageDigests[image] = repoDigests
+		}
+	}
+	return state, nil
+}
+
+// compareState returns the drifts of [state] from [desired]
+func compareState(desired DesiredState, state NodeState) []Drift {
+	drifts := []Drift{}
+	addDrift := func(field string, expected string, actual string) {
+		drifts = append(drifts, Drift{NodeID: state.NodeID, Field: field, Expected: expected, Actual: actual})
+	}
+	if desired.AvalancheGoVersion != "" && state.AvalancheGoVersion != desired.AvalancheGoVersion {
+		addDrift("avalanchego version", desired.AvalancheGoVersion, state.AvalancheGoVersion)
+	}
+	if desired.TrackedSubnets != nil {
+		expected := parseTrackedSubnets(strings.Join(desired.TrackedSubnets, ","))
+		actual := parseTrackedSubnets(strings.Join(state.TrackedSubnets, ","))
+		if strings.Join(expected, ",") != strings.Join(actual, ",") {
+			addDrift("tracked subnets", fmt.Sprintf("%v", expected), fmt.Sprintf("%v", actual))
+		}
+	}
+	blockchainIDs := make([]string, 0, len(desired.ChainConfigs))
+	for blockchainID := range desired.ChainConfigs {
+		blockchainIDs = append(blockchainIDs, blockchainID)
+	}
+	sort.Strings(blockchainIDs)
+	for _, blockchainID := range blockchainIDs {
+		expected := configHash(desired.ChainConfigs[blockchainID])
+		actual := state.ChainConfigs[blockchainID]
+		if actual == "" {
+			actual = "no config"
+		}
+		if actual != expected {
+			addDrift(fmt.Sprintf("chain config %s", blockchainID), "sha256 "+expected, actual)
+		}
+	}
+	images := make([]string, 0, len(desired.DockerImages))
+	for image := range desired.DockerImages {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		digest := desired.DockerImages[image]
+		if err := checkRepoDigests(image, digest, state.DockerImageDigests[image]); err != nil {
+			addDrift(fmt.Sprintf("docker image %s", image), digest, fmt.Sprintf("%v", state.DockerImageDigests[image]))
+		}
+	}
+	return drifts
+}
+
+// ConfigDrift collects the avalanchego version, tracked subnets, chain configs and docker
+// image digests of all the nodes of the cluster in parallel, and reports how they
+// drift from [desired]. Nodes that can't be inspected are reported on Errors, so a
+// reconcile loop can act on a partial view of the fleet
+func (c *Cluster) ConfigDrift(desired DesiredState) DriftReport {
+	results := NodeResultsOf[NodeState]{}
+	wg := sync.WaitGroup{}
+	for i := range c.Nodes {
+		wg.Add(1)
+		go func(h *Node) {
+			defer wg.Done()
+			results.Run(h.NodeID, func() (NodeState, error) {
+				return h.GetConfigState(desired)
+			})
+		}(&c.Nodes[i])
+	}
+	wg.Wait()
+	report := DriftReport{
+		States: []NodeState{},
+		Drifts: []Drift{},
+		Errors: results.GetErrorHostMap(),
+	}
+	states := results.GetResults()
+	sort.Slice(states, func(i, j int) bool { return states[i].NodeID < states[j].NodeID })
+	for _, result := range states {
+		if result.Err != nil {
+			continue
+		}
+		report.States = append(report.States, result.Value)
+		report.Drifts = append(report.Drifts, compareState(desired, result.Value)...)
+	}
+	return report
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareState(t *testing.T) {
+	require := require.New(t)
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	chainConfig := []byte(`{"log-level":"info"}`)
+	desired := DesiredState{
+		AvalancheGoVersion: "avalanchego/1.11.5",
+		TrackedSubnets:     []string{"subnetB", "subnetA"},
+		ChainConfigs:       map[string][]byte{"chainA": chainConfig},
+		DockerImages:       map[string]string{"avaplatform/avalanchego:v1.11.5": digest},
+	}
+	inSync := NodeState{
+		NodeID:             "node1",
+		AvalancheGoVersion: "avalanchego/1.11.5",
+		TrackedSubnets:     parseTrackedSubnets("subnetA, subnetB,subnetA"),
+		ChainConfigs:       map[string]string{"chainA": configHash(chainConfig)},
+		DockerImageDigests: map[string][]string{"avaplatform/avalanchego:v1.11.5": {"avaplatform/avalanchego@" + digest}},
+	}
+	require.Empty(compareState(desired, inSync))
+	require.Empty(compareState(DesiredState{}, NodeState{NodeID: "node2"}))
+
+	drifted := NodeState{
+		NodeID:             "node2",
+		AvalancheGoVersion: "avalanchego/1.11.4",
+		TrackedSubnets:     []string{"subnetA"},
+		ChainConfigs:       map[string]string{"chainA": ""},
+		DockerImageDigests: map[string][]string{},
+	}
+	drifts := compareState(desired, drifted)
+	require.Len(drifts, 4)
+	require.Equal(Drift{NodeID: "node2", Field: "avalanchego version", Expected: "avalanchego/1.11.5", Actual: "avalanchego/1.11.4"}, drifts[0])
+	require.Equal("[subnetA subnetB]", drifts[1].Expected)
+	require.Equal("no config", drifts[2].Actual)
+	require.Equal(digest, drifts[3].Expected)
+
+	report := DriftReport{States: []NodeState{inSync}}
+	require.True(report.InSync())
+	require.Equal("1 nodes are in sync", report.String())
+	report.States = append(report.States, drifted)
+	report.Drifts = drifts
+	require.False(report.InSync())
+	require.True(strings.HasPrefix(report.String(), "4 drifts found on 2 nodes, 0 nodes failed:\n  node2 avalanchego version"))
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+)
+
+var (
+	ErrHostKeyMismatch         = errors.New("SSH host key does not match the known good host keys")
+	ErrNodeIdentityMismatch    = errors.New("node is not the expected cloud instance")
+	ErrIdentityNotVerifiable   = errors.New("node identity can't be verified")
+	ErrInvalidKnownGoodHostKey = errors.New("invalid known good SSH host key")
+)
+
+const (
+	// awsInstanceIDScript reads the instance ID from the EC2 metadata service, using a
+	// IMDSv2 session token when available
+	awsInstanceIDScript = `TOKEN=$(curl -s -m 5 -X PUT http://169.254.169.254/latest/api/token -H "X-aws-ec2-metadata-token-ttl-seconds: 60")
+if [ -n "$TOKEN" ]; then
+	curl -s -f -m 5 -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/instance-id
+else
+	curl -s -f -m 5 http://169.254.169.254/latest/meta-data/instance-id
+fi`
+	// azureVMNameScript reads the VM name from the Azure instance metadata service
+	azureVMNameScript = `curl -s -f -m 5 -H "Metadata: true" "http://169.254.169.254/metadata/instance/compute/name?api-version=2021-02-01&format=text"`
+	// gcpInstanceNameScript reads the instance name from the GCP metadata server
+	gcpInstanceNameScript = `curl -s -f -m 5 -H "Metadata-Flavor: Google" http://metadata.google.internal/computeMetadata/v1/instance/name`
+)
+
+// hostKeyCallback returns the callback checking the node host key against HostKeys.
+// Without HostKeys, the host key is not verified
+func (c SSHConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if len(c.HostKeys) == 0 {
+		// #nosec G106
+		return ssh.InsecureIgnoreHostKey(), nil // we don't verify node key ( similar to ansible)
+	}
+	knownKeys := make([][]byte, 0, len(c.HostKeys))
+	for _, hostKey := range c.HostKeys {
+		pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidKnownGoodHostKey, hostKey, err)
+		}
+		knownKeys = append(knownKeys, pubKey.Marshal())
+	}
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		for _, knownKey := range knownKeys {
+			if bytes.Equal(knownKey, key.Marshal()) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s presented %s %s", ErrHostKeyMismatch, hostname, key.Type(), ssh.FingerprintSHA256(key))
+	}, nil
+}
+
+// VerifyNodeIdentity checks that the host the node is connected to is the cloud instance
+// the node was created as, by comparing the instance ID read from the cloud metadata
+// service with the node ID. Together with SSHConfig.HostKeys, it protects against
+// connecting to another instance that reused the IP of the node.
+// The node must be connected
+func (h *Node) VerifyNodeIdentity() error {
+	var script string
+	switch h.Cloud {
+	case AWSCloud:
+		script = awsInstanceIDScript
+	case GCPCloud:
+		script = gcpInstanceNameScript
+	case AzureCloud:
+		script = azureVMNameScript
+	default:
+		return fmt.Errorf("%w: unsupported cloud %s", ErrIdentityNotVerifiable, h.Cloud.String())
+	}
+	output, err := h.Command(nil, constants.SSHScriptTimeout, script)
+	if err != nil {
+		return fmt.Errorf("%w: failure reading instance metadata of %s: %w", ErrIdentityNotVerifiable, h.IP, err)
+	}
+	return checkInstanceIdentity(h.GetCloudID(), string(output))
+}
+
+// checkInstanceIdentity compares the [metadataOutput] of the host with the expected [cloudID]
+func checkInstanceIdentity(cloudID string, metadataOutput string) error {
+	instanceID := strings.TrimSpace(metadataOutput)
+	if instanceID == "" {
+		return fmt.Errorf("%w: empty instance metadata", ErrIdentityNotVerifiable)
+	}
+	if instanceID != cloudID {
+		return fmt.Errorf("%w: expected %s, got %s", ErrNodeIdentityMismatch, cloudID, instanceID)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return sshPub
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	require := require.New(t)
+	hostKey := newTestHostKey(t)
+	otherKey := newTestHostKey(t)
+	consoleOutput := fmt.Sprintf(`[   10.1] cloud-init[1000]: Cloud-init v. 23.1 running 'modules:final'
+-----BEGIN SSH HOST KEY KEYS-----
+%s root@ip-10-0-0-1
+-----END SSH HOST KEY KEYS-----
+[   10.2] cloud-init[1000]: Cloud-init v. 23.1 finished
+`, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey))))
+	hostKeys := utils.ParseConsoleHostKeys(consoleOutput)
+	require.Len(hostKeys, 1)
+
+	callback, err := SSHConfig{HostKeys: hostKeys}.hostKeyCallback()
+	require.NoError(err)
+	require.NoError(callback("1.2.3.4:22", nil, hostKey))
+	require.ErrorIs(callback("1.2.3.4:22", nil, otherKey), ErrHostKeyMismatch)
+
+	// no known keys, no verification
+	callback, err = SSHConfig{}.hostKeyCallback()
+	require.NoError(err)
+	require.NoError(callback("1.2.3.4:22", nil, otherKey))
+
+	_, err = SSHConfig{HostKeys: []string{"ssh-ed25519 invalid"}}.hostKeyCallback()
+	require.ErrorIs(err, ErrInvalidKnownGoodHostKey)
+
+	require.Empty(utils.ParseConsoleHostKeys("no keys\n"))
+}
+
+func TestCheckInstanceIdentity(t *testing.T) {
+	require := require.New(t)
+	require.NoError(checkInstanceIdentity("i-0123456789abcdef0", "i-0123456789abcdef0\n"))
+	require.ErrorIs(checkInstanceIdentity("i-0123456789abcdef0", "i-0fedcba9876543210"), ErrNodeIdentityMismatch)
+	require.ErrorIs(checkInstanceIdentity("i-0123456789abcdef0", ""), ErrIdentityNotVerifiable)
+	require.ErrorIs((&Node{Cloud: Docker}).VerifyNodeIdentity(), ErrIdentityNotVerifiable)
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"fmt"
+
+	awsAPI "github.com/ava-labs/avalanche-tooling-sdk-go/cloud/aws"
+)
+
+// CreateRPCLoadBalancer puts the API nodes [nodes] behind an AWS load balancer health checked
+// on /ext/health, returning it. The public RPC endpoint is available at the returned DNSName.
+// All nodes must be on AWS, in the same profile, region and VPC.
+// [params.InstanceIDs] is filled from [nodes]
+func CreateRPCLoadBalancer(ctx context.Context, nodes []Node, params awsAPI.LoadBalancerParams) (awsAPI.LoadBalancer, error) {
+	if len(nodes) == 0 {
+		return awsAPI.LoadBalancer{}, awsAPI.ErrNoLoadBalancerTargets
+	}
+	awsProfile, region := "", ""
+	params.InstanceIDs = nil
+	for i, node := range nodes {
+		if node.Cloud != AWSCloud || node.CloudConfig.AWSConfig == nil {
+			return awsAPI.LoadBalancer{}, fmt.Errorf("node %s is not an AWS node", node.NodeID)
+		}
+		if i == 0 {
+			awsProfile, region = node.CloudConfig.AWSConfig.AWSProfile, node.CloudConfig.Region
+		} else if node.CloudConfig.AWSConfig.AWSProfile != awsProfile || node.CloudConfig.Region != region {
+			return awsAPI.LoadBalancer{}, fmt.Errorf("node %s is not in AWS profile %s, region %s", node.NodeID, awsProfile, region)
+		}
+		params.InstanceIDs = append(params.InstanceIDs, node.GetCloudID())
+	}
+	ec2Svc, err := awsAPI.NewAwsCloud(ctx, awsProfile, region)
+	if err != nil {
+		return awsAPI.LoadBalancer{}, err
+	}
+	return ec2Svc.CreateLoadBalancer(params)
+}
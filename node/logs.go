@@ -0,0 +1,35 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+)
+
+// MainLog is the name of the avalanchego log not related to a chain. Chain logs are
+// named after the chain alias, eg. "C", or ID
+const MainLog = "main"
+
+// RemoteLogPath is where the avalanchego log [name] is kept on the node
+func RemoteLogPath(name string) string {
+	return filepath.Join(constants.CloudNodeLogsPath, name+".log")
+}
+
+// GetLogs returns the last [lines] lines of the avalanchego log [name], eg. MainLog or
+// "C", or the whole log if [lines] is 0. See the logparse package to parse them
+func (h *Node) GetLogs(name string, lines int) ([]byte, error) {
+	remotePath := RemoteLogPath(name)
+	command := fmt.Sprintf("cat %s", remotePath)
+	if lines > 0 {
+		command = fmt.Sprintf("tail -n %d %s", lines, remotePath)
+	}
+	output, err := h.Command(nil, constants.SSHScriptTimeout, command)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting log %s of %s: %w: %s", name, h.NodeID, err, string(output))
+	}
+	return output, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+)
+
+// metricsExporterFile is the file served by the metrics exporter sidecar at /metrics
+const metricsExporterFile = "metrics"
+
+// ComposeSSHSetupMetricsExporter adds to the node the metrics exporter sidecar, a web
+// server that exposes the business metrics pushed with PushBusinessMetrics on
+// constants.MetricsExporterPort. Monitoring hosts set up with MonitorNodes afterwards
+// scrape it automatically
+func (h *Node) ComposeSSHSetupMetricsExporter() error {
+	for _, folder := range remoteconfig.MetricsExporterFoldersToCreate() {
+		if err := h.MkdirAll(folder, constants.SSHFileOpsTimeout); err != nil {
+			return err
+		}
+	}
+	// serve an empty page until the first push, so scrapes don't fail
+	if err := h.PushBusinessMetrics(nil); err != nil {
+		return err
+	}
+	return h.ComposeOverSSH("Setup Metrics Exporter",
+		constants.SSHScriptTimeout,
+		"templates/metricsexporter.docker-compose.yml",
+		dockerComposeInputs{
+			MetricsExporterPort: constants.MetricsExporterPort,
+		})
+}
+
+// HasMetricsExporter tells if the node runs the metrics exporter sidecar
+func (h *Node) HasMetricsExporter() (bool, error) {
+	return h.HasRemoteComposeService(utils.GetRemoteComposeFile(), constants.ServiceMetricsExporter, constants.SSHScriptTimeout)
+}
+
+// PushBusinessMetrics publishes [metrics], in the Prometheus text exposition format, on
+// the metrics exporter sidecar of the node, replacing the ones previously pushed.
+// See validator.BusinessMetrics
+func (h *Node) PushBusinessMetrics(metrics []byte) error {
+	remoteFile := utils.GetRemoteComposeServicePath(constants.ServiceMetricsExporter, metricsExporterFile)
+	tmpFile := remoteFile + ".tmp"
+	if err := h.UploadBytes(metrics, tmpFile, constants.SSHFileOpsTimeout); err != nil {
+		return err
+	}
+	// replace the file at once so scrapes never get partial metrics
+	if output, err := h.Commandf(nil, constants.SSHFileOpsTimeout, "mv -f %s %s", tmpFile, remoteFile); err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/node/monitoring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsExporterSetup(t *testing.T) {
+	require := require.New(t)
+	compose, err := renderComposeFile("templates/metricsexporter.docker-compose.yml", "Setup Metrics Exporter", dockerComposeInputs{
+		MetricsExporterPort: constants.MetricsExporterPort,
+	})
+	require.NoError(err)
+	require.Contains(string(compose), "httpd -f -p 9200 -h /metrics-exporter")
+	require.Contains(string(compose), `"9200:9200"`)
+
+	nodes := []Node{
+		{NodeID: "node1", IP: "10.0.0.1"},
+		{NodeID: "node2", IP: "10.0.0.2"},
+		{NodeID: "node3", IP: "10.0.0.3"},
+	}
+	targets := getMetricsExporterTargets(nodes, map[string]interface{}{"node1": true, "node2": false})
+	require.Equal([]string{"'10.0.0.1:9200'"}, targets)
+
+	configFile := filepath.Join(t.TempDir(), "prometheus.yml")
+	require.NoError(monitoring.WritePrometheusConfig(configFile, nil, nil, nil, targets))
+	config, err := os.ReadFile(configFile)
+	require.NoError(err)
+	require.Contains(string(config), "job_name: 'avalanchego-business'")
+	require.Contains(string(config), "targets: ['10.0.0.1:9200']")
+	require.NoError(monitoring.WritePrometheusConfig(configFile, nil, nil, nil, nil))
+	config, err = os.ReadFile(configFile)
+	require.NoError(err)
+	require.NotContains(string(config), "avalanchego-business")
+}
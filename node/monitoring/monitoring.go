@@ -17,14 +17,15 @@ import (
 )
 
 type configInputs struct {
-	AvalancheGoPorts string
-	MachinePorts     string
-	LoadTestPorts    string
-	IP               string
-	Port             string
-	Host             string
-	NodeID           string
-	ChainID          string
+	AvalancheGoPorts     string
+	MachinePorts         string
+	LoadTestPorts        string
+	MetricsExporterPorts string
+	IP                   string
+	Port                 string
+	Host                 string
+	NodeID               string
+	ChainID              string
 }
 
 //go:embed dashboards/*
@@ -80,11 +81,14 @@ func GenerateConfig(configPath string, configDesc string, templateVars configInp
 	return config.String(), nil
 }
 
-func WritePrometheusConfig(filePath string, avalancheGoPorts []string, machinePorts []string, loadTestPorts []string) error {
+// WritePrometheusConfig writes the prometheus config scraping the given targets.
+// [metricsExporterPorts] are the nodes running the business metrics exporter sidecar
+func WritePrometheusConfig(filePath string, avalancheGoPorts []string, machinePorts []string, loadTestPorts []string, metricsExporterPorts []string) error {
 	config, err := GenerateConfig("configs/prometheus.yml", "Prometheus Config", configInputs{
-		AvalancheGoPorts: strings.Join(utils.AddSingleQuotes(avalancheGoPorts), ","),
-		MachinePorts:     strings.Join(utils.AddSingleQuotes(machinePorts), ","),
-		LoadTestPorts:    strings.Join(utils.AddSingleQuotes(loadTestPorts), ","),
+		AvalancheGoPorts:     strings.Join(utils.AddSingleQuotes(avalancheGoPorts), ","),
+		MachinePorts:         strings.Join(utils.AddSingleQuotes(machinePorts), ","),
+		LoadTestPorts:        strings.Join(utils.AddSingleQuotes(loadTestPorts), ","),
+		MetricsExporterPorts: strings.Join(utils.AddSingleQuotes(metricsExporterPorts), ","),
 	})
 	if err != nil {
 		return err
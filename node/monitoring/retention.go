@@ -0,0 +1,136 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package monitoring
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPrometheusRetentionTime is the Prometheus default, kept when not set
+	DefaultPrometheusRetentionTime = 15 * 24 * time.Hour
+	// DefaultLokiRetentionPeriod keeps a week of logs, the oldest samples Loki accepts
+	DefaultLokiRetentionPeriod = 7 * 24 * time.Hour
+
+	// sizing assumptions used by EstimateDisk, for an avalanchego node tracking a few
+	// subnets: the series of avalanchego and node exporter scraped every 15s, at
+	// ~2 bytes per compressed sample, and the compressed logs sent by promtail
+	seriesPerNode          = 8000
+	scrapeIntervalSeconds  = 15
+	bytesPerSample         = 2
+	lokiBytesPerNodePerDay = 150 * 1024 * 1024
+	// diskHeadroomPercent covers the WAL, compactions and the OS
+	diskHeadroomPercent = 30
+)
+
+// Retention sets how much data the monitoring host keeps
+type Retention struct {
+	// PrometheusRetentionTime is how long metrics are kept. Defaults to
+	// DefaultPrometheusRetentionTime
+	PrometheusRetentionTime time.Duration
+	// PrometheusRetentionSize caps the size of the Prometheus TSDB, in bytes. The
+	// oldest blocks are removed first. 0 means no cap
+	PrometheusRetentionSize uint64
+	// LokiRetentionPeriod is how long logs are kept. Defaults to DefaultLokiRetentionPeriod
+	LokiRetentionPeriod time.Duration
+}
+
+// DefaultRetention returns the retention used when none is given
+func DefaultRetention() Retention {
+	return Retention{
+		PrometheusRetentionTime: DefaultPrometheusRetentionTime,
+		LokiRetentionPeriod:     DefaultLokiRetentionPeriod,
+	}
+}
+
+func (r Retention) withDefaults() Retention {
+	if r.PrometheusRetentionTime == 0 {
+		r.PrometheusRetentionTime = DefaultPrometheusRetentionTime
+	}
+	if r.LokiRetentionPeriod == 0 {
+		r.LokiRetentionPeriod = DefaultLokiRetentionPeriod
+	}
+	return r
+}
+
+// Validate checks the retention values are usable by Prometheus and Loki
+func (r Retention) Validate() error {
+	if r.PrometheusRetentionTime < 0 || r.LokiRetentionPeriod < 0 {
+		return fmt.Errorf("retention can't be negative")
+	}
+	if r.PrometheusRetentionTime != 0 && r.PrometheusRetentionTime < time.Hour {
+		return fmt.Errorf("prometheus retention time %s is lower than 1h", r.PrometheusRetentionTime)
+	}
+	// loki retention works with the 24h index period of the schema config
+	if r.LokiRetentionPeriod != 0 && r.LokiRetentionPeriod%(24*time.Hour) != 0 {
+		return fmt.Errorf("loki retention period %s must be a multiple of 24h", r.LokiRetentionPeriod)
+	}
+	if r.PrometheusRetentionSize != 0 && r.PrometheusRetentionSize < 1024*1024 {
+		return fmt.Errorf("prometheus retention size %d is lower than 1MB", r.PrometheusRetentionSize)
+	}
+	return nil
+}
+
+// PrometheusFlags returns the prometheus command line flags setting the retention
+func (r Retention) PrometheusFlags() []string {
+	r = r.withDefaults()
+	flags := []string{"--storage.tsdb.retention.time=" + hours(r.PrometheusRetentionTime)}
+	if r.PrometheusRetentionSize != 0 {
+		flags = append(flags, fmt.Sprintf("--storage.tsdb.retention.size=%dMB", r.PrometheusRetentionSize/(1024*1024)))
+	}
+	return flags
+}
+
+// LokiFlags returns the loki command line flags enabling the compactor retention
+func (r Retention) LokiFlags() []string {
+	r = r.withDefaults()
+	return []string{
+		"-compactor.retention-enabled=true",
+		"-compactor.delete-request-store=filesystem",
+		"-store.retention=" + hours(r.LokiRetentionPeriod),
+	}
+}
+
+// hours formats [d] in hours, as accepted by both Prometheus and Loki
+func hours(d time.Duration) string {
+	return fmt.Sprintf("%dh", int64(d/time.Hour))
+}
+
+// DiskEstimate is the disk the monitoring host needs, as computed by EstimateDisk
+type DiskEstimate struct {
+	PrometheusBytes uint64
+	LokiBytes       uint64
+	// TotalBytes adds headroom for the Prometheus WAL, compactions and the OS
+	TotalBytes uint64
+}
+
+// TotalGiB returns TotalBytes in GiB, rounded up, eg. to size the monitoring volume
+func (e DiskEstimate) TotalGiB() uint64 {
+	const gib = 1024 * 1024 * 1024
+	return (e.TotalBytes + gib - 1) / gib
+}
+
+// EstimateDisk estimates the disk a monitoring host needs to keep the data of
+// [nodeCount] avalanchego nodes for [retention]. The estimate assumes typical
+// avalanchego metrics and log volumes, so it is guidance rather than a bound: nodes
+// tracking many subnets, or with debug logs, need more. If PrometheusRetentionSize
+// is set, it caps the Prometheus estimate
+func EstimateDisk(nodeCount int, retention Retention) DiskEstimate {
+	retention = retention.withDefaults()
+	if nodeCount < 0 {
+		nodeCount = 0
+	}
+	samplesPerSecond := uint64(nodeCount) * seriesPerNode / scrapeIntervalSeconds
+	prometheusBytes := samplesPerSecond * bytesPerSample * uint64(retention.PrometheusRetentionTime/time.Second)
+	if retention.PrometheusRetentionSize != 0 && prometheusBytes > retention.PrometheusRetentionSize {
+		prometheusBytes = retention.PrometheusRetentionSize
+	}
+	lokiBytes := uint64(nodeCount) * lokiBytesPerNodePerDay * uint64(retention.LokiRetentionPeriod/(24*time.Hour))
+	return DiskEstimate{
+		PrometheusBytes: prometheusBytes,
+		LokiBytes:       lokiBytes,
+		TotalBytes:      (prometheusBytes + lokiBytes) * (100 + diskHeadroomPercent) / 100,
+	}
+}
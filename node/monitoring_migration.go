@@ -0,0 +1,152 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+// remoteMonitoringStateArchive is the path of the monitoring state archive on the monitoring host
+const remoteMonitoringStateArchive = "/tmp/monitoring-state.tar.gz"
+
+// monitoringStatePaths returns the service paths, relative to the remote services dir,
+// that make up the monitoring state: the Prometheus config and TSDB, and the Grafana
+// database, dashboards and datasources
+func monitoringStatePaths() []string {
+	return []string{
+		filepath.Join(constants.ServicePrometheus, "prometheus.yml"),
+		filepath.Join(constants.ServicePrometheus, "data"),
+		filepath.Join(constants.ServiceGrafana, "data"),
+		filepath.Join(constants.ServiceGrafana, "dashboards"),
+		filepath.Join(constants.ServiceGrafana, "provisioning"),
+	}
+}
+
+// monitoringStateServices are stopped while the state is exported or imported, so that
+// the Prometheus TSDB and the Grafana database are consistent
+func monitoringStateServices() []string {
+	return []string{constants.ServicePrometheus, constants.ServiceGrafana}
+}
+
+// ExportMonitoringState saves a snapshot of the monitoring state of the monitoring node
+// (Prometheus TSDB, Grafana dashboards and datasources) into the local archive [localArchive].
+// Prometheus and Grafana are stopped while the snapshot is taken
+func (h *Node) ExportMonitoringState(localArchive string) error {
+	if !isMonitoringNode(*h) {
+		return fmt.Errorf("%s is not a monitoring node", h.NodeID)
+	}
+	remoteComposeFile := utils.GetRemoteComposeFile()
+	if err := h.stopMonitoringStateServices(remoteComposeFile); err != nil {
+		return err
+	}
+	output, tarErr := h.Commandf(
+		nil,
+		constants.SSHLongRunningScriptTimeout,
+		"tar -czf %s -C %s %s",
+		remoteMonitoringStateArchive,
+		utils.GetRemoteComposeServicePath(""),
+		strings.Join(monitoringStatePaths(), " "),
+	)
+	if err := h.startMonitoringStateServices(remoteComposeFile); err != nil {
+		return err
+	}
+	if tarErr != nil {
+		return fmt.Errorf("failure archiving monitoring state: %w: %s", tarErr, string(output))
+	}
+	defer func() {
+		_ = h.Remove(remoteMonitoringStateArchive, false)
+	}()
+	return h.Download(remoteMonitoringStateArchive, localArchive, constants.SSHLongRunningScriptTimeout)
+}
+
+// ImportMonitoringState restores on the monitoring node the monitoring state saved by
+// ExportMonitoringState into [localArchive], replacing the current one
+func (h *Node) ImportMonitoringState(localArchive string) error {
+	if !isMonitoringNode(*h) {
+		return fmt.Errorf("%s is not a monitoring node", h.NodeID)
+	}
+	if !utils.FileExists(localArchive) {
+		return fmt.Errorf("monitoring state archive %s does not exist", localArchive)
+	}
+	if err := h.Upload(localArchive, remoteMonitoringStateArchive, constants.SSHLongRunningScriptTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		_ = h.Remove(remoteMonitoringStateArchive, false)
+	}()
+	remoteComposeFile := utils.GetRemoteComposeFile()
+	if err := h.stopMonitoringStateServices(remoteComposeFile); err != nil {
+		return err
+	}
+	servicesDir := utils.GetRemoteComposeServicePath("")
+	statePaths := []string{}
+	for _, path := range monitoringStatePaths() {
+		statePaths = append(statePaths, filepath.Join(servicesDir, path))
+	}
+	if output, err := h.Commandf(
+		nil,
+		constants.SSHLongRunningScriptTimeout,
+		"rm -rf %s && tar -xzf %s -C %s",
+		strings.Join(statePaths, " "),
+		remoteMonitoringStateArchive,
+		servicesDir,
+	); err != nil {
+		return fmt.Errorf("failure restoring monitoring state: %w: %s", err, string(output))
+	}
+	return h.startMonitoringStateServices(remoteComposeFile)
+}
+
+// RestoreMonitoring sets up the monitoring node with the monitoring state saved in
+// [localArchive] and links [targets] to it, re-pointing their promtail configs to this node.
+// Useful to replace a monitoring node that is no longer available, given a previous export
+func (h *Node) RestoreMonitoring(ctx context.Context, localArchive string, targets []Node, chainID string) error {
+	if err := h.ImportMonitoringState(localArchive); err != nil {
+		return err
+	}
+	return h.MonitorNodes(ctx, targets, chainID)
+}
+
+// MigrateMonitoring moves the monitoring state of the monitoring node to [newNode], and
+// re-points all [targets] promtail configs and metrics scraping to it. The current
+// monitoring node is left untouched, so it can be destroyed after the migration
+func (h *Node) MigrateMonitoring(ctx context.Context, newNode Node, targets []Node, chainID string) error {
+	if !isMonitoringNode(newNode) {
+		return fmt.Errorf("%s is not a monitoring node", newNode.NodeID)
+	}
+	tmpFile, err := os.CreateTemp("", "monitoring-state-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+	if err := h.ExportMonitoringState(tmpFile.Name()); err != nil {
+		return err
+	}
+	return newNode.RestoreMonitoring(ctx, tmpFile.Name(), targets, chainID)
+}
+
+func (h *Node) stopMonitoringStateServices(composeFile string) error {
+	for _, service := range monitoringStateServices() {
+		if err := h.StopDockerComposeService(composeFile, service, constants.SSHScriptTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Node) startMonitoringStateServices(composeFile string) error {
+	for _, service := range monitoringStateServices() {
+		if err := h.StartDockerComposeService(composeFile, service, constants.SSHScriptTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitoringStatePaths(t *testing.T) {
+	require := require.New(t)
+	require.Equal([]string{
+		"prometheus/prometheus.yml",
+		"prometheus/data",
+		"grafana/data",
+		"grafana/dashboards",
+		"grafana/provisioning",
+	}, monitoringStatePaths())
+}
+
+func TestMigrateMonitoringRequiresMonitoringNodes(t *testing.T) {
+	require := require.New(t)
+	validator := Node{NodeID: "validator", Roles: []SupportedRole{Validator}}
+	monitor := Node{NodeID: "monitor", Roles: []SupportedRole{Monitor}}
+	require.ErrorContains(validator.ExportMonitoringState("state.tar.gz"), "is not a monitoring node")
+	require.ErrorContains(validator.ImportMonitoringState("state.tar.gz"), "is not a monitoring node")
+	require.ErrorContains(monitor.ImportMonitoringState("missing.tar.gz"), "does not exist")
+	require.ErrorContains(monitor.MigrateMonitoring(context.Background(), validator, nil, ""), "is not a monitoring node")
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/node/monitoring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitoringRetention(t *testing.T) {
+	require := require.New(t)
+	retention := monitoring.Retention{
+		PrometheusRetentionTime: 30 * 24 * time.Hour,
+		PrometheusRetentionSize: 20 * 1024 * 1024 * 1024,
+		LokiRetentionPeriod:     3 * 24 * time.Hour,
+	}
+	require.NoError(retention.Validate())
+	compose, err := renderComposeFile("templates/monitoring.docker-compose.yml", "Setup Monitoring", dockerComposeInputs{
+		PrometheusFlags: retention.PrometheusFlags(),
+		LokiFlags:       retention.LokiFlags(),
+	})
+	require.NoError(err)
+	for _, flag := range []string{
+		"- '--storage.tsdb.path=/var/lib/prometheus'\n      - '--storage.tsdb.retention.time=720h'\n      - '--storage.tsdb.retention.size=20480MB'\n",
+		"- '-config.file=/etc/loki/loki.yml'\n      - '-compactor.retention-enabled=true'\n      - '-compactor.delete-request-store=filesystem'\n      - '-store.retention=72h'\n",
+	} {
+		require.Contains(string(compose), flag)
+	}
+
+	// defaults
+	require.Equal([]string{"--storage.tsdb.retention.time=360h"}, monitoring.DefaultRetention().PrometheusFlags())
+	require.Contains(monitoring.Retention{}.LokiFlags(), "-store.retention=168h")
+
+	require.Error(monitoring.Retention{LokiRetentionPeriod: 36 * time.Hour}.Validate())
+	require.Error(monitoring.Retention{PrometheusRetentionTime: time.Minute}.Validate())
+	require.Error(monitoring.Retention{PrometheusRetentionSize: 1024}.Validate())
+
+	estimate := monitoring.EstimateDisk(10, monitoring.DefaultRetention())
+	require.NotZero(estimate.PrometheusBytes)
+	require.NotZero(estimate.LokiBytes)
+	require.Greater(estimate.TotalBytes, estimate.PrometheusBytes+estimate.LokiBytes)
+	require.Greater(monitoring.EstimateDisk(20, monitoring.DefaultRetention()).TotalGiB(), estimate.TotalGiB())
+	capped := monitoring.EstimateDisk(10, monitoring.Retention{PrometheusRetentionSize: 1024 * 1024 * 1024})
+	require.Equal(uint64(1024*1024*1024), capped.PrometheusBytes)
+	require.Zero(monitoring.EstimateDisk(0, monitoring.DefaultRetention()).TotalBytes)
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,22 +25,66 @@ import (
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/proxy"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 )
 
 // SSHConfig contains the configuration for connecting to a node over SSH
 type SSHConfig struct {
-	// Username to use when connecting to the node
+	// Username to use when connecting to the node. Each node has its own SSHConfig,
+	// so nodes operated together can log in with different users
 	User string
 
 	// Path to the private key to use when connecting to the node
-	// If this is empty, the SSH agent will be used
+	// If this and Identities are empty, the SSH agent will be used
 	PrivateKeyPath string
 
+	// Path to an OpenSSH certificate for PrivateKeyPath, signed by an SSH CA.
+	// If empty, PrivateKeyPath + "-cert.pub" is used when it exists
+	CertificatePath string
+
+	// Identities are additional candidate credentials, tried in order after PrivateKeyPath
+	Identities []SSHIdentity
+
+	// PassphraseCallback is called to obtain the passphrase of encrypted private keys
+	// that don't have one set, eg by prompting the user
+	PassphraseCallback SSHPassphraseCallback
+
 	// Parameters to pass to the ssh command.
 	// See man ssh_config(5) for more information
 	// By defalult it's StrictHostKeyChecking=no
 	Params map[string]string // additional parameters to pass to the ssh command
+
+	// Proxy to connect through. If nil, the proxy set with proxy.SetDefault is used
+	Proxy *proxy.Config
+
+	// JumpHost, if set, is used as a bastion to reach the node
+	JumpHost *SSHJumpHost
+
+	// HostKeys are the known good SSH host public keys of the node, in authorized_keys
+	// format, eg. read from the instance console with GetInstanceHostKeys of the cloud
+	// package. If set, connecting to a host presenting another key fails
+	HostKeys []string
+
+	// VerifyIdentity makes Connect call VerifyNodeIdentity, so connecting to another
+	// instance that reused the node IP fails
+	VerifyIdentity bool
+}
+
+// SSHJumpHost is a bastion host the SSH connections to a node go through
+type SSHJumpHost struct {
+	// Username to use when connecting to the jump host
+	User string
+
+	// IP address or hostname of the jump host
+	IP string
+
+	// Port of the jump host SSH server. Defaults to 22
+	Port uint
+
+	// Path to the private key to use when connecting to the jump host
+	// If this is empty, the SSH agent will be used
+	PrivateKeyPath string
 }
 
 // Node is an output of CreateNodes
@@ -88,32 +133,110 @@ func NewNodeConnection(h *Node, port uint) (*goph.Client, error) {
 	if port == 0 {
 		port = constants.SSHTCPPort
 	}
+	auth, err := h.SSHConfig.auth()
+	if err != nil {
+		return nil, err
+	}
+	callback, err := h.SSHConfig.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+	config := &goph.Config{
+		User:     h.SSHConfig.User,
+		Addr:     h.IP,
+		Port:     port,
+		Auth:     auth,
+		Timeout:  sshConnectionTimeout,
+		Callback: callback,
+	}
+	proxyConfig := proxy.Default()
+	if h.SSHConfig.Proxy != nil {
+		proxyConfig = *h.SSHConfig.Proxy
+	}
+	if h.SSHConfig.JumpHost == nil && !proxyConfig.Enabled() {
+		return goph.NewConn(config)
+	}
+	return dialSSH(config, h.SSHConfig.JumpHost, h.SSHConfig.PassphraseCallback, proxyConfig)
+}
+
+// dialSSH opens the SSH connection described by [config] going through
+// [proxyConfig] and [jumpHost], if given. [passphraseCallback] is used to decrypt
+// the jump host private key
+func dialSSH(
+	config *goph.Config,
+	jumpHost *SSHJumpHost,
+	passphraseCallback SSHPassphraseCallback,
+	proxyConfig proxy.Config,
+) (*goph.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(config.Addr, strconv.Itoa(int(config.Port)))
 	var (
-		auth goph.Auth
+		conn net.Conn
 		err  error
 	)
-
-	if h.SSHConfig.PrivateKeyPath == "" {
-		auth, err = goph.UseAgent()
+	if jumpHost == nil {
+		conn, err = proxyConfig.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		auth, err = goph.Key(h.SSHConfig.PrivateKeyPath, "")
-	}
-	if err != nil {
-		return nil, err
+		jumpPort := jumpHost.Port
+		if jumpPort == 0 {
+			jumpPort = constants.SSHTCPPort
+		}
+		jumpAuth, err := SSHConfig{
+			PrivateKeyPath:     jumpHost.PrivateKeyPath,
+			PassphraseCallback: passphraseCallback,
+		}.auth()
+		if err != nil {
+			return nil, err
+		}
+		jumpClient, err := dialSSH(&goph.Config{
+			User:     jumpHost.User,
+			Addr:     jumpHost.IP,
+			Port:     jumpPort,
+			Auth:     jumpAuth,
+			Timeout:  config.Timeout,
+			Callback: ssh.InsecureIgnoreHostKey(), // #nosec G106 -- node host keys don't apply to the jump host
+		}, nil, nil, proxyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failure connecting to jump host %s: %w", jumpHost.IP, err)
+		}
+		jumpConn, err := jumpClient.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			_ = jumpClient.Close()
+			return nil, fmt.Errorf("failure connecting to %s through jump host %s: %w", addr, jumpHost.IP, err)
+		}
+		conn = &jumpHostConn{Conn: jumpConn, jumpClient: jumpClient}
 	}
-	cl, err := goph.NewConn(&goph.Config{
-		User:    h.SSHConfig.User,
-		Addr:    h.IP,
-		Port:    port,
-		Auth:    auth,
-		Timeout: sshConnectionTimeout,
-		// #nosec G106
-		Callback: ssh.InsecureIgnoreHostKey(), // we don't verify node key ( similar to ansible)
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            config.User,
+		Auth:            config.Auth,
+		Timeout:         config.Timeout,
+		HostKeyCallback: config.Callback,
 	})
 	if err != nil {
+		_ = conn.Close()
 		return nil, err
 	}
-	return cl, nil
+	return &goph.Client{
+		Client: ssh.NewClient(sshConn, chans, reqs),
+		Config: config,
+	}, nil
+}
+
+// jumpHostConn is a connection tunneled through a jump host, that closes
+// the jump host connection when closed
+type jumpHostConn struct {
+	net.Conn
+	jumpClient *goph.Client
+}
+
+func (c *jumpHostConn) Close() error {
+	err := c.Conn.Close()
+	_ = c.jumpClient.Close()
+	return err
 }
 
 // GetConnection returns the SSH connection client for the Node.
@@ -155,6 +278,13 @@ func (h *Node) Connect(port uint) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to node %s: %w", h.IP, err)
 	}
+	if h.SSHConfig.VerifyIdentity {
+		if err := h.VerifyNodeIdentity(); err != nil {
+			_ = h.connection.Close()
+			h.connection = nil
+			return err
+		}
+	}
 	return nil
 }
 
@@ -245,6 +375,9 @@ func (h *Node) ReadFileBytes(remoteFile string, timeout time.Duration) ([]byte,
 // ExpandHome expands the ~ symbol to the home directory.
 func (h *Node) ExpandHome(path string) string {
 	userHome := filepath.Join("/home", h.SSHConfig.User)
+	if h.SSHConfig.User == "root" {
+		userHome = "/root"
+	}
 	if path == "" {
 		return userHome
 	}
@@ -303,13 +436,23 @@ func (h *Node) Cmd(ctx context.Context, name string, script string) (*goph.Cmd,
 
 // Command executes a shell command on a remote node.
 func (h *Node) Command(env []string, timeout time.Duration, script string) ([]byte, error) {
+	return h.CommandContext(context.Background(), env, timeout, script)
+}
+
+// CommandContext executes a shell command on a remote node. When [ctx] is canceled or
+// [timeout] expires, a SIGINT is sent to the remote command. A zero [timeout] means
+// the command is only bounded by [ctx]
+func (h *Node) CommandContext(ctx context.Context, env []string, timeout time.Duration, script string) ([]byte, error) {
 	if !h.Connected() {
 		if err := h.Connect(0); err != nil {
 			return nil, err
 		}
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	cmd, err := h.connection.CommandContext(ctx, "", script)
 	if err != nil {
 		return nil, err
@@ -326,6 +469,11 @@ func (h *Node) Commandf(env []string, timeout time.Duration, format string, args
 	return h.Command(env, timeout, fmt.Sprintf(format, args...))
 }
 
+// CommandContextf is a shorthand for CommandContext with a formatted script.
+func (h *Node) CommandContextf(ctx context.Context, env []string, timeout time.Duration, format string, args ...interface{}) ([]byte, error) {
+	return h.CommandContext(ctx, env, timeout, fmt.Sprintf(format, args...))
+}
+
 // Forward forwards the TCP connection to a remote address.
 func (h *Node) Forward(httpRequest string, timeout time.Duration) ([]byte, error) {
 	if !h.Connected() {
@@ -507,13 +655,25 @@ func (h *Node) WaitForSSHShell(timeout time.Duration) error {
 
 // StreamSSHCommand streams the execution of an SSH command on the node.
 func (h *Node) StreamSSHCommand(env []string, timeout time.Duration, command string) error {
+	return h.StreamSSHCommandContext(context.Background(), env, timeout, command)
+}
+
+// StreamSSHCommandContext streams the execution of an SSH command on the node.
+// When [ctx] is canceled or [timeout] expires, a SIGINT is sent to the remote command.
+// A zero [timeout] means the command is only bounded by [ctx]
+func (h *Node) StreamSSHCommandContext(ctx context.Context, env []string, timeout time.Duration, command string) error {
 	if !h.Connected() {
 		if err := h.Connect(0); err != nil {
 			return err
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
 	defer cancel()
 
 	session, err := h.connection.NewSession()
@@ -557,7 +717,22 @@ func (h *Node) StreamSSHCommand(env []string, timeout time.Duration, command str
 		}
 	}()
 
+	// Interrupt the remote command on cancellation
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = session.Signal(ssh.SIGINT)
+			_ = session.Close()
+		case <-done:
+		}
+	}()
+
 	if err := session.Run(command); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to run command %s: %w", command, ctx.Err())
+		}
 		return fmt.Errorf("failed to run command %s: %w", command, err)
 	}
 	wg.Wait()
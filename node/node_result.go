@@ -3,50 +3,77 @@
 package node
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
-// NodeResult is a struct that holds the result of a async command executed on a host
-type NodeResult struct {
+// NodeResultOf is a struct that holds the typed result of a async command executed on a host
+type NodeResultOf[T any] struct {
 	// ID of the host
 	NodeID string
 
 	// Value is the result of the command executed on the host
-	Value interface{}
+	Value T
 
 	// Err is the error that occurred while executing the command on the host
 	Err error
+
+	// Duration is the time the command took on the host, if tracked
+	Duration time.Duration
 }
 
-// NodeResults is a struct that holds the results of multiple async commands executed on multiple hosts
-type NodeResults struct {
-	Results []NodeResult
+// NodeResultsOf is a struct that holds the typed results of multiple async commands executed on multiple hosts
+type NodeResultsOf[T any] struct {
+	Results []NodeResultOf[T]
 	Lock    sync.Mutex
 }
 
+// NodeResult is a struct that holds the result of a async command executed on a host
+type NodeResult = NodeResultOf[interface{}]
+
+// NodeResults is a struct that holds the results of multiple async commands executed on multiple hosts
+type NodeResults = NodeResultsOf[interface{}]
+
 // AddResult adds a new NodeResult to the NodeResults struct.
 //
 // Parameters:
 // - nodeID: the ID of the host.
 // - value: the result of the command executed on the host.
 // - err: the error that occurred while executing the command on the host.
-func (nr *NodeResults) AddResult(nodeID string, value interface{}, err error) {
+func (nr *NodeResultsOf[T]) AddResult(nodeID string, value T, err error) {
+	nr.AddTimedResult(nodeID, value, err, 0)
+}
+
+// AddTimedResult adds a new NodeResult to the NodeResults struct, including the time
+// the command took on the host.
+func (nr *NodeResultsOf[T]) AddTimedResult(nodeID string, value T, err error, duration time.Duration) {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
-	nr.Results = append(nr.Results, NodeResult{
-		NodeID: nodeID,
-		Value:  value,
-		Err:    err,
+	nr.Results = append(nr.Results, NodeResultOf[T]{
+		NodeID:   nodeID,
+		Value:    value,
+		Err:      err,
+		Duration: duration,
 	})
 }
 
+// Run executes [f] for [nodeID], adding its outcome and execution time to the NodeResults.
+// It is safe to call Run concurrently for different nodes.
+func (nr *NodeResultsOf[T]) Run(nodeID string, f func() (T, error)) {
+	startTime := time.Now()
+	value, err := f()
+	nr.AddTimedResult(nodeID, value, err, time.Since(startTime))
+}
+
 // GetResults returns the results of the NodeResults
 //
 // No parameters.
 // Returns:
 // - []NodeResult: the results of the NodeResults.
-func (nr *NodeResults) GetResults() []NodeResult {
+func (nr *NodeResultsOf[T]) GetResults() []NodeResultOf[T] {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
 	return nr.Results
@@ -59,11 +86,11 @@ func (nr *NodeResults) GetResults() []NodeResult {
 // Finally, it releases the lock and returns the result map.
 //
 // Returns:
-// - map[string]interface{}: A map with the nodeIDs as keys and the corresponding values as values.
-func (nr *NodeResults) GetResultMap() map[string]interface{} {
+// - map[string]T: A map with the nodeIDs as keys and the corresponding values as values.
+func (nr *NodeResultsOf[T]) GetResultMap() map[string]T {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
-	result := map[string]interface{}{}
+	result := map[string]T{}
 	for _, node := range nr.Results {
 		result[node.NodeID] = node.Value
 	}
@@ -77,7 +104,7 @@ func (nr *NodeResults) GetResultMap() map[string]interface{} {
 //
 // Returns:
 // - int: the number of results in the NodeResults.
-func (nr *NodeResults) Len() int {
+func (nr *NodeResultsOf[T]) Len() int {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
 	return len(nr.Results)
@@ -87,7 +114,7 @@ func (nr *NodeResults) Len() int {
 //
 // No parameters.
 // Returns a slice of strings.
-func (nr *NodeResults) GetNodeList() []string {
+func (nr *NodeResultsOf[T]) GetNodeList() []string {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
 	nodes := []string{}
@@ -105,7 +132,7 @@ func (nr *NodeResults) GetNodeList() []string {
 //
 // Returns:
 // - map[string]error: A map with the nodeIDs as keys and the corresponding errors as values.
-func (nr *NodeResults) GetErrorHostMap() map[string]error {
+func (nr *NodeResultsOf[T]) GetErrorHostMap() map[string]error {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
 	hostErrors := make(map[string]error)
@@ -124,7 +151,7 @@ func (nr *NodeResults) GetErrorHostMap() map[string]error {
 //
 // Return:
 // - bool: true if a node with the given nodeID has an error, false otherwise.
-func (nr *NodeResults) HasNodeIDWithError(nodeID string) bool {
+func (nr *NodeResultsOf[T]) HasNodeIDWithError(nodeID string) bool {
 	nr.Lock.Lock()
 	defer nr.Lock.Unlock()
 	for _, node := range nr.Results {
@@ -141,7 +168,7 @@ func (nr *NodeResults) HasNodeIDWithError(nodeID string) bool {
 // method of the NodeResults struct. If the length is greater than 0, it means
 // that there are errors present, and the function returns true. Otherwise, it
 // returns false.
-func (nr *NodeResults) HasErrors() bool {
+func (nr *NodeResultsOf[T]) HasErrors() bool {
 	return len(nr.GetErrorHostMap()) > 0
 }
 
@@ -149,7 +176,7 @@ func (nr *NodeResults) HasErrors() bool {
 //
 // No parameters.
 // Returns a slice of strings.
-func (nr *NodeResults) GetErrorHosts() []string {
+func (nr *NodeResultsOf[T]) GetErrorHosts() []string {
 	var nodes []string
 	for _, node := range nr.Results {
 		if node.Err != nil {
@@ -162,7 +189,7 @@ func (nr *NodeResults) GetErrorHosts() []string {
 // SumError collects and returns the errors with nodeIds if there are errors in the NodeResults.
 //
 // Returns an error type.
-func (nr *NodeResults) Error() error {
+func (nr *NodeResultsOf[T]) Error() error {
 	if nr.HasErrors() {
 		// if there are errors, collect and return them with nodeIds
 		hostErrorMap := nr.GetErrorHostMap()
@@ -175,3 +202,108 @@ func (nr *NodeResults) Error() error {
 		return nil
 	}
 }
+
+// Succeeded returns the results of the hosts where the command succeeded.
+func (nr *NodeResultsOf[T]) Succeeded() []NodeResultOf[T] {
+	succeeded, _ := nr.Partition()
+	return succeeded
+}
+
+// Failed returns the results of the hosts where the command failed.
+func (nr *NodeResultsOf[T]) Failed() []NodeResultOf[T] {
+	_, failed := nr.Partition()
+	return failed
+}
+
+// Partition splits the results into the ones that succeeded and the ones that failed.
+func (nr *NodeResultsOf[T]) Partition() ([]NodeResultOf[T], []NodeResultOf[T]) {
+	nr.Lock.Lock()
+	defer nr.Lock.Unlock()
+	succeeded := []NodeResultOf[T]{}
+	failed := []NodeResultOf[T]{}
+	for _, node := range nr.Results {
+		if node.Err != nil {
+			failed = append(failed, node)
+		} else {
+			succeeded = append(succeeded, node)
+		}
+	}
+	return succeeded, failed
+}
+
+// Merge composes the results of a later phase [other] into the NodeResults.
+//
+// For hosts present in both, the value is taken from [other], the durations are added
+// and the errors are joined. Hosts only present in [other] are appended.
+func (nr *NodeResultsOf[T]) Merge(other *NodeResultsOf[T]) {
+	if other == nil || other == nr {
+		return
+	}
+	otherResults := other.GetResults()
+	nr.Lock.Lock()
+	defer nr.Lock.Unlock()
+	for _, otherResult := range otherResults {
+		merged := false
+		for i := range nr.Results {
+			if nr.Results[i].NodeID != otherResult.NodeID {
+				continue
+			}
+			nr.Results[i].Value = otherResult.Value
+			nr.Results[i].Err = errors.Join(nr.Results[i].Err, otherResult.Err)
+			nr.Results[i].Duration += otherResult.Duration
+			merged = true
+			break
+		}
+		if !merged {
+			nr.Results = append(nr.Results, otherResult)
+		}
+	}
+}
+
+type nodeResultJSON[T any] struct {
+	NodeID     string `json:"nodeID"`
+	Value      T      `json:"value"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+type nodeResultsJSON[T any] struct {
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []nodeResultJSON[T] `json:"results"`
+}
+
+// MarshalJSON serializes the result for reporting, with the error as a string
+func (r NodeResultOf[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.toJSON())
+}
+
+func (r NodeResultOf[T]) toJSON() nodeResultJSON[T] {
+	out := nodeResultJSON[T]{
+		NodeID:     r.NodeID,
+		Value:      r.Value,
+		DurationMs: r.Duration.Milliseconds(),
+	}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return out
+}
+
+// MarshalJSON serializes the results for reporting, together with success/failure counts
+func (nr *NodeResultsOf[T]) MarshalJSON() ([]byte, error) {
+	nr.Lock.Lock()
+	defer nr.Lock.Unlock()
+	out := nodeResultsJSON[T]{
+		Results: []nodeResultJSON[T]{},
+	}
+	for _, node := range nr.Results {
+		if node.Err != nil {
+			out.Failed++
+		} else {
+			out.Succeeded++
+		}
+		out.Results = append(out.Results, node.toJSON())
+	}
+	return json.Marshal(out)
+}
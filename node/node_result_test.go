@@ -0,0 +1,60 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeResultsPartitionAndMerge(t *testing.T) {
+	require := require.New(t)
+	errFailed := errors.New("failed")
+
+	setup := &NodeResultsOf[int]{}
+	setup.AddTimedResult("node1", 1, nil, time.Second)
+	setup.AddTimedResult("node2", 0, errFailed, time.Second)
+	setup.Run("node3", func() (int, error) { return 3, nil })
+
+	succeeded, failed := setup.Partition()
+	require.Len(succeeded, 2)
+	require.Len(failed, 1)
+	require.Equal("node2", failed[0].NodeID)
+	require.Equal(map[string]int{"node1": 1, "node2": 0, "node3": 3}, setup.GetResultMap())
+
+	start := &NodeResultsOf[int]{}
+	start.AddTimedResult("node1", 10, errFailed, 2*time.Second)
+	start.AddTimedResult("node4", 4, nil, time.Second)
+	setup.Merge(start)
+
+	require.Equal(4, setup.Len())
+	resultMap := setup.GetResultMap()
+	require.Equal(10, resultMap["node1"])
+	require.Equal(4, resultMap["node4"])
+	require.ErrorIs(setup.GetErrorHostMap()["node1"], errFailed)
+	require.Equal(3*time.Second, setup.GetResults()[0].Duration)
+	require.ElementsMatch([]string{"node1", "node2"}, setup.GetErrorHosts())
+	require.Len(setup.Succeeded(), 2)
+	require.Len(setup.Failed(), 2)
+}
+
+func TestNodeResultsJSON(t *testing.T) {
+	require := require.New(t)
+	results := &NodeResults{}
+	results.AddTimedResult("node1", "ok", nil, 1500*time.Millisecond)
+	results.AddResult("node2", nil, errors.New("boom"))
+	bytes, err := json.Marshal(results)
+	require.NoError(err)
+	require.JSONEq(`{
+		"succeeded": 1,
+		"failed": 1,
+		"results": [
+			{"nodeID": "node1", "value": "ok", "durationMs": 1500},
+			{"nodeID": "node2", "value": null, "error": "boom", "durationMs": 0}
+		]
+	}`, string(bytes))
+}
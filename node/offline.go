@@ -0,0 +1,113 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+// remoteOfflineDir is where offline artifacts are uploaded to on the node
+var remoteOfflineDir = filepath.Join(constants.CloudNodeCLIConfigBasePath, "offline")
+
+// OfflineArtifacts are the files uploaded from the operator machine to set up a node
+// that has no outbound internet access. See WithOfflineArtifacts
+type OfflineArtifacts struct {
+	// Packages are .deb files installed with dpkg instead of fetching them with apt,
+	// eg. docker-ce, docker-compose-plugin and their dependencies
+	Packages []string
+
+	// DockerImages are image tarballs, as produced by docker save, loaded on the node
+	// instead of pulling the images, eg. the avalanchego image
+	DockerImages []string
+
+	// Binaries maps local executables, eg. avalanchego or VM plugin binaries, to the
+	// remote path they are installed at
+	Binaries map[string]string
+
+	// Files maps other local files, eg. compose files, to their remote path
+	Files map[string]string
+}
+
+// Validate checks that all the artifacts exist locally, and that packages and images
+// don't collide once uploaded
+func (a OfflineArtifacts) Validate() error {
+	for _, group := range [][]string{a.Packages, a.DockerImages} {
+		names := map[string]string{}
+		for _, path := range group {
+			if !utils.FileExists(path) {
+				return fmt.Errorf("offline artifact %s does not exist", path)
+			}
+			name := filepath.Base(path)
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("offline artifacts %s and %s have the same file name", other, path)
+			}
+			names[name] = path
+		}
+	}
+	for _, files := range []map[string]string{a.Binaries, a.Files} {
+		for localPath, remotePath := range files {
+			if !utils.FileExists(localPath) {
+				return fmt.Errorf("offline artifact %s does not exist", localPath)
+			}
+			if remotePath == "" {
+				return fmt.Errorf("offline artifact %s has no remote path", localPath)
+			}
+		}
+	}
+	return nil
+}
+
+// UploadOfflineArtifacts uploads [artifacts] to the node. Packages and docker images
+// go to a staging directory, from where RunSSHSetupNode installs them, while binaries
+// and files are put at their final location
+func (h *Node) UploadOfflineArtifacts(artifacts OfflineArtifacts) error {
+	if err := artifacts.Validate(); err != nil {
+		return err
+	}
+	uploadOpts := UploadOptions{VerifyChecksum: true}
+	for dir, paths := range map[string][]string{
+		"packages": artifacts.Packages,
+		"images":   artifacts.DockerImages,
+	} {
+		remoteDir := filepath.Join(remoteOfflineDir, dir)
+		if err := h.MkdirAll(remoteDir, constants.SSHFileOpsTimeout); err != nil {
+			return err
+		}
+		for _, path := range paths {
+			h.Logger.Infof("Uploading offline artifact %s to %s", path, h.NodeID)
+			if err := h.UploadResumable(path, filepath.Join(remoteDir, filepath.Base(path)), uploadOpts); err != nil {
+				return fmt.Errorf("failure uploading %s to %s: %w", path, h.NodeID, err)
+			}
+		}
+	}
+	for localPath, remotePath := range artifacts.Files {
+		if err := h.uploadOfflineFile(localPath, remotePath, uploadOpts); err != nil {
+			return err
+		}
+	}
+	for localPath, remotePath := range artifacts.Binaries {
+		if err := h.uploadOfflineFile(localPath, remotePath, uploadOpts); err != nil {
+			return err
+		}
+		if _, err := h.Commandf(nil, constants.SSHScriptTimeout, "chmod +x %s", remotePath); err != nil {
+			return fmt.Errorf("failure making %s executable on %s: %w", remotePath, h.NodeID, err)
+		}
+	}
+	return nil
+}
+
+func (h *Node) uploadOfflineFile(localPath string, remotePath string, uploadOpts UploadOptions) error {
+	if err := h.MkdirAll(filepath.Dir(remotePath), constants.SSHFileOpsTimeout); err != nil {
+		return err
+	}
+	h.Logger.Infof("Uploading offline artifact %s to %s:%s", localPath, h.NodeID, remotePath)
+	if err := h.UploadResumable(localPath, remotePath, uploadOpts); err != nil {
+		return fmt.Errorf("failure uploading %s to %s: %w", localPath, h.NodeID, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineArtifactsValidate(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	deb := filepath.Join(dir, "docker-ce.deb")
+	image := filepath.Join(dir, "avalanchego.tar")
+	for _, path := range []string{deb, image} {
+		require.NoError(os.WriteFile(path, []byte("artifact"), 0o600))
+	}
+
+	artifacts := OfflineArtifacts{
+		Packages:     []string{deb},
+		DockerImages: []string{image},
+		Files:        map[string]string{image: "/home/ubuntu/avalanchego.tar"},
+	}
+	require.NoError(artifacts.Validate())
+
+	artifacts.Binaries = map[string]string{deb: ""}
+	require.ErrorContains(artifacts.Validate(), "has no remote path")
+
+	artifacts.Binaries = nil
+	artifacts.Packages = append(artifacts.Packages, filepath.Join(dir, "missing.deb"))
+	require.ErrorContains(artifacts.Validate(), "does not exist")
+
+	otherDir := t.TempDir()
+	otherImage := filepath.Join(otherDir, "avalanchego.tar")
+	require.NoError(os.WriteFile(otherImage, []byte("artifact"), 0o600))
+	artifacts.Packages = []string{deb}
+	artifacts.DockerImages = []string{image, otherImage}
+	require.ErrorContains(artifacts.Validate(), "have the same file name")
+}
+
+func TestRenderSetupNodeScript(t *testing.T) {
+	require := require.New(t)
+	script, err := renderScript("Setup Node", "shell/setupNode.sh", ScriptInputs{
+		Offline:    true,
+		OfflineDir: remoteOfflineDir,
+	})
+	require.NoError(err)
+	require.Contains(script, "dpkg -i "+remoteOfflineDir+"/packages/*.deb")
+	require.Contains(script, "docker load -i")
+	require.NotContains(script, "apt-get")
+	require.NotContains(script, "curl")
+
+	script, err = renderScript("Setup Node", "shell/setupNode.sh", ScriptInputs{})
+	require.NoError(err)
+	require.Contains(script, "apt-get")
+	require.NotContains(script, "docker load")
+}
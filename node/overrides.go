@@ -0,0 +1,48 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+var (
+	overridesLock sync.RWMutex
+	overrides     fs.FS
+)
+
+// SetTemplateOverrides makes the node package use the shell scripts and templates found
+// in [fsys] instead of the embedded ones, so provisioning can be patched without forking.
+// Files in [fsys] use the same paths as the embedded ones, eg. shell/setupNode.sh,
+// templates/avalanchego.docker-compose.yml or templates/avalanche-node.tmpl, and the
+// files not found there are still read from the embedded ones. A nil [fsys] restores
+// the embedded files
+func SetTemplateOverrides(fsys fs.FS) {
+	overridesLock.Lock()
+	defer overridesLock.Unlock()
+	overrides = fsys
+	remoteconfig.SetOverrides(fsys)
+}
+
+// SetTemplateOverridesDir is like SetTemplateOverrides, for a local directory
+func SetTemplateOverridesDir(dir string) error {
+	if !utils.DirectoryExists(dir) {
+		return fmt.Errorf("template overrides directory %s does not exist", dir)
+	}
+	SetTemplateOverrides(os.DirFS(dir))
+	return nil
+}
+
+// readEmbedded reads [name] from [fsys], unless overridden. See SetTemplateOverrides
+func readEmbedded(fsys fs.FS, name string) ([]byte, error) {
+	overridesLock.RLock()
+	defer overridesLock.RUnlock()
+	return utils.ReadFileWithOverride(overrides, fsys, name)
+}
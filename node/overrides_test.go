@@ -0,0 +1,55 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTemplateOverrides(t *testing.T) {
+	require := require.New(t)
+	defer SetTemplateOverrides(nil)
+
+	SetTemplateOverrides(fstest.MapFS{
+		"shell/setupNode.sh":                       {Data: []byte("echo patched {{.OfflineDir}}")},
+		"templates/avalanchego.docker-compose.yml": {Data: []byte("services: {{.AvalanchegoVersion}}")},
+		"templates/avalanche-node.tmpl":            {Data: []byte(`{"network-id": "{{.NetworkID}}"}`)},
+	})
+	script, err := renderScript("setup", "shell/setupNode.sh", ScriptInputs{OfflineDir: "/offline"})
+	require.NoError(err)
+	require.Equal("echo patched /offline", script)
+	// files not overridden are read from the embedded ones
+	script, err = renderScript("patch", "shell/patchOS.sh", ScriptInputs{})
+	require.NoError(err)
+	require.Contains(script, "apt")
+	compose, err := renderComposeFile("templates/avalanchego.docker-compose.yml", "compose", dockerComposeInputs{AvalanchegoVersion: "v1.11.5"})
+	require.NoError(err)
+	require.Equal("services: v1.11.5", string(compose))
+	config, err := remoteconfig.RenderAvalancheTemplate("templates/avalanche-node.tmpl", remoteconfig.AvalancheConfigInputs{NetworkID: "fuji"})
+	require.NoError(err)
+	require.Equal(`{"network-id": "fuji"}`, string(config))
+
+	dir := t.TempDir()
+	require.NoError(os.MkdirAll(filepath.Join(dir, "shell"), 0o700))
+	require.NoError(os.WriteFile(filepath.Join(dir, "shell", "setupNode.sh"), []byte("echo from dir"), 0o600))
+	require.NoError(SetTemplateOverridesDir(dir))
+	script, err = renderScript("setup", "shell/setupNode.sh", ScriptInputs{})
+	require.NoError(err)
+	require.Equal("echo from dir", script)
+	config, err = remoteconfig.RenderAvalancheTemplate("templates/avalanche-node.tmpl", remoteconfig.AvalancheConfigInputs{NetworkID: "fuji"})
+	require.NoError(err)
+	require.Contains(string(config), `"log-dir"`)
+	require.Error(SetTemplateOverridesDir(filepath.Join(dir, "missing")))
+
+	SetTemplateOverrides(nil)
+	script, err = renderScript("setup", "shell/setupNode.sh", ScriptInputs{})
+	require.NoError(err)
+	require.Contains(script, "docker")
+}
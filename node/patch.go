@@ -0,0 +1,276 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+)
+
+// RebootPolicy decides if a node is rebooted after being patched
+type RebootPolicy int
+
+const (
+	// RebootIfRequired reboots the node only if the installed updates require it
+	RebootIfRequired RebootPolicy = iota
+	// RebootNever doesn't reboot the node, even if updates require it
+	RebootNever
+	// RebootAlways reboots the node after patching it
+	RebootAlways
+)
+
+const (
+	rebootRequiredMarker  = "AVALANCHE_TOOLING_REBOOT_REQUIRED"
+	defaultHealthTimeout  = 30 * time.Minute
+	defaultRebootTimeout  = 10 * time.Minute
+	patchOSScriptTimeout  = 30 * time.Minute
+	maxWindowSearchPeriod = 8 * 24 * time.Hour
+)
+
+var (
+	ErrPatchHealthGate          = errors.New("node did not become healthy after patching")
+	ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+)
+
+// MaintenanceWindow is a recurrent period of time in which nodes can be patched
+type MaintenanceWindow struct {
+	// Weekdays in which the window opens. Every day if empty
+	Weekdays []time.Weekday
+	// Start is the offset from midnight at which the window opens
+	Start time.Duration
+	// Duration of the window
+	Duration time.Duration
+	// Location the window is defined in. Defaults to UTC
+	Location *time.Location
+}
+
+func (w MaintenanceWindow) validate() error {
+	if w.Start < 0 || w.Start >= 24*time.Hour {
+		return fmt.Errorf("%w: start must be within a day", ErrInvalidMaintenanceWindow)
+	}
+	if w.Duration <= 0 || w.Duration > 24*time.Hour {
+		return fmt.Errorf("%w: duration must be positive and at most a day", ErrInvalidMaintenanceWindow)
+	}
+	return nil
+}
+
+func (w MaintenanceWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// opening returns when the window that would contain [t] opens if it starts on the day of [t]
+func (w MaintenanceWindow) opening(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location()).Add(w.Start)
+}
+
+func (w MaintenanceWindow) opensOn(day time.Weekday) bool {
+	return len(w.Weekdays) == 0 || slices.Contains(w.Weekdays, day)
+}
+
+// Contains returns true if [t] is inside an occurrence of the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	t = t.In(w.location())
+	// a window may have opened the day before and still be open
+	for _, day := range []time.Time{t, t.AddDate(0, 0, -1)} {
+		start := w.opening(day)
+		if w.opensOn(start.Weekday()) && !t.Before(start) && t.Before(start.Add(w.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the time the window is open at, or opens next, starting from [t]
+func (w MaintenanceWindow) Next(t time.Time) (time.Time, error) {
+	if err := w.validate(); err != nil {
+		return time.Time{}, err
+	}
+	if w.Contains(t) {
+		return t, nil
+	}
+	t = t.In(w.location())
+	for day := t; day.Sub(t) < maxWindowSearchPeriod; day = day.AddDate(0, 0, 1) {
+		start := w.opening(day)
+		if w.opensOn(start.Weekday()) && start.After(t) {
+			return start, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: no weekday matches", ErrInvalidMaintenanceWindow)
+}
+
+// PatchStrategy configures Cluster.PatchOS
+type PatchStrategy struct {
+	// BatchSize is the number of nodes patched at the same time. Defaults to 1
+	BatchSize int
+
+	// SecurityOnly only applies security updates instead of a full upgrade
+	SecurityOnly bool
+
+	// Reboot defaults to RebootIfRequired
+	Reboot RebootPolicy
+
+	// HealthTimeout is how long to wait for avalanchego to be healthy and bootstrapped
+	// after a node is patched, before considering the patch failed. Defaults to 30m
+	HealthTimeout time.Duration
+
+	// Window, if set, makes each batch wait for the maintenance window to be open
+	// before starting
+	Window *MaintenanceWindow
+
+	// ContinueOnFailure keeps patching the next batches after a node fails.
+	// By default the rollout stops at the first failed batch
+	ContinueOnFailure bool
+}
+
+// PatchResult is the result value of Cluster.PatchOS for each node
+type PatchResult struct {
+	// Output of the package upgrade
+	Output string
+	// RebootRequired is true if the updates required a reboot
+	RebootRequired bool
+	// Rebooted is true if the node was rebooted
+	Rebooted bool
+}
+
+func (s *PatchStrategy) setDefaults() {
+	if s.BatchSize <= 0 {
+		s.BatchSize = 1
+	}
+	if s.HealthTimeout == 0 {
+		s.HealthTimeout = defaultHealthTimeout
+	}
+}
+
+// PatchOS upgrades the OS packages of the cluster nodes in rolling batches. After a node
+// is patched, and rebooted if needed, avalanchego must be healthy before moving to the
+// next batch, so the cluster never has more than BatchSize nodes out of service.
+// The returned results contain a *PatchResult for each patched node
+func (c *Cluster) PatchOS(ctx context.Context, strategy PatchStrategy) (*NodeResults, error) {
+	strategy.setDefaults()
+	if strategy.Window != nil {
+		if err := strategy.Window.validate(); err != nil {
+			return nil, err
+		}
+	}
+	results := &NodeResults{}
+	for start := 0; start < len(c.Nodes); start += strategy.BatchSize {
+		if strategy.Window != nil {
+			if err := waitForWindow(ctx, *strategy.Window); err != nil {
+				return results, err
+			}
+		}
+		batch := c.Nodes[start:min(start+strategy.BatchSize, len(c.Nodes))]
+		batchResults := NewCluster(c.Name, batch).runParallel(func(h *Node) (interface{}, error) {
+			return h.PatchOS(ctx, strategy)
+		})
+		results.Merge(batchResults)
+		if batchResults.HasErrors() && !strategy.ContinueOnFailure {
+			return results, fmt.Errorf("stopping rollout: %w", batchResults.Error())
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// runParallel executes [f] on all the cluster nodes in parallel
+func (c *Cluster) runParallel(f func(h *Node) (interface{}, error)) *NodeResults {
+	results := &NodeResults{}
+	wg := sync.WaitGroup{}
+	for i := range c.Nodes {
+		wg.Add(1)
+		go func(h *Node) {
+			defer wg.Done()
+			results.Run(h.NodeID, func() (interface{}, error) {
+				return f(h)
+			})
+		}(&c.Nodes[i])
+	}
+	wg.Wait()
+	return results
+}
+
+func waitForWindow(ctx context.Context, window MaintenanceWindow) error {
+	next, err := window.Next(time.Now())
+	if err != nil {
+		return err
+	}
+	if wait := time.Until(next); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return nil
+}
+
+// PatchOS upgrades the OS packages of the node, reboots it according to [strategy],
+// and waits for avalanchego to be healthy
+func (h *Node) PatchOS(ctx context.Context, strategy PatchStrategy) (*PatchResult, error) {
+	strategy.setDefaults()
+	script, err := renderScript("Patch OS", "shell/patchOS.sh", ScriptInputs{
+		PatchSecurityOnly:    strategy.SecurityOnly,
+		RebootRequiredMarker: rebootRequiredMarker,
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.Logger.Infof("Patching OS on %s", h.NodeID)
+	output, err := h.CommandContext(ctx, nil, patchOSScriptTimeout, script)
+	result := &PatchResult{
+		Output:         string(output),
+		RebootRequired: strings.Contains(string(output), rebootRequiredMarker),
+	}
+	if err != nil {
+		return result, fmt.Errorf("failure patching OS on %s: %w: %s", h.NodeID, err, string(output))
+	}
+	if strategy.Reboot == RebootAlways || (strategy.Reboot == RebootIfRequired && result.RebootRequired) {
+		if err := h.Reboot(ctx); err != nil {
+			return result, err
+		}
+		result.Rebooted = true
+	}
+	if h.runsAvalancheGo() {
+		if err := h.WaitForAvalancheGoHealth(strategy.HealthTimeout); err != nil {
+			return result, fmt.Errorf("%w %s: %w", ErrPatchHealthGate, h.NodeID, err)
+		}
+	}
+	return result, nil
+}
+
+// Reboot restarts the node host and waits for SSH to be available again
+func (h *Node) Reboot(ctx context.Context) error {
+	h.Logger.Infof("Rebooting %s", h.NodeID)
+	// the connection is closed by the host while running the command, so the error is ignored
+	_, _ = h.CommandContext(ctx, nil, constants.SSHScriptTimeout, "sudo systemctl reboot")
+	_ = h.Disconnect()
+	// give the host time to go down so the SSH check doesn't succeed before the reboot
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(constants.SSHSleepBetweenChecks):
+	}
+	if err := h.WaitForSSHShell(defaultRebootTimeout); err != nil {
+		return fmt.Errorf("node %s not available after reboot: %w", h.NodeID, err)
+	}
+	return nil
+}
+
+// runsAvalancheGo returns true if the node roles include running avalanchego
+func (h *Node) runsAvalancheGo() bool {
+	return slices.Contains(h.Roles, Validator) || slices.Contains(h.Roles, API)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	require := require.New(t)
+	// Saturdays and Sundays from 23:00 to 03:00 UTC
+	window := MaintenanceWindow{
+		Weekdays: []time.Weekday{time.Saturday, time.Sunday},
+		Start:    23 * time.Hour,
+		Duration: 4 * time.Hour,
+	}
+	friday := time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC)
+	require.Equal(time.Friday, friday.Weekday())
+	require.False(window.Contains(friday))
+	next, err := window.Next(friday)
+	require.NoError(err)
+	require.Equal(time.Date(2024, 6, 8, 23, 0, 0, 0, time.UTC), next)
+
+	// window that opened on sunday is still open on monday morning
+	mondayMorning := time.Date(2024, 6, 10, 2, 0, 0, 0, time.UTC)
+	require.True(window.Contains(mondayMorning))
+	next, err = window.Next(mondayMorning)
+	require.NoError(err)
+	require.Equal(mondayMorning, next)
+
+	mondayLater := time.Date(2024, 6, 10, 3, 0, 0, 0, time.UTC)
+	require.False(window.Contains(mondayLater))
+	next, err = window.Next(mondayLater)
+	require.NoError(err)
+	require.Equal(time.Date(2024, 6, 15, 23, 0, 0, 0, time.UTC), next)
+
+	// every day window in another location
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(err)
+	daily := MaintenanceWindow{Start: 2 * time.Hour, Duration: time.Hour, Location: nyc}
+	next, err = daily.Next(time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC))
+	require.NoError(err)
+	require.True(next.Equal(time.Date(2024, 6, 8, 2, 0, 0, 0, nyc)))
+
+	_, err = MaintenanceWindow{Start: 25 * time.Hour, Duration: time.Hour}.Next(friday)
+	require.ErrorIs(err, ErrInvalidMaintenanceWindow)
+	_, err = MaintenanceWindow{Duration: 0}.Next(friday)
+	require.ErrorIs(err, ErrInvalidMaintenanceWindow)
+}
+
+func TestRenderPatchOSScript(t *testing.T) {
+	require := require.New(t)
+	script, err := renderScript("Patch OS", "shell/patchOS.sh", ScriptInputs{
+		PatchSecurityOnly:    true,
+		RebootRequiredMarker: rebootRequiredMarker,
+	})
+	require.NoError(err)
+	require.Contains(script, "unattended-upgrade")
+	require.NotContains(script, "apt-get -y $APT_OPTS upgrade")
+	require.Contains(script, rebootRequiredMarker)
+
+	script, err = renderScript("Patch OS", "shell/patchOS.sh", ScriptInputs{RebootRequiredMarker: rebootRequiredMarker})
+	require.NoError(err)
+	require.Contains(script, "apt-get -y $APT_OPTS upgrade")
+	require.NotContains(script, "unattended-upgrade")
+}
+
+func TestPatchStrategyDefaults(t *testing.T) {
+	strategy := PatchStrategy{}
+	strategy.setDefaults()
+	require.Equal(t, 1, strategy.BatchSize)
+	require.Equal(t, defaultHealthTimeout, strategy.HealthTimeout)
+	require.Equal(t, RebootIfRequired, strategy.Reboot)
+}
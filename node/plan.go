@@ -0,0 +1,452 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// hoursPerMonth is the number of hours cloud providers bill per month
+	hoursPerMonth = 730
+	// PlanSchemaVersion is the version of the Plan JSON output. It is increased
+	// on any incompatible change, so consumers can detect them
+	PlanSchemaVersion = 1
+)
+
+// ResourceAction is what Apply does with a planned resource
+type ResourceAction string
+
+const (
+	// ActionCreate creates the resource
+	ActionCreate ResourceAction = "create"
+	// ActionUseExisting uses a resource that must already exist
+	ActionUseExisting ResourceAction = "use existing"
+)
+
+// Estimated on demand prices in USD, from the AWS us-east-1, GCP us-east1 and Azure eastus
+// price lists.
+// They are only used to give an idea of the cost of a plan, actual prices vary by region
+var (
+	awsInstanceHourlyPrice = map[string]float64{
+		"c5.xlarge":   0.17,
+		"c5.2xlarge":  0.34,
+		"c5.4xlarge":  0.68,
+		"c6i.2xlarge": 0.34,
+		"c6i.4xlarge": 0.68,
+		"c7g.2xlarge": 0.29,
+		"c7g.4xlarge": 0.58,
+		"m5.2xlarge":  0.384,
+		"m6i.2xlarge": 0.384,
+		"t3.xlarge":   0.1664,
+		"t3.2xlarge":  0.3328,
+	}
+	gcpInstanceHourlyPrice = map[string]float64{
+		"e2-standard-4":  0.134,
+		"e2-standard-8":  0.268,
+		"e2-standard-16": 0.536,
+		"n2-standard-4":  0.194,
+		"n2-standard-8":  0.388,
+		"c3-standard-8":  0.418,
+	}
+	azureInstanceHourlyPrice = map[string]float64{
+		"Standard_F4s_v2": 0.169,
+		"Standard_F8s_v2": 0.338,
+		"Standard_D4s_v5": 0.192,
+		"Standard_D8s_v5": 0.384,
+		"Standard_E8s_v5": 0.504,
+	}
+	// per GB-month
+	azureDiskMonthlyPrice = map[string]float64{
+		"Premium_LRS":     0.135,
+		"StandardSSD_LRS": 0.075,
+		"Standard_LRS":    0.045,
+	}
+	// per GB-month
+	awsVolumeMonthlyPrice = map[string]float64{
+		"gp2": 0.10,
+		"gp3": 0.08,
+		"io1": 0.125,
+		"io2": 0.125,
+		"st1": 0.045,
+	}
+	// gp3 includes 3000 IOPS and 125 MB/s, the rest is billed per month
+	awsGp3BaselineIOPS       = 3000
+	awsGp3BaselineThroughput = 125
+	awsGp3IOPSMonthlyPrice   = 0.005
+	awsGp3MBpsMonthlyPrice   = 0.04
+	// public IPv4 addresses, both elastic and static
+	awsPublicIPHourlyPrice   = 0.005
+	gcpStaticIPHourlyPrice   = 0.005
+	azurePublicIPHourlyPrice = 0.005
+	// pd-standard per GB-month
+	gcpDiskMonthlyPrice = 0.04
+)
+
+// PlannedResource is a cloud resource CreateNodes would create or use
+type PlannedResource struct {
+	// Type of the resource, eg instance, security group, elastic IP
+	Type   string         `json:"type"`
+	Action ResourceAction `json:"action"`
+	// Name or ID of the resource, if known before it is created
+	Name string `json:"name"`
+	// Attributes are the settings the resource is created with
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// HourlyCost is the estimated USD cost per hour of the resource. Zero if
+	// the resource is free or its price is unknown
+	HourlyCost float64 `json:"hourlyCost"`
+}
+
+// Plan contains the resources that CreateNodes would create for a NodeParams, without
+// creating anything. It is built with NodeParams.Plan, reviewed, and executed with Apply
+type Plan struct {
+	Cloud  SupportedCloud
+	Region string
+	// Resources are in the order they are created
+	Resources []PlannedResource
+	// EstimatedHourlyCost is the sum of the resource hourly costs, in USD
+	EstimatedHourlyCost float64
+	// UnpricedResources lists the resources whose cost is not included in the estimate
+	UnpricedResources []string
+	// Fingerprint identifies the plan contents, so an approved plan can be checked to be
+	// the one being applied
+	Fingerprint string
+
+	params NodeParams
+}
+
+// EstimatedMonthlyCost is the estimated USD cost of running the plan resources for a month
+func (p *Plan) EstimatedMonthlyCost() float64 {
+	return p.EstimatedHourlyCost * hoursPerMonth
+}
+
+// Plan validates the params and returns the cloud resources that CreateNodes would create
+// for them, along with an estimated cost. Nothing is created, and no cloud API is called,
+// so the same params always give the same plan
+func (nodeParams *NodeParams) Plan() (*Plan, error) {
+	if nodeParams.CloudParams == nil {
+		return nil, fmt.Errorf("cloud params are required")
+	}
+	if err := preCreateCheck(*nodeParams.CloudParams, nodeParams.Count, nodeParams.UseStaticIP, nodeParams.SSHPrivateKeyPath); err != nil {
+		return nil, err
+	}
+	if err := CheckRoles(nodeParams.Roles); err != nil {
+		return nil, err
+	}
+	cp := nodeParams.CloudParams
+	p := &Plan{
+		Cloud:  cp.Cloud(),
+		Region: cp.Region,
+		params: nodeParams.clone(),
+	}
+	roleNames := make([]string, 0, len(nodeParams.Roles))
+	for _, role := range nodeParams.Roles {
+		roleNames = append(roleNames, role.String())
+	}
+	roles := strings.Join(roleNames, ",")
+	switch p.Cloud {
+	case AWSCloud:
+		aws := cp.AWSConfig
+		p.add(PlannedResource{
+			Type:   "key pair",
+			Action: ActionUseExisting,
+			Name:   aws.AWSKeyPair,
+		})
+		p.add(PlannedResource{
+			Type:       "security group",
+			Action:     ActionUseExisting,
+			Name:       aws.AWSSecurityGroupID,
+			Attributes: map[string]string{"name": aws.AWSSecurityGroupName},
+		})
+		instancePrice, instancePriced := awsInstanceHourlyPrice[cp.InstanceType]
+		volumePrice, volumePriced := awsVolumeHourlyCost(aws)
+		for i := 0; i < nodeParams.Count; i++ {
+			name := fmt.Sprintf("instance-%d", i)
+			p.add(PlannedResource{
+				Type:   "instance",
+				Action: ActionCreate,
+				Name:   name,
+				Attributes: map[string]string{
+					"image":                  cp.ImageID,
+					"instance type":          cp.InstanceType,
+					"key pair":               aws.AWSKeyPair,
+					"roles":                  roles,
+					"IMDSv2 required":        fmt.Sprint(aws.AWSRequireIMDSv2),
+					"termination protection": fmt.Sprint(aws.AWSTerminationProtection),
+				},
+				HourlyCost: instancePrice,
+			})
+			if !instancePriced {
+				p.UnpricedResources = append(p.UnpricedResources, name)
+			}
+			volumeName := name + "-volume"
+			p.add(PlannedResource{
+				Type:   "volume",
+				Action: ActionCreate,
+				Name:   volumeName,
+				Attributes: map[string]string{
+					"size":       fmt.Sprintf("%dGB", aws.AWSVolumeSize),
+					"type":       aws.AWSVolumeType,
+					"iops":       fmt.Sprint(aws.AWSVolumeIOPS),
+					"throughput": fmt.Sprint(aws.AWSVolumeThroughput),
+					"encrypted":  fmt.Sprint(aws.AWSEncryptVolume || aws.AWSKMSKeyID != ""),
+				},
+				HourlyCost: volumePrice,
+			})
+			if !volumePriced {
+				p.UnpricedResources = append(p.UnpricedResources, volumeName)
+			}
+			// instances get a public IPv4 either way, which AWS bills the same
+			ipType := "public IP"
+			if nodeParams.UseStaticIP {
+				ipType = "elastic IP"
+			}
+			p.add(PlannedResource{
+				Type:       ipType,
+				Action:     ActionCreate,
+				Name:       name + "-ip",
+				HourlyCost: awsPublicIPHourlyPrice,
+			})
+		}
+	case GCPCloud:
+		gcp := cp.GCPConfig
+		p.add(PlannedResource{
+			Type:   "network",
+			Action: ActionUseExisting,
+			Name:   gcp.GCPNetwork,
+		})
+		if nodeParams.UseStaticIP {
+			for i := 0; i < nodeParams.Count; i++ {
+				p.add(PlannedResource{
+					Type:       "static IP",
+					Action:     ActionCreate,
+					Name:       fmt.Sprintf("instance-%d-ip", i),
+					HourlyCost: gcpStaticIPHourlyPrice,
+				})
+			}
+		}
+		instancePrice, instancePriced := gcpInstanceHourlyPrice[cp.InstanceType]
+		diskPrice := float64(gcp.GCPVolumeSize) * gcpDiskMonthlyPrice / hoursPerMonth
+		for i := 0; i < nodeParams.Count; i++ {
+			name := fmt.Sprintf("instance-%d", i)
+			p.add(PlannedResource{
+				Type:   "instance",
+				Action: ActionCreate,
+				Name:   name,
+				Attributes: map[string]string{
+					"image":        cp.ImageID,
+					"machine type": cp.InstanceType,
+					"zone":         gcp.GCPZone,
+					"roles":        roles,
+				},
+				HourlyCost: instancePrice,
+			})
+			if !instancePriced {
+				p.UnpricedResources = append(p.UnpricedResources, name)
+			}
+			p.add(PlannedResource{
+				Type:       "disk",
+				Action:     ActionCreate,
+				Name:       name + "-disk",
+				Attributes: map[string]string{"size": fmt.Sprintf("%dGB", gcp.GCPVolumeSize)},
+				HourlyCost: diskPrice,
+			})
+		}
+	case AzureCloud:
+		azure := cp.AzureConfig
+		p.add(PlannedResource{
+			Type:   "SSH key",
+			Action: ActionUseExisting,
+			Name:   azure.AzureKeyName,
+		})
+		p.add(PlannedResource{
+			Type:   "network security group",
+			Action: ActionUseExisting,
+			Name:   azure.AzureSecurityGroup,
+		})
+		p.add(PlannedResource{
+			Type:   "virtual network",
+			Action: ActionUseExisting,
+			Name:   azure.AzureNetwork,
+		})
+		instancePrice, instancePriced := azureInstanceHourlyPrice[cp.InstanceType]
+		volumeType := azure.AzureVolumeType
+		if volumeType == "" {
+			volumeType = "Premium_LRS"
+		}
+		diskMonthlyPrice, diskPriced := azureDiskMonthlyPrice[volumeType]
+		diskPrice := float64(azure.AzureVolumeSize) * diskMonthlyPrice / hoursPerMonth
+		// VMs always get a static public IP, kept after deletion when UseStaticIP is set
+		ipType := "public IP"
+		if nodeParams.UseStaticIP {
+			ipType = "static IP"
+		}
+		for i := 0; i < nodeParams.Count; i++ {
+			name := fmt.Sprintf("vm-%d", i)
+			p.add(PlannedResource{
+				Type:   "VM",
+				Action: ActionCreate,
+				Name:   name,
+				Attributes: map[string]string{
+					"image":          cp.ImageID,
+					"size":           cp.InstanceType,
+					"location":       cp.Region,
+					"resource group": azure.AzureResourceGroup,
+					"roles":          roles,
+				},
+				HourlyCost: instancePrice,
+			})
+			if !instancePriced {
+				p.UnpricedResources = append(p.UnpricedResources, name)
+			}
+			diskName := name + "-disk"
+			p.add(PlannedResource{
+				Type:   "disk",
+				Action: ActionCreate,
+				Name:   diskName,
+				Attributes: map[string]string{
+					"size": fmt.Sprintf("%dGB", azure.AzureVolumeSize),
+					"type": volumeType,
+				},
+				HourlyCost: diskPrice,
+			})
+			if !diskPriced {
+				p.UnpricedResources = append(p.UnpricedResources, diskName)
+			}
+			p.add(PlannedResource{
+				Type:       ipType,
+				Action:     ActionCreate,
+				Name:       name + "-ip",
+				HourlyCost: azurePublicIPHourlyPrice,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cloud")
+	}
+	sum := sha256.Sum256([]byte(p.String()))
+	p.Fingerprint = hex.EncodeToString(sum[:])
+	return p, nil
+}
+
+func (p *Plan) add(resource PlannedResource) {
+	p.Resources = append(p.Resources, resource)
+	p.EstimatedHourlyCost += resource.HourlyCost
+}
+
+// awsVolumeHourlyCost returns the hourly cost of an EBS volume with the given settings
+func awsVolumeHourlyCost(aws *AWSConfig) (float64, bool) {
+	price, ok := awsVolumeMonthlyPrice[aws.AWSVolumeType]
+	if !ok {
+		return 0, false
+	}
+	monthly := float64(aws.AWSVolumeSize) * price
+	if aws.AWSVolumeType == "gp3" {
+		if aws.AWSVolumeIOPS > awsGp3BaselineIOPS {
+			monthly += float64(aws.AWSVolumeIOPS-awsGp3BaselineIOPS) * awsGp3IOPSMonthlyPrice
+		}
+		if aws.AWSVolumeThroughput > awsGp3BaselineThroughput {
+			monthly += float64(aws.AWSVolumeThroughput-awsGp3BaselineThroughput) * awsGp3MBpsMonthlyPrice
+		}
+	}
+	return monthly / hoursPerMonth, true
+}
+
+// String renders the plan for review
+func (p *Plan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Plan for %s in %s\n", p.Cloud.String(), p.Region)
+	for _, resource := range p.Resources {
+		fmt.Fprintf(&sb, "  %s %s %s", resource.Action, resource.Type, resource.Name)
+		if resource.HourlyCost > 0 {
+			fmt.Fprintf(&sb, " ($%.4f/h)", resource.HourlyCost)
+		}
+		sb.WriteString("\n")
+		keys := make([]string, 0, len(resource.Attributes))
+		for key := range resource.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&sb, "      %s: %s\n", key, resource.Attributes[key])
+		}
+	}
+	fmt.Fprintf(&sb, "Estimated cost: $%.2f/h, $%.2f/month\n", p.EstimatedHourlyCost, p.EstimatedMonthlyCost())
+	if len(p.UnpricedResources) > 0 {
+		fmt.Fprintf(&sb, "Not included in the estimate: %s\n", strings.Join(p.UnpricedResources, ", "))
+	}
+	return sb.String()
+}
+
+type planJSON struct {
+	SchemaVersion        int               `json:"schemaVersion"`
+	Cloud                string            `json:"cloud"`
+	Region               string            `json:"region"`
+	Resources            []PlannedResource `json:"resources"`
+	EstimatedHourlyCost  float64           `json:"estimatedHourlyCost"`
+	EstimatedMonthlyCost float64           `json:"estimatedMonthlyCost"`
+	UnpricedResources    []string          `json:"unpricedResources"`
+	Fingerprint          string            `json:"fingerprint"`
+}
+
+// MarshalJSON serializes the plan for review with a stable schema, versioned by PlanSchemaVersion
+func (p Plan) MarshalJSON() ([]byte, error) {
+	out := planJSON{
+		SchemaVersion:        PlanSchemaVersion,
+		Cloud:                p.Cloud.String(),
+		Region:               p.Region,
+		Resources:            p.Resources,
+		EstimatedHourlyCost:  p.EstimatedHourlyCost,
+		EstimatedMonthlyCost: p.EstimatedMonthlyCost(),
+		UnpricedResources:    p.UnpricedResources,
+		Fingerprint:          p.Fingerprint,
+	}
+	if out.Resources == nil {
+		out.Resources = []PlannedResource{}
+	}
+	if out.UnpricedResources == nil {
+		out.UnpricedResources = []string{}
+	}
+	return json.Marshal(out)
+}
+
+// Apply creates the nodes described by the plan, with the params it was created from.
+// Changes made to those params after calling Plan have no effect
+func (p *Plan) Apply(ctx context.Context) ([]Node, error) {
+	params := p.params.clone()
+	return CreateNodes(ctx, &params)
+}
+
+// clone returns a copy of the params that doesn't share the cloud configuration
+func (nodeParams *NodeParams) clone() NodeParams {
+	params := *nodeParams
+	params.Roles = append([]SupportedRole{}, nodeParams.Roles...)
+	params.SubnetIDs = append([]string{}, nodeParams.SubnetIDs...)
+	if nodeParams.CloudParams != nil {
+		cp := *nodeParams.CloudParams
+		if cp.AWSConfig != nil {
+			aws := *cp.AWSConfig
+			cp.AWSConfig = &aws
+		}
+		if cp.GCPConfig != nil {
+			gcp := *cp.GCPConfig
+			cp.GCPConfig = &gcp
+		}
+		if cp.AzureConfig != nil {
+			azure := *cp.AzureConfig
+			cp.AzureConfig = &azure
+		}
+		params.CloudParams = &cp
+	}
+	if nodeParams.TLS != nil {
+		tls := *nodeParams.TLS
+		params.TLS = &tls
+	}
+	return params
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testAWSNodeParams() *NodeParams {
+	return &NodeParams{
+		CloudParams: &CloudParams{
+			Region:       "us-east-1",
+			ImageID:      "ami-123",
+			InstanceType: "c5.2xlarge",
+			AWSConfig: &AWSConfig{
+				AWSProfile:           "default",
+				AWSKeyPair:           "kp",
+				AWSVolumeSize:        1000,
+				AWSVolumeType:        "gp3",
+				AWSVolumeIOPS:        1000,
+				AWSVolumeThroughput:  500,
+				AWSSecurityGroupID:   "sg-123",
+				AWSSecurityGroupName: "avalanche",
+			},
+		},
+		Count:       2,
+		Roles:       []SupportedRole{Validator},
+		UseStaticIP: true,
+	}
+}
+
+func TestPlanAWS(t *testing.T) {
+	require := require.New(t)
+	params := testAWSNodeParams()
+	plan, err := params.Plan()
+	require.NoError(err)
+	require.Equal(AWSCloud, plan.Cloud)
+	// key pair + security group + (instance + volume + eip) per node
+	require.Len(plan.Resources, 2+3*2)
+	require.Equal(ActionUseExisting, plan.Resources[0].Action)
+	require.Equal("elastic IP", plan.Resources[4].Type)
+	require.Empty(plan.UnpricedResources)
+	// 0.34 instance + (1000*0.08 + 375*0.04)/730 volume + 0.005 ip
+	require.InDelta(2*(0.34+95.0/730+0.005), plan.EstimatedHourlyCost, 1e-9)
+	require.InDelta(plan.EstimatedHourlyCost*730, plan.EstimatedMonthlyCost(), 1e-9)
+	require.Contains(plan.String(), "create instance instance-1")
+
+	again, err := params.Plan()
+	require.NoError(err)
+	require.Equal(plan.Fingerprint, again.Fingerprint)
+
+	// params changed after planning don't affect the plan
+	params.CloudParams.InstanceType = "unknown.large"
+	params.Count = 5
+	require.Equal("c5.2xlarge", plan.params.CloudParams.InstanceType)
+	require.Equal(2, plan.params.Count)
+	changed, err := params.Plan()
+	require.NoError(err)
+	require.NotEqual(plan.Fingerprint, changed.Fingerprint)
+	require.Len(changed.UnpricedResources, 5)
+}
+
+func TestPlanValidates(t *testing.T) {
+	params := testAWSNodeParams()
+	params.Count = 0
+	_, err := params.Plan()
+	require.ErrorContains(t, err, "count must be at least 1")
+
+	params = testAWSNodeParams()
+	params.CloudParams.AWSConfig.AWSKeyPair = ""
+	_, err = params.Plan()
+	require.ErrorContains(t, err, "key pair is required")
+
+	params = testAWSNodeParams()
+	params.CloudParams.AWSConfig.AWSDisablePublicIP = true
+	params.UseStaticIP = false
+	_, err = params.Plan()
+	require.ErrorContains(t, err, "public IP can only be disabled for nodes with a static IP")
+}
+
+func TestPlanJSON(t *testing.T) {
+	require := require.New(t)
+	plan, err := testAWSNodeParams().Plan()
+	require.NoError(err)
+	bytes, err := json.Marshal(plan)
+	require.NoError(err)
+	out := map[string]interface{}{}
+	require.NoError(json.Unmarshal(bytes, &out))
+	require.Equal(float64(PlanSchemaVersion), out["schemaVersion"])
+	require.Equal("aws", out["cloud"])
+	require.Equal(plan.Fingerprint, out["fingerprint"])
+	require.Equal([]interface{}{}, out["unpricedResources"])
+	require.InDelta(plan.EstimatedMonthlyCost(), out["estimatedMonthlyCost"], 1e-9)
+	resources, ok := out["resources"].([]interface{})
+	require.True(ok)
+	require.Len(resources, len(plan.Resources))
+	require.Equal(map[string]interface{}{
+		"type":       "key pair",
+		"action":     "use existing",
+		"name":       "kp",
+		"hourlyCost": 0.0,
+	}, resources[0])
+}
+
+func TestPlanAzure(t *testing.T) {
+	require := require.New(t)
+	params := &NodeParams{
+		CloudParams: &CloudParams{
+			Region:       "eastus",
+			ImageID:      "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest",
+			InstanceType: "Standard_F8s_v2",
+			AzureConfig: &AzureConfig{
+				AzureSubscriptionID: "00000000-0000-0000-0000-000000000000",
+				AzureResourceGroup:  "avalanche",
+				AzureNetwork:        "avalanche-vnet",
+				AzureSecurityGroup:  "avalanche-nsg",
+				AzureKeyName:        "avalanche-key",
+				AzureVolumeSize:     1000,
+			},
+		},
+		Count: 2,
+		Roles: []SupportedRole{Validator},
+	}
+	plan, err := params.Plan()
+	require.NoError(err)
+	require.Equal(AzureCloud, plan.Cloud)
+	// key + security group + network + (vm + disk + ip) per node
+	require.Len(plan.Resources, 3+3*2)
+	require.Equal("public IP", plan.Resources[5].Type)
+	require.Equal("Premium_LRS", plan.Resources[4].Attributes["type"])
+	require.Empty(plan.UnpricedResources)
+	require.InDelta(2*(0.338+135.0/730+0.005), plan.EstimatedHourlyCost, 1e-9)
+
+	params.CloudParams.AzureConfig.AzureKeyName = ""
+	_, err = params.Plan()
+	require.ErrorContains(err, "SSH key name is required")
+}
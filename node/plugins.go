@@ -0,0 +1,128 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var ErrInvalidVMPlugin = errors.New("invalid VM plugin")
+
+// VMPlugin is a custom VM binary needed by a subnet the node tracks. avalanchego loads
+// it from its plugin dir, where it must be named after its VM ID
+type VMPlugin struct {
+	VMID ids.ID
+
+	// LocalPath of the binary, uploaded to the node. Only one of LocalPath and URL
+	// must be set
+	LocalPath string
+
+	// URL of the binary, or of a .tar.gz release archive containing it, downloaded
+	// by the node
+	URL string
+
+	// ArchiveBinary is the path of the binary inside the archive, if URL is a .tar.gz
+	// release archive, eg. "subnet-evm"
+	ArchiveBinary string
+
+	// SHA256 is the expected hex checksum of the file at URL. Optional
+	SHA256 string
+}
+
+// Validate checks that [p] has a VM ID and a single source for the binary
+func (p VMPlugin) Validate() error {
+	switch {
+	case p.VMID == ids.Empty:
+		return fmt.Errorf("%w: VM ID is not set", ErrInvalidVMPlugin)
+	case p.LocalPath == "" && p.URL == "":
+		return fmt.Errorf("%w: neither a local path nor a URL are set for VM %s", ErrInvalidVMPlugin, p.VMID)
+	case p.LocalPath != "" && p.URL != "":
+		return fmt.Errorf("%w: both a local path and a URL are set for VM %s", ErrInvalidVMPlugin, p.VMID)
+	case p.LocalPath != "" && !utils.FileExists(p.LocalPath):
+		return fmt.Errorf("%w: %s does not exist", ErrInvalidVMPlugin, p.LocalPath)
+	case p.isArchive() && p.ArchiveBinary == "":
+		return fmt.Errorf("%w: the binary path inside %s is not set", ErrInvalidVMPlugin, p.URL)
+	}
+	return nil
+}
+
+func (p VMPlugin) isArchive() bool {
+	return strings.HasSuffix(p.URL, ".tar.gz") || strings.HasSuffix(p.URL, ".tgz")
+}
+
+// RemoteVMPluginPath is where the plugin of [vmID] is installed on the node
+func RemoteVMPluginPath(vmID ids.ID) string {
+	return filepath.Join(constants.CloudNodePluginsPath, vmID.String())
+}
+
+// downloadCommand is the shell command that downloads [p.URL] into [remotePath],
+// verifying its checksum and extracting it if needed
+func (p VMPlugin) downloadCommand(remotePath string) string {
+	commands := []string{
+		"set -e",
+		"tmp=$(mktemp -d)",
+		"trap 'rm -rf $tmp' EXIT",
+		fmt.Sprintf("curl -fsSL -o $tmp/download '%s'", p.URL),
+	}
+	if p.SHA256 != "" {
+		commands = append(commands, fmt.Sprintf("echo '%s  '$tmp/download | sha256sum -c -", strings.ToLower(p.SHA256)))
+	}
+	downloaded := "$tmp/download"
+	if p.isArchive() {
+		commands = append(commands, "mkdir $tmp/archive", "tar -xzf $tmp/download -C $tmp/archive")
+		downloaded = "$tmp/archive/" + strings.TrimPrefix(p.ArchiveBinary, "/")
+	}
+	commands = append(commands, fmt.Sprintf("mv %s %s", downloaded, remotePath))
+	return strings.Join(commands, "; ")
+}
+
+// InstallVMPlugins makes sure that [plugins] are present on the node plugin dir,
+// uploading or downloading the missing ones, and makes them executable. Plugins already
+// installed are not replaced
+func (h *Node) InstallVMPlugins(plugins []VMPlugin) error {
+	for _, plugin := range plugins {
+		if err := plugin.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(plugins) == 0 {
+		return nil
+	}
+	if err := h.MkdirAll(constants.CloudNodePluginsPath, constants.SSHFileOpsTimeout); err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		remotePath := RemoteVMPluginPath(plugin.VMID)
+		exists, err := h.FileExists(remotePath)
+		if err != nil {
+			return err
+		}
+		if exists {
+			h.Logger.Infof("VM plugin %s already installed on %s", plugin.VMID, h.NodeID)
+			continue
+		}
+		if plugin.LocalPath != "" {
+			h.Logger.Infof("Uploading VM plugin %s from %s to %s", plugin.VMID, plugin.LocalPath, h.NodeID)
+			if err := h.UploadResumable(plugin.LocalPath, remotePath, UploadOptions{VerifyChecksum: true}); err != nil {
+				return fmt.Errorf("failure uploading VM plugin %s to %s: %w", plugin.VMID, h.NodeID, err)
+			}
+		} else {
+			h.Logger.Infof("Downloading VM plugin %s from %s on %s", plugin.VMID, plugin.URL, h.NodeID)
+			if output, err := h.Command(nil, constants.SSHLongRunningScriptTimeout, plugin.downloadCommand(remotePath)); err != nil {
+				return fmt.Errorf("failure downloading VM plugin %s on %s: %w: %s", plugin.VMID, h.NodeID, err, string(output))
+			}
+		}
+		if _, err := h.Commandf(nil, constants.SSHScriptTimeout, "chmod 755 %s", remotePath); err != nil {
+			return fmt.Errorf("failure making VM plugin %s executable on %s: %w", plugin.VMID, h.NodeID, err)
+		}
+	}
+	return nil
+}
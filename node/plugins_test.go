@@ -0,0 +1,57 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMPluginValidate(t *testing.T) {
+	require := require.New(t)
+	vmID := ids.GenerateTestID()
+	localPath := filepath.Join(t.TempDir(), "plugin")
+	require.NoError(os.WriteFile(localPath, []byte("bin"), 0o600))
+
+	require.NoError(VMPlugin{VMID: vmID, LocalPath: localPath}.Validate())
+	require.NoError(VMPlugin{VMID: vmID, URL: "https://example.com/vm"}.Validate())
+	require.NoError(VMPlugin{VMID: vmID, URL: "https://example.com/vm.tar.gz", ArchiveBinary: "vm"}.Validate())
+	for _, plugin := range []VMPlugin{
+		{LocalPath: localPath},
+		{VMID: vmID},
+		{VMID: vmID, LocalPath: localPath, URL: "https://example.com/vm"},
+		{VMID: vmID, LocalPath: localPath + "-missing"},
+		{VMID: vmID, URL: "https://example.com/vm.tar.gz"},
+	} {
+		require.ErrorIs(plugin.Validate(), ErrInvalidVMPlugin)
+	}
+}
+
+func TestVMPluginDownloadCommand(t *testing.T) {
+	require := require.New(t)
+	vmID := ids.GenerateTestID()
+	remotePath := RemoteVMPluginPath(vmID)
+	require.Equal("/home/ubuntu/.avalanchego/plugins/"+vmID.String(), remotePath)
+
+	plugin := VMPlugin{VMID: vmID, URL: "https://example.com/vm"}
+	command := plugin.downloadCommand(remotePath)
+	require.Contains(command, "curl -fsSL -o $tmp/download 'https://example.com/vm'")
+	require.NotContains(command, "sha256sum")
+	require.Contains(command, "mv $tmp/download "+remotePath)
+
+	plugin = VMPlugin{
+		VMID:          vmID,
+		URL:           "https://example.com/subnet-evm_0.6.4_linux_amd64.tar.gz",
+		ArchiveBinary: "subnet-evm",
+		SHA256:        "ABCD",
+	}
+	command = plugin.downloadCommand(remotePath)
+	require.Contains(command, "echo 'abcd  '$tmp/download | sha256sum -c -")
+	require.Contains(command, "tar -xzf $tmp/download -C $tmp/archive")
+	require.Contains(command, "mv $tmp/archive/subnet-evm "+remotePath)
+}
@@ -0,0 +1,19 @@
+#!/usr/bin/env bash
+set -e
+export DEBIAN_FRONTEND=noninteractive
+APT_OPTS='-o Dpkg::Options::=--force-confdef -o Dpkg::Options::=--force-confold'
+
+sudo apt-get -y update
+{{if .PatchSecurityOnly }}
+if ! dpkg -s unattended-upgrades >/dev/null 2>&1; then
+    sudo -E apt-get -y $APT_OPTS install unattended-upgrades
+fi
+sudo unattended-upgrade -v
+{{ else }}
+sudo -E apt-get -y $APT_OPTS upgrade
+sudo -E apt-get -y autoremove
+{{ end }}
+
+if [ -f /var/run/reboot-required ]; then
+    echo "{{ .RebootRequiredMarker }}"
+fi
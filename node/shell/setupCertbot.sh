@@ -0,0 +1,23 @@
+#!/usr/bin/env bash
+export DEBIAN_FRONTEND=noninteractive
+set -e
+
+if ! dpkg -s certbot >/dev/null 2>&1; then
+    sudo apt-get -y update && sudo apt-get -y install certbot
+fi
+
+# HTTP-01 challenge: certbot binds port 80, so the reverse proxy is stopped meanwhile.
+# Hooks are persisted in the renewal config and also apply to certbot renew
+sudo mkdir -p /etc/letsencrypt
+sudo certbot certonly --standalone --non-interactive --agree-tos --keep-until-expiring \
+    -m "{{ .TLSEmail }}" -d "{{ .TLSDomain }}"{{ if .TLSStaging }} --staging{{ end }} \
+    --pre-hook "docker stop nginx || true" \
+    --post-hook "docker start nginx || true"
+
+# renewal cron, twice a day as recommended by Let's Encrypt
+cat <<CRON | sudo tee /etc/cron.d/certbot-renew
+SHELL=/bin/sh
+PATH=/usr/local/sbin:/usr/local/bin:/sbin:/bin:/usr/sbin:/usr/bin
+0 */12 * * * root certbot renew --quiet
+CRON
+sudo chmod 644 /etc/cron.d/certbot-renew
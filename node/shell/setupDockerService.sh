@@ -20,11 +20,11 @@ Requires=docker.service
 After=docker.service
 
 [Service]
-User=ubuntu
-Group=ubuntu
+User={{ .User }}
+Group={{ .User }}
 Restart=on-failure
-ExecStart=/usr/bin/docker compose -f /home/ubuntu/.avalanche-cli/services/docker-compose.yml up 
-ExecStop=/usr/bin/docker compose -f /home/ubuntu/.avalanche-cli/services/docker-compose.yml down
+ExecStart=/usr/bin/docker compose -f {{ .HomeDir }}/.avalanche-cli/services/docker-compose.yml up 
+ExecStop=/usr/bin/docker compose -f {{ .HomeDir }}/.avalanche-cli/services/docker-compose.yml down
 
 [Install]
 WantedBy=multi-user.target
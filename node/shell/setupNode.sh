@@ -1,6 +1,17 @@
 #!/usr/bin/env bash
 export DEBIAN_FRONTEND=noninteractive
 
+{{if .Offline}}
+# offline mode: install the uploaded packages, no apt or network fetches
+if ls {{.OfflineDir}}/packages/*.deb >/dev/null 2>&1; then
+    sudo dpkg -i {{.OfflineDir}}/packages/*.deb
+fi
+
+if ! command -v docker >/dev/null 2>&1; then
+    echo "docker is not installed and can't be fetched in offline mode. include its packages in the offline artifacts"
+    exit 1
+fi
+{{else}}
 if ! dpkg -s busybox-static software-properties-common >/dev/null 2>&1; then
     sudo apt-get -y update && sudo apt-get -y install busybox-static software-properties-common
 fi
@@ -15,7 +26,14 @@ if ! dpkg -s docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-c
     echo deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/docker.asc] https://download.docker.com/linux/ubuntu $(. /etc/os-release && echo \"$VERSION_CODENAME\") stable | sudo tee /etc/apt/sources.list.d/docker.list >/dev/null
     sudo apt-get -y update && sudo apt-get -y install docker-ce docker-ce-cli containerd.io docker-buildx-plugin docker-compose-plugin docker-compose
 fi
+{{end}}
 
 sudo usermod -aG docker ubuntu
 sudo chgrp ubuntu /var/run/docker.sock
 sudo chmod +rw /var/run/docker.sock
+{{if .Offline}}
+for image in {{.OfflineDir}}/images/*.tar; do
+    [ -e "$image" ] || continue
+    docker load -i "$image"
+done
+{{end}}
@@ -24,7 +24,9 @@ import (
 	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 )
 
-type scriptInputs struct {
+// ScriptInputs are the template vars of the embedded shell scripts, see RunOverSSH
+// and Cluster.RunScript
+type ScriptInputs struct {
 	AvalancheGoVersion   string
 	SubnetExportFileName string
 	SubnetName           string
@@ -44,35 +46,47 @@ type scriptInputs struct {
 	CheckoutCommit       bool
 	LoadTestResultFile   string
 	GrafanaPkg           string
+	TLSDomain            string
+	TLSEmail             string
+	TLSStaging           bool
+	PatchSecurityOnly    bool
+	RebootRequiredMarker string
+	Offline              bool
+	OfflineDir           string
+	// User and HomeDir are the node user and its home directory
+	User    string
+	HomeDir string
 }
 
 //go:embed shell/*.sh
 var script embed.FS
 
 // RunOverSSH runs provided script path over ssh.
-// This script can be template as it will be rendered using scriptInputs vars
+// This script can be template as it will be rendered using ScriptInputs vars
 func (h *Node) RunOverSSH(
 	scriptDesc string,
 	timeout time.Duration,
 	scriptPath string,
-	templateVars scriptInputs,
+	templateVars ScriptInputs,
+) error {
+	return h.RunOverSSHContext(context.Background(), scriptDesc, timeout, scriptPath, templateVars)
+}
+
+// RunOverSSHContext is like RunOverSSH, but the remote script is interrupted when [ctx] is canceled
+func (h *Node) RunOverSSHContext(
+	ctx context.Context,
+	scriptDesc string,
+	timeout time.Duration,
+	scriptPath string,
+	templateVars ScriptInputs,
 ) error {
 	startTime := time.Now()
-	shellScript, err := script.ReadFile(scriptPath)
-	if err != nil {
-		return err
-	}
-	var script bytes.Buffer
-	t, err := template.New(scriptDesc).Parse(string(shellScript))
-	if err != nil {
-		return err
-	}
-	err = t.Execute(&script, templateVars)
+	script, err := renderScript(scriptDesc, scriptPath, templateVars)
 	if err != nil {
 		return err
 	}
 
-	if output, err := h.Command(nil, timeout, script.String()); err != nil {
+	if output, err := h.CommandContext(ctx, nil, timeout, script); err != nil {
 		return fmt.Errorf("%w: %s", err, string(output))
 	}
 	executionTime := time.Since(startTime)
@@ -80,13 +94,42 @@ func (h *Node) RunOverSSH(
 	return nil
 }
 
+// renderScript renders the embedded script template at [scriptPath] using [templateVars].
+// See SetTemplateOverrides to replace the embedded scripts
+func renderScript(scriptDesc string, scriptPath string, templateVars ScriptInputs) (string, error) {
+	shellScript, err := readEmbedded(script, scriptPath)
+	if err != nil {
+		return "", err
+	}
+	var script bytes.Buffer
+	t, err := template.New(scriptDesc).Parse(string(shellScript))
+	if err != nil {
+		return "", err
+	}
+	if err := t.Execute(&script, templateVars); err != nil {
+		return "", err
+	}
+	return script.String(), nil
+}
+
 // RunSSHSetupNode runs script to setup sdk dependencies on a remote host over SSH.
-func (h *Node) RunSSHSetupNode() error {
-	if err := h.RunOverSSH(
+// See WithOfflineArtifacts for nodes without outbound internet access
+func (h *Node) RunSSHSetupNode(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
+	inputs := ScriptInputs{}
+	if o.offline != nil {
+		if err := h.UploadOfflineArtifacts(*o.offline); err != nil {
+			return err
+		}
+		inputs.Offline = true
+		inputs.OfflineDir = remoteOfflineDir
+	}
+	if err := h.RunOverSSHContext(
+		o.ctx,
 		"Setup Node",
-		constants.SSHLongRunningScriptTimeout,
+		o.timeout,
 		"shell/setupNode.sh",
-		scriptInputs{},
+		inputs,
 	); err != nil {
 		return err
 	}
@@ -94,13 +137,15 @@ func (h *Node) RunSSHSetupNode() error {
 }
 
 // RunSSHSetupDockerService runs script to setup docker compose service for CLI
-func (h *Node) RunSSHSetupDockerService() error {
+func (h *Node) RunSSHSetupDockerService(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
 	if h.HasSystemDAvailable() {
-		return h.RunOverSSH(
+		return h.RunOverSSHContext(
+			o.ctx,
 			"Setup Docker Service",
-			constants.SSHLongRunningScriptTimeout,
+			o.timeout,
 			"shell/setupDockerService.sh",
-			scriptInputs{},
+			ScriptInputs{User: h.SSHConfig.User, HomeDir: h.ExpandHome("")},
 		)
 	} else {
 		// no need to setup docker service
@@ -109,23 +154,41 @@ func (h *Node) RunSSHSetupDockerService() error {
 }
 
 // RunSSHRestartAvalanchego runs script to restart avalanchego
-func (h *Node) RunSSHRestartAvalanchego() error {
+func (h *Node) RunSSHRestartAvalanchego(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
 	remoteComposeFile := utils.GetRemoteComposeFile()
-	return h.RestartDockerComposeService(remoteComposeFile, constants.ServiceAvalanchego, constants.SSHLongRunningScriptTimeout)
+	return h.restartDockerComposeService(o.ctx, remoteComposeFile, constants.ServiceAvalanchego, o.timeout)
 }
 
 // RunSSHStartAWMRelayerService runs script to start an AWM Relayer Service
-func (h *Node) RunSSHStartAWMRelayerService() error {
-	return h.StartDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceAWMRelayer, constants.SSHLongRunningScriptTimeout)
+func (h *Node) RunSSHStartAWMRelayerService(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
+	return h.startDockerComposeService(o.ctx, utils.GetRemoteComposeFile(), constants.ServiceAWMRelayer, o.timeout)
 }
 
 // RunSSHStopAWMRelayerService runs script to start an AWM Relayer Service
-func (h *Node) RunSSHStopAWMRelayerService() error {
-	return h.StopDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceAWMRelayer, constants.SSHLongRunningScriptTimeout)
+func (h *Node) RunSSHStopAWMRelayerService(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
+	return h.stopDockerComposeService(o.ctx, utils.GetRemoteComposeFile(), constants.ServiceAWMRelayer, o.timeout)
 }
 
 // RunSSHUpgradeAvalanchego runs script to upgrade avalanchego
-func (h *Node) RunSSHUpgradeAvalanchego(avalancheGoVersion string) error {
+func (h *Node) RunSSHUpgradeAvalanchego(avalancheGoVersion string, opts ...SSHOption) error {
+	return h.runSSHUpgradeAvalanchego(avalancheGoVersion, "", opts...)
+}
+
+// RunSSHUpgradePinnedAvalanchego upgrades avalanchego to [avalancheGoVersion], refusing
+// to restart the node if the pulled image does not match [avalancheGoDigest]
+func (h *Node) RunSSHUpgradePinnedAvalanchego(avalancheGoVersion string, avalancheGoDigest string, opts ...SSHOption) error {
+	image := fmt.Sprintf("%s:%s", constants.AvalancheGoDockerImage, avalancheGoVersion)
+	if err := h.PreparePinnedDockerImage(image, avalancheGoDigest); err != nil {
+		return err
+	}
+	return h.runSSHUpgradeAvalanchego(avalancheGoVersion, avalancheGoDigest, opts...)
+}
+
+func (h *Node) runSSHUpgradeAvalanchego(avalancheGoVersion string, avalancheGoDigest string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
 	withMonitoring, err := h.WasNodeSetupWithMonitoring()
 	if err != nil {
 		return err
@@ -136,6 +199,7 @@ func (h *Node) RunSSHUpgradeAvalanchego(avalancheGoVersion string) error {
 		"templates/avalanchego.docker-compose.yml",
 		dockerComposeInputs{
 			AvalanchegoVersion: avalancheGoVersion,
+			AvalanchegoDigest:  avalancheGoDigest,
 			WithMonitoring:     withMonitoring,
 			WithAvalanchego:    true,
 			E2E:                utils.IsE2E(),
@@ -144,30 +208,34 @@ func (h *Node) RunSSHUpgradeAvalanchego(avalancheGoVersion string) error {
 		}); err != nil {
 		return err
 	}
-	return h.RestartDockerCompose(constants.SSHLongRunningScriptTimeout)
+	return h.restartDockerCompose(o.ctx, o.timeout)
 }
 
 // RunSSHStartAvalanchego runs script to start avalanchego
-func (h *Node) RunSSHStartAvalanchego() error {
-	return h.StartDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceAvalanchego, constants.SSHLongRunningScriptTimeout)
+func (h *Node) RunSSHStartAvalanchego(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
+	return h.startDockerComposeService(o.ctx, utils.GetRemoteComposeFile(), constants.ServiceAvalanchego, o.timeout)
 }
 
 // RunSSHStopAvalanchego runs script to stop avalanchego
-func (h *Node) RunSSHStopAvalanchego() error {
-	return h.StopDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceAvalanchego, constants.SSHLongRunningScriptTimeout)
+func (h *Node) RunSSHStopAvalanchego(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHLongRunningScriptTimeout, opts...)
+	return h.stopDockerComposeService(o.ctx, utils.GetRemoteComposeFile(), constants.ServiceAvalanchego, o.timeout)
 }
 
 // RunSSHUpgradeSubnetEVM runs script to upgrade subnet evm
-func (h *Node) RunSSHUpgradeSubnetEVM(subnetEVMBinaryPath string) error {
-	if _, err := h.Commandf(nil, constants.SSHScriptTimeout, "cp -f subnet-evm %s", subnetEVMBinaryPath); err != nil {
+func (h *Node) RunSSHUpgradeSubnetEVM(subnetEVMBinaryPath string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHScriptTimeout, opts...)
+	if _, err := h.CommandContextf(o.ctx, nil, o.timeout, "cp -f subnet-evm %s", subnetEVMBinaryPath); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (h *Node) RunSSHSetupPrometheusConfig(avalancheGoPorts, machinePorts, loadTestPorts []string) error {
+func (h *Node) RunSSHSetupPrometheusConfig(avalancheGoPorts, machinePorts, loadTestPorts, metricsExporterPorts []string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	for _, folder := range remoteconfig.PrometheusFoldersToCreate() {
-		if err := h.MkdirAll(folder, constants.SSHFileOpsTimeout); err != nil {
+		if err := h.MkdirAll(folder, o.timeout); err != nil {
 			return err
 		}
 	}
@@ -177,20 +245,21 @@ func (h *Node) RunSSHSetupPrometheusConfig(avalancheGoPorts, machinePorts, loadT
 		return err
 	}
 	defer os.Remove(promConfig.Name())
-	if err := monitoring.WritePrometheusConfig(promConfig.Name(), avalancheGoPorts, machinePorts, loadTestPorts); err != nil {
+	if err := monitoring.WritePrometheusConfig(promConfig.Name(), avalancheGoPorts, machinePorts, loadTestPorts, metricsExporterPorts); err != nil {
 		return err
 	}
 
 	return h.Upload(
 		promConfig.Name(),
 		cloudNodePrometheusConfigTemp,
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	)
 }
 
-func (h *Node) RunSSHSetupLokiConfig(port int) error {
+func (h *Node) RunSSHSetupLokiConfig(port int, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	for _, folder := range remoteconfig.LokiFoldersToCreate() {
-		if err := h.MkdirAll(folder, constants.SSHFileOpsTimeout); err != nil {
+		if err := h.MkdirAll(folder, o.timeout); err != nil {
 			return err
 		}
 	}
@@ -206,13 +275,14 @@ func (h *Node) RunSSHSetupLokiConfig(port int) error {
 	return h.Upload(
 		lokiConfig.Name(),
 		cloudNodeLokiConfigTemp,
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	)
 }
 
-func (h *Node) RunSSHSetupPromtailConfig(lokiIP string, lokiPort int, cloudID string, nodeID string, chainID string) error {
+func (h *Node) RunSSHSetupPromtailConfig(lokiIP string, lokiPort int, cloudID string, nodeID string, chainID string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	for _, folder := range remoteconfig.PromtailFoldersToCreate() {
-		if err := h.MkdirAll(folder, constants.SSHFileOpsTimeout); err != nil {
+		if err := h.MkdirAll(folder, o.timeout); err != nil {
 			return err
 		}
 	}
@@ -229,65 +299,70 @@ func (h *Node) RunSSHSetupPromtailConfig(lokiIP string, lokiPort int, cloudID st
 	return h.Upload(
 		promtailConfig.Name(),
 		cloudNodePromtailConfigTemp,
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	)
 }
 
-func (h *Node) RunSSHUploadNodeAWMRelayerConfig(nodeInstanceDirPath string) error {
+func (h *Node) RunSSHUploadNodeAWMRelayerConfig(nodeInstanceDirPath string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	cloudAWMRelayerConfigDir := filepath.Join(constants.CloudNodeCLIConfigBasePath, constants.ServicesDir, constants.AWMRelayerInstallDir)
-	if err := h.MkdirAll(cloudAWMRelayerConfigDir, constants.SSHFileOpsTimeout); err != nil {
+	if err := h.MkdirAll(cloudAWMRelayerConfigDir, o.timeout); err != nil {
 		return err
 	}
 	return h.Upload(
 		filepath.Join(nodeInstanceDirPath, constants.ServicesDir, constants.AWMRelayerInstallDir, constants.AWMRelayerConfigFilename),
 		filepath.Join(cloudAWMRelayerConfigDir, constants.AWMRelayerConfigFilename),
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	)
 }
 
 // RunSSHGetNewSubnetEVMRelease runs script to download new subnet evm
-func (h *Node) RunSSHGetNewSubnetEVMRelease(subnetEVMReleaseURL, subnetEVMArchive string) error {
-	return h.RunOverSSH(
+func (h *Node) RunSSHGetNewSubnetEVMRelease(subnetEVMReleaseURL, subnetEVMArchive string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHScriptTimeout, opts...)
+	return h.RunOverSSHContext(
+		o.ctx,
 		"Get Subnet EVM Release",
-		constants.SSHScriptTimeout,
+		o.timeout,
 		"shell/getNewSubnetEVMRelease.sh",
-		scriptInputs{SubnetEVMReleaseURL: subnetEVMReleaseURL, SubnetEVMArchive: subnetEVMArchive},
+		ScriptInputs{SubnetEVMReleaseURL: subnetEVMReleaseURL, SubnetEVMArchive: subnetEVMArchive},
 	)
 }
 
 // RunSSHUploadStakingFiles uploads staking files to a remote host via SSH.
-func (h *Node) RunSSHUploadStakingFiles(keyPath string) error {
+func (h *Node) RunSSHUploadStakingFiles(keyPath string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	if err := h.MkdirAll(
 		constants.CloudNodeStakingPath,
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	); err != nil {
 		return err
 	}
 	if err := h.Upload(
 		filepath.Join(keyPath, constants.StakerCertFileName),
 		filepath.Join(constants.CloudNodeStakingPath, constants.StakerCertFileName),
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	); err != nil {
 		return err
 	}
 	if err := h.Upload(
 		filepath.Join(keyPath, constants.StakerKeyFileName),
 		filepath.Join(constants.CloudNodeStakingPath, constants.StakerKeyFileName),
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	); err != nil {
 		return err
 	}
 	return h.Upload(
 		filepath.Join(keyPath, constants.BLSKeyFileName),
 		filepath.Join(constants.CloudNodeStakingPath, constants.BLSKeyFileName),
-		constants.SSHFileOpsTimeout,
+		o.timeout,
 	)
 }
 
 // RunSSHSetupMonitoringFolders sets up monitoring folders
-func (h *Node) RunSSHSetupMonitoringFolders() error {
+func (h *Node) RunSSHSetupMonitoringFolders(opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	for _, folder := range remoteconfig.RemoteFoldersToCreateMonitoring() {
-		if err := h.MkdirAll(folder, constants.SSHFileOpsTimeout); err != nil {
+		if err := h.MkdirAll(folder, o.timeout); err != nil {
 			return err
 		}
 	}
@@ -339,6 +414,8 @@ func (h *Node) MonitorNodes(ctx context.Context, targets []Node, chainID string)
 				nodeResults.AddResult(target.NodeID, nil, err)
 				return
 			}
+			withMetricsExporter, err := target.HasMetricsExporter()
+			nodeResults.AddResult(target.NodeID, withMetricsExporter, err)
 		}(&wgResults, target)
 	}
 	wg.Wait()
@@ -361,7 +438,8 @@ func (h *Node) MonitorNodes(ctx context.Context, targets []Node, chainID string)
 		return err
 	}
 	avalancheGoPorts, machinePorts, ltPorts := getPrometheusTargets(targets)
-	h.Logger.Infof("avalancheGoPorts: %v, machinePorts: %v, ltPorts: %v", avalancheGoPorts, machinePorts, ltPorts)
+	metricsExporterPorts := getMetricsExporterTargets(targets, wgResults.GetResultMap())
+	h.Logger.Infof("avalancheGoPorts: %v, machinePorts: %v, ltPorts: %v, metricsExporterPorts: %v", avalancheGoPorts, machinePorts, ltPorts, metricsExporterPorts)
 	// reconfigure monitoring instance
 	if err := h.RunSSHSetupLokiConfig(constants.AvalanchegoLokiPort); err != nil {
 		return err
@@ -369,7 +447,7 @@ func (h *Node) MonitorNodes(ctx context.Context, targets []Node, chainID string)
 	if err := h.RestartDockerComposeService(remoteComposeFile, constants.ServiceLoki, constants.SSHScriptTimeout); err != nil {
 		return err
 	}
-	if err := h.RunSSHSetupPrometheusConfig(avalancheGoPorts, machinePorts, ltPorts); err != nil {
+	if err := h.RunSSHSetupPrometheusConfig(avalancheGoPorts, machinePorts, ltPorts, metricsExporterPorts); err != nil {
 		return err
 	}
 	if err := h.RestartDockerComposeService(remoteComposeFile, constants.ServicePrometheus, constants.SSHScriptTimeout); err != nil {
@@ -379,8 +457,9 @@ func (h *Node) MonitorNodes(ctx context.Context, targets []Node, chainID string)
 	return nil
 }
 
-// SyncSubnets reconfigures avalanchego to sync subnets
-func (h *Node) SyncSubnets(subnetsToTrack []string) error {
+// SyncSubnets reconfigures avalanchego to sync subnets. The VM [plugins] needed by
+// custom VM subnets are installed beforehand, if missing. See InstallVMPlugins
+func (h *Node) SyncSubnets(subnetsToTrack []string, plugins ...VMPlugin) error {
 	// necessary checks
 	if !isAvalancheGoNode(*h) {
 		return fmt.Errorf("%s is not a avalanchego node", h.NodeID)
@@ -392,6 +471,9 @@ func (h *Node) SyncSubnets(subnetsToTrack []string) error {
 	if err := h.WaitForSSHShell(constants.SSHScriptTimeout); err != nil {
 		return err
 	}
+	if err := h.InstallVMPlugins(plugins); err != nil {
+		return err
+	}
 	avagoVersion, err := h.GetDockerImageVersion(constants.AvalancheGoDockerImage, constants.SSHScriptTimeout)
 	if err != nil {
 		return err
@@ -410,13 +492,14 @@ func (h *Node) SyncSubnets(subnetsToTrack []string) error {
 	return nil
 }
 
-func (h *Node) RunSSHCopyMonitoringDashboards(monitoringDashboardPath string) error {
+func (h *Node) RunSSHCopyMonitoringDashboards(monitoringDashboardPath string, opts ...SSHOption) error {
+	o := newSSHOptions(constants.SSHFileOpsTimeout, opts...)
 	// TODO: download dashboards from github instead
 	remoteDashboardsPath := utils.GetRemoteComposeServicePath("grafana", "dashboards")
 	if !utils.DirectoryExists(monitoringDashboardPath) {
 		return fmt.Errorf("%s does not exist", monitoringDashboardPath)
 	}
-	if err := h.MkdirAll(remoteDashboardsPath, constants.SSHFileOpsTimeout); err != nil {
+	if err := h.MkdirAll(remoteDashboardsPath, o.timeout); err != nil {
 		return err
 	}
 	monitoringDashboardPath = filepath.Join(monitoringDashboardPath, constants.DashboardsDir)
@@ -428,13 +511,13 @@ func (h *Node) RunSSHCopyMonitoringDashboards(monitoringDashboardPath string) er
 		if err := h.Upload(
 			filepath.Join(monitoringDashboardPath, dashboard.Name()),
 			filepath.Join(remoteDashboardsPath, dashboard.Name()),
-			constants.SSHFileOpsTimeout,
+			o.timeout,
 		); err != nil {
 			return err
 		}
 	}
 	if composeFileExists(*h) {
-		return h.RestartDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceGrafana, constants.SSHScriptTimeout)
+		return h.restartDockerComposeService(o.ctx, utils.GetRemoteComposeFile(), constants.ServiceGrafana, constants.SSHScriptTimeout)
 	}
 	return nil
 }
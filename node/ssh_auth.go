@@ -0,0 +1,136 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/melbahja/goph"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+// sshCertificateSuffix is the suffix OpenSSH uses for the certificate of a private key
+const sshCertificateSuffix = "-cert.pub"
+
+var ErrNoSSHIdentityLoaded = errors.New("none of the ssh identities could be loaded")
+
+// SSHIdentity is a candidate credential used to authenticate against a node
+type SSHIdentity struct {
+	// Path to the private key
+	PrivateKeyPath string
+
+	// Passphrase of the private key, if it is encrypted. If empty and the key is
+	// encrypted, SSHConfig.PassphraseCallback is used to obtain it
+	Passphrase string
+
+	// Path to an OpenSSH certificate for the private key, signed by an SSH CA.
+	// If empty, PrivateKeyPath + "-cert.pub" is used when it exists
+	CertificatePath string
+}
+
+// SSHPassphraseCallback is called to obtain the passphrase of the encrypted private key
+// at [privateKeyPath], eg by prompting the user
+type SSHPassphraseCallback func(privateKeyPath string) (string, error)
+
+// identities returns the identities of the config in the order they are tried:
+// PrivateKeyPath first, then Identities
+func (c SSHConfig) identities() []SSHIdentity {
+	identities := []SSHIdentity{}
+	if c.PrivateKeyPath != "" {
+		identities = append(identities, SSHIdentity{
+			PrivateKeyPath:  c.PrivateKeyPath,
+			CertificatePath: c.CertificatePath,
+		})
+	}
+	return append(identities, c.Identities...)
+}
+
+// auth returns the SSH authentication methods for the config. If the config has no
+// identities, the SSH agent is used. Keys are only loaded when the server asks for
+// them, so passphrase callbacks are not run until connecting
+func (c SSHConfig) auth() (goph.Auth, error) {
+	identities := c.identities()
+	if len(identities) == 0 {
+		return goph.UseAgent()
+	}
+	// the server is offered the signers in order until one is accepted
+	return goph.Auth{ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		return loadSigners(identities, c.PassphraseCallback)
+	})}, nil
+}
+
+// loadSigners loads the signers of [identities], skipping the ones that fail to load,
+// eg. a missing key file or a canceled passphrase prompt. It fails only if none of
+// them can be loaded
+func loadSigners(identities []SSHIdentity, passphraseCallback SSHPassphraseCallback) ([]ssh.Signer, error) {
+	signers := make([]ssh.Signer, 0, len(identities))
+	errs := []error{}
+	for _, identity := range identities {
+		signer, err := identity.signer(passphraseCallback)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrNoSSHIdentityLoaded, errors.Join(errs...))
+	}
+	return signers, nil
+}
+
+// signer loads the private key of the identity, decrypting it with its passphrase
+// or [passphraseCallback] if needed, and wraps it with its certificate if any
+func (identity SSHIdentity) signer(passphraseCallback SSHPassphraseCallback) (ssh.Signer, error) {
+	keyBytes, err := os.ReadFile(identity.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failure reading ssh private key %s: %w", identity.PrivateKeyPath, err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	var passphraseMissingErr *ssh.PassphraseMissingError
+	if errors.As(err, &passphraseMissingErr) {
+		passphrase := identity.Passphrase
+		if passphrase == "" {
+			if passphraseCallback == nil {
+				return nil, fmt.Errorf("ssh private key %s is encrypted and no passphrase was given", identity.PrivateKeyPath)
+			}
+			passphrase, err = passphraseCallback(identity.PrivateKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failure obtaining passphrase for ssh private key %s: %w", identity.PrivateKeyPath, err)
+			}
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failure parsing ssh private key %s: %w", identity.PrivateKeyPath, err)
+	}
+	certificatePath := identity.CertificatePath
+	if certificatePath == "" {
+		if !utils.FileExists(identity.PrivateKeyPath + sshCertificateSuffix) {
+			return signer, nil
+		}
+		certificatePath = identity.PrivateKeyPath + sshCertificateSuffix
+	}
+	certBytes, err := os.ReadFile(certificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failure reading ssh certificate %s: %w", certificatePath, err)
+	}
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(certBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failure parsing ssh certificate %s: %w", certificatePath, err)
+	}
+	cert, ok := pubKey.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ssh certificate", certificatePath)
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, fmt.Errorf("ssh certificate %s does not match private key %s: %w", certificatePath, identity.PrivateKeyPath, err)
+	}
+	return certSigner, nil
+}
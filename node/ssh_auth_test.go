@@ -0,0 +1,167 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func writeTestSSHKey(t *testing.T, dir string, name string, passphrase string) (string, ssh.Signer) {
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(privKey, name)
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(privKey, name, []byte(passphrase))
+	}
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	signer, err := ssh.NewSignerFromKey(privKey)
+	require.NoError(t, err)
+	return keyPath, signer
+}
+
+func TestSSHIdentitySigner(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+
+	plainPath, plainSigner := writeTestSSHKey(t, dir, "plain", "")
+	signer, err := SSHIdentity{PrivateKeyPath: plainPath}.signer(nil)
+	require.NoError(err)
+	require.Equal(plainSigner.PublicKey().Marshal(), signer.PublicKey().Marshal())
+
+	encryptedPath, encryptedSigner := writeTestSSHKey(t, dir, "encrypted", "secret")
+	_, err = SSHIdentity{PrivateKeyPath: encryptedPath}.signer(nil)
+	require.ErrorContains(err, "no passphrase was given")
+	signer, err = SSHIdentity{PrivateKeyPath: encryptedPath, Passphrase: "secret"}.signer(nil)
+	require.NoError(err)
+	require.Equal(encryptedSigner.PublicKey().Marshal(), signer.PublicKey().Marshal())
+	prompted := ""
+	signer, err = SSHIdentity{PrivateKeyPath: encryptedPath}.signer(func(keyPath string) (string, error) {
+		prompted = keyPath
+		return "secret", nil
+	})
+	require.NoError(err)
+	require.Equal(encryptedPath, prompted)
+	require.Equal(encryptedSigner.PublicKey().Marshal(), signer.PublicKey().Marshal())
+	_, err = SSHIdentity{PrivateKeyPath: encryptedPath}.signer(func(string) (string, error) {
+		return "", fmt.Errorf("prompt canceled")
+	})
+	require.ErrorContains(err, "prompt canceled")
+}
+
+func TestSSHIdentityCertificate(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	_, caSigner := writeTestSSHKey(t, dir, "ca", "")
+	keyPath, keySigner := writeTestSSHKey(t, dir, "user", "")
+	cert := &ssh.Certificate{
+		Key:             keySigner.PublicKey(),
+		CertType:        ssh.UserCert,
+		KeyId:           "user",
+		ValidPrincipals: []string{"ubuntu"},
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	require.NoError(cert.SignCert(rand.Reader, caSigner))
+	require.NoError(os.WriteFile(keyPath+sshCertificateSuffix, ssh.MarshalAuthorizedKey(cert), 0o600))
+
+	// certificate found next to the private key
+	signer, err := SSHIdentity{PrivateKeyPath: keyPath}.signer(nil)
+	require.NoError(err)
+	signerCert, ok := signer.PublicKey().(*ssh.Certificate)
+	require.True(ok)
+	require.Equal("user", signerCert.KeyId)
+
+	// explicit certificate not matching the key
+	otherPath, _ := writeTestSSHKey(t, dir, "other", "")
+	_, err = SSHIdentity{PrivateKeyPath: otherPath, CertificatePath: keyPath + sshCertificateSuffix}.signer(nil)
+	require.ErrorContains(err, "does not match")
+}
+
+func TestSSHConfigIdentities(t *testing.T) {
+	require := require.New(t)
+	config := SSHConfig{
+		PrivateKeyPath: "main",
+		Identities: []SSHIdentity{
+			{PrivateKeyPath: "second"},
+			{PrivateKeyPath: "third"},
+		},
+	}
+	paths := []string{}
+	for _, identity := range config.identities() {
+		paths = append(paths, identity.PrivateKeyPath)
+	}
+	require.Equal([]string{"main", "second", "third"}, paths)
+	require.Empty(SSHConfig{}.identities())
+}
+
+func TestSSHConfigAuth(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	plainPath, plainSigner := writeTestSSHKey(t, dir, "plain", "")
+	encryptedPath, _ := writeTestSSHKey(t, dir, "encrypted", "secret")
+	prompts := 0
+	config := SSHConfig{
+		PrivateKeyPath: filepath.Join(dir, "missing"),
+		Identities: []SSHIdentity{
+			{PrivateKeyPath: encryptedPath},
+			{PrivateKeyPath: plainPath},
+		},
+		PassphraseCallback: func(string) (string, error) {
+			prompts++
+			return "", fmt.Errorf("prompt canceled")
+		},
+	}
+	_, err := config.auth()
+	require.NoError(err)
+	require.Zero(prompts)
+
+	// identities failing to load are skipped
+	signers, err := loadSigners(config.identities(), config.PassphraseCallback)
+	require.NoError(err)
+	require.Equal(1, prompts)
+	require.Len(signers, 1)
+	require.Equal(plainSigner.PublicKey().Marshal(), signers[0].PublicKey().Marshal())
+
+	_, err = loadSigners(config.identities()[:2], config.PassphraseCallback)
+	require.ErrorIs(err, ErrNoSSHIdentityLoaded)
+	require.ErrorContains(err, "prompt canceled")
+}
+
+func TestSSHConfigAuthConnect(t *testing.T) {
+	require := require.New(t)
+	server := newTestSSHServer(t, func(string, []string) ([]byte, uint32) {
+		return nil, 0
+	})
+	h := Node{
+		IP: "127.0.0.1",
+		SSHConfig: SSHConfig{
+			User:           "ubuntu",
+			PrivateKeyPath: filepath.Join(t.TempDir(), "missing"),
+			Identities:     []SSHIdentity{{PrivateKeyPath: server.keyPath}},
+		},
+	}
+	require.NoError(h.Connect(server.port))
+	require.NoError(h.Disconnect())
+
+	h = Node{
+		IP: "127.0.0.1",
+		SSHConfig: SSHConfig{
+			User:           "ubuntu",
+			PrivateKeyPath: filepath.Join(t.TempDir(), "missing"),
+		},
+	}
+	require.ErrorContains(h.Connect(server.port), ErrNoSSHIdentityLoaded.Error())
+}
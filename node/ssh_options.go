@@ -0,0 +1,55 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"time"
+)
+
+// SSHOption overrides the defaults used by the RunSSH* helpers
+type SSHOption func(*sshOptions)
+
+type sshOptions struct {
+	ctx     context.Context
+	timeout time.Duration
+	offline *OfflineArtifacts
+}
+
+// WithSSHContext sets the context used to run the remote commands. When [ctx] is
+// canceled, a SIGINT is sent to the remote command and the helper returns.
+// File operations are not affected by [ctx]
+func WithSSHContext(ctx context.Context) SSHOption {
+	return func(o *sshOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithSSHTimeout overrides the timeout associated to the command class of the helper
+// (eg constants.SSHScriptTimeout, constants.SSHLongRunningScriptTimeout, constants.SSHFileOpsTimeout)
+func WithSSHTimeout(timeout time.Duration) SSHOption {
+	return func(o *sshOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithOfflineArtifacts makes RunSSHSetupNode work on nodes without outbound internet
+// access: [artifacts] are uploaded from the operator machine and installed from there,
+// and the setup script skips all apt and network fetches. Other helpers ignore it
+func WithOfflineArtifacts(artifacts OfflineArtifacts) SSHOption {
+	return func(o *sshOptions) {
+		o.offline = &artifacts
+	}
+}
+
+func newSSHOptions(defaultTimeout time.Duration, opts ...SSHOption) sshOptions {
+	o := sshOptions{
+		ctx:     context.Background(),
+		timeout: defaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
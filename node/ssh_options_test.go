@@ -0,0 +1,36 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSSHOptions(t *testing.T) {
+	require := require.New(t)
+
+	o := newSSHOptions(constants.SSHScriptTimeout)
+	require.Equal(constants.SSHScriptTimeout, o.timeout)
+	require.Equal(context.Background(), o.ctx)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o = newSSHOptions(constants.SSHScriptTimeout, WithSSHContext(ctx), WithSSHTimeout(time.Second))
+	require.Equal(time.Second, o.timeout)
+	require.Equal(ctx, o.ctx)
+}
+
+func TestWithOfflineArtifacts(t *testing.T) {
+	require := require.New(t)
+	o := newSSHOptions(constants.SSHScriptTimeout)
+	require.Nil(o.offline)
+	artifacts := OfflineArtifacts{DockerImages: []string{"avalanchego.tar"}}
+	o = newSSHOptions(constants.SSHScriptTimeout, WithOfflineArtifacts(artifacts))
+	require.Equal(&artifacts, o.offline)
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testCommandHandler answers a command received by a testSSHServer, returning its
+// combined output and exit status
+type testCommandHandler func(command string, env []string) ([]byte, uint32)
+
+// testSSHServer is an in-process SSH server that accepts the key at keyPath, and
+// answers the exec requests with its handler
+type testSSHServer struct {
+	port    uint
+	keyPath string
+	handler testCommandHandler
+}
+
+func newTestSSHServer(t *testing.T, handler testCommandHandler) *testSSHServer {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+	clientPub, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authorizedKey, err := ssh.NewPublicKey(clientPub)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, errors.New("unauthorized key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	server := &testSSHServer{
+		port:    uint(listener.Addr().(*net.TCPAddr).Port),
+		keyPath: keyPath,
+		handler: handler,
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config)
+		}
+	}()
+	return server
+}
+
+// node returns a node connected to the server
+func (s *testSSHServer) node(t *testing.T, nodeID string) Node {
+	h := Node{
+		NodeID:    nodeID,
+		IP:        "127.0.0.1",
+		SSHConfig: SSHConfig{User: "ubuntu", PrivateKeyPath: s.keyPath},
+	}
+	require.NoError(t, h.Connect(s.port))
+	t.Cleanup(func() { _ = h.Disconnect() })
+	return h
+}
+
+func (s *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(channel, requests)
+	}
+}
+
+func (s *testSSHServer) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	env := []string{}
+	for req := range requests {
+		switch req.Type {
+		case "env":
+			var kv struct{ Name, Value string }
+			if err := ssh.Unmarshal(req.Payload, &kv); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			env = append(env, kv.Name+"="+kv.Value)
+			_ = req.Reply(true, nil)
+		case "exec":
+			var exec struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &exec); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			output, status := s.handler(exec.Command, env)
+			_, _ = channel.Write(output)
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+			return
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// StateSyncPeer is a healthy node new nodes state sync from, instead of from random
+// peers of the network, which shortens their bootstrap on large networks
+type StateSyncPeer struct {
+	NodeID ids.NodeID
+	// IP is the staking address of the peer, in IP:port format
+	IP string
+}
+
+// stateSyncFlags returns the state-sync-ids and state-sync-ips values for [peers]
+func stateSyncFlags(peers []StateSyncPeer) (string, string) {
+	nodeIDs := make([]string, len(peers))
+	ips := make([]string, len(peers))
+	for i, peer := range peers {
+		nodeIDs[i] = peer.NodeID.String()
+		ips[i] = peer.IP
+	}
+	return strings.Join(nodeIDs, ","), strings.Join(ips, ",")
+}
+
+// GetAvalancheGoNodeID returns the node ID of the avalanchego running on the node
+func (h *Node) GetAvalancheGoNodeID() (ids.NodeID, error) {
+	requestBody := "{\"jsonrpc\":\"2.0\", \"id\":1,\"method\":\"info.getNodeID\"}"
+	resp, err := h.Post("/ext/info", requestBody)
+	if err != nil {
+		return ids.EmptyNodeID, err
+	}
+	return parseNodeIDOutput(resp)
+}
+
+func parseNodeIDOutput(byteValue []byte) (ids.NodeID, error) {
+	reply := struct {
+		Result info.GetNodeIDReply `json:"result"`
+	}{}
+	if err := json.Unmarshal(byteValue, &reply); err != nil {
+		return ids.EmptyNodeID, err
+	}
+	if reply.Result.NodeID == ids.EmptyNodeID {
+		return ids.EmptyNodeID, fmt.Errorf("unable to parse node ID")
+	}
+	return reply.Result.NodeID, nil
+}
+
+// GetStateSyncPeer returns the node as a state sync peer. It fails if avalanchego is
+// not healthy on the node
+func (h *Node) GetStateSyncPeer() (StateSyncPeer, error) {
+	isHealthy, err := h.GetAvalancheGoHealth()
+	if err != nil {
+		return StateSyncPeer{}, err
+	}
+	if !isHealthy {
+		return StateSyncPeer{}, fmt.Errorf("avalanchego is not healthy on node %s", h.NodeID)
+	}
+	nodeID, err := h.GetAvalancheGoNodeID()
+	if err != nil {
+		return StateSyncPeer{}, err
+	}
+	return StateSyncPeer{
+		NodeID: nodeID,
+		IP:     fmt.Sprintf("%s:%d", h.IP, constants.AvalanchegoP2PPort),
+	}, nil
+}
+
+// StateSyncPeers returns the healthy avalanchego nodes of the cluster as state sync
+// peers. Nodes that are not running avalanchego, or are not healthy, are left out
+func (c *Cluster) StateSyncPeers() []StateSyncPeer {
+	peers := make([]*StateSyncPeer, len(c.Nodes))
+	wg := sync.WaitGroup{}
+	for i := range c.Nodes {
+		if !c.Nodes[i].runsAvalancheGo() {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			peer, err := c.Nodes[i].GetStateSyncPeer()
+			if err != nil {
+				c.Nodes[i].Logger.Infof("node %s not used as state sync peer: %s", c.Nodes[i].NodeID, err)
+				return
+			}
+			peers[i] = &peer
+		}(i)
+	}
+	wg.Wait()
+	healthyPeers := []StateSyncPeer{}
+	for _, peer := range peers {
+		if peer != nil {
+			healthyPeers = append(healthyPeers, *peer)
+		}
+	}
+	return healthyPeers
+}
+
+// AddNode creates new nodes as CreateNodes does, and adds them to the cluster. If
+// [nodeParams] has no StateSyncPeers, the healthy avalanchego nodes already in the
+// cluster are used, so the new nodes state sync from them
+func (c *Cluster) AddNode(ctx context.Context, nodeParams *NodeParams) ([]Node, error) {
+	if len(nodeParams.StateSyncPeers) == 0 {
+		params := *nodeParams
+		params.StateSyncPeers = c.StateSyncPeers()
+		nodeParams = &params
+	}
+	nodes, err := CreateNodes(ctx, nodeParams)
+	if err != nil {
+		return nodes, err
+	}
+	c.Nodes = append(c.Nodes, nodes...)
+	return nodes, nil
+}
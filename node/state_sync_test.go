@@ -0,0 +1,48 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"encoding/json"
+	"testing"
+
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSyncPeersConfig(t *testing.T) {
+	require := require.New(t)
+	peers := []StateSyncPeer{
+		{NodeID: ids.GenerateTestNodeID(), IP: "10.0.0.1:9651"},
+		{NodeID: ids.GenerateTestNodeID(), IP: "10.0.0.2:9651"},
+	}
+	avagoConf := remoteconfig.PrepareAvalancheConfig("10.0.0.3", "fuji", nil)
+	avagoConf.StateSyncIDs, avagoConf.StateSyncIPs = stateSyncFlags(peers)
+	configBytes, err := remoteconfig.RenderAvalancheNodeConfig(avagoConf)
+	require.NoError(err)
+	config := map[string]interface{}{}
+	require.NoError(json.Unmarshal(configBytes, &config))
+	require.Equal(peers[0].NodeID.String()+","+peers[1].NodeID.String(), config["state-sync-ids"])
+	require.Equal("10.0.0.1:9651,10.0.0.2:9651", config["state-sync-ips"])
+
+	// no peers, no flags
+	avagoConf.StateSyncIDs, avagoConf.StateSyncIPs = stateSyncFlags(nil)
+	configBytes, err = remoteconfig.RenderAvalancheNodeConfig(avagoConf)
+	require.NoError(err)
+	config = map[string]interface{}{}
+	require.NoError(json.Unmarshal(configBytes, &config))
+	require.NotContains(config, "state-sync-ids")
+	require.NotContains(config, "state-sync-ips")
+}
+
+func TestParseNodeIDOutput(t *testing.T) {
+	require := require.New(t)
+	nodeID := ids.GenerateTestNodeID()
+	parsed, err := parseNodeIDOutput([]byte(`{"jsonrpc":"2.0","result":{"nodeID":"` + nodeID.String() + `"},"id":1}`))
+	require.NoError(err)
+	require.Equal(nodeID, parsed)
+	_, err = parseNodeIDOutput([]byte(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"not found"},"id":1}`))
+	require.Error(err)
+}
@@ -14,6 +14,7 @@ const (
 	AWSCloud SupportedCloud = iota
 	GCPCloud
 	Docker // fake Cloud used for E2E tests
+	AzureCloud
 	Unknown
 )
 
@@ -35,6 +36,8 @@ func NewSupportedCloud(s string) SupportedCloud {
 		return GCPCloud
 	case "docker":
 		return Docker
+	case "azure":
+		return AzureCloud
 	default:
 		return Unknown
 	}
@@ -49,6 +52,8 @@ func (c *SupportedCloud) String() string {
 		return "gcp"
 	case Docker:
 		return "docker"
+	case AzureCloud:
+		return "azure"
 	default:
 		return "unknown"
 	}
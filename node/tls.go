@@ -0,0 +1,211 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/cloud/dns"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+// ACMEChallenge is the challenge used to prove domain ownership to Let's Encrypt
+type ACMEChallenge int
+
+const (
+	// HTTP01 is solved by certbot on the node. Requires port 80 to be publicly reachable.
+	// Renewal is done by a cron job on the node
+	HTTP01 ACMEChallenge = iota
+	// DNS01 is solved from the SDK using a dns.Provider. Renewal is done by calling
+	// RenewTLSCertificate periodically, as the DNS credentials are not available on the node
+	DNS01
+)
+
+const (
+	tlsCertFileName = "fullchain.pem"
+	tlsKeyFileName  = "privkey.pem"
+)
+
+var (
+	ErrTLSDomainNotProvided = errors.New("TLS domain not provided")
+	ErrTLSEmailNotProvided  = errors.New("TLS contact email not provided")
+	ErrTLSInvalidDomain     = errors.New("invalid TLS domain")
+	ErrTLSInvalidEmail      = errors.New("invalid TLS contact email")
+	ErrTLSDNSNotProvided    = errors.New("DNS provider and zone are required for DNS-01 challenge")
+)
+
+var (
+	// tlsDomainRegex matches fully qualified host names, eg. rpc.mychain.example.com
+	tlsDomainRegex = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)+[A-Za-z]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+	// tlsEmailLocalPartRegex matches the part before @ of plain email addresses, without
+	// display names or quoted parts
+	tlsEmailLocalPartRegex = regexp.MustCompile(`^[A-Za-z0-9._%+-]+$`)
+)
+
+// TLSParams configures TLS termination for the public RPC endpoint of an API node
+type TLSParams struct {
+	// Domain is the public name of the RPC endpoint (eg rpc.mychain.example.com). It must
+	// already resolve to the node IP (see cloud/dns)
+	Domain string
+	// Email is the contact email for the Let's Encrypt account
+	Email string
+	// Challenge is the ACME challenge type to use
+	Challenge ACMEChallenge
+	// DNSProvider and DNSZone are used to solve DNS01 challenges
+	DNSProvider dns.Provider
+	DNSZone     string
+	// AccountKey is the ACME account key used for DNS01. If nil, a new account is registered
+	AccountKey crypto.Signer
+	// Staging uses the Let's Encrypt staging environment, useful for tests
+	Staging bool
+}
+
+func (p TLSParams) validate() error {
+	if p.Domain == "" {
+		return ErrTLSDomainNotProvided
+	}
+	if p.Email == "" {
+		return ErrTLSEmailNotProvided
+	}
+	// both are passed to certbot on the node command line
+	if !isValidTLSDomain(p.Domain) {
+		return fmt.Errorf("%w: %q", ErrTLSInvalidDomain, p.Domain)
+	}
+	localPart, emailDomain, found := strings.Cut(p.Email, "@")
+	if !found || !tlsEmailLocalPartRegex.MatchString(localPart) || !isValidTLSDomain(emailDomain) {
+		return fmt.Errorf("%w: %q", ErrTLSInvalidEmail, p.Email)
+	}
+	if p.Challenge == DNS01 && (p.DNSProvider == nil || p.DNSZone == "") {
+		return ErrTLSDNSNotProvided
+	}
+	return nil
+}
+
+// isValidTLSDomain checks if [domain] is a fully qualified host name
+func isValidTLSDomain(domain string) bool {
+	return len(domain) <= 253 && tlsDomainRegex.MatchString(domain)
+}
+
+// remoteCertFile returns the path of the certificate on the node filesystem
+func (p TLSParams) remoteCertFile() string {
+	if p.Challenge == HTTP01 {
+		return filepath.Join("/etc/letsencrypt/live", p.Domain, tlsCertFileName)
+	}
+	return filepath.Join(remoteconfig.GetRemoteNginxCertsDir(), tlsCertFileName)
+}
+
+// SetupTLS provisions a Let's Encrypt certificate for [params.Domain] and sets up an nginx
+// reverse proxy terminating TLS on port 443 in front of the avalanchego API.
+// Ports 80 and 443 must be publicly reachable on the node
+func (h *Node) SetupTLS(params TLSParams) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+	for _, folder := range remoteconfig.NginxFoldersToCreate() {
+		if err := h.MkdirAll(folder, constants.SSHFileOpsTimeout); err != nil {
+			return err
+		}
+	}
+	switch params.Challenge {
+	case HTTP01:
+		if err := h.RunOverSSH(
+			"Setup Certbot",
+			constants.SSHLongRunningScriptTimeout,
+			"shell/setupCertbot.sh",
+			ScriptInputs{TLSDomain: params.Domain, TLSEmail: params.Email, TLSStaging: params.Staging},
+		); err != nil {
+			return err
+		}
+	case DNS01:
+		if err := h.issueAndUploadCertificate(params); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported ACME challenge %d", params.Challenge)
+	}
+	nginxConfig, err := remoteconfig.RenderNginxConfig(remoteconfig.PrepareNginxConfig(params.Domain, params.Challenge == HTTP01))
+	if err != nil {
+		return err
+	}
+	if err := h.UploadBytes(nginxConfig, remoteconfig.GetRemoteNginxConfig(), constants.SSHFileOpsTimeout); err != nil {
+		return err
+	}
+	if err := h.ComposeOverSSH("Setup TLS Proxy",
+		constants.SSHScriptTimeout,
+		"templates/nginx.docker-compose.yml",
+		dockerComposeInputs{}); err != nil {
+		return err
+	}
+	return h.StartDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceNginx, constants.SSHScriptTimeout)
+}
+
+// GetTLSCertificateExpiry returns the expiration time of the certificate served by the node
+func (h *Node) GetTLSCertificateExpiry(params TLSParams) (time.Time, error) {
+	output, err := h.Commandf(nil, constants.SSHScriptTimeout, "sudo cat %s", params.remoteCertFile())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", err, string(output))
+	}
+	return certificateExpiry(output)
+}
+
+// RenewTLSCertificate renews the DNS01 certificate of the node if it expires in less than
+// [renewBefore], restarting the reverse proxy. Returns true if the certificate was renewed.
+// HTTP01 certificates are renewed by certbot on the node
+func (h *Node) RenewTLSCertificate(params TLSParams, renewBefore time.Duration) (bool, error) {
+	if err := params.validate(); err != nil {
+		return false, err
+	}
+	if params.Challenge != DNS01 {
+		return false, fmt.Errorf("certificates for challenge %d are renewed on the node", params.Challenge)
+	}
+	expiry, err := h.GetTLSCertificateExpiry(params)
+	if err != nil {
+		return false, err
+	}
+	if time.Until(expiry) > renewBefore {
+		return false, nil
+	}
+	if err := h.issueAndUploadCertificate(params); err != nil {
+		return false, err
+	}
+	return true, h.RestartDockerComposeService(utils.GetRemoteComposeFile(), constants.ServiceNginx, constants.SSHScriptTimeout)
+}
+
+func (h *Node) issueAndUploadCertificate(params TLSParams) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.SSHLongRunningScriptTimeout)
+	defer cancel()
+	certPEM, keyPEM, err := issueCertificateDNS01(ctx, params)
+	if err != nil {
+		return err
+	}
+	certsDir := remoteconfig.GetRemoteNginxCertsDir()
+	if err := h.UploadBytes(certPEM, filepath.Join(certsDir, tlsCertFileName), constants.SSHFileOpsTimeout); err != nil {
+		return err
+	}
+	return h.UploadBytes(keyPEM, filepath.Join(certsDir, tlsKeyFileName), constants.SSHFileOpsTimeout)
+}
+
+// certificateExpiry returns the NotAfter of the first certificate in [certPEM]
+func certificateExpiry(certPEM []byte) (time.Time, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, fmt.Errorf("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
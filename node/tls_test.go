@@ -0,0 +1,79 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/cloud/dns"
+	remoteconfig "github.com/ava-labs/avalanche-tooling-sdk-go/node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSParamsValidate(t *testing.T) {
+	require := require.New(t)
+	require.ErrorIs(TLSParams{Email: "a@b.c"}.validate(), ErrTLSDomainNotProvided)
+	require.ErrorIs(TLSParams{Domain: "rpc.example.com"}.validate(), ErrTLSEmailNotProvided)
+	require.ErrorIs(TLSParams{Domain: "rpc.example.com", Email: "a@b.c", Challenge: DNS01}.validate(), ErrTLSDNSNotProvided)
+	for _, domain := range []string{"localhost", "rpc.example.com; reboot", "-d.example.com", "rpc..example.com", "rpc.example.com -d x.org"} {
+		require.ErrorIs(TLSParams{Domain: domain, Email: "a@b.c"}.validate(), ErrTLSInvalidDomain, domain)
+	}
+	for _, email := range []string{"a", "a@b", "a b@example.com", "$(id)@example.com", "a@example.com -d x.org"} {
+		require.ErrorIs(TLSParams{Domain: "rpc.example.com", Email: email}.validate(), ErrTLSInvalidEmail, email)
+	}
+	require.NoError(TLSParams{Domain: "rpc-1.my-chain.example.com", Email: "ops+tls@my-chain.example.com"}.validate())
+	require.NoError(TLSParams{Domain: "rpc.example.com", Email: "a@b.c"}.validate())
+	require.NoError(TLSParams{Domain: "rpc.example.com", Email: "a@b.c", Challenge: DNS01, DNSProvider: &dns.Route53{}, DNSZone: "Z1"}.validate())
+}
+
+func TestRenderTLSConfigs(t *testing.T) {
+	require := require.New(t)
+	nginxConfig, err := remoteconfig.RenderNginxConfig(remoteconfig.PrepareNginxConfig("rpc.example.com", true))
+	require.NoError(err)
+	require.Contains(string(nginxConfig), "server_name rpc.example.com;")
+	require.Contains(string(nginxConfig), "ssl_certificate /etc/letsencrypt/live/rpc.example.com/fullchain.pem;")
+	require.Contains(string(nginxConfig), "proxy_pass http://127.0.0.1:9650;")
+
+	script, err := renderScript("Setup Certbot", "shell/setupCertbot.sh", ScriptInputs{TLSDomain: "rpc.example.com", TLSEmail: "a@b.c", TLSStaging: true})
+	require.NoError(err)
+	require.Contains(script, `-m "a@b.c" -d "rpc.example.com" --staging`)
+
+	h := &Node{SSHConfig: SSHConfig{User: "root"}}
+	compose, err := renderComposeFile("templates/nginx.docker-compose.yml", "Setup TLS Proxy", dockerComposeInputs{HomeDir: h.ExpandHome("")})
+	require.NoError(err)
+	require.Contains(string(compose), "container_name: nginx")
+	require.Contains(string(compose), "- /root/.avalanche-cli/services/nginx/certs:/etc/nginx/certs:ro")
+
+	script, err = renderScript("Setup Docker Service", "shell/setupDockerService.sh", ScriptInputs{User: "root", HomeDir: h.ExpandHome("")})
+	require.NoError(err)
+	require.Contains(script, "User=root\n")
+	require.Contains(script, "docker compose -f /root/.avalanche-cli/services/docker-compose.yml up")
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	require := require.New(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	notAfter := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second).UTC()
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rpc.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     notAfter,
+	}, &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "rpc.example.com"}}, &key.PublicKey, key)
+	require.NoError(err)
+	expiry, err := certificateExpiry(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	require.NoError(err)
+	require.Equal(notAfter, expiry)
+	_, err = certificateExpiry([]byte("garbage"))
+	require.Error(err)
+}
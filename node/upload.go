@@ -0,0 +1,261 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/pkg/sftp"
+)
+
+const (
+	defaultUploadChunkSize = 4 * 1024 * 1024
+	defaultUploadRetries   = 5
+	uploadRetryDelay       = 2 * time.Second
+)
+
+// UploadOptions configures UploadResumable
+type UploadOptions struct {
+	// Size of the chunks the file is uploaded in. Defaults to 4MiB
+	ChunkSize int64
+
+	// Max upload rate in bytes per second. 0 means no limit
+	BytesPerSecond int64
+
+	// Number of times a failing chunk is retried, reconnecting to the node
+	// each time. Defaults to 5
+	Retries int
+
+	// Progress is called after each uploaded chunk
+	Progress func(uploaded int64, total int64)
+
+	// VerifyChecksum compares the sha256 of the local and remote files after
+	// the upload is done
+	VerifyChecksum bool
+}
+
+// UploadResumable uploads a local file to a remote file on the node in chunks,
+// reconnecting and resuming from the last uploaded chunk on failure.
+// If [remoteFile] already exists, is smaller than [localFile], and matches its start, the
+// upload is resumed from the remote file size, so a failed call can also be retried.
+// Otherwise [remoteFile] is overwritten
+func (h *Node) UploadResumable(localFile string, remoteFile string, opts UploadOptions) error {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultUploadChunkSize
+	}
+	if opts.Retries <= 0 {
+		opts.Retries = defaultUploadRetries
+	}
+	src, err := os.Open(localFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	total := srcInfo.Size()
+	limiter := newRateLimiter(opts.BytesPerSecond)
+	buf := make([]byte, opts.ChunkSize)
+	uploaded := int64(-1)
+	for retries := 0; ; retries++ {
+		uploaded, err = h.uploadChunks(src, remoteFile, total, uploaded, buf, func(uploaded int64, chunkSize int) {
+			limiter.wait(chunkSize)
+			if opts.Progress != nil {
+				opts.Progress(uploaded, total)
+			}
+		})
+		if err == nil {
+			break
+		}
+		if retries >= opts.Retries {
+			return fmt.Errorf("failure uploading %s to %s for node %s: %w", localFile, remoteFile, h.IP, err)
+		}
+		time.Sleep(uploadRetryDelay)
+		if err := h.reconnect(); err != nil {
+			return err
+		}
+	}
+	if opts.VerifyChecksum {
+		return h.verifyChecksum(localFile, remoteFile)
+	}
+	return nil
+}
+
+// uploadChunks writes [src] into [remoteFile] starting at [offset]. If [offset] is
+// negative, it is taken from the remote file size. Returns the offset reached
+func (h *Node) uploadChunks(
+	src *os.File,
+	remoteFile string,
+	total int64,
+	offset int64,
+	buf []byte,
+	onChunk func(uploaded int64, chunkSize int),
+) (int64, error) {
+	if !h.Connected() {
+		if err := h.Connect(0); err != nil {
+			return offset, err
+		}
+	}
+	client, err := h.connection.NewSftp()
+	if err != nil {
+		return offset, err
+	}
+	defer client.Close()
+	if offset < 0 {
+		offset = 0
+		if remoteInfo, err := client.Stat(remoteFile); err == nil {
+			offset = resumeOffset(src, remoteInfo.Size(), total, func() (string, error) {
+				return h.remoteSHA256(remoteFile)
+			})
+		}
+	}
+	dst, err := client.OpenFile(remoteFile, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return offset, err
+	}
+	defer dst.Close()
+	if offset == 0 {
+		if err := dst.Truncate(0); err != nil {
+			return offset, err
+		}
+	}
+	for offset < total {
+		n, err := src.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return offset, err
+		}
+		if _, err := writeAt(dst, buf[:n], offset); err != nil {
+			return offset, err
+		}
+		offset += int64(n)
+		onChunk(offset, n)
+	}
+	return offset, nil
+}
+
+// resumeOffset returns the offset to resume uploading [src] from, given the size of the
+// already uploaded remote file. The upload is only resumed if the remote file is smaller
+// than [total] and has the same content as the start of [src], as given by [remoteChecksum].
+// Otherwise it starts over
+func resumeOffset(
+	src io.ReaderAt,
+	remoteSize int64,
+	total int64,
+	remoteChecksum func() (string, error),
+) int64 {
+	if remoteSize <= 0 || remoteSize >= total {
+		return 0
+	}
+	localChecksum, err := readerSHA256(io.NewSectionReader(src, 0, remoteSize))
+	if err != nil {
+		return 0
+	}
+	checksum, err := remoteChecksum()
+	if err != nil || checksum != localChecksum {
+		return 0
+	}
+	return remoteSize
+}
+
+func writeAt(dst *sftp.File, data []byte, offset int64) (int, error) {
+	if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return dst.Write(data)
+}
+
+// rateLimiter delays the caller so as the average rate of the bytes informed
+// to wait is not greater than [bytesPerSecond]
+type rateLimiter struct {
+	bytesPerSecond int64
+	start          time.Time
+	sent           int64
+}
+
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	return &rateLimiter{
+		bytesPerSecond: bytesPerSecond,
+		start:          time.Now(),
+	}
+}
+
+func (r *rateLimiter) wait(n int) {
+	r.sent += int64(n)
+	if r.bytesPerSecond <= 0 {
+		return
+	}
+	if delay := r.delay(time.Since(r.start)); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// delay returns the time to wait, given [elapsed] since the start, to keep the rate
+func (r *rateLimiter) delay(elapsed time.Duration) time.Duration {
+	expected := time.Duration(float64(r.sent) / float64(r.bytesPerSecond) * float64(time.Second))
+	return expected - elapsed
+}
+
+// reconnect closes the current SSH connection to the node, if any, and opens a new one
+func (h *Node) reconnect() error {
+	if h.connection != nil {
+		_ = h.connection.Close()
+		h.connection = nil
+	}
+	return h.Connect(0)
+}
+
+func (h *Node) verifyChecksum(localFile string, remoteFile string) error {
+	localChecksum, err := fileSHA256(localFile)
+	if err != nil {
+		return err
+	}
+	checksum, err := h.remoteSHA256(remoteFile)
+	if err != nil {
+		return err
+	}
+	if checksum != localChecksum {
+		return fmt.Errorf("checksum mismatch for %s on node %s: expected %s, got %s", remoteFile, h.IP, localChecksum, checksum)
+	}
+	return nil
+}
+
+// remoteSHA256 returns the hex encoded sha256 of [remoteFile] on the node
+func (h *Node) remoteSHA256(remoteFile string) (string, error) {
+	output, err := h.Commandf(nil, constants.SSHScriptTimeout, "sha256sum %s", utils.ShellQuote(remoteFile))
+	if err != nil {
+		return "", fmt.Errorf("failure computing checksum of %s for node %s: %w", remoteFile, h.IP, err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected sha256sum output for %s on node %s: %q", remoteFile, h.IP, string(output))
+	}
+	return fields[0], nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return readerSHA256(f)
+}
+
+func readerSHA256(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterDelay(t *testing.T) {
+	limiter := newRateLimiter(1000)
+	limiter.sent = 2000
+	require.Equal(t, 2*time.Second, limiter.delay(0))
+	require.Equal(t, 500*time.Millisecond, limiter.delay(1500*time.Millisecond))
+	require.LessOrEqual(t, limiter.delay(3*time.Second), time.Duration(0))
+}
+
+func TestFileSHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+	checksum, err := fileSHA256(path)
+	require.NoError(t, err)
+	require.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", checksum)
+}
+
+func TestResumeOffset(t *testing.T) {
+	require := require.New(t)
+	src := strings.NewReader("hello world")
+	total := int64(src.Len())
+	prefixChecksum, err := readerSHA256(strings.NewReader("hello"))
+	require.NoError(err)
+	checksum := func(checksum string, err error) func() (string, error) {
+		return func() (string, error) {
+			return checksum, err
+		}
+	}
+
+	// resumes after a matching prefix
+	require.Equal(int64(5), resumeOffset(src, 5, total, checksum(prefixChecksum, nil)))
+	// starts over if the uploaded content differs
+	otherChecksum, err := readerSHA256(strings.NewReader("HELLO"))
+	require.NoError(err)
+	require.Zero(resumeOffset(src, 5, total, checksum(otherChecksum, nil)))
+	require.Zero(resumeOffset(src, 5, total, checksum("", errors.New("connection lost"))))
+	// a remote file of the same size or larger is not assumed to be uploaded
+	require.Zero(resumeOffset(src, total, total, checksum(prefixChecksum, nil)))
+	require.Zero(resumeOffset(src, total+1, total, checksum(prefixChecksum, nil)))
+	require.Zero(resumeOffset(src, 0, total, checksum(prefixChecksum, nil)))
+}
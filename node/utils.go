@@ -111,6 +111,18 @@ func getPrometheusTargets(nodes []Node) ([]string, []string, []string) {
 	return avalancheGoPorts, machinePorts, ltPorts
 }
 
+// getMetricsExporterTargets returns the business metrics targets of the [nodes] that run
+// the metrics exporter sidecar, as told by [withMetricsExporter]
+func getMetricsExporterTargets(nodes []Node, withMetricsExporter map[string]interface{}) []string {
+	ports := []string{}
+	for _, host := range nodes {
+		if enabled, ok := withMetricsExporter[host.NodeID].(bool); ok && enabled {
+			ports = append(ports, fmt.Sprintf("'%s:%s'", host.IP, strconv.Itoa(constants.MetricsExporterPort)))
+		}
+	}
+	return ports
+}
+
 func composeFileExists(node Node) bool {
 	composeFileExists, _ := node.FileExists(utils.GetRemoteComposeFile())
 	return composeFileExists
@@ -95,3 +95,13 @@ func TestGetPublicKeyFromSSHKey(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeExpandHome(t *testing.T) {
+	require := require.New(t)
+	h := Node{SSHConfig: SSHConfig{User: "ubuntu"}}
+	require.Equal("/home/ubuntu", h.ExpandHome(""))
+	require.Equal("/home/ubuntu/.avalanchego", h.ExpandHome("~/.avalanchego"))
+	require.Equal("/var/log", h.ExpandHome("/var/log"))
+	h.SSHConfig.User = "root"
+	require.Equal("/root/.avalanchego", h.ExpandHome("~/.avalanchego"))
+}
@@ -0,0 +1,313 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package pchain follows the P-Chain as it accepts blocks, without external indexers:
+// it tails the P-Chain block index of an API node, that must have it enabled
+// (--index-enabled)
+package pchain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	// pageSize is the number of blocks requested to the index per call
+	pageSize = 256
+)
+
+// Filter selects the txs a Watcher reports. Txs matching any of its fields are
+// reported. An empty filter matches all txs
+type Filter struct {
+	// SubnetIDs match the txs that create, modify, add a chain to, or add or remove
+	// a validator of, one of the subnets. Reward txs are not matched, as the staker
+	// they reward is not part of the tx
+	SubnetIDs []ids.ID
+	// Addresses match the txs with an output, stake output, exported output, or
+	// owner (subnet, rewards) that includes one of the addresses. The inputs are not
+	// resolved, so spends are matched by the change they return
+	Addresses []ids.ShortID
+}
+
+func (f Filter) empty() bool {
+	return len(f.SubnetIDs) == 0 && len(f.Addresses) == 0
+}
+
+// TxEvent is an accepted P-Chain tx reported by a Watcher
+type TxEvent struct {
+	TxID   ids.ID
+	TxType string
+	Tx     *txs.Tx
+	// BlockIndex is the position of the block in the P-Chain block index
+	BlockIndex  uint64
+	BlockID     ids.ID
+	BlockHeight uint64
+	Timestamp   time.Time
+	// SubnetIDs and Addresses are the ones of the filter the tx matched
+	SubnetIDs []ids.ID
+	Addresses []ids.ShortID
+}
+
+type blockIndexClient interface {
+	GetLastAccepted(ctx context.Context, options ...rpc.Option) (indexer.Container, uint64, error)
+	GetContainerRange(ctx context.Context, startIndex uint64, numToFetch int, options ...rpc.Option) ([]indexer.Container, error)
+}
+
+// Watcher tails the P-Chain blocks accepted by an API node, and reports the txs that
+// match its Filter, in acceptance order
+type Watcher struct {
+	Network avalanche.Network
+	Filter  Filter
+	// StartIndex is the index of the first block checked. If not set, the watcher
+	// starts after the last accepted block at its first poll
+	StartIndex *uint64
+	// PollInterval is the time between polls of the index. Defaults to 2 seconds
+	PollInterval time.Duration
+	// OnError is called when a poll fails, if set. The watcher keeps running on
+	// errors, retrying from the first block not checked
+	OnError func(error)
+
+	index blockIndexClient
+	// next is the index of the next block to check, once started
+	next    uint64
+	started bool
+}
+
+// NextIndex returns the index of the next block the watcher checks, eg. to persist
+// it and resume with StartIndex
+func (w *Watcher) NextIndex() uint64 {
+	return w.next
+}
+
+// Poll checks the blocks accepted since the previous poll, and returns the txs
+// matching the filter. On error, the txs of the blocks checked before the failure are
+// returned, and the next poll resumes from the failing block
+func (w *Watcher) Poll(ctx context.Context) ([]TxEvent, error) {
+	if w.index == nil {
+		w.index = indexer.NewClient(w.Network.Endpoint + "/ext/index/P/block")
+	}
+	lastIndex, err := w.lastAcceptedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !w.started {
+		w.started = true
+		w.next = lastIndex + 1
+		if w.StartIndex != nil {
+			w.next = *w.StartIndex
+		}
+	}
+	events := []TxEvent{}
+	for w.next <= lastIndex {
+		containers, err := w.containers(ctx, w.next, int(min(pageSize, lastIndex-w.next+1)))
+		if err != nil {
+			return events, err
+		}
+		if len(containers) == 0 {
+			break
+		}
+		for _, container := range containers {
+			blockEvents, err := w.blockEvents(container, w.next)
+			if err != nil {
+				return events, err
+			}
+			events = append(events, blockEvents...)
+			w.next++
+		}
+	}
+	return events, nil
+}
+
+func (w *Watcher) lastAcceptedIndex(ctx context.Context) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.APIRequestTimeout)
+	defer cancel()
+	_, lastIndex, err := w.index.GetLastAccepted(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failure getting last accepted block from the P-Chain index: %w", err)
+	}
+	return lastIndex, nil
+}
+
+func (w *Watcher) containers(ctx context.Context, start uint64, count int) ([]indexer.Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.APIRequestLargeTimeout)
+	defer cancel()
+	containers, err := w.index.GetContainerRange(ctx, start, count)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting blocks from %d from the P-Chain index: %w", start, err)
+	}
+	return containers, nil
+}
+
+func (w *Watcher) blockEvents(container indexer.Container, blockIndex uint64) ([]TxEvent, error) {
+	blk, err := block.Parse(block.Codec, container.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failure parsing P-Chain block %s: %w", container.ID, err)
+	}
+	events := []TxEvent{}
+	for _, tx := range blk.Txs() {
+		subnetIDs, addresses, ok := w.Filter.match(tx)
+		if !ok {
+			continue
+		}
+		events = append(events, TxEvent{
+			TxID:        tx.ID(),
+			TxType:      strings.TrimPrefix(fmt.Sprintf("%T", tx.Unsigned), "*txs."),
+			Tx:          tx,
+			BlockIndex:  blockIndex,
+			BlockID:     blk.ID(),
+			BlockHeight: blk.Height(),
+			Timestamp:   time.Unix(0, container.Timestamp),
+			SubnetIDs:   subnetIDs,
+			Addresses:   addresses,
+		})
+	}
+	return events, nil
+}
+
+// Run polls the index every PollInterval until [ctx] is done, sending the matching
+// txs to [events]
+func (w *Watcher) Run(ctx context.Context, events chan<- TxEvent) error {
+	interval := w.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		polled, err := w.Poll(ctx)
+		if err != nil && w.OnError != nil && ctx.Err() == nil {
+			w.OnError(err)
+		}
+		for _, event := range polled {
+			select {
+			case <-ctx.Done():
+				return nil
+			case events <- event:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Subscribe runs the watcher on the background until [ctx] is done, and returns the
+// channel the matching txs are sent to. The channel is closed when the watcher stops
+func (w *Watcher) Subscribe(ctx context.Context) <-chan TxEvent {
+	events := make(chan TxEvent)
+	go func() {
+		defer close(events)
+		_ = w.Run(ctx, events)
+	}()
+	return events
+}
+
+// match tells if [tx] matches the filter, returning the subnets and addresses matched
+func (f Filter) match(tx *txs.Tx) ([]ids.ID, []ids.ShortID, bool) {
+	if f.empty() {
+		return nil, nil, true
+	}
+	var subnetIDs []ids.ID
+	for _, subnetID := range TxSubnetIDs(tx) {
+		if utils.Belongs(f.SubnetIDs, subnetID) {
+			subnetIDs = append(subnetIDs, subnetID)
+		}
+	}
+	var addresses []ids.ShortID
+	if len(f.Addresses) > 0 {
+		txAddresses := TxAddresses(tx)
+		for _, addr := range f.Addresses {
+			if txAddresses.Contains(addr) {
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	return subnetIDs, addresses, len(subnetIDs) > 0 || len(addresses) > 0
+}
+
+// TxSubnetIDs returns the subnets [tx] refers to: the one it creates, or the one it
+// modifies, adds a chain to, or adds or removes a validator of. Primary network
+// staker txs return the primary network ID
+func TxSubnetIDs(tx *txs.Tx) []ids.ID {
+	switch utx := tx.Unsigned.(type) {
+	case *txs.CreateSubnetTx:
+		return []ids.ID{tx.ID()}
+	case *txs.CreateChainTx:
+		return []ids.ID{utx.SubnetID}
+	case *txs.RemoveSubnetValidatorTx:
+		return []ids.ID{utx.Subnet}
+	case *txs.TransformSubnetTx:
+		return []ids.ID{utx.Subnet}
+	case *txs.TransferSubnetOwnershipTx:
+		return []ids.ID{utx.Subnet}
+	case interface{ SubnetID() ids.ID }:
+		return []ids.ID{utx.SubnetID()}
+	}
+	return nil
+}
+
+// TxAddresses returns the addresses owning the outputs of [tx], including staked and
+// exported outputs, and the addresses of the owners it sets
+func TxAddresses(tx *txs.Tx) set.Set[ids.ShortID] {
+	addresses := set.Set[ids.ShortID]{}
+	outs := tx.Unsigned.Outputs()
+	if exportTx, ok := tx.Unsigned.(*txs.ExportTx); ok {
+		outs = append(append([]*avax.TransferableOutput{}, outs...), exportTx.ExportedOutputs...)
+	}
+	if stakerTx, ok := tx.Unsigned.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		outs = append(append([]*avax.TransferableOutput{}, outs...), stakerTx.Stake()...)
+	}
+	for _, out := range outs {
+		addOwners(addresses, out.Out)
+	}
+	owners := []fx.Owner{}
+	switch utx := tx.Unsigned.(type) {
+	case *txs.CreateSubnetTx:
+		owners = append(owners, utx.Owner)
+	case *txs.TransferSubnetOwnershipTx:
+		owners = append(owners, utx.Owner)
+	case txs.ValidatorTx:
+		owners = append(owners, utx.ValidationRewardsOwner(), utx.DelegationRewardsOwner())
+	case txs.DelegatorTx:
+		owners = append(owners, utx.RewardsOwner())
+	}
+	for _, owner := range owners {
+		addOwners(addresses, owner)
+	}
+	return addresses
+}
+
+// addOwners adds to [addresses] the addresses of [owner], if it is a secp256k1fx
+// output or owner
+func addOwners(addresses set.Set[ids.ShortID], owner interface{}) {
+	if lockOut, ok := owner.(*stakeable.LockOut); ok {
+		owner = lockOut.TransferableOut
+	}
+	switch owner := owner.(type) {
+	case *secp256k1fx.TransferOutput:
+		addresses.Add(owner.Addrs...)
+	case *secp256k1fx.OutputOwners:
+		addresses.Add(owner.Addrs...)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pchain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBlockIndex struct {
+	containers []indexer.Container
+}
+
+func (i *fakeBlockIndex) GetLastAccepted(context.Context, ...rpc.Option) (indexer.Container, uint64, error) {
+	return i.containers[len(i.containers)-1], uint64(len(i.containers) - 1), nil
+}
+
+func (i *fakeBlockIndex) GetContainerRange(_ context.Context, startIndex uint64, numToFetch int, _ ...rpc.Option) ([]indexer.Container, error) {
+	end := min(int(startIndex)+numToFetch, len(i.containers))
+	return i.containers[startIndex:end], nil
+}
+
+func (i *fakeBlockIndex) accept(t *testing.T, blockTxs ...*txs.Tx) {
+	blk, err := block.NewBanffStandardBlock(time.Unix(1_700_000_000, 0), ids.GenerateTestID(), uint64(len(i.containers)+1), blockTxs)
+	require.NoError(t, err)
+	i.containers = append(i.containers, indexer.Container{
+		ID:        blk.ID(),
+		Bytes:     blk.Bytes(),
+		Timestamp: blk.Timestamp().UnixNano(),
+	})
+}
+
+func owners(addr ids.ShortID) *secp256k1fx.OutputOwners {
+	return &secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{addr}}
+}
+
+func baseTx(outs ...*avax.TransferableOutput) txs.BaseTx {
+	return txs.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    constants.FujiID,
+		BlockchainID: constants.PlatformChainID,
+		Outs:         outs,
+	}}
+}
+
+func initializedTx(t *testing.T, unsignedTx txs.UnsignedTx) *txs.Tx {
+	tx := &txs.Tx{Unsigned: unsignedTx}
+	require.NoError(t, tx.Initialize(txs.Codec))
+	return tx
+}
+
+func TestWatcherPoll(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	addr := ids.GenerateTestShortID()
+	subnetOwner := ids.GenerateTestShortID()
+
+	index := &fakeBlockIndex{}
+	index.accept(t, initializedTx(t, &txs.AdvanceTimeTx{Time: 1}))
+	w := &Watcher{index: index}
+	// starts after the last accepted block
+	events, err := w.Poll(ctx)
+	require.NoError(err)
+	require.Empty(events)
+	require.Equal(uint64(1), w.NextIndex())
+
+	createSubnetTx := initializedTx(t, &txs.CreateSubnetTx{BaseTx: baseTx(), Owner: owners(subnetOwner)})
+	subnetID := createSubnetTx.ID()
+	createChainTx := initializedTx(t, &txs.CreateChainTx{BaseTx: baseTx(), SubnetID: subnetID, ChainName: "l1", SubnetAuth: &secp256k1fx.Input{}})
+	transferTx := initializedTx(t, &txs.BaseTx{BaseTx: baseTx(&avax.TransferableOutput{
+		Asset: avax.Asset{ID: ids.GenerateTestID()},
+		Out:   &secp256k1fx.TransferOutput{Amt: 1, OutputOwners: *owners(addr)},
+	}).BaseTx})
+	otherChainTx := initializedTx(t, &txs.CreateChainTx{BaseTx: baseTx(), SubnetID: ids.GenerateTestID(), ChainName: "other", SubnetAuth: &secp256k1fx.Input{}})
+	index.accept(t, createSubnetTx)
+	index.accept(t, createChainTx, otherChainTx)
+	index.accept(t, transferTx)
+
+	require.Equal([]ids.ID{subnetID}, TxSubnetIDs(createSubnetTx))
+	subnetAddresses := TxAddresses(createSubnetTx)
+	require.True(subnetAddresses.Contains(subnetOwner))
+
+	w.Filter = Filter{SubnetIDs: []ids.ID{subnetID}, Addresses: []ids.ShortID{addr}}
+	events, err = w.Poll(ctx)
+	require.NoError(err)
+	require.Len(events, 3)
+	require.Equal(createSubnetTx.ID(), events[0].TxID)
+	require.Equal("CreateSubnetTx", events[0].TxType)
+	require.Equal(uint64(1), events[0].BlockIndex)
+	require.Equal([]ids.ID{subnetID}, events[0].SubnetIDs)
+	require.Equal(createChainTx.ID(), events[1].TxID)
+	require.Equal(uint64(2), events[1].BlockIndex)
+	require.Equal(uint64(3), events[1].BlockHeight)
+	require.Equal(transferTx.ID(), events[2].TxID)
+	require.Equal([]ids.ShortID{addr}, events[2].Addresses)
+	require.Empty(events[2].SubnetIDs)
+	require.Equal(uint64(4), w.NextIndex())
+
+	// nothing new
+	events, err = w.Poll(ctx)
+	require.NoError(err)
+	require.Empty(events)
+
+	// an empty filter matches all txs, and StartIndex replays from a given block
+	start := uint64(2)
+	replay := &Watcher{index: index, StartIndex: &start}
+	events, err = replay.Poll(ctx)
+	require.NoError(err)
+	require.Len(events, 3)
+	require.Equal(otherChainTx.ID(), events[1].TxID)
+}
+
+func TestWatcherSubscribe(t *testing.T) {
+	require := require.New(t)
+	index := &fakeBlockIndex{}
+	index.accept(t, initializedTx(t, &txs.AdvanceTimeTx{Time: 1}))
+	createSubnetTx := initializedTx(t, &txs.CreateSubnetTx{BaseTx: baseTx(), Owner: owners(ids.GenerateTestShortID())})
+	index.accept(t, createSubnetTx)
+
+	start := uint64(1)
+	w := &Watcher{index: index, StartIndex: &start, PollInterval: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	events := w.Subscribe(ctx)
+	event := <-events
+	require.Equal(createSubnetTx.ID(), event.TxID)
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package proxy configures the egress proxy used by the SDK outbound connections:
+// EVM and Avalanche API clients, SSH connections to nodes, and cloud SDKs
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	xproxy "golang.org/x/net/proxy"
+)
+
+var ErrUnsupportedScheme = errors.New("unsupported proxy scheme")
+
+// Config describes a proxy
+type Config struct {
+	// URL of the proxy, with scheme http, https or socks5, and optional
+	// user:password credentials. An empty URL means no proxy
+	URL string
+
+	// NoProxy is a comma separated list of hosts, domains (.example.com),
+	// IPs or CIDRs that are reached directly, as in the NO_PROXY env var
+	NoProxy string
+}
+
+var (
+	defaultLock   sync.RWMutex
+	defaultConfig Config
+)
+
+// FromEnvironment returns the proxy configured by the ALL_PROXY, HTTPS_PROXY,
+// HTTP_PROXY and NO_PROXY env vars (or their lowercase versions), in that
+// order of precedence
+func FromEnvironment() Config {
+	getenv := func(names ...string) string {
+		for _, name := range names {
+			if value := os.Getenv(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	return Config{
+		URL:     getenv("ALL_PROXY", "all_proxy", "HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"),
+		NoProxy: getenv("NO_PROXY", "no_proxy"),
+	}
+}
+
+// SetDefault sets the proxy used by the SDK when none is given explicitly.
+// It also sets the transport of http.DefaultClient, used by the Avalanche
+// API clients (platformvm, info, etc)
+func SetDefault(config Config) error {
+	transport, err := config.Transport()
+	if err != nil {
+		return err
+	}
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultConfig = config
+	if config.Enabled() {
+		http.DefaultClient.Transport = transport
+	} else {
+		http.DefaultClient.Transport = nil
+	}
+	return nil
+}
+
+// Default returns the proxy set with SetDefault
+func Default() Config {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	return defaultConfig
+}
+
+// Enabled returns true if a proxy is configured
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
+
+func (c Config) parseURL() (*url.URL, error) {
+	proxyURL, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, proxyURL.Scheme)
+	}
+	return proxyURL, nil
+}
+
+// ProxyFunc returns a function that selects the proxy for an http request,
+// suitable for http.Transport.Proxy. Requests to NoProxy hosts go direct
+func (c Config) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  c.URL,
+		HTTPSProxy: c.URL,
+		NoProxy:    c.NoProxy,
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// Transport returns a clone of http.DefaultTransport that uses the proxy
+func (c Config) Transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !c.Enabled() {
+		return transport, nil
+	}
+	if _, err := c.parseURL(); err != nil {
+		return nil, err
+	}
+	transport.Proxy = c.ProxyFunc()
+	return transport, nil
+}
+
+// HTTPClient returns an http client that uses the proxy
+func (c Config) HTTPClient() (*http.Client, error) {
+	transport, err := c.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// bypass returns true if [addr] must be reached directly
+func (c Config) bypass(addr string) bool {
+	proxyURL, err := (&httpproxy.Config{
+		HTTPSProxy: c.URL,
+		NoProxy:    c.NoProxy,
+	}).ProxyFunc()(&url.URL{Scheme: "https", Host: addr})
+	return err == nil && proxyURL == nil
+}
+
+// DialContext opens a TCP connection to [addr] through the proxy, using CONNECT
+// for http(s) proxies. It is used for non http protocols, like SSH. NoProxy and
+// loopback addresses are dialed directly
+func (c Config) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	direct := &net.Dialer{}
+	if !c.Enabled() || c.bypass(addr) {
+		return direct.DialContext(ctx, network, addr)
+	}
+	proxyURL, err := c.parseURL()
+	if err != nil {
+		return nil, err
+	}
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		var auth *xproxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &xproxy.Auth{User: proxyURL.User.Username(), Password: password}
+		}
+		dialer, err := xproxy.SOCKS5("tcp", proxyAddress(proxyURL), auth, direct)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(xproxy.ContextDialer).DialContext(ctx, network, addr)
+	default:
+		var tlsConfig *tls.Config
+		if proxyURL.Scheme == "https" {
+			tlsConfig = &tls.Config{ServerName: proxyURL.Hostname()}
+		}
+		return dialConnect(ctx, direct, proxyURL, addr, tlsConfig)
+	}
+}
+
+// proxyAddress returns the host:port of [proxyURL], with the default port of its
+// scheme if it has none
+func proxyAddress(proxyURL *url.URL) string {
+	if proxyURL.Port() != "" {
+		return proxyURL.Host
+	}
+	port := "80"
+	switch proxyURL.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// dialConnect opens a tunnel to [addr] with an http CONNECT request to [proxyURL],
+// over TLS if [tlsConfig] is not nil
+func dialConnect(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	proxyAddr := proxyAddress(proxyURL)
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failure connecting to proxy %s: %w", proxyAddr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failure establishing TLS with proxy %s: %w", proxyAddr, err)
+		}
+		conn = tlsConn
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyAddr, addr, resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	if reader.Buffered() > 0 {
+		// servers that talk first, like SSH, may have sent data along with the proxy response
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads first the data already buffered from the connection
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("ALL_PROXY", "")
+	t.Setenv("all_proxy", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "http://https-proxy:3128")
+	t.Setenv("HTTP_PROXY", "http://http-proxy:3128")
+	t.Setenv("NO_PROXY", "localhost,.internal")
+	config := FromEnvironment()
+	require.Equal(t, "http://https-proxy:3128", config.URL)
+	require.Equal(t, "localhost,.internal", config.NoProxy)
+	require.True(t, config.Enabled())
+
+	t.Setenv("ALL_PROXY", "socks5://socks:1080")
+	require.Equal(t, "socks5://socks:1080", FromEnvironment().URL)
+}
+
+func TestProxyFunc(t *testing.T) {
+	require := require.New(t)
+	config := Config{URL: "http://proxy:3128", NoProxy: ".internal,10.0.0.0/8"}
+	_, err := config.Transport()
+	require.NoError(err)
+	proxyFunc := config.ProxyFunc()
+	for target, expected := range map[string]string{
+		"https://api.avax.network/ext/bc/C/rpc": "http://proxy:3128",
+		"http://node.internal:9650/ext/info":    "",
+		"http://10.1.2.3:9650/ext/info":         "",
+	} {
+		targetURL, err := url.Parse(target)
+		require.NoError(err)
+		proxyURL, err := proxyFunc(&http.Request{URL: targetURL})
+		require.NoError(err)
+		if expected == "" {
+			require.Nil(proxyURL, target)
+		} else {
+			require.Equal(expected, proxyURL.String(), target)
+		}
+	}
+	_, err = Config{URL: "ftp://proxy:21"}.Transport()
+	require.ErrorIs(err, ErrUnsupportedScheme)
+}
+
+func TestDialContextConnect(t *testing.T) {
+	require := require.New(t)
+	// target echoes back what it receives
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer target.Close()
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+	// proxy accepts a single CONNECT
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer proxyListener.Close()
+	requests := make(chan *http.Request, 1)
+	go func() {
+		conn, err := proxyListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		requests <- req
+		// loopback addresses always bypass the proxy, so the test
+		// dials a made up host that the proxy resolves to the target
+		upstream, err := net.Dial("tcp", target.Addr().String())
+		if err != nil {
+			_, _ = conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+			return
+		}
+		defer upstream.Close()
+		// the target banner is sent along with the response, as SSH servers talk first
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\nbanner"))
+		go func() { _, _ = io.Copy(upstream, conn) }()
+		_, _ = io.Copy(conn, upstream)
+	}()
+
+	config := Config{URL: "http://user:pass@" + proxyListener.Addr().String()}
+	conn, err := config.DialContext(context.Background(), "tcp", "node.example.com:22")
+	require.NoError(err)
+	defer conn.Close()
+	req := <-requests
+	require.Equal(http.MethodConnect, req.Method)
+	require.Equal("node.example.com:22", req.Host)
+	require.Equal("Basic dXNlcjpwYXNz", req.Header.Get("Proxy-Authorization"))
+	buf := make([]byte, 6)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(err)
+	require.Equal("banner", string(buf))
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(err)
+	buf = make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(err)
+	require.Equal("ping", string(buf))
+}
+
+func TestDialContextNoProxy(t *testing.T) {
+	require := require.New(t)
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer target.Close()
+	// the proxy is unreachable, so the dial only succeeds if it goes direct,
+	// as loopback addresses always do
+	config := Config{URL: "http://127.0.0.1:1"}
+	conn, err := config.DialContext(context.Background(), "tcp", "localhost:"+strconv.Itoa(target.Addr().(*net.TCPAddr).Port))
+	require.NoError(err)
+	require.NoError(conn.Close())
+}
+
+func TestProxyAddress(t *testing.T) {
+	require := require.New(t)
+	for proxy, expected := range map[string]string{
+		"http://proxy":             "proxy:80",
+		"https://proxy":            "proxy:443",
+		"socks5://proxy":           "proxy:1080",
+		"socks5h://user:pw@proxy":  "proxy:1080",
+		"https://proxy:8443":       "proxy:8443",
+		"http://[2001:db8::1]":     "[2001:db8::1]:80",
+		"socks5://[2001:db8::1]:9": "[2001:db8::1]:9",
+	} {
+		proxyURL, err := url.Parse(proxy)
+		require.NoError(err)
+		require.Equal(expected, proxyAddress(proxyURL), proxy)
+	}
+}
+
+func TestDialContextConnectTLS(t *testing.T) {
+	require := require.New(t)
+	// proxy accepts CONNECT over TLS, and echoes back what it receives through the tunnel
+	hosts := make(chan string, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		hosts <- r.Host
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		_, _ = io.Copy(conn, buf)
+	}))
+	defer server.Close()
+	proxyURL, err := url.Parse(server.URL)
+	require.NoError(err)
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.ServerName = proxyURL.Hostname()
+
+	conn, err := dialConnect(context.Background(), &net.Dialer{}, proxyURL, "node.example.com:22", tlsConfig)
+	require.NoError(err)
+	defer conn.Close()
+	require.Equal("node.example.com:22", <-hosts)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(err)
+	require.Equal("ping", string(buf))
+
+	// the proxy certificate is verified
+	_, err = dialConnect(context.Background(), &net.Dialer{}, proxyURL, "node.example.com:22", &tls.Config{ServerName: proxyURL.Hostname()})
+	require.ErrorContains(err, "failure establishing TLS with proxy")
+}
@@ -0,0 +1,301 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package sdk provides single call facades over the lower level SDK packages,
+// for platforms that want to deploy a blockchain without orchestrating each of
+// the required steps themselves
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/subnet"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/validator"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/wallet"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// ValidatorManagement is the mechanism used to decide the validator set of the L1.
+//
+// Proof of stake is out of the scope of CreateL1: it needs the subnet to be
+// converted to an L1 managed by a validator manager contract, which the
+// network versions supported by this SDK can't do. The validator manager
+// contracts of already converted L1s are operated with package validatormanager
+type ValidatorManagement string
+
+// ProofOfAuthority lets the L1 control keys add and remove validators
+const ProofOfAuthority ValidatorManagement = "PoA"
+
+// Stage identifies a step of CreateL1
+type Stage string
+
+const (
+	StageCreateWallet     Stage = "create-wallet"
+	StageCreateSubnet     Stage = "create-subnet"
+	StageCreateBlockchain Stage = "create-blockchain"
+	StageAddValidator     Stage = "add-validator"
+	StageDone             Stage = "done"
+)
+
+var (
+	ErrNoL1Params              = errors.New("no subnet params provided")
+	ErrNoL1Validators          = errors.New("at least one validator is needed")
+	ErrUnsupportedManagement   = errors.New("unsupported validator management")
+	ErrInvalidL1Threshold      = errors.New("threshold must be between 1 and the number of control keys")
+	ErrNotEnoughKeychainSigner = errors.New("keychain does not hold enough control keys to reach the threshold")
+	ErrDuplicatedL1Validator   = errors.New("validator is listed more than once")
+	ErrStageNotApproved        = errors.New("stage was not approved")
+)
+
+// ApprovalFunc is called before a CreateL1 stage issues its tx, with a human
+// readable [summary] of the tx. Returning false aborts CreateL1 with
+// ErrStageNotApproved, so it can gate the stage on interactive confirmation or
+// on a spend policy
+type ApprovalFunc func(stage Stage, summary string) (bool, error)
+
+// AutoApprove is the default ApprovalFunc, that approves every stage
+func AutoApprove(Stage, string) (bool, error) {
+	return true, nil
+}
+
+// Progress is reported to L1Config.OnProgress when a CreateL1 step starts and
+// when it finishes
+type Progress struct {
+	// Stage being executed
+	Stage Stage
+	// NodeID is set for StageAddValidator
+	NodeID ids.NodeID
+	// TxID is set once the tx of the stage has been accepted
+	TxID ids.ID
+	// Done is true when the stage finished
+	Done bool
+}
+
+// L1Config is the declarative description of the L1 to be created
+type L1Config struct {
+	// Network to create the L1 on
+	Network avalanche.Network
+
+	// Keychain pays for all txs, and signs as control key of the L1
+	Keychain keychain.Keychain
+
+	// Subnet holds the VM and genesis definition of the L1 blockchain
+	Subnet subnet.SubnetParams
+
+	// ControlKeys are the addresses allowed to modify the L1. Defaults to
+	// the keychain addresses
+	ControlKeys []ids.ShortID
+
+	// Threshold is the number of control keys needed to sign a change to the
+	// L1. Defaults to 1
+	Threshold uint32
+
+	// Validators are the nodes to be added as initial L1 validators. They must
+	// already be validating the Primary Network and tracking the L1
+	// (see node.SyncSubnets)
+	Validators []validator.SubnetValidatorParams
+
+	// Management defaults to ProofOfAuthority, the only one supported. See
+	// ValidatorManagement
+	Management ValidatorManagement
+
+	// OnProgress, if set, is called when each stage starts and finishes
+	OnProgress func(Progress)
+
+	// Approve is called before each stage issues its tx. Defaults to AutoApprove
+	Approve ApprovalFunc
+}
+
+// L1 is the result of CreateL1
+type L1 struct {
+	// Subnet contains the L1 SubnetID, VMID, genesis and auth keys
+	Subnet *subnet.Subnet
+	// BlockchainID of the L1 blockchain
+	BlockchainID ids.ID
+	// RPCEndpoint and WSEndpoint are the L1 blockchain endpoints on the
+	// network API
+	RPCEndpoint string
+	WSEndpoint  string
+	// ValidatorTxIDs maps each validator to the tx that added it
+	ValidatorTxIDs map[ids.NodeID]ids.ID
+}
+
+func (cfg *L1Config) setDefaults() {
+	if cfg.Management == "" {
+		cfg.Management = ProofOfAuthority
+	}
+	if len(cfg.ControlKeys) == 0 && cfg.Keychain.Keychain != nil {
+		cfg.ControlKeys = cfg.Keychain.Addresses().List()
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = 1
+	}
+	if cfg.Approve == nil {
+		cfg.Approve = AutoApprove
+	}
+}
+
+// validate checks the config and returns the control keys held by the
+// keychain that are going to sign the L1 changing txs
+func (cfg *L1Config) validate() ([]ids.ShortID, error) {
+	if cfg.Management != ProofOfAuthority {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedManagement, cfg.Management)
+	}
+	if cfg.Subnet.SubnetEVM == nil && cfg.Subnet.GenesisFilePath == "" {
+		return nil, ErrNoL1Params
+	}
+	if len(cfg.Validators) == 0 {
+		return nil, ErrNoL1Validators
+	}
+	nodeIDs := set.Set[ids.NodeID]{}
+	for _, v := range cfg.Validators {
+		if nodeIDs.Contains(v.NodeID) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatedL1Validator, v.NodeID)
+		}
+		nodeIDs.Add(v.NodeID)
+	}
+	if cfg.Threshold > uint32(len(cfg.ControlKeys)) {
+		return nil, ErrInvalidL1Threshold
+	}
+	owned := set.Set[ids.ShortID]{}
+	if cfg.Keychain.Keychain != nil {
+		owned = cfg.Keychain.Addresses()
+	}
+	authKeys := []ids.ShortID{}
+	for _, addr := range cfg.ControlKeys {
+		if uint32(len(authKeys)) == cfg.Threshold {
+			break
+		}
+		if owned.Contains(addr) {
+			authKeys = append(authKeys, addr)
+		}
+	}
+	if uint32(len(authKeys)) < cfg.Threshold {
+		return nil, ErrNotEnoughKeychainSigner
+	}
+	return authKeys, nil
+}
+
+func (cfg *L1Config) report(p Progress) {
+	if cfg.OnProgress != nil {
+		cfg.OnProgress(p)
+	}
+}
+
+// approve asks cfg.Approve whether [tx], built by [stage], can be issued
+func (cfg *L1Config) approve(stage Stage, w wallet.Wallet, tx multisig.Multisig, description string) error {
+	kind, err := tx.GetTxKind()
+	if err != nil {
+		return err
+	}
+	fee, err := w.MultisigTxFee(tx)
+	if err != nil {
+		return err
+	}
+	summary := fmt.Sprintf("%s: issue %s %s paying a fee of %d nAVAX", description, kind, tx.String(), fee)
+	approved, err := cfg.Approve(stage, summary)
+	if err != nil {
+		return fmt.Errorf("failure approving %s: %w", stage, err)
+	}
+	if !approved {
+		return fmt.Errorf("%w: %s", ErrStageNotApproved, stage)
+	}
+	return nil
+}
+
+// CreateL1 creates the subnet, the blockchain and the initial validator set
+// described by [cfg], waiting for each tx to be accepted before issuing the
+// next one.
+//
+// All txs are paid and signed by cfg.Keychain, so it must hold at least
+// cfg.Threshold of the control keys. Each tx is issued only after cfg.Approve
+// approves it
+func CreateL1(ctx context.Context, cfg L1Config) (*L1, error) {
+	cfg.setDefaults()
+	authKeys, err := cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+	newSubnet, err := subnet.New(&cfg.Subnet)
+	if err != nil {
+		return nil, err
+	}
+	newSubnet.SetSubnetControlParams(cfg.ControlKeys, cfg.Threshold)
+	newSubnet.SetSubnetAuthKeys(authKeys)
+
+	cfg.report(Progress{Stage: StageCreateWallet})
+	w, err := wallet.NewWithOptions(ctx, cfg.Network, cfg.Keychain)
+	if err != nil {
+		return nil, err
+	}
+	cfg.report(Progress{Stage: StageCreateWallet, Done: true})
+
+	cfg.report(Progress{Stage: StageCreateSubnet})
+	createSubnetTx, err := newSubnet.CreateSubnetTx(w)
+	if err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("create subnet with %d control keys and threshold %d", len(cfg.ControlKeys), cfg.Threshold)
+	if err := cfg.approve(StageCreateSubnet, w, *createSubnetTx, description); err != nil {
+		return nil, err
+	}
+	subnetID, err := newSubnet.Commit(*createSubnetTx, w, true)
+	if err != nil {
+		return nil, fmt.Errorf("failure creating subnet: %w", err)
+	}
+	cfg.report(Progress{Stage: StageCreateSubnet, TxID: subnetID, Done: true})
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cfg.report(Progress{Stage: StageCreateBlockchain})
+	createChainTx, err := newSubnet.CreateBlockchainTx(w)
+	if err != nil {
+		return nil, err
+	}
+	description = fmt.Sprintf("create blockchain %s on subnet %s", cfg.Subnet.Name, subnetID)
+	if err := cfg.approve(StageCreateBlockchain, w, *createChainTx, description); err != nil {
+		return nil, err
+	}
+	blockchainID, err := newSubnet.Commit(*createChainTx, w, true)
+	if err != nil {
+		return nil, fmt.Errorf("failure creating blockchain on subnet %s: %w", subnetID, err)
+	}
+	cfg.report(Progress{Stage: StageCreateBlockchain, TxID: blockchainID, Done: true})
+
+	l1 := &L1{
+		Subnet:         newSubnet,
+		BlockchainID:   blockchainID,
+		RPCEndpoint:    cfg.Network.BlockchainEndpoint(blockchainID.String()),
+		WSEndpoint:     cfg.Network.BlockchainWSEndpoint(blockchainID.String()),
+		ValidatorTxIDs: map[ids.NodeID]ids.ID{},
+	}
+	for _, v := range cfg.Validators {
+		if err := ctx.Err(); err != nil {
+			return l1, err
+		}
+		cfg.report(Progress{Stage: StageAddValidator, NodeID: v.NodeID})
+		addValidatorTx, err := newSubnet.AddValidator(w, v)
+		if err != nil {
+			return l1, err
+		}
+		description := fmt.Sprintf("add validator %s", v.NodeID)
+		if err := cfg.approve(StageAddValidator, w, *addValidatorTx, description); err != nil {
+			return l1, err
+		}
+		txID, err := newSubnet.Commit(*addValidatorTx, w, true)
+		if err != nil {
+			return l1, fmt.Errorf("failure adding validator %s: %w", v.NodeID, err)
+		}
+		l1.ValidatorTxIDs[v.NodeID] = txID
+		cfg.report(Progress{Stage: StageAddValidator, NodeID: v.NodeID, TxID: txID, Done: true})
+	}
+	cfg.report(Progress{Stage: StageDone, Done: true})
+	return l1, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sdk
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/subnet"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/validator"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestL1ConfigValidate(t *testing.T) {
+	require := require.New(t)
+	privKey, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	owned := privKey.Address()
+	foreign := ids.GenerateTestShortID()
+	nodeID := ids.GenerateTestNodeID()
+	newConfig := func() L1Config {
+		return L1Config{
+			Keychain:   keychain.Keychain{Keychain: secp256k1fx.NewKeychain(privKey)},
+			Subnet:     subnet.SubnetParams{GenesisFilePath: "genesis.json", Name: "l1"},
+			Validators: []validator.SubnetValidatorParams{{NodeID: nodeID}},
+		}
+	}
+
+	cfg := newConfig()
+	cfg.setDefaults()
+	authKeys, err := cfg.validate()
+	require.NoError(err)
+	require.Equal(ProofOfAuthority, cfg.Management)
+	require.Equal([]ids.ShortID{owned}, cfg.ControlKeys)
+	require.Equal([]ids.ShortID{owned}, authKeys)
+	approved, err := cfg.Approve(StageCreateSubnet, "create subnet")
+	require.NoError(err)
+	require.True(approved)
+
+	cfg = newConfig()
+	cfg.Management = "PoS"
+	cfg.setDefaults()
+	_, err = cfg.validate()
+	require.ErrorIs(err, ErrUnsupportedManagement)
+
+	cfg = newConfig()
+	cfg.Subnet = subnet.SubnetParams{Name: "l1"}
+	cfg.setDefaults()
+	_, err = cfg.validate()
+	require.ErrorIs(err, ErrNoL1Params)
+
+	cfg = newConfig()
+	cfg.Validators = append(cfg.Validators, validator.SubnetValidatorParams{NodeID: nodeID})
+	cfg.setDefaults()
+	_, err = cfg.validate()
+	require.ErrorIs(err, ErrDuplicatedL1Validator)
+
+	cfg = newConfig()
+	cfg.ControlKeys = []ids.ShortID{foreign, owned}
+	cfg.Threshold = 3
+	cfg.setDefaults()
+	_, err = cfg.validate()
+	require.ErrorIs(err, ErrInvalidL1Threshold)
+
+	cfg.Threshold = 2
+	_, err = cfg.validate()
+	require.ErrorIs(err, ErrNotEnoughKeychainSigner)
+
+	cfg.Threshold = 1
+	authKeys, err = cfg.validate()
+	require.NoError(err)
+	require.Equal([]ids.ShortID{owned}, authKeys)
+}
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/telemetry"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/wallet"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
@@ -45,6 +46,10 @@ func (c *Subnet) AddValidator(wallet wallet.Wallet, validatorInput validator.Sub
 		return nil, ErrEmptySubnetAuth
 	}
 
+	subnetAuth, err := c.computeSubnetAuth()
+	if err != nil {
+		return nil, err
+	}
 	wallet.SetSubnetAuthMultisig(c.DeployInfo.SubnetAuthKeys)
 
 	validator := &txs.SubnetValidator{
@@ -60,9 +65,13 @@ func (c *Subnet) AddValidator(wallet wallet.Wallet, validatorInput validator.Sub
 	if err != nil {
 		return nil, fmt.Errorf("error building tx: %w", err)
 	}
-	tx := txs.Tx{Unsigned: unsignedTx}
-	if err := wallet.P().Signer().Sign(context.Background(), &tx); err != nil {
-		return nil, fmt.Errorf("error signing tx: %w", err)
+	if subnetAuth != nil {
+		unsignedTx.SubnetAuth = subnetAuth
+	}
+	ms := multisig.New(&txs.Tx{Unsigned: unsignedTx})
+	if err := wallet.SignMultisig(context.Background(), ms); err != nil {
+		return nil, err
 	}
-	return multisig.New(&tx), nil
+	telemetry.TxBuilt(multisig.PChainAddSubnetValidatorTx.String())
+	return ms, nil
 }
@@ -0,0 +1,269 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/key"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/precompile/allowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/deployerallowlist"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/feemanager"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/nativeminter"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/rewardmanager"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// eip1967AdminSlot is the storage slot where transparent proxies keep their admin
+var eip1967AdminSlot = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+
+// allowListPrecompiles are the precompiles managed with an allow list, by config key
+var allowListPrecompiles = map[string]common.Address{
+	deployerallowlist.ConfigKey: deployerallowlist.ContractAddress,
+	txallowlist.ConfigKey:       txallowlist.ContractAddress,
+	nativeminter.ConfigKey:      nativeminter.ContractAddress,
+	feemanager.ConfigKey:        feemanager.ContractAddress,
+	rewardmanager.ConfigKey:     rewardmanager.ContractAddress,
+}
+
+// PermissionAuditParams selects what AuditPermissions checks besides the subnet owners
+type PermissionAuditParams struct {
+	SubnetID ids.ID
+	// RPCURL of the subnet EVM chain. If empty, the EVM checks are skipped
+	RPCURL string
+	// ValidatorManagerAddress is the validator manager contract, or its proxy. If
+	// zero, the validator manager checks are skipped
+	ValidatorManagerAddress common.Address
+	// DefaultPChainAddresses and DefaultEVMAddresses are keys known to be shared or
+	// test keys, in addition to the ewoq key, eg. the deployer key of a tool
+	DefaultPChainAddresses []ids.ShortID
+	DefaultEVMAddresses    []common.Address
+}
+
+// EVMOwner is an EVM account that holds a permission
+type EVMOwner struct {
+	Address common.Address
+	// IsContract tells if the account has code, eg. a multisig wallet. Otherwise
+	// it is controlled by a single key
+	IsContract bool
+}
+
+// PrecompileAdmins are the admins and managers of an allow list precompile
+type PrecompileAdmins struct {
+	Precompile string
+	Address    common.Address
+	Admins     []EVMOwner
+	Managers   []EVMOwner
+}
+
+// PermissionAudit lists who controls a subnet, as reported by AuditPermissions
+type PermissionAudit struct {
+	SubnetID    ids.ID
+	ControlKeys []ids.ShortID
+	Threshold   uint32
+	// ValidatorManagerOwner is the owner of the validator manager contract, if checked
+	ValidatorManagerOwner *EVMOwner
+	// ProxyAdmin is the admin of the validator manager proxy, if it is a transparent
+	// proxy, and ProxyAdminOwner the owner of the admin contract
+	ProxyAdmin      *EVMOwner
+	ProxyAdminOwner *EVMOwner
+	// PrecompileAdmins has the admins and managers of the active allow list precompiles,
+	// sorted by precompile
+	PrecompileAdmins []PrecompileAdmins
+	// Issues lists the risky configurations found
+	Issues []string
+}
+
+// Risky tells if any risky configuration was found
+func (a PermissionAudit) Risky() bool {
+	return len(a.Issues) > 0
+}
+
+// AuditPermissions reports the owners and threshold of [params.SubnetID], the owner of
+// its validator manager and proxy admin, and the admin lists of its precompiles, and
+// flags risky configurations: assets on mainnet controlled by a single key, and
+// permissions held by default keys, like ewoq
+//
+// Precompile admin and manager lists are taken from the active precompile configs, and
+// only the addresses that still hold their role on chain are reported, so roles granted
+// later by an admin are not listed
+func AuditPermissions(network avalanche.Network, params PermissionAuditParams) (PermissionAudit, error) {
+	audit := PermissionAudit{SubnetID: params.SubnetID}
+	var err error
+	audit.ControlKeys, audit.Threshold, err = multisig.GetOwners(network, params.SubnetID)
+	if err != nil {
+		return audit, err
+	}
+	if params.RPCURL != "" {
+		if err := auditEVMPermissions(&audit, params); err != nil {
+			return audit, err
+		}
+	}
+	ewoq, err := key.LoadEwoq()
+	if err != nil {
+		return audit, err
+	}
+	defaultPChainAddresses := append(ewoq.Addresses(), params.DefaultPChainAddresses...)
+	defaultEVMAddresses := append([]common.Address{common.HexToAddress(ewoq.C())}, params.DefaultEVMAddresses...)
+	audit.Issues = permissionIssues(audit, network.Kind == avalanche.Mainnet, defaultPChainAddresses, defaultEVMAddresses)
+	return audit, nil
+}
+
+func auditEVMPermissions(audit *PermissionAudit, params PermissionAuditParams) error {
+	client, err := evm.GetClient(params.RPCURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if params.ValidatorManagerAddress != (common.Address{}) {
+		if audit.ValidatorManagerOwner, err = getContractOwner(client, params.RPCURL, params.ValidatorManagerAddress); err != nil {
+			return fmt.Errorf("failure getting owner of validator manager %s: %w", params.ValidatorManagerAddress.Hex(), err)
+		}
+		ctx, cancel := utils.GetAPIContext()
+		adminSlot, err := client.StorageAt(ctx, params.ValidatorManagerAddress, eip1967AdminSlot, nil)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failure getting proxy admin of %s: %w", params.ValidatorManagerAddress.Hex(), err)
+		}
+		if proxyAdmin := common.BytesToAddress(adminSlot); proxyAdmin != (common.Address{}) {
+			if audit.ProxyAdmin, err = getEVMOwner(client, proxyAdmin); err != nil {
+				return err
+			}
+			if audit.ProxyAdmin.IsContract {
+				if audit.ProxyAdminOwner, err = getContractOwner(client, params.RPCURL, proxyAdmin); err != nil {
+					return fmt.Errorf("failure getting owner of proxy admin %s: %w", proxyAdmin.Hex(), err)
+				}
+			}
+		}
+	}
+	rpcClient, err := evm.GetRPCClient(params.RPCURL)
+	if err != nil {
+		return err
+	}
+	defer rpcClient.Close()
+	precompiles, err := evm.GetActivePrecompilesAt(rpcClient, nil)
+	if err != nil {
+		return err
+	}
+	for configKey, precompileAddress := range allowListPrecompiles {
+		config, ok := precompiles[configKey]
+		if !ok {
+			continue
+		}
+		admins := PrecompileAdmins{Precompile: configKey, Address: precompileAddress}
+		for _, role := range []struct {
+			field  string
+			role   allowlist.Role
+			owners *[]EVMOwner
+		}{
+			{"adminAddresses", allowlist.AdminRole, &admins.Admins},
+			{"managerAddresses", allowlist.ManagerRole, &admins.Managers},
+		} {
+			addresses, _ := config[role.field].([]interface{})
+			for _, addressValue := range addresses {
+				addressStr, ok := addressValue.(string)
+				if !ok {
+					continue
+				}
+				address := common.HexToAddress(addressStr)
+				out, err := evm.CallToMethod(params.RPCURL, precompileAddress, "readAllowList(address)->(uint256)", address)
+				if err != nil {
+					return fmt.Errorf("failure reading %s role of %s: %w", configKey, address.Hex(), err)
+				}
+				if currentRole, ok := out[0].(*big.Int); !ok || currentRole.Cmp(role.role.Big()) != 0 {
+					continue
+				}
+				owner, err := getEVMOwner(client, address)
+				if err != nil {
+					return err
+				}
+				*role.owners = append(*role.owners, *owner)
+			}
+		}
+		audit.PrecompileAdmins = append(audit.PrecompileAdmins, admins)
+	}
+	sort.Slice(audit.PrecompileAdmins, func(i, j int) bool {
+		return audit.PrecompileAdmins[i].Precompile < audit.PrecompileAdmins[j].Precompile
+	})
+	return nil
+}
+
+// getContractOwner calls owner() on [contractAddress]
+func getContractOwner(client ethclient.Client, rpcURL string, contractAddress common.Address) (*EVMOwner, error) {
+	out, err := evm.CallToMethod(rpcURL, contractAddress, "owner()->(address)")
+	if err != nil {
+		return nil, err
+	}
+	owner, ok := out[0].(common.Address)
+	if !ok {
+		return nil, fmt.Errorf("unexpected owner type %T", out[0])
+	}
+	return getEVMOwner(client, owner)
+}
+
+func getEVMOwner(client ethclient.Client, address common.Address) (*EVMOwner, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting code of %s: %w", address.Hex(), err)
+	}
+	return &EVMOwner{Address: address, IsContract: len(code) > 0}, nil
+}
+
+// permissionIssues flags the risky configurations of [audit]. Single key control is
+// only flagged on mainnet, while default keys are flagged on every network
+func permissionIssues(
+	audit PermissionAudit,
+	mainnet bool,
+	defaultPChainAddresses []ids.ShortID,
+	defaultEVMAddresses []common.Address,
+) []string {
+	issues := []string{}
+	for _, controlKey := range audit.ControlKeys {
+		if utils.Belongs(defaultPChainAddresses, controlKey) {
+			issues = append(issues, fmt.Sprintf("subnet control key %s is a default key", controlKey))
+		}
+	}
+	if mainnet && audit.Threshold == 1 {
+		if len(audit.ControlKeys) == 1 {
+			issues = append(issues, "subnet is owned by a single key")
+		} else {
+			issues = append(issues, fmt.Sprintf("any single one of the %d subnet control keys can act on the subnet", len(audit.ControlKeys)))
+		}
+	}
+	evmOwnerIssues := func(role string, owner *EVMOwner) {
+		if owner == nil {
+			return
+		}
+		if utils.Belongs(defaultEVMAddresses, owner.Address) {
+			issues = append(issues, fmt.Sprintf("%s %s is a default key", role, owner.Address.Hex()))
+		} else if mainnet && !owner.IsContract {
+			issues = append(issues, fmt.Sprintf("%s %s is a single key", role, owner.Address.Hex()))
+		}
+	}
+	evmOwnerIssues("validator manager owner", audit.ValidatorManagerOwner)
+	if audit.ProxyAdmin != nil && !audit.ProxyAdmin.IsContract {
+		evmOwnerIssues("validator manager proxy admin", audit.ProxyAdmin)
+	}
+	evmOwnerIssues("validator manager proxy admin owner", audit.ProxyAdminOwner)
+	for _, precompile := range audit.PrecompileAdmins {
+		for i := range precompile.Admins {
+			evmOwnerIssues(precompile.Precompile+" admin", &precompile.Admins[i])
+		}
+		for i := range precompile.Managers {
+			evmOwnerIssues(precompile.Precompile+" manager", &precompile.Managers[i])
+		}
+	}
+	return issues
+}
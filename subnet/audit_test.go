@@ -0,0 +1,57 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionIssues(t *testing.T) {
+	require := require.New(t)
+	defaultPChainAddress := ids.GenerateTestShortID()
+	defaultEVMAddress := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	multisigWallet := &EVMOwner{Address: common.HexToAddress("0x01"), IsContract: true}
+	singleKey := &EVMOwner{Address: common.HexToAddress("0x02")}
+
+	audit := PermissionAudit{
+		ControlKeys:           []ids.ShortID{ids.GenerateTestShortID(), ids.GenerateTestShortID()},
+		Threshold:             2,
+		ValidatorManagerOwner: multisigWallet,
+		ProxyAdmin:            &EVMOwner{Address: common.HexToAddress("0x03"), IsContract: true},
+		ProxyAdminOwner:       multisigWallet,
+		PrecompileAdmins: []PrecompileAdmins{
+			{Precompile: "txAllowListConfig", Admins: []EVMOwner{*multisigWallet}},
+		},
+	}
+	require.Empty(permissionIssues(audit, true, []ids.ShortID{defaultPChainAddress}, []common.Address{defaultEVMAddress}))
+
+	// single keys are only flagged on mainnet
+	audit.Threshold = 1
+	audit.ProxyAdminOwner = singleKey
+	audit.PrecompileAdmins[0].Managers = []EVMOwner{*singleKey}
+	require.Empty(permissionIssues(audit, false, []ids.ShortID{defaultPChainAddress}, []common.Address{defaultEVMAddress}))
+	issues := permissionIssues(audit, true, []ids.ShortID{defaultPChainAddress}, []common.Address{defaultEVMAddress})
+	require.Equal([]string{
+		"any single one of the 2 subnet control keys can act on the subnet",
+		"validator manager proxy admin owner 0x0000000000000000000000000000000000000002 is a single key",
+		"txAllowListConfig manager 0x0000000000000000000000000000000000000002 is a single key",
+	}, issues)
+
+	// default keys are flagged on every network
+	audit = PermissionAudit{
+		ControlKeys:           []ids.ShortID{defaultPChainAddress},
+		Threshold:             1,
+		ValidatorManagerOwner: &EVMOwner{Address: defaultEVMAddress},
+	}
+	issues = permissionIssues(audit, false, []ids.ShortID{defaultPChainAddress}, []common.Address{defaultEVMAddress})
+	require.Len(issues, 2)
+	require.Contains(issues[0], "is a default key")
+	require.Contains(issues[1], "validator manager owner")
+	issues = permissionIssues(audit, true, []ids.ShortID{defaultPChainAddress}, []common.Address{defaultEVMAddress})
+	require.Contains(issues, "subnet is owned by a single key")
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+)
+
+// DefaultChainlistURL is the public EVM chain registry used by RefreshChainlist
+const DefaultChainlistURL = "https://chainid.network/chains_mini.json"
+
+// chainlistSnapshot is a snapshot of the most used public networks of the registry,
+// used until RefreshChainlist is called
+//
+//go:embed chainlist.json
+var chainlistSnapshot []byte
+
+// PublicChain is an EVM network listed on the public chain registry
+type PublicChain struct {
+	Name      string `json:"name"`
+	ChainID   uint64 `json:"chainId"`
+	ShortName string `json:"shortName"`
+}
+
+var (
+	chainlistLock sync.RWMutex
+	chainlist     map[uint64]PublicChain
+)
+
+func parseChainlist(chainlistBytes []byte) (map[uint64]PublicChain, error) {
+	chains := []PublicChain{}
+	if err := json.Unmarshal(chainlistBytes, &chains); err != nil {
+		return nil, fmt.Errorf("invalid chainlist: %w", err)
+	}
+	byID := make(map[uint64]PublicChain, len(chains))
+	for _, chain := range chains {
+		byID[chain.ChainID] = chain
+	}
+	return byID, nil
+}
+
+// RefreshChainlist replaces the embedded snapshot of the public chain registry with
+// the full registry downloaded from [url], or DefaultChainlistURL if empty. The
+// registry is kept in memory, and used by all later chain ID checks
+func RefreshChainlist(url string) error {
+	if url == "" {
+		url = DefaultChainlistURL
+	}
+	chainlistBytes, err := utils.HTTPGet(url, "")
+	if err != nil {
+		return err
+	}
+	chains, err := parseChainlist(chainlistBytes)
+	if err != nil {
+		return err
+	}
+	chainlistLock.Lock()
+	defer chainlistLock.Unlock()
+	chainlist = chains
+	return nil
+}
+
+// LookupPublicChain returns the public network that uses [chainID], if any
+func LookupPublicChain(chainID *big.Int) (PublicChain, bool) {
+	if chainID == nil || !chainID.IsUint64() {
+		return PublicChain{}, false
+	}
+	chainlistLock.Lock()
+	defer chainlistLock.Unlock()
+	if chainlist == nil {
+		// the embedded snapshot is checked by tests
+		chainlist, _ = parseChainlist(chainlistSnapshot)
+	}
+	chain, ok := chainlist[chainID.Uint64()]
+	return chain, ok
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+)
+
+func TestChainlist(t *testing.T) {
+	require := require.New(t)
+	defer func() { chainlist = nil }()
+
+	_, err := parseChainlist(chainlistSnapshot)
+	require.NoError(err)
+	chain, ok := LookupPublicChain(big.NewInt(8453))
+	require.True(ok)
+	require.Equal("Base", chain.Name)
+	_, ok = LookupPublicChain(big.NewInt(123456))
+	require.False(ok)
+	_, ok = LookupPublicChain(new(big.Int).Lsh(big.NewInt(1), 70))
+	require.False(ok)
+
+	params := getDefaultSubnetEVMGenesis().SubnetEVM
+	params.ChainID = big.NewInt(8453)
+	warnings, err := params.Validate()
+	require.NoError(err)
+	require.Equal([]string{"chain ID 8453 collides with public network Base (base)"}, warnings)
+
+	// dev chain IDs listed on the registry are only warned about
+	params.ChainID = big.NewInt(31337)
+	subnet, err := New(&SubnetParams{SubnetEVM: params, Name: "hardhat"})
+	require.NoError(err)
+	require.Equal([]string{"chain ID 31337 collides with public network Hardhat (hardhat)"}, subnet.Warnings)
+	// while the C-Chain IDs are still rejected
+	params.ChainID = big.NewInt(LocalCChainEVMChainID)
+	_, err = New(&SubnetParams{SubnetEVM: params, Name: "local"})
+	require.ErrorContains(err, "collides with local network C-Chain")
+	params.ChainID = big.NewInt(avalanche.MainnetCChainEVMChainID)
+	warnings, err = params.Validate()
+	require.ErrorContains(err, "collides with Mainnet C-Chain")
+	require.Empty(warnings)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"name":"Some Chain","chain":"SC","chainId":123456,"shortName":"sc","networkId":123456}]`))
+	}))
+	defer server.Close()
+	require.NoError(RefreshChainlist(server.URL))
+	chain, ok = LookupPublicChain(big.NewInt(123456))
+	require.True(ok)
+	require.Equal("sc", chain.ShortName)
+	_, ok = LookupPublicChain(big.NewInt(8453))
+	require.False(ok)
+
+	require.Error(RefreshChainlist(server.URL + "/missing/\x7f"))
+}
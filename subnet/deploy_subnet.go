@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/telemetry"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/wallet"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -36,11 +37,12 @@ func (c *Subnet) CreateSubnetTx(wallet wallet.Wallet) (*multisig.Multisig, error
 	if err != nil {
 		return nil, fmt.Errorf("error building tx: %w", err)
 	}
-	tx := txs.Tx{Unsigned: unsignedTx}
-	if err := wallet.P().Signer().Sign(context.Background(), &tx); err != nil {
-		return nil, fmt.Errorf("error signing tx: %w", err)
+	ms := multisig.New(&txs.Tx{Unsigned: unsignedTx})
+	if err := wallet.SignMultisig(context.Background(), ms); err != nil {
+		return nil, err
 	}
-	return multisig.New(&tx), nil
+	telemetry.TxBuilt(multisig.PChainCreateSubnetTx.String())
+	return ms, nil
 }
 
 // CreateBlockchainTx creates uncommitted CreateChainTx
@@ -61,6 +63,10 @@ func (c *Subnet) CreateBlockchainTx(wallet wallet.Wallet) (*multisig.Multisig, e
 	if c.Name == "" {
 		return nil, fmt.Errorf("subnet name is not provided")
 	}
+	subnetAuth, err := c.computeSubnetAuth()
+	if err != nil {
+		return nil, err
+	}
 	wallet.SetSubnetAuthMultisig(c.DeployInfo.SubnetAuthKeys)
 
 	// create tx
@@ -75,9 +81,23 @@ func (c *Subnet) CreateBlockchainTx(wallet wallet.Wallet) (*multisig.Multisig, e
 	if err != nil {
 		return nil, fmt.Errorf("error building tx: %w", err)
 	}
-	tx := txs.Tx{Unsigned: unsignedTx}
-	if err := wallet.P().Signer().Sign(context.Background(), &tx); err != nil {
-		return nil, fmt.Errorf("error signing tx: %w", err)
+	if subnetAuth != nil {
+		unsignedTx.SubnetAuth = subnetAuth
+	}
+	ms := multisig.New(&txs.Tx{Unsigned: unsignedTx})
+	if err := wallet.SignMultisig(context.Background(), ms); err != nil {
+		return nil, err
+	}
+	telemetry.TxBuilt(multisig.PChainCreateChainTx.String())
+	return ms, nil
+}
+
+// computeSubnetAuth returns the subnet auth for txs signed by the subnet auth keys.
+// If the subnet control keys are not known, nil is returned, and the subnet auth
+// generated by the wallet is used
+func (c *Subnet) computeSubnetAuth() (*secp256k1fx.Input, error) {
+	if len(c.DeployInfo.ControlKeys) == 0 {
+		return nil, nil
 	}
-	return multisig.New(&tx), nil
+	return multisig.ComputeSubnetAuth(c.DeployInfo.ControlKeys, c.DeployInfo.Threshold, c.DeployInfo.SubnetAuthKeys)
 }
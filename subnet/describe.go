@@ -0,0 +1,298 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/validatormanager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// DescriptionSchemaVersion is the version of the Description JSON output. It is
+	// increased on any incompatible change, so consumers can detect them
+	DescriptionSchemaVersion = 1
+
+	ManagerKindPoA     = "proof-of-authority"
+	ManagerKindPoS     = "proof-of-stake"
+	ManagerKindUnknown = "unknown"
+)
+
+var ErrNoSubnetID = errors.New("subnet ID is not set")
+
+// DescribeParams selects the L1 chain Describe checks besides the P-Chain
+type DescribeParams struct {
+	// RPCURL of the EVM chain of the L1 that holds the validator manager. If empty, the
+	// chain checks are skipped
+	RPCURL string
+	// ValidatorManagerAddress overrides the manager address set on the L1 conversion
+	ValidatorManagerAddress common.Address
+}
+
+// DescribedChain is a blockchain validated by the subnet
+type DescribedChain struct {
+	BlockchainID ids.ID `json:"blockchainID"`
+	Name         string `json:"name"`
+	VMID         ids.ID `json:"vmID"`
+}
+
+// DescribedValidator is a validator of the subnet, as seen by the P-Chain and, for L1
+// validators, by the manager contract
+type DescribedValidator struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	// ValidationID is only set for L1 validators
+	ValidationID *ids.ID `json:"validationID,omitempty"`
+	PChainWeight uint64  `json:"pChainWeight"`
+	// PChainBalance is the nAVAX left to pay the L1 validator continuous fee
+	PChainBalance *uint64 `json:"pChainBalance,omitempty"`
+	// ContractStatus and ContractWeight are set if the manager contract was queried
+	ContractStatus string  `json:"contractStatus,omitempty"`
+	ContractWeight *uint64 `json:"contractWeight,omitempty"`
+}
+
+// ValidatorManagerInfo describes the validator manager contract of an L1
+type ValidatorManagerInfo struct {
+	Address common.Address `json:"address"`
+	// Kind is one of the ManagerKind* values
+	Kind  string    `json:"kind"`
+	Owner *EVMOwner `json:"owner,omitempty"`
+}
+
+// RPCHealth is the result of probing the RPC endpoint of an L1 chain
+type RPCHealth struct {
+	URL         string        `json:"url"`
+	Healthy     bool          `json:"healthy"`
+	ChainID     *big.Int      `json:"chainID,omitempty"`
+	BlockNumber uint64        `json:"blockNumber,omitempty"`
+	BlockTime   time.Time     `json:"blockTime,omitempty"`
+	Latency     time.Duration `json:"latency"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// FeeConfigInfo is the fee config currently active on an L1 chain
+type FeeConfigInfo struct {
+	FeeConfig commontype.FeeConfig `json:"feeConfig"`
+	// LastChangedAt is the block the config was last changed at by the fee manager
+	LastChangedAt *big.Int `json:"lastChangedAt,omitempty"`
+}
+
+// Description gathers the state of a deployed subnet or L1, as returned by Describe
+type Description struct {
+	SchemaVersion int                   `json:"schemaVersion"`
+	SubnetID      ids.ID                `json:"subnetID"`
+	Subnet        avalanche.SubnetInfo  `json:"subnet"`
+	Chains        []DescribedChain      `json:"chains"`
+	Validators    []DescribedValidator  `json:"validators"`
+	Manager       *ValidatorManagerInfo `json:"validatorManager,omitempty"`
+	RPC           *RPCHealth            `json:"rpc,omitempty"`
+	FeeConfig     *FeeConfigInfo        `json:"feeConfig,omitempty"`
+	// Errors lists the L1 chain checks that failed. The P-Chain ones fail Describe
+	Errors []string `json:"errors"`
+}
+
+// Describe gathers in one call the P-Chain state of the subnet, its chains and
+// validators, and, if [params.RPCURL] is set, the RPC health, fee config and validator
+// manager of the L1 chain, together with the manager view of each L1 validator.
+//
+// Failures reading the P-Chain are returned as errors. Failures of the L1 chain checks
+// are reported on Description.Errors, so a partial description is still available
+// when the L1 is not healthy
+func (c *Subnet) Describe(network avalanche.Network, params DescribeParams) (Description, error) {
+	if c.SubnetID == ids.Empty {
+		return Description{}, ErrNoSubnetID
+	}
+	description := Description{
+		SchemaVersion: DescriptionSchemaVersion,
+		SubnetID:      c.SubnetID,
+		Chains:        []DescribedChain{},
+		Validators:    []DescribedValidator{},
+		Errors:        []string{},
+	}
+	if err := describePChain(network, &description); err != nil {
+		return description, err
+	}
+	if params.RPCURL == "" {
+		return description, nil
+	}
+	description.RPC = probeRPC(params.RPCURL)
+	if !description.RPC.Healthy {
+		description.Errors = append(description.Errors, fmt.Sprintf("RPC %s is not healthy: %s", params.RPCURL, description.RPC.Error))
+		return description, nil
+	}
+	feeConfig, err := getFeeConfig(params.RPCURL)
+	if err != nil {
+		description.Errors = append(description.Errors, fmt.Sprintf("failure getting fee config: %s", err))
+	} else {
+		description.FeeConfig = feeConfig
+	}
+	managerAddress := params.ValidatorManagerAddress
+	if managerAddress == (common.Address{}) && description.Subnet.ManagerAddress != "" {
+		managerAddress = common.HexToAddress(description.Subnet.ManagerAddress)
+	}
+	if managerAddress != (common.Address{}) {
+		description.Manager, description.Errors = describeManager(params.RPCURL, managerAddress, description.Validators, description.Errors)
+	}
+	return description, nil
+}
+
+// describePChain fills the P-Chain parts of [description]
+func describePChain(network avalanche.Network, description *Description) error {
+	ctx, cancel := utils.GetAPILargeContext()
+	defer cancel()
+	subnetID := description.SubnetID
+	var err error
+	description.Subnet, err = network.GetSubnetInfo(ctx, subnetID)
+	if err != nil {
+		return fmt.Errorf("failure getting subnet %s: %w", subnetID, err)
+	}
+	pClient := platformvm.NewClient(network.Endpoint)
+	blockchains, err := pClient.GetBlockchains(ctx)
+	if err != nil {
+		return fmt.Errorf("failure getting blockchains: %w", err)
+	}
+	for _, blockchain := range blockchains {
+		if blockchain.SubnetID == subnetID {
+			description.Chains = append(description.Chains, DescribedChain{
+				BlockchainID: blockchain.ID,
+				Name:         blockchain.Name,
+				VMID:         blockchain.VMID,
+			})
+		}
+	}
+	validators, err := pClient.GetCurrentValidators(ctx, subnetID, nil)
+	if err != nil {
+		return fmt.Errorf("failure getting current validators for subnet %s: %w", subnetID, err)
+	}
+	validationIDs := map[ids.NodeID]ids.ID{}
+	if description.Subnet.IsL1() {
+		validationIDs, err = network.GetL1ValidationIDs(ctx, subnetID)
+		if err != nil {
+			return fmt.Errorf("failure getting L1 validators of subnet %s: %w", subnetID, err)
+		}
+	}
+	for _, validator := range validators {
+		described := DescribedValidator{
+			NodeID:       validator.NodeID,
+			PChainWeight: validator.Weight,
+		}
+		if validationID, ok := validationIDs[validator.NodeID]; ok {
+			described.ValidationID = &validationID
+			l1Validator, err := network.GetL1Validator(ctx, validationID)
+			if err != nil {
+				return fmt.Errorf("failure getting L1 validator %s: %w", validationID, err)
+			}
+			balance := uint64(l1Validator.Balance)
+			described.PChainBalance = &balance
+		}
+		description.Validators = append(description.Validators, described)
+	}
+	sort.Slice(description.Validators, func(i, j int) bool {
+		return description.Validators[i].NodeID.Compare(description.Validators[j].NodeID) < 0
+	})
+	return nil
+}
+
+// probeRPC checks that [rpcURL] answers, and gets its chain ID and latest block
+func probeRPC(rpcURL string) *RPCHealth {
+	health := &RPCHealth{URL: rpcURL}
+	start := time.Now()
+	defer func() { health.Latency = time.Since(start) }()
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer client.Close()
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	if health.ChainID, err = client.ChainID(ctx); err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.BlockNumber = header.Number.Uint64()
+	health.BlockTime = time.Unix(int64(header.Time), 0).UTC()
+	health.Healthy = true
+	return health
+}
+
+// getFeeConfig returns the fee config active at the latest block of [rpcURL]
+func getFeeConfig(rpcURL string) (*FeeConfigInfo, error) {
+	client, err := evm.GetRPCClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	feeConfig := &FeeConfigInfo{}
+	if err := client.CallContext(ctx, feeConfig, "eth_feeConfig", nil); err != nil {
+		return nil, err
+	}
+	return feeConfig, nil
+}
+
+// describeManager gets the kind and owner of the manager at [managerAddress], and the
+// contract view of the L1 validators in [validators], appending the failures to [errs]
+func describeManager(
+	rpcURL string,
+	managerAddress common.Address,
+	validators []DescribedValidator,
+	errs []string,
+) (*ValidatorManagerInfo, []string) {
+	manager := &ValidatorManagerInfo{
+		Address: managerAddress,
+		Kind:    getManagerKind(rpcURL, managerAddress),
+	}
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return manager, append(errs, err.Error())
+	}
+	defer client.Close()
+	if owner, err := getContractOwner(client, rpcURL, managerAddress); err == nil {
+		manager.Owner = owner
+	}
+	for i := range validators {
+		if validators[i].ValidationID == nil {
+			continue
+		}
+		contractValidator, err := validatormanager.GetContractValidator(rpcURL, managerAddress, *validators[i].ValidationID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failure getting validator %s from manager: %s", *validators[i].ValidationID, err))
+			continue
+		}
+		weight := contractValidator.Weight
+		validators[i].ContractStatus = contractValidator.Status.String()
+		validators[i].ContractWeight = &weight
+	}
+	return manager, errs
+}
+
+// getManagerKind tells the kind of the manager at [managerAddress] from the methods it
+// answers: weightToValue only exists on staking managers, and owner on ownable
+// proof of authority managers
+func getManagerKind(rpcURL string, managerAddress common.Address) string {
+	if _, err := evm.CallToMethod(rpcURL, managerAddress, "weightToValue(uint64)->(uint256)", uint64(1)); err == nil {
+		return ManagerKindPoS
+	}
+	if _, err := evm.CallToMethod(rpcURL, managerAddress, "owner()->(address)"); err == nil {
+		return ManagerKindPoA
+	}
+	return ManagerKindUnknown
+}
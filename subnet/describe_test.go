@@ -0,0 +1,70 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	require := require.New(t)
+	subnetID := ids.GenerateTestID()
+	conversionID := ids.GenerateTestID()
+	blockchainID := ids.GenerateTestID()
+	vmID := ids.GenerateTestID()
+	validationID := ids.GenerateTestID()
+	l1NodeID := ids.GenerateTestNodeID()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		require.NoError(json.NewDecoder(r.Body).Decode(&req))
+		switch req.Method {
+		case "platform.getSubnet":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"isPermissioned":false,"controlKeys":[],"threshold":"0","locktime":"0","subnetTransformationTxID":"%s","conversionID":"%s","managerChainID":"%s","managerAddress":"0x0feedc0de0000000000000000000000000000000"}}`, ids.Empty, conversionID, blockchainID)
+		case "platform.getBlockchains":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"blockchains":[{"id":"%s","name":"l1","subnetID":"%s","vmID":"%s"},{"id":"%s","name":"other","subnetID":"%s","vmID":"%s"}]}}`, blockchainID, subnetID, vmID, ids.GenerateTestID(), ids.GenerateTestID(), vmID)
+		case "platform.getCurrentValidators":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"validators":[{"nodeID":"%s","validationID":"%s","weight":"100","txID":"%s","startTime":"0","endTime":"0"}]}}`, l1NodeID, validationID, ids.Empty)
+		case "platform.getL1Validator":
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"nodeID":"%s","weight":"100","minNonce":"0","balance":"5000"}}`, l1NodeID)
+		default:
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+		}
+	}))
+	defer server.Close()
+	network := avalanche.NewNetwork(avalanche.Devnet, 1338, server.URL)
+
+	_, err := (&Subnet{}).Describe(network, DescribeParams{})
+	require.ErrorIs(err, ErrNoSubnetID)
+
+	description, err := (&Subnet{SubnetID: subnetID}).Describe(network, DescribeParams{})
+	require.NoError(err)
+	require.True(description.Subnet.IsL1())
+	require.Equal(blockchainID, description.Subnet.ManagerChainID)
+	require.Equal([]DescribedChain{{BlockchainID: blockchainID, Name: "l1", VMID: vmID}}, description.Chains)
+	require.Len(description.Validators, 1)
+	require.Equal(l1NodeID, description.Validators[0].NodeID)
+	require.Equal(validationID, *description.Validators[0].ValidationID)
+	require.Equal(uint64(100), description.Validators[0].PChainWeight)
+	require.Equal(uint64(5000), *description.Validators[0].PChainBalance)
+	require.Nil(description.RPC)
+
+	out, err := json.Marshal(description)
+	require.NoError(err)
+	report := map[string]interface{}{}
+	require.NoError(json.Unmarshal(out, &report))
+	require.Equal(float64(DescriptionSchemaVersion), report["schemaVersion"])
+	require.Equal(conversionID.String(), report["subnet"].(map[string]interface{})["conversionID"])
+	require.NotContains(report, "rpc")
+	require.Empty(report["errors"])
+}
@@ -0,0 +1,503 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/telemetry"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/wallet"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	commonAvago "github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+// X-Chain asset restrictions, as enforced by avalanchego
+const (
+	maxAssetNameLen      = 128
+	maxAssetSymbolLen    = 4
+	maxAssetDenomination = 32
+)
+
+var ErrTransformNotReady = errors.New("transform subnet tx is not fully signed")
+
+// ElasticSubnetParams are the parameters used to transform a permissioned subnet into an
+// elastic (permissionless) one, where validators stake a subnet specific asset
+type ElasticSubnetParams struct {
+	// TokenName is the name of the staking asset created on the X-Chain.
+	// Only ASCII letters, digits and spaces are allowed
+	TokenName string
+	// TokenSymbol is the symbol of the staking asset, 1 to 4 uppercase ASCII letters
+	TokenSymbol string
+	// TokenDenomination is the number of decimal digits of the staking asset
+	TokenDenomination byte
+
+	// MaxSupply is the maximum amount of the staking asset that will ever exist. The
+	// difference with InitialSupply is reserved for staking rewards
+	MaxSupply uint64
+	// InitialSupply is the amount of the staking asset in circulation after the transform
+	InitialSupply uint64
+
+	// MinConsumptionRate and MaxConsumptionRate are the reward rates, out of 1,000,000, for
+	// stakers with the shortest and the longest (minting period) durations
+	MinConsumptionRate uint64
+	MaxConsumptionRate uint64
+
+	// MinValidatorStake and MaxValidatorStake bound the stake of a validator, the latter
+	// including delegations
+	MinValidatorStake uint64
+	MaxValidatorStake uint64
+
+	// MinStakeDuration and MaxStakeDuration bound the duration of a validation or delegation.
+	// MaxStakeDuration can't be greater than the primary network one
+	MinStakeDuration time.Duration
+	MaxStakeDuration time.Duration
+
+	// MinDelegationFee is the minimum fee, out of 1,000,000, a validator can charge delegators
+	MinDelegationFee uint32
+	// MinDelegatorStake is the minimum amount a delegator can stake
+	MinDelegatorStake uint64
+	// MaxValidatorWeightFactor limits the delegations a validator can receive to this factor
+	// times its own stake. A value of 1 disables delegation
+	MaxValidatorWeightFactor byte
+	// UptimeRequirement is the minimum uptime, out of 1,000,000, a validator needs to be rewarded
+	UptimeRequirement uint32
+}
+
+// ElasticValidatorParams describes a permissionless validator of an elastic subnet
+type ElasticValidatorParams struct {
+	// NodeID of the validator. It must already be a primary network validator for the
+	// whole validation period, and be tracking the subnet
+	NodeID ids.NodeID
+	// StakeAmount is the amount of the subnet staking asset staked by the validator
+	StakeAmount uint64
+	// Duration of the validation
+	Duration time.Duration
+	// DelegationFee charged to delegators, out of 1,000,000. It can't be lower than the
+	// subnet MinDelegationFee. If not set, MinDelegationFee is used
+	DelegationFee uint32
+}
+
+// ElasticSubnetValidationError lists all the issues found on ElasticSubnetParams
+type ElasticSubnetValidationError struct {
+	Issues []string
+}
+
+func (e *ElasticSubnetValidationError) Error() string {
+	return fmt.Sprintf("invalid elastic subnet params: %s", strings.Join(e.Issues, "; "))
+}
+
+// MarshalJSON serializes the issues with a stable schema, versioned by ValidationSchemaVersion
+func (e *ElasticSubnetValidationError) MarshalJSON() ([]byte, error) {
+	return marshalValidation("elastic-subnet", e.Issues, nil)
+}
+
+// Validate checks [p] against the rules avalanchego applies to CreateAssetTx and
+// TransformSubnetTx on [network], returning an *ElasticSubnetValidationError that
+// lists all the issues found, or nil if there are none
+func (p *ElasticSubnetParams) Validate(network avalanche.Network) error {
+	issues := []string{}
+	issues = append(issues, p.validateToken()...)
+	issues = append(issues, p.validateSupply()...)
+	issues = append(issues, p.validateStaking(network)...)
+	if len(issues) > 0 {
+		return &ElasticSubnetValidationError{Issues: issues}
+	}
+	return nil
+}
+
+func (p *ElasticSubnetParams) validateToken() []string {
+	issues := []string{}
+	switch {
+	case p.TokenName == "":
+		issues = append(issues, "token name is not provided")
+	case len(p.TokenName) > maxAssetNameLen:
+		issues = append(issues, fmt.Sprintf("token name is longer than %d characters", maxAssetNameLen))
+	case strings.TrimSpace(p.TokenName) != p.TokenName:
+		issues = append(issues, "token name has leading or trailing spaces")
+	}
+	for _, r := range p.TokenName {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsNumber(r) || r == ' ') {
+			issues = append(issues, fmt.Sprintf("token name has illegal character %q", r))
+			break
+		}
+	}
+	switch {
+	case p.TokenSymbol == "":
+		issues = append(issues, "token symbol is not provided")
+	case len(p.TokenSymbol) > maxAssetSymbolLen:
+		issues = append(issues, fmt.Sprintf("token symbol is longer than %d characters", maxAssetSymbolLen))
+	}
+	for _, r := range p.TokenSymbol {
+		if r > unicode.MaxASCII || !unicode.IsUpper(r) {
+			issues = append(issues, "token symbol must only contain uppercase letters")
+			break
+		}
+	}
+	if p.TokenDenomination > maxAssetDenomination {
+		issues = append(issues, fmt.Sprintf("token denomination %d is greater than %d", p.TokenDenomination, maxAssetDenomination))
+	}
+	return issues
+}
+
+func (p *ElasticSubnetParams) validateSupply() []string {
+	issues := []string{}
+	if p.InitialSupply == 0 {
+		issues = append(issues, "initial supply must be positive")
+	}
+	if p.InitialSupply > p.MaxSupply {
+		issues = append(issues, fmt.Sprintf("initial supply %d is greater than max supply %d", p.InitialSupply, p.MaxSupply))
+	}
+	if p.MinConsumptionRate > p.MaxConsumptionRate {
+		issues = append(issues, fmt.Sprintf("min consumption rate %d is greater than max consumption rate %d", p.MinConsumptionRate, p.MaxConsumptionRate))
+	}
+	if p.MaxConsumptionRate > reward.PercentDenominator {
+		issues = append(issues, fmt.Sprintf("max consumption rate %d is greater than %d", p.MaxConsumptionRate, reward.PercentDenominator))
+	}
+	return issues
+}
+
+func (p *ElasticSubnetParams) validateStaking(network avalanche.Network) []string {
+	issues := []string{}
+	if p.MinValidatorStake == 0 {
+		issues = append(issues, "min validator stake must be positive")
+	}
+	if p.MinValidatorStake > p.InitialSupply {
+		issues = append(issues, fmt.Sprintf("min validator stake %d is greater than initial supply %d", p.MinValidatorStake, p.InitialSupply))
+	}
+	if p.MinValidatorStake > p.MaxValidatorStake {
+		issues = append(issues, fmt.Sprintf("min validator stake %d is greater than max validator stake %d", p.MinValidatorStake, p.MaxValidatorStake))
+	}
+	if p.MaxValidatorStake > p.MaxSupply {
+		issues = append(issues, fmt.Sprintf("max validator stake %d is greater than max supply %d", p.MaxValidatorStake, p.MaxSupply))
+	}
+	if p.MinStakeDuration < time.Second {
+		issues = append(issues, "min stake duration must be at least 1s")
+	}
+	if p.MinStakeDuration > p.MaxStakeDuration {
+		issues = append(issues, fmt.Sprintf("min stake duration %s is greater than max stake duration %s", p.MinStakeDuration, p.MaxStakeDuration))
+	}
+	if genesisParams := network.GenesisParams(); genesisParams != nil && p.MaxStakeDuration > genesisParams.MaxStakeDuration {
+		issues = append(issues, fmt.Sprintf("max stake duration %s is greater than the network max stake duration %s", p.MaxStakeDuration, genesisParams.MaxStakeDuration))
+	}
+	if p.MinDelegationFee > reward.PercentDenominator {
+		issues = append(issues, fmt.Sprintf("min delegation fee %d is greater than %d", p.MinDelegationFee, reward.PercentDenominator))
+	}
+	if p.MinDelegatorStake == 0 {
+		issues = append(issues, "min delegator stake must be positive")
+	}
+	if p.MaxValidatorWeightFactor == 0 {
+		issues = append(issues, "max validator weight factor must be positive")
+	}
+	if p.UptimeRequirement > reward.PercentDenominator {
+		issues = append(issues, fmt.Sprintf("uptime requirement %d is greater than %d", p.UptimeRequirement, reward.PercentDenominator))
+	}
+	return issues
+}
+
+// ValidateValidator checks that [validator] can validate an elastic subnet with params [p]
+func (p *ElasticSubnetParams) ValidateValidator(validator ElasticValidatorParams) error {
+	switch {
+	case validator.NodeID == ids.EmptyNodeID:
+		return ErrEmptyValidatorNodeID
+	case validator.StakeAmount < p.MinValidatorStake:
+		return fmt.Errorf("stake %d of %s is lower than min validator stake %d", validator.StakeAmount, validator.NodeID, p.MinValidatorStake)
+	case validator.StakeAmount > p.MaxValidatorStake:
+		return fmt.Errorf("stake %d of %s is greater than max validator stake %d", validator.StakeAmount, validator.NodeID, p.MaxValidatorStake)
+	case validator.Duration < p.MinStakeDuration:
+		return fmt.Errorf("duration %s of %s is lower than min stake duration %s", validator.Duration, validator.NodeID, p.MinStakeDuration)
+	case validator.Duration > p.MaxStakeDuration:
+		return fmt.Errorf("duration %s of %s is greater than max stake duration %s", validator.Duration, validator.NodeID, p.MaxStakeDuration)
+	case validator.DelegationFee != 0 && validator.DelegationFee < p.MinDelegationFee:
+		return fmt.Errorf("delegation fee %d of %s is lower than min delegation fee %d", validator.DelegationFee, validator.NodeID, p.MinDelegationFee)
+	case validator.DelegationFee > reward.PercentDenominator:
+		return fmt.Errorf("delegation fee %d of %s is greater than %d", validator.DelegationFee, validator.NodeID, reward.PercentDenominator)
+	}
+	return nil
+}
+
+// ElasticSubnetResult contains the outcome of each step of TransformToElastic. On failure,
+// it has the results of the steps that did complete
+type ElasticSubnetResult struct {
+	// AssetID is the ID of the staking asset, which is also the ID of its CreateAssetTx
+	AssetID ids.ID
+	// ExportTxID is the X-Chain tx moving the staking asset to the P-Chain
+	ExportTxID ids.ID
+	// ImportTxID is the P-Chain tx receiving the staking asset
+	ImportTxID ids.ID
+	// TransformTx is the TransformSubnetTx. It is only partially signed if the wallet
+	// doesn't hold enough subnet auth keys, in which case the remaining steps are not done
+	TransformTx   *multisig.Multisig
+	TransformTxID ids.ID
+	// ValidatorTxIDs are the AddPermissionlessValidatorTx of each validator
+	ValidatorTxIDs map[ids.NodeID]ids.ID
+}
+
+// TransformToElastic runs the full sequence to turn the subnet into an elastic one:
+//   - create the staking asset on the X-Chain, with all its max supply owned by the wallet
+//   - export it to the P-Chain and import it there
+//   - issue a TransformSubnetTx, which locks max supply - initial supply as rewards
+//   - add [validators] as permissionless validators, staking the new asset
+//
+// [params] and [validators] are validated against [network] rules before issuing anything.
+// If the wallet can't fully sign the TransformSubnetTx, ErrTransformNotReady is returned
+// along with the partially signed tx, to be signed by the remaining subnet auth keys and
+// committed, after which the validators can be added with AddPermissionlessValidator
+func (c *Subnet) TransformToElastic(
+	ctx context.Context,
+	network avalanche.Network,
+	wallet wallet.Wallet,
+	params ElasticSubnetParams,
+	validators []ElasticValidatorParams,
+) (*ElasticSubnetResult, error) {
+	if c.SubnetID == ids.Empty {
+		return nil, ErrEmptySubnetID
+	}
+	if len(c.DeployInfo.SubnetAuthKeys) == 0 {
+		return nil, ErrEmptySubnetAuth
+	}
+	if err := params.Validate(network); err != nil {
+		return nil, err
+	}
+	staked := uint64(0)
+	for _, validator := range validators {
+		if err := params.ValidateValidator(validator); err != nil {
+			return nil, err
+		}
+		staked += validator.StakeAmount
+	}
+	if staked > params.InitialSupply {
+		return nil, fmt.Errorf("validators stake %d is greater than initial supply %d", staked, params.InitialSupply)
+	}
+	result := &ElasticSubnetResult{
+		ValidatorTxIDs: map[ids.NodeID]ids.ID{},
+	}
+	var err error
+	result.AssetID, err = CreateStakingAsset(ctx, wallet, params)
+	if err != nil {
+		return result, err
+	}
+	result.ExportTxID, result.ImportTxID, err = MoveAssetToPChain(ctx, wallet, result.AssetID, params.MaxSupply)
+	if err != nil {
+		return result, err
+	}
+	result.TransformTx, err = c.TransformSubnetTx(wallet, result.AssetID, params)
+	if err != nil {
+		return result, err
+	}
+	isReady, err := result.TransformTx.IsReadyToCommit()
+	if err != nil {
+		return result, err
+	}
+	if !isReady {
+		return result, ErrTransformNotReady
+	}
+	result.TransformTxID, err = c.Commit(*result.TransformTx, wallet, true)
+	if err != nil {
+		return result, err
+	}
+	for _, validator := range validators {
+		if validator.DelegationFee == 0 {
+			validator.DelegationFee = params.MinDelegationFee
+		}
+		txID, err := c.AddPermissionlessValidator(ctx, wallet, result.AssetID, validator)
+		if err != nil {
+			return result, err
+		}
+		result.ValidatorTxIDs[validator.NodeID] = txID
+	}
+	return result, nil
+}
+
+// walletOwner returns an owner for the first address of the wallet
+func walletOwner(wallet wallet.Wallet) (*secp256k1fx.OutputOwners, error) {
+	addrs := wallet.Addresses()
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("wallet has no addresses")
+	}
+	return &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     addrs[:1],
+	}, nil
+}
+
+// CreateStakingAsset issues a CreateAssetTx on the X-Chain for the staking asset described
+// by [params], minting its whole max supply to the wallet, and returns the asset ID
+func CreateStakingAsset(
+	ctx context.Context,
+	wallet wallet.Wallet,
+	params ElasticSubnetParams,
+) (ids.ID, error) {
+	owner, err := walletOwner(wallet)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := wallet.X().IssueCreateAssetTx(
+		params.TokenName,
+		params.TokenSymbol,
+		params.TokenDenomination,
+		map[uint32][]verify.State{
+			0: {
+				&secp256k1fx.TransferOutput{
+					Amt:          params.MaxSupply,
+					OutputOwners: *owner,
+				},
+			},
+		},
+		commonAvago.WithContext(ctx),
+	)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failure creating staking asset: %w", err)
+	}
+	return tx.ID(), nil
+}
+
+// MoveAssetToPChain exports [amount] of [assetID] from the X-Chain and imports it on the
+// P-Chain, owned by the wallet. Returns the export and import tx IDs
+func MoveAssetToPChain(
+	ctx context.Context,
+	wallet wallet.Wallet,
+	assetID ids.ID,
+	amount uint64,
+) (ids.ID, ids.ID, error) {
+	owner, err := walletOwner(wallet)
+	if err != nil {
+		return ids.Empty, ids.Empty, err
+	}
+	exportTx, err := wallet.X().IssueExportTx(
+		avagoconstants.PlatformChainID,
+		[]*avax.TransferableOutput{
+			{
+				Asset: avax.Asset{ID: assetID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt:          amount,
+					OutputOwners: *owner,
+				},
+			},
+		},
+		commonAvago.WithContext(ctx),
+	)
+	if err != nil {
+		return ids.Empty, ids.Empty, fmt.Errorf("failure exporting asset %s from X-Chain: %w", assetID, err)
+	}
+	importTx, err := wallet.P().IssueImportTx(
+		wallet.X().Builder().Context().BlockchainID,
+		owner,
+		commonAvago.WithContext(ctx),
+	)
+	if err != nil {
+		return exportTx.ID(), ids.Empty, fmt.Errorf("failure importing asset %s into P-Chain: %w", assetID, err)
+	}
+	return exportTx.ID(), importTx.ID(), nil
+}
+
+// TransformSubnetTx creates uncommitted TransformSubnetTx, that makes the subnet permissionless
+// with [assetID] as staking asset. [assetID] must already be on the P-Chain, owned by the wallet.
+// keychain in wallet will be used to build, sign and pay for the transaction
+func (c *Subnet) TransformSubnetTx(
+	wallet wallet.Wallet,
+	assetID ids.ID,
+	params ElasticSubnetParams,
+) (*multisig.Multisig, error) {
+	if c.SubnetID == ids.Empty {
+		return nil, ErrEmptySubnetID
+	}
+	if len(c.DeployInfo.SubnetAuthKeys) == 0 {
+		return nil, ErrEmptySubnetAuth
+	}
+	subnetAuth, err := c.computeSubnetAuth()
+	if err != nil {
+		return nil, err
+	}
+	wallet.SetSubnetAuthMultisig(c.DeployInfo.SubnetAuthKeys)
+
+	unsignedTx, err := wallet.P().Builder().NewTransformSubnetTx(
+		c.SubnetID,
+		assetID,
+		params.InitialSupply,
+		params.MaxSupply,
+		params.MinConsumptionRate,
+		params.MaxConsumptionRate,
+		params.MinValidatorStake,
+		params.MaxValidatorStake,
+		params.MinStakeDuration,
+		params.MaxStakeDuration,
+		params.MinDelegationFee,
+		params.MinDelegatorStake,
+		params.MaxValidatorWeightFactor,
+		params.UptimeRequirement,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error building tx: %w", err)
+	}
+	if subnetAuth != nil {
+		unsignedTx.SubnetAuth = subnetAuth
+	}
+	ms := multisig.New(&txs.Tx{Unsigned: unsignedTx})
+	if err := wallet.SignMultisig(context.Background(), ms); err != nil {
+		return nil, err
+	}
+	telemetry.TxBuilt(multisig.PChainTransformSubnetTx.String())
+	return ms, nil
+}
+
+// AddPermissionlessValidator issues an AddPermissionlessValidatorTx that adds [validator]
+// to the elastic subnet, staking [assetID]. Validation and delegation rewards go to the wallet
+func (c *Subnet) AddPermissionlessValidator(
+	ctx context.Context,
+	wallet wallet.Wallet,
+	assetID ids.ID,
+	validator ElasticValidatorParams,
+) (ids.ID, error) {
+	if c.SubnetID == ids.Empty {
+		return ids.Empty, ErrEmptySubnetID
+	}
+	if validator.NodeID == ids.EmptyNodeID {
+		return ids.Empty, ErrEmptyValidatorNodeID
+	}
+	if validator.Duration == 0 {
+		return ids.Empty, ErrEmptyValidatorDuration
+	}
+	owner, err := walletOwner(wallet)
+	if err != nil {
+		return ids.Empty, err
+	}
+	start := time.Now()
+	tx, err := wallet.P().IssueAddPermissionlessValidatorTx(
+		&txs.SubnetValidator{
+			Validator: txs.Validator{
+				NodeID: validator.NodeID,
+				Start:  uint64(start.Unix()),
+				End:    uint64(start.Add(validator.Duration).Unix()),
+				Wght:   validator.StakeAmount,
+			},
+			Subnet: c.SubnetID,
+		},
+		&signer.Empty{},
+		assetID,
+		owner,
+		owner,
+		validator.DelegationFee,
+		commonAvago.WithContext(ctx),
+	)
+	if err != nil {
+		telemetry.Failure("add permissionless validator", err)
+		return ids.Empty, fmt.Errorf("failure adding permissionless validator %s: %w", validator.NodeID, err)
+	}
+	telemetry.TxIssued(multisig.PChainAddPermissionlessValidatorTx.String(), time.Since(start))
+	return tx.ID(), nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func getDefaultElasticSubnetParams() ElasticSubnetParams {
+	return ElasticSubnetParams{
+		TokenName:                "Test Token",
+		TokenSymbol:              "TEST",
+		TokenDenomination:        9,
+		MaxSupply:                1_000_000_000,
+		InitialSupply:            600_000_000,
+		MinConsumptionRate:       100_000,
+		MaxConsumptionRate:       120_000,
+		MinValidatorStake:        1_000,
+		MaxValidatorStake:        100_000_000,
+		MinStakeDuration:         24 * time.Hour,
+		MaxStakeDuration:         365 * 24 * time.Hour,
+		MinDelegationFee:         20_000,
+		MinDelegatorStake:        100,
+		MaxValidatorWeightFactor: 5,
+		UptimeRequirement:        800_000,
+	}
+}
+
+func TestElasticSubnetParamsValidate(t *testing.T) {
+	require := require.New(t)
+	network := avalanche.FujiNetwork()
+
+	params := getDefaultElasticSubnetParams()
+	require.NoError(params.Validate(network))
+
+	params.TokenName = " Test-Token"
+	params.TokenSymbol = "test1"
+	params.InitialSupply = params.MaxSupply + 1
+	params.MaxStakeDuration = 2 * 365 * 24 * time.Hour
+	params.MaxValidatorWeightFactor = 0
+	err := params.Validate(network)
+	var validationErr *ElasticSubnetValidationError
+	require.ErrorAs(err, &validationErr)
+	require.Len(validationErr.Issues, 7)
+	require.Contains(err.Error(), "leading or trailing spaces")
+	require.Contains(err.Error(), "illegal character '-'")
+	require.Contains(err.Error(), "token symbol is longer than 4 characters")
+	require.Contains(err.Error(), "token symbol must only contain uppercase letters")
+	require.Contains(err.Error(), "initial supply 1000000001 is greater than max supply")
+	require.Contains(err.Error(), "greater than the network max stake duration")
+	require.Contains(err.Error(), "max validator weight factor must be positive")
+
+	empty := &ElasticSubnetParams{}
+	err = empty.Validate(network)
+	require.ErrorAs(err, &validationErr)
+	require.Contains(err.Error(), "token name is not provided")
+	require.Contains(err.Error(), "min stake duration must be at least 1s")
+}
+
+func TestElasticSubnetParamsValidateValidator(t *testing.T) {
+	require := require.New(t)
+	params := getDefaultElasticSubnetParams()
+	validator := ElasticValidatorParams{
+		NodeID:      ids.GenerateTestNodeID(),
+		StakeAmount: 10_000,
+		Duration:    48 * time.Hour,
+	}
+	require.NoError(params.ValidateValidator(validator))
+
+	invalid := validator
+	invalid.NodeID = ids.EmptyNodeID
+	require.ErrorIs(params.ValidateValidator(invalid), ErrEmptyValidatorNodeID)
+	invalid = validator
+	invalid.StakeAmount = 999
+	require.ErrorContains(params.ValidateValidator(invalid), "lower than min validator stake")
+	invalid = validator
+	invalid.Duration = time.Hour
+	require.ErrorContains(params.ValidateValidator(invalid), "lower than min stake duration")
+	invalid = validator
+	invalid.DelegationFee = 10_000
+	require.ErrorContains(params.ValidateValidator(invalid), "lower than min delegation fee")
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/telemetry"
 	utilsSDK "github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/wallet"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
@@ -55,6 +56,10 @@ type SubnetEVMParams struct {
 	// Allocation specifies the initial state that is part of the genesis block.
 	Allocation core.GenesisAlloc
 
+	// VestingAllocations are allocations locked at genesis in a vesting contract, that
+	// releases them to their beneficiaries over time. See VestingAllocation
+	VestingAllocations []VestingAllocation
+
 	// Ethereum uses Precompiles to efficiently implement cryptographic primitives within the EVM
 	// instead of re-implementing the same primitives in Solidity.
 	//
@@ -102,6 +107,10 @@ type Subnet struct {
 
 	// DeployInfo contains all the necessary information for createSubnetTx
 	DeployInfo DeployParams
+
+	// Warnings are the issues found on the Subnet-EVM params that don't prevent
+	// creating the genesis, eg. a chain ID used by a public network
+	Warnings []string
 }
 
 func (c *Subnet) SetParams(controlKeys []ids.ShortID, subnetAuthKeys []ids.ShortID, threshold uint32) {
@@ -163,12 +172,13 @@ func New(subnetParams *SubnetParams) (*Subnet, error) {
 	}
 
 	var genesisBytes []byte
+	var warnings []string
 	var err error
 	switch {
 	case subnetParams.GenesisFilePath != "":
 		genesisBytes, err = os.ReadFile(subnetParams.GenesisFilePath)
 	case subnetParams.SubnetEVM != nil:
-		genesisBytes, err = createEvmGenesis(subnetParams.SubnetEVM)
+		genesisBytes, warnings, err = createEvmGenesis(subnetParams.SubnetEVM)
 	default:
 	}
 	if err != nil {
@@ -180,9 +190,10 @@ func New(subnetParams *SubnetParams) (*Subnet, error) {
 		return nil, fmt.Errorf("failed to create VM ID from %s: %w", subnetParams.Name, err)
 	}
 	subnet := Subnet{
-		Name:    subnetParams.Name,
-		VMID:    vmID,
-		Genesis: genesisBytes,
+		Name:     subnetParams.Name,
+		VMID:     vmID,
+		Genesis:  genesisBytes,
+		Warnings: warnings,
 	}
 	return &subnet, nil
 }
@@ -193,31 +204,18 @@ func (c *Subnet) SetSubnetID(subnetID ids.ID) {
 
 func createEvmGenesis(
 	subnetEVMParams *SubnetEVMParams,
-) ([]byte, error) {
+) ([]byte, []string, error) {
 	genesis := core.Genesis{}
 	genesis.Timestamp = *utils.TimeToNewUint64(time.Now())
 
 	conf := params.SubnetEVMDefaultChainConfig
 	conf.NetworkUpgrades = params.NetworkUpgrades{}
 
-	var err error
-
-	if subnetEVMParams.ChainID == nil {
-		return nil, fmt.Errorf("genesis params chain ID cannot be empty")
-	}
-
-	if subnetEVMParams.FeeConfig == commontype.EmptyFeeConfig {
-		return nil, fmt.Errorf("genesis params fee config cannot be empty")
-	}
-
-	if subnetEVMParams.Allocation == nil {
-		return nil, fmt.Errorf("genesis params allocation cannot be empty")
-	}
-	allocation := subnetEVMParams.Allocation
-
-	if subnetEVMParams.Precompiles == nil {
-		return nil, fmt.Errorf("genesis params precompiles cannot be empty")
+	warnings, err := subnetEVMParams.validateGenesis()
+	if err != nil {
+		return nil, nil, err
 	}
+	allocation := subnetEVMParams.genesisAllocation()
 
 	conf.FeeConfig = subnetEVMParams.FeeConfig
 	conf.GenesisPrecompiles = subnetEVMParams.Precompiles
@@ -231,16 +229,16 @@ func createEvmGenesis(
 
 	jsonBytes, err := genesis.MarshalJSON()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var prettyJSON bytes.Buffer
 	err = json.Indent(&prettyJSON, jsonBytes, "", "    ")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return prettyJSON.Bytes(), nil
+	return prettyJSON.Bytes(), warnings, nil
 }
 
 func vmID(vmName string) (ids.ID, error) {
@@ -275,6 +273,8 @@ func (c *Subnet) Commit(ms multisig.Multisig, wallet wallet.Wallet, waitForTxAcc
 	if err != nil {
 		return ids.Empty, err
 	}
+	txKind, _ := ms.GetTxKind()
+	startTime := time.Now()
 	for i := 0; i < repeats; i++ {
 		ctx, cancel := utilsSDK.GetAPILargeContext()
 		defer cancel()
@@ -295,8 +295,10 @@ func (c *Subnet) Commit(ms multisig.Multisig, wallet wallet.Wallet, waitForTxAcc
 		time.Sleep(sleepBetweenRepeats)
 	}
 	if issueTxErr != nil {
+		telemetry.Failure("commit", issueTxErr)
 		return ids.Empty, fmt.Errorf("issue tx error %w", issueTxErr)
 	}
+	telemetry.TxIssued(txKind.String(), time.Since(startTime))
 	if _, ok := ms.PChainTx.Unsigned.(*txs.CreateSubnetTx); ok {
 		c.SubnetID = tx.ID()
 	}
@@ -0,0 +1,196 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/subnet-evm/commontype"
+)
+
+const (
+	// C-Chain EVM chain ID of local networks
+	LocalCChainEVMChainID = avalanche.LocalCChainEVMChainID
+	// gas needed by a native transfer, the cheapest possible tx
+	minGasLimit = 21_000
+)
+
+// ValidationSchemaVersion is the version of the JSON output of the params validation
+// errors. It is increased on any incompatible change, so consumers can detect them
+const ValidationSchemaVersion = 1
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ValidationError lists all the issues found on SubnetEVMParams, along the warnings
+// that don't prevent creating the chain
+type ValidationError struct {
+	Issues   []string
+	Warnings []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid subnet-evm params: %s", strings.Join(e.Issues, "; "))
+}
+
+type validationJSON struct {
+	SchemaVersion int      `json:"schemaVersion"`
+	Params        string   `json:"params"`
+	Valid         bool     `json:"valid"`
+	Issues        []string `json:"issues"`
+	Warnings      []string `json:"warnings"`
+}
+
+func marshalValidation(params string, issues []string, warnings []string) ([]byte, error) {
+	if issues == nil {
+		issues = []string{}
+	}
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return json.Marshal(validationJSON{
+		SchemaVersion: ValidationSchemaVersion,
+		Params:        params,
+		Valid:         len(issues) == 0,
+		Issues:        issues,
+		Warnings:      warnings,
+	})
+}
+
+// MarshalJSON serializes the issues with a stable schema, versioned by ValidationSchemaVersion
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return marshalValidation("subnet-evm", e.Issues, e.Warnings)
+}
+
+// Validate checks [p] in one pass, returning a *ValidationError that lists all
+// the issues found, or nil if there are none. Precompile activation timestamps in
+// the past are also reported, as they are only valid when regenerating the genesis
+// of an existing chain, so this check is not done when creating the genesis.
+// The chain ID is checked against the public chain registry, see RefreshChainlist,
+// and the collisions are returned as warnings, as many registered IDs, eg. 1337 or
+// 31337, are commonly used by dev networks
+func (p *SubnetEVMParams) Validate() ([]string, error) {
+	warnings := p.chainIDWarnings()
+	issues := p.genesisIssues()
+	issues = append(issues, p.validatePrecompileActivations(time.Now())...)
+	if len(issues) > 0 {
+		return warnings, &ValidationError{Issues: issues, Warnings: warnings}
+	}
+	return warnings, nil
+}
+
+// validateGenesis is Validate without the checks that depend on the current time,
+// used to create the genesis
+func (p *SubnetEVMParams) validateGenesis() ([]string, error) {
+	warnings := p.chainIDWarnings()
+	if issues := p.genesisIssues(); len(issues) > 0 {
+		return warnings, &ValidationError{Issues: issues, Warnings: warnings}
+	}
+	return warnings, nil
+}
+
+func (p *SubnetEVMParams) genesisIssues() []string {
+	issues := []string{}
+	issues = append(issues, p.validateChainID()...)
+	issues = append(issues, p.validateFeeConfig()...)
+	issues = append(issues, p.validateAllocation()...)
+	issues = append(issues, p.validateVestingAllocations()...)
+	if p.Precompiles == nil {
+		issues = append(issues, "precompiles are not provided")
+	}
+	return issues
+}
+
+func (p *SubnetEVMParams) validateChainID() []string {
+	if p.ChainID == nil {
+		return []string{"chain ID is not provided"}
+	}
+	if p.ChainID.Sign() <= 0 {
+		return []string{fmt.Sprintf("chain ID %s must be positive", p.ChainID)}
+	}
+	if p.ChainID.IsUint64() {
+		switch p.ChainID.Uint64() {
+		case avalanche.MainnetCChainEVMChainID:
+			return []string{fmt.Sprintf("chain ID %s collides with Mainnet C-Chain", p.ChainID)}
+		case avalanche.FujiCChainEVMChainID:
+			return []string{fmt.Sprintf("chain ID %s collides with Fuji C-Chain", p.ChainID)}
+		case LocalCChainEVMChainID:
+			return []string{fmt.Sprintf("chain ID %s collides with local network C-Chain", p.ChainID)}
+		}
+	}
+	return nil
+}
+
+// chainIDWarnings reports a valid chain ID that is used by a public network
+func (p *SubnetEVMParams) chainIDWarnings() []string {
+	if len(p.validateChainID()) > 0 {
+		return nil
+	}
+	if chain, ok := LookupPublicChain(p.ChainID); ok {
+		return []string{fmt.Sprintf("chain ID %s collides with public network %s (%s)", p.ChainID, chain.Name, chain.ShortName)}
+	}
+	return nil
+}
+
+func (p *SubnetEVMParams) validateFeeConfig() []string {
+	if p.FeeConfig == commontype.EmptyFeeConfig {
+		return []string{"fee config is not provided"}
+	}
+	issues := []string{}
+	if err := p.FeeConfig.Verify(); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid fee config: %s", err))
+	}
+	if p.FeeConfig.GasLimit != nil && p.FeeConfig.GasLimit.Cmp(big.NewInt(minGasLimit)) < 0 {
+		issues = append(issues, fmt.Sprintf("fee config gas limit %s is lower than the gas needed by a native transfer (%d)", p.FeeConfig.GasLimit, minGasLimit))
+	}
+	if p.FeeConfig.GasLimit != nil && p.FeeConfig.MaxBlockGasCost != nil && p.FeeConfig.MaxBlockGasCost.Cmp(p.FeeConfig.GasLimit) > 0 {
+		issues = append(issues, fmt.Sprintf("fee config max block gas cost %s is greater than gas limit %s", p.FeeConfig.MaxBlockGasCost, p.FeeConfig.GasLimit))
+	}
+	return issues
+}
+
+func (p *SubnetEVMParams) validateAllocation() []string {
+	if p.Allocation == nil {
+		return []string{"allocation is not provided"}
+	}
+	issues := []string{}
+	total := big.NewInt(0)
+	for addr, account := range p.Allocation {
+		if account.Balance == nil {
+			continue
+		}
+		if account.Balance.Sign() < 0 {
+			issues = append(issues, fmt.Sprintf("allocation for %s has negative balance %s", addr.Hex(), account.Balance))
+			continue
+		}
+		total.Add(total, account.Balance)
+	}
+	for _, vesting := range p.VestingAllocations {
+		if vesting.Amount != nil && vesting.Amount.Sign() > 0 {
+			total.Add(total, vesting.Amount)
+		}
+	}
+	if total.Cmp(maxUint256) > 0 {
+		issues = append(issues, fmt.Sprintf("total allocation %s overflows uint256", total))
+	}
+	sort.Strings(issues)
+	return issues
+}
+
+func (p *SubnetEVMParams) validatePrecompileActivations(now time.Time) []string {
+	issues := []string{}
+	for key, config := range p.Precompiles {
+		timestamp := config.Timestamp()
+		if timestamp != nil && *timestamp != 0 && *timestamp < uint64(now.Unix()) {
+			issues = append(issues, fmt.Sprintf("precompile %s activation timestamp %d is in the past", key, *timestamp))
+		}
+	}
+	sort.Strings(issues)
+	return issues
+}
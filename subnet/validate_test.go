@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/txallowlist"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSubnetEVMParamsValidate(t *testing.T) {
+	require := require.New(t)
+
+	params := getDefaultSubnetEVMGenesis().SubnetEVM
+	warnings, err := params.Validate()
+	require.NoError(err)
+	require.Empty(warnings)
+
+	params.ChainID = big.NewInt(avalanche.FujiCChainEVMChainID)
+	feeConfig := params.FeeConfig
+	feeConfig.GasLimit = big.NewInt(20_000)
+	feeConfig.MaxBlockGasCost = big.NewInt(30_000)
+	params.FeeConfig = feeConfig
+	half := new(big.Int).Lsh(big.NewInt(1), 255)
+	params.Allocation = core.GenesisAlloc{
+		common.HexToAddress("0x01"): {Balance: half},
+		common.HexToAddress("0x02"): {Balance: half},
+		common.HexToAddress("0x03"): {Balance: big.NewInt(-1)},
+	}
+	_, err = params.Validate()
+	require.Error(err)
+	var validationErr *ValidationError
+	require.ErrorAs(err, &validationErr)
+	require.Len(validationErr.Issues, 5)
+	require.Contains(err.Error(), "collides with Fuji C-Chain")
+	require.Contains(err.Error(), "lower than the gas needed by a native transfer")
+	require.Contains(err.Error(), "greater than gas limit")
+	require.Contains(err.Error(), "negative balance")
+	require.Contains(err.Error(), "overflows uint256")
+
+	empty := &SubnetEVMParams{}
+	_, err = empty.Validate()
+	require.ErrorAs(err, &validationErr)
+	require.Len(validationErr.Issues, 4)
+}
+
+func TestPrecompileActivationInThePast(t *testing.T) {
+	require := require.New(t)
+	params := getDefaultSubnetEVMGenesis().SubnetEVM
+	activation := uint64(1_600_000_000)
+	params.Precompiles[txallowlist.ConfigKey] = txallowlist.NewConfig(&activation, []common.Address{common.HexToAddress("0x01")}, nil, nil)
+
+	_, err := params.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(err, &validationErr)
+	require.Len(validationErr.Issues, 1)
+	require.Contains(err.Error(), "activation timestamp 1600000000 is in the past")
+	// an existing chain genesis can still be regenerated
+	_, _, err = createEvmGenesis(params)
+	require.NoError(err)
+}
+
+func TestValidationErrorJSON(t *testing.T) {
+	bytes, err := json.Marshal(&ValidationError{Issues: []string{"chain ID is not provided"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"schemaVersion": 1,
+		"params": "subnet-evm",
+		"valid": false,
+		"issues": ["chain ID is not provided"],
+		"warnings": []
+	}`, string(bytes))
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+const (
+	defaultValidatorsLivenessPollInterval = 5 * time.Second
+	// ValidatorsReportSchemaVersion is the version of the ValidatorsReport JSON output. It
+	// is increased on any incompatible change, so consumers can detect them
+	ValidatorsReportSchemaVersion = 1
+)
+
+// ValidatorStatus reports the liveness of a subnet validator, as seen by the
+// network API endpoint
+type ValidatorStatus struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	// the node is a peer of the API node
+	Connected bool `json:"connected"`
+	// the node informs it tracks the subnet
+	TrackingSubnet bool `json:"trackingSubnet"`
+	// the node is in the current validator set of the subnet
+	Validating bool `json:"validating"`
+}
+
+// Ready indicates if the validator is connected and validating the subnet
+func (s ValidatorStatus) Ready() bool {
+	return s.Connected && s.Validating
+}
+
+// ValidatorsReport is the liveness of the validators of a subnet, as returned by
+// GetValidatorsStatus or WaitForValidators, for JSON reporting
+type ValidatorsReport struct {
+	SubnetID   ids.ID
+	Validators []ValidatorStatus
+}
+
+type validatorStatusJSON struct {
+	ValidatorStatus
+	Ready bool `json:"ready"`
+}
+
+type validatorsReportJSON struct {
+	SchemaVersion int                   `json:"schemaVersion"`
+	SubnetID      ids.ID                `json:"subnetID"`
+	Ready         int                   `json:"ready"`
+	NotReady      int                   `json:"notReady"`
+	Validators    []validatorStatusJSON `json:"validators"`
+}
+
+// MarshalJSON serializes the report with a stable schema, versioned by
+// ValidatorsReportSchemaVersion, that includes the ready/not ready counts
+func (r ValidatorsReport) MarshalJSON() ([]byte, error) {
+	out := validatorsReportJSON{
+		SchemaVersion: ValidatorsReportSchemaVersion,
+		SubnetID:      r.SubnetID,
+		Validators:    []validatorStatusJSON{},
+	}
+	for _, status := range r.Validators {
+		if status.Ready() {
+			out.Ready++
+		} else {
+			out.NotReady++
+		}
+		out.Validators = append(out.Validators, validatorStatusJSON{
+			ValidatorStatus: status,
+			Ready:           status.Ready(),
+		})
+	}
+	return json.Marshal(out)
+}
+
+// GetValidatorsStatus gets the liveness status of [nodeIDs] as validators of [subnetID]
+func GetValidatorsStatus(
+	network avalanche.Network,
+	subnetID ids.ID,
+	nodeIDs []ids.NodeID,
+) ([]ValidatorStatus, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	peers, err := info.NewClient(network.Endpoint).Peers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting peers from %s: %w", network.Endpoint, err)
+	}
+	validators, err := platformvm.NewClient(network.Endpoint).GetCurrentValidators(ctx, subnetID, nodeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failure getting current validators for subnet %s: %w", subnetID, err)
+	}
+	return validatorsStatus(subnetID, nodeIDs, peers, validators), nil
+}
+
+func validatorsStatus(
+	subnetID ids.ID,
+	nodeIDs []ids.NodeID,
+	peers []info.Peer,
+	validators []platformvm.ClientPermissionlessValidator,
+) []ValidatorStatus {
+	statuses := map[ids.NodeID]*ValidatorStatus{}
+	for _, nodeID := range nodeIDs {
+		statuses[nodeID] = &ValidatorStatus{NodeID: nodeID}
+	}
+	for _, peer := range peers {
+		if status, ok := statuses[peer.ID]; ok {
+			status.Connected = true
+			status.TrackingSubnet = subnetID == ids.Empty || peer.TrackedSubnets.Contains(subnetID)
+		}
+	}
+	for _, validator := range validators {
+		if status, ok := statuses[validator.NodeID]; ok {
+			status.Validating = true
+			if validator.Connected != nil && *validator.Connected {
+				status.Connected = true
+			}
+		}
+	}
+	return utils.Map(nodeIDs, func(nodeID ids.NodeID) ValidatorStatus { return *statuses[nodeID] })
+}
+
+// WaitForValidators waits until at least [quorum] of [nodeIDs] are connected and
+// validating [subnetID], or [timeout] is reached. If [quorum] is 0, all of
+// them are waited for. On timeout, the error lists the validators that are
+// not ready
+func WaitForValidators(
+	network avalanche.Network,
+	subnetID ids.ID,
+	nodeIDs []ids.NodeID,
+	quorum int,
+	timeout time.Duration,
+) ([]ValidatorStatus, error) {
+	if quorum <= 0 || quorum > len(nodeIDs) {
+		quorum = len(nodeIDs)
+	}
+	startTime := time.Now()
+	for {
+		statuses, err := GetValidatorsStatus(network, subnetID, nodeIDs)
+		if err != nil {
+			return nil, err
+		}
+		notReady := utils.Filter(statuses, func(s ValidatorStatus) bool { return !s.Ready() })
+		if len(statuses)-len(notReady) >= quorum {
+			return statuses, nil
+		}
+		if time.Since(startTime) > timeout {
+			return statuses, fmt.Errorf(
+				"timeout waiting for %d validators of subnet %s to be ready. not ready: %v",
+				quorum,
+				subnetID,
+				utils.Map(notReady, func(s ValidatorStatus) ids.NodeID { return s.NodeID }),
+			)
+		}
+		time.Sleep(defaultValidatorsLivenessPollInterval)
+	}
+}
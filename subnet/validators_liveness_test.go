@@ -0,0 +1,68 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorsStatus(t *testing.T) {
+	subnetID := ids.GenerateTestID()
+	nodeA := ids.GenerateTestNodeID()
+	nodeB := ids.GenerateTestNodeID()
+	nodeC := ids.GenerateTestNodeID()
+	connected := true
+	peers := []info.Peer{
+		{Info: peer.Info{ID: nodeA, TrackedSubnets: set.Of(subnetID)}},
+		{Info: peer.Info{ID: ids.GenerateTestNodeID()}},
+	}
+	validators := []platformvm.ClientPermissionlessValidator{
+		{ClientStaker: platformvm.ClientStaker{NodeID: nodeA}},
+		{ClientStaker: platformvm.ClientStaker{NodeID: nodeB}, Connected: &connected},
+	}
+	statuses := validatorsStatus(subnetID, []ids.NodeID{nodeA, nodeB, nodeC}, peers, validators)
+	require.Equal(t, []ValidatorStatus{
+		{NodeID: nodeA, Connected: true, TrackingSubnet: true, Validating: true},
+		{NodeID: nodeB, Connected: true, Validating: true},
+		{NodeID: nodeC},
+	}, statuses)
+	require.True(t, statuses[0].Ready())
+	require.True(t, statuses[1].Ready())
+	require.False(t, statuses[2].Ready())
+}
+
+func TestValidatorsReportJSON(t *testing.T) {
+	require := require.New(t)
+	subnetID := ids.GenerateTestID()
+	nodeA := ids.GenerateTestNodeID()
+	nodeB := ids.GenerateTestNodeID()
+	report := ValidatorsReport{
+		SubnetID: subnetID,
+		Validators: []ValidatorStatus{
+			{NodeID: nodeA, Connected: true, TrackingSubnet: true, Validating: true},
+			{NodeID: nodeB, Connected: true},
+		},
+	}
+	bytes, err := json.Marshal(report)
+	require.NoError(err)
+	require.JSONEq(fmt.Sprintf(`{
+		"schemaVersion": 1,
+		"subnetID": %q,
+		"ready": 1,
+		"notReady": 1,
+		"validators": [
+			{"nodeID": %q, "connected": true, "trackingSubnet": true, "validating": true, "ready": true},
+			{"nodeID": %q, "connected": true, "trackingSubnet": false, "validating": false, "ready": false}
+		]
+	}`, subnetID, nodeA, nodeB), string(bytes))
+}
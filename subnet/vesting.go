@@ -0,0 +1,149 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Storage layout of the vesting contract
+var (
+	vestingBeneficiarySlot = common.BigToHash(big.NewInt(0))
+	vestingStartSlot       = common.BigToHash(big.NewInt(1))
+	vestingCliffSlot       = common.BigToHash(big.NewInt(2))
+	vestingDurationSlot    = common.BigToHash(big.NewInt(3))
+	vestingReleasedSlot    = common.BigToHash(big.NewInt(4))
+)
+
+// VestingContractCode is the runtime code of the linear vesting contract used for genesis
+// vesting allocations. As it is set at genesis, it has no constructor, and its params are
+// kept in storage: beneficiary (slot 0), start (slot 1), cliff end (slot 2) and duration
+// (slot 3), all timestamps in seconds, and the amount released so far (slot 4).
+//
+// The contract holds the vested tokens as its balance, and accepts further plain
+// transfers. It exposes the same functions as OpenZeppelin's VestingWallet for the native
+// token:
+//   - releasable() returns the amount vested and not yet released: nothing before the cliff
+//     end, and then (balance + released) * (now - start) / duration, capped to the total
+//   - release() sends the releasable amount to the beneficiary. Anyone can call it
+//
+// Assembly:
+//
+//	00 CALLDATASIZE PUSH1 05 JUMPI STOP
+//	05 JUMPDEST PUSH1 00 CALLDATALOAD PUSH1 e0 SHR
+//	   DUP1 PUSH4 86d1a69f EQ PUSH1 24 JUMPI             ; release()
+//	   DUP1 PUSH4 fbccedae EQ PUSH1 47 JUMPI             ; releasable()
+//	   PUSH1 00 DUP1 REVERT
+//	24 JUMPDEST PUSH1 2a PUSH1 56 JUMP                   ; release: amount = releasable
+//	2a JUMPDEST DUP1 PUSH1 04 SLOAD ADD PUSH1 04 SSTORE  ; released += amount
+//	   PUSH1 00 DUP1 DUP1 DUP1 DUP5 PUSH1 00 SLOAD GAS CALL
+//	   PUSH1 45 JUMPI PUSH1 00 DUP1 REVERT
+//	45 JUMPDEST STOP
+//	47 JUMPDEST PUSH1 4d PUSH1 56 JUMP                   ; releasable
+//	4d JUMPDEST PUSH1 00 MSTORE PUSH1 20 PUSH1 00 RETURN
+//	56 JUMPDEST PUSH1 04 SLOAD DUP1 SELFBALANCE ADD      ; total = balance + released
+//	   PUSH1 02 SLOAD TIMESTAMP LT PUSH1 7f JUMPI        ; before cliff end
+//	   PUSH1 01 SLOAD TIMESTAMP SUB                      ; elapsed
+//	   PUSH1 03 SLOAD DUP1 DUP3 LT PUSH1 78 JUMPI        ; elapsed < duration
+//	   POP POP PUSH1 83 JUMP                             ; vested = total
+//	78 JUMPDEST SWAP2 MUL DIV PUSH1 83 JUMP              ; vested = total * elapsed / duration
+//	7f JUMPDEST POP PUSH1 00                             ; vested = 0
+//	83 JUMPDEST SUB SWAP1 JUMP                           ; return vested - released
+var VestingContractCode = common.FromHex(
+	"0x36600557005b60003560e01c806386d1a69f146024578063fbccedae14604757600080fd5b602a6056565b80600454016004556000808080846000545af1604557600080fd5b005b604d6056565b60005260206000f35b6004548047016002544210607f57600154420360035480821060785750506083565b9102046083565b5060005b039056",
+)
+
+// VestingAllocation is a genesis allocation locked in a vesting contract, that releases
+// [Amount] to [Beneficiary] linearly from [Start] over [Duration], with nothing released
+// before [Start] + [Cliff]
+type VestingAllocation struct {
+	// Address where the vesting contract is placed at genesis
+	Address     common.Address
+	Beneficiary common.Address
+	Amount      *big.Int
+	Start       time.Time
+	Cliff       time.Duration
+	Duration    time.Duration
+}
+
+// CliffEnd is the time at which the first tokens can be released
+func (a VestingAllocation) CliffEnd() time.Time {
+	return a.Start.Add(a.Cliff)
+}
+
+// End is the time at which all the tokens are vested
+func (a VestingAllocation) End() time.Time {
+	return a.Start.Add(a.Duration)
+}
+
+// GenesisAccount is the genesis allocation that deploys the vesting contract of [a],
+// funded with [a.Amount]
+func (a VestingAllocation) GenesisAccount() core.GenesisAccount {
+	return core.GenesisAccount{
+		Code: VestingContractCode,
+		Storage: map[common.Hash]common.Hash{
+			vestingBeneficiarySlot: common.BytesToHash(a.Beneficiary.Bytes()),
+			vestingStartSlot:       common.BigToHash(big.NewInt(a.Start.Unix())),
+			vestingCliffSlot:       common.BigToHash(big.NewInt(a.CliffEnd().Unix())),
+			vestingDurationSlot:    common.BigToHash(big.NewInt(int64(a.Duration / time.Second))),
+		},
+		Balance: new(big.Int).Set(a.Amount),
+	}
+}
+
+func (p *SubnetEVMParams) validateVestingAllocations() []string {
+	issues := []string{}
+	seen := map[common.Address]bool{}
+	for _, allocation := range p.VestingAllocations {
+		addr := allocation.Address.Hex()
+		switch {
+		case allocation.Address == (common.Address{}):
+			issues = append(issues, "vesting allocation has no contract address")
+		case seen[allocation.Address]:
+			issues = append(issues, fmt.Sprintf("vesting allocation address %s is used more than once", addr))
+		default:
+			if _, ok := p.Allocation[allocation.Address]; ok {
+				issues = append(issues, fmt.Sprintf("vesting allocation address %s is also in the allocation", addr))
+			}
+		}
+		seen[allocation.Address] = true
+		if allocation.Beneficiary == (common.Address{}) {
+			issues = append(issues, fmt.Sprintf("vesting allocation %s has no beneficiary", addr))
+		}
+		if allocation.Amount == nil || allocation.Amount.Sign() <= 0 {
+			issues = append(issues, fmt.Sprintf("vesting allocation %s amount must be positive", addr))
+		}
+		if allocation.Start.Unix() < 0 {
+			issues = append(issues, fmt.Sprintf("vesting allocation %s has no start time", addr))
+		}
+		if allocation.Duration < 0 || allocation.Cliff < 0 {
+			issues = append(issues, fmt.Sprintf("vesting allocation %s has negative duration or cliff", addr))
+		} else if allocation.Cliff > allocation.Duration {
+			issues = append(issues, fmt.Sprintf("vesting allocation %s cliff %s is longer than its duration %s", addr, allocation.Cliff, allocation.Duration))
+		}
+	}
+	sort.Strings(issues)
+	return issues
+}
+
+// genesisAllocation returns the allocation plus the vesting contracts
+func (p *SubnetEVMParams) genesisAllocation() core.GenesisAlloc {
+	if len(p.VestingAllocations) == 0 {
+		return p.Allocation
+	}
+	allocation := make(core.GenesisAlloc, len(p.Allocation)+len(p.VestingAllocations))
+	for addr, account := range p.Allocation {
+		allocation[addr] = account
+	}
+	for _, vesting := range p.VestingAllocations {
+		allocation[vesting.Address] = vesting.GenesisAccount()
+	}
+	return allocation
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnet
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVestingContract(t *testing.T) {
+	require := require.New(t)
+	start := time.Unix(1_700_000_000, 0)
+	allocation := VestingAllocation{
+		Address:     common.HexToAddress("0x0100000000000000000000000000000000000001"),
+		Beneficiary: common.HexToAddress("0xbe0eb53f46cd790cd13851d5eff43d12404d33e8"),
+		Amount:      big.NewInt(1_000_000),
+		Start:       start,
+		Cliff:       100 * time.Second,
+		Duration:    1000 * time.Second,
+	}
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	account := allocation.GenesisAccount()
+	statedb.SetCode(allocation.Address, account.Code)
+	statedb.SetBalance(allocation.Address, account.Balance)
+	for key, value := range account.Storage {
+		statedb.SetState(allocation.Address, key, value)
+	}
+
+	call := func(method string, at time.Time) []byte {
+		out, _, err := runtime.Call(allocation.Address, crypto.Keccak256([]byte(method))[:4], &runtime.Config{
+			State: statedb,
+			Time:  uint64(at.Unix()),
+		})
+		require.NoError(err)
+		return out
+	}
+	releasable := func(at time.Time) int64 {
+		return new(big.Int).SetBytes(call("releasable()", at)).Int64()
+	}
+
+	require.Zero(releasable(start.Add(99 * time.Second)))
+	require.Equal(int64(100_000), releasable(allocation.CliffEnd()))
+	require.Equal(int64(500_000), releasable(start.Add(500*time.Second)))
+
+	call("release()", start.Add(500*time.Second))
+	require.Equal(int64(500_000), statedb.GetBalance(allocation.Beneficiary).Int64())
+	require.Equal(int64(500_000), statedb.GetBalance(allocation.Address).Int64())
+	require.Zero(releasable(start.Add(500 * time.Second)))
+	require.Equal(int64(250_000), releasable(start.Add(750*time.Second)))
+	require.Equal(int64(500_000), releasable(allocation.End().Add(time.Hour)))
+
+	call("release()", allocation.End())
+	require.Equal(int64(1_000_000), statedb.GetBalance(allocation.Beneficiary).Int64())
+	require.Zero(statedb.GetBalance(allocation.Address).Int64())
+
+	_, _, err = runtime.Call(allocation.Address, []byte{1, 2, 3, 4}, &runtime.Config{State: statedb})
+	require.Error(err)
+}
+
+func TestVestingAllocationsGenesis(t *testing.T) {
+	require := require.New(t)
+	params := getDefaultSubnetEVMGenesis().SubnetEVM
+	vesting := VestingAllocation{
+		Address:     common.HexToAddress("0x0100000000000000000000000000000000000001"),
+		Beneficiary: common.HexToAddress("0x0200000000000000000000000000000000000002"),
+		Amount:      big.NewInt(1_000),
+		Start:       time.Unix(1_700_000_000, 0),
+		Duration:    time.Hour,
+	}
+	params.VestingAllocations = []VestingAllocation{vesting}
+	genesisBytes, _, err := createEvmGenesis(params)
+	require.NoError(err)
+	genesis := core.Genesis{}
+	require.NoError(json.Unmarshal(genesisBytes, &genesis))
+	require.Equal(VestingContractCode, genesis.Alloc[vesting.Address].Code)
+	require.Equal(big.NewInt(1_000), genesis.Alloc[vesting.Address].Balance)
+	require.Equal(common.BigToHash(big.NewInt(3600)), genesis.Alloc[vesting.Address].Storage[vestingDurationSlot])
+	require.NotContains(params.Allocation, vesting.Address)
+
+	existing := common.HexToAddress("0x0300000000000000000000000000000000000003")
+	params.Allocation = core.GenesisAlloc{existing: {Balance: big.NewInt(1)}}
+	params.VestingAllocations = []VestingAllocation{
+		vesting,
+		vesting,
+		{Address: existing, Amount: big.NewInt(0), Cliff: 2 * time.Hour, Duration: time.Hour},
+	}
+	_, err = params.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(err, &validationErr)
+	require.Len(validationErr.Issues, 6)
+	require.Contains(err.Error(), "is used more than once")
+	require.Contains(err.Error(), "is also in the allocation")
+	require.Contains(err.Error(), "has no beneficiary")
+	require.Contains(err.Error(), "amount must be positive")
+	require.Contains(err.Error(), "has no start time")
+	require.Contains(err.Error(), "is longer than its duration")
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package telemetry
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type prometheusRecorder struct {
+	txBuilt        *prometheus.CounterVec
+	txIssued       *prometheus.CounterVec
+	txIssuanceTime *prometheus.HistogramVec
+	failures       *prometheus.CounterVec
+}
+
+// NewPrometheusRecorder creates a Recorder that exposes SDK usage metrics under
+// [namespace], registering them into [registerer]
+func NewPrometheusRecorder(namespace string, registerer prometheus.Registerer) (Recorder, error) {
+	r := &prometheusRecorder{
+		txBuilt: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tx_built",
+			Help:      "Number of txs built by the SDK",
+		}, []string{"tx_kind"}),
+		txIssued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tx_issued",
+			Help:      "Number of txs issued by the SDK",
+		}, []string{"tx_kind"}),
+		txIssuanceTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tx_issuance_seconds",
+			Help:      "Time taken to issue txs, in seconds",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"tx_kind"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "failures",
+			Help:      "Number of failed SDK operations",
+		}, []string{"operation", "error_type"}),
+	}
+	for _, collector := range []prometheus.Collector{
+		r.txBuilt,
+		r.txIssued,
+		r.txIssuanceTime,
+		r.failures,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *prometheusRecorder) TxBuilt(txKind string) {
+	r.txBuilt.WithLabelValues(txKind).Inc()
+}
+
+func (r *prometheusRecorder) TxIssued(txKind string, duration time.Duration) {
+	r.txIssued.WithLabelValues(txKind).Inc()
+	r.txIssuanceTime.WithLabelValues(txKind).Observe(duration.Seconds())
+}
+
+func (r *prometheusRecorder) Failure(operation string, err error) {
+	r.failures.WithLabelValues(operation, ErrorType(err)).Inc()
+}
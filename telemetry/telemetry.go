@@ -0,0 +1,85 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package telemetry provides opt-in usage metrics for the SDK. By default
+// events are discarded. Consumers can set a Recorder, eg the one returned
+// by NewPrometheusRecorder, to monitor SDK behavior
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Recorder receives SDK usage events. Implementations must be safe for concurrent use
+type Recorder interface {
+	// TxBuilt is called after a tx of [txKind] is built and signed by the SDK
+	TxBuilt(txKind string)
+	// TxIssued is called after a tx of [txKind] is issued to the network,
+	// with the time taken to issue it
+	TxIssued(txKind string, duration time.Duration)
+	// Failure is called when [operation] fails with [err]
+	Failure(operation string, err error)
+}
+
+type noopRecorder struct{}
+
+func (noopRecorder) TxBuilt(string)                 {}
+func (noopRecorder) TxIssued(string, time.Duration) {}
+func (noopRecorder) Failure(string, error)          {}
+
+var current = struct {
+	lock     sync.RWMutex
+	recorder Recorder
+}{
+	recorder: noopRecorder{},
+}
+
+// SetRecorder sets the recorder that receives SDK usage events.
+// A nil [recorder] disables telemetry
+func SetRecorder(recorder Recorder) {
+	if recorder == nil {
+		recorder = noopRecorder{}
+	}
+	current.lock.Lock()
+	defer current.lock.Unlock()
+	current.recorder = recorder
+}
+
+func getRecorder() Recorder {
+	current.lock.RLock()
+	defer current.lock.RUnlock()
+	return current.recorder
+}
+
+// TxBuilt records that a tx of [txKind] was built
+func TxBuilt(txKind string) {
+	getRecorder().TxBuilt(txKind)
+}
+
+// TxIssued records that a tx of [txKind] was issued, taking [duration]
+func TxIssued(txKind string, duration time.Duration) {
+	getRecorder().TxIssued(txKind, duration)
+}
+
+// Failure records that [operation] failed with [err]. Nil errors are ignored
+func Failure(operation string, err error) {
+	if err == nil {
+		return
+	}
+	getRecorder().Failure(operation, err)
+}
+
+// ErrorType returns the type name of [err], skipping fmt.Errorf wrappers,
+// to be used to classify failures
+func ErrorType(err error) string {
+	for {
+		errType := fmt.Sprintf("%T", err)
+		if errType != "*fmt.wrapError" {
+			return errType
+		}
+		err = errors.Unwrap(err)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusRecorder(t *testing.T) {
+	require := require.New(t)
+	registry := prometheus.NewRegistry()
+	recorder, err := NewPrometheusRecorder("sdk", registry)
+	require.NoError(err)
+	SetRecorder(recorder)
+	defer SetRecorder(nil)
+
+	TxBuilt("CreateSubnetTx")
+	TxBuilt("CreateSubnetTx")
+	TxIssued("CreateSubnetTx", time.Second)
+	Failure("commit", fmt.Errorf("issue failed: %w", fs.ErrNotExist))
+	Failure("commit", nil)
+
+	r := recorder.(*prometheusRecorder)
+	require.InDelta(2, testutil.ToFloat64(r.txBuilt.WithLabelValues("CreateSubnetTx")), 0)
+	require.InDelta(1, testutil.ToFloat64(r.txIssued.WithLabelValues("CreateSubnetTx")), 0)
+	require.Equal(1, testutil.CollectAndCount(r.failures))
+
+	_, err = NewPrometheusRecorder("sdk", registry)
+	require.Error(err)
+}
+
+func TestErrorType(t *testing.T) {
+	require.Equal(t, "*errors.errorString", ErrorType(errors.New("err")))
+	require.Equal(t, "*fs.PathError", ErrorType(fmt.Errorf("wrapped: %w", &fs.PathError{Err: fs.ErrNotExist})))
+}
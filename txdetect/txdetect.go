@@ -0,0 +1,460 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package txdetect detects the chain, network and type of serialized Avalanche
+// and EVM txs, as exchanged between tools and multisig participants. Its API is
+// stable: new chains and parsers are added without changing existing results
+package txdetect
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/vms/avm/fxs"
+	"github.com/ava-labs/avalanchego/vms/nftfx"
+	"github.com/ava-labs/avalanchego/vms/propertyfx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	avmtxs "github.com/ava-labs/avalanchego/vms/avm/txs"
+	platformvmtxs "github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+const (
+	PChain = "P"
+	XChain = "X"
+	CChain = "C"
+	// EVM txs and blocks not issued on the primary network (eg subnet-evm L1s). The
+	// ones with a primary network C-Chain EVM chain ID are detected as CChain, see
+	// RegisterCChainEVMChainID
+	EVMChain = "L1-EVM"
+)
+
+// cChainEVMChainIDs are the EVM chain IDs of primary network C-Chains, set with
+// RegisterCChainEVMChainID
+var cChainEVMChainIDs = struct {
+	lock     sync.RWMutex
+	chainIDs map[uint64]struct{}
+}{
+	chainIDs: map[uint64]struct{}{},
+}
+
+var (
+	ErrUnknownChain = errors.New("could not detect tx chain")
+	ErrNoNetworkID  = errors.New("tx has no network ID")
+)
+
+// TxParser parses tx bytes belonging to [Chain]. [Name] identifies the parser on
+// detection errors. Parse returns the parsed tx and the codec version it was
+// encoded with, if the encoding has one
+type TxParser struct {
+	Chain string
+	Name  string
+	Parse func(txBytes []byte) (tx interface{}, codecVersion uint16, err error)
+}
+
+// Detection is the result of Detect
+type Detection struct {
+	// Chain the tx belongs to, eg PChain
+	Chain string
+	// Parser is the name of the parser that accepted the tx
+	Parser string
+	// TxType is the name of the tx type, eg CreateSubnetTx, or DynamicFeeTx for EVM txs
+	TxType string
+	// CodecVersion the tx was encoded with. Zero for EVM txs and blocks
+	CodecVersion uint16
+	// NetworkID of the tx. Zero if the tx type has none, eg EVM txs
+	NetworkID uint32
+	// EVMChainID of EVM txs and blocks. For blocks, it is taken from the first tx,
+	// so it is nil for empty blocks
+	EVMChainID *big.Int
+	// Tx is the parsed tx. For signed txs, it is the unsigned tx
+	Tx interface{}
+	// Signed is set when the tx was parsed from signed bytes by DetectSigned
+	Signed bool
+	// Credentials is the number of credentials of a signed tx
+	Credentials int
+	// UnsignedTxBytes are the bytes of a signed tx with its credentials stripped
+	UnsignedTxBytes []byte
+}
+
+// signedTx is a signed P-Chain, X-Chain or C-Chain atomic tx, split into its parts
+type signedTx struct {
+	unsignedTx    interface{}
+	unsignedBytes []byte
+	credentials   int
+}
+
+// signedTxParser parses signed tx bytes belonging to [chain]
+type signedTxParser struct {
+	chain string
+	name  string
+	parse func(signedTxBytes []byte) (signedTx, error)
+}
+
+var txParsers = struct {
+	lock    sync.RWMutex
+	parsers []TxParser
+	xFxs    []fxs.Fx
+}{
+	xFxs: []fxs.Fx{
+		&secp256k1fx.Fx{},
+		&nftfx.Fx{},
+		&propertyfx.Fx{},
+	},
+}
+
+// RegisterTxParser adds [parser] to the ones used by Detect. Custom parsers are
+// tried after the default P/X/C/EVM ones, in registration order
+func RegisterTxParser(parser TxParser) {
+	txParsers.lock.Lock()
+	defer txParsers.lock.Unlock()
+	txParsers.parsers = append(txParsers.parsers, parser)
+}
+
+// RegisterXChainFxs adds [fxs] to the ones known by the X-Chain parser, so txs
+// using them can be detected and parsed
+func RegisterXChainFxs(fxs ...fxs.Fx) {
+	txParsers.lock.Lock()
+	defer txParsers.lock.Unlock()
+	txParsers.xFxs = append(txParsers.xFxs, fxs...)
+}
+
+func getTxParsers() ([]TxParser, error) {
+	txParsers.lock.RLock()
+	defer txParsers.lock.RUnlock()
+	xParser, err := avmtxs.NewParser(txParsers.xFxs)
+	if err != nil {
+		return nil, fmt.Errorf("failure creating X-Chain parser: %w", err)
+	}
+	parsers := []TxParser{
+		{
+			Chain: PChain,
+			Name:  "platformvm",
+			Parse: func(unsignedTxBytes []byte) (interface{}, uint16, error) {
+				var utx platformvmtxs.UnsignedTx
+				version, err := platformvmtxs.Codec.Unmarshal(unsignedTxBytes, &utx)
+				return utx, version, err
+			},
+		},
+		{
+			Chain: XChain,
+			Name:  "avm",
+			Parse: func(unsignedTxBytes []byte) (interface{}, uint16, error) {
+				var utx avmtxs.UnsignedTx
+				version, err := xParser.Codec().Unmarshal(unsignedTxBytes, &utx)
+				return utx, version, err
+			},
+		},
+		{
+			Chain: CChain,
+			Name:  "coreth atomic",
+			Parse: func(unsignedTxBytes []byte) (interface{}, uint16, error) {
+				var utx evm.UnsignedAtomicTx
+				version, err := evm.Codec.Unmarshal(unsignedTxBytes, &utx)
+				return utx, version, err
+			},
+		},
+		{
+			Chain: EVMChain,
+			Name:  "evm tx",
+			Parse: func(txBytes []byte) (interface{}, uint16, error) {
+				tx := &types.Transaction{}
+				return tx, 0, tx.UnmarshalBinary(txBytes)
+			},
+		},
+		{
+			Chain: EVMChain,
+			Name:  "evm block",
+			Parse: func(blockBytes []byte) (interface{}, uint16, error) {
+				block := &types.Block{}
+				return block, 0, rlp.DecodeBytes(blockBytes, block)
+			},
+		},
+	}
+	return append(parsers, txParsers.parsers...), nil
+}
+
+func getSignedTxParsers() ([]signedTxParser, error) {
+	txParsers.lock.RLock()
+	defer txParsers.lock.RUnlock()
+	xParser, err := avmtxs.NewParser(txParsers.xFxs)
+	if err != nil {
+		return nil, fmt.Errorf("failure creating X-Chain parser: %w", err)
+	}
+	return []signedTxParser{
+		{
+			chain: PChain,
+			name:  "platformvm signed",
+			parse: func(signedTxBytes []byte) (signedTx, error) {
+				tx, err := platformvmtxs.Parse(platformvmtxs.Codec, signedTxBytes)
+				if err != nil {
+					return signedTx{}, err
+				}
+				return signedTx{tx.Unsigned, tx.Unsigned.Bytes(), len(tx.Creds)}, nil
+			},
+		},
+		{
+			chain: XChain,
+			name:  "avm signed",
+			parse: func(signedTxBytes []byte) (signedTx, error) {
+				tx, err := xParser.ParseTx(signedTxBytes)
+				if err != nil {
+					return signedTx{}, err
+				}
+				return signedTx{tx.Unsigned, tx.Unsigned.Bytes(), len(tx.Creds)}, nil
+			},
+		},
+		{
+			chain: CChain,
+			name:  "coreth atomic signed",
+			parse: func(signedTxBytes []byte) (signedTx, error) {
+				tx, err := evm.ExtractAtomicTx(signedTxBytes, evm.Codec)
+				if err != nil {
+					return signedTx{}, err
+				}
+				return signedTx{tx.UnsignedAtomicTx, tx.UnsignedAtomicTx.Bytes(), len(tx.Creds)}, nil
+			},
+		},
+	}, nil
+}
+
+// Detect parses [txBytes] with all the known parsers, and describes the first
+// successful parse. On failure, the error wraps ErrUnknownChain and lists the
+// parsers that were tried. Malformed input never panics
+func Detect(txBytes []byte) (Detection, error) {
+	parsers, err := getTxParsers()
+	if err != nil {
+		return Detection{}, err
+	}
+	parseErrs := []string{}
+	for _, parser := range parsers {
+		tx, codecVersion, err := safeParse(parser, txBytes)
+		if err != nil {
+			parseErrs = append(parseErrs, fmt.Sprintf("%s: %s", parser.Name, err))
+			continue
+		}
+		detection := Detection{
+			Chain:        parser.Chain,
+			Parser:       parser.Name,
+			TxType:       typeName(tx),
+			CodecVersion: codecVersion,
+			NetworkID:    networkID(tx),
+			Tx:           tx,
+		}
+		switch tx := tx.(type) {
+		case *types.Transaction:
+			detection.TxType = evmTxTypeName(tx.Type())
+			detection.EVMChainID = tx.ChainId()
+		case *types.Block:
+			detection.TxType = "Block"
+			if txs := tx.Transactions(); len(txs) > 0 {
+				detection.EVMChainID = txs[0].ChainId()
+			}
+		}
+		if detection.Chain == EVMChain && isCChainEVMChainID(detection.EVMChainID) {
+			detection.Chain = CChain
+		}
+		return detection, nil
+	}
+	return Detection{}, fmt.Errorf("%w. tried [%s]", ErrUnknownChain, strings.Join(parseErrs, "; "))
+}
+
+// DetectSigned works as Detect, but for signed or partially signed P-Chain, X-Chain
+// and C-Chain atomic txs, as exchanged between multisig participants. The detection
+// describes the unsigned tx, whose bytes, with the credentials stripped, are set in
+// UnsignedTxBytes. EVM txs and blocks are also detected, as they are always signed
+func DetectSigned(signedTxBytes []byte) (Detection, error) {
+	parsers, err := getSignedTxParsers()
+	if err != nil {
+		return Detection{}, err
+	}
+	parseErrs := []string{}
+	for _, parser := range parsers {
+		tx, err := safeParseSigned(parser, signedTxBytes)
+		if err != nil {
+			parseErrs = append(parseErrs, fmt.Sprintf("%s: %s", parser.name, err))
+			continue
+		}
+		return Detection{
+			Chain:           parser.chain,
+			Parser:          parser.name,
+			TxType:          typeName(tx.unsignedTx),
+			CodecVersion:    binary.BigEndian.Uint16(signedTxBytes),
+			NetworkID:       networkID(tx.unsignedTx),
+			Tx:              tx.unsignedTx,
+			Signed:          true,
+			Credentials:     tx.credentials,
+			UnsignedTxBytes: tx.unsignedBytes,
+		}, nil
+	}
+	detection, err := Detect(signedTxBytes)
+	if err != nil {
+		return Detection{}, fmt.Errorf("%w; %s", err, strings.Join(parseErrs, "; "))
+	}
+	if !isEVM(detection.Tx) {
+		// unsigned avalanche txs are not accepted as signed ones
+		return Detection{}, fmt.Errorf("%w: %s %s is not signed", ErrUnknownChain, detection.Chain, detection.TxType)
+	}
+	return detection, nil
+}
+
+// RegisterCChainEVMChainID makes EVM txs and blocks with [chainID] to be detected
+// as CChain. Package avalanche registers the C-Chain EVM chain ID of each network
+// it knows of, including the ones added with avalanche.RegisterNetworkInfo
+func RegisterCChainEVMChainID(chainID uint64) {
+	cChainEVMChainIDs.lock.Lock()
+	defer cChainEVMChainIDs.lock.Unlock()
+	cChainEVMChainIDs.chainIDs[chainID] = struct{}{}
+}
+
+// isCChainEVMChainID checks if [chainID] is the EVM chain ID of a primary network C-Chain
+func isCChainEVMChainID(chainID *big.Int) bool {
+	if chainID == nil || !chainID.IsUint64() {
+		return false
+	}
+	cChainEVMChainIDs.lock.RLock()
+	defer cChainEVMChainIDs.lock.RUnlock()
+	_, ok := cChainEVMChainIDs.chainIDs[chainID.Uint64()]
+	return ok
+}
+
+// isEVM checks if [tx] is an EVM tx or block
+func isEVM(tx interface{}) bool {
+	switch tx.(type) {
+	case *types.Transaction, *types.Block:
+		return true
+	default:
+		return false
+	}
+}
+
+// AutoDetectChain returns the chain [unsignedTxBytes] belongs to, by trying to
+// parse it with all the known parsers. On failure, the error lists the
+// parsers that were tried
+func AutoDetectChain(unsignedTxBytes []byte) (string, error) {
+	detection, err := Detect(unsignedTxBytes)
+	return detection.Chain, err
+}
+
+// AutoDetectChainID works as AutoDetectChain, but it also returns the EVM chain ID
+// when [txBytes] is detected to be an EVM tx or block. For blocks, the chain ID is
+// taken from the first tx, so it is nil for empty blocks
+func AutoDetectChainID(txBytes []byte) (string, *big.Int, error) {
+	detection, err := Detect(txBytes)
+	return detection.Chain, detection.EVMChainID, err
+}
+
+// GetNetworkID returns the network ID of the P-Chain, X-Chain or C-Chain atomic
+// tx [unsignedTxBytes]. It returns ErrNoNetworkID for txs without one, eg EVM txs
+func GetNetworkID(unsignedTxBytes []byte) (uint32, error) {
+	detection, err := Detect(unsignedTxBytes)
+	if err != nil {
+		return 0, err
+	}
+	if detection.NetworkID == 0 {
+		return 0, fmt.Errorf("%w: %s %s", ErrNoNetworkID, detection.Chain, detection.TxType)
+	}
+	return detection.NetworkID, nil
+}
+
+// GetNetworkIDFromSignedTx works as GetNetworkID, but for signed or partially signed txs
+func GetNetworkIDFromSignedTx(signedTxBytes []byte) (uint32, error) {
+	detection, err := DetectSigned(signedTxBytes)
+	if err != nil {
+		return 0, err
+	}
+	if detection.NetworkID == 0 {
+		return 0, fmt.Errorf("%w: %s %s", ErrNoNetworkID, detection.Chain, detection.TxType)
+	}
+	return detection.NetworkID, nil
+}
+
+// StripCredentials returns the unsigned tx bytes of the signed P-Chain, X-Chain or
+// C-Chain atomic tx [signedTxBytes]
+func StripCredentials(signedTxBytes []byte) ([]byte, error) {
+	detection, err := DetectSigned(signedTxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !detection.Signed {
+		return nil, fmt.Errorf("%s %s has no credentials to strip", detection.Chain, detection.TxType)
+	}
+	return detection.UnsignedTxBytes, nil
+}
+
+// safeParseSigned runs [parser] on [signedTxBytes], turning panics on malformed
+// input into errors
+func safeParseSigned(parser signedTxParser, signedTxBytes []byte) (tx signedTx, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tx, err = signedTx{}, fmt.Errorf("panic parsing tx: %v", r)
+		}
+	}()
+	return parser.parse(signedTxBytes)
+}
+
+// safeParse runs [parser] on [txBytes], turning panics on malformed input into errors
+func safeParse(parser TxParser, txBytes []byte) (tx interface{}, codecVersion uint16, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tx, codecVersion, err = nil, 0, fmt.Errorf("panic parsing tx: %v", r)
+		}
+	}()
+	return parser.Parse(txBytes)
+}
+
+// typeName returns the name of the type of [tx], dereferencing pointers
+func typeName(tx interface{}) string {
+	t := reflect.TypeOf(tx)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// networkID returns the NetworkID field of [tx], possibly promoted from an
+// embedded BaseTx, or 0 if it has none
+func networkID(tx interface{}) uint32 {
+	v := reflect.ValueOf(tx)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	structField, ok := v.Type().FieldByName("NetworkID")
+	if !ok {
+		return 0
+	}
+	field, err := v.FieldByIndexErr(structField.Index)
+	if err != nil || field.Kind() != reflect.Uint32 {
+		return 0
+	}
+	return uint32(field.Uint())
+}
+
+func evmTxTypeName(txType uint8) string {
+	switch txType {
+	case types.LegacyTxType:
+		return "LegacyTx"
+	case types.AccessListTxType:
+		return "AccessListTx"
+	case types.DynamicFeeTxType:
+		return "DynamicFeeTx"
+	}
+	return fmt.Sprintf("EVMTx(type %d)", txType)
+}
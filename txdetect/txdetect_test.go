@@ -0,0 +1,297 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package txdetect
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	avmtxs "github.com/ava-labs/avalanchego/vms/avm/txs"
+	platformvmtxs "github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+func testOutputs() []*avax.TransferableOutput {
+	return []*avax.TransferableOutput{
+		{
+			Asset: avax.Asset{ID: ids.GenerateTestID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+				},
+			},
+		},
+	}
+}
+
+func TestAutoDetectChain(t *testing.T) {
+	require := require.New(t)
+
+	var pTx platformvmtxs.UnsignedTx = &platformvmtxs.BaseTx{
+		BaseTx: avax.BaseTx{NetworkID: 5, Outs: testOutputs()},
+	}
+	pTxBytes, err := platformvmtxs.Codec.Marshal(platformvmtxs.CodecVersion, &pTx)
+	require.NoError(err)
+	chain, err := AutoDetectChain(pTxBytes)
+	require.NoError(err)
+	require.Equal(PChain, chain)
+
+	parsers, err := getTxParsers()
+	require.NoError(err)
+	require.Len(parsers, 5)
+	xParser, err := avmtxs.NewParser(txParsers.xFxs)
+	require.NoError(err)
+	var xTx avmtxs.UnsignedTx = &avmtxs.BaseTx{
+		BaseTx: avax.BaseTx{NetworkID: 5, Outs: testOutputs()},
+	}
+	xTxBytes, err := xParser.Codec().Marshal(avmtxs.CodecVersion, &xTx)
+	require.NoError(err)
+	chain, err = AutoDetectChain(xTxBytes)
+	require.NoError(err)
+	require.Equal(XChain, chain)
+
+	var cTx evm.UnsignedAtomicTx = &evm.UnsignedImportTx{
+		NetworkID:    5,
+		SourceChain:  ids.GenerateTestID(),
+		BlockchainID: ids.GenerateTestID(),
+	}
+	cTxBytes, err := evm.Codec.Marshal(0, &cTx)
+	require.NoError(err)
+	chain, err = AutoDetectChain(cTxBytes)
+	require.NoError(err)
+	require.Equal(CChain, chain)
+
+	_, err = AutoDetectChain([]byte{1, 2, 3})
+	require.ErrorIs(err, ErrUnknownChain)
+	require.Contains(err.Error(), "platformvm")
+	require.Contains(err.Error(), "avm")
+	require.Contains(err.Error(), "coreth atomic")
+}
+
+func TestRegisterTxParser(t *testing.T) {
+	require := require.New(t)
+	magic := []byte("custom tx")
+	RegisterTxParser(TxParser{
+		Chain: "custom",
+		Name:  "custom parser",
+		Parse: func(unsignedTxBytes []byte) (interface{}, uint16, error) {
+			if string(unsignedTxBytes) != string(magic) {
+				return nil, 0, errors.New("not a custom tx")
+			}
+			return unsignedTxBytes, 0, nil
+		},
+	})
+	defer func() {
+		txParsers.parsers = nil
+	}()
+	chain, err := AutoDetectChain(magic)
+	require.NoError(err)
+	require.Equal("custom", chain)
+	_, err = AutoDetectChain([]byte{1, 2, 3})
+	require.ErrorContains(err, "custom parser: not a custom tx")
+}
+
+func TestAutoDetectChainIDEVM(t *testing.T) {
+	require := require.New(t)
+	key, err := crypto.GenerateKey()
+	require.NoError(err)
+	chainID := big.NewInt(12345)
+	to := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		To:        &to,
+		Gas:       21_000,
+		GasFeeCap: big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		Value:     big.NewInt(1),
+	})
+	require.NoError(err)
+	txBytes, err := tx.MarshalBinary()
+	require.NoError(err)
+	chain, detectedChainID, err := AutoDetectChainID(txBytes)
+	require.NoError(err)
+	require.Equal(EVMChain, chain)
+	require.Equal(chainID, detectedChainID)
+
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+	}).WithBody([]*types.Transaction{tx}, nil)
+	blockBytes, err := rlp.EncodeToBytes(block)
+	require.NoError(err)
+	chain, detectedChainID, err = AutoDetectChainID(blockBytes)
+	require.NoError(err)
+	require.Equal(EVMChain, chain)
+	require.Equal(chainID, detectedChainID)
+
+	// primary network C-Chain txs, with the chain IDs registered by package avalanche
+	RegisterCChainEVMChainID(43114)
+	RegisterCChainEVMChainID(43113)
+	for _, cChainID := range []*big.Int{big.NewInt(43114), big.NewInt(43113)} {
+		cChainTx, err := types.SignNewTx(key, types.LatestSignerForChainID(cChainID), &types.DynamicFeeTx{
+			ChainID:   cChainID,
+			Nonce:     1,
+			To:        &to,
+			Gas:       21_000,
+			GasFeeCap: big.NewInt(1),
+			GasTipCap: big.NewInt(1),
+		})
+		require.NoError(err)
+		cChainTxBytes, err := cChainTx.MarshalBinary()
+		require.NoError(err)
+		chain, detectedChainID, err = AutoDetectChainID(cChainTxBytes)
+		require.NoError(err)
+		require.Equal(CChain, chain)
+		require.Equal(cChainID, detectedChainID)
+		detection, err := DetectSigned(cChainTxBytes)
+		require.NoError(err)
+		require.Equal(CChain, detection.Chain)
+		require.Equal("DynamicFeeTx", detection.TxType)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	require := require.New(t)
+
+	var pTx platformvmtxs.UnsignedTx = &platformvmtxs.CreateSubnetTx{
+		BaseTx: platformvmtxs.BaseTx{BaseTx: avax.BaseTx{NetworkID: 5, Outs: testOutputs()}},
+		Owner:  &secp256k1fx.OutputOwners{},
+	}
+	pTxBytes, err := platformvmtxs.Codec.Marshal(platformvmtxs.CodecVersion, &pTx)
+	require.NoError(err)
+	detection, err := Detect(pTxBytes)
+	require.NoError(err)
+	require.Equal(PChain, detection.Chain)
+	require.Equal("platformvm", detection.Parser)
+	require.Equal("CreateSubnetTx", detection.TxType)
+	require.Equal(uint16(platformvmtxs.CodecVersion), detection.CodecVersion)
+	require.Equal(uint32(5), detection.NetworkID)
+	networkID, err := GetNetworkID(pTxBytes)
+	require.NoError(err)
+	require.Equal(uint32(5), networkID)
+
+	var cTx evm.UnsignedAtomicTx = &evm.UnsignedImportTx{
+		NetworkID:    1,
+		SourceChain:  ids.GenerateTestID(),
+		BlockchainID: ids.GenerateTestID(),
+	}
+	cTxBytes, err := evm.Codec.Marshal(0, &cTx)
+	require.NoError(err)
+	detection, err = Detect(cTxBytes)
+	require.NoError(err)
+	require.Equal(CChain, detection.Chain)
+	require.Equal("UnsignedImportTx", detection.TxType)
+	require.Equal(uint32(1), detection.NetworkID)
+
+	to := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	evmTxBytes, err := types.NewTx(&types.LegacyTx{To: &to, Gas: 21_000, GasPrice: big.NewInt(1)}).MarshalBinary()
+	require.NoError(err)
+	detection, err = Detect(evmTxBytes)
+	require.NoError(err)
+	require.Equal(EVMChain, detection.Chain)
+	require.Equal("LegacyTx", detection.TxType)
+	_, err = GetNetworkID(evmTxBytes)
+	require.ErrorIs(err, ErrNoNetworkID)
+
+	_, err = GetNetworkID(nil)
+	require.ErrorIs(err, ErrUnknownChain)
+}
+
+func FuzzDetect(f *testing.F) {
+	var pTx platformvmtxs.UnsignedTx = &platformvmtxs.BaseTx{
+		BaseTx: avax.BaseTx{NetworkID: 5, Outs: testOutputs()},
+	}
+	pTxBytes, err := platformvmtxs.Codec.Marshal(platformvmtxs.CodecVersion, &pTx)
+	require.NoError(f, err)
+	f.Add(pTxBytes)
+	f.Add(pTxBytes[:len(pTxBytes)/2])
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 0, 0, 0, 0x22})
+	f.Add([]byte{0xf9, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, txBytes []byte) {
+		if detection, err := DetectSigned(txBytes); err == nil {
+			require.NotEmpty(t, detection.Chain)
+		}
+		detection, err := Detect(txBytes)
+		if err != nil {
+			require.ErrorIs(t, err, ErrUnknownChain)
+			return
+		}
+		require.NotEmpty(t, detection.Chain)
+		require.NotNil(t, detection.Tx)
+	})
+}
+
+func TestDetectSigned(t *testing.T) {
+	require := require.New(t)
+
+	pTx := &platformvmtxs.Tx{
+		Unsigned: &platformvmtxs.BaseTx{BaseTx: avax.BaseTx{NetworkID: 5, Outs: testOutputs()}},
+		Creds:    []verify.Verifiable{&secp256k1fx.Credential{}},
+	}
+	require.NoError(pTx.Initialize(platformvmtxs.Codec))
+	detection, err := DetectSigned(pTx.Bytes())
+	require.NoError(err)
+	require.Equal(PChain, detection.Chain)
+	require.Equal("BaseTx", detection.TxType)
+	require.True(detection.Signed)
+	require.Equal(1, detection.Credentials)
+	require.Equal(uint32(5), detection.NetworkID)
+	require.Equal(pTx.Unsigned.Bytes(), detection.UnsignedTxBytes)
+	unsignedBytes, err := StripCredentials(pTx.Bytes())
+	require.NoError(err)
+	chain, err := AutoDetectChain(unsignedBytes)
+	require.NoError(err)
+	require.Equal(PChain, chain)
+	// unsigned bytes are not signed txs
+	_, err = DetectSigned(unsignedBytes)
+	require.ErrorIs(err, ErrUnknownChain)
+
+	xParser, err := avmtxs.NewParser(txParsers.xFxs)
+	require.NoError(err)
+	xTx := &avmtxs.Tx{Unsigned: &avmtxs.BaseTx{BaseTx: avax.BaseTx{NetworkID: 1, Outs: testOutputs()}}}
+	require.NoError(xTx.SignSECP256K1Fx(xParser.Codec(), nil))
+	networkID, err := GetNetworkIDFromSignedTx(xTx.Bytes())
+	require.NoError(err)
+	require.Equal(uint32(1), networkID)
+	detection, err = DetectSigned(xTx.Bytes())
+	require.NoError(err)
+	require.Equal(XChain, detection.Chain)
+	require.Zero(detection.Credentials)
+
+	cTx := &evm.Tx{UnsignedAtomicTx: &evm.UnsignedExportTx{
+		NetworkID:        1337,
+		BlockchainID:     ids.GenerateTestID(),
+		DestinationChain: ids.GenerateTestID(),
+	}}
+	require.NoError(cTx.Sign(evm.Codec, nil))
+	detection, err = DetectSigned(cTx.SignedBytes())
+	require.NoError(err)
+	require.Equal(CChain, detection.Chain)
+	require.Equal("UnsignedExportTx", detection.TxType)
+	require.Equal(uint32(1337), detection.NetworkID)
+	require.Equal(cTx.Bytes(), detection.UnsignedTxBytes)
+
+	to := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	evmTxBytes, err := types.NewTx(&types.LegacyTx{To: &to, Gas: 21_000, GasPrice: big.NewInt(1)}).MarshalBinary()
+	require.NoError(err)
+	detection, err = DetectSigned(evmTxBytes)
+	require.NoError(err)
+	require.Equal(EVMChain, detection.Chain)
+	_, err = StripCredentials(evmTxBytes)
+	require.ErrorContains(err, "no credentials")
+}
@@ -9,7 +9,6 @@ import (
 	"math/rand"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"golang.org/x/exp/slices"
 )
 
@@ -167,8 +166,10 @@ func RandomString(length int) string {
 	return string(result)
 }
 
+// SupportedAvagoArch returns the architectures avalanchego is released for, named as
+// the cloud providers do (AWS ec2 architecture types, GCP image architectures)
 func SupportedAvagoArch() []string {
-	return []string{string(types.ArchitectureTypeArm64), string(types.ArchitectureTypeX8664)}
+	return []string{"arm64", "x86_64"}
 }
 
 func ArchSupported(arch string) bool {
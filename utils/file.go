@@ -3,6 +3,8 @@
 package utils
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -62,3 +64,18 @@ func GetRemoteComposeServicePath(serviceName string, dirs ...string) string {
 	servicePrefix := filepath.Join(constants.CloudNodeCLIConfigBasePath, "services", serviceName)
 	return filepath.Join(append([]string{servicePrefix}, dirs...)...)
 }
+
+// ReadFileWithOverride reads [name] from [override], if set and the file is found there,
+// or from [fsys] otherwise
+func ReadFileWithOverride(override fs.FS, fsys fs.FS, name string) ([]byte, error) {
+	if override != nil {
+		content, err := fs.ReadFile(override, name)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return fs.ReadFile(fsys, name)
+}
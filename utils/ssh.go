@@ -12,6 +12,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 )
 
@@ -117,3 +118,34 @@ func IsSSHPubKey(pubkey string) bool {
 	// Check if the key matches the pattern
 	return regex.MatchString(key)
 }
+
+const (
+	consoleHostKeysBegin = "-----BEGIN SSH HOST KEY KEYS-----"
+	consoleHostKeysEnd   = "-----END SSH HOST KEY KEYS-----"
+)
+
+// ParseConsoleHostKeys returns the SSH host public keys printed by cloud-init on the
+// console of an instance at first boot, in authorized_keys format. Console lines can
+// be prefixed, eg. by timestamps, and invalid keys are skipped
+func ParseConsoleHostKeys(consoleOutput string) []string {
+	hostKeys := []string{}
+	inKeys := false
+	for _, line := range strings.Split(consoleOutput, "\n") {
+		switch {
+		case strings.Contains(line, consoleHostKeysBegin):
+			inKeys = true
+		case strings.Contains(line, consoleHostKeysEnd):
+			inKeys = false
+		case inKeys:
+			fields := strings.Fields(line)
+			for i := 0; i+1 < len(fields); i++ {
+				candidate := fields[i] + " " + fields[i+1]
+				if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(candidate)); err == nil {
+					hostKeys = append(hostKeys, candidate)
+					break
+				}
+			}
+		}
+	}
+	return hostKeys
+}
@@ -53,6 +53,12 @@ func AddSingleQuotes(s []string) []string {
 	})
 }
 
+// ShellQuote quotes [s] as a single shell word, so it can be interpolated into a remote
+// command without being expanded or split by the shell
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // StringValue returns the value of a key in a map as a string.
 func StringValue(data map[string]interface{}, key string) (string, error) {
 	if value, ok := data[key]; ok {
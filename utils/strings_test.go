@@ -61,3 +61,17 @@ func TestAddSingleQuotes(t *testing.T) {
 		t.Errorf("AddSingleQuotes(%v) = %v, expected %v", input, output, expected)
 	}
 }
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":                "''",
+		"file":            "'file'",
+		"my file; rm -rf": "'my file; rm -rf'",
+		"it's $HOME":      `'it'\''s $HOME'`,
+	}
+	for input, expected := range tests {
+		if output := ShellQuote(input); output != expected {
+			t.Errorf("ShellQuote(%q) = %s, expected %s", input, output, expected)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+// PrimaryNetworkMaxValidatorWeightFactor is the factor of its own stake a primary
+// network validator weight, delegations included, is limited to
+const PrimaryNetworkMaxValidatorWeightFactor = 5
+
+var ErrValidatorNotFound = errors.New("node is not a current validator")
+
+// DelegationParams are the staking rules that bound the delegations a validator accepts
+type DelegationParams struct {
+	MinDelegatorStake uint64
+	// MaxValidatorStake bounds the weight of a validator, delegations included
+	MaxValidatorStake uint64
+	// MaxValidatorWeightFactor bounds the weight of a validator, delegations included,
+	// to this factor times its own stake
+	MaxValidatorWeightFactor uint64
+	MinStakeDuration         time.Duration
+	MaxStakeDuration         time.Duration
+}
+
+// PrimaryNetworkDelegationParams returns the delegation rules of the primary network
+// of [network]
+func PrimaryNetworkDelegationParams(network avalanche.Network) DelegationParams {
+	config := genesis.GetStakingConfig(network.ID)
+	return DelegationParams{
+		MinDelegatorStake:        config.MinDelegatorStake,
+		MaxValidatorStake:        config.MaxValidatorStake,
+		MaxValidatorWeightFactor: PrimaryNetworkMaxValidatorWeightFactor,
+		MinStakeDuration:         config.MinStakeDuration,
+		MaxStakeDuration:         config.MaxStakeDuration,
+	}
+}
+
+// Delegation is a current delegation to a validator
+type Delegation struct {
+	Weight    uint64
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// DelegationCapacity is the delegated weight a validator can still accept
+type DelegationCapacity struct {
+	SubnetID         ids.ID
+	NodeID           ids.NodeID
+	ValidatorStake   uint64
+	ValidatorEndTime time.Time
+	// MaxWeight is the max weight of the validator, its own stake and delegations
+	MaxWeight uint64
+	// DelegatedWeight is the sum of the current delegations
+	DelegatedWeight uint64
+	// Remaining is the weight that can be delegated now
+	Remaining uint64
+	// Delegations are the current delegations, sorted by end time
+	Delegations []Delegation
+
+	params DelegationParams
+}
+
+// DelegationProjection is the outcome of a proposed delegation. See DelegationCapacity.Project
+type DelegationProjection struct {
+	Amount    uint64
+	StartTime time.Time
+	EndTime   time.Time
+	// RemainingAfter is the weight that can still be delegated after the proposed
+	// delegation, if it is accepted
+	RemainingAfter uint64
+	// Issues lists the reasons the delegation would be rejected
+	Issues []string
+}
+
+// Allowed tells if the proposed delegation would be accepted
+func (p DelegationProjection) Allowed() bool {
+	return len(p.Issues) == 0
+}
+
+// GetDelegationCapacity computes the delegation capacity of [nodeID] as a validator
+// of [subnetID], from the current validator set. Use ids.Empty for the primary network
+// together with PrimaryNetworkDelegationParams
+func GetDelegationCapacity(
+	network avalanche.Network,
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	params DelegationParams,
+) (DelegationCapacity, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	// delegators are only included when node IDs are given
+	validators, err := platformvm.NewClient(network.Endpoint).GetCurrentValidators(ctx, subnetID, []ids.NodeID{nodeID})
+	if err != nil {
+		return DelegationCapacity{}, fmt.Errorf("failure getting current validators for %s: %w", subnetName(subnetID), err)
+	}
+	for _, validator := range validators {
+		if validator.NodeID == nodeID {
+			return delegationCapacity(subnetID, validator, params)
+		}
+	}
+	return DelegationCapacity{}, fmt.Errorf("%w: %s on %s", ErrValidatorNotFound, nodeID, subnetName(subnetID))
+}
+
+func delegationCapacity(
+	subnetID ids.ID,
+	validator platformvm.ClientPermissionlessValidator,
+	params DelegationParams,
+) (DelegationCapacity, error) {
+	stake := validator.Weight
+	if validator.StakeAmount != nil {
+		stake = *validator.StakeAmount
+	}
+	capacity := DelegationCapacity{
+		SubnetID:         subnetID,
+		NodeID:           validator.NodeID,
+		ValidatorStake:   stake,
+		ValidatorEndTime: time.Unix(int64(validator.EndTime), 0),
+		MaxWeight:        params.MaxValidatorStake,
+		Delegations:      []Delegation{},
+		params:           params,
+	}
+	if maxWeight, err := safemath.Mul64(stake, params.MaxValidatorWeightFactor); err == nil {
+		capacity.MaxWeight = min(maxWeight, params.MaxValidatorStake)
+	}
+	for _, delegator := range validator.Delegators {
+		capacity.Delegations = append(capacity.Delegations, Delegation{
+			Weight:    delegator.Weight,
+			StartTime: time.Unix(int64(delegator.StartTime), 0),
+			EndTime:   time.Unix(int64(delegator.EndTime), 0),
+		})
+		var err error
+		if capacity.DelegatedWeight, err = safemath.Add64(capacity.DelegatedWeight, delegator.Weight); err != nil {
+			return DelegationCapacity{}, fmt.Errorf("delegated weight of %s: %w", validator.NodeID, err)
+		}
+	}
+	sort.SliceStable(capacity.Delegations, func(i, j int) bool {
+		return capacity.Delegations[i].EndTime.Before(capacity.Delegations[j].EndTime)
+	})
+	capacity.Remaining = capacity.remaining(capacity.DelegatedWeight)
+	return capacity, nil
+}
+
+func (c DelegationCapacity) remaining(delegatedWeight uint64) uint64 {
+	weight, err := safemath.Add64(c.ValidatorStake, delegatedWeight)
+	if err != nil || weight >= c.MaxWeight {
+		return 0
+	}
+	return c.MaxWeight - weight
+}
+
+// RemainingAt is the weight that can be delegated at [t], once the current delegations
+// ending before it have finished
+func (c DelegationCapacity) RemainingAt(t time.Time) uint64 {
+	if !t.Before(c.ValidatorEndTime) {
+		return 0
+	}
+	delegatedWeight := uint64(0)
+	for _, delegation := range c.Delegations {
+		if delegation.EndTime.After(t) {
+			delegatedWeight += delegation.Weight
+		}
+	}
+	return c.remaining(delegatedWeight)
+}
+
+// Project checks a delegation of [amount] for [duration] starting at [now], as the
+// P-Chain does, and the capacity left after it
+func (c DelegationCapacity) Project(amount uint64, duration time.Duration, now time.Time) DelegationProjection {
+	projection := DelegationProjection{
+		Amount:    amount,
+		StartTime: now,
+		EndTime:   now.Add(duration),
+	}
+	remaining := c.RemainingAt(now)
+	if amount < c.params.MinDelegatorStake {
+		projection.Issues = append(projection.Issues, fmt.Sprintf(
+			"amount %d is lower than the min delegator stake %d", amount, c.params.MinDelegatorStake,
+		))
+	}
+	if amount > remaining {
+		projection.Issues = append(projection.Issues, fmt.Sprintf(
+			"amount %d is greater than the remaining delegation capacity %d of %s", amount, remaining, c.NodeID,
+		))
+	} else {
+		projection.RemainingAfter = remaining - amount
+	}
+	if duration < c.params.MinStakeDuration {
+		projection.Issues = append(projection.Issues, fmt.Sprintf(
+			"duration %s is shorter than the min stake duration %s", duration, c.params.MinStakeDuration,
+		))
+	}
+	if c.params.MaxStakeDuration > 0 && duration > c.params.MaxStakeDuration {
+		projection.Issues = append(projection.Issues, fmt.Sprintf(
+			"duration %s is longer than the max stake duration %s", duration, c.params.MaxStakeDuration,
+		))
+	}
+	if projection.EndTime.After(c.ValidatorEndTime) {
+		projection.Issues = append(projection.Issues, fmt.Sprintf(
+			"delegation would end at %s, after the validation of %s ends at %s",
+			projection.EndTime.UTC().Format(time.RFC3339), c.NodeID, c.ValidatorEndTime.UTC().Format(time.RFC3339),
+		))
+	}
+	return projection
+}
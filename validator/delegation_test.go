@@ -0,0 +1,71 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegationCapacity(t *testing.T) {
+	require := require.New(t)
+	now := time.Unix(1_700_000_000, 0)
+	day := 24 * time.Hour
+	nodeID := ids.GenerateTestNodeID()
+	stake := uint64(2_000)
+	validator := platformvm.ClientPermissionlessValidator{
+		ClientStaker: platformvm.ClientStaker{
+			NodeID:      nodeID,
+			StartTime:   uint64(now.Add(-day).Unix()),
+			EndTime:     uint64(now.Add(100 * day).Unix()),
+			Weight:      stake + 5_000,
+			StakeAmount: &stake,
+		},
+		Delegators: []platformvm.ClientDelegator{
+			{ClientStaker: platformvm.ClientStaker{Weight: 3_000, EndTime: uint64(now.Add(50 * day).Unix())}},
+			{ClientStaker: platformvm.ClientStaker{Weight: 2_000, EndTime: uint64(now.Add(10 * day).Unix())}},
+		},
+	}
+	params := DelegationParams{
+		MinDelegatorStake:        25,
+		MaxValidatorStake:        9_000,
+		MaxValidatorWeightFactor: 5,
+		MinStakeDuration:         14 * day,
+		MaxStakeDuration:         365 * day,
+	}
+	capacity, err := delegationCapacity(avagoconstants.PrimaryNetworkID, validator, params)
+	require.NoError(err)
+	// 5 * 2,000 is capped by the max validator stake
+	require.Equal(uint64(9_000), capacity.MaxWeight)
+	require.Equal(uint64(5_000), capacity.DelegatedWeight)
+	require.Equal(uint64(2_000), capacity.Remaining)
+	require.Equal(uint64(2_000), capacity.Delegations[0].Weight)
+	require.Equal(uint64(4_000), capacity.RemainingAt(now.Add(20*day)))
+	require.Equal(uint64(7_000), capacity.RemainingAt(now.Add(60*day)))
+	require.Zero(capacity.RemainingAt(now.Add(100 * day)))
+
+	projection := capacity.Project(1_500, 30*day, now)
+	require.True(projection.Allowed(), projection.Issues)
+	require.Equal(uint64(500), projection.RemainingAfter)
+
+	projection = capacity.Project(2_500, 30*day, now)
+	require.False(projection.Allowed())
+	require.Len(projection.Issues, 1)
+	require.Contains(projection.Issues[0], "remaining delegation capacity")
+
+	projection = capacity.Project(10, 7*day, now.Add(95*day))
+	require.Len(projection.Issues, 3)
+}
+
+func TestPrimaryNetworkDelegationParams(t *testing.T) {
+	params := PrimaryNetworkDelegationParams(avalanche.MainnetNetwork())
+	require.Equal(t, uint64(25*1_000_000_000), params.MinDelegatorStake)
+	require.Equal(t, uint64(PrimaryNetworkMaxValidatorWeightFactor), params.MaxValidatorWeightFactor)
+}
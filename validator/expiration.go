@@ -0,0 +1,257 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+const defaultExpirationCheckInterval = time.Hour
+
+var ErrNoExpirationThresholds = errors.New("at least one expiration threshold is needed")
+
+// Expiration is the staking period of a validator of the primary network or of a
+// permissioned subnet
+type Expiration struct {
+	SubnetID  ids.ID
+	NodeID    ids.NodeID
+	StartTime time.Time
+	EndTime   time.Time
+	// TimeToExpiry is the time left until EndTime, when the expiration was computed
+	TimeToExpiry time.Duration
+}
+
+// ExpirationAlert is sent when a validator is about to expire. See ExpirationWatcher
+type ExpirationAlert struct {
+	Expiration
+	// Threshold is the time before EndTime that triggered the alert
+	Threshold time.Duration
+}
+
+type expirationAlertJSON struct {
+	SubnetID         ids.ID     `json:"subnetID"`
+	NodeID           ids.NodeID `json:"nodeID"`
+	PrimaryNetwork   bool       `json:"primaryNetwork"`
+	StartTime        time.Time  `json:"startTime"`
+	EndTime          time.Time  `json:"endTime"`
+	SecondsToExpiry  int64      `json:"secondsToExpiry"`
+	ThresholdSeconds int64      `json:"thresholdSeconds"`
+}
+
+// MarshalJSON serializes the alert as posted to webhooks, with durations in seconds
+func (a ExpirationAlert) MarshalJSON() ([]byte, error) {
+	return json.Marshal(expirationAlertJSON{
+		SubnetID:         a.SubnetID,
+		NodeID:           a.NodeID,
+		PrimaryNetwork:   a.SubnetID == avagoconstants.PrimaryNetworkID,
+		StartTime:        a.StartTime.UTC(),
+		EndTime:          a.EndTime.UTC(),
+		SecondsToExpiry:  int64(a.TimeToExpiry / time.Second),
+		ThresholdSeconds: int64(a.Threshold / time.Second),
+	})
+}
+
+type currentValidatorsClient interface {
+	GetCurrentValidators(ctx context.Context, subnetID ids.ID, nodeIDs []ids.NodeID, options ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error)
+}
+
+// GetExpirations returns the staking periods of [nodeIDs] as validators of each of
+// [subnetIDs], sorted by EndTime. Use ids.Empty for the primary network. Nodes that
+// are not current validators of a subnet are not included
+func GetExpirations(
+	network avalanche.Network,
+	subnetIDs []ids.ID,
+	nodeIDs []ids.NodeID,
+) ([]Expiration, error) {
+	return getExpirations(platformvm.NewClient(network.Endpoint), subnetIDs, nodeIDs, time.Now())
+}
+
+func getExpirations(
+	client currentValidatorsClient,
+	subnetIDs []ids.ID,
+	nodeIDs []ids.NodeID,
+	now time.Time,
+) ([]Expiration, error) {
+	expirations := []Expiration{}
+	for _, subnetID := range subnetIDs {
+		ctx, cancel := utils.GetAPIContext()
+		validators, err := client.GetCurrentValidators(ctx, subnetID, nodeIDs)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failure getting current validators for %s: %w", subnetName(subnetID), err)
+		}
+		for _, validator := range validators {
+			endTime := time.Unix(int64(validator.EndTime), 0)
+			expirations = append(expirations, Expiration{
+				SubnetID:     subnetID,
+				NodeID:       validator.NodeID,
+				StartTime:    time.Unix(int64(validator.StartTime), 0),
+				EndTime:      endTime,
+				TimeToExpiry: endTime.Sub(now),
+			})
+		}
+	}
+	sort.SliceStable(expirations, func(i, j int) bool {
+		return expirations[i].EndTime.Before(expirations[j].EndTime)
+	})
+	return expirations, nil
+}
+
+// ExpirationWatcher periodically checks the staking periods of a set of validators, and
+// alerts each time one of them gets within a threshold of its end time, so operators
+// can re-stake without a gap in validation
+type ExpirationWatcher struct {
+	Network avalanche.Network
+	// SubnetIDs are the subnets to check, ids.Empty being the primary network.
+	// Defaults to the primary network only
+	SubnetIDs []ids.ID
+	NodeIDs   []ids.NodeID
+	// Thresholds are the times before the end time an alert is sent at, eg. 30, 7
+	// and 1 days. Each threshold alerts once per staking period
+	Thresholds []time.Duration
+	// Interval between checks. Defaults to 1 hour
+	Interval time.Duration
+	// OnExpiring is called for each alert, if set
+	OnExpiring func(ExpirationAlert)
+	// WebhookURL gets each alert as a JSON POST, if set
+	WebhookURL string
+	// OnError is called when a check or a webhook post fails, if set. The watcher
+	// keeps running on errors
+	OnError func(error)
+
+	client currentValidatorsClient
+	// alerted holds the alerts already sent
+	alerted map[alertKey]struct{}
+}
+
+type alertKey struct {
+	subnetID  ids.ID
+	nodeID    ids.NodeID
+	endTime   time.Time
+	threshold time.Duration
+}
+
+// Run checks the validators until [ctx] is done
+func (w *ExpirationWatcher) Run(ctx context.Context) error {
+	if len(w.Thresholds) == 0 {
+		return ErrNoExpirationThresholds
+	}
+	interval := w.Interval
+	if interval == 0 {
+		interval = defaultExpirationCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(ctx, time.Now()); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check does a single check of the validators as of [now], sending the alerts not
+// sent before. It returns the first error found, after sending all alerts it could
+func (w *ExpirationWatcher) Check(ctx context.Context, now time.Time) error {
+	if w.client == nil {
+		w.client = platformvm.NewClient(w.Network.Endpoint)
+	}
+	if w.alerted == nil {
+		w.alerted = map[alertKey]struct{}{}
+	}
+	subnetIDs := w.SubnetIDs
+	if len(subnetIDs) == 0 {
+		subnetIDs = []ids.ID{avagoconstants.PrimaryNetworkID}
+	}
+	expirations, err := getExpirations(w.client, subnetIDs, w.NodeIDs, now)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, alert := range w.pendingAlerts(expirations) {
+		if w.OnExpiring != nil {
+			w.OnExpiring(alert)
+		}
+		if w.WebhookURL != "" {
+			if err := postAlert(ctx, w.WebhookURL, alert); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// pendingAlerts returns, for each expiration, an alert for the smallest threshold it
+// crossed, if not sent yet. Larger thresholds crossed are marked as sent, so a watcher
+// started close to an end time doesn't send all of them at once
+func (w *ExpirationWatcher) pendingAlerts(expirations []Expiration) []ExpirationAlert {
+	thresholds := append([]time.Duration{}, w.Thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	alerts := []ExpirationAlert{}
+	for _, expiration := range expirations {
+		for i, threshold := range thresholds {
+			if expiration.TimeToExpiry > threshold {
+				continue
+			}
+			key := alertKey{expiration.SubnetID, expiration.NodeID, expiration.EndTime, threshold}
+			if _, ok := w.alerted[key]; !ok {
+				alerts = append(alerts, ExpirationAlert{Expiration: expiration, Threshold: threshold})
+			}
+			for _, crossed := range thresholds[i:] {
+				w.alerted[alertKey{expiration.SubnetID, expiration.NodeID, expiration.EndTime, crossed}] = struct{}{}
+			}
+			break
+		}
+	}
+	return alerts
+}
+
+func postAlert(ctx context.Context, url string, alert ExpirationAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, constants.APIRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failure posting expiration alert for %s: %w", alert.NodeID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("expiration alert webhook for %s returned %s", alert.NodeID, resp.Status)
+	}
+	return nil
+}
+
+func subnetName(subnetID ids.ID) string {
+	if subnetID == avagoconstants.PrimaryNetworkID {
+		return "primary network"
+	}
+	return "subnet " + subnetID.String()
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeValidatorsClient struct {
+	validators map[ids.ID][]platformvm.ClientPermissionlessValidator
+}
+
+func (c *fakeValidatorsClient) GetCurrentValidators(
+	_ context.Context,
+	subnetID ids.ID,
+	_ []ids.NodeID,
+	_ ...rpc.Option,
+) ([]platformvm.ClientPermissionlessValidator, error) {
+	return c.validators[subnetID], nil
+}
+
+func fakeValidator(nodeID ids.NodeID, endTime time.Time) platformvm.ClientPermissionlessValidator {
+	return platformvm.ClientPermissionlessValidator{
+		ClientStaker: platformvm.ClientStaker{
+			NodeID:    nodeID,
+			StartTime: uint64(endTime.Add(-365 * 24 * time.Hour).Unix()),
+			EndTime:   uint64(endTime.Unix()),
+		},
+	}
+}
+
+func TestGetExpirations(t *testing.T) {
+	require := require.New(t)
+	now := time.Unix(1_700_000_000, 0)
+	nodeA := ids.GenerateTestNodeID()
+	nodeB := ids.GenerateTestNodeID()
+	subnetID := ids.GenerateTestID()
+	client := &fakeValidatorsClient{validators: map[ids.ID][]platformvm.ClientPermissionlessValidator{
+		avagoconstants.PrimaryNetworkID: {fakeValidator(nodeA, now.Add(48*time.Hour))},
+		subnetID:                        {fakeValidator(nodeB, now.Add(time.Hour))},
+	}}
+	expirations, err := getExpirations(client, []ids.ID{avagoconstants.PrimaryNetworkID, subnetID}, nil, now)
+	require.NoError(err)
+	require.Len(expirations, 2)
+	require.Equal(nodeB, expirations[0].NodeID)
+	require.Equal(subnetID, expirations[0].SubnetID)
+	require.Equal(time.Hour, expirations[0].TimeToExpiry)
+	require.Equal(nodeA, expirations[1].NodeID)
+	require.Equal(48*time.Hour, expirations[1].TimeToExpiry)
+}
+
+func TestExpirationWatcherCheck(t *testing.T) {
+	require := require.New(t)
+	now := time.Unix(1_700_000_000, 0)
+	day := 24 * time.Hour
+	nodeA := ids.GenerateTestNodeID()
+	nodeB := ids.GenerateTestNodeID()
+	client := &fakeValidatorsClient{validators: map[ids.ID][]platformvm.ClientPermissionlessValidator{
+		avagoconstants.PrimaryNetworkID: {
+			fakeValidator(nodeA, now.Add(5*day)),
+			fakeValidator(nodeB, now.Add(60*day)),
+		},
+	}}
+	posted := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]interface{}{}
+		require.NoError(json.NewDecoder(r.Body).Decode(&payload))
+		posted = append(posted, payload)
+	}))
+	defer server.Close()
+
+	alerts := []ExpirationAlert{}
+	watcher := &ExpirationWatcher{
+		NodeIDs:    []ids.NodeID{nodeA, nodeB},
+		Thresholds: []time.Duration{30 * day, 7 * day, day},
+		OnExpiring: func(alert ExpirationAlert) { alerts = append(alerts, alert) },
+		WebhookURL: server.URL,
+		client:     client,
+	}
+	// nodeA crossed both 30 and 7 days, only the 7 days alert is sent
+	require.NoError(watcher.Check(context.Background(), now))
+	require.Len(alerts, 1)
+	require.Equal(nodeA, alerts[0].NodeID)
+	require.Equal(7*day, alerts[0].Threshold)
+	require.Len(posted, 1)
+	require.Equal(nodeA.String(), posted[0]["nodeID"])
+	require.Equal(float64(7*24*60*60), posted[0]["thresholdSeconds"])
+	require.Equal(true, posted[0]["primaryNetwork"])
+
+	// alerts are not repeated
+	require.NoError(watcher.Check(context.Background(), now.Add(time.Hour)))
+	require.Len(alerts, 1)
+
+	// next threshold
+	require.NoError(watcher.Check(context.Background(), now.Add(4*day+time.Hour)))
+	require.Len(alerts, 2)
+	require.Equal(day, alerts[1].Threshold)
+
+	// re-staking rearms the alerts
+	client.validators[avagoconstants.PrimaryNetworkID][0] = fakeValidator(nodeA, now.Add(20*day))
+	require.NoError(watcher.Check(context.Background(), now))
+	require.Len(alerts, 3)
+	require.Equal(30*day, alerts[2].Threshold)
+	require.Len(posted, 3)
+}
+
+func TestExpirationWatcherRunNoThresholds(t *testing.T) {
+	watcher := &ExpirationWatcher{}
+	require.ErrorIs(t, watcher.Run(context.Background()), ErrNoExpirationThresholds)
+}
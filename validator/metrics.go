@@ -0,0 +1,140 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// BusinessMetrics are validator metrics computed by the SDK from the P-Chain, instead of
+// measured by avalanchego: staking period countdowns, pending rewards and L1 fee
+// balances. They are rendered with Render and published on the nodes by the metrics
+// exporter sidecar, so the monitoring stack scrapes them along the node metrics
+type BusinessMetrics struct {
+	registry        *prometheus.Registry
+	secondsToExpiry *prometheus.GaugeVec
+	potentialReward *prometheus.GaugeVec
+	delegateeReward *prometheus.GaugeVec
+	l1FeeBalance    *prometheus.GaugeVec
+	lastUpdate      prometheus.Gauge
+
+	client currentValidatorsClient
+}
+
+// NewBusinessMetrics creates the business metrics under [namespace], eg. "avalanche"
+func NewBusinessMetrics(namespace string) (*BusinessMetrics, error) {
+	m := &BusinessMetrics{
+		registry: prometheus.NewRegistry(),
+		secondsToExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "validator_seconds_to_expiry",
+			Help:      "Seconds left until the end of the staking period of the validator",
+		}, []string{"subnet_id", "node_id"}),
+		potentialReward: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "validator_potential_reward_navax",
+			Help:      "Reward the primary network validator gets at the end of its staking period, in nAVAX",
+		}, []string{"node_id"}),
+		delegateeReward: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "validator_accrued_delegatee_reward_navax",
+			Help:      "Delegation fees accrued by the primary network validator, in nAVAX",
+		}, []string{"node_id"}),
+		l1FeeBalance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "l1_validator_fee_balance_navax",
+			Help:      "Balance left to pay the continuous fee of the L1 validator, in nAVAX",
+		}, []string{"subnet_id", "node_id"}),
+		lastUpdate: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "business_metrics_last_update_timestamp_seconds",
+			Help:      "Unix time of the last update of the business metrics",
+		}),
+	}
+	for _, collector := range []prometheus.Collector{
+		m.secondsToExpiry,
+		m.potentialReward,
+		m.delegateeReward,
+		m.l1FeeBalance,
+		m.lastUpdate,
+	} {
+		if err := m.registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Update sets the metrics of [nodeIDs] as validators of [subnetIDs] from the current
+// P-Chain validators. Use ids.Empty for the primary network. Nodes that stopped being
+// validators are removed from the metrics
+func (m *BusinessMetrics) Update(network avalanche.Network, subnetIDs []ids.ID, nodeIDs []ids.NodeID) error {
+	if m.client == nil {
+		m.client = platformvm.NewClient(network.Endpoint)
+	}
+	return m.update(subnetIDs, nodeIDs, time.Now())
+}
+
+func (m *BusinessMetrics) update(subnetIDs []ids.ID, nodeIDs []ids.NodeID, now time.Time) error {
+	m.secondsToExpiry.Reset()
+	m.potentialReward.Reset()
+	m.delegateeReward.Reset()
+	for _, subnetID := range subnetIDs {
+		ctx, cancel := utils.GetAPIContext()
+		validators, err := m.client.GetCurrentValidators(ctx, subnetID, nodeIDs)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failure getting current validators for %s: %w", subnetName(subnetID), err)
+		}
+		for _, validator := range validators {
+			nodeID := validator.NodeID.String()
+			endTime := time.Unix(int64(validator.EndTime), 0)
+			m.secondsToExpiry.WithLabelValues(subnetID.String(), nodeID).Set(endTime.Sub(now).Seconds())
+			if subnetID != avagoconstants.PrimaryNetworkID {
+				continue
+			}
+			if validator.PotentialReward != nil {
+				m.potentialReward.WithLabelValues(nodeID).Set(float64(*validator.PotentialReward))
+			}
+			if validator.AccruedDelegateeReward != nil {
+				m.delegateeReward.WithLabelValues(nodeID).Set(float64(*validator.AccruedDelegateeReward))
+			}
+		}
+	}
+	m.lastUpdate.Set(float64(now.Unix()))
+	return nil
+}
+
+// SetL1FeeBalance sets the balance left to pay the continuous fee of [nodeID] as an L1
+// validator of [subnetID]. The P-Chain API of the avalanchego version supported by the
+// SDK does not expose it, so it is set by the caller
+func (m *BusinessMetrics) SetL1FeeBalance(subnetID ids.ID, nodeID ids.NodeID, balance uint64) {
+	m.l1FeeBalance.WithLabelValues(subnetID.String(), nodeID.String()).Set(float64(balance))
+}
+
+// Render returns the metrics in the Prometheus text exposition format
+func (m *BusinessMetrics) Render() ([]byte, error) {
+	families, err := m.registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
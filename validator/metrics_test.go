@@ -0,0 +1,50 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessMetrics(t *testing.T) {
+	require := require.New(t)
+	now := time.Unix(1_700_000_000, 0)
+	nodeA := ids.GenerateTestNodeID()
+	nodeB := ids.GenerateTestNodeID()
+	subnetID := ids.GenerateTestID()
+	reward := uint64(5_000_000)
+	validatorA := fakeValidator(nodeA, now.Add(time.Hour))
+	validatorA.PotentialReward = &reward
+	client := &fakeValidatorsClient{validators: map[ids.ID][]platformvm.ClientPermissionlessValidator{
+		avagoconstants.PrimaryNetworkID: {validatorA},
+		subnetID:                        {fakeValidator(nodeB, now.Add(time.Minute))},
+	}}
+	m, err := NewBusinessMetrics("avalanche")
+	require.NoError(err)
+	m.client = client
+	require.NoError(m.update([]ids.ID{avagoconstants.PrimaryNetworkID, subnetID}, nil, now))
+	m.SetL1FeeBalance(subnetID, nodeB, 1_000)
+
+	metrics, err := m.Render()
+	require.NoError(err)
+	rendered := string(metrics)
+	require.Contains(rendered, `avalanche_validator_seconds_to_expiry{node_id="`+nodeA.String()+`",subnet_id="`+avagoconstants.PrimaryNetworkID.String()+`"} 3600`)
+	require.Contains(rendered, `avalanche_validator_seconds_to_expiry{node_id="`+nodeB.String()+`",subnet_id="`+subnetID.String()+`"} 60`)
+	require.Contains(rendered, `avalanche_validator_potential_reward_navax{node_id="`+nodeA.String()+`"} 5e+06`)
+	require.Contains(rendered, `avalanche_l1_validator_fee_balance_navax{node_id="`+nodeB.String()+`",subnet_id="`+subnetID.String()+`"} 1000`)
+	require.Contains(rendered, "avalanche_business_metrics_last_update_timestamp_seconds 1.7e+09")
+
+	// validators that are gone are dropped on update
+	client.validators[subnetID] = nil
+	require.NoError(m.update([]ids.ID{subnetID}, nil, now))
+	metrics, err = m.Render()
+	require.NoError(err)
+	require.NotContains(string(metrics), "validator_seconds_to_expiry{")
+}
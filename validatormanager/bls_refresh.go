@@ -0,0 +1,272 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var ErrBLSKeyRefreshWeight = errors.New("weight of the refreshed validator is unknown")
+
+const (
+	StepCheckBLSKey          = "check bls key"
+	StepInitiateRemoval      = "initiate removal"
+	StepCompleteRemoval      = "complete removal"
+	StepRegisterNewKey       = "register new bls key"
+	StepCompleteRegistration = "complete registration"
+)
+
+// PChainOwner is a P-Chain owner, as the manager contract takes it
+type PChainOwner struct {
+	Threshold uint32
+	Addresses []common.Address
+}
+
+// BLSKeyRefreshParams are the params of RefreshBLSKey
+type BLSKeyRefreshParams struct {
+	Network        avalanche.Network
+	RPCURL         string
+	ManagerAddress common.Address
+	// PrivateKey of the manager owner, that pays for the contract txs
+	PrivateKey string
+	// ValidationID of the validator registered with the old BLS key
+	ValidationID ids.ID
+	// NodeEndpoint is the API endpoint of the node, used to get its current BLS key
+	NodeEndpoint string
+	// Weight the validator is registered again with. Defaults to the weight of the
+	// validator, and must be set when resuming a refresh whose removal was already
+	// initiated, as the contract doesn't keep the weight of removed validators
+	Weight uint64
+	// RemainingBalanceOwner and DisableOwner of the new registration
+	RemainingBalanceOwner PChainOwner
+	DisableOwner          PChainOwner
+}
+
+// BLSKeyRefreshReport is the result of RefreshBLSKey
+type BLSKeyRefreshReport struct {
+	ValidationID ids.ID
+	NodeID       ids.NodeID
+	// RegisteredKey is the BLS key the P-Chain has for ValidationID, if it still has it
+	RegisteredKey string
+	// NodeKey is the BLS key the node reports
+	NodeKey string
+	// ProofOfPossession of NodeKey, to be included in the RegisterL1ValidatorTx
+	// of the new registration
+	ProofOfPossession *signer.ProofOfPossession
+	// Weight of the new registration
+	Weight uint64
+	// NewValidationID of the new registration, once initiated
+	NewValidationID ids.ID
+	Steps           []InitializationStep
+	// TxHashes of the contract txs executed
+	TxHashes []common.Hash
+}
+
+// NoOp tells if no refresh step was executed
+func (r BLSKeyRefreshReport) NoOp() bool {
+	for _, step := range r.Steps {
+		if step.Status == StepExecuted {
+			return false
+		}
+	}
+	return true
+}
+
+func (r BLSKeyRefreshReport) String() string {
+	lines := []string{fmt.Sprintf("bls key refresh of validator %s (node %s):", r.ValidationID, r.NodeID)}
+	for _, step := range r.Steps {
+		lines = append(lines, "  "+step.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blsKeyRefreshState is the state of a BLS key refresh, read from the contract,
+// the P-Chain and the node
+type blsKeyRefreshState struct {
+	old           ContractValidator
+	onPChain      bool
+	registeredKey string
+	nodeKey       string
+	// new is the validator registered for the node after the old one was
+	// removed. Its status is Unknown if there is none
+	new ContractValidator
+}
+
+// GetNodeProofOfPossession returns the ID of the node at [endpoint] and the proof of
+// possession of its current BLS key
+func GetNodeProofOfPossession(endpoint string) (ids.NodeID, *signer.ProofOfPossession, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	nodeID, pop, err := info.NewClient(endpoint).GetNodeID(ctx)
+	if err != nil {
+		return ids.EmptyNodeID, nil, fmt.Errorf("failure getting node ID from %s: %w", endpoint, err)
+	}
+	if pop == nil {
+		return ids.EmptyNodeID, nil, fmt.Errorf("node %s has no BLS key", nodeID)
+	}
+	return nodeID, pop, nil
+}
+
+// refreshSteps decides the refresh steps to execute, given its [state]. The old
+// validator is removed and the node registered again with its current key, as the
+// manager contracts don't support changing the key of a validator
+func refreshSteps(state blsKeyRefreshState) ([]InitializationStep, error) {
+	if state.onPChain && state.registeredKey == "" {
+		return nil, fmt.Errorf("the P-Chain doesn't report the registered key of validator %s", state.old.ValidationID)
+	}
+	if state.old.Status == Active && state.onPChain && strings.EqualFold(state.registeredKey, state.nodeKey) {
+		return []InitializationStep{{
+			Name:   StepCheckBLSKey,
+			Status: StepAlreadyDone,
+			Detail: "registered key matches the node key",
+		}}, nil
+	}
+	steps := []InitializationStep{}
+	switch state.old.Status {
+	case Active:
+		steps = append(steps, InitializationStep{Name: StepInitiateRemoval, Status: StepExecuted})
+	case PendingRemoved, Completed:
+		steps = append(steps, InitializationStep{Name: StepInitiateRemoval, Status: StepAlreadyDone})
+	default:
+		return nil, fmt.Errorf("validator %s is %s on the contract, not active", state.old.ValidationID, state.old.Status)
+	}
+	if state.old.Status == Completed {
+		steps = append(steps, InitializationStep{Name: StepCompleteRemoval, Status: StepAlreadyDone})
+	} else {
+		steps = append(steps, InitializationStep{
+			Name:   StepCompleteRemoval,
+			Status: StepSkipped,
+			Detail: "needs the P-Chain L1ValidatorRegistrationMessage for the removal",
+		})
+	}
+	switch {
+	case state.new.Status == PendingAdded || state.new.Status == Active:
+		steps = append(steps, InitializationStep{Name: StepRegisterNewKey, Status: StepAlreadyDone})
+	case state.old.Status == Completed:
+		steps = append(steps, InitializationStep{Name: StepRegisterNewKey, Status: StepExecuted})
+	default:
+		steps = append(steps, InitializationStep{
+			Name:   StepRegisterNewKey,
+			Status: StepSkipped,
+			Detail: "waiting for the removal to be completed",
+		})
+	}
+	if state.new.Status == Active {
+		steps = append(steps, InitializationStep{Name: StepCompleteRegistration, Status: StepAlreadyDone})
+	} else {
+		steps = append(steps, InitializationStep{
+			Name:   StepCompleteRegistration,
+			Status: StepSkipped,
+			Detail: "needs the P-Chain L1ValidatorRegistrationMessage for the registration",
+		})
+	}
+	return steps, nil
+}
+
+// RefreshBLSKey checks whether the BLS key the P-Chain has registered for the validator
+// [params.ValidationID] matches the one its node currently reports, and if not, drives
+// the rotation: the validator is removed from the manager contract and the node is
+// registered again with its new key and the same weight.
+//
+// The steps that need P-Chain warp messages are reported as skipped, so the function
+// is meant to be run again after completing them, until the report has no skipped
+// steps. Steps already done are not executed again
+func RefreshBLSKey(params BLSKeyRefreshParams) (BLSKeyRefreshReport, error) {
+	report := BLSKeyRefreshReport{ValidationID: params.ValidationID}
+	state := blsKeyRefreshState{}
+	var err error
+	state.old, err = GetContractValidator(params.RPCURL, params.ManagerAddress, params.ValidationID)
+	if err != nil {
+		return report, err
+	}
+	nodeID, pop, err := GetNodeProofOfPossession(params.NodeEndpoint)
+	if err != nil {
+		return report, err
+	}
+	if state.old.NodeID != ids.EmptyNodeID && state.old.NodeID != nodeID {
+		return report, fmt.Errorf("validator %s is node %s, but %s reports node %s", params.ValidationID, state.old.NodeID, params.NodeEndpoint, nodeID)
+	}
+	report.NodeID = nodeID
+	report.ProofOfPossession = pop
+	state.nodeKey = hexutil.Encode(pop.PublicKey[:])
+	report.NodeKey = state.nodeKey
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	pChainValidator, err := params.Network.GetL1Validator(ctx, params.ValidationID)
+	switch {
+	case errors.Is(err, avalanche.ErrL1ValidatorNotFound):
+	case err != nil:
+		return report, fmt.Errorf("failure getting L1 validator %s: %w", params.ValidationID, err)
+	default:
+		state.onPChain = true
+		state.registeredKey = pChainValidator.PublicKey
+		report.RegisteredKey = pChainValidator.PublicKey
+	}
+	registeredID, err := GetRegisteredValidationID(params.RPCURL, params.ManagerAddress, nodeID)
+	if err != nil {
+		return report, err
+	}
+	if registeredID != ids.Empty && registeredID != params.ValidationID {
+		state.new, err = GetContractValidator(params.RPCURL, params.ManagerAddress, registeredID)
+		if err != nil {
+			return report, err
+		}
+		report.NewValidationID = registeredID
+	}
+	report.Steps, err = refreshSteps(state)
+	if err != nil {
+		return report, err
+	}
+	report.Weight = params.Weight
+	if report.Weight == 0 {
+		report.Weight = state.old.Weight
+	}
+	for i, step := range report.Steps {
+		if step.Status != StepExecuted {
+			continue
+		}
+		var signature string
+		var args []interface{}
+		switch step.Name {
+		case StepInitiateRemoval:
+			signature = "initiateValidatorRemoval(bytes32)"
+			args = []interface{}{[32]byte(params.ValidationID)}
+		case StepRegisterNewKey:
+			if report.Weight == 0 {
+				return report, fmt.Errorf("%w: set it to the weight validator %s had", ErrBLSKeyRefreshWeight, params.ValidationID)
+			}
+			signature = "initiateValidatorRegistration(bytes,bytes,(uint32,address[]),(uint32,address[]),uint64)"
+			args = []interface{}{
+				nodeID.Bytes(),
+				pop.PublicKey[:],
+				params.RemainingBalanceOwner,
+				params.DisableOwner,
+				report.Weight,
+			}
+		}
+		tx, _, err := evm.TxToMethod(params.RPCURL, params.PrivateKey, params.ManagerAddress, nil, signature, args...)
+		if err != nil {
+			report.Steps = report.Steps[:i]
+			return report, fmt.Errorf("failure executing %s for validator %s: %w", step.Name, params.ValidationID, err)
+		}
+		report.TxHashes = append(report.TxHashes, tx.Hash())
+		if step.Name == StepRegisterNewKey {
+			if report.NewValidationID, err = GetRegisteredValidationID(params.RPCURL, params.ManagerAddress, nodeID); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
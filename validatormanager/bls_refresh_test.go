@@ -0,0 +1,83 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func stepStatuses(steps []InitializationStep) map[string]StepStatus {
+	statuses := map[string]StepStatus{}
+	for _, step := range steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
+
+func TestRefreshSteps(t *testing.T) {
+	require := require.New(t)
+	old := ContractValidator{ValidationID: ids.GenerateTestID(), Status: Active, Weight: 100}
+
+	// keys match
+	steps, err := refreshSteps(blsKeyRefreshState{old: old, onPChain: true, registeredKey: "0xAB", nodeKey: "0xab"})
+	require.NoError(err)
+	require.Equal(map[string]StepStatus{StepCheckBLSKey: StepAlreadyDone}, stepStatuses(steps))
+
+	// P-Chain doesn't report the key
+	_, err = refreshSteps(blsKeyRefreshState{old: old, onPChain: true, nodeKey: "0xab"})
+	require.ErrorContains(err, "doesn't report the registered key")
+
+	// key rotated on the node
+	steps, err = refreshSteps(blsKeyRefreshState{old: old, onPChain: true, registeredKey: "0xab", nodeKey: "0xcd"})
+	require.NoError(err)
+	require.Equal(map[string]StepStatus{
+		StepInitiateRemoval:      StepExecuted,
+		StepCompleteRemoval:      StepSkipped,
+		StepRegisterNewKey:       StepSkipped,
+		StepCompleteRegistration: StepSkipped,
+	}, stepStatuses(steps))
+
+	// removal initiated but not completed
+	old.Status = PendingRemoved
+	steps, err = refreshSteps(blsKeyRefreshState{old: old, onPChain: true, registeredKey: "0xab", nodeKey: "0xcd"})
+	require.NoError(err)
+	require.Equal(map[string]StepStatus{
+		StepInitiateRemoval:      StepAlreadyDone,
+		StepCompleteRemoval:      StepSkipped,
+		StepRegisterNewKey:       StepSkipped,
+		StepCompleteRegistration: StepSkipped,
+	}, stepStatuses(steps))
+
+	// removal completed
+	old.Status = Completed
+	steps, err = refreshSteps(blsKeyRefreshState{old: old, nodeKey: "0xcd"})
+	require.NoError(err)
+	require.Equal(map[string]StepStatus{
+		StepInitiateRemoval:      StepAlreadyDone,
+		StepCompleteRemoval:      StepAlreadyDone,
+		StepRegisterNewKey:       StepExecuted,
+		StepCompleteRegistration: StepSkipped,
+	}, stepStatuses(steps))
+
+	// new registration pending and then completed
+	newValidator := ContractValidator{ValidationID: ids.GenerateTestID(), Status: PendingAdded}
+	steps, err = refreshSteps(blsKeyRefreshState{old: old, nodeKey: "0xcd", new: newValidator})
+	require.NoError(err)
+	require.Equal(StepAlreadyDone, stepStatuses(steps)[StepRegisterNewKey])
+	require.Equal(StepSkipped, stepStatuses(steps)[StepCompleteRegistration])
+	newValidator.Status = Active
+	steps, err = refreshSteps(blsKeyRefreshState{old: old, nodeKey: "0xcd", new: newValidator})
+	require.NoError(err)
+	for _, step := range steps {
+		require.Equal(StepAlreadyDone, step.Status, step.Name)
+	}
+
+	// validator that was never active
+	old.Status = PendingAdded
+	_, err = refreshSteps(blsKeyRefreshState{old: old, nodeKey: "0xcd"})
+	require.ErrorContains(err, "not active")
+}
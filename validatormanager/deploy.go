@@ -0,0 +1,99 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ICMInitializable is the constructor param of the manager contracts that tells if
+// they can be initialized directly, or only through a proxy
+type ICMInitializable uint8
+
+const (
+	ICMInitializableAllowed ICMInitializable = iota
+	ICMInitializableDisallowed
+)
+
+// managerConstructorEsp is the constructor signature of the manager contracts
+const managerConstructorEsp = "(uint8)"
+
+// ManagerCreate2Address returns the address DeployManagerCreate2 deploys the manager
+// contract with [bytecode] to with [salt], which is the same on every L1, so it can
+// be computed before the deployment, eg. to be set on the subnet conversion
+func ManagerCreate2Address(
+	bytecode []byte,
+	salt [32]byte,
+	init ICMInitializable,
+) (common.Address, error) {
+	initCode, err := evm.Create2InitCode(bytecode, managerConstructorEsp, uint8(init))
+	if err != nil {
+		return common.Address{}, err
+	}
+	return evm.Create2Address(salt, initCode), nil
+}
+
+// DeployManagerCreate2 deploys the manager contract with [bytecode] on the L1 at [rpcURL]
+// through the CREATE2 deployer, paying with [privateKey], at the address given by
+// ManagerCreate2Address. Returns true if it was already deployed there.
+//
+// The deployer does not become the owner, so the manager must then be initialized with
+// InitializeProofOfAuthority, or through a proxy if [init] is ICMInitializableDisallowed
+func DeployManagerCreate2(
+	rpcURL string,
+	privateKey string,
+	bytecode []byte,
+	salt [32]byte,
+	init ICMInitializable,
+) (common.Address, bool, error) {
+	return evm.DeployContractCreate2(rpcURL, privateKey, salt, bytecode, managerConstructorEsp, uint8(init))
+}
+
+// VerifyManagerCreate2Deployment checks that the manager contract with [bytecode] is
+// deployed at the address given by ManagerCreate2Address, with [deployedBytecode] code if
+// set. As anyone can deploy the contract at that address, its initialization state is
+// returned, so a manager initialized by someone else can be detected
+func VerifyManagerCreate2Deployment(
+	rpcURL string,
+	bytecode []byte,
+	deployedBytecode []byte,
+	salt [32]byte,
+	init ICMInitializable,
+) (common.Address, InitializationState, error) {
+	managerAddress, err := ManagerCreate2Address(bytecode, salt, init)
+	if err != nil {
+		return common.Address{}, InitializationState{}, err
+	}
+	if len(deployedBytecode) > 0 {
+		if err := evm.VerifyContractCode(rpcURL, managerAddress, deployedBytecode); err != nil {
+			return managerAddress, InitializationState{}, err
+		}
+	} else if err := checkDeployed(rpcURL, managerAddress); err != nil {
+		return managerAddress, InitializationState{}, err
+	}
+	state, err := GetInitializationState(rpcURL, managerAddress)
+	if err != nil {
+		return managerAddress, InitializationState{}, err
+	}
+	return managerAddress, state, nil
+}
+
+func checkDeployed(rpcURL string, address common.Address) error {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	deployed, err := evm.ContractAlreadyDeployed(client, address.Hex())
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		return fmt.Errorf("%w at %s", evm.ErrContractNotDeployed, address.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerCreate2Address(t *testing.T) {
+	require := require.New(t)
+	bytecode := []byte("0x608060405234801561001057600080fd5b50")
+	salt := evm.Create2Salt("PoAValidatorManager")
+	address, err := ManagerCreate2Address(bytecode, salt, ICMInitializableAllowed)
+	require.NoError(err)
+	initCode := append(common.FromHex(string(bytecode)), common.LeftPadBytes([]byte{0}, 32)...)
+	require.Equal(crypto.CreateAddress2(evm.Create2DeployerAddress, salt, crypto.Keccak256(initCode)), address)
+
+	disallowed, err := ManagerCreate2Address(bytecode, salt, ICMInitializableDisallowed)
+	require.NoError(err)
+	require.NotEqual(address, disallowed)
+	otherSalt, err := ManagerCreate2Address(bytecode, evm.Create2Salt("other"), ICMInitializableAllowed)
+	require.NoError(err)
+	require.NotEqual(address, otherSalt)
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrNoRPCEndpoints        = errors.New("no RPC endpoints provided")
+	ErrManagerNotInitialized = errors.New("validator manager is not initialized")
+	ErrChurnLimitExceeded    = errors.New("weight change exceeds the churn allowed in an epoch")
+)
+
+// L1Epoch is a churn period of the validator manager: the window over which it limits
+// the weight changed to a percentage of the total weight at the epoch start
+type L1Epoch struct {
+	StartedAt          time.Time
+	Duration           time.Duration
+	MaxChurnPercentage uint8
+	// InitialWeight is the total weight at the epoch start
+	InitialWeight uint64
+	// TotalWeight is the current total weight
+	TotalWeight uint64
+	// ChurnAmount is the weight changed so far in the epoch
+	ChurnAmount uint64
+}
+
+// End is the time at which the epoch ends
+func (e L1Epoch) End() time.Time {
+	return e.StartedAt.Add(e.Duration)
+}
+
+// Active tells if the epoch is still ongoing at [t]. Once it ends, the next weight
+// change starts a new epoch
+func (e L1Epoch) Active(t time.Time) bool {
+	return t.Before(e.End())
+}
+
+// RemainingChurn is the weight that can still be changed at [t] without exceeding the
+// max churn percentage
+func (e L1Epoch) RemainingChurn(t time.Time) uint64 {
+	initialWeight, churnAmount := e.InitialWeight, e.ChurnAmount
+	if !e.Active(t) {
+		initialWeight, churnAmount = e.TotalWeight, 0
+	}
+	allowed := new(big.Int).SetUint64(initialWeight)
+	allowed.Mul(allowed, big.NewInt(int64(e.MaxChurnPercentage)))
+	allowed.Div(allowed, big.NewInt(100))
+	if allowed.Cmp(new(big.Int).SetUint64(churnAmount)) <= 0 {
+		return 0
+	}
+	return allowed.Uint64() - churnAmount
+}
+
+// EpochReader reads the current epoch of a validator manager contract, trying each of
+// its RPC endpoints in order until one succeeds. The epoch duration and max churn
+// percentage are cached, as they can't be changed after the contract initialization
+type EpochReader struct {
+	managerAddress common.Address
+	rpcURLs        []string
+
+	lock               sync.Mutex
+	duration           time.Duration
+	maxChurnPercentage uint8
+}
+
+// NewEpochReader creates an EpochReader for the validator manager at [managerAddress],
+// reachable through any of [rpcURLs]
+func NewEpochReader(managerAddress common.Address, rpcURLs ...string) (*EpochReader, error) {
+	if len(rpcURLs) == 0 {
+		return nil, ErrNoRPCEndpoints
+	}
+	return &EpochReader{
+		managerAddress: managerAddress,
+		rpcURLs:        rpcURLs,
+	}, nil
+}
+
+func (r *EpochReader) readStorage(ctx context.Context, slots ...common.Hash) (map[common.Hash]common.Hash, error) {
+	errs := []error{}
+	for _, rpcURL := range r.rpcURLs {
+		values, err := readStorage(ctx, rpcURL, r.managerAddress, slots...)
+		if err == nil {
+			return values, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", rpcURL, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (r *EpochReader) epochSettings(ctx context.Context) (time.Duration, uint8, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.duration != 0 {
+		return r.duration, r.maxChurnPercentage, nil
+	}
+	slot := slotAt(validatorManagerStorageLocation, churnSettingsOffset)
+	values, err := r.readStorage(ctx, slot)
+	if err != nil {
+		return 0, 0, err
+	}
+	duration := time.Duration(uintAt(values[slot], 0, 8)) * time.Second
+	if duration == 0 {
+		return 0, 0, fmt.Errorf("%w: %s", ErrManagerNotInitialized, r.managerAddress.Hex())
+	}
+	r.duration = duration
+	r.maxChurnPercentage = uint8(uintAt(values[slot], 8, 1))
+	return r.duration, r.maxChurnPercentage, nil
+}
+
+// GetCurrentL1Epoch returns the last epoch started by the validator manager at
+// [managerAddress], trying each of [rpcURLs] in order until one succeeds
+func GetCurrentL1Epoch(ctx context.Context, managerAddress common.Address, rpcURLs ...string) (L1Epoch, error) {
+	reader, err := NewEpochReader(managerAddress, rpcURLs...)
+	if err != nil {
+		return L1Epoch{}, err
+	}
+	return reader.GetCurrentEpoch(ctx)
+}
+
+// GetCurrentEpoch returns the last epoch started by the validator manager
+func (r *EpochReader) GetCurrentEpoch(ctx context.Context) (L1Epoch, error) {
+	duration, maxChurnPercentage, err := r.epochSettings(ctx)
+	if err != nil {
+		return L1Epoch{}, err
+	}
+	startedAtSlot := slotAt(validatorManagerStorageLocation, churnStartedAtOffset)
+	weightsSlot := slotAt(validatorManagerStorageLocation, churnWeightsOffset)
+	values, err := r.readStorage(ctx, startedAtSlot, weightsSlot)
+	if err != nil {
+		return L1Epoch{}, err
+	}
+	return parseEpoch(values[startedAtSlot], values[weightsSlot], duration, maxChurnPercentage), nil
+}
+
+func parseEpoch(startedAt common.Hash, weights common.Hash, duration time.Duration, maxChurnPercentage uint8) L1Epoch {
+	return L1Epoch{
+		StartedAt:          time.Unix(int64(uintAt(startedAt, 0, 8)), 0),
+		Duration:           duration,
+		MaxChurnPercentage: maxChurnPercentage,
+		InitialWeight:      uintAt(weights, 0, 8),
+		TotalWeight:        uintAt(weights, 8, 8),
+		ChurnAmount:        uintAt(weights, 16, 8),
+	}
+}
+
+// WaitForNextEpoch waits until the current epoch ends, so the next weight change
+// starts a new one, and returns the ended epoch. It returns immediately if the
+// current epoch already ended
+func (r *EpochReader) WaitForNextEpoch(ctx context.Context) (L1Epoch, error) {
+	epoch, err := r.GetCurrentEpoch(ctx)
+	if err != nil {
+		return L1Epoch{}, err
+	}
+	if !epoch.Active(time.Now()) {
+		return epoch, nil
+	}
+	timer := time.NewTimer(time.Until(epoch.End()))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return L1Epoch{}, ctx.Err()
+	case <-timer.C:
+		return epoch, nil
+	}
+}
+
+// WaitForChurnCapacity waits until a change of [weight] fits in the churn allowed by
+// the validator manager, waiting for the next epoch if the current one has not enough
+// remaining churn. It is meant to schedule weight changes that would otherwise be
+// rejected by the contract. Changes that don't fit even in a new epoch return
+// ErrChurnLimitExceeded, as they must be split
+func (r *EpochReader) WaitForChurnCapacity(ctx context.Context, weight uint64) (L1Epoch, error) {
+	epoch, err := r.GetCurrentEpoch(ctx)
+	if err != nil {
+		return L1Epoch{}, err
+	}
+	now := time.Now()
+	if weight <= epoch.RemainingChurn(now) {
+		return epoch, nil
+	}
+	if weight > epoch.RemainingChurn(epoch.End()) {
+		return epoch, fmt.Errorf("%w: %d over %d", ErrChurnLimitExceeded, weight, epoch.RemainingChurn(epoch.End()))
+	}
+	return r.WaitForNextEpoch(ctx)
+}
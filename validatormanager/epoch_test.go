@@ -0,0 +1,110 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStorageRPC serves eth_getStorageAt from [storage], counting the reads of each slot
+func fakeStorageRPC(t *testing.T, storage map[common.Hash]common.Hash, reads map[common.Hash]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "eth_getStorageAt", req.Method)
+		var slot common.Hash
+		require.NoError(t, json.Unmarshal(req.Params[1], &slot))
+		reads[slot]++
+		value := storage[slot]
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  value.Hex(),
+		}))
+	}))
+}
+
+func packedUints(values ...uint64) common.Hash {
+	packed := common.Hash{}
+	for i, value := range values {
+		end := common.HashLength - 8*i
+		copy(packed[end-8:end], common.BigToHash(new(big.Int).SetUint64(value)).Bytes()[24:])
+	}
+	return packed
+}
+
+func TestEpochReader(t *testing.T) {
+	require := require.New(t)
+	startedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	settingsSlot := slotAt(validatorManagerStorageLocation, churnSettingsOffset)
+	churnSettings := packedUints(3600)
+	churnSettings[23] = 20
+	storage := map[common.Hash]common.Hash{
+		settingsSlot: churnSettings,
+		slotAt(validatorManagerStorageLocation, churnStartedAtOffset): common.BigToHash(big.NewInt(startedAt.Unix())),
+		slotAt(validatorManagerStorageLocation, churnWeightsOffset):   packedUints(1000, 1050, 150),
+	}
+	reads := map[common.Hash]int{}
+	server := fakeStorageRPC(t, storage, reads)
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	_, err := NewEpochReader(common.Address{})
+	require.ErrorIs(err, ErrNoRPCEndpoints)
+	reader, err := NewEpochReader(common.Address{}, down.URL, server.URL)
+	require.NoError(err)
+
+	ctx := context.Background()
+	epoch, err := reader.GetCurrentEpoch(ctx)
+	require.NoError(err)
+	require.Equal(L1Epoch{
+		StartedAt:          startedAt,
+		Duration:           time.Hour,
+		MaxChurnPercentage: 20,
+		InitialWeight:      1000,
+		TotalWeight:        1050,
+		ChurnAmount:        150,
+	}, epoch)
+	require.True(epoch.Active(time.Now()))
+	require.Equal(uint64(50), epoch.RemainingChurn(time.Now()))
+	require.Equal(uint64(210), epoch.RemainingChurn(epoch.End()))
+
+	_, err = reader.GetCurrentEpoch(ctx)
+	require.NoError(err)
+	require.Equal(1, reads[settingsSlot])
+
+	epoch, err = reader.WaitForChurnCapacity(ctx, 50)
+	require.NoError(err)
+	require.Equal(startedAt, epoch.StartedAt)
+	_, err = reader.WaitForChurnCapacity(ctx, 211)
+	require.ErrorIs(err, ErrChurnLimitExceeded)
+
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = reader.WaitForChurnCapacity(cancelled, 100)
+	require.ErrorIs(err, context.DeadlineExceeded)
+
+	// the next weight change starts a new epoch
+	storage[slotAt(validatorManagerStorageLocation, churnStartedAtOffset)] = common.BigToHash(big.NewInt(startedAt.Add(-time.Hour).Unix()))
+	epoch, err = reader.WaitForNextEpoch(ctx)
+	require.NoError(err)
+	require.False(epoch.Active(time.Now()))
+
+	_, err = GetCurrentL1Epoch(ctx, common.Address{}, down.URL)
+	require.Error(err)
+}
@@ -0,0 +1,350 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrInvalidPoASettings           = errors.New("invalid proof of authority settings")
+	ErrInitializedWithOtherSettings = errors.New("validator manager is already initialized with different settings")
+	ErrInitializersDisabled         = errors.New("validator manager initializers are disabled")
+)
+
+// Storage locations of the manager contract state, following ERC-7201
+var (
+	initializableStorageLocation    = erc7201Slot("openzeppelin.storage.Initializable")
+	ownableStorageLocation          = erc7201Slot("openzeppelin.storage.Ownable")
+	validatorManagerStorageLocation = erc7201Slot("avalanche-icm.storage.ValidatorManager")
+)
+
+// Offsets of the ValidatorManagerStorage fields used, from validatorManagerStorageLocation
+const (
+	subnetIDOffset                = 0
+	churnSettingsOffset           = 1
+	churnStartedAtOffset          = 2
+	churnWeightsOffset            = 3
+	initializedValidatorSetOffset = 7
+)
+
+// erc7201Slot is the storage location of [namespace], as defined by ERC-7201:
+// keccak256(abi.encode(uint256(keccak256(namespace)) - 1)) & ~bytes32(uint256(0xff))
+func erc7201Slot(namespace string) common.Hash {
+	id := new(big.Int).SetBytes(crypto.Keccak256([]byte(namespace)))
+	id.Sub(id, big.NewInt(1))
+	slot := crypto.Keccak256Hash(common.BigToHash(id).Bytes())
+	slot[common.HashLength-1] = 0
+	return slot
+}
+
+func slotAt(location common.Hash, offset int64) common.Hash {
+	return common.BigToHash(new(big.Int).Add(location.Big(), big.NewInt(offset)))
+}
+
+// PoASettings are the params a proof of authority validator manager is initialized with
+type PoASettings struct {
+	SubnetID ids.ID
+	// ChurnPeriod is the period over which MaxChurnPercentage is enforced. It is
+	// rounded to seconds
+	ChurnPeriod time.Duration
+	// MaxChurnPercentage of the total weight that can change in a churn period
+	MaxChurnPercentage uint8
+	// Owner is allowed to add and remove validators. Defaults to the address of the
+	// key that initializes the contract
+	Owner common.Address
+}
+
+func (s PoASettings) validate() error {
+	switch {
+	case s.SubnetID == ids.Empty:
+		return fmt.Errorf("%w: subnet ID is not set", ErrInvalidPoASettings)
+	case s.MaxChurnPercentage == 0 || s.MaxChurnPercentage > 100:
+		return fmt.Errorf("%w: max churn percentage must be between 1 and 100, got %d", ErrInvalidPoASettings, s.MaxChurnPercentage)
+	case s.ChurnPeriod < time.Second:
+		return fmt.Errorf("%w: churn period must be at least one second, got %s", ErrInvalidPoASettings, s.ChurnPeriod)
+	}
+	return nil
+}
+
+func (s PoASettings) churnPeriodSeconds() uint64 {
+	return uint64(s.ChurnPeriod / time.Second)
+}
+
+// InitializationState is the initialization progress of a validator manager contract,
+// read from its storage
+type InitializationState struct {
+	// Initialized tells if initialize was called. The fields below it are only set if so
+	Initialized bool
+	// InitializersDisabled is set if the contract is an implementation that can only
+	// be initialized through a proxy
+	InitializersDisabled bool
+	SubnetID             ids.ID
+	ChurnPeriodSeconds   uint64
+	MaxChurnPercentage   uint8
+	Owner                common.Address
+	// ValidatorSetInitialized tells if the initial validator set, resulting from the
+	// subnet conversion, was registered
+	ValidatorSetInitialized bool
+	// TotalWeight of the registered validator set
+	TotalWeight uint64
+}
+
+// stateSlots are the storage slots GetInitializationState reads
+func stateSlots() []common.Hash {
+	return []common.Hash{
+		initializableStorageLocation,
+		ownableStorageLocation,
+		slotAt(validatorManagerStorageLocation, subnetIDOffset),
+		slotAt(validatorManagerStorageLocation, churnSettingsOffset),
+		slotAt(validatorManagerStorageLocation, churnWeightsOffset),
+		slotAt(validatorManagerStorageLocation, initializedValidatorSetOffset),
+	}
+}
+
+// uintAt returns the [size] bytes unsigned integer packed in [value] at [offset] bytes
+// from its lower order end, as solidity packs storage
+func uintAt(value common.Hash, offset int, size int) uint64 {
+	end := common.HashLength - offset
+	return new(big.Int).SetBytes(value[end-size : end]).Uint64()
+}
+
+// parseInitializationState decodes the values of the stateSlots
+func parseInitializationState(values map[common.Hash]common.Hash) InitializationState {
+	get := func(location common.Hash, offset int64) common.Hash {
+		return values[slotAt(location, offset)]
+	}
+	initializedVersion := uintAt(values[initializableStorageLocation], 0, 8)
+	state := InitializationState{
+		Initialized:          initializedVersion != 0,
+		InitializersDisabled: initializedVersion == math.MaxUint64,
+	}
+	if !state.Initialized || state.InitializersDisabled {
+		return state
+	}
+	churnSettings := get(validatorManagerStorageLocation, churnSettingsOffset)
+	state.SubnetID = ids.ID(get(validatorManagerStorageLocation, subnetIDOffset))
+	state.ChurnPeriodSeconds = uintAt(churnSettings, 0, 8)
+	state.MaxChurnPercentage = uint8(uintAt(churnSettings, 8, 1))
+	state.Owner = common.BytesToAddress(values[ownableStorageLocation].Bytes())
+	state.ValidatorSetInitialized = uintAt(get(validatorManagerStorageLocation, initializedValidatorSetOffset), 0, 1) != 0
+	state.TotalWeight = uintAt(get(validatorManagerStorageLocation, churnWeightsOffset), 8, 8)
+	return state
+}
+
+// GetInitializationState reads the initialization progress of the proof of authority
+// validator manager contract at [managerAddress], on the L1 at [rpcURL]
+func GetInitializationState(
+	rpcURL string,
+	managerAddress common.Address,
+) (InitializationState, error) {
+	ctx, cancel := utils.GetAPILargeContext()
+	defer cancel()
+	values, err := readStorage(ctx, rpcURL, managerAddress, stateSlots()...)
+	if err != nil {
+		return InitializationState{}, err
+	}
+	return parseInitializationState(values), nil
+}
+
+// readStorage returns the values of [slots] of the contract at [managerAddress]
+func readStorage(
+	ctx context.Context,
+	rpcURL string,
+	managerAddress common.Address,
+	slots ...common.Hash,
+) (map[common.Hash]common.Hash, error) {
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	values := map[common.Hash]common.Hash{}
+	for _, slot := range slots {
+		value, err := client.StorageAt(ctx, managerAddress, slot, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failure reading storage of validator manager %s: %w", managerAddress.Hex(), err)
+		}
+		values[slot] = common.BytesToHash(value)
+	}
+	return values, nil
+}
+
+// StepStatus is the outcome of an initialization step
+type StepStatus string
+
+const (
+	StepExecuted    StepStatus = "executed"
+	StepAlreadyDone StepStatus = "already done"
+	StepSkipped     StepStatus = "skipped"
+)
+
+const (
+	StepInitialize             = "initialize"
+	StepInitializeValidatorSet = "initialize validator set"
+)
+
+// InitializationStep is an initialization step and its outcome
+type InitializationStep struct {
+	Name   string
+	Status StepStatus
+	Detail string
+}
+
+func (s InitializationStep) String() string {
+	if s.Detail == "" {
+		return fmt.Sprintf("%s: %s", s.Name, s.Status)
+	}
+	return fmt.Sprintf("%s: %s (%s)", s.Name, s.Status, s.Detail)
+}
+
+// InitializationReport is the result of InitializeProofOfAuthority
+type InitializationReport struct {
+	ManagerAddress common.Address
+	// State of the contract before any step was executed
+	State InitializationState
+	Steps []InitializationStep
+	// TxHash of the initialize tx, if it was executed
+	TxHash common.Hash
+}
+
+// NoOp tells if the contract was already in the desired state, so no step was executed
+func (r InitializationReport) NoOp() bool {
+	for _, step := range r.Steps {
+		if step.Status == StepExecuted {
+			return false
+		}
+	}
+	return true
+}
+
+func (r InitializationReport) String() string {
+	lines := []string{fmt.Sprintf("validator manager %s:", r.ManagerAddress.Hex())}
+	for _, step := range r.Steps {
+		lines = append(lines, "  "+step.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// initializeStep decides whether initialize must be called, given the contract [state]
+// and the desired [settings]. Settings that differ from the stored ones are an error, as
+// the contract can't be initialized twice
+func initializeStep(state InitializationState, settings PoASettings) (InitializationStep, error) {
+	step := InitializationStep{Name: StepInitialize}
+	if state.InitializersDisabled {
+		return step, fmt.Errorf("%w: the address is probably the implementation behind the manager proxy", ErrInitializersDisabled)
+	}
+	if !state.Initialized {
+		step.Status = StepExecuted
+		return step, nil
+	}
+	diffs := []string{}
+	if state.SubnetID != settings.SubnetID {
+		diffs = append(diffs, fmt.Sprintf("subnet ID is %s, not %s", state.SubnetID, settings.SubnetID))
+	}
+	if state.ChurnPeriodSeconds != settings.churnPeriodSeconds() {
+		diffs = append(diffs, fmt.Sprintf("churn period is %ds, not %ds", state.ChurnPeriodSeconds, settings.churnPeriodSeconds()))
+	}
+	if state.MaxChurnPercentage != settings.MaxChurnPercentage {
+		diffs = append(diffs, fmt.Sprintf("max churn percentage is %d, not %d", state.MaxChurnPercentage, settings.MaxChurnPercentage))
+	}
+	if state.Owner != settings.Owner {
+		diffs = append(diffs, fmt.Sprintf("owner is %s, not %s", state.Owner.Hex(), settings.Owner.Hex()))
+	}
+	if len(diffs) > 0 {
+		return step, fmt.Errorf("%w: %s", ErrInitializedWithOtherSettings, strings.Join(diffs, ", "))
+	}
+	step.Status = StepAlreadyDone
+	return step, nil
+}
+
+func validatorSetStep(state InitializationState) InitializationStep {
+	step := InitializationStep{Name: StepInitializeValidatorSet}
+	if state.ValidatorSetInitialized {
+		step.Status = StepAlreadyDone
+		step.Detail = fmt.Sprintf("total weight %d", state.TotalWeight)
+		return step
+	}
+	// registering the initial validator set needs the warp message of the subnet
+	// conversion, which is not available for the network versions supported by
+	// this SDK
+	step.Status = StepSkipped
+	step.Detail = "needs the subnet conversion warp message"
+	return step
+}
+
+type poaSettingsInput struct {
+	SubnetID               [32]byte
+	ChurnPeriodSeconds     uint64
+	MaximumChurnPercentage uint8
+}
+
+// InitializeProofOfAuthority initializes the proof of authority validator manager
+// contract at [managerAddress], on the L1 at [rpcURL], with [settings], paying with
+// [privateKey].
+//
+// The contract state is read first, so the function can be safely run again: steps
+// already done are not executed, and the report tells which ones were executed,
+// already done, or skipped. If the contract was already initialized with different
+// settings, ErrInitializedWithOtherSettings is returned
+func InitializeProofOfAuthority(
+	rpcURL string,
+	managerAddress common.Address,
+	privateKey string,
+	settings PoASettings,
+) (InitializationReport, error) {
+	report := InitializationReport{ManagerAddress: managerAddress}
+	if settings.Owner == (common.Address{}) {
+		pk, err := crypto.HexToECDSA(privateKey)
+		if err != nil {
+			return report, err
+		}
+		settings.Owner = crypto.PubkeyToAddress(pk.PublicKey)
+	}
+	if err := settings.validate(); err != nil {
+		return report, err
+	}
+	state, err := GetInitializationState(rpcURL, managerAddress)
+	if err != nil {
+		return report, err
+	}
+	report.State = state
+	step, err := initializeStep(state, settings)
+	if err != nil {
+		return report, err
+	}
+	if step.Status == StepExecuted {
+		tx, _, err := evm.TxToMethod(
+			rpcURL,
+			privateKey,
+			managerAddress,
+			nil,
+			"initialize((bytes32,uint64,uint8),address)",
+			poaSettingsInput{
+				SubnetID:               settings.SubnetID,
+				ChurnPeriodSeconds:     settings.churnPeriodSeconds(),
+				MaximumChurnPercentage: settings.MaxChurnPercentage,
+			},
+			settings.Owner,
+		)
+		if err != nil {
+			return report, fmt.Errorf("failure initializing validator manager %s: %w", managerAddress.Hex(), err)
+		}
+		report.TxHash = tx.Hash()
+	}
+	report.Steps = append(report.Steps, step, validatorSetStep(state))
+	return report, nil
+}
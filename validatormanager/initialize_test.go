@@ -0,0 +1,118 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC7201Slot(t *testing.T) {
+	require := require.New(t)
+	require.Equal(
+		common.HexToHash("0xf0c57e16840df040f15088dc2f81fe391c3923bec73e23a9662efc9c229c6a00"),
+		initializableStorageLocation,
+	)
+	require.Equal(
+		common.HexToHash("0x9016d09d72d40fdae2fd8ceac6b6234c7706214fd39c1cd1e609a0528c199300"),
+		ownableStorageLocation,
+	)
+}
+
+func TestParseInitializationState(t *testing.T) {
+	require := require.New(t)
+	subnetID := ids.GenerateTestID()
+	owner := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+
+	require.Equal(InitializationState{}, parseInitializationState(map[common.Hash]common.Hash{}))
+	require.Equal(
+		InitializationState{Initialized: true, InitializersDisabled: true},
+		parseInitializationState(map[common.Hash]common.Hash{
+			initializableStorageLocation: common.BigToHash(new(big.Int).SetUint64(math.MaxUint64)),
+		}),
+	)
+
+	// churn period 3600s and max churn 20% are packed in one slot, as the initial,
+	// total and churn weights are
+	churnSettings := common.Hash{}
+	churnSettings[23] = 20
+	copy(churnSettings[24:], common.BigToHash(big.NewInt(3600)).Bytes()[24:])
+	churnWeights := common.Hash{}
+	copy(churnWeights[16:24], common.BigToHash(big.NewInt(300)).Bytes()[24:])
+	copy(churnWeights[24:], common.BigToHash(big.NewInt(100)).Bytes()[24:])
+	values := map[common.Hash]common.Hash{
+		initializableStorageLocation:                                 common.BigToHash(big.NewInt(1)),
+		ownableStorageLocation:                                       common.BytesToHash(owner.Bytes()),
+		slotAt(validatorManagerStorageLocation, subnetIDOffset):      common.Hash(subnetID),
+		slotAt(validatorManagerStorageLocation, churnSettingsOffset): churnSettings,
+		slotAt(validatorManagerStorageLocation, churnWeightsOffset):  churnWeights,
+	}
+	expected := InitializationState{
+		Initialized:        true,
+		SubnetID:           subnetID,
+		ChurnPeriodSeconds: 3600,
+		MaxChurnPercentage: 20,
+		Owner:              owner,
+		TotalWeight:        300,
+	}
+	require.Equal(expected, parseInitializationState(values))
+
+	values[slotAt(validatorManagerStorageLocation, initializedValidatorSetOffset)] = common.BigToHash(big.NewInt(1))
+	expected.ValidatorSetInitialized = true
+	require.Equal(expected, parseInitializationState(values))
+	require.Len(stateSlots(), 6)
+}
+
+func TestInitializeSteps(t *testing.T) {
+	require := require.New(t)
+	settings := PoASettings{
+		SubnetID:           ids.GenerateTestID(),
+		ChurnPeriod:        time.Hour,
+		MaxChurnPercentage: 20,
+		Owner:              common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"),
+	}
+	require.NoError(settings.validate())
+	invalid := settings
+	invalid.MaxChurnPercentage = 101
+	require.ErrorIs(invalid.validate(), ErrInvalidPoASettings)
+
+	step, err := initializeStep(InitializationState{}, settings)
+	require.NoError(err)
+	require.Equal(StepExecuted, step.Status)
+	require.Equal(StepSkipped, validatorSetStep(InitializationState{}).Status)
+
+	_, err = initializeStep(InitializationState{Initialized: true, InitializersDisabled: true}, settings)
+	require.ErrorIs(err, ErrInitializersDisabled)
+
+	state := InitializationState{
+		Initialized:             true,
+		SubnetID:                settings.SubnetID,
+		ChurnPeriodSeconds:      3600,
+		MaxChurnPercentage:      20,
+		Owner:                   settings.Owner,
+		ValidatorSetInitialized: true,
+		TotalWeight:             300,
+	}
+	step, err = initializeStep(state, settings)
+	require.NoError(err)
+	report := InitializationReport{Steps: []InitializationStep{step, validatorSetStep(state)}}
+	require.True(report.NoOp())
+	require.Equal(
+		"validator manager 0x0000000000000000000000000000000000000000:\n"+
+			"  initialize: already done\n"+
+			"  initialize validator set: already done (total weight 300)",
+		report.String(),
+	)
+
+	state.MaxChurnPercentage = 10
+	_, err = initializeStep(state, settings)
+	require.ErrorIs(err, ErrInitializedWithOtherSettings)
+	require.ErrorContains(err, "max churn percentage is 10, not 20")
+}
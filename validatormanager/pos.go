@@ -0,0 +1,320 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// maxDelegationFeeBips is the delegation fee denominator of the staking manager
+	maxDelegationFeeBips = 10_000
+	// maxStakeMultiplierLimit is the highest maximum stake multiplier the staking
+	// manager accepts
+	maxStakeMultiplierLimit = 10
+)
+
+var (
+	ErrInvalidPoSSettings     = errors.New("invalid proof of stake settings")
+	ErrNotManagerOwner        = errors.New("key is not the owner of the validator manager")
+	ErrPoSParamNotUpdatable   = errors.New("proof of stake param can't be updated on this validator manager")
+	ErrPoSUpdateNotApplied    = errors.New("proof of stake param update was not applied")
+	ErrEmptyPoSSettingsUpdate = errors.New("no proof of stake param to update")
+)
+
+var posValidatorManagerStorageLocation = erc7201Slot("avalanche-icm.storage.PoSValidatorManager")
+
+// Offsets of the PoSValidatorManagerStorage fields, from posValidatorManagerStorageLocation
+const (
+	minimumStakeAmountOffset  = 0
+	maximumStakeAmountOffset  = 1
+	stakeSettingsOffset       = 2
+	weightToValueFactorOffset = 3
+	rewardCalculatorOffset    = 4
+	uptimeBlockchainIDOffset  = 5
+)
+
+// PoSSettings are the economics of a proof of stake validator manager, read from its
+// storage. Amounts are in the smallest unit of the staked token
+type PoSSettings struct {
+	MinimumStakeAmount       *big.Int
+	MaximumStakeAmount       *big.Int
+	MinimumStakeDuration     time.Duration
+	MinimumDelegationFeeBips uint16
+	MaximumStakeMultiplier   uint8
+	WeightToValueFactor      *big.Int
+	RewardCalculator         common.Address
+	UptimeBlockchainID       ids.ID
+}
+
+func posSlots() []common.Hash {
+	slots := []common.Hash{}
+	for offset := int64(minimumStakeAmountOffset); offset <= uptimeBlockchainIDOffset; offset++ {
+		slots = append(slots, slotAt(posValidatorManagerStorageLocation, offset))
+	}
+	return slots
+}
+
+func parsePoSSettings(values map[common.Hash]common.Hash) PoSSettings {
+	get := func(offset int64) common.Hash {
+		return values[slotAt(posValidatorManagerStorageLocation, offset)]
+	}
+	stakeSettings := get(stakeSettingsOffset)
+	return PoSSettings{
+		MinimumStakeAmount:       get(minimumStakeAmountOffset).Big(),
+		MaximumStakeAmount:       get(maximumStakeAmountOffset).Big(),
+		MinimumStakeDuration:     time.Duration(uintAt(stakeSettings, 0, 8)) * time.Second,
+		MinimumDelegationFeeBips: uint16(uintAt(stakeSettings, 8, 2)),
+		MaximumStakeMultiplier:   uint8(uintAt(stakeSettings, 10, 1)),
+		WeightToValueFactor:      get(weightToValueFactorOffset).Big(),
+		RewardCalculator:         common.BytesToAddress(get(rewardCalculatorOffset).Bytes()),
+		UptimeBlockchainID:       ids.ID(get(uptimeBlockchainIDOffset)),
+	}
+}
+
+// GetPoSSettings reads the settings of the proof of stake validator manager contract
+// at [managerAddress], on the L1 at [rpcURL]. Returns ErrManagerNotInitialized if the
+// contract has no staking settings
+func GetPoSSettings(rpcURL string, managerAddress common.Address) (PoSSettings, error) {
+	ctx, cancel := utils.GetAPILargeContext()
+	defer cancel()
+	values, err := readStorage(ctx, rpcURL, managerAddress, posSlots()...)
+	if err != nil {
+		return PoSSettings{}, err
+	}
+	settings := parsePoSSettings(values)
+	if settings.WeightToValueFactor.Sign() == 0 {
+		return settings, fmt.Errorf("%w: %s has no proof of stake settings", ErrManagerNotInitialized, managerAddress.Hex())
+	}
+	return settings, nil
+}
+
+// PoSSettingsUpdate lists the proof of stake params to change. Nil fields are kept.
+// The weight to value factor and the uptime blockchain ID are not included, as
+// changing them would invalidate the accounting of the existing stakes
+type PoSSettingsUpdate struct {
+	MinimumStakeAmount       *big.Int
+	MaximumStakeAmount       *big.Int
+	MinimumStakeDuration     *time.Duration
+	MinimumDelegationFeeBips *uint16
+	MaximumStakeMultiplier   *uint8
+	RewardCalculator         *common.Address
+}
+
+// posParamUpdate is the change of a single param, made with [method]
+type posParamUpdate struct {
+	name    string
+	method  string
+	value   interface{}
+	current string
+	desired string
+}
+
+// changes returns the updates to apply over [settings], skipping the params that
+// already have the desired value
+func (u PoSSettingsUpdate) changes(settings PoSSettings) ([]posParamUpdate, []string) {
+	changes := []posParamUpdate{}
+	unchanged := []string{}
+	add := func(name string, method string, value interface{}, current string, desired string) {
+		if current == desired {
+			unchanged = append(unchanged, name)
+			return
+		}
+		changes = append(changes, posParamUpdate{name: name, method: method, value: value, current: current, desired: desired})
+	}
+	if u.MinimumStakeAmount != nil {
+		add("minimum stake amount", "setMinimumStakeAmount(uint256)", u.MinimumStakeAmount,
+			settings.MinimumStakeAmount.String(), u.MinimumStakeAmount.String())
+	}
+	if u.MaximumStakeAmount != nil {
+		add("maximum stake amount", "setMaximumStakeAmount(uint256)", u.MaximumStakeAmount,
+			settings.MaximumStakeAmount.String(), u.MaximumStakeAmount.String())
+	}
+	if u.MinimumStakeDuration != nil {
+		seconds := uint64(*u.MinimumStakeDuration / time.Second)
+		add("minimum stake duration", "setMinimumStakeDuration(uint64)", seconds,
+			settings.MinimumStakeDuration.String(), (time.Duration(seconds) * time.Second).String())
+	}
+	if u.MinimumDelegationFeeBips != nil {
+		add("minimum delegation fee", "setMinimumDelegationFeeBips(uint16)", *u.MinimumDelegationFeeBips,
+			fmt.Sprintf("%d bips", settings.MinimumDelegationFeeBips), fmt.Sprintf("%d bips", *u.MinimumDelegationFeeBips))
+	}
+	if u.MaximumStakeMultiplier != nil {
+		add("maximum stake multiplier", "setMaximumStakeMultiplier(uint8)", *u.MaximumStakeMultiplier,
+			fmt.Sprintf("%d", settings.MaximumStakeMultiplier), fmt.Sprintf("%d", *u.MaximumStakeMultiplier))
+	}
+	if u.RewardCalculator != nil {
+		add("reward calculator", "setRewardCalculator(address)", *u.RewardCalculator,
+			settings.RewardCalculator.Hex(), u.RewardCalculator.Hex())
+	}
+	return changes, unchanged
+}
+
+// apply returns [settings] with the update applied
+func (u PoSSettingsUpdate) apply(settings PoSSettings) PoSSettings {
+	if u.MinimumStakeAmount != nil {
+		settings.MinimumStakeAmount = u.MinimumStakeAmount
+	}
+	if u.MaximumStakeAmount != nil {
+		settings.MaximumStakeAmount = u.MaximumStakeAmount
+	}
+	if u.MinimumStakeDuration != nil {
+		settings.MinimumStakeDuration = *u.MinimumStakeDuration / time.Second * time.Second
+	}
+	if u.MinimumDelegationFeeBips != nil {
+		settings.MinimumDelegationFeeBips = *u.MinimumDelegationFeeBips
+	}
+	if u.MaximumStakeMultiplier != nil {
+		settings.MaximumStakeMultiplier = *u.MaximumStakeMultiplier
+	}
+	if u.RewardCalculator != nil {
+		settings.RewardCalculator = *u.RewardCalculator
+	}
+	return settings
+}
+
+// validate checks [s] with the same rules the staking manager initialization uses
+func (s PoSSettings) validate() error {
+	switch {
+	case s.MinimumStakeAmount == nil || s.MaximumStakeAmount == nil:
+		return fmt.Errorf("%w: stake amounts are not set", ErrInvalidPoSSettings)
+	case s.MinimumStakeAmount.Cmp(s.MaximumStakeAmount) > 0:
+		return fmt.Errorf("%w: minimum stake amount %s is greater than the maximum %s", ErrInvalidPoSSettings, s.MinimumStakeAmount, s.MaximumStakeAmount)
+	case s.MinimumDelegationFeeBips == 0 || s.MinimumDelegationFeeBips > maxDelegationFeeBips:
+		return fmt.Errorf("%w: minimum delegation fee must be between 1 and %d bips, got %d", ErrInvalidPoSSettings, maxDelegationFeeBips, s.MinimumDelegationFeeBips)
+	case s.MaximumStakeMultiplier == 0 || s.MaximumStakeMultiplier > maxStakeMultiplierLimit:
+		return fmt.Errorf("%w: maximum stake multiplier must be between 1 and %d, got %d", ErrInvalidPoSSettings, maxStakeMultiplierLimit, s.MaximumStakeMultiplier)
+	case s.MinimumStakeDuration < time.Second:
+		return fmt.Errorf("%w: minimum stake duration must be at least one second, got %s", ErrInvalidPoSSettings, s.MinimumStakeDuration)
+	case s.RewardCalculator == (common.Address{}):
+		return fmt.Errorf("%w: reward calculator is not set", ErrInvalidPoSSettings)
+	}
+	return nil
+}
+
+// PoSUpdateReport is the result of UpdatePoSSettings
+type PoSUpdateReport struct {
+	ManagerAddress common.Address
+	// Before and After are the settings read before and after the update
+	Before PoSSettings
+	After  PoSSettings
+	// Steps has one step for each param of the update
+	Steps []InitializationStep
+	// TxHashes of the executed updates
+	TxHashes []common.Hash
+}
+
+// NoOp tells if all the params already had the desired value
+func (r PoSUpdateReport) NoOp() bool {
+	return len(r.TxHashes) == 0
+}
+
+func (r PoSUpdateReport) String() string {
+	lines := []string{fmt.Sprintf("validator manager %s:", r.ManagerAddress.Hex())}
+	for _, step := range r.Steps {
+		lines = append(lines, "  "+step.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// UpdatePoSSettings changes the proof of stake params in [update] of the validator
+// manager at [managerAddress], on the L1 at [rpcURL], with txs signed by [privateKey],
+// that must be the key of the contract owner.
+//
+// The standard staking manager only sets its params on initialization, so this is
+// meant for managers whose implementation adds owner only setters for them, eg. after
+// an upgrade. Updates the contract rejects return ErrPoSParamNotUpdatable. The
+// resulting settings are checked before any tx is sent, params that already have the
+// desired value are skipped, and the settings are read again at the end to verify the
+// new values, returning ErrPoSUpdateNotApplied on a mismatch
+func UpdatePoSSettings(
+	rpcURL string,
+	managerAddress common.Address,
+	privateKey string,
+	update PoSSettingsUpdate,
+) (PoSUpdateReport, error) {
+	report := PoSUpdateReport{ManagerAddress: managerAddress}
+	before, err := GetPoSSettings(rpcURL, managerAddress)
+	if err != nil {
+		return report, err
+	}
+	report.Before = before
+	changes, unchanged := update.changes(before)
+	if len(changes) == 0 && len(unchanged) == 0 {
+		return report, ErrEmptyPoSSettingsUpdate
+	}
+	if err := update.apply(before).validate(); err != nil {
+		return report, err
+	}
+	for _, name := range unchanged {
+		report.Steps = append(report.Steps, InitializationStep{Name: "set " + name, Status: StepAlreadyDone})
+	}
+	if len(changes) > 0 {
+		if err := checkManagerOwner(rpcURL, managerAddress, privateKey); err != nil {
+			return report, err
+		}
+	}
+	for _, change := range changes {
+		tx, _, err := evm.TxToMethod(rpcURL, privateKey, managerAddress, nil, change.method, change.value)
+		if err != nil {
+			if tx == nil {
+				// the tx is not sent if its gas estimation fails, that is, if the
+				// contract reverts the call
+				return report, fmt.Errorf("%w: %s: %w", ErrPoSParamNotUpdatable, change.name, err)
+			}
+			return report, fmt.Errorf("failure setting %s of validator manager %s: %w", change.name, managerAddress.Hex(), err)
+		}
+		report.TxHashes = append(report.TxHashes, tx.Hash())
+		report.Steps = append(report.Steps, InitializationStep{
+			Name:   "set " + change.name,
+			Status: StepExecuted,
+			Detail: fmt.Sprintf("%s -> %s", change.current, change.desired),
+		})
+	}
+	after, err := GetPoSSettings(rpcURL, managerAddress)
+	if err != nil {
+		return report, err
+	}
+	report.After = after
+	if remaining, _ := update.changes(after); len(remaining) > 0 {
+		names := []string{}
+		for _, change := range remaining {
+			names = append(names, fmt.Sprintf("%s is %s, not %s", change.name, change.current, change.desired))
+		}
+		return report, fmt.Errorf("%w: %s", ErrPoSUpdateNotApplied, strings.Join(names, ", "))
+	}
+	return report, nil
+}
+
+// checkManagerOwner returns ErrNotManagerOwner if [privateKey] is not the key of
+// the owner of the validator manager at [managerAddress]
+func checkManagerOwner(rpcURL string, managerAddress common.Address, privateKey string) error {
+	pk, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return err
+	}
+	address := crypto.PubkeyToAddress(pk.PublicKey)
+	out, err := evm.CallToMethod(rpcURL, managerAddress, "owner()->(address)")
+	if err != nil {
+		return fmt.Errorf("failure getting owner of validator manager %s: %w", managerAddress.Hex(), err)
+	}
+	owner, ok := out[0].(common.Address)
+	if !ok {
+		return fmt.Errorf("unexpected owner %v of validator manager %s", out[0], managerAddress.Hex())
+	}
+	if owner != address {
+		return fmt.Errorf("%w: owner is %s, not %s", ErrNotManagerOwner, owner.Hex(), address.Hex())
+	}
+	return nil
+}
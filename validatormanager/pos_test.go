@@ -0,0 +1,72 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoSSettingsUpdate(t *testing.T) {
+	require := require.New(t)
+	uptimeBlockchainID := ids.GenerateTestID()
+	rewardCalculator := common.HexToAddress("0x0200000000000000000000000000000000000000")
+
+	// min stake duration 86400s, min delegation fee 100 bips and max stake multiplier 4
+	// are packed in one slot
+	stakeSettings := common.Hash{}
+	stakeSettings[21] = 4
+	copy(stakeSettings[22:24], common.BigToHash(big.NewInt(100)).Bytes()[30:])
+	copy(stakeSettings[24:], common.BigToHash(big.NewInt(86400)).Bytes()[24:])
+	values := map[common.Hash]common.Hash{
+		slotAt(posValidatorManagerStorageLocation, minimumStakeAmountOffset):  common.BigToHash(big.NewInt(1_000)),
+		slotAt(posValidatorManagerStorageLocation, maximumStakeAmountOffset):  common.BigToHash(big.NewInt(1_000_000)),
+		slotAt(posValidatorManagerStorageLocation, stakeSettingsOffset):       stakeSettings,
+		slotAt(posValidatorManagerStorageLocation, weightToValueFactorOffset): common.BigToHash(big.NewInt(1)),
+		slotAt(posValidatorManagerStorageLocation, rewardCalculatorOffset):    common.BytesToHash(rewardCalculator.Bytes()),
+		slotAt(posValidatorManagerStorageLocation, uptimeBlockchainIDOffset):  common.Hash(uptimeBlockchainID),
+	}
+	require.Len(posSlots(), len(values))
+	settings := parsePoSSettings(values)
+	require.Equal(PoSSettings{
+		MinimumStakeAmount:       big.NewInt(1_000),
+		MaximumStakeAmount:       big.NewInt(1_000_000),
+		MinimumStakeDuration:     24 * time.Hour,
+		MinimumDelegationFeeBips: 100,
+		MaximumStakeMultiplier:   4,
+		WeightToValueFactor:      big.NewInt(1),
+		RewardCalculator:         rewardCalculator,
+		UptimeBlockchainID:       uptimeBlockchainID,
+	}, settings)
+	require.NoError(settings.validate())
+
+	duration := 48*time.Hour + 500*time.Millisecond
+	feeBips := uint16(100)
+	update := PoSSettingsUpdate{
+		MinimumStakeDuration:     &duration,
+		MinimumDelegationFeeBips: &feeBips,
+	}
+	changes, unchanged := update.changes(settings)
+	require.Equal([]string{"minimum delegation fee"}, unchanged)
+	require.Len(changes, 1)
+	require.Equal("setMinimumStakeDuration(uint64)", changes[0].method)
+	require.Equal(uint64(172800), changes[0].value)
+	require.Equal("24h0m0s", changes[0].current)
+	require.Equal("48h0m0s", changes[0].desired)
+
+	updated := update.apply(settings)
+	require.Equal(48*time.Hour, updated.MinimumStakeDuration)
+	remaining, _ := update.changes(updated)
+	require.Empty(remaining)
+
+	feeBips = 0
+	require.ErrorIs(update.apply(settings).validate(), ErrInvalidPoSSettings)
+	minimumStake := big.NewInt(2_000_000)
+	require.ErrorIs(PoSSettingsUpdate{MinimumStakeAmount: minimumStake}.apply(settings).validate(), ErrInvalidPoSSettings)
+}
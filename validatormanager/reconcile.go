@@ -0,0 +1,384 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package validatormanager contains helpers to operate the validator manager contract
+// of an L1
+package validatormanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/evm"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidatorStatus is the status of a validator in the manager contract
+type ValidatorStatus uint8
+
+const (
+	Unknown ValidatorStatus = iota
+	PendingAdded
+	Active
+	PendingRemoved
+	Completed
+	Invalidated
+)
+
+func (s ValidatorStatus) String() string {
+	switch s {
+	case Unknown:
+		return "unknown"
+	case PendingAdded:
+		return "pending added"
+	case Active:
+		return "active"
+	case PendingRemoved:
+		return "pending removed"
+	case Completed:
+		return "completed"
+	case Invalidated:
+		return "invalidated"
+	}
+	return "invalid status"
+}
+
+// Action is the corrective action suggested for a validator set mismatch
+type Action string
+
+const (
+	// ActionCompleteRegistration calls completeValidatorRegistration with the P-Chain
+	// L1ValidatorRegistrationMessage, as the P-Chain already registered the validator
+	ActionCompleteRegistration Action = "complete registration"
+	// ActionResendRegistration issues again the RegisterL1ValidatorTx with the warp
+	// message signed for the registration, if it didn't expire
+	ActionResendRegistration Action = "resend registration warp message"
+	// ActionResendWeightUpdate issues again the SetL1ValidatorWeightTx with the warp
+	// message signed for the contract last weight change, including removals
+	ActionResendWeightUpdate Action = "resend weight update warp message"
+	// ActionCompleteWeightUpdate calls completeValidatorWeightUpdate with the P-Chain
+	// L1ValidatorWeightMessage, as the P-Chain already applied the change
+	ActionCompleteWeightUpdate Action = "complete weight update"
+	// ActionCompleteEndValidation calls completeEndValidation, as the P-Chain already
+	// removed the validator
+	ActionCompleteEndValidation Action = "complete end validation"
+	// ActionIncreaseBalance issues an IncreaseL1ValidatorBalanceTx, as the validator is
+	// inactive on the P-Chain
+	ActionIncreaseBalance Action = "increase balance"
+	// ActionInvestigate is suggested when there is no protocol action that fixes the
+	// mismatch, eg. when the manager address is wrong or the validator was changed by a
+	// different manager
+	ActionInvestigate Action = "investigate"
+)
+
+// ContractValidator is a validator as stored by the manager contract
+type ContractValidator struct {
+	ValidationID   ids.ID
+	Status         ValidatorStatus
+	NodeID         ids.NodeID
+	StartingWeight uint64
+	// MessageNonce is the nonce of the last weight update sent to the P-Chain
+	MessageNonce uint64
+	Weight       uint64
+	StartedAt    uint64
+	EndedAt      uint64
+}
+
+// Mismatch is a difference between the manager contract and the P-Chain view of a
+// validator, with the action suggested to fix it
+type Mismatch struct {
+	ValidationID ids.ID
+	NodeID       ids.NodeID
+	// ContractStatus is Unknown if the contract doesn't have the validator
+	ContractStatus ValidatorStatus
+	// OnPChain tells if the P-Chain has the validator
+	OnPChain bool
+	Issue    string
+	Action   Action
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s (node %s): %s. suggested action: %s", m.ValidationID, m.NodeID, m.Issue, m.Action)
+}
+
+// ReconciliationReport is the result of comparing the validators of an L1 manager
+// contract with the P-Chain ones
+type ReconciliationReport struct {
+	SubnetID   ids.ID
+	Checked    int
+	Mismatches []Mismatch
+}
+
+// InSync tells if no mismatches were found
+func (r ReconciliationReport) InSync() bool {
+	return len(r.Mismatches) == 0
+}
+
+func (r ReconciliationReport) String() string {
+	if r.InSync() {
+		return fmt.Sprintf("%d validators of subnet %s are in sync", r.Checked, r.SubnetID)
+	}
+	lines := []string{fmt.Sprintf("%d of %d validators of subnet %s are out of sync:", len(r.Mismatches), r.Checked, r.SubnetID)}
+	for _, m := range r.Mismatches {
+		lines = append(lines, "  "+m.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validatorManagerABI has the view methods of the manager contract used for reconciliation
+const validatorManagerABI = `[
+	{"type":"function","name":"getValidator","stateMutability":"view",
+	 "inputs":[{"name":"validationID","type":"bytes32"}],
+	 "outputs":[{"name":"","type":"tuple","components":[
+		{"name":"status","type":"uint8"},
+		{"name":"nodeID","type":"bytes"},
+		{"name":"startingWeight","type":"uint64"},
+		{"name":"messageNonce","type":"uint64"},
+		{"name":"weight","type":"uint64"},
+		{"name":"startedAt","type":"uint64"},
+		{"name":"endedAt","type":"uint64"}]}]},
+	{"type":"function","name":"registeredValidators","stateMutability":"view",
+	 "inputs":[{"name":"nodeID","type":"bytes"}],
+	 "outputs":[{"name":"","type":"bytes32"}]}
+]`
+
+type contractValidatorOutput struct {
+	Status         uint8
+	NodeID         []byte
+	StartingWeight uint64
+	MessageNonce   uint64
+	Weight         uint64
+	StartedAt      uint64
+	EndedAt        uint64
+}
+
+func callManager(
+	rpcURL string,
+	managerAddress common.Address,
+	method string,
+	params ...interface{},
+) ([]interface{}, error) {
+	managerABI, err := abi.JSON(strings.NewReader(validatorManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	client, err := evm.GetClient(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	contract := bind.NewBoundContract(managerAddress, managerABI, client, client, client)
+	var out []interface{}
+	if err := contract.Call(&bind.CallOpts{}, &out, method, params...); err != nil {
+		return nil, fmt.Errorf("failure calling %s on validator manager %s: %w", method, managerAddress.Hex(), err)
+	}
+	return out, nil
+}
+
+// GetContractValidator returns the validator [validationID] as stored by the manager
+// contract at [managerAddress]. Its status is Unknown if the contract doesn't have it
+func GetContractValidator(
+	rpcURL string,
+	managerAddress common.Address,
+	validationID ids.ID,
+) (ContractValidator, error) {
+	out, err := callManager(rpcURL, managerAddress, "getValidator", [32]byte(validationID))
+	if err != nil {
+		return ContractValidator{}, err
+	}
+	raw, ok := abi.ConvertType(out[0], new(contractValidatorOutput)).(*contractValidatorOutput)
+	if !ok {
+		return ContractValidator{}, fmt.Errorf("unexpected getValidator output %T", out[0])
+	}
+	validator := ContractValidator{
+		ValidationID:   validationID,
+		Status:         ValidatorStatus(raw.Status),
+		StartingWeight: raw.StartingWeight,
+		MessageNonce:   raw.MessageNonce,
+		Weight:         raw.Weight,
+		StartedAt:      raw.StartedAt,
+		EndedAt:        raw.EndedAt,
+	}
+	if len(raw.NodeID) > 0 {
+		validator.NodeID, err = ids.ToNodeID(raw.NodeID)
+		if err != nil {
+			return ContractValidator{}, fmt.Errorf("invalid node ID for validator %s: %w", validationID, err)
+		}
+	}
+	return validator, nil
+}
+
+// GetRegisteredValidationID returns the validation ID the manager contract at
+// [managerAddress] has registered for [nodeID], or ids.Empty if there is none
+func GetRegisteredValidationID(
+	rpcURL string,
+	managerAddress common.Address,
+	nodeID ids.NodeID,
+) (ids.ID, error) {
+	out, err := callManager(rpcURL, managerAddress, "registeredValidators", nodeID.Bytes())
+	if err != nil {
+		return ids.Empty, err
+	}
+	validationID, ok := out[0].([32]byte)
+	if !ok {
+		return ids.Empty, fmt.Errorf("unexpected registeredValidators output %T", out[0])
+	}
+	return ids.ID(validationID), nil
+}
+
+// Reconcile compares the validators of [subnetID] registered on the manager contract at
+// [managerAddress], on the L1 at [rpcURL], with the ones the P-Chain of [network] has,
+// and reports each mismatch with a suggested corrective action, eg. after a missed
+// registration or weight update completion.
+//
+// The current P-Chain validators are always checked. As the contract can't list its
+// validators, the ones only the contract knows about, eg. pending registrations, are
+// checked only if their validation IDs are given in [validationIDs]
+func Reconcile(
+	network avalanche.Network,
+	subnetID ids.ID,
+	rpcURL string,
+	managerAddress common.Address,
+	validationIDs ...ids.ID,
+) (ReconciliationReport, error) {
+	ctx, cancel := utils.GetAPILargeContext()
+	defer cancel()
+	pChainIDs, err := network.GetL1ValidationIDs(ctx, subnetID)
+	if err != nil {
+		return ReconciliationReport{}, fmt.Errorf("failure getting L1 validators of subnet %s: %w", subnetID, err)
+	}
+	toCheck := append([]ids.ID{}, validationIDs...)
+	for _, validationID := range pChainIDs {
+		toCheck = append(toCheck, validationID)
+	}
+	toCheck = utils.Unique(toCheck)
+	contractValidators := map[ids.ID]ContractValidator{}
+	pChainValidators := map[ids.ID]avalanche.L1Validator{}
+	for _, validationID := range toCheck {
+		contractValidator, err := GetContractValidator(rpcURL, managerAddress, validationID)
+		if err != nil {
+			return ReconciliationReport{}, err
+		}
+		contractValidators[validationID] = contractValidator
+		pChainValidator, err := network.GetL1Validator(ctx, validationID)
+		switch {
+		case errors.Is(err, avalanche.ErrL1ValidatorNotFound):
+		case err != nil:
+			return ReconciliationReport{}, fmt.Errorf("failure getting L1 validator %s: %w", validationID, err)
+		default:
+			pChainValidators[validationID] = pChainValidator
+		}
+	}
+	return ReconciliationReport{
+		SubnetID:   subnetID,
+		Checked:    len(toCheck),
+		Mismatches: reconcile(contractValidators, pChainValidators),
+	}, nil
+}
+
+// reconcile compares each contract validator with the P-Chain one with the same
+// validation ID, if any, and the P-Chain validators not known by the contract
+func reconcile(
+	contractValidators map[ids.ID]ContractValidator,
+	pChainValidators map[ids.ID]avalanche.L1Validator,
+) []Mismatch {
+	mismatches := []Mismatch{}
+	for validationID, c := range contractValidators {
+		p, onPChain := pChainValidators[validationID]
+		mismatch := Mismatch{
+			ValidationID:   validationID,
+			NodeID:         c.NodeID,
+			ContractStatus: c.Status,
+			OnPChain:       onPChain,
+		}
+		if onPChain {
+			mismatch.NodeID = p.NodeID
+		}
+		switch {
+		case c.Status == Unknown && onPChain:
+			mismatch.Issue = "P-Chain validator is not registered on the manager contract"
+			mismatch.Action = ActionInvestigate
+		case c.Status == PendingAdded && onPChain:
+			mismatch.Issue = "registration was accepted by the P-Chain but not completed on the contract"
+			mismatch.Action = ActionCompleteRegistration
+		case c.Status == PendingAdded:
+			mismatch.Issue = "registration is pending on the contract but the P-Chain doesn't have the validator"
+			mismatch.Action = ActionResendRegistration
+		case c.Status == Active && !onPChain:
+			mismatch.Issue = "validator is active on the contract but the P-Chain doesn't have it"
+			mismatch.Action = ActionInvestigate
+		case c.Status == Active && c.Weight != uint64(p.Weight) && c.MessageNonce >= uint64(p.MinNonce):
+			mismatch.Issue = fmt.Sprintf("contract weight %d was not applied by the P-Chain, that has weight %d", c.Weight, p.Weight)
+			mismatch.Action = ActionResendWeightUpdate
+		case c.Status == Active && c.Weight != uint64(p.Weight):
+			mismatch.Issue = fmt.Sprintf("P-Chain weight %d update was not completed on the contract, that has weight %d", p.Weight, c.Weight)
+			mismatch.Action = ActionCompleteWeightUpdate
+		case c.Status == PendingRemoved && onPChain:
+			mismatch.Issue = "removal is pending on the contract but the P-Chain still has the validator"
+			mismatch.Action = ActionResendWeightUpdate
+		case c.Status == PendingRemoved:
+			mismatch.Issue = "validator was removed by the P-Chain but the removal was not completed on the contract"
+			mismatch.Action = ActionCompleteEndValidation
+		case (c.Status == Completed || c.Status == Invalidated) && onPChain:
+			mismatch.Issue = fmt.Sprintf("validator is %s on the contract but the P-Chain still has it", c.Status)
+			mismatch.Action = ActionInvestigate
+		case c.Status == Active && !p.Active():
+			mismatch.Issue = "validator is active on the contract but has no balance left on the P-Chain"
+			mismatch.Action = ActionIncreaseBalance
+		default:
+			continue
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	for validationID, p := range pChainValidators {
+		if _, ok := contractValidators[validationID]; !ok {
+			mismatches = append(mismatches, Mismatch{
+				ValidationID: validationID,
+				NodeID:       p.NodeID,
+				OnPChain:     true,
+				Issue:        "P-Chain validator is not registered on the manager contract",
+				Action:       ActionInvestigate,
+			})
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].ValidationID.Compare(mismatches[j].ValidationID) < 0
+	})
+	return mismatches
+}
+
+// MonitorDrift runs Reconcile every [interval] until [ctx] is done, calling [alert]
+// each time the validator sets are found out of sync, or the check fails
+func MonitorDrift(
+	ctx context.Context,
+	interval time.Duration,
+	network avalanche.Network,
+	subnetID ids.ID,
+	rpcURL string,
+	managerAddress common.Address,
+	alert func(ReconciliationReport, error),
+	validationIDs ...ids.ID,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := Reconcile(network, subnetID, rpcURL, managerAddress, validationIDs...)
+		if err != nil || !report.InSync() {
+			alert(report, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
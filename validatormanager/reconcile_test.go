@@ -0,0 +1,122 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	require := require.New(t)
+	nodeID := ids.GenerateTestNodeID()
+	validationID := ids.GenerateTestID()
+	pChain := avalanche.L1Validator{ValidationID: validationID, NodeID: nodeID, Weight: 100, MinNonce: 2, Balance: 1000}
+
+	tests := []struct {
+		name     string
+		contract *ContractValidator
+		pChain   *avalanche.L1Validator
+		action   Action
+	}{
+		{
+			name:     "in sync",
+			contract: &ContractValidator{Status: Active, NodeID: nodeID, Weight: 100, MessageNonce: 1},
+			pChain:   &pChain,
+		},
+		{
+			name:     "registration not completed",
+			contract: &ContractValidator{Status: PendingAdded, NodeID: nodeID, Weight: 100},
+			pChain:   &pChain,
+			action:   ActionCompleteRegistration,
+		},
+		{
+			name:     "registration not accepted",
+			contract: &ContractValidator{Status: PendingAdded, NodeID: nodeID, Weight: 100},
+			action:   ActionResendRegistration,
+		},
+		{
+			name:     "weight update not sent",
+			contract: &ContractValidator{Status: Active, NodeID: nodeID, Weight: 200, MessageNonce: 2},
+			pChain:   &pChain,
+			action:   ActionResendWeightUpdate,
+		},
+		{
+			name:     "weight update not completed",
+			contract: &ContractValidator{Status: Active, NodeID: nodeID, Weight: 50, MessageNonce: 1},
+			pChain:   &pChain,
+			action:   ActionCompleteWeightUpdate,
+		},
+		{
+			name:     "removal not sent",
+			contract: &ContractValidator{Status: PendingRemoved, NodeID: nodeID, MessageNonce: 2},
+			pChain:   &pChain,
+			action:   ActionResendWeightUpdate,
+		},
+		{
+			name:     "removal not completed",
+			contract: &ContractValidator{Status: PendingRemoved, NodeID: nodeID, MessageNonce: 2},
+			action:   ActionCompleteEndValidation,
+		},
+		{
+			name:     "removed validator",
+			contract: &ContractValidator{Status: Completed, NodeID: nodeID},
+		},
+		{
+			name:     "not on contract",
+			contract: &ContractValidator{Status: Unknown},
+			pChain:   &pChain,
+			action:   ActionInvestigate,
+		},
+		{
+			name:   "not checked on contract",
+			pChain: &pChain,
+			action: ActionInvestigate,
+		},
+		{
+			name:     "missing on P-Chain",
+			contract: &ContractValidator{Status: Active, NodeID: nodeID, Weight: 100},
+			action:   ActionInvestigate,
+		},
+		{
+			name:     "no balance",
+			contract: &ContractValidator{Status: Active, NodeID: nodeID, Weight: 100, MessageNonce: 1},
+			pChain:   &avalanche.L1Validator{ValidationID: validationID, NodeID: nodeID, Weight: 100, MinNonce: 2},
+			action:   ActionIncreaseBalance,
+		},
+	}
+	for _, test := range tests {
+		contractValidators := map[ids.ID]ContractValidator{}
+		if test.contract != nil {
+			contractValidators[validationID] = *test.contract
+		}
+		pChainValidators := map[ids.ID]avalanche.L1Validator{}
+		if test.pChain != nil {
+			pChainValidators[validationID] = *test.pChain
+		}
+		mismatches := reconcile(contractValidators, pChainValidators)
+		if test.action == "" {
+			require.Empty(mismatches, test.name)
+			continue
+		}
+		require.Len(mismatches, 1, test.name)
+		require.Equal(test.action, mismatches[0].Action, test.name)
+		require.Equal(validationID, mismatches[0].ValidationID, test.name)
+		require.Equal(nodeID, mismatches[0].NodeID, test.name)
+		require.Equal(test.pChain != nil, mismatches[0].OnPChain, test.name)
+	}
+}
+
+func TestReconciliationReportString(t *testing.T) {
+	require := require.New(t)
+	report := ReconciliationReport{SubnetID: ids.GenerateTestID(), Checked: 2}
+	require.True(report.InSync())
+	require.Contains(report.String(), "2 validators")
+	report.Mismatches = []Mismatch{{ValidationID: ids.GenerateTestID(), Issue: "issue", Action: ActionCompleteRegistration}}
+	require.False(report.InSync())
+	require.Contains(report.String(), "suggested action: complete registration")
+}
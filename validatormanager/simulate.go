@@ -0,0 +1,228 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+)
+
+var ErrInvalidQuorumNumerator = errors.New("quorum numerator must be between 1 and the quorum denominator")
+
+// WeightChange is a proposed new weight for a validator. A zero weight removes the
+// validator, and a node that is not a validator yet is added
+type WeightChange struct {
+	NodeID ids.NodeID
+	Weight uint64
+}
+
+// SimulationParams are the thresholds weight changes are checked against
+type SimulationParams struct {
+	// QuorumNumerator is the warp quorum numerator of the L1, over
+	// warp.WarpQuorumDenominator. Defaults to warp.WarpDefaultQuorumNumerator
+	QuorumNumerator uint64
+	// MaxEntityShare is the maximum fraction of the total weight, between 0 and 1, a
+	// single entity may have. 0 disables the check
+	MaxEntityShare float64
+	// MaxChurnPercentage is the maximum percentage of the total weight the manager
+	// contract allows to change in a churn period. 0 disables the check
+	MaxChurnPercentage uint64
+	// Entities groups validators run by the same operator, by node ID. Validators
+	// not listed are an entity on their own
+	Entities map[ids.NodeID]string
+}
+
+// WeightShare is the weight of a validator or entity and its share of the total weight
+type WeightShare struct {
+	ID     string
+	Weight uint64
+	Share  float64
+}
+
+// WeightSimulation is the stake distribution resulting from a set of weight changes,
+// as computed by SimulateWeightChanges
+type WeightSimulation struct {
+	TotalWeightBefore uint64
+	TotalWeightAfter  uint64
+	// Validators and Entities are sorted by decreasing weight
+	Validators []WeightShare
+	Entities   []WeightShare
+	// ChurnPercentage is the weight changed, as a percentage of TotalWeightBefore
+	ChurnPercentage float64
+	// MinValidatorsForQuorum is the smallest number of validators whose signatures
+	// reach the warp quorum
+	MinValidatorsForQuorum int
+	// QuorumWithoutLargestEntity tells if the warp quorum can be reached when the
+	// largest entity doesn't sign
+	QuorumWithoutLargestEntity bool
+	// Issues lists the thresholds the resulting distribution breaks
+	Issues []string
+}
+
+// Safe tells if the resulting distribution doesn't break any threshold
+func (s WeightSimulation) Safe() bool {
+	return len(s.Issues) == 0
+}
+
+// SimulateWeightChanges applies [changes] to the validator weights [current] and
+// reports the resulting weight shares, by validator and by entity, and whether warp
+// quorum stays achievable without depending on, or being controlled by, a single
+// entity. All issues found are listed in the result; an error is only returned
+// for invalid input
+func SimulateWeightChanges(
+	current map[ids.NodeID]uint64,
+	changes []WeightChange,
+	params SimulationParams,
+) (WeightSimulation, error) {
+	quorumNumerator := params.QuorumNumerator
+	if quorumNumerator == 0 {
+		quorumNumerator = warp.WarpDefaultQuorumNumerator
+	}
+	if quorumNumerator > warp.WarpQuorumDenominator {
+		return WeightSimulation{}, fmt.Errorf("%w: %d", ErrInvalidQuorumNumerator, quorumNumerator)
+	}
+	after := map[ids.NodeID]uint64{}
+	simulation := WeightSimulation{}
+	var err error
+	for nodeID, weight := range current {
+		after[nodeID] = weight
+		if simulation.TotalWeightBefore, err = safemath.Add64(simulation.TotalWeightBefore, weight); err != nil {
+			return WeightSimulation{}, fmt.Errorf("total weight before changes: %w", err)
+		}
+	}
+	churn := uint64(0)
+	for _, change := range changes {
+		previous := after[change.NodeID]
+		if change.Weight > previous {
+			churn += change.Weight - previous
+		} else {
+			churn += previous - change.Weight
+		}
+		if change.Weight == 0 {
+			delete(after, change.NodeID)
+		} else {
+			after[change.NodeID] = change.Weight
+		}
+	}
+	entityWeights := map[string]uint64{}
+	for nodeID, weight := range after {
+		if simulation.TotalWeightAfter, err = safemath.Add64(simulation.TotalWeightAfter, weight); err != nil {
+			return WeightSimulation{}, fmt.Errorf("total weight after changes: %w", err)
+		}
+		entity, ok := params.Entities[nodeID]
+		if !ok {
+			entity = nodeID.String()
+		}
+		entityWeights[entity] += weight
+	}
+	if simulation.TotalWeightBefore > 0 {
+		simulation.ChurnPercentage = 100 * float64(churn) / float64(simulation.TotalWeightBefore)
+	}
+	if simulation.TotalWeightAfter == 0 {
+		simulation.Issues = append(simulation.Issues, "no validator weight left")
+		return simulation, nil
+	}
+	for nodeID, weight := range after {
+		simulation.Validators = append(simulation.Validators, simulation.share(nodeID.String(), weight))
+	}
+	for entity, weight := range entityWeights {
+		simulation.Entities = append(simulation.Entities, simulation.share(entity, weight))
+	}
+	sortShares(simulation.Validators)
+	sortShares(simulation.Entities)
+
+	signedWeight := uint64(0)
+	for i, validator := range simulation.Validators {
+		signedWeight += validator.Weight
+		if reachesQuorum(signedWeight, simulation.TotalWeightAfter, quorumNumerator) {
+			simulation.MinValidatorsForQuorum = i + 1
+			break
+		}
+	}
+	largest := simulation.Entities[0]
+	simulation.QuorumWithoutLargestEntity = reachesQuorum(
+		simulation.TotalWeightAfter-largest.Weight,
+		simulation.TotalWeightAfter,
+		quorumNumerator,
+	)
+	for _, entity := range simulation.Entities {
+		if reachesQuorum(entity.Weight, simulation.TotalWeightAfter, quorumNumerator) {
+			simulation.Issues = append(simulation.Issues, fmt.Sprintf(
+				"%s has %.2f%% of the weight, enough to reach the %d%% warp quorum alone",
+				entity.ID, 100*entity.Share, quorumNumerator,
+			))
+		}
+		if params.MaxEntityShare > 0 && entity.Share > params.MaxEntityShare {
+			simulation.Issues = append(simulation.Issues, fmt.Sprintf(
+				"%s has %.2f%% of the weight, more than the %.2f%% limit",
+				entity.ID, 100*entity.Share, 100*params.MaxEntityShare,
+			))
+		}
+	}
+	if !simulation.QuorumWithoutLargestEntity {
+		simulation.Issues = append(simulation.Issues, fmt.Sprintf(
+			"warp quorum can't be reached if %s doesn't sign",
+			largest.ID,
+		))
+	}
+	if params.MaxChurnPercentage > 0 && simulation.ChurnPercentage > float64(params.MaxChurnPercentage) {
+		simulation.Issues = append(simulation.Issues, fmt.Sprintf(
+			"changes churn %.2f%% of the weight, more than the %d%% allowed per churn period",
+			simulation.ChurnPercentage, params.MaxChurnPercentage,
+		))
+	}
+	return simulation, nil
+}
+
+// SimulateSubnetWeightChanges runs SimulateWeightChanges taking the current weights
+// from the validators of [subnetID] on the P-Chain of [network]
+func SimulateSubnetWeightChanges(
+	network avalanche.Network,
+	subnetID ids.ID,
+	changes []WeightChange,
+	params SimulationParams,
+) (WeightSimulation, error) {
+	ctx, cancel := utils.GetAPIContext()
+	defer cancel()
+	validators, err := platformvm.NewClient(network.Endpoint).GetCurrentValidators(ctx, subnetID, nil)
+	if err != nil {
+		return WeightSimulation{}, fmt.Errorf("failure getting current validators for subnet %s: %w", subnetID, err)
+	}
+	current := map[ids.NodeID]uint64{}
+	for _, validator := range validators {
+		current[validator.NodeID] = validator.Weight
+	}
+	return SimulateWeightChanges(current, changes, params)
+}
+
+func (s WeightSimulation) share(id string, weight uint64) WeightShare {
+	return WeightShare{
+		ID:     id,
+		Weight: weight,
+		Share:  float64(weight) / float64(s.TotalWeightAfter),
+	}
+}
+
+// reachesQuorum uses the same check as warp signature verification
+func reachesQuorum(weight uint64, totalWeight uint64, quorumNumerator uint64) bool {
+	return avalancheWarp.VerifyWeight(weight, totalWeight, quorumNumerator, warp.WarpQuorumDenominator) == nil
+}
+
+func sortShares(shares []WeightShare) {
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Weight != shares[j].Weight {
+			return shares[i].Weight > shares[j].Weight
+		}
+		return shares[i].ID < shares[j].ID
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validatormanager
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateWeightChanges(t *testing.T) {
+	require := require.New(t)
+	nodeIDs := []ids.NodeID{ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID()}
+	current := map[ids.NodeID]uint64{
+		nodeIDs[0]: 100,
+		nodeIDs[1]: 100,
+		nodeIDs[2]: 100,
+		nodeIDs[3]: 100,
+	}
+
+	simulation, err := SimulateWeightChanges(current, nil, SimulationParams{})
+	require.NoError(err)
+	require.True(simulation.Safe(), simulation.Issues)
+	require.Equal(uint64(400), simulation.TotalWeightAfter)
+	require.Len(simulation.Entities, 4)
+	require.InDelta(0.25, simulation.Validators[0].Share, 1e-9)
+	require.Equal(3, simulation.MinValidatorsForQuorum)
+	require.True(simulation.QuorumWithoutLargestEntity)
+	require.Zero(simulation.ChurnPercentage)
+
+	// one validator grows to half of the weight, and another one is removed
+	changes := []WeightChange{{NodeID: nodeIDs[0], Weight: 200}, {NodeID: nodeIDs[3], Weight: 0}}
+	simulation, err = SimulateWeightChanges(current, changes, SimulationParams{MaxChurnPercentage: 20})
+	require.NoError(err)
+	require.Equal(uint64(400), simulation.TotalWeightAfter)
+	require.Len(simulation.Validators, 3)
+	require.Equal(nodeIDs[0].String(), simulation.Validators[0].ID)
+	require.InDelta(0.5, simulation.Validators[0].Share, 1e-9)
+	require.InDelta(50, simulation.ChurnPercentage, 1e-9)
+	require.False(simulation.QuorumWithoutLargestEntity)
+	require.Equal(2, simulation.MinValidatorsForQuorum)
+	require.Len(simulation.Issues, 2)
+	require.Contains(simulation.Issues[0], "can't be reached if "+nodeIDs[0].String())
+	require.Contains(simulation.Issues[1], "50.00% of the weight, more than the 20% allowed")
+
+	// validators run by the same entity add up
+	params := SimulationParams{
+		MaxEntityShare: 0.4,
+		Entities:       map[ids.NodeID]string{nodeIDs[1]: "op", nodeIDs[2]: "op", nodeIDs[3]: "op"},
+	}
+	simulation, err = SimulateWeightChanges(current, nil, params)
+	require.NoError(err)
+	require.Len(simulation.Entities, 2)
+	require.Equal(WeightShare{ID: "op", Weight: 300, Share: 0.75}, simulation.Entities[0])
+	require.Len(simulation.Issues, 3)
+	require.Contains(simulation.Issues[0], "op has 75.00% of the weight, enough to reach the 67% warp quorum alone")
+	require.Contains(simulation.Issues[1], "more than the 40.00% limit")
+
+	// a lower quorum is not blocked by the entity with half of the weight
+	simulation, err = SimulateWeightChanges(current, changes, SimulationParams{QuorumNumerator: 50})
+	require.NoError(err)
+	require.True(simulation.QuorumWithoutLargestEntity)
+	require.Contains(simulation.Issues[0], "enough to reach the 50% warp quorum alone")
+
+	simulation, err = SimulateWeightChanges(map[ids.NodeID]uint64{nodeIDs[0]: 1}, []WeightChange{{NodeID: nodeIDs[0]}}, SimulationParams{})
+	require.NoError(err)
+	require.Equal([]string{"no validator weight left"}, simulation.Issues)
+
+	_, err = SimulateWeightChanges(current, nil, SimulationParams{QuorumNumerator: 101})
+	require.ErrorIs(err, ErrInvalidQuorumNumerator)
+}
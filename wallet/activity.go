@@ -0,0 +1,449 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/addressbook"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// activityPageSize is the number of blocks requested to the index per call
+const activityPageSize = 256
+
+// rewardsCounterparty is the counterparty of stake returns and staking rewards
+const rewardsCounterparty = "staking rewards"
+
+// ActivityRange bounds an activity report. Zero values don't bound it
+type ActivityRange struct {
+	// StartIndex and EndIndex bound the blocks checked, by their position in the
+	// P-Chain block index, both included
+	StartIndex uint64
+	EndIndex   uint64
+	// Start and End bound the blocks checked by acceptance time
+	Start time.Time
+	End   time.Time
+}
+
+// PChainActivity is a P-Chain tx that moved AVAX of an address. Amounts are in nAVAX
+type PChainActivity struct {
+	TxID       ids.ID
+	TxType     string
+	BlockID    ids.ID
+	BlockIndex uint64
+	Timestamp  time.Time
+	// Inflow and Outflow are the net amounts received and spent by the address.
+	// Change returned to the address is not counted
+	Inflow  uint64
+	Outflow uint64
+	// Staked is the part of Outflow locked as stake. It gets back as Inflow
+	// of the tx that rewards the staker
+	Staked uint64
+	// Fee burned by the tx. Only set if the address paid for it
+	Fee uint64
+	// Counterparties are the other side of the transfer: P-Chain addresses, the
+	// chain funds are imported from or exported to, or staking rewards
+	Counterparties []string
+}
+
+type pChainActivityClient interface {
+	GetTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error)
+	GetRewardUTXOs(ctx context.Context, args *api.GetTxArgs, options ...rpc.Option) ([][]byte, error)
+	GetStakingAssetID(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (ids.ID, error)
+}
+
+type blockIndexClient interface {
+	GetLastAccepted(ctx context.Context, options ...rpc.Option) (indexer.Container, uint64, error)
+	GetContainerRange(ctx context.Context, startIndex uint64, numToFetch int, options ...rpc.Option) ([]indexer.Container, error)
+}
+
+// GetPChainActivity lists the txs that moved AVAX of P-Chain address [addr] in the
+// blocks of [activityRange], walking the P-Chain block index of [network]. The API
+// node must have the index enabled (--index-enabled)
+func GetPChainActivity(
+	network avalanche.Network,
+	addr ids.ShortID,
+	activityRange ActivityRange,
+) ([]PChainActivity, error) {
+	return getPChainActivity(
+		platformvm.NewClient(network.Endpoint),
+		indexer.NewClient(network.Endpoint+"/ext/index/P/block"),
+		network.HRP(),
+		addr,
+		activityRange,
+	)
+}
+
+func getPChainActivity(
+	client pChainActivityClient,
+	index blockIndexClient,
+	hrp string,
+	addr ids.ShortID,
+	activityRange ActivityRange,
+) ([]PChainActivity, error) {
+	ctx, cancel := utils.GetAPIContext()
+	avaxAssetID, err := client.GetStakingAssetID(ctx, avagoconstants.PrimaryNetworkID)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failure getting AVAX asset ID: %w", err)
+	}
+	ctx, cancel = utils.GetAPIContext()
+	_, lastIndex, err := index.GetLastAccepted(ctx)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failure getting last accepted block from the P-Chain index: %w", err)
+	}
+	endIndex := lastIndex
+	if activityRange.EndIndex != 0 && activityRange.EndIndex < endIndex {
+		endIndex = activityRange.EndIndex
+	}
+	r := &activityResolver{
+		client:      client,
+		hrp:         hrp,
+		addr:        addr,
+		avaxAssetID: avaxAssetID,
+		txs:         map[ids.ID]*txs.Tx{},
+	}
+	activities := []PChainActivity{}
+	for start := activityRange.StartIndex; start <= endIndex; start += activityPageSize {
+		ctx, cancel := utils.GetAPILargeContext()
+		containers, err := index.GetContainerRange(ctx, start, int(min(activityPageSize, endIndex-start+1)))
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failure getting blocks from %d from the P-Chain index: %w", start, err)
+		}
+		for i, container := range containers {
+			timestamp := time.Unix(0, container.Timestamp)
+			if !activityRange.Start.IsZero() && timestamp.Before(activityRange.Start) {
+				continue
+			}
+			if !activityRange.End.IsZero() && timestamp.After(activityRange.End) {
+				return activities, nil
+			}
+			blk, err := block.Parse(block.Codec, container.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failure parsing P-Chain block %s: %w", container.ID, err)
+			}
+			for _, tx := range blk.Txs() {
+				activity, ok, err := r.activity(tx)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					activity.BlockID = blk.ID()
+					activity.BlockIndex = start + uint64(i)
+					activity.Timestamp = timestamp
+					activities = append(activities, activity)
+				}
+			}
+		}
+		if len(containers) == 0 {
+			break
+		}
+	}
+	return activities, nil
+}
+
+// activityResolver computes the activity of an address on P-Chain txs, resolving the
+// UTXOs they consume from the txs that produced them
+type activityResolver struct {
+	client      pChainActivityClient
+	hrp         string
+	addr        ids.ShortID
+	avaxAssetID ids.ID
+	txs         map[ids.ID]*txs.Tx
+}
+
+func (r *activityResolver) activity(tx *txs.Tx) (PChainActivity, bool, error) {
+	activity := PChainActivity{
+		TxID:   tx.ID(),
+		TxType: strings.TrimPrefix(fmt.Sprintf("%T", tx.Unsigned), "*txs."),
+	}
+	if rewardTx, ok := tx.Unsigned.(*txs.RewardValidatorTx); ok {
+		return r.rewardActivity(activity, rewardTx.TxID)
+	}
+	ins, err := multisig.GetInputs(tx.Unsigned)
+	if err != nil {
+		// txs without inputs, like AdvanceTimeTx, don't move funds
+		return activity, false, nil
+	}
+	var (
+		spent, received, burned uint64
+		senders                 = map[string]struct{}{}
+		receivers               = map[string]struct{}{}
+	)
+	for _, in := range ins {
+		if in.AssetID() != r.avaxAssetID {
+			continue
+		}
+		burned += in.In.Amount()
+		out, err := r.utxo(in.UTXOID)
+		if err != nil {
+			return activity, false, err
+		}
+		owners, _ := transferOutput(out)
+		if r.owns(owners) {
+			spent += in.In.Amount()
+		} else {
+			r.addOwners(senders, owners)
+		}
+	}
+	outs := tx.Unsigned.Outputs()
+	if importTx, ok := tx.Unsigned.(*txs.ImportTx); ok {
+		for _, in := range importTx.ImportedInputs {
+			if in.AssetID() == r.avaxAssetID {
+				burned += in.In.Amount()
+			}
+		}
+		senders["chain "+importTx.SourceChain.String()] = struct{}{}
+	}
+	if exportTx, ok := tx.Unsigned.(*txs.ExportTx); ok {
+		for _, out := range exportTx.ExportedOutputs {
+			if out.AssetID() == r.avaxAssetID {
+				burned -= min(burned, out.Out.Amount())
+			}
+		}
+		receivers["chain "+exportTx.DestinationChain.String()] = struct{}{}
+	}
+	for _, out := range outs {
+		if out.AssetID() != r.avaxAssetID {
+			continue
+		}
+		burned -= min(burned, out.Out.Amount())
+		owners, amount := transferOutput(out.Out)
+		if r.owns(owners) {
+			received += amount
+		} else {
+			r.addOwners(receivers, owners)
+		}
+	}
+	if stakerTx, ok := tx.Unsigned.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		for _, out := range stakerTx.Stake() {
+			if out.AssetID() != r.avaxAssetID {
+				continue
+			}
+			burned -= min(burned, out.Out.Amount())
+			if spent > 0 {
+				activity.Staked += out.Out.Amount()
+			}
+		}
+	}
+	if spent == 0 && received == 0 {
+		return activity, false, nil
+	}
+	if spent > 0 {
+		activity.Fee = burned
+		activity.Counterparties = sortedKeys(receivers)
+	} else {
+		activity.Counterparties = sortedKeys(senders)
+	}
+	if received >= spent {
+		activity.Inflow = received - spent
+	} else {
+		activity.Outflow = spent - received
+	}
+	return activity, true, nil
+}
+
+// rewardActivity reports the stake returned and the rewards paid to the address when
+// the staker [stakerTxID] finishes
+func (r *activityResolver) rewardActivity(activity PChainActivity, stakerTxID ids.ID) (PChainActivity, bool, error) {
+	stakerTx, err := r.tx(stakerTxID)
+	if err != nil {
+		return activity, false, err
+	}
+	if staker, ok := stakerTx.Unsigned.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		for _, out := range staker.Stake() {
+			if owners, amount := transferOutput(out.Out); out.AssetID() == r.avaxAssetID && r.owns(owners) {
+				activity.Inflow += amount
+			}
+		}
+	}
+	ctx, cancel := utils.GetAPIContext()
+	rewardUTXOs, err := r.client.GetRewardUTXOs(ctx, &api.GetTxArgs{TxID: stakerTxID})
+	cancel()
+	if err != nil {
+		return activity, false, fmt.Errorf("failure getting reward UTXOs of %s: %w", stakerTxID, err)
+	}
+	for _, utxoBytes := range rewardUTXOs {
+		utxo := avax.UTXO{}
+		if _, err := txs.Codec.Unmarshal(utxoBytes, &utxo); err != nil {
+			return activity, false, fmt.Errorf("failure parsing reward UTXO of %s: %w", stakerTxID, err)
+		}
+		if owners, amount := transferOutput(utxo.Out); utxo.AssetID() == r.avaxAssetID && r.owns(owners) {
+			activity.Inflow += amount
+		}
+	}
+	if activity.Inflow == 0 {
+		return activity, false, nil
+	}
+	activity.Counterparties = []string{rewardsCounterparty}
+	return activity, true, nil
+}
+
+// utxo returns the output consumed by [utxoID]. Staked outputs are indexed after the
+// regular ones
+func (r *activityResolver) utxo(utxoID avax.UTXOID) (verify.State, error) {
+	tx, err := r.tx(utxoID.TxID)
+	if err != nil {
+		return nil, err
+	}
+	outs := tx.Unsigned.Outputs()
+	if stakerTx, ok := tx.Unsigned.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		outs = append(outs, stakerTx.Stake()...)
+	}
+	if utxoID.OutputIndex < uint32(len(outs)) {
+		return outs[utxoID.OutputIndex].Out, nil
+	}
+	// reward UTXOs are indexed after the staked ones
+	ctx, cancel := utils.GetAPIContext()
+	rewardUTXOs, err := r.client.GetRewardUTXOs(ctx, &api.GetTxArgs{TxID: utxoID.TxID})
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failure getting reward UTXOs of %s: %w", utxoID.TxID, err)
+	}
+	for _, utxoBytes := range rewardUTXOs {
+		utxo := avax.UTXO{}
+		if _, err := txs.Codec.Unmarshal(utxoBytes, &utxo); err != nil {
+			return nil, fmt.Errorf("failure parsing reward UTXO of %s: %w", utxoID.TxID, err)
+		}
+		if utxo.OutputIndex == utxoID.OutputIndex {
+			return utxo.Out, nil
+		}
+	}
+	return nil, fmt.Errorf("output index %d not found on tx %s", utxoID.OutputIndex, utxoID.TxID)
+}
+
+func (r *activityResolver) tx(txID ids.ID) (*txs.Tx, error) {
+	if tx, ok := r.txs[txID]; ok {
+		return tx, nil
+	}
+	ctx, cancel := utils.GetAPIContext()
+	txBytes, err := r.client.GetTx(ctx, txID)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("tx %s query error: %w", txID, err)
+	}
+	tx, err := txs.Parse(txs.Codec, txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx %s: %w", txID, err)
+	}
+	r.txs[txID] = tx
+	return tx, nil
+}
+
+func (r *activityResolver) owns(owners *secp256k1fx.OutputOwners) bool {
+	if owners == nil {
+		return false
+	}
+	for _, addr := range owners.Addrs {
+		if addr == r.addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *activityResolver) addOwners(counterparties map[string]struct{}, owners *secp256k1fx.OutputOwners) {
+	if owners == nil {
+		return
+	}
+	for _, addr := range owners.Addrs {
+		formatted, err := address.Format("P", r.hrp, addr[:])
+		if err != nil {
+			formatted = addr.String()
+		}
+		counterparties[formatted] = struct{}{}
+	}
+}
+
+// transferOutput returns the owners and amount of [out], or nil owners if it is not
+// a secp256k1fx output
+func transferOutput(out verify.State) (*secp256k1fx.OutputOwners, uint64) {
+	if lockOut, ok := out.(*stakeable.LockOut); ok {
+		out = lockOut.TransferableOut
+	}
+	transferOutput, ok := out.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return nil, 0
+	}
+	return &transferOutput.OutputOwners, transferOutput.Amt
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AnnotatePChainActivity returns a copy of [activities] with the counterparty addresses
+// annotated with their labels in [ab], eg "P-fuji1... (treasury)"
+func AnnotatePChainActivity(activities []PChainActivity, ab *addressbook.AddressBook) []PChainActivity {
+	annotated := make([]PChainActivity, 0, len(activities))
+	for _, activity := range activities {
+		activity.Counterparties = utils.Map(activity.Counterparties, ab.Annotate)
+		annotated = append(annotated, activity)
+	}
+	return annotated
+}
+
+// WritePChainActivityCSV writes [activities] as CSV into [w], with a header row.
+// Amounts are in nAVAX, and counterparties are separated by spaces
+func WritePChainActivityCSV(w io.Writer, activities []PChainActivity) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{
+		"timestamp", "blockIndex", "blockID", "txID", "txType", "inflow", "outflow", "staked", "fee", "counterparties",
+	}); err != nil {
+		return err
+	}
+	for _, activity := range activities {
+		if err := csvWriter.Write([]string{
+			activity.Timestamp.UTC().Format(time.RFC3339),
+			strconv.FormatUint(activity.BlockIndex, 10),
+			activity.BlockID.String(),
+			activity.TxID.String(),
+			activity.TxType,
+			strconv.FormatUint(activity.Inflow, 10),
+			strconv.FormatUint(activity.Outflow, 10),
+			strconv.FormatUint(activity.Staked, 10),
+			strconv.FormatUint(activity.Fee, 10),
+			strings.Join(activity.Counterparties, " "),
+		}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,214 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/addressbook"
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+)
+
+var activityAssetID = ids.GenerateTestID()
+
+type fakeActivityClient struct {
+	txs         map[ids.ID][]byte
+	rewardUTXOs map[ids.ID][][]byte
+}
+
+func (c *fakeActivityClient) GetTx(_ context.Context, txID ids.ID, _ ...rpc.Option) ([]byte, error) {
+	txBytes, ok := c.txs[txID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return txBytes, nil
+}
+
+func (c *fakeActivityClient) GetRewardUTXOs(_ context.Context, args *api.GetTxArgs, _ ...rpc.Option) ([][]byte, error) {
+	return c.rewardUTXOs[args.TxID], nil
+}
+
+func (*fakeActivityClient) GetStakingAssetID(context.Context, ids.ID, ...rpc.Option) (ids.ID, error) {
+	return activityAssetID, nil
+}
+
+type fakeBlockIndex struct {
+	containers []indexer.Container
+}
+
+func (i *fakeBlockIndex) GetLastAccepted(context.Context, ...rpc.Option) (indexer.Container, uint64, error) {
+	return i.containers[len(i.containers)-1], uint64(len(i.containers) - 1), nil
+}
+
+func (i *fakeBlockIndex) GetContainerRange(_ context.Context, startIndex uint64, numToFetch int, _ ...rpc.Option) ([]indexer.Container, error) {
+	end := min(int(startIndex)+numToFetch, len(i.containers))
+	return i.containers[startIndex:end], nil
+}
+
+func activityOut(amount uint64, addr ids.ShortID) *avax.TransferableOutput {
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: activityAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{addr}},
+		},
+	}
+}
+
+func activityIn(txID ids.ID, index uint32, amount uint64) *avax.TransferableInput {
+	return &avax.TransferableInput{
+		UTXOID: avax.UTXOID{TxID: txID, OutputIndex: index},
+		Asset:  avax.Asset{ID: activityAssetID},
+		In:     &secp256k1fx.TransferInput{Amt: amount, Input: secp256k1fx.Input{SigIndices: []uint32{0}}},
+	}
+}
+
+func activityBaseTx(ins []*avax.TransferableInput, outs []*avax.TransferableOutput) txs.BaseTx {
+	return txs.BaseTx{BaseTx: avax.BaseTx{
+		NetworkID:    constants.FujiID,
+		BlockchainID: constants.PlatformChainID,
+		Ins:          ins,
+		Outs:         outs,
+	}}
+}
+
+func initializedTx(t *testing.T, unsignedTx txs.UnsignedTx) *txs.Tx {
+	tx := &txs.Tx{Unsigned: unsignedTx}
+	require.NoError(t, tx.Initialize(txs.Codec))
+	return tx
+}
+
+func TestGetPChainActivity(t *testing.T) {
+	require := require.New(t)
+	addr := ids.GenerateTestShortID()
+	other := ids.GenerateTestShortID()
+	funder := ids.GenerateTestShortID()
+	otherAddr, err := address.Format("P", constants.FujiHRP, other[:])
+	require.NoError(err)
+	funderAddr, err := address.Format("P", constants.FujiHRP, funder[:])
+	require.NoError(err)
+
+	// funding tx, outside of the range
+	fundTxBase := activityBaseTx(nil, []*avax.TransferableOutput{activityOut(10_000, addr), activityOut(5_000, funder)})
+	fundTx := initializedTx(t, &fundTxBase)
+	// addr sends 3,000 to other, with 6,999 change and a fee of 1
+	sendTxBase := activityBaseTx(
+		[]*avax.TransferableInput{activityIn(fundTx.ID(), 0, 10_000)},
+		[]*avax.TransferableOutput{activityOut(3_000, other), activityOut(6_999, addr)},
+	)
+	sendTx := initializedTx(t, &sendTxBase)
+	// funder sends 4,000 to addr
+	receiveTxBase := activityBaseTx(
+		[]*avax.TransferableInput{activityIn(fundTx.ID(), 1, 5_000)},
+		[]*avax.TransferableOutput{activityOut(4_000, addr), activityOut(999, funder)},
+	)
+	receiveTx := initializedTx(t, &receiveTxBase)
+	// addr delegates 5,000 out of the 6,999 change
+	delegateTx := initializedTx(t, &txs.AddDelegatorTx{
+		BaseTx: activityBaseTx(
+			[]*avax.TransferableInput{activityIn(sendTx.ID(), 1, 6_999)},
+			[]*avax.TransferableOutput{activityOut(1_998, addr)},
+		),
+		Validator:              txs.Validator{NodeID: ids.GenerateTestNodeID(), Wght: 5_000},
+		StakeOuts:              []*avax.TransferableOutput{activityOut(5_000, addr)},
+		DelegationRewardsOwner: &secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{addr}},
+	})
+	rewardTx := initializedTx(t, &txs.RewardValidatorTx{TxID: delegateTx.ID()})
+	rewardUTXO := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: delegateTx.ID(), OutputIndex: 2},
+		Asset:  avax.Asset{ID: activityAssetID},
+		Out:    activityOut(100, addr).Out,
+	}
+	rewardUTXOBytes, err := txs.Codec.Marshal(txs.CodecVersion, rewardUTXO)
+	require.NoError(err)
+	// unrelated tx
+	unrelatedTxBase := activityBaseTx(
+		[]*avax.TransferableInput{activityIn(receiveTx.ID(), 1, 999)},
+		[]*avax.TransferableOutput{activityOut(998, other)},
+	)
+	unrelatedTx := initializedTx(t, &unrelatedTxBase)
+
+	client := &fakeActivityClient{
+		txs:         map[ids.ID][]byte{},
+		rewardUTXOs: map[ids.ID][][]byte{delegateTx.ID(): {rewardUTXOBytes}},
+	}
+	for _, tx := range []*txs.Tx{fundTx, sendTx, receiveTx, delegateTx, rewardTx, unrelatedTx} {
+		client.txs[tx.ID()] = tx.Bytes()
+	}
+	start := time.Unix(1_700_000_000, 0)
+	index := &fakeBlockIndex{}
+	for i, blockTxs := range [][]*txs.Tx{{fundTx}, {sendTx, receiveTx}, {delegateTx, unrelatedTx}, {rewardTx}} {
+		blk, err := block.NewBanffStandardBlock(start, ids.GenerateTestID(), uint64(i+1), blockTxs)
+		require.NoError(err)
+		index.containers = append(index.containers, indexer.Container{
+			ID:        blk.ID(),
+			Bytes:     blk.Bytes(),
+			Timestamp: start.Add(time.Duration(i) * time.Hour).UnixNano(),
+		})
+	}
+
+	activities, err := getPChainActivity(client, index, constants.FujiHRP, addr, ActivityRange{StartIndex: 1})
+	require.NoError(err)
+	require.Len(activities, 4)
+
+	require.Equal(sendTx.ID(), activities[0].TxID)
+	require.Equal("BaseTx", activities[0].TxType)
+	require.Equal(uint64(1), activities[0].BlockIndex)
+	require.Equal(uint64(3_001), activities[0].Outflow)
+	require.Equal(uint64(1), activities[0].Fee)
+	require.Equal([]string{otherAddr}, activities[0].Counterparties)
+
+	require.Equal(receiveTx.ID(), activities[1].TxID)
+	require.Equal(uint64(4_000), activities[1].Inflow)
+	require.Zero(activities[1].Fee)
+	require.Equal([]string{funderAddr}, activities[1].Counterparties)
+
+	require.Equal(delegateTx.ID(), activities[2].TxID)
+	require.Equal("AddDelegatorTx", activities[2].TxType)
+	require.Equal(uint64(5_001), activities[2].Outflow)
+	require.Equal(uint64(5_000), activities[2].Staked)
+	require.Equal(uint64(1), activities[2].Fee)
+
+	require.Equal(rewardTx.ID(), activities[3].TxID)
+	require.Equal(uint64(5_100), activities[3].Inflow)
+	require.Equal([]string{rewardsCounterparty}, activities[3].Counterparties)
+
+	ab := addressbook.New()
+	require.NoError(ab.Add("treasury", funderAddr))
+	annotated := AnnotatePChainActivity(activities, ab)
+	require.Equal([]string{otherAddr}, annotated[0].Counterparties)
+	require.Equal([]string{funderAddr + " (treasury)"}, annotated[1].Counterparties)
+	require.Equal([]string{rewardsCounterparty}, annotated[3].Counterparties)
+	require.Equal([]string{funderAddr}, activities[1].Counterparties)
+
+	// time range
+	activities, err = getPChainActivity(client, index, constants.FujiHRP, addr, ActivityRange{
+		Start: start.Add(90 * time.Minute),
+		End:   start.Add(150 * time.Minute),
+	})
+	require.NoError(err)
+	require.Len(activities, 1)
+	require.Equal(delegateTx.ID(), activities[0].TxID)
+
+	buf := &bytes.Buffer{}
+	require.NoError(WritePChainActivityCSV(buf, activities))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(lines, 2)
+	require.True(strings.HasPrefix(lines[0], "timestamp,blockIndex,blockID,txID,txType"))
+	require.Contains(lines[1], ",AddDelegatorTx,0,5001,5000,1,")
+}
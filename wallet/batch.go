@@ -0,0 +1,191 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+const batchStatusPollFrequency = time.Second
+
+var (
+	ErrEmptyBatchStepName     = errors.New("batch step name cannot be empty")
+	ErrDuplicatedBatchStep    = errors.New("duplicated batch step name")
+	ErrNilBatchStepBuilder    = errors.New("batch step build function cannot be nil")
+	ErrBatchWithoutNetworkURI = errors.New("wallet has no network URI to check tx status")
+)
+
+// BatchStep is one P-Chain tx of a Batch
+type BatchStep struct {
+	// Name identifies the step, so that later steps can refer to its tx
+	Name string
+	// Build builds and signs the tx of the step using [w]. [state] contains the IDs
+	// of the txs accepted on previous steps (eg the subnet ID of a create subnet step)
+	Build func(w Wallet, state *BatchState) (*txs.Tx, error)
+}
+
+// BatchState records the progress of a Batch. It can be serialized to resume
+// a batch on a different process
+type BatchState struct {
+	// Confirmed contains the IDs of the accepted txs, by step name
+	Confirmed map[string]ids.ID `json:"confirmed"`
+	// PendingStep is the step whose tx was issued but not confirmed as accepted
+	PendingStep string `json:"pendingStep,omitempty"`
+	// PendingTxID is the ID of the tx issued for PendingStep
+	PendingTxID ids.ID `json:"pendingTxID"`
+}
+
+// TxID returns the ID of the tx accepted on step [name]
+func (s *BatchState) TxID(name string) (ids.ID, error) {
+	txID, ok := s.Confirmed[name]
+	if !ok {
+		return ids.Empty, fmt.Errorf("batch step %q has not been confirmed", name)
+	}
+	return txID, nil
+}
+
+// BatchStepError is returned when a batch stops at a given step
+type BatchStepError struct {
+	Step  string
+	Index int
+	Err   error
+}
+
+func (e *BatchStepError) Error() string {
+	return fmt.Sprintf("batch step %d (%s) failed: %s", e.Index, e.Step, e.Err)
+}
+
+func (e *BatchStepError) Unwrap() error {
+	return e.Err
+}
+
+// txStatusClient is the subset of platformvm.Client used to check tx status
+type txStatusClient interface {
+	GetTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (*platformvm.GetTxStatusResponse, error)
+	AwaitTxDecided(ctx context.Context, txID ids.ID, freq time.Duration, options ...rpc.Option) (*platformvm.GetTxStatusResponse, error)
+}
+
+// Batch issues an ordered list of dependent P-Chain txs (eg create subnet -> create chain),
+// waiting for the acceptance of each tx before building the next one.
+// If a step fails, Execute can be called again to roll forward from the last
+// confirmed step. A tx whose acceptance is unknown is checked on the network before
+// being rebuilt, so no step is executed twice
+type Batch struct {
+	wallet  Wallet
+	steps   []BatchStep
+	State   BatchState
+	client  txStatusClient
+	issueTx func(ctx context.Context, tx *txs.Tx) error
+}
+
+// NewBatch creates a batch that issues [steps] in order with [wallet]
+func NewBatch(wallet Wallet, steps ...BatchStep) (*Batch, error) {
+	names := map[string]struct{}{}
+	for _, step := range steps {
+		if step.Name == "" {
+			return nil, ErrEmptyBatchStepName
+		}
+		if _, ok := names[step.Name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatedBatchStep, step.Name)
+		}
+		if step.Build == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNilBatchStepBuilder, step.Name)
+		}
+		names[step.Name] = struct{}{}
+	}
+	b := &Batch{
+		steps: steps,
+		State: BatchState{
+			Confirmed: map[string]ids.ID{},
+		},
+	}
+	b.SetWallet(wallet)
+	return b, nil
+}
+
+// SetWallet replaces the wallet used to build and issue the remaining steps. Useful
+// to resume a batch with a wallet whose UTXO set reflects the already accepted txs
+func (b *Batch) SetWallet(wallet Wallet) {
+	b.wallet = wallet
+	if wallet.config != nil {
+		b.client = platformvm.NewClient(wallet.config.URI)
+	}
+	b.issueTx = func(ctx context.Context, tx *txs.Tx) error {
+		return b.wallet.P().IssueTx(tx, common.WithContext(ctx))
+	}
+}
+
+// Execute builds and issues all the steps not yet confirmed, in order, waiting
+// for each tx to be accepted. Returns a *BatchStepError on failure
+func (b *Batch) Execute(ctx context.Context) (*BatchState, error) {
+	if b.State.Confirmed == nil {
+		b.State.Confirmed = map[string]ids.ID{}
+	}
+	for i, step := range b.steps {
+		if _, ok := b.State.Confirmed[step.Name]; ok {
+			continue
+		}
+		if b.State.PendingStep == step.Name && b.State.PendingTxID != ids.Empty {
+			accepted, err := b.checkPending(ctx)
+			if err != nil {
+				return &b.State, &BatchStepError{Step: step.Name, Index: i, Err: err}
+			}
+			if accepted {
+				continue
+			}
+		}
+		tx, err := step.Build(b.wallet, &b.State)
+		if err != nil {
+			return &b.State, &BatchStepError{Step: step.Name, Index: i, Err: fmt.Errorf("failure building tx: %w", err)}
+		}
+		b.State.PendingStep = step.Name
+		b.State.PendingTxID = tx.ID()
+		if err := b.issueTx(ctx, tx); err != nil {
+			return &b.State, &BatchStepError{Step: step.Name, Index: i, Err: fmt.Errorf("failure issuing tx %s: %w", tx.ID(), err)}
+		}
+		b.confirmPending()
+	}
+	return &b.State, nil
+}
+
+// checkPending resolves the status of the pending tx, waiting for it if it is still
+// processing. Returns true if it was accepted
+func (b *Batch) checkPending(ctx context.Context) (bool, error) {
+	if b.client == nil {
+		return false, ErrBatchWithoutNetworkURI
+	}
+	resp, err := b.client.GetTxStatus(ctx, b.State.PendingTxID)
+	if err != nil {
+		return false, err
+	}
+	if resp.Status == status.Processing {
+		resp, err = b.client.AwaitTxDecided(ctx, b.State.PendingTxID, batchStatusPollFrequency)
+		if err != nil {
+			return false, err
+		}
+	}
+	if resp.Status == status.Committed {
+		b.confirmPending()
+		return true, nil
+	}
+	// dropped, aborted or unknown: the step needs to be rebuilt
+	b.State.PendingStep = ""
+	b.State.PendingTxID = ids.Empty
+	return false, nil
+}
+
+func (b *Batch) confirmPending() {
+	b.State.Confirmed[b.State.PendingStep] = b.State.PendingTxID
+	b.State.PendingStep = ""
+	b.State.PendingTxID = ids.Empty
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStatusClient struct {
+	statuses map[ids.ID]status.Status
+}
+
+func (c *fakeStatusClient) GetTxStatus(_ context.Context, txID ids.ID, _ ...rpc.Option) (*platformvm.GetTxStatusResponse, error) {
+	return &platformvm.GetTxStatusResponse{Status: c.statuses[txID]}, nil
+}
+
+func (c *fakeStatusClient) AwaitTxDecided(ctx context.Context, txID ids.ID, _ time.Duration, options ...rpc.Option) (*platformvm.GetTxStatusResponse, error) {
+	return c.GetTxStatus(ctx, txID, options...)
+}
+
+func newBatchTestTx(t *testing.T, memo string) *txs.Tx {
+	tx := &txs.Tx{
+		Unsigned: &txs.BaseTx{
+			BaseTx: avax.BaseTx{
+				NetworkID:    constants.FujiID,
+				BlockchainID: constants.PlatformChainID,
+				Memo:         []byte(memo),
+			},
+		},
+	}
+	require.NoError(t, tx.Initialize(txs.Codec))
+	return tx
+}
+
+func TestNewBatchValidation(t *testing.T) {
+	require := require.New(t)
+	build := func(Wallet, *BatchState) (*txs.Tx, error) { return nil, nil }
+	_, err := NewBatch(Wallet{}, BatchStep{Build: build})
+	require.ErrorIs(err, ErrEmptyBatchStepName)
+	_, err = NewBatch(Wallet{}, BatchStep{Name: "a", Build: build}, BatchStep{Name: "a", Build: build})
+	require.ErrorIs(err, ErrDuplicatedBatchStep)
+	_, err = NewBatch(Wallet{}, BatchStep{Name: "a"})
+	require.ErrorIs(err, ErrNilBatchStepBuilder)
+}
+
+func TestBatchRollForward(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	errIssue := errors.New("issue failure")
+
+	createSubnetTx := newBatchTestTx(t, "create subnet")
+	createChainTx := newBatchTestTx(t, "create chain")
+	builds := map[string]int{}
+	var subnetIDSeen ids.ID
+	batch, err := NewBatch(
+		Wallet{},
+		BatchStep{
+			Name: "createSubnet",
+			Build: func(Wallet, *BatchState) (*txs.Tx, error) {
+				builds["createSubnet"]++
+				return createSubnetTx, nil
+			},
+		},
+		BatchStep{
+			Name: "createChain",
+			Build: func(_ Wallet, state *BatchState) (*txs.Tx, error) {
+				builds["createChain"]++
+				subnetID, err := state.TxID("createSubnet")
+				if err != nil {
+					return nil, err
+				}
+				subnetIDSeen = subnetID
+				return createChainTx, nil
+			},
+		},
+	)
+	require.NoError(err)
+	client := &fakeStatusClient{statuses: map[ids.ID]status.Status{}}
+	batch.client = client
+
+	// create subnet is issued but its acceptance can't be verified
+	issued := []ids.ID{}
+	batch.issueTx = func(_ context.Context, tx *txs.Tx) error {
+		issued = append(issued, tx.ID())
+		return errIssue
+	}
+	state, err := batch.Execute(ctx)
+	var stepErr *BatchStepError
+	require.ErrorAs(err, &stepErr)
+	require.ErrorIs(err, errIssue)
+	require.Equal("createSubnet", stepErr.Step)
+	require.Equal(createSubnetTx.ID(), state.PendingTxID)
+
+	// on resume, create subnet is found committed and not reissued
+	client.statuses[createSubnetTx.ID()] = status.Committed
+	batch.issueTx = func(_ context.Context, tx *txs.Tx) error {
+		issued = append(issued, tx.ID())
+		return nil
+	}
+	state, err = batch.Execute(ctx)
+	require.NoError(err)
+	require.Equal([]ids.ID{createSubnetTx.ID(), createChainTx.ID()}, issued)
+	require.Equal(map[string]int{"createSubnet": 1, "createChain": 1}, builds)
+	require.Equal(createSubnetTx.ID(), subnetIDSeen)
+	require.Equal(map[string]ids.ID{
+		"createSubnet": createSubnetTx.ID(),
+		"createChain":  createChainTx.ID(),
+	}, state.Confirmed)
+	require.Equal(ids.Empty, state.PendingTxID)
+
+	// nothing left to do
+	_, err = batch.Execute(ctx)
+	require.NoError(err)
+	require.Len(issued, 2)
+}
+
+func TestBatchRebuildsDroppedTx(t *testing.T) {
+	require := require.New(t)
+	tx := newBatchTestTx(t, "base")
+	builds := 0
+	batch, err := NewBatch(Wallet{}, BatchStep{
+		Name: "base",
+		Build: func(Wallet, *BatchState) (*txs.Tx, error) {
+			builds++
+			return tx, nil
+		},
+	})
+	require.NoError(err)
+	batch.client = &fakeStatusClient{statuses: map[ids.ID]status.Status{tx.ID(): status.Dropped}}
+	batch.State.PendingStep = "base"
+	batch.State.PendingTxID = tx.ID()
+	batch.issueTx = func(context.Context, *txs.Tx) error { return nil }
+	state, err := batch.Execute(context.Background())
+	require.NoError(err)
+	require.Equal(1, builds)
+	require.Equal(tx.ID(), state.Confirmed["base"])
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/math"
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+var ErrFeeCapExceeded = errors.New("total tx fees exceed the given cap")
+
+// TxFee returns the fee the P-Chain charges for a tx of the given kind, as
+// informed by the network the wallet is connected to. Permissionless validator
+// and delegator txs are priced as primary network ones, see MultisigTxFee
+func (w *Wallet) TxFee(kind multisig.TxKind) (uint64, error) {
+	return txFee(w.P().Builder().Context(), kind, constants.PrimaryNetworkID)
+}
+
+// MultisigTxFee returns the fee the P-Chain charges for [tx], taking into account
+// the subnet permissionless validator and delegator txs are issued for
+func (w *Wallet) MultisigTxFee(tx multisig.Multisig) (uint64, error) {
+	return multisigTxFee(w.P().Builder().Context(), tx)
+}
+
+// TotalTxFee returns the total fee to be paid for issuing a batch of P-Chain
+// txs of the given kinds
+func (w *Wallet) TotalTxFee(kinds ...multisig.TxKind) (uint64, error) {
+	return totalTxFee(w.P().Builder().Context(), kinds)
+}
+
+// CheckTotalTxFee returns ErrFeeCapExceeded if the total fee to be paid for
+// issuing a batch of P-Chain txs of the given kinds is greater than [maxFee]
+func (w *Wallet) CheckTotalTxFee(maxFee uint64, kinds ...multisig.TxKind) error {
+	total, err := w.TotalTxFee(kinds...)
+	if err != nil {
+		return err
+	}
+	if total > maxFee {
+		return fmt.Errorf("%w: %d > %d", ErrFeeCapExceeded, total, maxFee)
+	}
+	return nil
+}
+
+func totalTxFee(context *pbuilder.Context, kinds []multisig.TxKind) (uint64, error) {
+	total := uint64(0)
+	for _, kind := range kinds {
+		fee, err := txFee(context, kind, constants.PrimaryNetworkID)
+		if err != nil {
+			return 0, err
+		}
+		total, err = math.Add64(total, fee)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+func multisigTxFee(context *pbuilder.Context, tx multisig.Multisig) (uint64, error) {
+	kind, err := tx.GetTxKind()
+	if err != nil {
+		return 0, err
+	}
+	subnetID := constants.PrimaryNetworkID
+	if kind == multisig.PChainAddPermissionlessValidatorTx || kind == multisig.PChainAddPermissionlessDelegatorTx {
+		if subnetID, err = tx.GetSubnetID(); err != nil {
+			return 0, err
+		}
+	}
+	return txFee(context, kind, subnetID)
+}
+
+// txFee returns the fee of a tx of [kind]. [subnetID] is the subnet permissionless
+// validator and delegator txs are issued for
+func txFee(context *pbuilder.Context, kind multisig.TxKind, subnetID ids.ID) (uint64, error) {
+	switch kind {
+	case multisig.PChainCreateSubnetTx:
+		return context.CreateSubnetTxFee, nil
+	case multisig.PChainTransformSubnetTx:
+		return context.TransformSubnetTxFee, nil
+	case multisig.PChainCreateChainTx:
+		return context.CreateBlockchainTxFee, nil
+	case multisig.PChainAddPermissionlessValidatorTx:
+		if subnetID != constants.PrimaryNetworkID {
+			return context.AddSubnetValidatorFee, nil
+		}
+		return context.AddPrimaryNetworkValidatorFee, nil
+	case multisig.PChainAddPermissionlessDelegatorTx:
+		if subnetID != constants.PrimaryNetworkID {
+			return context.AddSubnetDelegatorFee, nil
+		}
+		return context.AddPrimaryNetworkDelegatorFee, nil
+	case multisig.PChainAddValidatorTx:
+		return context.AddPrimaryNetworkValidatorFee, nil
+	case multisig.PChainAddDelegatorTx:
+		return context.AddPrimaryNetworkDelegatorFee, nil
+	case multisig.PChainAddSubnetValidatorTx:
+		return context.AddSubnetValidatorFee, nil
+	case multisig.PChainRemoveSubnetValidatorTx,
+		multisig.PChainTransferSubnetOwnershipTx,
+		multisig.PChainBaseTx,
+		multisig.PChainImportTx,
+		multisig.PChainExportTx:
+		return context.BaseTxFee, nil
+	default:
+		return 0, fmt.Errorf("unexpected tx kind %d", kind)
+	}
+}
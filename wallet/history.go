@@ -0,0 +1,370 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/addressbook"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+)
+
+// TxHistoryFormat is a format tx history can be exported to
+type TxHistoryFormat string
+
+const (
+	TxHistoryJSON TxHistoryFormat = "json"
+	TxHistoryCSV  TxHistoryFormat = "csv"
+)
+
+var (
+	ErrTxHistoryNotEnabled        = errors.New("wallet tx history is not enabled")
+	ErrUnsupportedTxHistoryFormat = errors.New("unsupported tx history format")
+)
+
+// TxRecord is a tx issued by a wallet with tx history enabled. Amounts are in nAVAX
+type TxRecord struct {
+	TxID  ids.ID `json:"txID"`
+	Chain string `json:"chain"`
+	Kind  string `json:"kind"`
+	// Fee is the AVAX burned by the tx
+	Fee      uint64    `json:"fee"`
+	IssuedAt time.Time `json:"issuedAt"`
+	// Network is the name of the network, if it is a well known one
+	Network   string `json:"network,omitempty"`
+	NetworkID uint32 `json:"networkID"`
+	// Endpoint is the API endpoint the tx was issued to
+	Endpoint string `json:"endpoint,omitempty"`
+	// Error is set if the tx failed to be issued or was not committed
+	Error string `json:"error,omitempty"`
+	// Recipients are the owners of the P-Chain outputs of the tx, change included
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// TxHistoryStore keeps the records of the txs issued by a wallet, eg. to persist
+// them as an audit trail. Implementations must be safe for concurrent use
+type TxHistoryStore interface {
+	// Append adds [record] to the store
+	Append(record TxRecord) error
+	// Records returns all the records of the store, in issuance order
+	Records() ([]TxRecord, error)
+}
+
+// MemoryTxHistoryStore keeps tx records in memory, for the wallet session
+type MemoryTxHistoryStore struct {
+	lock    sync.Mutex
+	records []TxRecord
+}
+
+var _ TxHistoryStore = (*MemoryTxHistoryStore)(nil)
+
+func NewMemoryTxHistoryStore() *MemoryTxHistoryStore {
+	return &MemoryTxHistoryStore{}
+}
+
+func (s *MemoryTxHistoryStore) Append(record TxRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *MemoryTxHistoryStore) Records() ([]TxRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]TxRecord{}, s.records...), nil
+}
+
+// FileTxHistoryStore appends tx records to a file, one JSON record per line, so the
+// history survives the wallet session and can be shared by several of them
+type FileTxHistoryStore struct {
+	lock sync.Mutex
+	path string
+}
+
+var _ TxHistoryStore = (*FileTxHistoryStore)(nil)
+
+// NewFileTxHistoryStore creates a store on [path]. The file is created on the
+// first append
+func NewFileTxHistoryStore(path string) *FileTxHistoryStore {
+	return &FileTxHistoryStore{path: path}
+}
+
+func (s *FileTxHistoryStore) Append(record TxRecord) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, constants.WriteReadUserOnlyPerms)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(recordBytes, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileTxHistoryStore) Records() ([]TxRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records := []TxRecord{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		record := TxRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid tx record on %s line %d: %w", s.path, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// SetTxHistory makes the wallet record in [store] every P-Chain tx it issues, including
+// the ones that fail to be issued or committed. X-Chain and C-Chain txs are not recorded.
+// Each call adds a store, and TxHistory reads the last one
+func (w *Wallet) SetTxHistory(store TxHistoryStore) {
+	endpoint := ""
+	if w.config != nil {
+		endpoint = w.config.URI
+	}
+	w.txHistory = store
+	w.Wallet = primary.NewWallet(
+		newHistoryPWallet(w.Wallet.P(), store, endpoint),
+		w.Wallet.X(),
+		w.Wallet.C(),
+	)
+}
+
+// TxHistory returns the records of the txs issued by the wallet. Returns
+// ErrTxHistoryNotEnabled if SetTxHistory was not called
+func (w *Wallet) TxHistory() ([]TxRecord, error) {
+	if w.txHistory == nil {
+		return nil, ErrTxHistoryNotEnabled
+	}
+	return w.txHistory.Records()
+}
+
+// ExportTxHistory writes the records of the txs issued by the wallet into [out],
+// in the given [format]
+func (w *Wallet) ExportTxHistory(out io.Writer, format TxHistoryFormat) error {
+	records, err := w.TxHistory()
+	if err != nil {
+		return err
+	}
+	return WriteTxHistory(out, format, records)
+}
+
+// WriteTxHistory writes [records] into [w], in the given [format]
+func WriteTxHistory(w io.Writer, format TxHistoryFormat, records []TxRecord) error {
+	switch format {
+	case TxHistoryJSON:
+		return WriteTxHistoryJSON(w, records)
+	case TxHistoryCSV:
+		return WriteTxHistoryCSV(w, records)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedTxHistoryFormat, format)
+	}
+}
+
+// WriteTxHistoryJSON writes [records] as an indented JSON array into [w]
+func WriteTxHistoryJSON(w io.Writer, records []TxRecord) error {
+	if records == nil {
+		records = []TxRecord{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// AnnotateTxHistory returns a copy of [records] with the recipient addresses annotated
+// with their labels in [ab], eg "P-fuji1... (treasury)"
+func AnnotateTxHistory(records []TxRecord, ab *addressbook.AddressBook) []TxRecord {
+	annotated := make([]TxRecord, 0, len(records))
+	for _, record := range records {
+		record.Recipients = utils.Map(record.Recipients, ab.Annotate)
+		annotated = append(annotated, record)
+	}
+	return annotated
+}
+
+// WriteTxHistoryCSV writes [records] as CSV into [w], with a header row. Fees
+// are in nAVAX, and recipients are separated by spaces
+func WriteTxHistoryCSV(w io.Writer, records []TxRecord) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{
+		"issuedAt", "txID", "chain", "kind", "fee", "network", "networkID", "endpoint", "error", "recipients",
+	}); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := csvWriter.Write([]string{
+			record.IssuedAt.UTC().Format(time.RFC3339),
+			record.TxID.String(),
+			record.Chain,
+			record.Kind,
+			strconv.FormatUint(record.Fee, 10),
+			record.Network,
+			strconv.FormatUint(uint64(record.NetworkID), 10),
+			record.Endpoint,
+			record.Error,
+			strings.Join(record.Recipients, " "),
+		}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// historyPWallet records in a TxHistoryStore the P-Chain txs issued with the wrapped
+// wallet. Txs built by the wallet are signed and then issued with IssueTx, so they
+// are recorded once
+type historyPWallet struct {
+	*issuingPWallet
+	store    TxHistoryStore
+	endpoint string
+}
+
+var _ p.Wallet = (*historyPWallet)(nil)
+
+func newHistoryPWallet(wallet p.Wallet, store TxHistoryStore, endpoint string) *historyPWallet {
+	w := &historyPWallet{
+		store:    store,
+		endpoint: endpoint,
+	}
+	w.issuingPWallet = &issuingPWallet{
+		Wallet: wallet,
+		issueUnsignedTx: func(utx txs.UnsignedTx, options ...common.Option) (*txs.Tx, error) {
+			ctx := common.NewOptions(options).Context()
+			tx, err := psigner.SignUnsigned(ctx, w.Signer(), utx)
+			if err != nil {
+				return nil, err
+			}
+			return tx, w.IssueTx(tx, options...)
+		},
+	}
+	return w
+}
+
+func (w *historyPWallet) IssueTx(tx *txs.Tx, options ...common.Option) error {
+	issuedAt := time.Now().UTC()
+	issueErr := w.Wallet.IssueTx(tx, options...)
+	if err := w.store.Append(w.record(tx, issuedAt, issueErr)); err != nil {
+		return errors.Join(issueErr, fmt.Errorf("failure recording tx %s: %w", tx.ID(), err))
+	}
+	return issueErr
+}
+
+func (w *historyPWallet) record(tx *txs.Tx, issuedAt time.Time, issueErr error) TxRecord {
+	pContext := w.Builder().Context()
+	record := TxRecord{
+		TxID:      tx.ID(),
+		Chain:     "P",
+		Fee:       burnedAVAX(tx.Unsigned, pContext.AVAXAssetID),
+		IssuedAt:  issuedAt,
+		NetworkID: pContext.NetworkID,
+		Endpoint:  w.endpoint,
+	}
+	if kind, err := multisig.New(tx).GetTxKind(); err == nil {
+		record.Kind = kind.String()
+	}
+	network := avalanche.NetworkFromNetworkID(pContext.NetworkID)
+	if network != avalanche.UndefinedNetwork {
+		record.Network = network.Kind.String()
+	}
+	record.Recipients = recipients(tx.Unsigned, network.HRP())
+	if issueErr != nil {
+		record.Error = issueErr.Error()
+	}
+	return record
+}
+
+// recipients returns the sorted P-Chain addresses owning the outputs of [utx]
+func recipients(utx txs.UnsignedTx, hrp string) []string {
+	addrs := map[string]struct{}{}
+	for _, out := range utx.Outputs() {
+		owners, _ := transferOutput(out.Out)
+		if owners == nil {
+			continue
+		}
+		for _, addr := range owners.Addrs {
+			if formatted, err := address.Format("P", hrp, addr[:]); err == nil {
+				addrs[formatted] = struct{}{}
+			}
+		}
+	}
+	return sortedKeys(addrs)
+}
+
+// burnedAVAX returns the [avaxAssetID] consumed by [utx] and not sent to any output,
+// that is, the fee paid
+func burnedAVAX(utx txs.UnsignedTx, avaxAssetID ids.ID) uint64 {
+	ins, err := multisig.GetInputs(utx)
+	if err != nil {
+		return 0
+	}
+	outs := utx.Outputs()
+	switch utx := utx.(type) {
+	case *txs.ImportTx:
+		ins = append(append([]*avax.TransferableInput{}, ins...), utx.ImportedInputs...)
+	case *txs.ExportTx:
+		outs = append(append([]*avax.TransferableOutput{}, outs...), utx.ExportedOutputs...)
+	}
+	if stakerTx, ok := utx.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		outs = append(append([]*avax.TransferableOutput{}, outs...), stakerTx.Stake()...)
+	}
+	var consumed, produced uint64
+	for _, in := range ins {
+		if in.AssetID() == avaxAssetID {
+			consumed += in.In.Amount()
+		}
+	}
+	for _, out := range outs {
+		if out.AssetID() == avaxAssetID {
+			produced += out.Out.Amount()
+		}
+	}
+	if produced > consumed {
+		return 0
+	}
+	return consumed - produced
+}
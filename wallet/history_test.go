@@ -0,0 +1,153 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/addressbook"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/stretchr/testify/require"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+)
+
+var errHistoryTestIssue = errors.New("tx rejected")
+
+type fakeHistoryBuilder struct {
+	pbuilder.Builder
+}
+
+func (*fakeHistoryBuilder) Context() *pbuilder.Context {
+	return &pbuilder.Context{NetworkID: constants.FujiID, AVAXAssetID: activityAssetID}
+}
+
+type fakeHistorySigner struct{}
+
+func (fakeHistorySigner) Sign(_ context.Context, tx *txs.Tx) error {
+	return tx.Initialize(txs.Codec)
+}
+
+type fakeHistoryPWallet struct {
+	p.Wallet
+	issued []ids.ID
+	fail   bool
+}
+
+func (*fakeHistoryPWallet) Builder() pbuilder.Builder {
+	return &fakeHistoryBuilder{}
+}
+
+func (*fakeHistoryPWallet) Signer() psigner.Signer {
+	return fakeHistorySigner{}
+}
+
+func (w *fakeHistoryPWallet) IssueTx(tx *txs.Tx, _ ...common.Option) error {
+	if w.fail {
+		return errHistoryTestIssue
+	}
+	w.issued = append(w.issued, tx.ID())
+	return nil
+}
+
+func TestHistoryPWallet(t *testing.T) {
+	require := require.New(t)
+	walletAddr := ids.GenerateTestShortID()
+	inner := &fakeHistoryPWallet{}
+	store := NewMemoryTxHistoryStore()
+	wallet := newHistoryPWallet(inner, store, "https://api.avax-test.network")
+
+	baseTx := activityBaseTx(
+		[]*avax.TransferableInput{activityIn(ids.GenerateTestID(), 0, 1_000)},
+		[]*avax.TransferableOutput{activityOut(900, walletAddr)},
+	)
+	tx, err := wallet.IssueUnsignedTx(&baseTx)
+	require.NoError(err)
+	require.Equal([]ids.ID{tx.ID()}, inner.issued)
+
+	exportTx := &txs.ExportTx{
+		BaseTx: activityBaseTx(
+			[]*avax.TransferableInput{activityIn(ids.GenerateTestID(), 0, 10_000)},
+			[]*avax.TransferableOutput{activityOut(4_000, walletAddr)},
+		),
+		DestinationChain: ids.GenerateTestID(),
+		ExportedOutputs:  []*avax.TransferableOutput{activityOut(5_000, walletAddr)},
+	}
+	inner.fail = true
+	_, err = wallet.IssueUnsignedTx(exportTx)
+	require.ErrorIs(err, errHistoryTestIssue)
+
+	records, err := store.Records()
+	require.NoError(err)
+	require.Len(records, 2)
+	require.Equal(tx.ID(), records[0].TxID)
+	require.Equal("P", records[0].Chain)
+	require.Equal("BaseTx", records[0].Kind)
+	require.Equal(uint64(100), records[0].Fee)
+	require.Equal("Fuji", records[0].Network)
+	require.Equal(constants.FujiID, records[0].NetworkID)
+	require.Equal("https://api.avax-test.network", records[0].Endpoint)
+	require.False(records[0].IssuedAt.IsZero())
+	require.Empty(records[0].Error)
+	walletAddrStr, err := address.Format("P", constants.FujiHRP, walletAddr[:])
+	require.NoError(err)
+	require.Equal([]string{walletAddrStr}, records[0].Recipients)
+	require.Equal("ExportTx", records[1].Kind)
+	require.Equal(uint64(1_000), records[1].Fee)
+	require.Equal(errHistoryTestIssue.Error(), records[1].Error)
+
+	out := &bytes.Buffer{}
+	require.NoError(WriteTxHistory(out, TxHistoryCSV, records))
+	rows, err := csv.NewReader(out).ReadAll()
+	require.NoError(err)
+	require.Len(rows, 3)
+	require.Equal([]string{"issuedAt", "txID", "chain", "kind", "fee", "network", "networkID", "endpoint", "error", "recipients"}, rows[0])
+	require.Equal(tx.ID().String(), rows[1][1])
+	require.Equal("100", rows[1][4])
+
+	ab := addressbook.New()
+	require.NoError(ab.Add("treasury", walletAddrStr))
+	annotated := AnnotateTxHistory(records, ab)
+	require.Equal([]string{walletAddrStr + " (treasury)"}, annotated[0].Recipients)
+	require.Equal([]string{walletAddrStr}, records[0].Recipients)
+
+	out.Reset()
+	require.NoError(WriteTxHistory(out, TxHistoryJSON, records))
+	decoded := []TxRecord{}
+	require.NoError(json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(decoded, 2)
+	require.Equal(records[1].TxID, decoded[1].TxID)
+	require.ErrorIs(WriteTxHistory(out, "xml", records), ErrUnsupportedTxHistoryFormat)
+
+	_, err = (&Wallet{}).TxHistory()
+	require.ErrorIs(err, ErrTxHistoryNotEnabled)
+}
+
+func TestFileTxHistoryStore(t *testing.T) {
+	require := require.New(t)
+	store := NewFileTxHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	records, err := store.Records()
+	require.NoError(err)
+	require.Empty(records)
+
+	first := TxRecord{TxID: ids.GenerateTestID(), Chain: "P", Kind: "CreateSubnetTx", Fee: 1_000_000}
+	second := TxRecord{TxID: ids.GenerateTestID(), Chain: "P", Kind: "CreateChainTx", Error: "failed"}
+	require.NoError(store.Append(first))
+	require.NoError(store.Append(second))
+	records, err = store.Records()
+	require.NoError(err)
+	require.Equal([]TxRecord{first, second}, records)
+}
@@ -0,0 +1,209 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+
+	vmsigner "github.com/ava-labs/avalanchego/vms/platformvm/signer"
+)
+
+// issuingPWallet builds P-Chain txs with the builder of the wrapped wallet, and issues
+// all of them with [issueUnsignedTx]. It is the base of the P-Chain wallet wrappers that
+// check or record every tx the wallet issues
+type issuingPWallet struct {
+	p.Wallet
+	issueUnsignedTx func(utx txs.UnsignedTx, options ...common.Option) (*txs.Tx, error)
+}
+
+func (w *issuingPWallet) IssueUnsignedTx(utx txs.UnsignedTx, options ...common.Option) (*txs.Tx, error) {
+	return w.issueUnsignedTx(utx, options...)
+}
+
+// issue builds a tx with [build] and issues it through IssueUnsignedTx
+func issue[T txs.UnsignedTx](w *issuingPWallet, options []common.Option, build func() (T, error)) (*txs.Tx, error) {
+	utx, err := build()
+	if err != nil {
+		return nil, err
+	}
+	return w.IssueUnsignedTx(utx, options...)
+}
+
+func (w *issuingPWallet) IssueBaseTx(
+	outputs []*avax.TransferableOutput,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.BaseTx, error) {
+		return w.Builder().NewBaseTx(outputs, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueAddValidatorTx(
+	vdr *txs.Validator,
+	rewardsOwner *secp256k1fx.OutputOwners,
+	shares uint32,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.AddValidatorTx, error) {
+		return w.Builder().NewAddValidatorTx(vdr, rewardsOwner, shares, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueAddSubnetValidatorTx(
+	vdr *txs.SubnetValidator,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.AddSubnetValidatorTx, error) {
+		return w.Builder().NewAddSubnetValidatorTx(vdr, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueRemoveSubnetValidatorTx(
+	nodeID ids.NodeID,
+	subnetID ids.ID,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.RemoveSubnetValidatorTx, error) {
+		return w.Builder().NewRemoveSubnetValidatorTx(nodeID, subnetID, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueAddDelegatorTx(
+	vdr *txs.Validator,
+	rewardsOwner *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.AddDelegatorTx, error) {
+		return w.Builder().NewAddDelegatorTx(vdr, rewardsOwner, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueCreateChainTx(
+	subnetID ids.ID,
+	genesis []byte,
+	vmID ids.ID,
+	fxIDs []ids.ID,
+	chainName string,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.CreateChainTx, error) {
+		return w.Builder().NewCreateChainTx(subnetID, genesis, vmID, fxIDs, chainName, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueCreateSubnetTx(
+	owner *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.CreateSubnetTx, error) {
+		return w.Builder().NewCreateSubnetTx(owner, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueTransferSubnetOwnershipTx(
+	subnetID ids.ID,
+	owner *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.TransferSubnetOwnershipTx, error) {
+		return w.Builder().NewTransferSubnetOwnershipTx(subnetID, owner, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueImportTx(
+	chainID ids.ID,
+	to *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.ImportTx, error) {
+		return w.Builder().NewImportTx(chainID, to, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueExportTx(
+	chainID ids.ID,
+	outputs []*avax.TransferableOutput,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.ExportTx, error) {
+		return w.Builder().NewExportTx(chainID, outputs, options...)
+	})
+}
+
+func (w *issuingPWallet) IssueTransformSubnetTx(
+	subnetID ids.ID,
+	assetID ids.ID,
+	initialSupply uint64,
+	maxSupply uint64,
+	minConsumptionRate uint64,
+	maxConsumptionRate uint64,
+	minValidatorStake uint64,
+	maxValidatorStake uint64,
+	minStakeDuration time.Duration,
+	maxStakeDuration time.Duration,
+	minDelegationFee uint32,
+	minDelegatorStake uint64,
+	maxValidatorWeightFactor byte,
+	uptimeRequirement uint32,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.TransformSubnetTx, error) {
+		return w.Builder().NewTransformSubnetTx(
+			subnetID,
+			assetID,
+			initialSupply,
+			maxSupply,
+			minConsumptionRate,
+			maxConsumptionRate,
+			minValidatorStake,
+			maxValidatorStake,
+			minStakeDuration,
+			maxStakeDuration,
+			minDelegationFee,
+			minDelegatorStake,
+			maxValidatorWeightFactor,
+			uptimeRequirement,
+			options...,
+		)
+	})
+}
+
+func (w *issuingPWallet) IssueAddPermissionlessValidatorTx(
+	vdr *txs.SubnetValidator,
+	signer vmsigner.Signer,
+	assetID ids.ID,
+	validationRewardsOwner *secp256k1fx.OutputOwners,
+	delegationRewardsOwner *secp256k1fx.OutputOwners,
+	shares uint32,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.AddPermissionlessValidatorTx, error) {
+		return w.Builder().NewAddPermissionlessValidatorTx(
+			vdr,
+			signer,
+			assetID,
+			validationRewardsOwner,
+			delegationRewardsOwner,
+			shares,
+			options...,
+		)
+	})
+}
+
+func (w *issuingPWallet) IssueAddPermissionlessDelegatorTx(
+	vdr *txs.SubnetValidator,
+	assetID ids.ID,
+	rewardsOwner *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return issue(w, options, func() (*txs.AddPermissionlessDelegatorTx, error) {
+		return w.Builder().NewAddPermissionlessDelegatorTx(vdr, assetID, rewardsOwner, options...)
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+var ErrMemoTooLong = errors.New("memo is too long")
+
+// ValidateMemo checks that [memo] fits in the memo field of P-Chain and X-Chain txs
+func ValidateMemo(memo []byte) error {
+	if len(memo) > avax.MaxMemoSize {
+		return fmt.Errorf("%w: %d bytes, max is %d", ErrMemoTooLong, len(memo), avax.MaxMemoSize)
+	}
+	return nil
+}
+
+// MemoOption returns a builder option that sets [memo] on a single P-Chain or X-Chain tx,
+// eg w.P().Builder().NewBaseTx(outputs, option)
+func MemoOption(memo []byte) (common.Option, error) {
+	if err := ValidateMemo(memo); err != nil {
+		return nil, err
+	}
+	return common.WithMemo(memo), nil
+}
+
+// SetMemo sets [memo] on all the P-Chain and X-Chain txs built by the wallet from now on,
+// including the ones built by the subnet and node packages. An empty memo removes it
+func (w *Wallet) SetMemo(memo []byte) error {
+	option, err := MemoOption(memo)
+	if err != nil {
+		return err
+	}
+	w.options = append(w.options, option)
+	w.Wallet = primary.NewWalletWithOptions(w.Wallet, w.options...)
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/stretchr/testify/require"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+type memoTestBackend struct {
+	utxos []*avax.UTXO
+}
+
+func (b *memoTestBackend) UTXOs(context.Context, ids.ID) ([]*avax.UTXO, error) {
+	return b.utxos, nil
+}
+
+func (*memoTestBackend) GetSubnetOwner(context.Context, ids.ID) (fx.Owner, error) {
+	return nil, nil
+}
+
+func TestSetMemo(t *testing.T) {
+	require := require.New(t)
+	addr := ids.GenerateTestShortID()
+	avaxAssetID := ids.GenerateTestID()
+	backend := &memoTestBackend{
+		utxos: []*avax.UTXO{{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: avaxAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1_000_000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}},
+	}
+	builder := pbuilder.New(set.Of(addr), &pbuilder.Context{AVAXAssetID: avaxAssetID, BaseTxFee: 1000}, backend)
+	w := Wallet{
+		Wallet: primary.NewWallet(p.NewWallet(builder, nil, nil, nil), nil, nil),
+	}
+
+	require.ErrorIs(w.SetMemo(make([]byte, avax.MaxMemoSize+1)), ErrMemoTooLong)
+	require.NoError(w.SetMemo([]byte("invoice-42")))
+	tx, err := w.P().Builder().NewBaseTx(nil)
+	require.NoError(err)
+	require.Equal([]byte("invoice-42"), []byte(tx.Memo))
+
+	// a per tx memo overrides the wallet one
+	option, err := MemoOption([]byte("refund-7"))
+	require.NoError(err)
+	tx, err = w.P().Builder().NewBaseTx(nil, option)
+	require.NoError(err)
+	require.Equal([]byte("refund-7"), []byte(tx.Memo))
+
+	require.NoError(w.SetMemo(nil))
+	tx, err = w.P().Builder().NewBaseTx(nil)
+	require.NoError(err)
+	require.Empty(tx.Memo)
+}
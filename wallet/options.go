@@ -0,0 +1,107 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/constants"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/c"
+)
+
+// UTXOFilter decides if a UTXO fetched from the network can be used by the wallet
+// when building txs
+type UTXOFilter func(utxo *avax.UTXO) bool
+
+type options struct {
+	timeout     time.Duration
+	subnetIDs   set.Set[ids.ID]
+	ethKeychain c.EthKeychain
+	utxoFilter  UTXOFilter
+	memo        []byte
+	spendPolicy *SpendPolicy
+	changeOwner *secp256k1fx.OutputOwners
+	txHistory   TxHistoryStore
+}
+
+// Option configures the wallet created by NewWithOptions
+type Option func(*options)
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		timeout:     constants.APIRequestLargeTimeout,
+		subnetIDs:   set.Set[ids.ID]{},
+		ethKeychain: secp256k1fx.NewKeychain(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTimeout sets the maximum time to wait for the wallet state to be fetched
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithSubnetIDs makes the wallet fetch the state of the given subnets, so it is
+// able to generate txs for them (eg add a subnet validator, or create a blockchain)
+func WithSubnetIDs(subnetIDs ...ids.ID) Option {
+	return func(o *options) {
+		o.subnetIDs.Add(subnetIDs...)
+	}
+}
+
+// WithEthKeychain sets the keychain used for C-Chain txs. Defaults to an
+// empty keychain
+func WithEthKeychain(ethKeychain c.EthKeychain) Option {
+	return func(o *options) {
+		o.ethKeychain = ethKeychain
+	}
+}
+
+// WithUTXOFilter restricts the UTXOs the wallet uses to build txs to the ones
+// accepted by [filter]
+func WithUTXOFilter(filter UTXOFilter) Option {
+	return func(o *options) {
+		o.utxoFilter = filter
+	}
+}
+
+// WithMemo sets [memo] on all the P-Chain and X-Chain txs built by the wallet.
+// See Wallet.SetMemo
+func WithMemo(memo []byte) Option {
+	return func(o *options) {
+		o.memo = memo
+	}
+}
+
+// WithSpendPolicy makes the wallet check [policy] on every P-Chain tx before signing it.
+// See Wallet.SetSpendPolicy
+func WithSpendPolicy(policy SpendPolicy) Option {
+	return func(o *options) {
+		o.spendPolicy = &policy
+	}
+}
+
+// WithChangeOwner sends the change of all the P-Chain and X-Chain txs built by the wallet
+// to [owners]. See Wallet.SetChangeOwner
+func WithChangeOwner(owners *secp256k1fx.OutputOwners) Option {
+	return func(o *options) {
+		o.changeOwner = owners
+	}
+}
+
+// WithTxHistory records in [store] every P-Chain tx issued by the wallet.
+// See Wallet.SetTxHistory
+func WithTxHistory(store TxHistoryStore) Option {
+	return func(o *options) {
+		o.txHistory = store
+	}
+}
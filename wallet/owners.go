@@ -0,0 +1,90 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+
+	avagoutils "github.com/ava-labs/avalanchego/utils"
+)
+
+var ErrInvalidOwners = errors.New("invalid output owners")
+
+// NewOutputOwners returns the owners that need [threshold] signatures of [addrs] to
+// spend an output, and only after [locktime], in unix seconds, if not 0. [addrs] are
+// sorted and deduplicated
+func NewOutputOwners(addrs []ids.ShortID, threshold uint32, locktime uint64) (*secp256k1fx.OutputOwners, error) {
+	owners := &secp256k1fx.OutputOwners{
+		Locktime:  locktime,
+		Threshold: threshold,
+		Addrs:     utils.Unique(addrs),
+	}
+	avagoutils.Sort(owners.Addrs)
+	if err := ValidateOwners(owners); err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
+
+// ValidateOwners checks that [owners] can spend the outputs they receive. Owners
+// without addresses are valid on the P-Chain, but funds sent to them are burned, so
+// they are rejected
+func ValidateOwners(owners *secp256k1fx.OutputOwners) error {
+	if owners == nil {
+		return fmt.Errorf("%w: no owners", ErrInvalidOwners)
+	}
+	if err := owners.Verify(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOwners, err)
+	}
+	if owners.Threshold == 0 {
+		return fmt.Errorf("%w: threshold must be at least 1", ErrInvalidOwners)
+	}
+	return nil
+}
+
+// NewTransferOutput returns an output of [amount] of [assetID] for [owners], to be used
+// on txs that send funds, eg. w.P().Builder().NewBaseTx(outputs)
+func NewTransferOutput(assetID ids.ID, amount uint64, owners *secp256k1fx.OutputOwners) (*avax.TransferableOutput, error) {
+	if err := ValidateOwners(owners); err != nil {
+		return nil, err
+	}
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          amount,
+			OutputOwners: *owners,
+		},
+	}, nil
+}
+
+// ChangeOwnerOption returns a builder option that sends the change of a single P-Chain or
+// X-Chain tx to [owners], eg w.P().Builder().NewBaseTx(outputs, option)
+func ChangeOwnerOption(owners *secp256k1fx.OutputOwners) (common.Option, error) {
+	if err := ValidateOwners(owners); err != nil {
+		return nil, err
+	}
+	return common.WithChangeOwner(owners), nil
+}
+
+// SetChangeOwner sends the change of all the P-Chain and X-Chain txs built by the wallet
+// from now on to [owners], instead of to a wallet address. Unlocked stake returned at
+// the end of a staking period also goes to [owners]. SecureWalletIsChangeOwner keeps a
+// change owner set this way
+func (w *Wallet) SetChangeOwner(owners *secp256k1fx.OutputOwners) error {
+	option, err := ChangeOwnerOption(owners)
+	if err != nil {
+		return err
+	}
+	w.changeOwner = owners
+	w.options = append(w.options, option)
+	w.Wallet = primary.NewWalletWithOptions(w.Wallet, w.options...)
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/stretchr/testify/require"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+func TestSetChangeOwner(t *testing.T) {
+	require := require.New(t)
+	addr := ids.GenerateTestShortID()
+	custody := []ids.ShortID{ids.GenerateTestShortID(), ids.GenerateTestShortID(), ids.GenerateTestShortID()}
+	avaxAssetID := ids.GenerateTestID()
+	backend := &memoTestBackend{
+		utxos: []*avax.UTXO{{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: avaxAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:          1_000_000,
+				OutputOwners: secp256k1fx.OutputOwners{Threshold: 1, Addrs: []ids.ShortID{addr}},
+			},
+		}},
+	}
+	builder := pbuilder.New(set.Of(addr), &pbuilder.Context{AVAXAssetID: avaxAssetID, BaseTxFee: 1000}, backend)
+	w := Wallet{
+		Wallet: primary.NewWallet(p.NewWallet(builder, nil, nil, nil), nil, nil),
+	}
+
+	_, err := NewOutputOwners(custody, 4, 0)
+	require.ErrorIs(err, ErrInvalidOwners)
+	_, err = NewOutputOwners(nil, 0, 0)
+	require.ErrorIs(err, ErrInvalidOwners)
+	require.ErrorIs(w.SetChangeOwner(&secp256k1fx.OutputOwners{Threshold: 1}), ErrInvalidOwners)
+
+	owners, err := NewOutputOwners(append(custody, custody[0]), 2, 1_900_000_000)
+	require.NoError(err)
+	require.Len(owners.Addrs, 3)
+	require.NoError(w.SetChangeOwner(owners))
+	// subnet flows secure the change for the wallet, but keep a custom owner
+	w.SecureWalletIsChangeOwner()
+
+	output, err := NewTransferOutput(avaxAssetID, 1000, owners)
+	require.NoError(err)
+	tx, err := w.P().Builder().NewBaseTx([]*avax.TransferableOutput{output})
+	require.NoError(err)
+	require.Len(tx.Outs, 2)
+	for _, out := range tx.Outs {
+		transferOut, ok := out.Out.(*secp256k1fx.TransferOutput)
+		require.True(ok)
+		require.Equal(owners.Addrs, transferOut.Addrs)
+		require.Equal(uint32(2), transferOut.Threshold)
+		require.Equal(uint64(1_900_000_000), transferOut.Locktime)
+	}
+}
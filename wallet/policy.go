@@ -0,0 +1,196 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+)
+
+var (
+	ErrPolicyViolation        = errors.New("spend policy violation")
+	ErrAmountExceeded         = errors.New("tx amount exceeds the policy limit")
+	ErrDestinationNotAllowed  = errors.New("tx destination is not allowed by the policy")
+	ErrChainNotAllowed        = errors.New("tx chain is not allowed by the policy")
+	ErrTxKindNotAllowed       = errors.New("tx kind is not allowed by the policy")
+	errUnexpectedOutputFormat = errors.New("unexpected output format")
+)
+
+// PolicyViolationError is returned when the wallet refuses to sign a tx that breaks its
+// SpendPolicy. It matches both ErrPolicyViolation and the error of the rule broken, eg.
+// ErrAmountExceeded
+type PolicyViolationError struct {
+	Kind multisig.TxKind
+	// Rule is the error of the rule broken, eg. ErrAmountExceeded
+	Rule   error
+	Detail string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrPolicyViolation, e.Rule, e.Detail)
+}
+
+func (e *PolicyViolationError) Unwrap() []error {
+	return []error{ErrPolicyViolation, e.Rule}
+}
+
+// SpendPolicy restricts the P-Chain txs the wallet signs. Each check is disabled when
+// its field is left empty
+type SpendPolicy struct {
+	// MaxAmount is the max nAVAX a tx can take out of the wallet: all the AVAX consumed,
+	// minus the change returned to wallet addresses. It includes the fee, and the
+	// staked and exported amounts
+	MaxAmount uint64
+	// AllowedDestinations are the addresses, besides the wallet ones, that can own
+	// the outputs of a tx, including stake and exported outputs
+	AllowedDestinations []ids.ShortID
+	// AllowedChains are the chains funds can be exported to or imported from
+	AllowedChains []ids.ID
+	// AllowedTxKinds are the txs the wallet can sign
+	AllowedTxKinds []multisig.TxKind
+}
+
+// Check returns a *PolicyViolationError if [utx] breaks the policy. [walletAddrs] are
+// the addresses owned by the wallet, and [avaxAssetID] the asset limited by MaxAmount
+func (p SpendPolicy) Check(utx txs.UnsignedTx, avaxAssetID ids.ID, walletAddrs set.Set[ids.ShortID]) error {
+	kind, err := multisig.New(&txs.Tx{Unsigned: utx}).GetTxKind()
+	if err != nil {
+		return err
+	}
+	violation := func(rule error, format string, args ...interface{}) error {
+		return &PolicyViolationError{Kind: kind, Rule: rule, Detail: fmt.Sprintf(format, args...)}
+	}
+	if len(p.AllowedTxKinds) > 0 && !utils.Belongs(p.AllowedTxKinds, kind) {
+		return violation(ErrTxKindNotAllowed, "%s", kind)
+	}
+	ins, err := multisig.GetInputs(utx)
+	if err != nil {
+		return err
+	}
+	outs := utx.Outputs()
+	var otherOuts []*avax.TransferableOutput
+	switch utx := utx.(type) {
+	case *txs.ImportTx:
+		if len(p.AllowedChains) > 0 && !utils.Belongs(p.AllowedChains, utx.SourceChain) {
+			return violation(ErrChainNotAllowed, "import from chain %s", utx.SourceChain)
+		}
+		ins = append(append([]*avax.TransferableInput{}, ins...), utx.ImportedInputs...)
+	case *txs.ExportTx:
+		if len(p.AllowedChains) > 0 && !utils.Belongs(p.AllowedChains, utx.DestinationChain) {
+			return violation(ErrChainNotAllowed, "export to chain %s", utx.DestinationChain)
+		}
+		otherOuts = utx.ExportedOutputs
+	}
+	if stakerTx, ok := utx.(interface {
+		Stake() []*avax.TransferableOutput
+	}); ok {
+		otherOuts = append(otherOuts, stakerTx.Stake()...)
+	}
+	allowed := set.Set[ids.ShortID]{}
+	allowed.Union(walletAddrs)
+	allowed.Add(p.AllowedDestinations...)
+	var consumed, returned uint64
+	for _, in := range ins {
+		if in.AssetID() == avaxAssetID {
+			consumed += in.In.Amount()
+		}
+	}
+	for i, out := range append(append([]*avax.TransferableOutput{}, outs...), otherOuts...) {
+		owners, amount := transferOutput(out.Out)
+		if owners == nil {
+			return fmt.Errorf("%w: output %d is %T", errUnexpectedOutputFormat, i, out.Out)
+		}
+		ownedByWallet := len(owners.Addrs) > 0
+		for _, addr := range owners.Addrs {
+			if len(p.AllowedDestinations) > 0 && !allowed.Contains(addr) {
+				return violation(ErrDestinationNotAllowed, "output to %s", addr)
+			}
+			ownedByWallet = ownedByWallet && walletAddrs.Contains(addr)
+		}
+		// only change kept on the P-Chain is returned to the wallet
+		if ownedByWallet && i < len(outs) && out.AssetID() == avaxAssetID {
+			returned += amount
+		}
+	}
+	if p.MaxAmount > 0 && consumed > returned && consumed-returned > p.MaxAmount {
+		return violation(ErrAmountExceeded, "%d nAVAX spent, limit is %d", consumed-returned, p.MaxAmount)
+	}
+	return nil
+}
+
+// SetSpendPolicy makes the wallet check [policy] on every P-Chain tx before signing it,
+// both for the txs it builds and for the ones given to its signer, eg. with SignMultisig.
+// Violations are returned as *PolicyViolationError. Each call adds a policy, that must
+// be satisfied together with the ones previously set
+func (w *Wallet) SetSpendPolicy(policy SpendPolicy) {
+	walletAddrs := set.Of(w.Addresses()...)
+	w.Wallet = primary.NewWallet(
+		newPolicyPWallet(w.Wallet.P(), policy, walletAddrs),
+		w.Wallet.X(),
+		w.Wallet.C(),
+	)
+}
+
+// policyPWallet checks a spend policy before signing P-Chain txs. Txs are built with the
+// builder of the wrapped wallet, and then checked and issued with IssueUnsignedTx
+type policyPWallet struct {
+	*issuingPWallet
+	policy      SpendPolicy
+	walletAddrs set.Set[ids.ShortID]
+}
+
+var _ p.Wallet = (*policyPWallet)(nil)
+
+func newPolicyPWallet(wallet p.Wallet, policy SpendPolicy, walletAddrs set.Set[ids.ShortID]) *policyPWallet {
+	w := &policyPWallet{
+		policy:      policy,
+		walletAddrs: walletAddrs,
+	}
+	w.issuingPWallet = &issuingPWallet{
+		Wallet: wallet,
+		issueUnsignedTx: func(utx txs.UnsignedTx, options ...common.Option) (*txs.Tx, error) {
+			if err := w.check(utx); err != nil {
+				return nil, err
+			}
+			return wallet.IssueUnsignedTx(utx, options...)
+		},
+	}
+	return w
+}
+
+func (w *policyPWallet) check(utx txs.UnsignedTx) error {
+	return w.policy.Check(utx, w.Builder().Context().AVAXAssetID, w.walletAddrs)
+}
+
+func (w *policyPWallet) Signer() psigner.Signer {
+	return &policySigner{
+		Signer: w.Wallet.Signer(),
+		wallet: w,
+	}
+}
+
+// policySigner checks the spend policy of [wallet] before signing
+type policySigner struct {
+	psigner.Signer
+	wallet *policyPWallet
+}
+
+func (s *policySigner) Sign(ctx context.Context, tx *txs.Tx) error {
+	if err := s.wallet.check(tx.Unsigned); err != nil {
+		return err
+	}
+	return s.Signer.Sign(ctx, tx)
+}
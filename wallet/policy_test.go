@@ -0,0 +1,141 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/stretchr/testify/require"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+)
+
+type fakePolicyBuilder struct {
+	pbuilder.Builder
+}
+
+func (*fakePolicyBuilder) Context() *pbuilder.Context {
+	return &pbuilder.Context{AVAXAssetID: activityAssetID}
+}
+
+type fakePolicySigner struct {
+	signed int
+}
+
+func (s *fakePolicySigner) Sign(context.Context, *txs.Tx) error {
+	s.signed++
+	return nil
+}
+
+type fakePolicyPWallet struct {
+	p.Wallet
+	signer *fakePolicySigner
+	issued int
+}
+
+func (*fakePolicyPWallet) Builder() pbuilder.Builder {
+	return &fakePolicyBuilder{}
+}
+
+func (w *fakePolicyPWallet) Signer() psigner.Signer {
+	return w.signer
+}
+
+func (w *fakePolicyPWallet) IssueUnsignedTx(utx txs.UnsignedTx, _ ...common.Option) (*txs.Tx, error) {
+	w.issued++
+	return &txs.Tx{Unsigned: utx}, nil
+}
+
+func TestSpendPolicyCheck(t *testing.T) {
+	require := require.New(t)
+	walletAddr := ids.GenerateTestShortID()
+	allowedAddr := ids.GenerateTestShortID()
+	otherAddr := ids.GenerateTestShortID()
+	walletAddrs := set.Of(walletAddr)
+	allowedChain := ids.GenerateTestID()
+
+	send := func(amount uint64, to ids.ShortID) *txs.BaseTx {
+		tx := activityBaseTx(
+			[]*avax.TransferableInput{activityIn(ids.GenerateTestID(), 0, 10_000)},
+			[]*avax.TransferableOutput{activityOut(amount, to), activityOut(10_000-amount-1, walletAddr)},
+		)
+		return &tx
+	}
+	export := &txs.ExportTx{
+		BaseTx: activityBaseTx(
+			[]*avax.TransferableInput{activityIn(ids.GenerateTestID(), 0, 10_000)},
+			[]*avax.TransferableOutput{activityOut(4_999, walletAddr)},
+		),
+		DestinationChain: ids.GenerateTestID(),
+		ExportedOutputs:  []*avax.TransferableOutput{activityOut(5_000, walletAddr)},
+	}
+
+	policy := SpendPolicy{}
+	require.NoError(policy.Check(send(9_000, otherAddr), activityAssetID, walletAddrs))
+	require.NoError(policy.Check(export, activityAssetID, walletAddrs))
+
+	policy = SpendPolicy{
+		MaxAmount:           5_001,
+		AllowedDestinations: []ids.ShortID{allowedAddr},
+		AllowedChains:       []ids.ID{allowedChain},
+		AllowedTxKinds:      []multisig.TxKind{multisig.PChainBaseTx, multisig.PChainExportTx},
+	}
+	// amount includes the fee
+	require.NoError(policy.Check(send(5_000, allowedAddr), activityAssetID, walletAddrs))
+	err := policy.Check(send(5_001, allowedAddr), activityAssetID, walletAddrs)
+	require.ErrorIs(err, ErrPolicyViolation)
+	require.ErrorIs(err, ErrAmountExceeded)
+	var violation *PolicyViolationError
+	require.ErrorAs(err, &violation)
+	require.Equal(multisig.PChainBaseTx, violation.Kind)
+
+	require.ErrorIs(policy.Check(send(1, otherAddr), activityAssetID, walletAddrs), ErrDestinationNotAllowed)
+	require.ErrorIs(policy.Check(export, activityAssetID, walletAddrs), ErrChainNotAllowed)
+	export.DestinationChain = allowedChain
+	// exported amounts count as spent, even if exported to the wallet
+	require.NoError(policy.Check(export, activityAssetID, walletAddrs))
+	export.ExportedOutputs[0] = activityOut(5_001, walletAddr)
+	export.Outs[0] = activityOut(4_998, walletAddr)
+	require.ErrorIs(policy.Check(export, activityAssetID, walletAddrs), ErrAmountExceeded)
+
+	createSubnet := &txs.CreateSubnetTx{
+		BaseTx: activityBaseTx(nil, nil),
+		Owner:  &secp256k1fx.OutputOwners{},
+	}
+	require.ErrorIs(policy.Check(createSubnet, activityAssetID, walletAddrs), ErrTxKindNotAllowed)
+}
+
+func TestPolicyPWallet(t *testing.T) {
+	require := require.New(t)
+	walletAddr := ids.GenerateTestShortID()
+	inner := &fakePolicyPWallet{signer: &fakePolicySigner{}}
+	wallet := newPolicyPWallet(inner, SpendPolicy{MaxAmount: 100}, set.Of(walletAddr))
+
+	allowed := activityBaseTx(
+		[]*avax.TransferableInput{activityIn(ids.GenerateTestID(), 0, 1_000)},
+		[]*avax.TransferableOutput{activityOut(950, walletAddr)},
+	)
+	denied := activityBaseTx(
+		[]*avax.TransferableInput{activityIn(ids.GenerateTestID(), 0, 1_000)},
+		[]*avax.TransferableOutput{activityOut(999, ids.GenerateTestShortID())},
+	)
+	_, err := wallet.IssueUnsignedTx(&allowed)
+	require.NoError(err)
+	_, err = wallet.IssueUnsignedTx(&denied)
+	require.ErrorIs(err, ErrAmountExceeded)
+	require.Equal(1, inner.issued)
+
+	require.NoError(wallet.Signer().Sign(context.Background(), &txs.Tx{Unsigned: &allowed}))
+	require.ErrorIs(wallet.Signer().Sign(context.Background(), &txs.Tx{Unsigned: &denied}), ErrAmountExceeded)
+	require.Equal(1, inner.signer.signed)
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+// UTXOKind tells how a P-Chain UTXO can be used by a set of addresses at a given time
+type UTXOKind int
+
+const (
+	// UTXOUnlocked can be used by any tx
+	UTXOUnlocked UTXOKind = iota
+	// UTXOStakeableLocked can only be used as stake by validator and delegator txs
+	// until its locktime. Staked outputs keep the lock
+	UTXOStakeableLocked
+	// UTXOTimeLocked can't be used by any tx until its locktime
+	UTXOTimeLocked
+	// UTXONotSpendable can't be used by the addresses, eg. because they don't reach
+	// the threshold of a multisig owner
+	UTXONotSpendable
+)
+
+func (k UTXOKind) String() string {
+	switch k {
+	case UTXOUnlocked:
+		return "unlocked"
+	case UTXOStakeableLocked:
+		return "stakeable locked"
+	case UTXOTimeLocked:
+		return "time locked"
+	case UTXONotSpendable:
+		return "not spendable"
+	}
+	return "invalid kind"
+}
+
+// ClassifyUTXO returns how [utxo] can be used by [addrs] at [now], and the time until
+// which it is locked, if it is
+func ClassifyUTXO(utxo *avax.UTXO, addrs set.Set[ids.ShortID], now time.Time) (UTXOKind, time.Time) {
+	unixNow := uint64(now.Unix())
+	kind := UTXOUnlocked
+	lockedUntil := uint64(0)
+	out := utxo.Out
+	if lockedOut, ok := out.(*stakeable.LockOut); ok {
+		if lockedOut.Locktime > unixNow {
+			kind = UTXOStakeableLocked
+			lockedUntil = lockedOut.Locktime
+		}
+		out = lockedOut.TransferableOut
+	}
+	transferOut, ok := out.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return UTXONotSpendable, time.Time{}
+	}
+	if transferOut.Locktime > unixNow {
+		kind = UTXOTimeLocked
+		lockedUntil = transferOut.Locktime
+		unixNow = transferOut.Locktime
+	}
+	if _, ok := common.MatchOwners(&transferOut.OutputOwners, addrs, unixNow); !ok {
+		return UTXONotSpendable, time.Time{}
+	}
+	if lockedUntil == 0 {
+		return kind, time.Time{}
+	}
+	return kind, time.Unix(int64(lockedUntil), 0)
+}
+
+// PChainBalance is the AVAX held by a set of addresses on the P-Chain, by how it can
+// be used
+type PChainBalance struct {
+	Unlocked        uint64
+	StakeableLocked uint64
+	TimeLocked      uint64
+	NotSpendable    uint64
+	// NextUnlock is the earliest time at which a locked amount is unlocked. Zero if
+	// nothing is locked
+	NextUnlock time.Time
+}
+
+// Stakeable is the amount that can be used as stake
+func (b PChainBalance) Stakeable() uint64 {
+	return b.Unlocked + b.StakeableLocked
+}
+
+func (b PChainBalance) String() string {
+	return fmt.Sprintf(
+		"unlocked %d, stakeable locked %d, time locked %d, not spendable %d",
+		b.Unlocked,
+		b.StakeableLocked,
+		b.TimeLocked,
+		b.NotSpendable,
+	)
+}
+
+func (b *PChainBalance) add(kind UTXOKind, amount uint64, lockedUntil time.Time) error {
+	var err error
+	switch kind {
+	case UTXOUnlocked:
+		b.Unlocked, err = math.Add64(b.Unlocked, amount)
+	case UTXOStakeableLocked:
+		b.StakeableLocked, err = math.Add64(b.StakeableLocked, amount)
+	case UTXOTimeLocked:
+		b.TimeLocked, err = math.Add64(b.TimeLocked, amount)
+	default:
+		b.NotSpendable, err = math.Add64(b.NotSpendable, amount)
+	}
+	if !lockedUntil.IsZero() && (b.NextUnlock.IsZero() || lockedUntil.Before(b.NextUnlock)) {
+		b.NextUnlock = lockedUntil
+	}
+	return err
+}
+
+// ClassifyPChainBalance classifies the AVAX of [utxos] by how it can be used by [addrs]
+// at [now]
+func ClassifyPChainBalance(
+	utxos []*avax.UTXO,
+	avaxAssetID ids.ID,
+	addrs set.Set[ids.ShortID],
+	now time.Time,
+) (PChainBalance, error) {
+	balance := PChainBalance{}
+	for _, utxo := range utxos {
+		if utxo.AssetID() != avaxAssetID {
+			continue
+		}
+		owners, amount := transferOutput(utxo.Out)
+		if owners == nil {
+			continue
+		}
+		kind, lockedUntil := ClassifyUTXO(utxo, addrs, now)
+		if err := balance.add(kind, amount, lockedUntil); err != nil {
+			return PChainBalance{}, fmt.Errorf("failure adding UTXO %s to the balance: %w", utxo.InputID(), err)
+		}
+	}
+	return balance, nil
+}
+
+// GetPChainBalance fetches the P-Chain UTXOs of the wallet keychain and classifies their
+// AVAX by how they can be used now. Contrary to the builder balance, locked amounts are
+// also reported
+func (w *Wallet) GetPChainBalance(ctx context.Context) (PChainBalance, error) {
+	if w.config == nil {
+		return PChainBalance{}, fmt.Errorf("wallet has no network configuration")
+	}
+	addrs := w.Keychain.Addresses()
+	utxos := common.NewUTXOs()
+	client := platformvm.NewClient(w.config.URI)
+	if err := primary.AddAllUTXOs(
+		ctx,
+		utxos,
+		client,
+		txs.Codec,
+		avagoconstants.PlatformChainID,
+		avagoconstants.PlatformChainID,
+		addrs.List(),
+	); err != nil {
+		return PChainBalance{}, fmt.Errorf("failure fetching P-Chain UTXOs: %w", err)
+	}
+	pUTXOs, err := utxos.UTXOs(ctx, avagoconstants.PlatformChainID, avagoconstants.PlatformChainID)
+	if err != nil {
+		return PChainBalance{}, err
+	}
+	return ClassifyPChainBalance(pUTXOs, w.P().Builder().Context().AVAXAssetID, addrs, time.Now())
+}
+
+// InsufficientFundsError reports a P-Chain balance not enough to burn and stake the
+// requested amounts, detailing the amounts that can't be used
+type InsufficientFundsError struct {
+	Burn    uint64
+	Stake   uint64
+	Balance PChainBalance
+}
+
+func (e *InsufficientFundsError) Error() string {
+	msg := fmt.Sprintf(
+		"%s: needed %d to burn and %d to stake, but the balance is %s",
+		pbuilder.ErrInsufficientFunds,
+		e.Burn,
+		e.Stake,
+		e.Balance,
+	)
+	if !e.Balance.NextUnlock.IsZero() {
+		msg += fmt.Sprintf(". next unlock at %s", e.Balance.NextUnlock.UTC().Format(time.RFC3339))
+	}
+	return msg
+}
+
+func (*InsufficientFundsError) Unwrap() error {
+	return pbuilder.ErrInsufficientFunds
+}
+
+// CheckFunds verifies that [b] has enough AVAX to burn [burn] and stake [stake], the way
+// the P-Chain builder selects UTXOs: stake is taken from stakeable locked UTXOs first,
+// and from unlocked ones after, while only unlocked UTXOs can be burned
+func (b PChainBalance) CheckFunds(burn uint64, stake uint64) error {
+	unlockedStake := uint64(0)
+	if stake > b.StakeableLocked {
+		unlockedStake = stake - b.StakeableLocked
+	}
+	needed, err := math.Add64(burn, unlockedStake)
+	if err != nil || needed > b.Unlocked {
+		return &InsufficientFundsError{Burn: burn, Stake: stake, Balance: b}
+	}
+	return nil
+}
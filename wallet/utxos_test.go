@@ -0,0 +1,86 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/stretchr/testify/require"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+)
+
+func testUTXO(assetID ids.ID, out avax.TransferableOut) *avax.UTXO {
+	return &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: assetID},
+		Out:    out,
+	}
+}
+
+func TestClassifyPChainBalance(t *testing.T) {
+	require := require.New(t)
+	now := time.Unix(1_700_000_000, 0)
+	addr := ids.GenerateTestShortID()
+	otherAddr := ids.GenerateTestShortID()
+	addrs := set.Of(addr)
+	owners := func(locktime uint64, threshold uint32, addrs ...ids.ShortID) secp256k1fx.OutputOwners {
+		return secp256k1fx.OutputOwners{Locktime: locktime, Threshold: threshold, Addrs: addrs}
+	}
+	unlockAt := uint64(now.Add(time.Hour).Unix())
+
+	unlocked := testUTXO(activityAssetID, &secp256k1fx.TransferOutput{Amt: 100, OutputOwners: owners(0, 1, addr)})
+	stakeableLocked := testUTXO(activityAssetID, &stakeable.LockOut{
+		Locktime:        unlockAt,
+		TransferableOut: &secp256k1fx.TransferOutput{Amt: 200, OutputOwners: owners(0, 1, addr)},
+	})
+	expiredLock := testUTXO(activityAssetID, &stakeable.LockOut{
+		Locktime:        uint64(now.Add(-time.Hour).Unix()),
+		TransferableOut: &secp256k1fx.TransferOutput{Amt: 10, OutputOwners: owners(0, 1, addr)},
+	})
+	timeLocked := testUTXO(activityAssetID, &secp256k1fx.TransferOutput{Amt: 300, OutputOwners: owners(unlockAt+1, 1, addr)})
+	multisig := testUTXO(activityAssetID, &secp256k1fx.TransferOutput{Amt: 400, OutputOwners: owners(0, 2, addr, otherAddr)})
+	otherAsset := testUTXO(ids.GenerateTestID(), &secp256k1fx.TransferOutput{Amt: 500, OutputOwners: owners(0, 1, addr)})
+
+	kind, lockedUntil := ClassifyUTXO(stakeableLocked, addrs, now)
+	require.Equal(UTXOStakeableLocked, kind)
+	require.Equal(time.Unix(int64(unlockAt), 0), lockedUntil)
+	kind, _ = ClassifyUTXO(timeLocked, addrs, now)
+	require.Equal(UTXOTimeLocked, kind)
+	kind, _ = ClassifyUTXO(timeLocked, set.Of(otherAddr), now)
+	require.Equal(UTXONotSpendable, kind)
+	kind, _ = ClassifyUTXO(multisig, set.Of(addr, otherAddr), now)
+	require.Equal(UTXOUnlocked, kind)
+
+	balance, err := ClassifyPChainBalance(
+		[]*avax.UTXO{unlocked, stakeableLocked, expiredLock, timeLocked, multisig, otherAsset},
+		activityAssetID,
+		addrs,
+		now,
+	)
+	require.NoError(err)
+	require.Equal(PChainBalance{
+		Unlocked:        110,
+		StakeableLocked: 200,
+		TimeLocked:      300,
+		NotSpendable:    400,
+		NextUnlock:      time.Unix(int64(unlockAt), 0),
+	}, balance)
+	require.Equal(uint64(310), balance.Stakeable())
+
+	require.NoError(balance.CheckFunds(110, 0))
+	require.NoError(balance.CheckFunds(10, 300))
+	err = balance.CheckFunds(111, 0)
+	require.ErrorIs(err, pbuilder.ErrInsufficientFunds)
+	var fundsErr *InsufficientFundsError
+	require.ErrorAs(err, &fundsErr)
+	require.Equal(uint64(300), fundsErr.Balance.TimeLocked)
+	require.ErrorContains(err, "stakeable locked 200, time locked 300, not spendable 400")
+	require.ErrorIs(balance.CheckFunds(10, 301), pbuilder.ErrInsufficientFunds)
+}
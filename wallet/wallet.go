@@ -5,13 +5,28 @@ package wallet
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/ava-labs/avalanche-tooling-sdk-go/avalanche"
 	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/utils"
 	"github.com/ava-labs/avalanchego/ids"
+	avagoconstants "github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/c"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/chain/x"
 	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
 	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+	xbuilder "github.com/ava-labs/avalanchego/wallet/chain/x/builder"
+	xsigner "github.com/ava-labs/avalanchego/wallet/chain/x/signer"
 )
 
 var ErrNotReadyToCommit = errors.New("tx is not fully signed so can't be committed")
@@ -21,6 +36,10 @@ type Wallet struct {
 	Keychain keychain.Keychain
 	options  []common.Option
 	config   *primary.WalletConfig
+	// changeOwner is set by SetChangeOwner
+	changeOwner *secp256k1fx.OutputOwners
+	// txHistory is set by SetTxHistory
+	txHistory TxHistoryStore
 }
 
 func New(ctx context.Context, config *primary.WalletConfig) (Wallet, error) {
@@ -37,9 +56,154 @@ func New(ctx context.Context, config *primary.WalletConfig) (Wallet, error) {
 	}, err
 }
 
+// NewWithOptions creates a wallet for [network] that signs with [kc], fetching
+// its state from the network API endpoint. See Option for the available settings
+func NewWithOptions(
+	ctx context.Context,
+	network avalanche.Network,
+	kc keychain.Keychain,
+	opts ...Option,
+) (Wallet, error) {
+	o := newOptions(opts)
+	if err := ValidateMemo(o.memo); err != nil {
+		return Wallet{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	config := &primary.WalletConfig{
+		URI:              network.Endpoint,
+		AVAXKeychain:     kc.Keychain,
+		EthKeychain:      o.ethKeychain,
+		PChainTxsToFetch: o.subnetIDs,
+	}
+	var (
+		wallet primary.Wallet
+		err    error
+	)
+	if o.utxoFilter == nil {
+		wallet, err = primary.MakeWallet(ctx, config)
+	} else {
+		wallet, err = makeFilteredWallet(ctx, config, o.utxoFilter)
+	}
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failure creating wallet for %s: %w", network.Endpoint, err)
+	}
+	w := Wallet{
+		Wallet:   wallet,
+		Keychain: kc,
+		config:   config,
+	}
+	if len(o.memo) > 0 {
+		if err := w.SetMemo(o.memo); err != nil {
+			return Wallet{}, err
+		}
+	}
+	if o.changeOwner != nil {
+		if err := w.SetChangeOwner(o.changeOwner); err != nil {
+			return Wallet{}, err
+		}
+	}
+	if o.spendPolicy != nil {
+		w.SetSpendPolicy(*o.spendPolicy)
+	}
+	if o.txHistory != nil {
+		w.SetTxHistory(o.txHistory)
+	}
+	return w, nil
+}
+
+// makeFilteredWallet is equivalent to primary.MakeWallet, but only makes
+// available for building txs the UTXOs accepted by [utxoFilter]
+func makeFilteredWallet(
+	ctx context.Context,
+	config *primary.WalletConfig,
+	utxoFilter UTXOFilter,
+) (primary.Wallet, error) {
+	avaxAddrs := config.AVAXKeychain.Addresses()
+	avaxState, err := primary.FetchState(ctx, config.URI, avaxAddrs)
+	if err != nil {
+		return nil, err
+	}
+	ethAddrs := config.EthKeychain.EthAddresses()
+	ethState, err := primary.FetchEthState(ctx, config.URI, ethAddrs)
+	if err != nil {
+		return nil, err
+	}
+	pChainTxs := map[ids.ID]*txs.Tx{}
+	for txID := range config.PChainTxsToFetch {
+		txBytes, err := avaxState.PClient.GetTx(ctx, txID)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := txs.Parse(txs.Codec, txBytes)
+		if err != nil {
+			return nil, err
+		}
+		pChainTxs[txID] = tx
+	}
+	utxos := &filteredUTXOs{
+		utxos:  avaxState.UTXOs,
+		filter: utxoFilter,
+	}
+
+	pUTXOs := common.NewChainUTXOs(avagoconstants.PlatformChainID, utxos)
+	pBackend := p.NewBackend(avaxState.PCTX, pUTXOs, pChainTxs)
+	pBuilder := pbuilder.New(avaxAddrs, avaxState.PCTX, pBackend)
+	pSigner := psigner.New(config.AVAXKeychain, pBackend)
+
+	xUTXOs := common.NewChainUTXOs(avaxState.XCTX.BlockchainID, utxos)
+	xBackend := x.NewBackend(avaxState.XCTX, xUTXOs)
+	xBuilder := xbuilder.New(avaxAddrs, avaxState.XCTX, xBackend)
+	xSigner := xsigner.New(config.AVAXKeychain, xBackend)
+
+	cUTXOs := common.NewChainUTXOs(avaxState.CCTX.BlockchainID(), utxos)
+	cBackend := c.NewBackend(avaxState.CCTX, cUTXOs, ethState.Accounts)
+	cBuilder := c.NewBuilder(avaxAddrs, ethAddrs, cBackend)
+	cSigner := c.NewSigner(config.AVAXKeychain, config.EthKeychain, cBackend)
+
+	return primary.NewWallet(
+		p.NewWallet(pBuilder, pSigner, avaxState.PClient, pBackend),
+		x.NewWallet(xBuilder, xSigner, avaxState.XClient, xBackend),
+		c.NewWallet(cBuilder, cSigner, avaxState.CClient, ethState.Client, cBackend),
+	), nil
+}
+
+// filteredUTXOs only lists the UTXOs accepted by [filter]. Direct UTXO access
+// is not filtered, so txs built elsewhere can still be signed
+type filteredUTXOs struct {
+	utxos  common.UTXOs
+	filter UTXOFilter
+}
+
+var _ common.UTXOs = (*filteredUTXOs)(nil)
+
+func (u *filteredUTXOs) AddUTXO(ctx context.Context, sourceChainID, destinationChainID ids.ID, utxo *avax.UTXO) error {
+	return u.utxos.AddUTXO(ctx, sourceChainID, destinationChainID, utxo)
+}
+
+func (u *filteredUTXOs) RemoveUTXO(ctx context.Context, sourceChainID, destinationChainID, utxoID ids.ID) error {
+	return u.utxos.RemoveUTXO(ctx, sourceChainID, destinationChainID, utxoID)
+}
+
+func (u *filteredUTXOs) UTXOs(ctx context.Context, sourceChainID, destinationChainID ids.ID) ([]*avax.UTXO, error) {
+	utxos, err := u.utxos.UTXOs(ctx, sourceChainID, destinationChainID)
+	if err != nil {
+		return nil, err
+	}
+	return utils.Filter(utxos, u.filter), nil
+}
+
+func (u *filteredUTXOs) GetUTXO(ctx context.Context, sourceChainID, destinationChainID, utxoID ids.ID) (*avax.UTXO, error) {
+	return u.utxos.GetUTXO(ctx, sourceChainID, destinationChainID, utxoID)
+}
+
 // SecureWalletIsChangeOwner ensures that a fee paying address (wallet's keychain) will receive
-// the change UTXO and not a randomly selected auth key that may not be paying fees
+// the change UTXO and not a randomly selected auth key that may not be paying fees. A change
+// owner set with SetChangeOwner is kept
 func (w *Wallet) SecureWalletIsChangeOwner() {
+	if w.changeOwner != nil {
+		return
+	}
 	addrs := w.Addresses()
 	changeAddr := addrs[0]
 	// sets change to go to wallet addr (instead of any other subnet auth key)
@@ -67,6 +231,57 @@ func (w *Wallet) SetSubnetAuthMultisig(authKeys []ids.ShortID) {
 	w.SetAuthKeys(authKeys)
 }
 
+// SetSpendMultisig makes the wallet fund the txs it builds from now on with the UTXOs
+// owned by [owners], a multisig address whose threshold may be greater than 1, as long as
+// one of the wallet addresses is among them. Change goes back to [owners], unless a change
+// owner was set with SetChangeOwner.
+//
+// The txs built are signed by the wallet keys only. The other owners sign their copies
+// with SignMultisig, and multisig.Aggregate combines them
+func (w *Wallet) SetSpendMultisig(owners *secp256k1fx.OutputOwners) error {
+	if err := ValidateOwners(owners); err != nil {
+		return err
+	}
+	if w.changeOwner == nil {
+		if err := w.SetChangeOwner(owners); err != nil {
+			return err
+		}
+	}
+	w.SetAuthKeys(owners.Addrs)
+	return nil
+}
+
 func (w *Wallet) Addresses() []ids.ShortID {
 	return w.Keychain.Addresses().List()
 }
+
+// SignMultisig signs [ms] with the wallet keys, and records in it the SignerMetadata of the
+// wallet keys that signed, so the tx envelope and signature report show which key material
+// produced each signature
+func (w *Wallet) SignMultisig(ctx context.Context, ms *multisig.Multisig) error {
+	if ms.Undefined() {
+		return multisig.ErrUndefinedTx
+	}
+	if err := w.P().Signer().Sign(ctx, ms.PChainTx); err != nil {
+		return fmt.Errorf("error signing tx: %w", err)
+	}
+	signatures, err := ms.Signatures()
+	if err != nil {
+		return err
+	}
+	recorded := set.Set[ids.ShortID]{}
+	for _, signature := range signatures {
+		if recorded.Contains(signature.Address) {
+			continue
+		}
+		recorded.Add(signature.Address)
+		metadata, ok, err := w.Keychain.SignerMetadata(signature.Address)
+		if err != nil {
+			return err
+		}
+		if ok {
+			ms.AddSignerMetadata(metadata)
+		}
+	}
+	return nil
+}
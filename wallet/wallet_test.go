@@ -0,0 +1,186 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-tooling-sdk-go/keychain"
+	"github.com/ava-labs/avalanche-tooling-sdk-go/multisig"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/p"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/stretchr/testify/require"
+
+	pbuilder "github.com/ava-labs/avalanchego/wallet/chain/p/builder"
+	psigner "github.com/ava-labs/avalanchego/wallet/chain/p/signer"
+)
+
+func TestFilteredUTXOs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	chainID := constants.PlatformChainID
+	allowedAsset := ids.GenerateTestID()
+	utxos := common.NewUTXOs()
+	allowed := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: allowedAsset},
+	}
+	filtered := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: ids.GenerateTestID()},
+	}
+	require.NoError(utxos.AddUTXO(ctx, chainID, chainID, allowed))
+	require.NoError(utxos.AddUTXO(ctx, chainID, chainID, filtered))
+	filteredUTXOs := &filteredUTXOs{
+		utxos: utxos,
+		filter: func(utxo *avax.UTXO) bool {
+			return utxo.AssetID() == allowedAsset
+		},
+	}
+	listed, err := filteredUTXOs.UTXOs(ctx, chainID, chainID)
+	require.NoError(err)
+	require.Equal([]*avax.UTXO{allowed}, listed)
+	// filtered out UTXOs are still available for signing
+	utxo, err := filteredUTXOs.GetUTXO(ctx, chainID, chainID, filtered.InputID())
+	require.NoError(err)
+	require.Equal(filtered, utxo)
+}
+
+func TestNewOptions(t *testing.T) {
+	subnetID := ids.GenerateTestID()
+	o := newOptions([]Option{WithSubnetIDs(subnetID), WithTimeout(5)})
+	require.True(t, o.subnetIDs.Contains(subnetID))
+	require.EqualValues(t, 5, o.timeout)
+	require.NotNil(t, o.ethKeychain)
+	require.Nil(t, o.utxoFilter)
+}
+
+func TestTotalTxFee(t *testing.T) {
+	context := &pbuilder.Context{
+		BaseTxFee:             1,
+		CreateSubnetTxFee:     10,
+		CreateBlockchainTxFee: 100,
+		AddSubnetValidatorFee: 1000,
+	}
+	total, err := totalTxFee(context, []multisig.TxKind{
+		multisig.PChainCreateSubnetTx,
+		multisig.PChainCreateChainTx,
+		multisig.PChainAddSubnetValidatorTx,
+		multisig.PChainAddSubnetValidatorTx,
+		multisig.PChainRemoveSubnetValidatorTx,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2111), total)
+	_, err = totalTxFee(context, []multisig.TxKind{multisig.Undefined})
+	require.Error(t, err)
+}
+
+func TestMultisigTxFee(t *testing.T) {
+	require := require.New(t)
+	context := &pbuilder.Context{
+		AddPrimaryNetworkValidatorFee: 1,
+		AddPrimaryNetworkDelegatorFee: 10,
+		AddSubnetValidatorFee:         100,
+		AddSubnetDelegatorFee:         1000,
+	}
+	subnetID := ids.GenerateTestID()
+	for _, test := range []struct {
+		tx       txs.UnsignedTx
+		expected uint64
+	}{
+		{&txs.AddPermissionlessValidatorTx{Subnet: constants.PrimaryNetworkID}, 1},
+		{&txs.AddPermissionlessDelegatorTx{Subnet: constants.PrimaryNetworkID}, 10},
+		{&txs.AddPermissionlessValidatorTx{Subnet: subnetID}, 100},
+		{&txs.AddPermissionlessDelegatorTx{Subnet: subnetID}, 1000},
+	} {
+		fee, err := multisigTxFee(context, *multisig.New(&txs.Tx{Unsigned: test.tx}))
+		require.NoError(err)
+		require.Equal(test.expected, fee, "%T", test.tx)
+	}
+	_, err := multisigTxFee(context, multisig.Multisig{})
+	require.Error(err)
+}
+
+type spendMultisigBackend struct {
+	memoTestBackend
+}
+
+func (b *spendMultisigBackend) GetUTXO(_ context.Context, _, utxoID ids.ID) (*avax.UTXO, error) {
+	for _, utxo := range b.utxos {
+		if utxo.InputID() == utxoID {
+			return utxo, nil
+		}
+	}
+	return nil, database.ErrNotFound
+}
+
+func TestSetSpendMultisig(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	keys := make([]*secp256k1.PrivateKey, 2)
+	addrs := make([]ids.ShortID, 2)
+	for i := range keys {
+		key, err := secp256k1.NewPrivateKey()
+		require.NoError(err)
+		keys[i] = key
+		addrs[i] = key.Address()
+	}
+	owners, err := NewOutputOwners(addrs, 2, 0)
+	require.NoError(err)
+	avaxAssetID := ids.GenerateTestID()
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: avaxAssetID},
+		Out:    &secp256k1fx.TransferOutput{Amt: 1_000_000, OutputOwners: *owners},
+	}
+	backend := &spendMultisigBackend{memoTestBackend{utxos: []*avax.UTXO{utxo}}}
+	newWallet := func(key *secp256k1.PrivateKey) Wallet {
+		kc := secp256k1fx.NewKeychain(key)
+		builder := pbuilder.New(set.Of(key.Address()), &pbuilder.Context{AVAXAssetID: avaxAssetID, BaseTxFee: 1000}, backend)
+		return Wallet{
+			Wallet:   primary.NewWallet(p.NewWallet(builder, psigner.New(kc, backend), nil, nil), nil, nil),
+			Keychain: keychain.Keychain{Keychain: kc},
+		}
+	}
+	w := newWallet(keys[0])
+	// a single owner can't spend the multisig utxo
+	_, err = w.P().Builder().NewBaseTx(nil)
+	require.ErrorIs(err, pbuilder.ErrInsufficientFunds)
+	require.ErrorIs(w.SetSpendMultisig(&secp256k1fx.OutputOwners{Threshold: 3, Addrs: addrs}), ErrInvalidOwners)
+	require.NoError(w.SetSpendMultisig(owners))
+	utx, err := w.P().Builder().NewBaseTx(nil)
+	require.NoError(err)
+	require.Len(utx.Ins, 1)
+	require.Equal([]uint32{0, 1}, utx.Ins[0].In.(*secp256k1fx.TransferInput).SigIndices)
+	require.Len(utx.Outs, 1)
+	require.Equal(owners.Addrs, utx.Outs[0].Out.(*secp256k1fx.TransferOutput).Addrs)
+
+	// each owner signs its own copy of the tx
+	partiallySigned := []*multisig.Multisig{}
+	for _, key := range keys {
+		ms := multisig.New(&txs.Tx{Unsigned: utx})
+		signer := newWallet(key)
+		require.NoError(signer.SignMultisig(ctx, ms))
+		ready, err := ms.IsReadyToCommit()
+		require.NoError(err)
+		require.False(ready)
+		partiallySigned = append(partiallySigned, ms)
+	}
+	ms, err := multisig.Aggregate(partiallySigned...)
+	require.NoError(err)
+	ready, err := ms.IsReadyToCommit()
+	require.NoError(err)
+	require.True(ready)
+	utxoOwners := map[ids.ID]*secp256k1fx.OutputOwners{utxo.InputID(): owners}
+	require.NoError(multisig.VerifySignatures(ms.PChainTx, multisig.ExpectedOwners{UTXOOwners: utxoOwners}))
+}